@@ -619,6 +619,16 @@ func (b *backupResumer) Resume(ctx context.Context, execCtx interface{}) error {
 		}
 	}
 
+	// If the backup was taken by a schedule with a retention period, drop the
+	// backups in the collection that have fallen out of it. The backup itself
+	// succeeded, so failing to do so is not an error; the next backup will try
+	// again.
+	if details.CollectionURI != "" {
+		if err := maybeApplyBackupScheduleRetention(ctx, p.ExecCfg(), b.job.ID()); err != nil {
+			log.Warningf(ctx, "failed to apply backup schedule retention: %v", err)
+		}
+	}
+
 	b.backupStats = res
 
 	// Collect telemetry.
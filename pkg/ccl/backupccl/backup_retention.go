@@ -0,0 +1,590 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cloud"
+	"github.com/cockroachdb/cockroach/pkg/featureflag"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver/protectedts"
+	"github.com/cockroachdb/cockroach/pkg/scheduledjobs"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+const dropBackupsOptDryRun = "dry_run"
+
+var dropBackupsOptionExpectValues = map[string]sql.KVStringOptValidate{
+	backupOptIncStorage:  sql.KVStringOptRequireValue,
+	dropBackupsOptDryRun: sql.KVStringOptRequireNoValue,
+}
+
+var dropBackupsHeader = colinfo.ResultColumns{
+	{Name: "path", Typ: types.String},
+	{Name: "full_backup_time", Typ: types.TimestampTZ},
+	{Name: "end_time", Typ: types.TimestampTZ},
+	{Name: "incremental_backups", Typ: types.Int},
+}
+
+// backupCollectionLocations describes where the backups of a collection are
+// stored.
+type backupCollectionLocations struct {
+	// collection holds the URIs of the (possibly locality-partitioned)
+	// collection, which contains the full backups and, unless
+	// incrementalStorage is set, their incremental backups.
+	collection []string
+	// incrementalStorage holds the URIs that incremental backups are written to
+	// when they are stored outside of the collection.
+	incrementalStorage []string
+}
+
+// backupChain is a full backup in a collection together with the incremental
+// backups that were appended to it. Incremental backups depend on every
+// backup before them in the chain, so a chain can only be dropped as a whole.
+type backupChain struct {
+	// subdir is the path of the full backup within the collection, e.g.
+	// /2022/03/14-120000.00.
+	subdir string
+	// fullEndTime is the end time of the full backup.
+	fullEndTime time.Time
+	// endTime is the end time of the most recent backup in the chain.
+	endTime time.Time
+	// incrementals are the paths of the incremental backups in the chain,
+	// relative to subdir.
+	incrementals []string
+}
+
+// listBackupChains returns the backup chains in the collection, ordered by the
+// end time of their full backup. Full backups whose path was not generated by
+// BACKUP INTO, and so cannot be dated, are never returned.
+func listBackupChains(
+	ctx context.Context,
+	makeCloudStorage cloud.ExternalStorageFromURIFactory,
+	user security.SQLUsername,
+	locations backupCollectionLocations,
+) ([]backupChain, error) {
+	collectionURI, _, err := getURIsByLocalityKV(locations.collection, "")
+	if err != nil {
+		return nil, err
+	}
+	store, err := makeCloudStorage(ctx, collectionURI, user)
+	if err != nil {
+		return nil, errors.Wrap(err, "connect to external storage")
+	}
+	defer store.Close()
+	fulls, err := ListFullBackupsInCollection(ctx, store)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing backups in collection")
+	}
+
+	chains := make([]backupChain, 0, len(fulls))
+	for _, subdir := range fulls {
+		fullEndTime, err := time.Parse(DateBasedIntoFolderName, subdir)
+		if err != nil {
+			log.Warningf(ctx, "skipping backup %s with unexpected path: %v", subdir, err)
+			continue
+		}
+		chain := backupChain{subdir: subdir, fullEndTime: fullEndTime, endTime: fullEndTime}
+
+		chainStorage := locations.collection
+		if len(locations.incrementalStorage) > 0 {
+			chainStorage = locations.incrementalStorage
+		}
+		chainURI, _, err := getURIsByLocalityKV(chainStorage, subdir)
+		if err != nil {
+			return nil, err
+		}
+		incStore, err := makeCloudStorage(ctx, chainURI, user)
+		if err != nil {
+			return nil, errors.Wrap(err, "connect to incremental storage")
+		}
+		chain.incrementals, err = FindPriorBackups(ctx, incStore, OmitManifest)
+		incStore.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, inc := range chain.incrementals {
+			incEndTime, err := time.Parse(DateBasedIncFolderName, inc)
+			if err != nil {
+				// We cannot tell how recent this chain is, so we treat it as if it
+				// never ended to keep it from being dropped.
+				log.Warningf(ctx, "incremental backup %s in %s has unexpected path: %v", inc, subdir, err)
+				chain.endTime = hlc.MaxTimestamp.GoTime()
+				break
+			}
+			if incEndTime.After(chain.endTime) {
+				chain.endTime = incEndTime
+			}
+		}
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].fullEndTime.Before(chains[j].fullEndTime)
+	})
+	return chains, nil
+}
+
+// selectBackupChainsToDrop returns the chains, ordered by the end time of their
+// full backup, that are not needed to restore to any time at or after cutoff.
+//
+// The most recent chain whose full backup ended at or before cutoff is kept,
+// since it is required to restore to cutoff, along with every chain after it.
+// Of the older chains, the ones that are dropped are those that:
+//   - ended at or before cutoff,
+//   - are not the chain the LATEST file points to, since scheduled incremental
+//     backups are appended to it, and
+//   - ended before protectedAfter, the earliest timestamp protected by the
+//     chained protected timestamp records of a schedule writing to the
+//     collection. Incremental backups of such a schedule rely on the
+//     backups after that timestamp to pick up where the chain left off.
+//     protectedAfter is ignored if it is zero.
+func selectBackupChainsToDrop(
+	chains []backupChain, cutoff time.Time, latest string, protectedAfter time.Time,
+) []backupChain {
+	keep := -1
+	for i := range chains {
+		if chains[i].fullEndTime.After(cutoff) {
+			break
+		}
+		keep = i
+	}
+	var toDrop []backupChain
+	for i := 0; i < keep; i++ {
+		c := chains[i]
+		if c.endTime.After(cutoff) {
+			continue
+		}
+		if latest != "" && path.Clean(c.subdir) == path.Clean(latest) {
+			continue
+		}
+		if !protectedAfter.IsZero() && !c.endTime.Before(protectedAfter) {
+			continue
+		}
+		toDrop = append(toDrop, c)
+	}
+	return toDrop
+}
+
+// dropBackupChain deletes every file of the chain, in the collection and in
+// the incremental storage. The manifests are deleted last so that a drop that
+// fails part way through leaves a chain that is still listed, and can be
+// dropped again.
+func dropBackupChain(
+	ctx context.Context,
+	makeCloudStorage cloud.ExternalStorageFromURIFactory,
+	user security.SQLUsername,
+	locations backupCollectionLocations,
+	chain backupChain,
+) error {
+	uris := append([]string(nil), locations.collection...)
+	uris = append(uris, locations.incrementalStorage...)
+	for _, uri := range uris {
+		_, baseURI, err := getLocalityAndBaseURI(uri, "")
+		if err != nil {
+			return err
+		}
+		store, err := makeCloudStorage(ctx, baseURI, user)
+		if err != nil {
+			return errors.Wrap(err, "connect to external storage")
+		}
+		err = deleteBackupChainFiles(ctx, store, chain.subdir)
+		store.Close()
+		if err != nil {
+			return errors.Wrapf(err, "dropping backup %s from %s", chain.subdir,
+				RedactURIForErrorMessage(baseURI))
+		}
+	}
+	return nil
+}
+
+func deleteBackupChainFiles(ctx context.Context, store cloud.ExternalStorage, subdir string) error {
+	prefix := strings.TrimSuffix(subdir, "/") + "/"
+	var files, manifests []string
+	if err := store.List(ctx, prefix, "", func(f string) error {
+		f = path.Join(prefix, f)
+		if strings.HasPrefix(path.Base(f), backupOldManifestName) {
+			manifests = append(manifests, f)
+		} else {
+			files = append(files, f)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, f := range append(files, manifests...) {
+		if err := store.Delete(ctx, strings.TrimPrefix(f, "/")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropBackupsOlderThan drops the chains of the collection that are not needed
+// to restore to any time at or after cutoff, and returns them. If dryRun is
+// set, the chains are returned without being dropped.
+func dropBackupsOlderThan(
+	ctx context.Context,
+	execCfg *sql.ExecutorConfig,
+	user security.SQLUsername,
+	locations backupCollectionLocations,
+	cutoff time.Time,
+	dryRun bool,
+) ([]backupChain, error) {
+	makeCloudStorage := execCfg.DistSQLSrv.ExternalStorageFromURI
+	collectionURI, _, err := getURIsByLocalityKV(locations.collection, "")
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := readLatestFile(ctx, collectionURI, makeCloudStorage, user)
+	if err != nil {
+		if !errors.Is(err, cloud.ErrFileDoesNotExist) {
+			return nil, err
+		}
+		latest = ""
+	}
+
+	var protectedAfter time.Time
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		protectedAfter, err = collectionProtectedAfter(ctx, execCfg, txn, collectionURI)
+		return err
+	}); err != nil {
+		return nil, errors.Wrap(err, "resolving protected timestamps of backup schedules")
+	}
+
+	chains, err := listBackupChains(ctx, makeCloudStorage, user, locations)
+	if err != nil {
+		return nil, err
+	}
+	toDrop := selectBackupChainsToDrop(chains, cutoff, latest, protectedAfter)
+	if dryRun {
+		return toDrop, nil
+	}
+	for i, chain := range toDrop {
+		if err := dropBackupChain(ctx, makeCloudStorage, user, locations, chain); err != nil {
+			return toDrop[:i], err
+		}
+		log.Infof(ctx, "dropped backup %s with %d incremental backups", chain.subdir,
+			len(chain.incrementals))
+	}
+	return toDrop, nil
+}
+
+// collectionProtectedAfter returns the earliest timestamp protected by the
+// chained protected timestamp record of a backup schedule writing to the
+// collection, or the zero time if there is no such schedule.
+func collectionProtectedAfter(
+	ctx context.Context, execCfg *sql.ExecutorConfig, txn *kv.Txn, collectionURI string,
+) (time.Time, error) {
+	env := scheduledjobs.ProdJobSchedulerEnv
+	if knobs, ok := execCfg.DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
+		if knobs.JobSchedulerEnv != nil {
+			env = knobs.JobSchedulerEnv
+		}
+	}
+
+	rows, err := execCfg.InternalExecutor.QueryBufferedEx(
+		ctx,
+		"lookup-backup-schedules",
+		txn,
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		fmt.Sprintf("SELECT schedule_id FROM %s WHERE executor_type = $1",
+			env.ScheduledJobsTableName()),
+		tree.ScheduledBackupExecutor.InternalName())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var protectedAfter time.Time
+	for _, row := range rows {
+		sj, args, err := getScheduledBackupExecutionArgsFromSchedule(ctx, env, txn,
+			execCfg.InternalExecutor, int64(tree.MustBeDInt(row[0])))
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !args.ChainProtectedTimestampRecords || args.ProtectedTimestampRecord == nil {
+			continue
+		}
+		backupNode, err := extractBackupStatement(sj)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !backupWritesToCollection(backupNode.Backup, collectionURI) {
+			continue
+		}
+		rec, err := execCfg.ProtectedTimestampProvider.GetRecord(ctx, txn, *args.ProtectedTimestampRecord)
+		if err != nil {
+			if errors.Is(err, protectedts.ErrNotExists) {
+				continue
+			}
+			return time.Time{}, err
+		}
+		if ts := rec.Timestamp.GoTime(); protectedAfter.IsZero() || ts.Before(protectedAfter) {
+			protectedAfter = ts
+		}
+	}
+	return protectedAfter, nil
+}
+
+// backupWritesToCollection returns whether the scheduled backup statement
+// writes to the collection. URIs are compared without their query parameters,
+// which may hold credentials that differ between the two.
+func backupWritesToCollection(backup *tree.Backup, collectionURI string) bool {
+	stripQuery := func(uri string) string {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			return uri
+		}
+		parsed.RawQuery = ""
+		parsed.Path = path.Clean(parsed.Path)
+		return parsed.String()
+	}
+	collection := stripQuery(collectionURI)
+	for _, to := range backup.To {
+		if s, ok := to.(*tree.StrVal); ok && stripQuery(s.RawString()) == collection {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeApplyBackupScheduleRetention drops the backup chains that have fallen
+// out of the retention period of the schedule that created the backup job, if
+// there is one and it has a retention period. It is called on the completion
+// of both the full and the incremental backups of the schedule.
+//
+// The locations of the collection are read from the backup statement of the
+// schedule rather than from the details of the job, whose destination only
+// holds the resolved subdirectory once the job has been planned.
+func maybeApplyBackupScheduleRetention(
+	ctx context.Context, execCfg *sql.ExecutorConfig, id jobspb.JobID,
+) error {
+	env := scheduledjobs.ProdJobSchedulerEnv
+	if knobs, ok := execCfg.DistSQLSrv.TestingKnobs.JobsTestingKnobs.(*jobs.TestingKnobs); ok {
+		if knobs.JobSchedulerEnv != nil {
+			env = knobs.JobSchedulerEnv
+		}
+	}
+
+	var args *ScheduledBackupExecutionArgs
+	var owner security.SQLUsername
+	var locations backupCollectionLocations
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		datums, err := execCfg.InternalExecutor.QueryRowEx(
+			ctx,
+			"lookup-schedule-info",
+			txn,
+			sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+			fmt.Sprintf(
+				"SELECT created_by_id FROM %s WHERE id=$1 AND created_by_type=$2",
+				env.SystemJobsTableName()),
+			id, jobs.CreatedByScheduledJobs)
+		if err != nil {
+			return errors.Wrap(err, "schedule info lookup")
+		}
+		if datums == nil {
+			// Not a scheduled backup.
+			return nil
+		}
+		var sj *jobs.ScheduledJob
+		sj, args, err = getScheduledBackupExecutionArgsFromSchedule(ctx, env, txn,
+			execCfg.InternalExecutor, int64(tree.MustBeDInt(datums[0])))
+		if err != nil {
+			return errors.Wrap(err, "load scheduled job")
+		}
+		owner = sj.Owner()
+		if args.Retention == "" {
+			return nil
+		}
+		backup, err := extractBackupStatement(sj)
+		if err != nil {
+			return err
+		}
+		locations = backupStatementLocations(backup.Backup)
+
+		// The incremental backups of a full backup schedule are written by its
+		// dependent schedule, possibly to a different location.
+		if args.BackupType != ScheduledBackupExecutionArgs_FULL || args.DependentScheduleID == 0 ||
+			len(locations.incrementalStorage) > 0 {
+			return nil
+		}
+		incSj, _, err := getScheduledBackupExecutionArgsFromSchedule(ctx, env, txn,
+			execCfg.InternalExecutor, args.DependentScheduleID)
+		if err != nil {
+			if jobs.HasScheduledJobNotFoundError(err) {
+				return nil
+			}
+			return err
+		}
+		incBackup, err := extractBackupStatement(incSj)
+		if err != nil {
+			return err
+		}
+		locations.incrementalStorage = backupStatementLocations(incBackup.Backup).incrementalStorage
+		return nil
+	}); err != nil {
+		return err
+	}
+	if args == nil || args.Retention == "" {
+		return nil
+	}
+
+	retention, err := tree.ParseDInterval(duration.IntervalStyle_POSTGRES, args.Retention)
+	if err != nil {
+		return errors.Wrapf(err, "parsing schedule %s", optRetention)
+	}
+	cutoff := duration.Add(execCfg.Clock.PhysicalTime(), retention.Duration.Mul(-1))
+	dropped, err := dropBackupsOlderThan(ctx, execCfg, owner, locations, cutoff, false /* dryRun */)
+	if len(dropped) > 0 {
+		log.Infof(ctx, "dropped %d backups older than the %s schedule %s", len(dropped),
+			args.Retention, optRetention)
+	}
+	return err
+}
+
+// backupStatementLocations returns the locations of the collection a backup
+// statement writes to.
+func backupStatementLocations(backup *tree.Backup) backupCollectionLocations {
+	var locations backupCollectionLocations
+	for _, dest := range backup.To {
+		if s, ok := dest.(*tree.StrVal); ok {
+			locations.collection = append(locations.collection, s.RawString())
+		}
+	}
+	for _, dest := range backup.Options.IncrementalStorage {
+		if s, ok := dest.(*tree.StrVal); ok {
+			locations.incrementalStorage = append(locations.incrementalStorage, s.RawString())
+		}
+	}
+	return locations
+}
+
+// parseBackupRetention parses an OLDER THAN or retention interval, which must
+// be positive.
+func parseBackupRetention(style duration.IntervalStyle, s string, op string) (*tree.DInterval, error) {
+	d, err := tree.ParseDInterval(style, s)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue, "invalid %s interval", op)
+	}
+	if d.Duration.Compare(duration.Duration{}) <= 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s interval must be positive, found %s", op, d.Duration)
+	}
+	return d, nil
+}
+
+func dropBackupsPlanHook(
+	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, colinfo.ResultColumns, []sql.PlanNode, bool, error) {
+	dropBackups, ok := stmt.(*tree.DropBackups)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	if err := featureflag.CheckEnabled(
+		ctx,
+		p.ExecCfg(),
+		featureBackupEnabled,
+		"DROP BACKUPS",
+	); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	collectionFn, err := p.TypeAsString(ctx, dropBackups.In, "DROP BACKUPS")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	olderThanFn, err := p.TypeAsString(ctx, dropBackups.OlderThan, "DROP BACKUPS")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	optsFn, err := p.TypeAsStringOpts(ctx, dropBackups.Options, dropBackupsOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer span.Finish()
+
+		if err := p.RequireAdminRole(ctx, "DROP BACKUPS"); err != nil {
+			return err
+		}
+
+		collection, err := collectionFn()
+		if err != nil {
+			return err
+		}
+		olderThan, err := olderThanFn()
+		if err != nil {
+			return err
+		}
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		interval, err := parseBackupRetention(p.SessionData().GetIntervalStyle(), olderThan, "OLDER THAN")
+		if err != nil {
+			return err
+		}
+		cutoff := duration.Add(p.ExecCfg().Clock.PhysicalTime(), interval.Duration.Mul(-1))
+
+		locations := backupCollectionLocations{collection: []string{collection}}
+		if incStorage, ok := opts[backupOptIncStorage]; ok {
+			locations.incrementalStorage = []string{incStorage}
+		}
+		_, dryRun := opts[dropBackupsOptDryRun]
+
+		dropped, err := dropBackupsOlderThan(ctx, p.ExecCfg(), p.User(), locations, cutoff, dryRun)
+		for _, chain := range dropped {
+			fullEndTime, tsErr := tree.MakeDTimestampTZ(chain.fullEndTime, time.Microsecond)
+			if tsErr != nil {
+				return tsErr
+			}
+			endTime, tsErr := tree.MakeDTimestampTZ(chain.endTime, time.Microsecond)
+			if tsErr != nil {
+				return tsErr
+			}
+			resultsCh <- tree.Datums{
+				tree.NewDString(chain.subdir),
+				fullEndTime,
+				endTime,
+				tree.NewDInt(tree.DInt(len(chain.incrementals))),
+			}
+		}
+		return err
+	}
+	return fn, dropBackupsHeader, nil, false, nil
+}
+
+func init() {
+	sql.AddPlanHook("drop backups", dropBackupsPlanHook)
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+	pbtypes "github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectBackupChainsToDrop(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	day := func(d int) time.Time {
+		return time.Date(2022, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+	// chain returns a chain with a full backup taken on day full, and
+	// incremental backups appended to it until day end.
+	chain := func(full, end int) backupChain {
+		c := backupChain{
+			subdir:      day(full).Format(DateBasedIntoFolderName),
+			fullEndTime: day(full),
+			endTime:     day(end),
+		}
+		for d := full + 1; d <= end; d++ {
+			c.incrementals = append(c.incrementals, day(d).Format(DateBasedIncFolderName))
+		}
+		return c
+	}
+	chains := []backupChain{chain(1, 6), chain(7, 13), chain(14, 20), chain(21, 24)}
+	subdirs := func(chains []backupChain) []string {
+		var res []string
+		for _, c := range chains {
+			res = append(res, c.subdir)
+		}
+		return res
+	}
+
+	for _, tc := range []struct {
+		name           string
+		cutoff         time.Time
+		latest         string
+		protectedAfter time.Time
+		expected       []string
+	}{
+		{
+			name:   "cutoff before all backups",
+			cutoff: day(0),
+		},
+		{
+			name:   "cutoff within first chain",
+			cutoff: day(5),
+		},
+		{
+			name:     "chain needed to restore to cutoff is kept",
+			cutoff:   day(16),
+			expected: subdirs(chains[:2]),
+		},
+		{
+			name:     "cutoff on full backup",
+			cutoff:   day(14),
+			expected: subdirs(chains[:2]),
+		},
+		{
+			name:     "cutoff after all backups",
+			cutoff:   day(30),
+			expected: subdirs(chains[:3]),
+		},
+		{
+			name:     "latest chain is kept",
+			cutoff:   day(30),
+			latest:   chains[1].subdir,
+			expected: subdirs([]backupChain{chains[0], chains[2]}),
+		},
+		{
+			name:           "chains protected by a schedule are kept",
+			cutoff:         day(30),
+			protectedAfter: day(13),
+			expected:       subdirs(chains[:1]),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			toDrop := selectBackupChainsToDrop(chains, tc.cutoff, tc.latest, tc.protectedAfter)
+			require.Equal(t, tc.expected, subdirs(toDrop))
+		})
+	}
+
+	t.Run("chain overlapping cutoff is kept", func(t *testing.T) {
+		// An incremental backup appended to the first chain after the second
+		// full backup was taken.
+		overlapping := []backupChain{chain(1, 10), chain(7, 13), chain(14, 20)}
+		require.Empty(t, selectBackupChainsToDrop(overlapping, day(9), "", time.Time{}))
+		require.Equal(t, subdirs(overlapping[:2]),
+			subdirs(selectBackupChainsToDrop(overlapping, day(15), "", time.Time{})))
+	})
+}
+
+// TestScheduledBackupRetentionAfterIncremental verifies that the retention
+// period of a backup schedule is enforced on the completion of its
+// incremental backups, and not only of its full backups.
+func TestScheduledBackupRetentionAfterIncremental(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	th, cleanup := newTestHelper(t)
+	defer cleanup()
+
+	th.sqlDB.Exec(t, `
+CREATE DATABASE db;
+USE db;
+CREATE TABLE t(a int);
+INSERT INTO t values (1), (10), (100);
+`)
+
+	// The backups must be taken as of the current time rather than the time
+	// of the test scheduler environment.
+	knobs := th.cfg.TestingKnobs.(*jobs.TestingKnobs)
+	knobs.OverrideAsOfClause = func(clause *tree.AsOfClause) {
+		expr, err := tree.MakeDTimestampTZ(th.cfg.DB.Clock().PhysicalTime(), time.Microsecond)
+		require.NoError(t, err)
+		clause.Expr = expr
+	}
+	defer func() { knobs.OverrideAsOfClause = nil }()
+
+	const collection = "nodelocal://0/backup/retention"
+	schedules, err := th.createBackupSchedule(t,
+		"CREATE SCHEDULE FOR BACKUP INTO $1 RECURRING '*/5 * * * *' FULL BACKUP '@daily'", collection)
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	fullID, incID := schedules[0].ScheduleID(), schedules[1].ScheduleID()
+	if schedules[0].IsPaused() {
+		fullID, incID = incID, fullID
+	}
+
+	runSchedule := func(id int64, expectedSuccesses int) {
+		s := th.loadSchedule(t, id)
+		s.SetNextRun(th.env.Now().Add(-time.Minute))
+		require.NoError(t, s.Update(context.Background(), th.cfg.InternalExecutor, nil))
+		require.NoError(t, th.executeSchedules())
+		testutils.SucceedsSoon(t, func() error {
+			th.server.JobRegistry().(*jobs.Registry).TestingNudgeAdoptionQueue()
+			var n int
+			th.sqlDB.QueryRow(t, "SELECT count(*) FROM "+th.env.SystemJobsTableName()+
+				" WHERE status=$1 AND created_by_type=$2 AND created_by_id=$3",
+				jobs.StatusSucceeded, jobs.CreatedByScheduledJobs, id).Scan(&n)
+			if n < expectedSuccesses {
+				return errors.Newf("%d of %d backups succeeded", n, expectedSuccesses)
+			}
+			return nil
+		})
+	}
+
+	// Write two backup chains while the schedule retains backups
+	// indefinitely.
+	runSchedule(fullID, 1)
+	runSchedule(fullID, 2)
+	require.Len(t, th.sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection), 2)
+
+	// Only retain the backups of the last second. The first chain is not
+	// needed anymore once the second one is older than that, which is
+	// detected by the next incremental backup.
+	inc := th.loadSchedule(t, incID)
+	args := &ScheduledBackupExecutionArgs{}
+	require.NoError(t, pbtypes.UnmarshalAny(inc.ExecutionArgs().Args, args))
+	args.Retention = "00:00:01"
+	any, err := pbtypes.MarshalAny(args)
+	require.NoError(t, err)
+	inc.SetExecutionDetails(inc.ExecutorType(), jobspb.ExecutionArguments{Args: any})
+	require.NoError(t, inc.Update(context.Background(), th.cfg.InternalExecutor, nil))
+	time.Sleep(time.Second)
+
+	runSchedule(incID, 1)
+	require.Len(t, th.sqlDB.QueryStr(t, `SHOW BACKUPS IN $1`, collection), 1)
+}
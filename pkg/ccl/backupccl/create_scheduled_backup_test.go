@@ -1151,6 +1151,12 @@ func constructExpectedScheduledBackupNode(
 			Value: tree.NewDString(wait),
 		},
 	}
+	if args.Retention != "" {
+		scheduleOptions = append(scheduleOptions, tree.KVOption{
+			Key:   optRetention,
+			Value: tree.NewDString(args.Retention),
+		})
+	}
 	sb := &tree.ScheduledBackup{
 		ScheduleLabelSpec: tree.ScheduleLabelSpec{
 			IfNotExists: false,
@@ -1203,6 +1209,12 @@ func TestShowCreateScheduleStatement(t *testing.T) {
 			fullRecurrence: "@daily",
 			recurrence:     "@hourly",
 		},
+		{
+			name:           "full-incremental-schedule-with-retention",
+			query:          `CREATE SCHEDULE FOR BACKUP INTO '%s' RECURRING '@hourly' FULL BACKUP '@daily' WITH SCHEDULE OPTIONS retention = '30 days'`,
+			fullRecurrence: "@daily",
+			recurrence:     "@hourly",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -37,6 +37,10 @@ type restorationData interface {
 	getTenantRekeys() []execinfrapb.TenantRekey
 	getPKIDs() map[uint64]bool
 
+	// getSampling returns the fraction of the data in sampleSpans that should be
+	// restored, or 0 if all of the data should be restored.
+	getSampling() (sampleFraction float64, sampleSpans []roachpb.Span)
+
 	// addTenant extends the set of data needed to restore to include a new tenant.
 	addTenant(fromID, toID roachpb.TenantID)
 
@@ -74,6 +78,11 @@ type restorationDataBase struct {
 	// systemTables store the system tables that need to be restored for cluster
 	// backups. Should be nil otherwise.
 	systemTables []catalog.TableDescriptor
+
+	// sampleFraction, if non-zero, is the fraction of the data in sampleSpans
+	// that should be restored.
+	sampleFraction float64
+	sampleSpans    []roachpb.Span
 }
 
 // restorationDataBase implements restorationData.
@@ -94,6 +103,11 @@ func (b *restorationDataBase) getPKIDs() map[uint64]bool {
 	return b.pkIDs
 }
 
+// getSampling implements restorationData.
+func (b *restorationDataBase) getSampling() (float64, []roachpb.Span) {
+	return b.sampleFraction, b.sampleSpans
+}
+
 // getSpans implements restorationData.
 func (b *restorationDataBase) getSpans() []roachpb.Span {
 	return b.spans
@@ -56,6 +56,9 @@ type restoreDataProcessor struct {
 	// flushBytes is the maximum buffer size used when creating SSTs to flush. It
 	// remains constant over the lifetime of the processor.
 	flushBytes int64
+	// sampler decides which rows are restored when restoring with a
+	// sample_fraction.
+	sampler restoreSampler
 
 	// phaseGroup manages the phases of the restore:
 	// 1) reading entries from the input
@@ -131,6 +134,7 @@ func newRestoreDataProcessor(
 		metaCh:     make(chan *execinfrapb.ProducerMetadata, 1),
 		numWorkers: int(numRestoreWorkers.Get(sv)),
 		flushBytes: bulk.IngestFileSize(flowCtx.Cfg.Settings),
+		sampler:    makeRestoreSampler(spec.SampleFraction, spec.SampleSpans),
 	}
 
 	if err := rd.Init(rd, post, restoreDataOutputTypes, flowCtx, processorID, output, nil, /* memMonitor */
@@ -458,6 +462,12 @@ func (rd *restoreDataProcessor) processRestoreSpanEntry(
 		value := roachpb.Value{RawBytes: valueScratch}
 		iter.NextKey()
 
+		if ok, err := rd.sampler.keep(key.Key); err != nil {
+			return summary, err
+		} else if !ok {
+			continue
+		}
+
 		key.Key, ok, err = kr.RewriteKey(key.Key)
 		if err != nil {
 			return summary, err
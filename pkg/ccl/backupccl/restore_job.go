@@ -355,6 +355,7 @@ func restore(
 	tasks = append(tasks, jobCheckpointLoop)
 
 	runRestore := func(ctx context.Context) error {
+		sampleFraction, sampleSpans := dataToRestore.getSampling()
 		return distRestore(
 			ctx,
 			execCtx,
@@ -364,6 +365,8 @@ func restore(
 			dataToRestore.getRekeys(),
 			dataToRestore.getTenantRekeys(),
 			endTime,
+			sampleFraction,
+			sampleSpans,
 			progCh,
 		)
 	}
@@ -680,10 +683,22 @@ func createImportingDescriptors(
 		switch desc := desc.(type) {
 		case catalog.TableDescriptor:
 			mut := tabledesc.NewBuilder(desc.TableDesc()).BuildCreatedMutableTable()
-			if shouldPreRestore(mut) {
-				preRestoreTables = append(preRestoreTables, mut)
-			} else {
-				postRestoreTables = append(postRestoreTables, mut)
+			sampled := details.SampleFraction > 0 && shouldSampleRestoredData(mut)
+			if sampled {
+				prepareTableForSampledRestore(mut)
+			}
+			if !details.SchemaOnly || restoresDataWithSchemaOnly(mut) {
+				// Only the primary index of a sampled table is restored, its
+				// secondary indexes are backfilled once it is published.
+				var dataTable catalog.TableDescriptor = mut
+				if sampled {
+					dataTable = withoutSecondaryIndexes(mut)
+				}
+				if shouldPreRestore(mut) {
+					preRestoreTables = append(preRestoreTables, dataTable)
+				} else {
+					postRestoreTables = append(postRestoreTables, dataTable)
+				}
 			}
 			tables = append(tables, mut)
 			mutableTables = append(mutableTables, mut)
@@ -714,6 +729,21 @@ func createImportingDescriptors(
 	// that is, in the 'old' keyspace, before we reassign the table IDs.
 	preRestoreSpans := spansForAllRestoreTableIndexes(backupCodec, preRestoreTables, nil)
 	postRestoreSpans := spansForAllRestoreTableIndexes(backupCodec, postRestoreTables, nil)
+	if len(details.SpanFilterSpans) > 0 {
+		// Only the portion of the primary index selected by the span_filter
+		// option is restored.
+		postRestoreSpans = details.SpanFilterSpans
+	}
+	var sampleSpans []roachpb.Span
+	if details.SampleFraction > 0 {
+		sampledTables := make([]catalog.TableDescriptor, 0, len(postRestoreTables))
+		for _, table := range postRestoreTables {
+			if shouldSampleRestoredData(table) {
+				sampledTables = append(sampledTables, table)
+			}
+		}
+		sampleSpans = spansForAllRestoreTableIndexes(backupCodec, sampledTables, nil)
+	}
 
 	log.Eventf(ctx, "starting restore for %d tables", len(mutableTables))
 
@@ -1095,9 +1125,11 @@ func createImportingDescriptors(
 
 	dataToRestore := &mainRestorationData{
 		restorationDataBase{
-			spans:       postRestoreSpans,
-			tableRekeys: rekeys,
-			pkIDs:       pkIDs,
+			spans:          postRestoreSpans,
+			tableRekeys:    rekeys,
+			pkIDs:          pkIDs,
+			sampleFraction: details.SampleFraction,
+			sampleSpans:    sampleSpans,
 		},
 	}
 
@@ -1739,6 +1771,10 @@ func (r *restoreResumer) publishDescriptors(
 		}
 
 		badIndexes := devalidateIndexes[mutTable.ID]
+		if len(details.SpanFilterSpans) > 0 ||
+			(details.SampleFraction > 0 && shouldSampleRestoredData(mutTable)) {
+			badIndexes = addSecondaryIndexesToBackfill(mutTable, badIndexes)
+		}
 		for _, badIdx := range badIndexes {
 			found, err := mutTable.FindIndexWithID(badIdx)
 			if err != nil {
@@ -66,6 +66,10 @@ const (
 	restoreOptSkipMissingViews          = "skip_missing_views"
 	restoreOptSkipLocalitiesCheck       = "skip_localities_check"
 	restoreOptDebugPauseOn              = "debug_pause_on"
+	restoreOptIntoTable                 = "into_table"
+	restoreOptSpanFilter                = "span_filter"
+	restoreOptSchemaOnly                = "schema_only"
+	restoreOptSampleFraction            = "sample_fraction"
 
 	// The temporary database system tables will be restored into for full
 	// cluster backups.
@@ -926,7 +930,14 @@ func maybeUpgradeDescriptorsInBackupManifests(
 // them to be suitable for displaying in the jobs' description.
 // This includes redacting secrets from external storage URIs.
 func resolveOptionsForRestoreJobDescription(
-	opts tree.RestoreOptions, intoDB string, newDBName string, kmsURIs []string, incFrom []string,
+	opts tree.RestoreOptions,
+	intoDB string,
+	newDBName string,
+	intoTable string,
+	spanFilter string,
+	sampleFraction float64,
+	kmsURIs []string,
+	incFrom []string,
 ) (tree.RestoreOptions, error) {
 	if opts.IsDefault() {
 		return opts, nil
@@ -938,6 +949,7 @@ func resolveOptionsForRestoreJobDescription(
 		SkipMissingSequenceOwners: opts.SkipMissingSequenceOwners,
 		SkipMissingViews:          opts.SkipMissingViews,
 		Detached:                  opts.Detached,
+		SchemaOnly:                opts.SchemaOnly,
 	}
 
 	if opts.EncryptionPassphrase != nil {
@@ -952,6 +964,18 @@ func resolveOptionsForRestoreJobDescription(
 		newOpts.NewDBName = tree.NewDString(newDBName)
 	}
 
+	if opts.IntoTable != nil {
+		newOpts.IntoTable = tree.NewDString(intoTable)
+	}
+
+	if opts.SpanFilter != nil {
+		newOpts.SpanFilter = tree.NewDString(spanFilter)
+	}
+
+	if opts.SampleFraction != nil {
+		newOpts.SampleFraction = tree.NewDString(strconv.FormatFloat(sampleFraction, 'g', -1, 64))
+	}
+
 	for _, uri := range kmsURIs {
 		redactedURI, err := cloud.RedactKMSURI(uri)
 		if err != nil {
@@ -979,6 +1003,9 @@ func restoreJobDescription(
 	opts tree.RestoreOptions,
 	intoDB string,
 	newDBName string,
+	intoTable string,
+	spanFilter string,
+	sampleFraction float64,
 	kmsURIs []string,
 ) (string, error) {
 	r := &tree.Restore{
@@ -992,7 +1019,7 @@ func restoreJobDescription(
 	var options tree.RestoreOptions
 	var err error
 	if options, err = resolveOptionsForRestoreJobDescription(opts, intoDB, newDBName,
-		kmsURIs, incFrom); err != nil {
+		intoTable, spanFilter, sampleFraction, kmsURIs, incFrom); err != nil {
 		return "", err
 	}
 	r.Options = options
@@ -1106,6 +1133,61 @@ func restorePlanHook(
 		}
 	}
 
+	var intoTableFn func() (string, error)
+	if restoreStmt.Options.IntoTable != nil {
+		if err := checkSingleTableRestoreOption(restoreStmt, restoreOptIntoTable); err != nil {
+			return nil, nil, nil, false, err
+		}
+		intoTableFn, err = p.TypeAsString(ctx, restoreStmt.Options.IntoTable, "RESTORE")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	var spanFilterFn func() (string, error)
+	if restoreStmt.Options.SpanFilter != nil {
+		if err := checkSingleTableRestoreOption(restoreStmt, restoreOptSpanFilter); err != nil {
+			return nil, nil, nil, false, err
+		}
+		if restoreStmt.Options.IntoTable == nil {
+			return nil, nil, nil, false, errors.Newf(
+				"%s can only be used together with the %s option", restoreOptSpanFilter, restoreOptIntoTable)
+		}
+		spanFilterFn, err = p.TypeAsString(ctx, restoreStmt.Options.SpanFilter, "RESTORE")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
+	if restoreStmt.Options.SchemaOnly {
+		if restoreStmt.SystemUsers {
+			return nil, nil, nil, false, errors.Newf(
+				"cannot set %s option when only restoring system users", restoreOptSchemaOnly)
+		}
+		if restoreStmt.Options.SpanFilter != nil || restoreStmt.Options.SampleFraction != nil {
+			return nil, nil, nil, false, errors.Newf(
+				"%s cannot be used together with the %s or %s options",
+				restoreOptSchemaOnly, restoreOptSpanFilter, restoreOptSampleFraction)
+		}
+	}
+
+	var sampleFractionFn func() (string, error)
+	if restoreStmt.Options.SampleFraction != nil {
+		if restoreStmt.SystemUsers || restoreStmt.Targets.Tenant != (roachpb.TenantID{}) {
+			return nil, nil, nil, false, errors.Newf(
+				"%s can only be used when restoring tables, databases or a full cluster",
+				restoreOptSampleFraction)
+		}
+		if restoreStmt.Options.SpanFilter != nil {
+			return nil, nil, nil, false, errors.Newf(
+				"%s cannot be used together with the %s option", restoreOptSampleFraction, restoreOptSpanFilter)
+		}
+		sampleFractionFn, err = p.TypeAsString(ctx, restoreStmt.Options.SampleFraction, "RESTORE")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		// TODO(dan): Move this span into sql.
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
@@ -1173,6 +1255,33 @@ func restorePlanHook(
 			}
 		}
 
+		var intoTable string
+		if intoTableFn != nil {
+			intoTable, err = intoTableFn()
+			if err != nil {
+				return err
+			}
+		}
+
+		var spanFilter string
+		if spanFilterFn != nil {
+			spanFilter, err = spanFilterFn()
+			if err != nil {
+				return err
+			}
+		}
+
+		var sampleFraction float64
+		if sampleFractionFn != nil {
+			s, err := sampleFractionFn()
+			if err != nil {
+				return err
+			}
+			if sampleFraction, err = parseRestoreSampleFraction(s); err != nil {
+				return err
+			}
+		}
+
 		// incFrom will contain the directory URIs for incremental backups (i.e.
 		// <prefix>/<subdir>) iff len(From)==1, regardless of the
 		// 'incremental_location' param. len(From)=1 implies that the user has not
@@ -1222,7 +1331,7 @@ func restorePlanHook(
 		}
 
 		return doRestorePlan(ctx, restoreStmt, p, from, incFrom, passphrase, kms, intoDB,
-			newDBName, endTime, resultsCh)
+			newDBName, intoTable, spanFilter, sampleFraction, endTime, resultsCh)
 	}
 
 	if restoreStmt.Options.Detached {
@@ -1346,6 +1455,9 @@ func doRestorePlan(
 	kms []string,
 	intoDB string,
 	newDBName string,
+	intoTable string,
+	spanFilter string,
+	sampleFraction float64,
 	endTime hlc.Timestamp,
 	resultsCh chan<- tree.Datums,
 ) error {
@@ -1607,6 +1719,35 @@ func doRestorePlan(
 		return err
 	}
 
+	var spanFilterSpans roachpb.Spans
+	if restoreStmt.Options.IntoTable != nil {
+		if len(filteredTablesByID) != 1 {
+			return errors.Newf("%s can only be used when restoring a single table, found %d tables",
+				restoreOptIntoTable, len(filteredTablesByID))
+		}
+		for _, table := range filteredTablesByID {
+			table.SetName(intoTable)
+			if restoreStmt.Options.SpanFilter != nil {
+				backupCodec, err := backupCodecForManifest(mainBackupManifests[len(mainBackupManifests)-1])
+				if err != nil {
+					return err
+				}
+				spanFilterSpans, err = spansForSpanFilter(ctx, p.SemaCtx(),
+					&p.ExtendedEvalContext().EvalContext, backupCodec, table, spanFilter)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if sampleFraction > 0 {
+		for _, table := range filteredTablesByID {
+			if shouldSampleRestoredData(table) {
+				prepareTableForSampledRestore(table)
+			}
+		}
+	}
+
 	// When running a full cluster restore, we drop the defaultdb and postgres
 	// databases that are present in a new cluster.
 	// This is done so that they can be restored the same way any other user
@@ -1633,9 +1774,13 @@ func doRestorePlan(
 	if err != nil {
 		return err
 	}
+	if restoreStmt.Options.IntoTable != nil {
+		for id := range filteredTablesByID {
+			descriptorRewrites[id].NewTableName = intoTable
+		}
+	}
 	description, err := restoreJobDescription(p, restoreStmt, from, incFrom, restoreStmt.Options,
-		intoDB,
-		newDBName, kms)
+		intoDB, newDBName, intoTable, spanFilter, sampleFraction, kms)
 	if err != nil {
 		return err
 	}
@@ -1713,6 +1858,9 @@ func doRestorePlan(
 			DatabaseModifiers:  databaseModifiers,
 			DebugPauseOn:       debugPauseOn,
 			RestoreSystemUsers: restoreStmt.SystemUsers,
+			SpanFilterSpans:    spanFilterSpans,
+			SchemaOnly:         restoreStmt.Options.SchemaOnly,
+			SampleFraction:     sampleFraction,
 		},
 		Progress: jobspb.RestoreProgress{},
 	}
@@ -1770,6 +1918,35 @@ func doRestorePlan(
 	return sj.ReportExecutionResults(ctx, resultsCh)
 }
 
+// checkSingleTableRestoreOption returns an error if the given option, which
+// only makes sense when restoring a single table, is used with a RESTORE of
+// databases, tenants, system users or a full cluster.
+func checkSingleTableRestoreOption(restoreStmt *tree.Restore, opt string) error {
+	if restoreStmt.DescriptorCoverage == tree.AllDescriptors ||
+		restoreStmt.SystemUsers ||
+		len(restoreStmt.Targets.Databases) != 0 ||
+		restoreStmt.Targets.Tenant != (roachpb.TenantID{}) ||
+		len(restoreStmt.Targets.Tables) != 1 {
+		return errors.Newf("%s can only be used for RESTORE TABLE with a single target table", opt)
+	}
+	return nil
+}
+
+// backupCodecForManifest returns the codec that was used to encode the keys in
+// the backup, i.e. the codec of the tenant in which the backup was taken.
+func backupCodecForManifest(manifest BackupManifest) (keys.SQLCodec, error) {
+	if len(manifest.Spans) == 0 || manifest.HasTenants() {
+		return keys.SystemSQLCodec, nil
+	}
+	// If there are no tenant targets, then the entire keyspace covered by Spans
+	// must lie in 1 tenant.
+	_, backupTenantID, err := keys.DecodeTenantPrefix(manifest.Spans[0].Key)
+	if err != nil {
+		return keys.SQLCodec{}, err
+	}
+	return keys.MakeSQLCodec(backupTenantID), nil
+}
+
 func filteredUserCreatedDescriptors(
 	allDescs []catalog.Descriptor,
 ) (userDescs []catalog.Descriptor) {
@@ -47,6 +47,8 @@ func distRestore(
 	tableRekeys []execinfrapb.TableRekey,
 	tenantRekeys []execinfrapb.TenantRekey,
 	restoreTime hlc.Timestamp,
+	sampleFraction float64,
+	sampleSpans []roachpb.Span,
 	progCh chan *execinfrapb.RemoteProducerMetadata_BulkProcessorProgress,
 ) error {
 	ctx = logtags.AddTag(ctx, "restore-distsql", nil)
@@ -89,11 +91,13 @@ func distRestore(
 	}
 
 	restoreDataSpec := execinfrapb.RestoreDataSpec{
-		RestoreTime:  restoreTime,
-		Encryption:   fileEncryption,
-		TableRekeys:  tableRekeys,
-		TenantRekeys: tenantRekeys,
-		PKIDs:        pkIDs,
+		RestoreTime:    restoreTime,
+		Encryption:     fileEncryption,
+		TableRekeys:    tableRekeys,
+		TenantRekeys:   tenantRekeys,
+		PKIDs:          pkIDs,
+		SampleFraction: sampleFraction,
+		SampleSpans:    sampleSpans,
 	}
 
 	if len(splitAndScatterSpecs) == 0 {
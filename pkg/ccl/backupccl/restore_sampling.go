@@ -0,0 +1,109 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/errors"
+)
+
+// parseRestoreSampleFraction parses the value of the sample_fraction option,
+// which must be a number in (0, 1].
+func parseRestoreSampleFraction(s string) (float64, error) {
+	fraction, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(fraction) || fraction <= 0 || fraction > 1 {
+		return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s must be a number greater than 0 and at most 1, found %q", restoreOptSampleFraction, s)
+	}
+	return fraction, nil
+}
+
+// shouldSampleRestoredData returns whether the data of the table is sampled
+// when restoring with the sample_fraction option. Sequences and the system
+// tables of a cluster restore are always restored in full.
+func shouldSampleRestoredData(table catalog.TableDescriptor) bool {
+	return !table.IsSequence() && table.GetParentID() != keys.SystemDatabaseID
+}
+
+// restoresDataWithSchemaOnly returns whether the data of the table is restored
+// when restoring with the schema_only option. The value of a sequence is part
+// of its schema, and the system tables of a cluster restore hold the cluster's
+// metadata, e.g. its users and zone configurations, so their data is restored.
+func restoresDataWithSchemaOnly(table catalog.TableDescriptor) bool {
+	return table.IsSequence() || table.GetParentID() == keys.SystemDatabaseID
+}
+
+// prepareTableForSampledRestore modifies a table restored with a
+// sample_fraction so that it is consistent with a sample of its rows: its
+// foreign key constraints, which may reference rows that were not sampled, are
+// marked as unvalidated. Its secondary indexes are rebuilt from the sampled
+// rows once the table is published.
+func prepareTableForSampledRestore(table *tabledesc.Mutable) {
+	for i := range table.OutboundFKs {
+		table.OutboundFKs[i].Validity = descpb.ConstraintValidity_Unvalidated
+	}
+}
+
+// withoutSecondaryIndexes returns a copy of the table without its secondary
+// indexes, which is used to compute the spans restored for a table whose
+// secondary indexes are backfilled rather than restored.
+func withoutSecondaryIndexes(table *tabledesc.Mutable) catalog.TableDescriptor {
+	primaryOnly := tabledesc.NewBuilder(table.TableDesc()).BuildCreatedMutableTable()
+	primaryOnly.Indexes = nil
+	return primaryOnly
+}
+
+// restoreSampler decides which keys are restored when restoring approximately
+// a fraction of the rows in a set of spans. Keys outside of these spans are
+// always restored.
+//
+// A key is kept based on a hash of the prefix of its row, in the keyspace of
+// the backup, so that all the column families of a row are kept or dropped
+// together. The sample is thus deterministic: restoring the same backup with
+// the same fraction restores the same rows, including when the job is resumed
+// or when the backup's files are split differently into restore spans, and a
+// smaller fraction restores a subset of the rows of a larger one.
+type restoreSampler struct {
+	spans     roachpb.SpanGroup
+	threshold uint64
+}
+
+func makeRestoreSampler(fraction float64, spans []roachpb.Span) restoreSampler {
+	var s restoreSampler
+	if fraction <= 0 || fraction >= 1 {
+		return s
+	}
+	s.spans.Add(spans...)
+	s.threshold = uint64(fraction * math.MaxUint64)
+	return s
+}
+
+// keep returns whether the key, in the keyspace of the backup, is restored.
+func (s *restoreSampler) keep(key roachpb.Key) (bool, error) {
+	if s.spans.Len() == 0 || !s.spans.Contains(key) {
+		return true, nil
+	}
+	row, err := keys.EnsureSafeSplitKey(key)
+	if err != nil {
+		return false, errors.Wrapf(err, "sampling key %s", key)
+	}
+	sum := sha256.Sum256(row)
+	return binary.BigEndian.Uint64(sum[:8]) <= s.threshold, nil
+}
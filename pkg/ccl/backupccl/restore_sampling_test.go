@@ -0,0 +1,122 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRestoreSampleFraction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	for _, tc := range []struct {
+		in       string
+		expected float64
+		err      bool
+	}{
+		{in: "0.1", expected: 0.1},
+		{in: "1", expected: 1},
+		{in: "1e-3", expected: 0.001},
+		{in: "0", err: true},
+		{in: "-0.5", err: true},
+		{in: "1.5", err: true},
+		{in: "NaN", err: true},
+		{in: "half", err: true},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			fraction, err := parseRestoreSampleFraction(tc.in)
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, fraction)
+		})
+	}
+}
+
+func TestRestoreSampler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	codec := keys.SystemSQLCodec
+	const tableID, numRows = 52, 1000
+	rowKey := func(i int) roachpb.Key {
+		return encoding.EncodeVarintAscending(codec.IndexPrefix(tableID, 1), int64(i))
+	}
+	// familyKey returns the key of the given column family of the i-th row.
+	familyKey := func(i int, family uint32) roachpb.Key {
+		return keys.MakeFamilyKey(rowKey(i), family)
+	}
+	prefix := codec.TablePrefix(tableID)
+	sampleSpans := []roachpb.Span{{Key: prefix, EndKey: prefix.PrefixEnd()}}
+	// sample returns the rows kept when sampling the given fraction.
+	sample := func(fraction float64) map[int]bool {
+		s := makeRestoreSampler(fraction, sampleSpans)
+		kept := make(map[int]bool)
+		for i := 0; i < numRows; i++ {
+			ok, err := s.keep(familyKey(i, 0))
+			require.NoError(t, err)
+			if ok {
+				kept[i] = true
+			}
+		}
+		return kept
+	}
+
+	t.Run("all", func(t *testing.T) {
+		require.Len(t, sample(1), numRows)
+	})
+
+	t.Run("fraction", func(t *testing.T) {
+		kept := sample(0.1)
+		require.InDelta(t, numRows/10, len(kept), numRows/20)
+		// The sample is deterministic.
+		require.Equal(t, kept, sample(0.1))
+	})
+
+	t.Run("column families", func(t *testing.T) {
+		s := makeRestoreSampler(0.5, sampleSpans)
+		for i := 0; i < numRows; i++ {
+			first, err := s.keep(familyKey(i, 0))
+			require.NoError(t, err)
+			for _, family := range []uint32{1, 7} {
+				ok, err := s.keep(familyKey(i, family))
+				require.NoError(t, err)
+				require.Equal(t, first, ok, "row %d family %d", i, family)
+			}
+		}
+	})
+
+	t.Run("smaller fraction is a subset", func(t *testing.T) {
+		larger := sample(0.5)
+		for i := range sample(0.2) {
+			require.True(t, larger[i], "row %d", i)
+		}
+	})
+
+	t.Run("outside of sampled spans", func(t *testing.T) {
+		s := makeRestoreSampler(0.01, sampleSpans)
+		for i := 0; i < numRows; i++ {
+			key := keys.MakeFamilyKey(
+				encoding.EncodeVarintAscending(codec.IndexPrefix(tableID+1, 1), int64(i)), 0)
+			ok, err := s.keep(key)
+			require.NoError(t, err)
+			require.True(t, ok)
+		}
+	})
+}
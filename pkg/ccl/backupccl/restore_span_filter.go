@@ -0,0 +1,294 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// spanFilterBound is one end of the range constraint placed on a primary key
+// column by a span_filter predicate.
+type spanFilterBound struct {
+	datum     tree.Datum
+	inclusive bool
+}
+
+// spanFilterConstraint collects the constraints that a span_filter predicate
+// places on a single primary key column.
+type spanFilterConstraint struct {
+	eq           tree.Datum
+	lower, upper *spanFilterBound
+}
+
+// spansForSpanFilter returns the spans of the primary index of table, encoded
+// with codec, that hold every row satisfying the span_filter predicate. The
+// predicate must be a conjunction of comparisons between primary key columns
+// and constants: equalities on a prefix of the primary key columns, optionally
+// followed by range constraints (<, <=, >, >=, BETWEEN) on the next column.
+//
+// The returned spans are a superset of the rows matching the predicate, but
+// never miss a matching row, so they can be used to restore just enough of a
+// table to answer a primary-key-bounded query.
+func spansForSpanFilter(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	codec keys.SQLCodec,
+	table catalog.TableDescriptor,
+	filter string,
+) (roachpb.Spans, error) {
+	expr, err := parser.ParseExpr(filter)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", restoreOptSpanFilter)
+	}
+
+	constraints := make(map[tree.Name]*spanFilterConstraint)
+	var collect func(e tree.Expr) error
+	collect = func(e tree.Expr) error {
+		switch t := e.(type) {
+		case *tree.ParenExpr:
+			return collect(t.Expr)
+		case *tree.AndExpr:
+			if err := collect(t.Left); err != nil {
+				return err
+			}
+			return collect(t.Right)
+		case *tree.ComparisonExpr:
+			return addSpanFilterComparison(ctx, semaCtx, evalCtx, table, constraints, t)
+		case *tree.RangeCond:
+			if t.Not || t.Symmetric {
+				break
+			}
+			if err := addSpanFilterComparison(ctx, semaCtx, evalCtx, table, constraints,
+				&tree.ComparisonExpr{
+					Operator: treecmp.MakeComparisonOperator(treecmp.GE), Left: t.Left, Right: t.From,
+				}); err != nil {
+				return err
+			}
+			return addSpanFilterComparison(ctx, semaCtx, evalCtx, table, constraints,
+				&tree.ComparisonExpr{
+					Operator: treecmp.MakeComparisonOperator(treecmp.LE), Left: t.Left, Right: t.To,
+				})
+		}
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s only supports conjunctions of comparisons between primary key columns and constants, found %s",
+			restoreOptSpanFilter, tree.AsString(e))
+	}
+	if err := collect(expr); err != nil {
+		return nil, err
+	}
+
+	primary := table.GetPrimaryIndex()
+	prefix := roachpb.Key(codec.IndexPrefix(uint32(table.GetID()), uint32(primary.GetID())))
+	startKey, endKey := prefix, prefix.PrefixEnd()
+	var usedConstraints int
+	for i := 0; i < primary.NumKeyColumns(); i++ {
+		col, err := table.FindColumnWithID(primary.GetKeyColumnID(i))
+		if err != nil {
+			return nil, err
+		}
+		c, ok := constraints[tree.Name(col.GetName())]
+		if !ok {
+			break
+		}
+		usedConstraints++
+		dir, err := primary.GetKeyColumnDirection(i).ToEncodingDirection()
+		if err != nil {
+			return nil, err
+		}
+		if c.eq != nil {
+			if c.lower != nil || c.upper != nil {
+				return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+					"%s cannot constrain column %q with both an equality and a range",
+					restoreOptSpanFilter, col.GetName())
+			}
+			key, err := keyside.Encode(prefix, c.eq, dir)
+			if err != nil {
+				return nil, err
+			}
+			prefix = roachpb.Key(key)
+			startKey, endKey = prefix, prefix.PrefixEnd()
+			continue
+		}
+
+		// A range constraint ends the usable prefix of the primary key. A
+		// descending column stores the value range in reverse key order, so the
+		// lower bound of the values becomes the upper bound of the keys.
+		keyLower, keyUpper := c.lower, c.upper
+		if dir == encoding.Descending {
+			keyLower, keyUpper = c.upper, c.lower
+		}
+		if keyLower != nil {
+			key, err := keyside.Encode(prefix, keyLower.datum, dir)
+			if err != nil {
+				return nil, err
+			}
+			startKey = roachpb.Key(key)
+			if !keyLower.inclusive {
+				startKey = startKey.PrefixEnd()
+			}
+		}
+		if keyUpper != nil {
+			key, err := keyside.Encode(prefix, keyUpper.datum, dir)
+			if err != nil {
+				return nil, err
+			}
+			endKey = roachpb.Key(key)
+			if keyUpper.inclusive {
+				endKey = endKey.PrefixEnd()
+			}
+		}
+		break
+	}
+	if usedConstraints != len(constraints) {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s must constrain a prefix of the primary key columns of table %q",
+			restoreOptSpanFilter, table.GetName())
+	}
+	if usedConstraints == 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s must constrain at least one primary key column of table %q",
+			restoreOptSpanFilter, table.GetName())
+	}
+	if startKey.Compare(endKey) >= 0 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s %q cannot match any rows", restoreOptSpanFilter, filter)
+	}
+	return roachpb.Spans{{Key: startKey, EndKey: endKey}}, nil
+}
+
+// addSpanFilterComparison records the constraint that the comparison places on
+// a primary key column of table.
+func addSpanFilterComparison(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+	table catalog.TableDescriptor,
+	constraints map[tree.Name]*spanFilterConstraint,
+	cmp *tree.ComparisonExpr,
+) error {
+	op := cmp.Operator.Symbol
+	left, right := cmp.Left, cmp.Right
+	if _, ok := left.(*tree.UnresolvedName); !ok {
+		// Normalize `constant op column` to `column op' constant`.
+		left, right = right, left
+		switch op {
+		case treecmp.LT:
+			op = treecmp.GT
+		case treecmp.LE:
+			op = treecmp.GE
+		case treecmp.GT:
+			op = treecmp.LT
+		case treecmp.GE:
+			op = treecmp.LE
+		}
+	}
+	name, ok := left.(*tree.UnresolvedName)
+	if !ok || name.NumParts != 1 || name.Star {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s comparisons must reference a single primary key column, found %s",
+			restoreOptSpanFilter, tree.AsString(cmp))
+	}
+	colName := tree.Name(name.Parts[0])
+	col, err := table.FindColumnWithName(colName)
+	if err != nil {
+		return err
+	}
+	if !table.GetPrimaryIndex().CollectKeyColumnIDs().Contains(col.GetID()) {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s can only reference primary key columns, %q is not part of the primary key of %q",
+			restoreOptSpanFilter, col.GetName(), table.GetName())
+	}
+	typed, err := tree.TypeCheckAndRequire(ctx, right, semaCtx, col.GetType(), restoreOptSpanFilter)
+	if err != nil {
+		return err
+	}
+	if !tree.IsConst(evalCtx, typed) {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s comparisons must be against constants, found %s",
+			restoreOptSpanFilter, tree.AsString(right))
+	}
+	datum, err := typed.Eval(evalCtx)
+	if err != nil {
+		return err
+	}
+	if datum == tree.DNull {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s cannot compare column %q to NULL", restoreOptSpanFilter, col.GetName())
+	}
+
+	c, ok := constraints[colName]
+	if !ok {
+		c = &spanFilterConstraint{}
+		constraints[colName] = c
+	}
+	// tighten narrows one side of the range, keeping the more restrictive of the
+	// existing and new bound.
+	tighten := func(existing **spanFilterBound, b *spanFilterBound, wantSmaller bool) {
+		if *existing == nil {
+			*existing = b
+			return
+		}
+		res := b.datum.Compare(evalCtx, (*existing).datum)
+		if (wantSmaller && res < 0) || (!wantSmaller && res > 0) ||
+			(res == 0 && !b.inclusive) {
+			*existing = b
+		}
+	}
+	switch op {
+	case treecmp.EQ:
+		if c.eq != nil && c.eq.Compare(evalCtx, datum) != 0 {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"%s constrains column %q to multiple values", restoreOptSpanFilter, col.GetName())
+		}
+		c.eq = datum
+	case treecmp.GT, treecmp.GE:
+		tighten(&c.lower, &spanFilterBound{datum: datum, inclusive: op == treecmp.GE}, false /* wantSmaller */)
+	case treecmp.LT, treecmp.LE:
+		tighten(&c.upper, &spanFilterBound{datum: datum, inclusive: op == treecmp.LE}, true /* wantSmaller */)
+	default:
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"%s does not support the %s operator", restoreOptSpanFilter, cmp.Operator)
+	}
+	return nil
+}
+
+// addSecondaryIndexesToBackfill adds the secondary indexes of a table restored
+// with a span_filter to the indexes that are rebuilt once the table is
+// published. Only a portion of the primary index is restored, so the data of
+// the secondary indexes is not restored: they are backfilled from the restored
+// rows by a schema change job instead, which also validates unique indexes.
+func addSecondaryIndexesToBackfill(
+	table catalog.TableDescriptor, indexes []descpb.IndexID,
+) []descpb.IndexID {
+	var seen util.FastIntSet
+	for _, id := range indexes {
+		seen.Add(int(id))
+	}
+	for _, idx := range table.PublicNonPrimaryIndexes() {
+		if !seen.Contains(int(idx.GetID())) {
+			indexes = append(indexes, idx.GetID())
+		}
+	}
+	return indexes
+}
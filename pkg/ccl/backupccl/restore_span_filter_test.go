@@ -0,0 +1,157 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpansForSpanFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	semaCtx := tree.MakeSemaContext()
+
+	// system.namespace has the primary key ("parentID", "parentSchemaID", name).
+	table := tabledesc.NewBuilder(systemschema.NamespaceTable.TableDesc()).BuildImmutableTable()
+	codec := keys.SystemSQLCodec
+	prefix := roachpb.Key(codec.IndexPrefix(uint32(table.GetID()), uint32(table.GetPrimaryIndexID())))
+	intKey := func(k roachpb.Key, vals ...int64) roachpb.Key {
+		for _, v := range vals {
+			k = encoding.EncodeVarintAscending(k, v)
+		}
+		return k
+	}
+	span := func(start, end roachpb.Key) roachpb.Spans {
+		return roachpb.Spans{{Key: start, EndKey: end}}
+	}
+
+	for _, tc := range []struct {
+		filter   string
+		expected roachpb.Spans
+		err      string
+	}{
+		{
+			filter:   `"parentID" = 1`,
+			expected: span(intKey(prefix, 1), intKey(prefix, 1).PrefixEnd()),
+		},
+		{
+			filter:   `"parentID" >= 10 AND "parentID" < 50`,
+			expected: span(intKey(prefix, 10), intKey(prefix, 50)),
+		},
+		{
+			filter:   `"parentID" BETWEEN 10 AND 50`,
+			expected: span(intKey(prefix, 10), intKey(prefix, 50).PrefixEnd()),
+		},
+		{
+			filter:   `10 < "parentID" AND "parentID" <= 20 AND "parentID" < 50`,
+			expected: span(intKey(prefix, 10).PrefixEnd(), intKey(prefix, 20).PrefixEnd()),
+		},
+		{
+			filter:   `"parentID" = 1 AND ("parentSchemaID" > 2)`,
+			expected: span(intKey(prefix, 1, 2).PrefixEnd(), intKey(prefix, 1).PrefixEnd()),
+		},
+		{
+			filter: `"parentSchemaID" = 1`,
+			err:    "must constrain a prefix of the primary key columns",
+		},
+		{
+			filter: `"parentID" > 1 AND "parentSchemaID" = 1`,
+			err:    "must constrain a prefix of the primary key columns",
+		},
+		{
+			filter: `id = 1`,
+			err:    `"id" is not part of the primary key`,
+		},
+		{
+			filter: `"parentID" = 1 OR "parentID" = 2`,
+			err:    "only supports conjunctions of comparisons",
+		},
+		{
+			filter: `"parentID" > 50 AND "parentID" < 10`,
+			err:    "cannot match any rows",
+		},
+		{
+			filter: `"parentID" = 'foo'`,
+			err:    "could not parse",
+		},
+	} {
+		t.Run(tc.filter, func(t *testing.T) {
+			spans, err := spansForSpanFilter(ctx, &semaCtx, &evalCtx, codec, table, tc.filter)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, spans)
+		})
+	}
+}
+
+func TestRestoreIntoTableWithSpanFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	_, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, 0 /* numAccounts */, InitManualReplication)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE TABLE data.t (a INT, b INT, c STRING, PRIMARY KEY (a, b), UNIQUE INDEX c_idx (c))`)
+	sqlDB.Exec(t, `INSERT INTO data.t SELECT x / 10, x % 10, 'v' || x::STRING FROM generate_series(0, 99) AS g(x)`)
+	var beforeUpdate string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&beforeUpdate)
+	sqlDB.Exec(t, `UPDATE data.t SET c = c || '-bad' WHERE a = 4`)
+	sqlDB.Exec(t, `BACKUP data.t TO $1 WITH revision_history`, localFoo)
+
+	t.Run("into_table", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(
+			`RESTORE data.t FROM $1 AS OF SYSTEM TIME %s WITH into_table = 't_full'`, beforeUpdate,
+		), localFoo)
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(*), count(*) FILTER (WHERE c LIKE '%-bad') FROM data.t_full`,
+			[][]string{{"100", "0"}})
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM data.t`, [][]string{{"100"}})
+	})
+
+	t.Run("span_filter", func(t *testing.T) {
+		sqlDB.Exec(t, fmt.Sprintf(
+			`RESTORE data.t FROM $1 AS OF SYSTEM TIME %s
+WITH into_table = 't_recovered', span_filter = 'a = 4 AND b >= 2 AND b < 5'`, beforeUpdate,
+		), localFoo)
+		sqlDB.CheckQueryResults(t, `SELECT a, b, c FROM data.t_recovered ORDER BY a, b`,
+			[][]string{{"4", "2", "v42"}, {"4", "3", "v43"}, {"4", "4", "v44"}})
+
+		// The unique secondary index is backfilled from the restored rows once the
+		// table is published.
+		sqlDB.Exec(t, `SHOW JOBS WHEN COMPLETE (
+  SELECT job_id FROM [SHOW JOBS] WHERE job_type = 'SCHEMA CHANGE' AND description LIKE 'RESTORING: %'
+)`)
+		sqlDB.CheckQueryResults(t, `SELECT a, b FROM data.t_recovered@c_idx WHERE c = 'v43'`,
+			[][]string{{"4", "3"}})
+		sqlDB.ExpectErr(t, `duplicate key value violates unique constraint "c_idx"`,
+			`INSERT INTO data.t_recovered VALUES (9, 9, 'v43')`)
+	})
+}
@@ -271,6 +271,12 @@ func (e *scheduledBackupExecutor) GetCreateScheduleStatement(
 			Value: tree.NewDString(wait),
 		},
 	}
+	if args.Retention != "" {
+		scheduleOptions = append(scheduleOptions, tree.KVOption{
+			Key:   optRetention,
+			Value: tree.NewDString(args.Retention),
+		})
+	}
 
 	var destinations []string
 	for i := range backupNode.To {
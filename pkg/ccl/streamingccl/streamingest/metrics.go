@@ -12,7 +12,11 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 var (
@@ -40,6 +44,25 @@ var (
 		Measurement: "Flushes",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaStreamingFrontierLagNanos = metric.Metadata{
+		Name: "streaming.frontier_lag_nanos",
+		Help: "Time between the wall clock and the replicated time of the stream ingestion job " +
+			"furthest behind on this node",
+		Measurement: "Nanoseconds",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaStreamingCutovers = metric.Metadata{
+		Name:        "streaming.cutovers",
+		Help:        "Stream ingestion jobs which completed a cutover",
+		Measurement: "Jobs",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaStreamingReverseReplicationsStarted = metric.Metadata{
+		Name:        "streaming.reverse_replications_started",
+		Help:        "Reverse replication streams started after a cutover",
+		Measurement: "Streams",
+		Unit:        metric.Unit_COUNT,
+	}
 )
 
 // Metrics are for production monitoring of stream ingestion jobs.
@@ -48,6 +71,18 @@ type Metrics struct {
 	IngestedBytes  *metric.Counter
 	Flushes        *metric.Counter
 	ResolvedEvents *metric.Counter
+	// FrontierLagNanos is the replication lag of the stream ingestion job
+	// furthest behind on this node.
+	FrontierLagNanos           *metric.Gauge
+	Cutovers                   *metric.Counter
+	ReverseReplicationsStarted *metric.Counter
+
+	frontiers struct {
+		syncutil.Mutex
+		// replicatedTime maps the stream ingestion jobs whose frontier processor
+		// is running on this node to their replicated time.
+		replicatedTime map[jobspb.JobID]hlc.Timestamp
+	}
 }
 
 // MetricStruct implements the metric.Struct interface.
@@ -56,14 +91,51 @@ func (*Metrics) MetricStruct() {}
 // MakeMetrics makes the metrics for stream ingestion job monitoring.
 func MakeMetrics(histogramWindow time.Duration) metric.Struct {
 	m := &Metrics{
-		IngestedEvents: metric.NewCounter(metaStreamingEventsIngested),
-		IngestedBytes:  metric.NewCounter(metaStreamingIngestedBytes),
-		Flushes:        metric.NewCounter(metaStreamingFlushes),
-		ResolvedEvents: metric.NewCounter(metaStreamingResolvedEventsIngested),
+		IngestedEvents:             metric.NewCounter(metaStreamingEventsIngested),
+		IngestedBytes:              metric.NewCounter(metaStreamingIngestedBytes),
+		Flushes:                    metric.NewCounter(metaStreamingFlushes),
+		ResolvedEvents:             metric.NewCounter(metaStreamingResolvedEventsIngested),
+		Cutovers:                   metric.NewCounter(metaStreamingCutovers),
+		ReverseReplicationsStarted: metric.NewCounter(metaStreamingReverseReplicationsStarted),
 	}
+	m.frontiers.replicatedTime = make(map[jobspb.JobID]hlc.Timestamp)
+	m.FrontierLagNanos = metric.NewFunctionalGauge(metaStreamingFrontierLagNanos, m.frontierLag)
 	return m
 }
 
+// updateReplicatedTime records the replicated time of a stream ingestion job
+// whose frontier processor runs on this node.
+func (m *Metrics) updateReplicatedTime(jobID jobspb.JobID, replicatedTime hlc.Timestamp) {
+	m.frontiers.Lock()
+	defer m.frontiers.Unlock()
+	m.frontiers.replicatedTime[jobID] = replicatedTime
+}
+
+// removeJob stops tracking the replicated time of a stream ingestion job, once
+// its frontier processor is no longer running on this node.
+func (m *Metrics) removeJob(jobID jobspb.JobID) {
+	m.frontiers.Lock()
+	defer m.frontiers.Unlock()
+	delete(m.frontiers.replicatedTime, jobID)
+}
+
+// frontierLag returns the time between now and the earliest replicated time of
+// the stream ingestion jobs tracked on this node, or 0 if there are none.
+func (m *Metrics) frontierLag() int64 {
+	m.frontiers.Lock()
+	defer m.frontiers.Unlock()
+	var earliest hlc.Timestamp
+	for _, ts := range m.frontiers.replicatedTime {
+		if earliest.IsEmpty() || ts.Less(earliest) {
+			earliest = ts
+		}
+	}
+	if earliest.IsEmpty() {
+		return 0
+	}
+	return timeutil.Since(earliest.GoTime()).Nanoseconds()
+}
+
 func init() {
 	jobs.MakeStreamIngestMetricsHook = MakeMetrics
 }
@@ -61,6 +61,9 @@ type streamIngestionFrontier struct {
 
 	lastPartitionUpdate time.Time
 	partitionProgress   map[string]jobspb.StreamIngestionProgress_PartitionProgress
+
+	// metrics are monitoring counters shared between all ingestion jobs.
+	metrics *Metrics
 }
 
 var _ execinfra.Processor = &streamIngestionFrontier{}
@@ -100,6 +103,10 @@ func newStreamIngestionFrontierProcessor(
 		nil, /* memMonitor */
 		execinfra.ProcStateOpts{
 			InputsToDrain: []execinfra.RowSource{sf.input},
+			TrailingMetaCallback: func() []execinfrapb.ProducerMetadata {
+				sf.close()
+				return nil
+			},
 		},
 	); err != nil {
 		return nil, err
@@ -115,9 +122,30 @@ func (sf *streamIngestionFrontier) MustBeStreaming() bool {
 // Start is part of the RowSource interface.
 func (sf *streamIngestionFrontier) Start(ctx context.Context) {
 	ctx = sf.StartInternal(ctx, streamIngestionFrontierProcName)
+	sf.metrics = sf.flowCtx.Cfg.JobRegistry.MetricsStruct().StreamIngest.(*Metrics)
+	if !sf.highWaterAtStart.IsEmpty() {
+		// Track the lag from the start, so that it is reported even if the stream
+		// never makes progress.
+		sf.metrics.updateReplicatedTime(jobspb.JobID(sf.spec.JobID), sf.highWaterAtStart)
+	}
 	sf.input.Start(ctx)
 }
 
+// ConsumerClosed is part of the RowSource interface.
+func (sf *streamIngestionFrontier) ConsumerClosed() {
+	sf.close()
+}
+
+func (sf *streamIngestionFrontier) close() {
+	if sf.Closed {
+		return
+	}
+	if sf.metrics != nil {
+		sf.metrics.removeJob(jobspb.JobID(sf.spec.JobID))
+	}
+	sf.InternalClose()
+}
+
 // Next is part of the RowSource interface.
 func (sf *streamIngestionFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.ProducerMetadata) {
 	for sf.State == execinfra.StateRunning {
@@ -147,6 +175,7 @@ func (sf *streamIngestionFrontier) Next() (rowenc.EncDatumRow, *execinfrapb.Prod
 		if frontierChanged {
 			// Send back a row to the job so that it can update the progress.
 			newResolvedTS := sf.frontier.Frontier()
+			sf.metrics.updateReplicatedTime(jobspb.JobID(sf.spec.JobID), newResolvedTS)
 			progressBytes, err := protoutil.Marshal(&newResolvedTS)
 			if err != nil {
 				sf.MoveToDraining(err)
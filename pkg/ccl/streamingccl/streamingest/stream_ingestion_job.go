@@ -10,6 +10,8 @@ package streamingest
 
 import (
 	"context"
+	gosql "database/sql"
+	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/streamingccl/streamclient"
@@ -20,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
 
@@ -85,12 +88,51 @@ func ingest(
 
 // Resume is part of the jobs.Resumer interface.
 func (s *streamIngestionResumer) Resume(resumeCtx context.Context, execCtx interface{}) error {
-	details := s.job.Details().(jobspb.StreamIngestionDetails)
 	p := execCtx.(sql.JobExecContext)
+	metrics := p.ExecCfg().JobRegistry.MetricsStruct().StreamIngest.(*Metrics)
+
+	// A job which is resumed after it has cut over only has the reverse
+	// replication left to start, if any.
+	if !s.job.Progress().GetStreamIngest().CutoverComplete {
+		if err := s.ingestAndCutover(resumeCtx, p, metrics); err != nil {
+			return err
+		}
+	}
+
+	// The cluster the job was ingesting from is typically unavailable when
+	// failing over to this cluster, so a failure to start the reverse
+	// replication does not fail the job, which has already left this cluster
+	// in a consistent state.
+	if err := s.maybeStartReverseReplication(resumeCtx, p, metrics); err != nil {
+		log.Warningf(resumeCtx, "failed to start reverse replication of tenant %s: %v",
+			s.job.Details().(jobspb.StreamIngestionDetails).TenantID, err)
+	}
+	return nil
+}
+
+// ingestAndCutover ingests the replication stream until the job is signaled
+// to cutover, and then reverts to the cutover time and marks the cutover as
+// complete.
+func (s *streamIngestionResumer) ingestAndCutover(
+	ctx context.Context, p sql.JobExecContext, metrics *Metrics,
+) error {
+	details := s.job.Details().(jobspb.StreamIngestionDetails)
+
+	// A job replicating the tenant back into its former primary starts by
+	// reverting its span to the start time, i.e. the cutover time of the new
+	// primary, to roll back the writes the cluster accepted after it. This is
+	// only done before any progress has been recorded.
+	if details.ReverseReplication && !details.StartTime.IsEmpty() {
+		if h := s.job.Progress().GetHighWater(); h == nil || h.IsEmpty() {
+			if err := revertSpanToTimestamp(ctx, p.ExecCfg().DB, details.Span, details.StartTime); err != nil {
+				return errors.Wrap(err, "reverting to the start time of the stream")
+			}
+		}
+	}
 
 	// Start ingesting KVs from the replication stream.
 	streamAddress := streamingccl.StreamAddress(details.StreamAddress)
-	err := ingest(resumeCtx, p, streamAddress, details.TenantID, details.StartTime, s.job.Progress(), s.job.ID())
+	err := ingest(ctx, p, streamAddress, details.TenantID, details.StartTime, s.job.Progress(), s.job.ID())
 	if err != nil {
 		return err
 	}
@@ -99,7 +141,28 @@ func (s *streamIngestionResumer) Resume(resumeCtx context.Context, execCtx inter
 	// processors shut down gracefully, i.e stopped ingesting any additional
 	// events from the replication stream. At this point it is safe to revert to
 	// the cutoff time to leave the cluster in a consistent state.
-	return s.revertToCutoverTimestamp(resumeCtx, execCtx)
+	if err := s.revertToCutoverTimestamp(ctx, p); err != nil {
+		return err
+	}
+	if err := s.updateStreamIngestProgress(ctx, func(progress *jobspb.StreamIngestionProgress) {
+		progress.CutoverComplete = true
+	}); err != nil {
+		return err
+	}
+	metrics.Cutovers.Inc()
+	return nil
+}
+
+// updateStreamIngestProgress updates the stream ingestion progress of the job,
+// leaving its high-water mark untouched.
+func (s *streamIngestionResumer) updateStreamIngestProgress(
+	ctx context.Context, fn func(progress *jobspb.StreamIngestionProgress),
+) error {
+	return s.job.Update(ctx, nil /* txn */, func(_ *kv.Txn, md jobs.JobMetadata, ju *jobs.JobUpdater) error {
+		fn(md.Progress.GetStreamIngest())
+		ju.UpdateProgress(md.Progress)
+		return nil
+	})
 }
 
 // revertToCutoverTimestamp reads the job progress for the cutover time and
@@ -109,31 +172,49 @@ func (s *streamIngestionResumer) revertToCutoverTimestamp(
 	ctx context.Context, execCtx interface{},
 ) error {
 	p := execCtx.(sql.JobExecContext)
-	db := p.ExecCfg().DB
-	j, err := p.ExecCfg().JobRegistry.LoadJob(ctx, s.job.ID())
+	sd, sp, err := s.loadDetailsAndProgress(ctx, p)
 	if err != nil {
 		return err
 	}
+
+	if sp.StreamIngest.CutoverTime.IsEmpty() {
+		return errors.AssertionFailedf("cutover time is unexpectedly empty, " +
+			"cannot revert to a consistent state")
+	}
+
+	return revertSpanToTimestamp(ctx, p.ExecCfg().DB, sd.Span, sp.StreamIngest.CutoverTime)
+}
+
+// loadDetailsAndProgress loads the latest details and progress of the job.
+func (s *streamIngestionResumer) loadDetailsAndProgress(
+	ctx context.Context, p sql.JobExecContext,
+) (jobspb.StreamIngestionDetails, *jobspb.Progress_StreamIngest, error) {
+	j, err := p.ExecCfg().JobRegistry.LoadJob(ctx, s.job.ID())
+	if err != nil {
+		return jobspb.StreamIngestionDetails{}, nil, err
+	}
 	details := j.Details()
 	var sd jobspb.StreamIngestionDetails
 	var ok bool
 	if sd, ok = details.(jobspb.StreamIngestionDetails); !ok {
-		return errors.Newf("unknown details type %T in stream ingestion job %d",
-			details, s.job.ID())
+		return jobspb.StreamIngestionDetails{}, nil, errors.Newf(
+			"unknown details type %T in stream ingestion job %d", details, s.job.ID())
 	}
 	progress := j.Progress()
 	var sp *jobspb.Progress_StreamIngest
 	if sp, ok = progress.GetDetails().(*jobspb.Progress_StreamIngest); !ok {
-		return errors.Newf("unknown progress type %T in stream ingestion job %d",
-			j.Progress().Progress, s.job.ID())
-	}
-
-	if sp.StreamIngest.CutoverTime.IsEmpty() {
-		return errors.AssertionFailedf("cutover time is unexpectedly empty, " +
-			"cannot revert to a consistent state")
+		return jobspb.StreamIngestionDetails{}, nil, errors.Newf(
+			"unknown progress type %T in stream ingestion job %d", j.Progress().Progress, s.job.ID())
 	}
+	return sd, sp, nil
+}
 
-	spans := []roachpb.Span{sd.Span}
+// revertSpanToTimestamp issues RevertRangeRequests to revert the span to its
+// state as of the target time.
+func revertSpanToTimestamp(
+	ctx context.Context, db *kv.DB, span roachpb.Span, targetTime hlc.Timestamp,
+) error {
+	spans := []roachpb.Span{span}
 	for len(spans) != 0 {
 		var b kv.Batch
 		for _, span := range spans {
@@ -142,7 +223,7 @@ func (s *streamIngestionResumer) revertToCutoverTimestamp(
 					Key:    span.Key,
 					EndKey: span.EndKey,
 				},
-				TargetTime:                          sp.StreamIngest.CutoverTime,
+				TargetTime:                          targetTime,
 				EnableTimeBoundIteratorOptimization: true,
 			})
 		}
@@ -166,6 +247,63 @@ func (s *streamIngestionResumer) revertToCutoverTimestamp(
 	return nil
 }
 
+// maybeStartReverseReplication starts replicating the tenant back into the
+// cluster the job was ingesting from, if it was requested along with the
+// cutover. A stream ingestion job is created in the source cluster with the
+// reverse_replication option, which consumes a replication stream of the
+// tenant from this cluster starting at the cutover time, i.e. the former
+// primary for the tenant becomes its standby.
+func (s *streamIngestionResumer) maybeStartReverseReplication(
+	ctx context.Context, p sql.JobExecContext, metrics *Metrics,
+) error {
+	details, sp, err := s.loadDetailsAndProgress(ctx, p)
+	if err != nil {
+		return err
+	}
+	progress := sp.StreamIngest
+	if progress.ReverseStreamAddress == "" || progress.ReverseIngestionJobID != 0 {
+		return nil
+	}
+
+	sourceURL, err := streamingccl.StreamAddress(details.StreamAddress).URL()
+	if err != nil {
+		return err
+	}
+	// The tenant ID parameter is only meaningful to the stream client.
+	q := sourceURL.Query()
+	q.Del(streamclient.TenantID)
+	sourceURL.RawQuery = q.Encode()
+	db, err := gosql.Open("postgres", sourceURL.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "connecting to the source cluster")
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, `SET enable_experimental_stream_replication = true`); err != nil {
+		return err
+	}
+	var reverseJobID jobspb.JobID
+	if err := conn.QueryRowContext(ctx, fmt.Sprintf(
+		`RESTORE TENANT %d FROM REPLICATION STREAM FROM $1 AS OF SYSTEM TIME %s WITH %s`,
+		details.TenantID.ToUint64(), progress.CutoverTime.AsOfSystemTime(), optReverseReplication),
+		progress.ReverseStreamAddress,
+	).Scan(&reverseJobID); err != nil {
+		return errors.Wrap(err, "starting reverse replication in the source cluster")
+	}
+	metrics.ReverseReplicationsStarted.Inc()
+	log.Infof(ctx, "started reverse replication of tenant %s in the source cluster as job %d",
+		details.TenantID, reverseJobID)
+
+	return s.updateStreamIngestProgress(ctx, func(progress *jobspb.StreamIngestionProgress) {
+		progress.ReverseIngestionJobID = reverseJobID
+	})
+}
+
 // OnFailOrCancel is part of the jobs.Resumer interface.
 // There is a know race between the ingestion processors shutting down, and
 // OnFailOrCancel being invoked. As a result of which we might see some keys
@@ -29,6 +29,16 @@ import (
 	"github.com/cockroachdb/errors"
 )
 
+const (
+	// optReverseReplication marks the ingestion as replicating the tenant back
+	// into a cluster which was its primary before a cutover.
+	optReverseReplication = "reverse_replication"
+)
+
+var streamIngestionOptionExpectValues = map[string]sql.KVStringOptValidate{
+	optReverseReplication: sql.KVStringOptRequireNoValue,
+}
+
 func streamIngestionJobDescription(
 	p sql.PlanHookState, streamIngestion *tree.StreamIngestion,
 ) (string, error) {
@@ -63,6 +73,11 @@ func ingestionPlanHook(
 		return nil, nil, nil, false, err
 	}
 
+	optsFn, err := p.TypeAsStringOpts(ctx, ingestionStmt.Options, streamIngestionOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
 	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
 		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
 		defer span.Finish()
@@ -79,6 +94,12 @@ func ingestionPlanHook(
 			return err
 		}
 
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+		_, reverseReplication := opts[optReverseReplication]
+
 		// We only support a TENANT target, so error out if that is nil.
 		if ingestionStmt.Targets.Tenant == (roachpb.TenantID{}) {
 			return errors.Newf("no tenant specified in ingestion query: %s", ingestionStmt.String())
@@ -111,12 +132,19 @@ func ingestionPlanHook(
 			}
 			startTime = asOf.Timestamp
 		}
+		// Reverse replication reverts the tenant to the start time before
+		// ingesting, so it has to be given explicitly.
+		if reverseReplication && ingestionStmt.AsOf.Expr == nil {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"%s requires AS OF SYSTEM TIME", optReverseReplication)
+		}
 
 		streamIngestionDetails := jobspb.StreamIngestionDetails{
-			StreamAddress: string(streamAddress),
-			TenantID:      ingestionStmt.Targets.Tenant,
-			Span:          roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
-			StartTime:     startTime,
+			StreamAddress:      string(streamAddress),
+			TenantID:           ingestionStmt.Targets.Tenant,
+			Span:               roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()},
+			StartTime:          startTime,
+			ReverseReplication: reverseReplication,
 		}
 
 		jobDescription, err := streamIngestionJobDescription(p, ingestionStmt)
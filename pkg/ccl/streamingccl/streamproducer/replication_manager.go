@@ -28,8 +28,9 @@ func (r *replicationStreamManagerImpl) CompleteStreamIngestion(
 	txn *kv.Txn,
 	streamID streaming.StreamID,
 	cutoverTimestamp hlc.Timestamp,
+	reverseStreamAddress string,
 ) error {
-	return completeStreamIngestion(evalCtx, txn, streamID, cutoverTimestamp)
+	return completeStreamIngestion(evalCtx, txn, streamID, cutoverTimestamp, reverseStreamAddress)
 }
 
 // StartReplicationStream implements ReplicationStreamManager interface.
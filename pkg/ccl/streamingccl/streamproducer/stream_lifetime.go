@@ -33,15 +33,18 @@ import (
 	"github.com/cockroachdb/errors"
 )
 
-// completeStreamIngestion terminates the stream as of specified time.
+// completeStreamIngestion terminates the stream as of specified time. If
+// reverseStreamAddress is set, the stream ingestion job starts replicating the
+// tenant back into the source cluster from that address once it has cut over.
 func completeStreamIngestion(
 	evalCtx *tree.EvalContext,
 	txn *kv.Txn,
 	streamID streaming.StreamID,
 	cutoverTimestamp hlc.Timestamp,
+	reverseStreamAddress string,
 ) error {
-	// Get the job payload for job_id.
-	const jobsQuery = `SELECT progress FROM system.jobs WHERE id=$1 FOR UPDATE`
+	// Get the job payload and progress for job_id.
+	const jobsQuery = `SELECT payload, progress FROM system.jobs WHERE id=$1 FOR UPDATE`
 	row, err := evalCtx.Planner.QueryRowEx(evalCtx.Context,
 		"get-stream-ingestion-job-metadata",
 		txn, sessiondata.NodeUserSessionDataOverride, jobsQuery, streamID)
@@ -54,27 +57,36 @@ func completeStreamIngestion(
 		return errors.Newf("job %d: not found in system.jobs table", streamID)
 	}
 
-	progress, err := jobs.UnmarshalProgress(row[0])
+	payload, err := jobs.UnmarshalPayload(row[0])
+	if err != nil {
+		return err
+	}
+	details, ok := payload.UnwrapDetails().(jobspb.StreamIngestionDetails)
+	if !ok {
+		return errors.Newf("job %d: not of expected type StreamIngest", streamID)
+	}
+	progress, err := jobs.UnmarshalProgress(row[1])
 	if err != nil {
 		return err
 	}
 	var sp *jobspb.Progress_StreamIngest
-	var ok bool
 	if sp, ok = progress.GetDetails().(*jobspb.Progress_StreamIngest); !ok {
 		return errors.Newf("job %d: not of expected type StreamIngest", streamID)
 	}
 
-	// Check that the supplied cutover time is a valid one.
-	// TODO(adityamaru): This will change once we allow a future cutover time to
-	// be specified.
-	hw := progress.GetHighWater()
-	if hw == nil || hw.Less(cutoverTimestamp) {
-		var highWaterTimestamp hlc.Timestamp
-		if hw != nil {
-			highWaterTimestamp = *hw
+	// Check that the supplied cutover time is a valid one. The cutover time may
+	// be ahead of the latest resolved time of the job, in which case the job
+	// keeps ingesting until it has resolved the cutover time, but it cannot
+	// precede the time the job started ingesting from.
+	if cutoverTimestamp.Less(details.StartTime) {
+		return errors.Newf("cannot cutover to a timestamp %s that is before the start time"+
+			" %s of job %d", cutoverTimestamp.String(), details.StartTime.String(), streamID)
+	}
+
+	if reverseStreamAddress != "" {
+		if _, err := streamingccl.StreamAddress(reverseStreamAddress).URL(); err != nil {
+			return errors.Wrapf(err, "invalid reverse stream address")
 		}
-		return errors.Newf("cannot cutover to a timestamp %s that is after the latest resolved time"+
-			" %s for job %d", cutoverTimestamp.String(), highWaterTimestamp.String(), streamID)
 	}
 
 	// Reject setting a cutover time, if an earlier request to cutover has already
@@ -90,6 +102,7 @@ func completeStreamIngestion(
 	// Update the sentinel being polled by the stream ingestion job to
 	// check if a complete has been signaled.
 	sp.StreamIngest.CutoverTime = cutoverTimestamp
+	sp.StreamIngest.ReverseStreamAddress = reverseStreamAddress
 	progress.ModifiedMicros = timeutil.ToUnixMicros(txn.ReadTimestamp().GoTime())
 	progressBytes, err := protoutil.Marshal(progress)
 	if err != nil {
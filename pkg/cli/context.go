@@ -435,6 +435,7 @@ func setDebugContextDefaults() {
 	debugCtx.decodeAsTableDesc = ""
 	debugCtx.verbose = false
 	debugCtx.keyTypes = showAll
+	doctorRepairOpts.confirmAction = prompt
 }
 
 // startCtx captures the command-line arguments for the `start` command.
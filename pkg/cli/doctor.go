@@ -75,6 +75,25 @@ tables are queried either from a live cluster or from an unzipped debug.zip.
 `,
 }
 
+var doctorRepairCmd = &cobra.Command{
+	Use:   "repair [cluster|zipdir]",
+	Short: "prints SQL that repairs inconsistencies in system tables",
+	Long: `
+Run the doctor tool to examine the system table contents and generate SQL
+statements that repair the inconsistencies which can be fixed automatically.
+Each inconsistency is reported along with its repair, followed by the
+inconsistencies which remain and must be repaired manually. System tables are
+queried either from a live cluster or from an unzipped debug.zip. When run
+against a live cluster, the repair statements can then be run in a single
+transaction, after confirmation.
+`,
+}
+
+// doctorRepairOpts holds the flags of the doctor repair cluster command.
+var doctorRepairOpts struct {
+	confirmAction confirmActionFlag
+}
+
 type doctorFn = func(
 	version *clusterversion.ClusterVersion,
 	descTable doctor.DescriptorTable,
@@ -146,6 +165,139 @@ var doctorExamineFallbackClusterCmd = deprecateCommand(makeClusterCommand(runDoc
 var doctorExamineFallbackZipDirCmd = deprecateCommand(makeZipDirCommand(runDoctorExamine))
 var doctorRecreateClusterCmd = makeClusterCommand(runDoctorRecreate)
 var doctorRecreateZipDirCmd = makeZipDirCommand(runDoctorRecreate)
+var doctorRepairClusterCmd = makeRepairClusterCommand()
+var doctorRepairZipDirCmd = makeZipDirCommand(runDoctorRepair)
+
+func makeRepairClusterCommand() *cobra.Command {
+	cmd := makeClusterCommand(nil /* fn */)
+	cmd.Long = `
+Run the doctor tool repair on system data from a live cluster specified by
+--url. After the repair statements are printed, they are run in a single
+transaction if confirmed.
+`
+	cmd.RunE = clierrorplus.MaybeDecorateError(
+		func(cmd *cobra.Command, args []string) (resErr error) {
+			sqlConn, err := makeSQLClient("cockroach doctor", useSystemDb)
+			if err != nil {
+				return errors.Wrap(err, "could not establish connection to cluster")
+			}
+			defer func() { resErr = errors.CombineErrors(resErr, sqlConn.Close()) }()
+			descs, ns, jobs, err := fromCluster(sqlConn, cliCtx.cmdTimeout)
+			if err != nil {
+				return err
+			}
+			repairs, ok, err := generateDoctorRepairs(nil, descs, ns, jobs, os.Stdout)
+			if err != nil {
+				return err
+			}
+			if len(repairs) > 0 {
+				if err := runDoctorRepairStatements(sqlConn, repairs, os.Stdout); err != nil {
+					return err
+				}
+			}
+			return doctorRepairResult(ok, os.Stdout)
+		})
+	return cmd
+}
+
+func runDoctorRepair(
+	version *clusterversion.ClusterVersion,
+	descTable doctor.DescriptorTable,
+	namespaceTable doctor.NamespaceTable,
+	jobsTable doctor.JobsTable,
+	out io.Writer,
+) (err error) {
+	_, ok, err := generateDoctorRepairs(version, descTable, namespaceTable, jobsTable, out)
+	if err != nil {
+		return err
+	}
+	return doctorRepairResult(ok, out)
+}
+
+// generateDoctorRepairs reports the problems in the system tables and prints
+// the script of statements that repair them.
+func generateDoctorRepairs(
+	version *clusterversion.ClusterVersion,
+	descTable doctor.DescriptorTable,
+	namespaceTable doctor.NamespaceTable,
+	jobsTable doctor.JobsTable,
+	out io.Writer,
+) (repairs []doctor.RepairStatement, ok bool, err error) {
+	if version == nil {
+		version = &clusterversion.ClusterVersion{
+			Version: clusterversion.DoctorBinaryVersion,
+		}
+	}
+	repairs, ok, err = doctor.Repair(
+		context.Background(),
+		*version,
+		descTable,
+		namespaceTable,
+		jobsTable,
+		out)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(repairs) > 0 {
+		fmt.Fprintln(out, "Repair statements:")
+		fmt.Fprintln(out, "BEGIN;")
+		for _, r := range repairs {
+			fmt.Fprintln(out, r.SQL)
+		}
+		fmt.Fprintln(out, "COMMIT;")
+	}
+	return repairs, ok, nil
+}
+
+// runDoctorRepairStatements runs the repair statements in a single
+// transaction, once confirmed.
+func runDoctorRepairStatements(
+	sqlConn clisqlclient.Conn, repairs []doctor.RepairStatement, out io.Writer,
+) error {
+	switch doctorRepairOpts.confirmAction {
+	case prompt:
+		fmt.Fprintf(out, "\nRun the %d repair statements above [y/N] ", len(repairs))
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return errors.Wrap(err, "failed to read user input")
+		}
+		fmt.Fprintln(out)
+		if len(line) < 1 || (line[0] != 'y' && line[0] != 'Y') {
+			fmt.Fprintln(out, "Aborted at user request")
+			return nil
+		}
+	case allYes:
+		// Run the statements.
+	default:
+		fmt.Fprintln(out, "Not running the repair statements due to --confirm option")
+		return nil
+	}
+	if err := sqlConn.ExecTxn(context.Background(),
+		func(ctx context.Context, conn clisqlclient.TxBoundConn) error {
+			for _, r := range repairs {
+				if err := conn.Exec(ctx, r.SQL); err != nil {
+					return errors.Wrapf(err, "repairing %q", r.Problem)
+				}
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Ran %d repair statements.\n", len(repairs))
+	return nil
+}
+
+// doctorRepairResult returns an error if problems which were not repaired
+// remain.
+func doctorRepairResult(ok bool, out io.Writer) error {
+	if !ok {
+		return clierror.NewError(errors.New("problems remain which require manual repair"),
+			exit.DoctorValidationFailed())
+	}
+	fmt.Fprintln(out, "No problems left after repair!")
+	return nil
+}
 
 func runDoctorRecreate(
 	_ *clusterversion.ClusterVersion,
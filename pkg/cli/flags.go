@@ -610,6 +610,7 @@ func init() {
 		doctorExamineClusterCmd,
 		doctorExamineFallbackClusterCmd,
 		doctorRecreateClusterCmd,
+		doctorRepairClusterCmd,
 		genHAProxyCmd,
 		initCmd,
 		quitCmd,
@@ -660,6 +661,7 @@ func init() {
 		doctorExamineClusterCmd,
 		doctorExamineFallbackClusterCmd,
 		doctorRecreateClusterCmd,
+		doctorRepairClusterCmd,
 		// If you add something here, make sure the actual implementation
 		// of the command uses `cmdTimeoutContext(.)` or it will ignore
 		// the timeout.
@@ -737,6 +739,7 @@ func init() {
 		doctorExamineClusterCmd,
 		doctorExamineFallbackClusterCmd,
 		doctorRecreateClusterCmd,
+		doctorRepairClusterCmd,
 		statementBundleRecreateCmd,
 		lsNodesCmd,
 		statusNodeCmd,
@@ -952,6 +955,8 @@ func init() {
 			doctorExamineFallbackZipDirCmd,
 			doctorRecreateClusterCmd,
 			doctorRecreateZipDirCmd,
+			doctorRepairClusterCmd,
+			doctorRepairZipDirCmd,
 		} {
 			f := c.Flags()
 			if f.Lookup(cliflags.Verbose.Name) == nil {
@@ -17,6 +17,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/cli/clierrorplus"
@@ -30,8 +34,9 @@ import (
 // TODO(knz): this struct belongs elsewhere.
 // See: https://github.com/cockroachdb/cockroach/issues/49509
 var debugTimeSeriesDumpOpts = struct {
-	format   tsDumpFormat
-	from, to timestampValue
+	format      tsDumpFormat
+	from, to    timestampValue
+	resolutions tsDumpResolutions
 }{
 	format: tsDumpText,
 	from:   timestampValue{},
@@ -42,18 +47,25 @@ var debugTimeSeriesDumpCmd = &cobra.Command{
 	Use:   "tsdump",
 	Short: "dump all the raw timeseries values in a cluster",
 	Long: `
-Dumps all of the raw timeseries values in a cluster. Only the default resolution
-is retrieved, i.e. typically datapoints older than the value of the
+Dumps all of the raw timeseries values in a cluster. By default, only the 10s
+resolution is retrieved, i.e. typically datapoints older than the value of the
 'timeseries.storage.resolution_10s.ttl' cluster setting will be absent from the
-output.
+output. Older data can be retrieved from the rollup resolutions (30m, and 1h
+and 1d if enabled) with --resolutions; the value of a rolled up datapoint is
+the average of the rolled up samples.
+
+The openmetrics format can be imported into Prometheus with:
+
+  promtool tsdb create-blocks-from openmetrics <file> <output dir>
 `,
 	RunE: clierrorplus.MaybeDecorateError(func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
 		req := &tspb.DumpRequest{
-			StartNanos: time.Time(debugTimeSeriesDumpOpts.from).UnixNano(),
-			EndNanos:   time.Time(debugTimeSeriesDumpOpts.to).UnixNano(),
+			StartNanos:  time.Time(debugTimeSeriesDumpOpts.from).UnixNano(),
+			EndNanos:    time.Time(debugTimeSeriesDumpOpts.to).UnixNano(),
+			Resolutions: debugTimeSeriesDumpOpts.resolutions,
 		}
 		var w tsWriter
 		switch debugTimeSeriesDumpOpts.format {
@@ -84,6 +96,8 @@ output.
 			cw := csvTSWriter{w: csv.NewWriter(os.Stdout)}
 			cw.w.Comma = '\t'
 			w = cw
+		case tsDumpOpenMetrics:
+			w = &openMetricsTSWriter{w: bufio.NewWriter(os.Stdout)}
 		case tsDumpText:
 			w = defaultTSWriter{w: os.Stdout}
 		default:
@@ -162,6 +176,90 @@ func (w defaultTSWriter) Emit(data *tspb.TimeSeriesData) error {
 	return nil
 }
 
+// openMetricsTSWriter writes datapoints in the OpenMetrics text format.
+// Node and store metrics are named as in the Prometheus endpoint, with the
+// node or store ID as a label.
+//
+// The datapoints of a metric must not be interleaved with other metrics,
+// and must be in timestamp order, whereas the datapoints of different
+// sources are interleaved in the dump. The datapoints are thus buffered
+// until all the datapoints of a metric have been received.
+type openMetricsTSWriter struct {
+	w       *bufio.Writer
+	name    string
+	samples []openMetricsSample
+}
+
+type openMetricsSample struct {
+	source         string
+	timestampNanos int64
+	value          float64
+}
+
+var openMetricsNameReplaceRE = regexp.MustCompile("^[^a-zA-Z_:]|[^a-zA-Z0-9_:]")
+
+func (w *openMetricsTSWriter) Emit(data *tspb.TimeSeriesData) error {
+	if data.Name != w.name {
+		if err := w.writeMetric(); err != nil {
+			return err
+		}
+		w.name = data.Name
+	}
+	for _, d := range data.Datapoints {
+		w.samples = append(w.samples, openMetricsSample{
+			source:         data.Source,
+			timestampNanos: d.TimestampNanos,
+			value:          d.Value,
+		})
+	}
+	return nil
+}
+
+func (w *openMetricsTSWriter) Flush() error {
+	if err := w.writeMetric(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString("# EOF\n"); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// writeMetric writes the buffered datapoints of the current metric.
+func (w *openMetricsTSWriter) writeMetric() error {
+	if len(w.samples) == 0 {
+		return nil
+	}
+	name, label := w.name, "source"
+	if strings.HasPrefix(name, "cr.node.") {
+		name, label = strings.TrimPrefix(name, "cr.node."), "node_id"
+	} else if strings.HasPrefix(name, "cr.store.") {
+		name, label = strings.TrimPrefix(name, "cr.store."), "store"
+	}
+	name = openMetricsNameReplaceRE.ReplaceAllString(name, "_")
+
+	sort.Slice(w.samples, func(i, j int) bool {
+		if w.samples[i].source != w.samples[j].source {
+			return w.samples[i].source < w.samples[j].source
+		}
+		return w.samples[i].timestampNanos < w.samples[j].timestampNanos
+	})
+	fmt.Fprintf(w.w, "# TYPE %s unknown\n", name)
+	for i, s := range w.samples {
+		// The same datapoint may be returned from several resolutions.
+		if i > 0 && s.source == w.samples[i-1].source &&
+			s.timestampNanos == w.samples[i-1].timestampNanos {
+			continue
+		}
+		fmt.Fprintf(w.w, "%s{%s=%q} %s %d.%03d\n", name, label, s.source,
+			strconv.FormatFloat(s.value, 'g', -1, 64),
+			s.timestampNanos/int64(time.Second),
+			(s.timestampNanos%int64(time.Second))/int64(time.Millisecond))
+	}
+	w.samples = w.samples[:0]
+	return nil
+}
+
 type tsDumpFormat int
 
 const (
@@ -169,6 +267,7 @@ const (
 	tsDumpCSV
 	tsDumpTSV
 	tsDumpRaw
+	tsDumpOpenMetrics
 )
 
 // Type implements the pflag.Value interface.
@@ -185,6 +284,8 @@ func (m *tsDumpFormat) String() string {
 		return "text"
 	case tsDumpRaw:
 		return "raw"
+	case tsDumpOpenMetrics:
+		return "openmetrics"
 	}
 	return ""
 }
@@ -200,8 +301,46 @@ func (m *tsDumpFormat) Set(s string) error {
 		*m = tsDumpTSV
 	case "raw":
 		*m = tsDumpRaw
+	case "openmetrics":
+		*m = tsDumpOpenMetrics
 	default:
 		return fmt.Errorf("invalid value for --format: %s", s)
 	}
 	return nil
 }
+
+// tsDumpResolutions is the list of resolutions to dump.
+type tsDumpResolutions []tspb.TimeSeriesResolution
+
+var tsDumpResolutionNames = map[string]tspb.TimeSeriesResolution{
+	"10s": tspb.TimeSeriesResolution_RESOLUTION_10S,
+	"30m": tspb.TimeSeriesResolution_RESOLUTION_30M,
+	"1h":  tspb.TimeSeriesResolution_RESOLUTION_1H,
+	"1d":  tspb.TimeSeriesResolution_RESOLUTION_1D,
+}
+
+// Type implements the pflag.Value interface.
+func (r *tsDumpResolutions) Type() string { return "string" }
+
+// String implements the pflag.Value interface.
+func (r *tsDumpResolutions) String() string {
+	var names []string
+	for _, res := range *r {
+		names = append(names, ts.ResolutionFromProto(res).String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements the pflag.Value interface.
+func (r *tsDumpResolutions) Set(s string) error {
+	var resolutions tsDumpResolutions
+	for _, name := range strings.Split(s, ",") {
+		res, ok := tsDumpResolutionNames[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("invalid value for --resolutions: %s", name)
+		}
+		resolutions = append(resolutions, res)
+	}
+	*r = resolutions
+	return nil
+}
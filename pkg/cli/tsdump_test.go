@@ -0,0 +1,72 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenMetricsTSWriter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var buf bytes.Buffer
+	w := &openMetricsTSWriter{w: bufio.NewWriter(&buf)}
+	for _, data := range []tspb.TimeSeriesData{
+		{Name: "cr.node.sql.conns", Source: "2", Datapoints: []tspb.TimeSeriesDatapoint{
+			{TimestampNanos: 10e9, Value: 3},
+		}},
+		{Name: "cr.node.sql.conns", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+			{TimestampNanos: 20e9, Value: 2},
+			{TimestampNanos: 10e9, Value: 1},
+		}},
+		// The same datapoint, from another resolution.
+		{Name: "cr.node.sql.conns", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+			{TimestampNanos: 10e9, Value: 1},
+		}},
+		{Name: "cr.store.capacity.used", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+			{TimestampNanos: 10500e6, Value: 1.5},
+		}},
+	} {
+		data := data
+		require.NoError(t, w.Emit(&data))
+	}
+	require.NoError(t, w.Flush())
+
+	require.Equal(t, `# TYPE sql_conns unknown
+sql_conns{node_id="1"} 1 10.000
+sql_conns{node_id="1"} 2 20.000
+sql_conns{node_id="2"} 3 10.000
+# TYPE capacity_used unknown
+capacity_used{store="1"} 1.5 10.500
+# EOF
+`, buf.String())
+}
+
+func TestTSDumpResolutions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var r tsDumpResolutions
+	require.NoError(t, r.Set("10s,30m,1h,1d"))
+	require.Equal(t, tsDumpResolutions{
+		tspb.TimeSeriesResolution_RESOLUTION_10S,
+		tspb.TimeSeriesResolution_RESOLUTION_30M,
+		tspb.TimeSeriesResolution_RESOLUTION_1H,
+		tspb.TimeSeriesResolution_RESOLUTION_1D,
+	}, r)
+	require.Equal(t, "10s,30m,1h,1d", r.String())
+	require.Error(t, r.Set("1m"))
+}
@@ -310,6 +310,34 @@ const (
 	// for raft log truncation, by allowing each replica to treat a truncation
 	// proposal as an upper bound on what should be truncated.
 	LooselyCoupledRaftLogTruncation
+	// StatementPlanPinsTable adds the system tables for storing the indexes
+	// pinned for statement fingerprints and the plan regressions reported for
+	// them.
+	StatementPlanPinsTable
+	// SampledStmtDiagReqs adds the sampling_probability, num_samples,
+	// collected_samples and on_plan_change columns to the
+	// system.statement_diagnostics_requests table.
+	SampledStmtDiagReqs
+	// TSearchTypes enables the tsvector and tsquery types for full text
+	// search.
+	TSearchTypes
+	// IncrementalMaterializedViewRefresh adds the system table recording the
+	// timestamp of the last incremental refresh of materialized views, and
+	// enables REFRESH MATERIALIZED VIEW ... INCREMENTALLY, which relies on all
+	// nodes recording the timestamp of the last full refresh in the view
+	// descriptor.
+	IncrementalMaterializedViewRefresh
+	// PartialTableStatistics enables CREATE STATISTICS ... USING EXTREMES,
+	// which relies on all nodes understanding the partial statistics fields
+	// of the CREATE STATISTICS job and sketch processor specs.
+	PartialTableStatistics
+	// ExclusionConstraints enables EXCLUDE constraints in CREATE TABLE, which
+	// rely on all nodes understanding the exclusion elements of an index
+	// descriptor.
+	ExclusionConstraints
+	// RangeTypes enables the int4range, int8range, numrange, tsrange,
+	// tstzrange and daterange types, and the corresponding multirange types.
+	RangeTypes
 
 	// *************************************************
 	// Step (1): Add new versions here.
@@ -506,6 +534,34 @@ var versionsSingleton = keyedVersions{
 		Key:     LooselyCoupledRaftLogTruncation,
 		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 80},
 	},
+	{
+		Key:     StatementPlanPinsTable,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 82},
+	},
+	{
+		Key:     SampledStmtDiagReqs,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 84},
+	},
+	{
+		Key:     TSearchTypes,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 86},
+	},
+	{
+		Key:     IncrementalMaterializedViewRefresh,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 88},
+	},
+	{
+		Key:     PartialTableStatistics,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 90},
+	},
+	{
+		Key:     ExclusionConstraints,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 92},
+	},
+	{
+		Key:     RangeTypes,
+		Version: roachpb.Version{Major: 21, Minor: 2, Internal: 94},
+	},
 
 	// *************************************************
 	// Step (2): Add new versions here.
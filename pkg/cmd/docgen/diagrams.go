@@ -702,7 +702,7 @@ var specs = []stmtSpec{
 	},
 	{
 		name:   "delete_stmt",
-		inline: []string{"opt_with_clause", "with_clause", "cte_list", "table_expr_opt_alias_idx", "table_name_opt_idx", "opt_where_clause", "where_clause", "returning_clause", "opt_sort_clause", "opt_limit_clause", "opt_only", "opt_descendant"},
+		inline: []string{"opt_with_clause", "with_clause", "cte_list", "table_expr_opt_alias_idx", "table_name_opt_idx", "opt_using_clause", "from_list", "opt_where_clause", "where_clause", "returning_clause", "opt_sort_clause", "opt_limit_clause", "opt_only", "opt_descendant"},
 		replace: map[string]string{
 			"relation_expr": "table_name",
 		},
@@ -1076,6 +1076,11 @@ var specs = []stmtSpec{
 			"opt_select_fetch_first_value": "count",
 		},
 	},
+	{
+		name:    "merge_stmt",
+		inline:  []string{"opt_with_clause", "with_clause", "cte_list", "merge_when_list", "merge_when_clause", "opt_merge_when_cond", "merge_matched_action", "merge_not_matched_action"},
+		nosplit: true,
+	},
 	{
 		name:   "offset_clause",
 		inline: []string{"row_or_rows"},
@@ -85,6 +85,11 @@ const AutoStatsName = "__auto__"
 // during import.
 const ImportStatsName = "__import__"
 
+// ForecastStatsName is the name to use for statistic forecasts, which are
+// extrapolated from the history of collected statistics and are never
+// persisted.
+const ForecastStatsName = "__forecast__"
+
 // AutomaticJobTypes is a list of automatic job types that currently exist.
 var AutomaticJobTypes = [...]Type{
 	TypeAutoCreateStats,
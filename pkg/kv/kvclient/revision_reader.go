@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
 )
 
 // VersionedValues is similar to roachpb.KeyValue except instead of just the
@@ -48,33 +49,97 @@ func GetAllRevisions(
 
 	var res []VersionedValues
 	for _, file := range resp.(*roachpb.ExportResponse).Files {
-		iter, err := storage.NewMemSSTIterator(file.SST, false)
-		if err != nil {
+		var err error
+		if res, err = appendRevisions(res, file.SST, startKey, endKey); err != nil {
 			return nil, err
 		}
-		defer iter.Close()
-		iter.SeekGE(storage.MVCCKey{Key: startKey})
+	}
+	return res, nil
+}
 
-		for ; ; iter.Next() {
-			if valid, err := iter.Valid(); !valid || err != nil {
-				if err != nil {
-					return nil, err
-				}
-				break
-			} else if iter.UnsafeKey().Key.Compare(endKey) >= 0 {
-				break
+// GetAllRevisionsPaginated is like GetAllRevisions, but it reads the revisions
+// in pages of roughly targetBytes bytes and calls fn with the revisions of each
+// page, so that the revisions of the whole span are never buffered in memory.
+// The revisions of a single key are never split across pages. If fn returns an
+// error, the iteration stops and the error is returned, unless it is
+// iterutil.StopIteration(), in which case nil is returned.
+func GetAllRevisionsPaginated(
+	ctx context.Context,
+	db *kv.DB,
+	startKey, endKey roachpb.Key,
+	startTime, endTime hlc.Timestamp,
+	targetBytes int64,
+	fn func([]VersionedValues) error,
+) error {
+	for key := startKey; key != nil; {
+		// The sentinel TargetBytes value of 1 makes the ExportRequest return
+		// after creating a single SST of roughly TargetFileSize bytes, along with
+		// the span that remains to be read.
+		header := roachpb.Header{Timestamp: endTime, TargetBytes: 1}
+		req := &roachpb.ExportRequest{
+			RequestHeader:  roachpb.RequestHeader{Key: key, EndKey: endKey},
+			StartTime:      startTime,
+			MVCCFilter:     roachpb.MVCCFilter_All,
+			ReturnSST:      true,
+			TargetFileSize: targetBytes,
+		}
+		resp, pErr := kv.SendWrappedWith(ctx, db.NonTransactionalSender(), header, req)
+		if pErr != nil {
+			return pErr.GoError()
+		}
+		exportResp := resp.(*roachpb.ExportResponse)
+		var page []VersionedValues
+		for _, file := range exportResp.Files {
+			var err error
+			if page, err = appendRevisions(page, file.SST, key, endKey); err != nil {
+				return err
+			}
+		}
+		if err := fn(page); err != nil {
+			if iterutil.Done(err) {
+				return nil
 			}
-			key := iter.UnsafeKey()
-			keyCopy := make([]byte, len(key.Key))
-			copy(keyCopy, key.Key)
-			key.Key = keyCopy
-			value := make([]byte, len(iter.UnsafeValue()))
-			copy(value, iter.UnsafeValue())
-			if len(res) == 0 || !res[len(res)-1].Key.Equal(key.Key) {
-				res = append(res, VersionedValues{Key: key.Key})
+			return err
+		}
+		key = nil
+		if exportResp.ResumeSpan != nil {
+			key = exportResp.ResumeSpan.Key
+		}
+	}
+	return nil
+}
+
+// appendRevisions appends the revisions of the keys between startKey and
+// endKey in the given SST to res.
+func appendRevisions(
+	res []VersionedValues, sst []byte, startKey, endKey roachpb.Key,
+) ([]VersionedValues, error) {
+	iter, err := storage.NewMemSSTIterator(sst, false)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	iter.SeekGE(storage.MVCCKey{Key: startKey})
+
+	for ; ; iter.Next() {
+		if valid, err := iter.Valid(); !valid || err != nil {
+			if err != nil {
+				return nil, err
 			}
-			res[len(res)-1].Values = append(res[len(res)-1].Values, roachpb.Value{Timestamp: key.Timestamp, RawBytes: value})
+			break
+		} else if iter.UnsafeKey().Key.Compare(endKey) >= 0 {
+			break
+		}
+		key := iter.UnsafeKey()
+		keyCopy := make([]byte, len(key.Key))
+		copy(keyCopy, key.Key)
+		key.Key = keyCopy
+		value := make([]byte, len(iter.UnsafeValue()))
+		copy(value, iter.UnsafeValue())
+		if len(res) == 0 || !res[len(res)-1].Key.Equal(key.Key) {
+			res = append(res, VersionedValues{Key: key.Key})
 		}
+		res[len(res)-1].Values = append(res[len(res)-1].Values, roachpb.Value{Timestamp: key.Timestamp, RawBytes: value})
 	}
 	return res, nil
 }
@@ -65,7 +65,7 @@ func createAndRegisterUnavailableRangesRule(
 	help := "This check detects when the number of ranges with less than quorum replicas live are non-zero for too long"
 
 	rule, err := metric.NewAlertingRule(
-		trippedReplicaCircuitBreakersRuleName,
+		unavailableRangesRuleName,
 		expr,
 		annotations,
 		nil,
@@ -88,8 +88,8 @@ func createAndRegisterTrippedReplicaCircuitBreakersRule(
 	recommendedHoldDuration := 10 * time.Minute
 	help := "This check detects when Replicas have stopped serving traffic as a result of KV health issues"
 
-	unavailableRanges, err := metric.NewAlertingRule(
-		unavailableRangesRuleName,
+	trippedReplicaCircuitBreakers, err := metric.NewAlertingRule(
+		trippedReplicaCircuitBreakersRuleName,
 		expr,
 		annotations,
 		nil,
@@ -97,7 +97,7 @@ func createAndRegisterTrippedReplicaCircuitBreakersRule(
 		help,
 		true,
 	)
-	maybeAddRuleToRegistry(ctx, err, unavailableRangesRuleName, unavailableRanges, ruleRegistry)
+	maybeAddRuleToRegistry(ctx, err, trippedReplicaCircuitBreakersRuleName, trippedReplicaCircuitBreakers, ruleRegistry)
 }
 
 func createAndRegisterUnderReplicatedRangesRule(
@@ -283,9 +283,11 @@ func maybeAddRuleToRegistry(
 ) {
 	if err != nil {
 		log.Warningf(ctx, "unable to create kv rule %s: %s", name, err.Error())
+		return
 	}
 	if ruleRegistry == nil {
 		log.Warningf(ctx, "unable to add kv rule %s: rule registry uninitialized", name)
+		return
 	}
 	ruleRegistry.AddRule(rule)
 }
@@ -0,0 +1,45 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/migration"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+)
+
+const addSamplingColsToStmtDiagReqs = `
+ALTER TABLE system.statement_diagnostics_requests
+  ADD COLUMN sampling_probability FLOAT NULL,
+  ADD COLUMN num_samples INT8 NULL,
+  ADD COLUMN collected_samples INT8 NULL,
+  ADD COLUMN on_plan_change BOOL NULL`
+
+// sampledStmtDiagReqsMigration adds the columns describing sampled and
+// plan-change statement diagnostics requests to the
+// system.statement_diagnostics_requests table.
+func sampledStmtDiagReqsMigration(
+	ctx context.Context, cs clusterversion.ClusterVersion, d migration.TenantDeps, _ *jobs.Job,
+) error {
+	op := operation{
+		name: "add-stmt-diag-reqs-sampling-columns",
+		schemaList: []string{
+			"sampling_probability", "num_samples", "collected_samples", "on_plan_change",
+		},
+		query:          addSamplingColsToStmtDiagReqs,
+		schemaExistsFn: hasColumn,
+	}
+	return migrateTable(ctx, cs, d, op, keys.StatementDiagnosticsRequestsTableID, systemschema.StatementDiagnosticsRequestsTable)
+}
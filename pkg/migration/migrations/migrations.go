@@ -125,6 +125,24 @@ var migrations = []migration.Migration{
 		NoPrecondition,
 		tenantSettingsTableMigration,
 	),
+	migration.NewTenantMigration(
+		"add the system.statement_plan_pins and system.statement_plan_regressions tables",
+		toCV(clusterversion.StatementPlanPinsTable),
+		NoPrecondition,
+		statementPlanPinsTableMigration,
+	),
+	migration.NewTenantMigration(
+		"add sampling columns to system.statement_diagnostics_requests",
+		toCV(clusterversion.SampledStmtDiagReqs),
+		NoPrecondition,
+		sampledStmtDiagReqsMigration,
+	),
+	migration.NewTenantMigration(
+		"add the system.materialized_view_refreshes table",
+		toCV(clusterversion.IncrementalMaterializedViewRefresh),
+		NoPrecondition,
+		materializedViewRefreshesTableMigration,
+	),
 }
 
 func init() {
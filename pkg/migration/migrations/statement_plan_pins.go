@@ -0,0 +1,37 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package migrations
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/migration"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/systemschema"
+)
+
+// statementPlanPinsTableMigration creates the system.statement_plan_pins and
+// system.statement_plan_regressions tables.
+func statementPlanPinsTableMigration(
+	ctx context.Context, _ clusterversion.ClusterVersion, d migration.TenantDeps, _ *jobs.Job,
+) error {
+	for _, table := range []catalog.TableDescriptor{
+		systemschema.StatementPlanPinsTable,
+		systemschema.StatementPlanRegressionsTable,
+	} {
+		if err := createSystemTable(ctx, d.DB, d.Codec, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
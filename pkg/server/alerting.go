@@ -0,0 +1,337 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/kv/kvserver"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/ts"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/logtags"
+)
+
+// alertingLookback is how far back the evaluation of the alerting rules
+// looks for the most recent value of a series. Sources which have not
+// recorded a value in this window, e.g. because their node is down, are
+// ignored.
+const alertingLookback = time.Minute
+
+var (
+	// alertingEnabled enables the evaluation of the alerting rules.
+	alertingEnabled = settings.RegisterBoolSetting(
+		settings.SystemOnly,
+		"server.alerting.enabled",
+		"if set, the node holding the lease of the node liveness range periodically "+
+			"evaluates the built-in alerting rules against the time series database "+
+			"and reports alerts to the event log",
+		false,
+	).WithPublic()
+	// alertingInterval is how often the alerting rules are evaluated.
+	alertingInterval = settings.RegisterDurationSetting(
+		settings.SystemOnly,
+		"server.alerting.evaluation_interval",
+		"the interval at which the built-in alerting rules are evaluated (if enabled)",
+		time.Minute,
+		settings.PositiveDuration,
+	).WithPublic()
+	// alertingWebhookURL is the URL, if any, to which alert notifications
+	// are posted.
+	alertingWebhookURL = settings.RegisterValidatedStringSetting(
+		settings.SystemOnly,
+		"server.alerting.webhook_url",
+		"if nonempty, POST a JSON notification to this URL when an alert starts firing or is resolved",
+		"",
+		func(_ *settings.Values, s string) error {
+			if s == "" {
+				return nil
+			}
+			u, err := url.Parse(s)
+			if err != nil {
+				return err
+			}
+			if u.Scheme != "http" && u.Scheme != "https" {
+				return errors.Newf("unsupported URL scheme %q", u.Scheme)
+			}
+			return nil
+		},
+	).WithPublic()
+)
+
+// startAlerting starts the periodic evaluation of the rules of the rule
+// registry against the time series database. The rules are only evaluated
+// by the node holding the lease of the node liveness range, so that the
+// time series database is queried, and every alert is reported, only once
+// in the cluster. Alerts are reported as structured events and, if
+// configured, to a webhook.
+func (s *Server) startAlerting(ctx context.Context) {
+	ctx = logtags.AddTag(ctx, "alerting", nil)
+	var alerting alertingState
+
+	_ = s.stopper.RunAsyncTask(ctx, "alert-evaluator", func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(alertingInterval.Get(&s.st.SV))
+			select {
+			case <-s.stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+				leaseholder := alertingEnabled.Get(&s.st.SV) && s.ownsLivenessRangeLease(ctx)
+				if evaluator := alerting.setLeaseholder(s.ruleRegistry, leaseholder); evaluator != nil {
+					s.evaluateAlerts(ctx, evaluator)
+				}
+			}
+		}
+	})
+}
+
+// evaluateAlerts evaluates the alerting rules once and reports the alerts
+// which started firing or were resolved.
+func (s *Server) evaluateAlerts(ctx context.Context, evaluator *metric.RuleEvaluator) {
+	now := timeutil.Now()
+	src := &tsSeriesSource{
+		db:         s.tsDB,
+		names:      s.recorder.GetTimeSeriesNames(),
+		storeNodes: make(map[string]string),
+		startNanos: now.Add(-alertingLookback).UnixNano(),
+		endNanos:   now.UnixNano(),
+	}
+	for storeID, desc := range s.storePool.GetStores() {
+		src.storeNodes[storeID.String()] = desc.Node.NodeID.String()
+	}
+
+	alerts, err := evaluator.Evaluate(ctx, src, now)
+	if err != nil {
+		log.Warningf(ctx, "error evaluating alerting rules: %v", err)
+	}
+	for _, a := range alerts {
+		s.logAlertEvent(ctx, a)
+	}
+	if webhookURL := alertingWebhookURL.Get(&s.st.SV); webhookURL != "" && len(alerts) > 0 {
+		if err := postAlertsToWebhook(ctx, webhookURL, alerts); err != nil {
+			log.Warningf(ctx, "error posting alerts to webhook: %v", err)
+		}
+	}
+}
+
+// alertingState tracks the evaluation of the alerting rules by a node.
+type alertingState struct {
+	// evaluator is set while the node is the one evaluating the rules. It
+	// holds the state of the alerts, which is dropped when the node stops
+	// evaluating the rules: the node evaluating them next starts from
+	// scratch, and the alerts firing at that point are reported again.
+	evaluator *metric.RuleEvaluator
+}
+
+// setLeaseholder records whether the node is the one designated to
+// evaluate the rules, and returns the evaluator to use if it is.
+func (a *alertingState) setLeaseholder(
+	registry *metric.RuleRegistry, leaseholder bool,
+) *metric.RuleEvaluator {
+	if !leaseholder {
+		a.evaluator = nil
+		return nil
+	}
+	if a.evaluator == nil {
+		a.evaluator = metric.NewRuleEvaluator(registry)
+	}
+	return a.evaluator
+}
+
+// ownsLivenessRangeLease returns whether one of the stores of this node
+// holds a valid lease on the node liveness range. Since there is at most
+// one such node at a time, it is the one designated to evaluate the
+// alerting rules.
+func (s *Server) ownsLivenessRangeLease(ctx context.Context) bool {
+	now := s.clock.NowAsClockTimestamp()
+	var owns bool
+	_ = s.node.stores.VisitStores(func(store *kvserver.Store) error {
+		repl := store.LookupReplica(roachpb.RKey(keys.NodeLivenessPrefix))
+		if repl != nil && repl.OwnsValidLease(ctx, now) {
+			owns = true
+		}
+		return nil
+	})
+	return owns
+}
+
+// logAlertEvent reports an alert transition to the HEALTH channel and the
+// system.eventlog table.
+func (s *Server) logAlertEvent(ctx context.Context, a metric.Alert) {
+	event := makeAlertEvent(a)
+	// Ensure the event goes to log files even if it cannot be recorded to
+	// the system.eventlog table.
+	log.StructuredEvent(ctx, event)
+
+	if err := s.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		return sql.InsertEventRecord(
+			ctx,
+			s.sqlServer.execCfg.InternalExecutor,
+			txn,
+			int32(s.NodeID()), /* reporting ID: the node where the event is logged */
+			sql.LogToSystemTable|sql.LogToDevChannelIfVerbose, /* we already call log.StructuredEvent above */
+			int32(s.NodeID()), /* target ID: the node that evaluated the rule */
+			event,
+		)
+	}); err != nil {
+		log.Ops.Errorf(ctx, "unable to record event: %+v: %+v", event, err)
+	}
+}
+
+// makeAlertEvent converts an alert transition to the corresponding
+// structured event.
+func makeAlertEvent(a metric.Alert) eventpb.EventPayload {
+	details := eventpb.CommonAlertDetails{
+		RuleName:    a.RuleName,
+		Summary:     a.Annotations["summary"],
+		Value:       a.Value,
+		ActiveSince: a.ActiveSince.UnixNano(),
+	}
+	for _, name := range sortedKeys(a.Labels) {
+		details.Labels = append(details.Labels, name+"="+a.Labels[name])
+	}
+	if a.State == metric.AlertResolved {
+		event := &eventpb.AlertResolved{CommonAlertDetails: details}
+		event.Timestamp = a.ResolvedAt.UnixNano()
+		return event
+	}
+	event := &eventpb.AlertFiring{CommonAlertDetails: details}
+	event.Timestamp = timeutil.Now().UnixNano()
+	return event
+}
+
+// alertWebhookPayload is the body of the notifications posted to the
+// alerting webhook. It follows the format of the Prometheus Alertmanager
+// webhook receiver, so that receivers written for the latter can be reused.
+type alertWebhookPayload struct {
+	Version string              `json:"version"`
+	Status  string              `json:"status"`
+	Alerts  []alertWebhookAlert `json:"alerts"`
+}
+
+type alertWebhookAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+func makeAlertWebhookPayload(alerts []metric.Alert) alertWebhookPayload {
+	payload := alertWebhookPayload{Version: "4", Status: metric.AlertResolved.String()}
+	for _, a := range alerts {
+		labels := map[string]string{"alertname": a.RuleName}
+		for name, val := range a.Labels {
+			labels[name] = val
+		}
+		payload.Alerts = append(payload.Alerts, alertWebhookAlert{
+			Status:      a.State.String(),
+			Labels:      labels,
+			Annotations: a.Annotations,
+			StartsAt:    a.ActiveSince.UTC(),
+			EndsAt:      a.ResolvedAt.UTC(),
+		})
+		if a.State == metric.AlertFiring {
+			payload.Status = metric.AlertFiring.String()
+		}
+	}
+	return payload
+}
+
+// postAlertsToWebhook posts a notification for the given alerts to the
+// webhook at the given URL.
+func postAlertsToWebhook(ctx context.Context, webhookURL string, alerts []metric.Alert) error {
+	body, err := json.Marshal(makeAlertWebhookPayload(alerts))
+	if err != nil {
+		return err
+	}
+	client := httputil.NewClientWithTimeout(10 * time.Second)
+	resp, err := client.Post(ctx, webhookURL, httputil.JSONContentType, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("%s: %s", resp.Status, msg)
+	}
+	return nil
+}
+
+// tsSeriesSource implements metric.SeriesSource over the time series
+// database. Series recorded per node are labeled with the node ID as
+// instance, and series recorded per store with the store ID as store and
+// the ID of the node of the store as instance.
+type tsSeriesSource struct {
+	db *ts.DB
+	// names maps the names of the metrics as exported to Prometheus to the
+	// names of the corresponding time series.
+	names map[string]string
+	// storeNodes maps store IDs to the IDs of their nodes.
+	storeNodes           map[string]string
+	startNanos, endNanos int64
+}
+
+var _ metric.SeriesSource = &tsSeriesSource{}
+
+// Series implements the metric.SeriesSource interface.
+func (s *tsSeriesSource) Series(ctx context.Context, name string) ([]metric.Sample, error) {
+	seriesName, ok := s.names[name]
+	if !ok {
+		return nil, nil
+	}
+	latest, err := s.db.QueryLatest(ctx, seriesName, ts.Resolution10s, s.startNanos, s.endNanos)
+	if err != nil {
+		return nil, err
+	}
+	isStore := strings.HasPrefix(seriesName, "cr.store.")
+	samples := make([]metric.Sample, 0, len(latest))
+	for source, dp := range latest {
+		labels := map[string]string{"instance": source}
+		if isStore {
+			labels = map[string]string{"store": source}
+			if nodeID, ok := s.storeNodes[source]; ok {
+				labels["instance"] = nodeID
+			}
+		}
+		samples = append(samples, metric.Sample{Labels: labels, Value: dp.Value})
+	}
+	return samples, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
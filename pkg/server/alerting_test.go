@@ -0,0 +1,128 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertEvents(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	activeSince := time.Unix(100, 0)
+	a := metric.Alert{
+		RuleName:    "HighOpenFDCount",
+		Labels:      map[string]string{"instance": "1", "cluster": "test"},
+		Annotations: map[string]string{"summary": "Too many open file descriptors on 1"},
+		Value:       0.9,
+		State:       metric.AlertFiring,
+		ActiveSince: activeSince,
+	}
+	firing, ok := makeAlertEvent(a).(*eventpb.AlertFiring)
+	require.True(t, ok)
+	require.Equal(t, eventpb.CommonAlertDetails{
+		RuleName:    "HighOpenFDCount",
+		Labels:      []string{"cluster=test", "instance=1"},
+		Summary:     "Too many open file descriptors on 1",
+		Value:       0.9,
+		ActiveSince: activeSince.UnixNano(),
+	}, firing.CommonAlertDetails)
+
+	a.State = metric.AlertResolved
+	a.ResolvedAt = activeSince.Add(time.Hour)
+	resolved, ok := makeAlertEvent(a).(*eventpb.AlertResolved)
+	require.True(t, ok)
+	require.Equal(t, a.ResolvedAt.UnixNano(), resolved.Timestamp)
+}
+
+func TestAlertingStateLeaseholder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	registry := metric.NewRuleRegistry()
+	var a alertingState
+	require.Nil(t, a.setLeaseholder(registry, false /* leaseholder */))
+
+	// The leaseholder keeps evaluating the rules with the same evaluator, so
+	// that the state of the alerts is kept.
+	evaluator := a.setLeaseholder(registry, true /* leaseholder */)
+	require.NotNil(t, evaluator)
+	require.Same(t, evaluator, a.setLeaseholder(registry, true /* leaseholder */))
+
+	// The state of the alerts is dropped when the lease is lost.
+	require.Nil(t, a.setLeaseholder(registry, false /* leaseholder */))
+	regained := a.setLeaseholder(registry, true /* leaseholder */)
+	require.NotNil(t, regained)
+	require.NotSame(t, evaluator, regained)
+}
+
+func TestPostAlertsToWebhook(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	payloads := make(chan alertWebhookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload alertWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		payloads <- payload
+	}))
+	defer srv.Close()
+
+	activeSince := time.Unix(100, 0).UTC()
+	alerts := []metric.Alert{
+		{
+			RuleName:    "UnavailableRanges",
+			Labels:      map[string]string{"instance": "1"},
+			State:       metric.AlertResolved,
+			ActiveSince: activeSince,
+			ResolvedAt:  activeSince.Add(time.Hour),
+		},
+		{
+			RuleName:    "HighOpenFDCount",
+			Labels:      map[string]string{"instance": "1"},
+			Annotations: map[string]string{"summary": "Too many open file descriptors on 1"},
+			State:       metric.AlertFiring,
+			ActiveSince: activeSince,
+		},
+	}
+	require.NoError(t, postAlertsToWebhook(context.Background(), srv.URL, alerts))
+	payload := <-payloads
+	require.Equal(t, "firing", payload.Status)
+	require.Len(t, payload.Alerts, 2)
+	require.Equal(t, "resolved", payload.Alerts[0].Status)
+	require.Equal(t, map[string]string{"alertname": "UnavailableRanges", "instance": "1"}, payload.Alerts[0].Labels)
+	require.Equal(t, activeSince.Add(time.Hour), payload.Alerts[0].EndsAt)
+	require.Equal(t, "firing", payload.Alerts[1].Status)
+	require.Equal(t, activeSince, payload.Alerts[1].StartsAt)
+	require.Equal(t, "Too many open file descriptors on 1", payload.Alerts[1].Annotations["summary"])
+
+	// Errors returned by the webhook are reported.
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	require.Error(t, postAlertsToWebhook(context.Background(), failing.URL, alerts))
+}
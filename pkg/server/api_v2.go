@@ -87,6 +87,18 @@ type apiV2Server struct {
 	status           *statusServer
 	promRuleExporter *metric.PrometheusRuleExporter
 	mux              *mux.Router
+	routes           []apiV2Route
+}
+
+// apiV2Route is the definition of an endpoint of the v2 API. See
+// registerRoutes() for a description of the fields.
+type apiV2Route struct {
+	url          string
+	method       string
+	handler      http.HandlerFunc
+	requiresAuth bool
+	role         apiRole
+	option       roleoption.Option
 }
 
 // newAPIV2Server returns a new apiV2Server.
@@ -117,6 +129,9 @@ func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler)
 	// - `url` is the path string that, if matched by the user request, is
 	//    routed to this endpoint. Pattern-matching handled by gorilla.Mux; see
 	//    https://github.com/gorilla/mux#matching-routes for supported patterns.
+	// - `method` is the HTTP method of the endpoint. GET endpoints also accept
+	//    HEAD requests. Requests to `url` with another method are rejected with
+	//    an HTTP 405 error.
 	// - `handler` is the http.HandlerFunc to be called if this endpoint url
 	//    matches.
 	// - `requiresAuth` is a bool that denotes whether this endpoint requires
@@ -127,48 +142,68 @@ func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler)
 	//    authorized to access this endpoint. If the user is not at least of type
 	//    `role`, or does not have the roleoption `option`, an HTTP 403 forbidden
 	//    error is returned.
-	routeDefinitions := []struct {
-		url          string
-		handler      http.HandlerFunc
-		requiresAuth bool
-		role         apiRole
-		option       roleoption.Option
-	}{
+	//
+	// The route definitions are also used to generate the OpenAPI spec
+	// served at `openapi.json`.
+	a.routes = []apiV2Route{
 		// Pass through auth-related endpoints to the auth server.
-		{"login/", a.authServer.ServeHTTP, false /* requiresAuth */, regularRole, noOption},
-		{"logout/", a.authServer.ServeHTTP, false /* requiresAuth */, regularRole, noOption},
+		{"login/", "POST", a.authServer.ServeHTTP, false /* requiresAuth */, regularRole, noOption},
+		{"logout/", "POST", a.authServer.ServeHTTP, false /* requiresAuth */, regularRole, noOption},
 
 		// Directly register other endpoints in the api server.
-		{"sessions/", a.listSessions, true /* requiresAuth */, adminRole, noOption},
-		{"nodes/", a.listNodes, true, adminRole, noOption},
+		{"sessions/", "GET", a.listSessions, true /* requiresAuth */, adminRole, noOption},
+		{"nodes/", "GET", a.listNodes, true, adminRole, noOption},
 		// Any endpoint returning range information requires an admin user. This is because range start/end keys
 		// are sensitive info.
-		{"nodes/{node_id}/ranges/", a.listNodeRanges, true, adminRole, noOption},
-		{"ranges/hot/", a.listHotRanges, true, adminRole, noOption},
-		{"ranges/{range_id:[0-9]+}/", a.listRange, true, adminRole, noOption},
-		{"health/", a.health, false, regularRole, noOption},
-		{"users/", a.listUsers, true, regularRole, noOption},
-		{"events/", a.listEvents, true, adminRole, noOption},
-		{"databases/", a.listDatabases, true, regularRole, noOption},
-		{"databases/{database_name:[\\w.]+}/", a.databaseDetails, true, regularRole, noOption},
-		{"databases/{database_name:[\\w.]+}/grants/", a.databaseGrants, true, regularRole, noOption},
-		{"databases/{database_name:[\\w.]+}/tables/", a.databaseTables, true, regularRole, noOption},
-		{"databases/{database_name:[\\w.]+}/tables/{table_name:[\\w.]+}/", a.tableDetails, true, regularRole, noOption},
-		{"rules/", a.listRules, false, regularRole, noOption},
+		{"nodes/{node_id}/ranges/", "GET", a.listNodeRanges, true, adminRole, noOption},
+		{"ranges/hot/", "GET", a.listHotRanges, true, adminRole, noOption},
+		{"ranges/{range_id:[0-9]+}/", "GET", a.listRange, true, adminRole, noOption},
+		{"health/", "GET", a.health, false, regularRole, noOption},
+		{"users/", "GET", a.listUsers, true, regularRole, noOption},
+		{"events/", "GET", a.listEvents, true, adminRole, noOption},
+		{"databases/", "GET", a.listDatabases, true, regularRole, noOption},
+		{"databases/{database_name:[\\w.]+}/", "GET", a.databaseDetails, true, regularRole, noOption},
+		{"databases/{database_name:[\\w.]+}/grants/", "GET", a.databaseGrants, true, regularRole, noOption},
+		{"databases/{database_name:[\\w.]+}/tables/", "GET", a.databaseTables, true, regularRole, noOption},
+		{"databases/{database_name:[\\w.]+}/tables/{table_name:[\\w.]+}/", "GET", a.tableDetails, true, regularRole, noOption},
+		{"rules/", "GET", a.listRules, false, regularRole, noOption},
+		{"openapi.json", "GET", a.openAPISpec, false, regularRole, noOption},
+
+		// Write endpoints. Besides the role checks below, the SQL
+		// privileges of the logged-in user apply to all of them.
+		{"jobs/{job_id:[0-9]+}/pause/", "POST", a.pauseJob, true, regularRole, roleoption.CONTROLJOB},
+		{"jobs/{job_id:[0-9]+}/resume/", "POST", a.resumeJob, true, regularRole, roleoption.CONTROLJOB},
+		{"jobs/{job_id:[0-9]+}/cancel/", "POST", a.cancelJob, true, regularRole, roleoption.CONTROLJOB},
+		// Users can cancel their own sessions and queries without the
+		// CANCELQUERY role option.
+		{"sessions/{session_id:[0-9a-f]+}/cancel/", "POST", a.cancelSession, true, regularRole, noOption},
+		{"queries/{query_id:[0-9a-f]+}/cancel/", "POST", a.cancelQuery, true, regularRole, noOption},
+		{"cluster/settings/{setting_name:[\\w.]+}/", "PUT", a.setClusterSetting, true, regularRole, roleoption.MODIFYCLUSTERSETTING},
+		{"cluster/settings/{setting_name:[\\w.]+}/", "DELETE", a.resetClusterSetting, true, regularRole, roleoption.MODIFYCLUSTERSETTING},
+		{"databases/{database_name:[\\w.]+}/zone/", "PUT", a.setZoneConfig, true, regularRole, noOption},
+		{"databases/{database_name:[\\w.]+}/zone/", "DELETE", a.discardZoneConfig, true, regularRole, noOption},
+		{"databases/{database_name:[\\w.]+}/tables/{table_name:[\\w.]+}/zone/", "PUT", a.setZoneConfig, true, regularRole, noOption},
+		{"databases/{database_name:[\\w.]+}/tables/{table_name:[\\w.]+}/zone/", "DELETE", a.discardZoneConfig, true, regularRole, noOption},
+		{"nodes/{node_id}/drain/", "POST", a.drainNode, true, adminRole, noOption},
+		{"statement_diagnostics/", "POST", a.createStatementDiagnostics, true, regularRole, roleoption.VIEWACTIVITY},
 	}
 
 	// For all routes requiring authentication, have the outer mux (a.mux)
 	// send requests through to the authMux, and also register the relevant route
 	// in innerMux. Routes not requiring login can directly be handled in a.mux.
-	for _, route := range routeDefinitions {
+	for _, route := range a.routes {
+		methods := []string{route.method}
+		if route.method == http.MethodGet {
+			methods = append(methods, http.MethodHead)
+		}
 		var handler http.Handler
 		handler = &callCountDecorator{
 			counter: telemetry.GetCounter(fmt.Sprintf("api.v2.%s", route.url)),
 			inner:   route.handler,
 		}
 		if route.requiresAuth {
-			a.mux.Handle(apiV2Path+route.url, authMux)
-			if route.role != regularRole {
+			a.mux.Handle(apiV2Path+route.url, authMux).Methods(methods...)
+			if route.role != regularRole || route.option != noOption {
 				handler = &roleAuthorizationMux{
 					ie:     a.admin.ie,
 					role:   route.role,
@@ -176,9 +211,9 @@ func (a *apiV2Server) registerRoutes(innerMux *mux.Router, authMux http.Handler)
 					inner:  handler,
 				}
 			}
-			innerMux.Handle(apiV2Path+route.url, handler)
+			innerMux.Handle(apiV2Path+route.url, handler).Methods(methods...)
 		} else {
-			a.mux.Handle(apiV2Path+route.url, handler)
+			a.mux.Handle(apiV2Path+route.url, handler).Methods(methods...)
 		}
 	}
 }
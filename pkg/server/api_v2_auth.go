@@ -13,6 +13,7 @@ package server
 import (
 	"context"
 	"encoding/base64"
+	"fmt"
 	"net/http"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -341,6 +342,19 @@ const (
 	superUserRole
 )
 
+func (r apiRole) String() string {
+	switch r {
+	case regularRole:
+		return "regular"
+	case adminRole:
+		return "admin"
+	case superUserRole:
+		return "superuser"
+	default:
+		return fmt.Sprintf("apiRole(%d)", int(r))
+	}
+}
+
 // roleAuthorizationMux enforces a role (eg. type of user, role option)
 // for an arbitrary inner mux. Meant to be used under authenticationV2Mux. If
 // the logged-in user is not at least of `role` type, and doesn't have
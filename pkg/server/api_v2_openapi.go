@@ -0,0 +1,272 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+)
+
+// openAPIDocument is an OpenAPI 2.0 document describing the v2 API,
+// generated from its route definitions.
+type openAPIDocument struct {
+	Swagger             string                                 `json:"swagger"`
+	Info                openAPIInfo                            `json:"info"`
+	BasePath            string                                 `json:"basePath"`
+	Schemes             []string                               `json:"schemes"`
+	Produces            []string                               `json:"produces"`
+	Paths               map[string]map[string]openAPIOperation `json:"paths"`
+	SecurityDefinitions map[string]openAPISecurityScheme       `json:"securityDefinitions"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	// RequiredRole and RequiredRoleOption are the authorization
+	// requirements enforced by roleAuthorizationMux.
+	RequiredRole       string `json:"x-cockroach-required-role,omitempty"`
+	RequiredRoleOption string `json:"x-cockroach-required-role-option,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Type     string         `json:"type,omitempty"`
+	Pattern  string         `json:"pattern,omitempty"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema,omitempty"`
+}
+
+// openAPISchema is the schema of a JSON value, e.g. of a request body.
+type openAPISchema struct {
+	Type                 string                   `json:"type"`
+	Properties           map[string]openAPISchema `json:"properties,omitempty"`
+	Required             []string                 `json:"required,omitempty"`
+	Items                *openAPISchema           `json:"items,omitempty"`
+	AdditionalProperties *openAPISchema           `json:"additionalProperties,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// openAPIRequestBodies maps the names of the handlers of the routes which
+// accept a JSON request body to a value of the type the body is decoded
+// into. Request bodies are optional unless they have required fields.
+var openAPIRequestBodies = map[string]interface{}{
+	"setClusterSetting":          setClusterSettingRequest{},
+	"setZoneConfig":              setZoneConfigRequest{},
+	"drainNode":                  drainNodeRequest{},
+	"createStatementDiagnostics": createStatementDiagnosticsRequest{},
+}
+
+// routeVarRE matches the variables in the URL of a route, with their
+// optional pattern.
+var routeVarRE = regexp.MustCompile(`\{(\w+)(?::([^}]*))?\}`)
+
+// buildOpenAPIDocument generates the OpenAPI document for the given
+// route definitions.
+func buildOpenAPIDocument(routes []apiV2Route) openAPIDocument {
+	var noOption roleoption.Option
+	doc := openAPIDocument{
+		Swagger: "2.0",
+		Info: openAPIInfo{
+			Title:   "CockroachDB v2 API",
+			Version: build.BinaryVersion(),
+		},
+		BasePath: strings.TrimSuffix(apiV2Path, "/"),
+		Schemes:  []string{"http", "https"},
+		Produces: []string{"application/json"},
+		Paths:    make(map[string]map[string]openAPIOperation),
+		SecurityDefinitions: map[string]openAPISecurityScheme{
+			"api_session": {
+				Type:        "apiKey",
+				Name:        apiV2AuthHeader,
+				In:          "header",
+				Description: "Handle to logged-in REST session. Use `/login/` to log in and get a session.",
+			},
+		},
+	}
+	usedIDs := make(map[string]bool)
+	for _, route := range routes {
+		path := "/" + routeVarRE.ReplaceAllString(route.url, "{$1}")
+		op := openAPIOperation{
+			OperationID: routeOperationID(route, path, usedIDs),
+			Responses: map[string]openAPIResponse{
+				"200": {Description: "Successful operation."},
+			},
+		}
+		for _, m := range routeVarRE.FindAllStringSubmatch(route.url, -1) {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name:     m[1],
+				In:       "path",
+				Type:     "string",
+				Pattern:  m[2],
+				Required: true,
+			})
+		}
+		if body, ok := openAPIRequestBodies[routeHandlerName(route)]; ok {
+			schema := openAPISchemaForType(reflect.TypeOf(body))
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name:     "body",
+				In:       "body",
+				Required: len(schema.Required) > 0,
+				Schema:   &schema,
+			})
+			op.Responses["400"] = openAPIResponse{Description: "Invalid request body."}
+		}
+		if route.requiresAuth {
+			op.Security = []map[string][]string{{"api_session": {}}}
+			op.Responses["401"] = openAPIResponse{Description: "Not logged in."}
+			if route.role != regularRole || route.option != noOption {
+				op.Responses["403"] = openAPIResponse{Description: "User not allowed to access this endpoint."}
+			}
+			if route.role != regularRole {
+				op.RequiredRole = route.role.String()
+			}
+			if route.option != noOption {
+				op.RequiredRoleOption = route.option.String()
+			}
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]openAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(route.method)] = op
+	}
+	return doc
+}
+
+// routeOperationID returns the ID of the operation for a route. This
+// is the name of the method handling the route, unless it is used by
+// several routes or handles routes in a sub-server; the ID is then
+// derived from the method and path of the route.
+func routeOperationID(route apiV2Route, path string, usedIDs map[string]bool) string {
+	name := routeHandlerName(route)
+	if name == "ServeHTTP" || usedIDs[name] {
+		name = strings.ToLower(route.method) + routeIDSeparatorRE.ReplaceAllString(path, "_")
+		name = strings.TrimRight(name, "_")
+	}
+	usedIDs[name] = true
+	return name
+}
+
+// routeHandlerName returns the name of the method handling a route.
+func routeHandlerName(route apiV2Route) string {
+	name := runtime.FuncForPC(reflect.ValueOf(route.handler).Pointer()).Name()
+	// Method values are named "pkg.(*T).method-fm".
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// openAPISchemaForType returns the schema of the JSON encoding of values
+// of the given type. Struct fields are described by their json tags, and
+// are required unless they are tagged with omitempty.
+func openAPISchemaForType(t reflect.Type) openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openAPISchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return openAPISchema{Type: "number"}
+	case reflect.String:
+		return openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		items := openAPISchemaForType(t.Elem())
+		return openAPISchema{Type: "array", Items: &items}
+	case reflect.Map:
+		values := openAPISchemaForType(t.Elem())
+		return openAPISchema{Type: "object", AdditionalProperties: &values}
+	case reflect.Struct:
+		schema := openAPISchema{Type: "object", Properties: make(map[string]openAPISchema)}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				// Unexported fields are not encoded.
+				continue
+			}
+			tag := strings.Split(f.Tag.Get("json"), ",")
+			name, opts := tag[0], tag[1:]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			schema.Properties[name] = openAPISchemaForType(f.Type)
+			if !hasJSONOption(opts, "omitempty") {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		return schema
+	default:
+		return openAPISchema{Type: "object"}
+	}
+}
+
+// hasJSONOption returns whether the given json tag options contain opt.
+func hasJSONOption(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// routeIDSeparatorRE matches the characters of a path which are
+// replaced in the operation IDs derived from it.
+var routeIDSeparatorRE = regexp.MustCompile(`[^\w]+`)
+
+// swagger:operation GET /openapi.json openAPISpec
+//
+// Get the OpenAPI spec of the API
+//
+// Endpoint to export the OpenAPI 2.0 spec of this API, generated from
+// its route definitions. The spec describes the path, method, path
+// parameters and authorization requirements of every endpoint.
+//
+// ---
+// produces:
+// - application/json
+// responses:
+//   "200":
+//     description: OpenAPI spec
+func (a *apiV2Server) openAPISpec(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(r.Context(), w, http.StatusOK, buildOpenAPIDocument(a.routes))
+}
@@ -0,0 +1,859 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
+	"github.com/cockroachdb/errors"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxAPIV2RequestBodySize is the maximum size of the body of the
+// requests to the write endpoints.
+const maxAPIV2RequestBodySize = 1 << 20
+
+// apiV2SQLError sends the error resulting from a SQL statement run on
+// behalf of the logged-in user over the http.ResponseWriter. Errors
+// caused by the request, such as missing privileges or objects, are
+// returned to the client with an appropriate status code. Other errors
+// are handled by apiV2InternalError.
+func apiV2SQLError(ctx context.Context, err error, w http.ResponseWriter) {
+	code := http.StatusInternalServerError
+	switch pgerror.GetPGCode(err) {
+	case pgcode.InsufficientPrivilege:
+		code = http.StatusForbidden
+	case pgcode.UndefinedObject, pgcode.UndefinedTable, pgcode.UndefinedSchema,
+		pgcode.InvalidCatalogName:
+		code = http.StatusNotFound
+	case pgcode.InvalidParameterValue, pgcode.InvalidTextRepresentation, pgcode.Syntax,
+		pgcode.InvalidDatetimeFormat, pgcode.CheckViolation:
+		code = http.StatusBadRequest
+	case pgcode.ObjectNotInPrerequisiteState:
+		code = http.StatusConflict
+	default:
+		// Errors from the status server, e.g. when canceling sessions, are
+		// gRPC errors.
+		switch status.Code(errors.UnwrapAll(err)) {
+		case codes.PermissionDenied:
+			code = http.StatusForbidden
+		case codes.NotFound:
+			code = http.StatusNotFound
+		case codes.InvalidArgument:
+			code = http.StatusBadRequest
+		default:
+			if jobs.HasJobNotFoundError(err) {
+				code = http.StatusNotFound
+			} else if errors.HasType(err, (*jobs.InvalidStatusError)(nil)) {
+				code = http.StatusConflict
+			}
+		}
+	}
+	if code == http.StatusInternalServerError {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// decodeAPIV2RequestBody decodes the JSON body of a request into dst.
+// If the body cannot be decoded, an HTTP 400 error is sent and false is
+// returned.
+func decodeAPIV2RequestBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxAPIV2RequestBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// execAsUser runs a SQL statement on behalf of the logged-in user, and
+// reports errors over the http.ResponseWriter. It returns false if the
+// statement failed.
+func (a *apiV2Server) execAsUser(
+	ctx context.Context, w http.ResponseWriter, opName string, stmt string, qargs ...interface{},
+) bool {
+	username := getSQLUsername(ctx)
+	ctx = a.admin.server.AnnotateCtx(ctx)
+	if _, err := a.admin.server.sqlServer.internalExecutor.ExecEx(
+		ctx, opName, nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: username},
+		stmt, qargs...,
+	); err != nil {
+		apiV2SQLError(ctx, err, w)
+		return false
+	}
+	return true
+}
+
+// Response for the write endpoints which do not return any details.
+//
+// swagger:model writeResponse
+type writeResponse struct{}
+
+// swagger:operation POST /jobs/{job_id}/pause/ pauseJob
+//
+// Pause a job
+//
+// Request the job to pause. The job is paused asynchronously.
+//
+// Client must be logged-in as a user with admin privileges or the
+// CONTROLJOB role option. Jobs owned by admin users can only be
+// controlled by admin users.
+//
+// ---
+// parameters:
+// - name: job_id
+//   type: integer
+//   in: path
+//   description: ID of the job.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Pause requested
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "404":
+//     description: Job not found
+//   "409":
+//     description: Job cannot be paused in its current state
+func (a *apiV2Server) pauseJob(w http.ResponseWriter, r *http.Request) {
+	a.controlJob(w, r, "PAUSE JOB $1")
+}
+
+// swagger:operation POST /jobs/{job_id}/resume/ resumeJob
+//
+// Resume a job
+//
+// Resume a paused job.
+//
+// Client must be logged-in as a user with admin privileges or the
+// CONTROLJOB role option. Jobs owned by admin users can only be
+// controlled by admin users.
+//
+// ---
+// parameters:
+// - name: job_id
+//   type: integer
+//   in: path
+//   description: ID of the job.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Job resumed
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "404":
+//     description: Job not found
+//   "409":
+//     description: Job cannot be resumed in its current state
+func (a *apiV2Server) resumeJob(w http.ResponseWriter, r *http.Request) {
+	a.controlJob(w, r, "RESUME JOB $1")
+}
+
+// swagger:operation POST /jobs/{job_id}/cancel/ cancelJob
+//
+// Cancel a job
+//
+// Request the job to be canceled. The job is canceled asynchronously.
+//
+// Client must be logged-in as a user with admin privileges or the
+// CONTROLJOB role option. Jobs owned by admin users can only be
+// controlled by admin users.
+//
+// ---
+// parameters:
+// - name: job_id
+//   type: integer
+//   in: path
+//   description: ID of the job.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Cancellation requested
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "404":
+//     description: Job not found
+//   "409":
+//     description: Job cannot be canceled in its current state
+func (a *apiV2Server) cancelJob(w http.ResponseWriter, r *http.Request) {
+	a.controlJob(w, r, "CANCEL JOB $1")
+}
+
+func (a *apiV2Server) controlJob(w http.ResponseWriter, r *http.Request, stmt string) {
+	ctx := r.Context()
+	jobID, err := strconv.ParseInt(mux.Vars(r)["job_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	if a.execAsUser(ctx, w, "api-v2-control-job", stmt, jobID) {
+		writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+	}
+}
+
+// swagger:operation POST /sessions/{session_id}/cancel/ cancelSession
+//
+// Cancel a session
+//
+// Cancel a session, and close its SQL connection.
+//
+// Client must be logged-in as the user who owns the session, as a user
+// with admin privileges, or as a user with the CANCELQUERY role option.
+// Only admin users can cancel the sessions of other admin users.
+//
+// ---
+// parameters:
+// - name: session_id
+//   type: string
+//   in: path
+//   description: ID of the session, in hexadecimal.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Session canceled
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "400":
+//     description: Invalid session ID
+//   "404":
+//     description: Session not found
+func (a *apiV2Server) cancelSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sessionID, err := sql.StringToClusterWideID(mux.Vars(r)["session_id"])
+	if err != nil {
+		http.Error(w, "invalid session ID", http.StatusBadRequest)
+		return
+	}
+	username := getSQLUsername(ctx)
+	ctx = apiToOutgoingGatewayCtx(ctx, r)
+	resp, err := a.status.CancelSession(ctx, &serverpb.CancelSessionRequest{
+		NodeId:    fmt.Sprintf("%d", sessionID.GetNodeID()),
+		SessionID: sessionID.GetBytes(),
+		Username:  username.Normalized(),
+	})
+	if err != nil {
+		apiV2SQLError(ctx, err, w)
+		return
+	}
+	if !resp.Canceled {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+}
+
+// swagger:operation POST /queries/{query_id}/cancel/ cancelQuery
+//
+// Cancel a query
+//
+// Cancel a running query.
+//
+// Client must be logged-in as the user who runs the query, as a user
+// with admin privileges, or as a user with the CANCELQUERY role option.
+// Only admin users can cancel the queries of other admin users.
+//
+// ---
+// parameters:
+// - name: query_id
+//   type: string
+//   in: path
+//   description: ID of the query, in hexadecimal.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Query canceled
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "400":
+//     description: Invalid query ID
+//   "404":
+//     description: Query not found
+func (a *apiV2Server) cancelQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	queryIDString := mux.Vars(r)["query_id"]
+	queryID, err := sql.StringToClusterWideID(queryIDString)
+	if err != nil {
+		http.Error(w, "invalid query ID", http.StatusBadRequest)
+		return
+	}
+	username := getSQLUsername(ctx)
+	ctx = apiToOutgoingGatewayCtx(ctx, r)
+	resp, err := a.status.CancelQuery(ctx, &serverpb.CancelQueryRequest{
+		// The lowest 32 bits of the query ID are the ID of the node running
+		// the query.
+		NodeId:   fmt.Sprintf("%d", 0xFFFFFFFF&queryID.Lo),
+		QueryID:  queryIDString,
+		Username: username.Normalized(),
+	})
+	if err != nil {
+		apiV2SQLError(ctx, err, w)
+		return
+	}
+	if !resp.Canceled {
+		http.Error(w, resp.Error, http.StatusNotFound)
+		return
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+}
+
+// Request for setClusterSetting.
+//
+// swagger:model setClusterSettingRequest
+type setClusterSettingRequest struct {
+	// The new value of the setting, in the same format as for SET CLUSTER
+	// SETTING.
+	Value string `json:"value"`
+}
+
+// lookupClusterSetting returns the name of the cluster setting in the
+// request path. If there is no such setting, an HTTP 404 error is sent
+// and false is returned.
+func (a *apiV2Server) lookupClusterSetting(w http.ResponseWriter, r *http.Request) (string, bool) {
+	name := mux.Vars(r)["setting_name"]
+	forSystemTenant := a.admin.server.sqlServer.execCfg.Codec.ForSystemTenant()
+	if _, ok := settings.Lookup(name, settings.LookupForLocalAccess, forSystemTenant); !ok {
+		http.Error(w, "cluster setting not found", http.StatusNotFound)
+		return "", false
+	}
+	return name, true
+}
+
+// swagger:operation PUT /cluster/settings/{setting_name}/ setClusterSetting
+//
+// Change a cluster setting
+//
+// Change the value of a cluster setting.
+//
+// Client must be logged-in as a user with admin privileges or the
+// MODIFYCLUSTERSETTING role option.
+//
+// ---
+// parameters:
+// - name: setting_name
+//   type: string
+//   in: path
+//   description: Name of the cluster setting.
+//   required: true
+// - name: body
+//   in: body
+//   required: true
+//   schema:
+//     "$ref": "#/definitions/setClusterSettingRequest"
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Cluster setting changed
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "400":
+//     description: Invalid value
+//   "404":
+//     description: Cluster setting not found
+func (a *apiV2Server) setClusterSetting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, ok := a.lookupClusterSetting(w, r)
+	if !ok {
+		return
+	}
+	var req setClusterSettingRequest
+	if !decodeAPIV2RequestBody(w, r, &req) {
+		return
+	}
+	// The name is that of an existing setting, so it does not need to be
+	// escaped.
+	stmt := fmt.Sprintf("SET CLUSTER SETTING %s = %s", name, lexbase.EscapeSQLString(req.Value))
+	if a.execAsUser(ctx, w, "api-v2-set-cluster-setting", stmt) {
+		writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+	}
+}
+
+// swagger:operation DELETE /cluster/settings/{setting_name}/ resetClusterSetting
+//
+// Reset a cluster setting
+//
+// Reset a cluster setting to its default value.
+//
+// Client must be logged-in as a user with admin privileges or the
+// MODIFYCLUSTERSETTING role option.
+//
+// ---
+// parameters:
+// - name: setting_name
+//   type: string
+//   in: path
+//   description: Name of the cluster setting.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Cluster setting reset
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "404":
+//     description: Cluster setting not found
+func (a *apiV2Server) resetClusterSetting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, ok := a.lookupClusterSetting(w, r)
+	if !ok {
+		return
+	}
+	if a.execAsUser(ctx, w, "api-v2-reset-cluster-setting", fmt.Sprintf("RESET CLUSTER SETTING %s", name)) {
+		writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+	}
+}
+
+// Request for setZoneConfig.
+//
+// swagger:model setZoneConfigRequest
+type setZoneConfigRequest struct {
+	// The zone configuration, in YAML. Only the fields present are
+	// changed.
+	Config string `json:"config"`
+}
+
+// zoneConfigTarget returns the target of a zone configuration change
+// from the request path, i.e. either `DATABASE db` or `TABLE db.table`.
+func zoneConfigTarget(r *http.Request) (string, error) {
+	pathVars := mux.Vars(r)
+	dbName := pathVars["database_name"]
+	tableName, ok := pathVars["table_name"]
+	if !ok {
+		return "DATABASE " + tree.NameString(dbName), nil
+	}
+	escQualTable, err := getFullyQualifiedTableName(dbName, tableName)
+	if err != nil {
+		return "", err
+	}
+	return "TABLE " + escQualTable, nil
+}
+
+// swagger:operation PUT /databases/{database}/zone/ setDatabaseZoneConfig
+//
+// Change the zone configuration of a database
+//
+// Change the zone configuration of a database. The fields of the
+// configuration which are not specified are left unchanged.
+//
+// Client must be logged-in as a user with the privileges needed to
+// configure the zone of the database.
+//
+// ---
+// parameters:
+// - name: database
+//   type: string
+//   in: path
+//   description: Name of the database.
+//   required: true
+// - name: body
+//   in: body
+//   required: true
+//   schema:
+//     "$ref": "#/definitions/setZoneConfigRequest"
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Zone configuration changed
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "400":
+//     description: Invalid zone configuration
+//   "404":
+//     description: Database not found
+
+// swagger:operation PUT /databases/{database}/tables/{table}/zone/ setTableZoneConfig
+//
+// Change the zone configuration of a table
+//
+// Change the zone configuration of a table. The fields of the
+// configuration which are not specified are left unchanged.
+//
+// Client must be logged-in as a user with the privileges needed to
+// configure the zone of the table.
+//
+// ---
+// parameters:
+// - name: database
+//   type: string
+//   in: path
+//   description: Name of the database.
+//   required: true
+// - name: table
+//   type: string
+//   in: path
+//   description: Name of the table, optionally schema-qualified.
+//   required: true
+// - name: body
+//   in: body
+//   required: true
+//   schema:
+//     "$ref": "#/definitions/setZoneConfigRequest"
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Zone configuration changed
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "400":
+//     description: Invalid zone configuration
+//   "404":
+//     description: Database or table not found
+func (a *apiV2Server) setZoneConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	target, err := zoneConfigTarget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req setZoneConfigRequest
+	if !decodeAPIV2RequestBody(w, r, &req) {
+		return
+	}
+	stmt := fmt.Sprintf("ALTER %s CONFIGURE ZONE = $1", target)
+	if a.execAsUser(ctx, w, "api-v2-set-zone-config", stmt, req.Config) {
+		writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+	}
+}
+
+// swagger:operation DELETE /databases/{database}/zone/ discardDatabaseZoneConfig
+//
+// Remove the zone configuration of a database
+//
+// Remove the zone configuration of a database, which then inherits the
+// default zone configuration.
+//
+// Client must be logged-in as a user with the privileges needed to
+// configure the zone of the database.
+//
+// ---
+// parameters:
+// - name: database
+//   type: string
+//   in: path
+//   description: Name of the database.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Zone configuration removed
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "404":
+//     description: Database not found
+
+// swagger:operation DELETE /databases/{database}/tables/{table}/zone/ discardTableZoneConfig
+//
+// Remove the zone configuration of a table
+//
+// Remove the zone configuration of a table, which then inherits the
+// zone configuration of its database.
+//
+// Client must be logged-in as a user with the privileges needed to
+// configure the zone of the table.
+//
+// ---
+// parameters:
+// - name: database
+//   type: string
+//   in: path
+//   description: Name of the database.
+//   required: true
+// - name: table
+//   type: string
+//   in: path
+//   description: Name of the table, optionally schema-qualified.
+//   required: true
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Zone configuration removed
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "404":
+//     description: Database or table not found
+func (a *apiV2Server) discardZoneConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	target, err := zoneConfigTarget(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stmt := fmt.Sprintf("ALTER %s CONFIGURE ZONE DISCARD", target)
+	if a.execAsUser(ctx, w, "api-v2-discard-zone-config", stmt) {
+		writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+	}
+}
+
+// Request for drainNode.
+//
+// swagger:model drainNodeRequest
+type drainNodeRequest struct {
+	// Whether to shut down the node after draining it.
+	Shutdown bool `json:"shutdown,omitempty"`
+}
+
+// Response for drainNode.
+//
+// swagger:model drainNodeResponse
+type drainNodeResponse serverpb.DrainResponse
+
+// swagger:operation POST /nodes/{node_id}/drain/ drainNode
+//
+// Drain a node
+//
+// Run one round of draining on a node: close its SQL client connections
+// and transfer away its range leases. The response reports the amount
+// of work remaining; as with `cockroach node drain`, the request should
+// be repeated until no work remains.
+//
+// If the node is to be shut down, draining is instead repeated until no
+// work remains before the node is shut down, as with `cockroach node
+// drain --shutdown`.
+//
+// Client must be logged-in as a user with admin privileges.
+//
+// ---
+// parameters:
+// - name: node_id
+//   type: string
+//   in: path
+//   description: ID of the node to drain, or `local` for the node
+//     serving the request.
+//   required: true
+// - name: body
+//   in: body
+//   required: false
+//   schema:
+//     "$ref": "#/definitions/drainNodeRequest"
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Drain response
+//     schema:
+//       "$ref": "#/definitions/drainNodeResponse"
+//   "400":
+//     description: Invalid node ID
+func (a *apiV2Server) drainNode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ctx = a.admin.server.AnnotateCtx(ctx)
+	nodeID, _, err := a.status.parseNodeID(mux.Vars(r)["node_id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req drainNodeRequest
+	if !decodeAPIV2RequestBody(w, r, &req) {
+		return
+	}
+
+	client, err := a.admin.dialNode(ctx, nodeID)
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	resp, err := drainNodeRound(ctx, client, &serverpb.DrainRequest{
+		NodeId:  nodeID.String(),
+		DoDrain: true,
+	})
+	// The node is only shut down once it is fully drained, so that its
+	// leases and SQL connections are not dropped abruptly.
+	for err == nil && req.Shutdown && resp.IsDraining && resp.DrainRemainingIndicator > 0 {
+		// Avoid a busy loop if the node replies with an incomplete drain too
+		// quickly.
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			continue
+		case <-time.After(200 * time.Millisecond):
+		}
+		resp, err = drainNodeRound(ctx, client, &serverpb.DrainRequest{
+			NodeId:  nodeID.String(),
+			DoDrain: true,
+		})
+	}
+	if err != nil {
+		apiV2InternalError(ctx, err, w)
+		return
+	}
+	if req.Shutdown {
+		// The response to the shutdown request may be lost as the node shuts
+		// down, so it is not checked.
+		if _, err := drainNodeRound(ctx, client, &serverpb.DrainRequest{
+			NodeId:   nodeID.String(),
+			Shutdown: true,
+		}); err != nil && !grpcutil.IsClosedConnection(err) {
+			apiV2InternalError(ctx, err, w)
+			return
+		}
+	}
+	writeJSONResponse(ctx, w, http.StatusOK, drainNodeResponse(*resp))
+}
+
+// drainNodeRound sends the given drain request to a node, and returns the
+// last response it sent.
+func drainNodeRound(
+	ctx context.Context, client serverpb.AdminClient, req *serverpb.DrainRequest,
+) (*serverpb.DrainResponse, error) {
+	stream, err := client.Drain(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp := &serverpb.DrainResponse{}
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			return resp, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp = r
+	}
+}
+
+// Request for createStatementDiagnostics.
+//
+// swagger:model createStatementDiagnosticsRequest
+type createStatementDiagnosticsRequest struct {
+	// Fingerprint of the statements to collect diagnostics bundles for.
+	StatementFingerprint string `json:"statement_fingerprint"`
+	// Probability with which each execution of the fingerprint is traced.
+	// Zero traces every execution.
+	SamplingProbability float64 `json:"sampling_probability,omitempty"`
+	// Minimum execution latency of the executions to collect bundles for, as
+	// an interval (e.g. `500ms`).
+	MinExecutionLatency string `json:"min_execution_latency,omitempty"`
+	// Interval after which the request expires. The request never expires if
+	// empty.
+	ExpiresAfter string `json:"expires_after,omitempty"`
+	// Number of bundles to collect before the request is completed.
+	NumSamples int `json:"num_samples,omitempty"`
+	// Only collect bundles for the executions whose plan differs from the plan
+	// of the previous execution of the fingerprint on the same node.
+	OnPlanChange bool `json:"on_plan_change,omitempty"`
+}
+
+// swagger:operation POST /statement_diagnostics/ createStatementDiagnostics
+//
+// Request statement diagnostics bundles
+//
+// Request the collection of diagnostics bundles for the executions of a
+// statement fingerprint which satisfy the given conditions.
+//
+// Client must be logged-in as a user with admin privileges or the
+// VIEWACTIVITY role option, and without the VIEWACTIVITYREDACTED role
+// option.
+//
+// ---
+// parameters:
+// - name: body
+//   in: body
+//   required: true
+//   schema:
+//     "$ref": "#/definitions/createStatementDiagnosticsRequest"
+// produces:
+// - application/json
+// security:
+// - api_session: []
+// responses:
+//   "200":
+//     description: Statement diagnostics requested
+//     schema:
+//       "$ref": "#/definitions/writeResponse"
+//   "400":
+//     description: Invalid statement diagnostics request
+func (a *apiV2Server) createStatementDiagnostics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req createStatementDiagnosticsRequest
+	if !decodeAPIV2RequestBody(w, r, &req) {
+		return
+	}
+	if req.StatementFingerprint == "" {
+		http.Error(w, "statement_fingerprint is required", http.StatusBadRequest)
+		return
+	}
+	intervalOrZero := func(s string) string {
+		if s == "" {
+			return "0s"
+		}
+		return s
+	}
+	if a.execAsUser(
+		ctx, w, "api-v2-create-statement-diagnostics",
+		"SELECT crdb_internal.request_statement_bundle($1, $2, $3::INTERVAL, $4::INTERVAL, $5, $6)",
+		req.StatementFingerprint, req.SamplingProbability, intervalOrZero(req.MinExecutionLatency),
+		intervalOrZero(req.ExpiresAfter), req.NumSamples, req.OnPlanChange,
+	) {
+		writeJSONResponse(ctx, w, http.StatusOK, writeResponse{})
+	}
+}
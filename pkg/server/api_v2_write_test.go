@@ -0,0 +1,281 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+// doAPIV2Request sends a request to the v2 API, with the given body
+// encoded in JSON if non-nil, and returns the status code and the body
+// of the response.
+func doAPIV2Request(
+	t *testing.T,
+	ts serverutils.TestServerInterface,
+	client http.Client,
+	method, path string,
+	body interface{},
+) (int, []byte) {
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+	req, err := http.NewRequest(method, ts.AdminURL()+apiV2Path+path, &reqBody)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+	respBody, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp.StatusCode, respBody
+}
+
+func TestControlJobsV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer ts.Stopper().Stop(ctx)
+
+	adminClient, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	nonAdminClient, err := ts.GetAuthenticatedHTTPClient(false)
+	require.NoError(t, err)
+
+	for _, op := range []string{"pause", "resume", "cancel"} {
+		t.Run(op, func(t *testing.T) {
+			path := fmt.Sprintf("jobs/%d/%s/", 123456789, op)
+
+			// Only POST is allowed.
+			code, _ := doAPIV2Request(t, ts, adminClient, "GET", path, nil)
+			require.Equal(t, http.StatusMethodNotAllowed, code)
+
+			// A non-admin user needs the CONTROLJOB role option.
+			code, body := doAPIV2Request(t, ts, nonAdminClient, "POST", path, nil)
+			require.Equal(t, http.StatusForbidden, code)
+			require.Contains(t, string(body), "not allowed")
+
+			code, _ = doAPIV2Request(t, ts, adminClient, "POST", path, nil)
+			require.Equal(t, http.StatusNotFound, code)
+		})
+	}
+}
+
+func TestClusterSettingsV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer ts.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	adminClient, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	nonAdminClient, err := ts.GetAuthenticatedHTTPClient(false)
+	require.NoError(t, err)
+
+	const path = "cluster/settings/sql.metrics.statement_details.threshold/"
+
+	code, _ := doAPIV2Request(t, ts, adminClient, "PUT", path, setClusterSettingRequest{Value: "5s"})
+	require.Equal(t, http.StatusOK, code)
+	sqlDB.CheckQueryResults(t,
+		"SHOW CLUSTER SETTING sql.metrics.statement_details.threshold", [][]string{{"00:00:05"}})
+
+	code, _ = doAPIV2Request(t, ts, adminClient, "PUT", path, setClusterSettingRequest{Value: "invalid"})
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = doAPIV2Request(t, ts, adminClient, "DELETE", path, nil)
+	require.Equal(t, http.StatusOK, code)
+	sqlDB.CheckQueryResults(t,
+		"SHOW CLUSTER SETTING sql.metrics.statement_details.threshold", [][]string{{"00:00:00"}})
+
+	code, _ = doAPIV2Request(t, ts, adminClient, "PUT", "cluster/settings/no.such.setting/",
+		setClusterSettingRequest{Value: "1"})
+	require.Equal(t, http.StatusNotFound, code)
+
+	// A non-admin user needs the MODIFYCLUSTERSETTING role option.
+	code, _ = doAPIV2Request(t, ts, nonAdminClient, "PUT", path, setClusterSettingRequest{Value: "5s"})
+	require.Equal(t, http.StatusForbidden, code)
+	sqlDB.Exec(t, fmt.Sprintf("ALTER USER %s MODIFYCLUSTERSETTING", authenticatedUserNameNoAdmin().SQLIdentifier()))
+	code, _ = doAPIV2Request(t, ts, nonAdminClient, "PUT", path, setClusterSettingRequest{Value: "5s"})
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestZoneConfigsV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer ts.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, "CREATE DATABASE testdb; CREATE TABLE testdb.t (a INT PRIMARY KEY)")
+
+	adminClient, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		path   string
+		target string
+	}{
+		{"databases/testdb/zone/", "DATABASE testdb"},
+		{"databases/testdb/tables/t/zone/", "TABLE testdb.t"},
+		{"databases/testdb/tables/public.t/zone/", "TABLE testdb.public.t"},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			code, body := doAPIV2Request(t, ts, adminClient, "PUT", tc.path,
+				setZoneConfigRequest{Config: "gc: {ttlseconds: 1234}"})
+			require.Equal(t, http.StatusOK, code, string(body))
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(
+				`SELECT raw_config_sql LIKE '%%gc.ttlseconds = 1234%%' FROM [SHOW ZONE CONFIGURATION FOR %s]`,
+				tc.target), [][]string{{"true"}})
+
+			code, _ = doAPIV2Request(t, ts, adminClient, "PUT", tc.path,
+				setZoneConfigRequest{Config: "num_replicas: -1"})
+			require.Equal(t, http.StatusBadRequest, code)
+
+			code, _ = doAPIV2Request(t, ts, adminClient, "DELETE", tc.path, nil)
+			require.Equal(t, http.StatusOK, code)
+			sqlDB.CheckQueryResults(t, fmt.Sprintf(
+				`SELECT raw_config_sql LIKE '%%gc.ttlseconds = 1234%%' FROM [SHOW ZONE CONFIGURATION FOR %s]`,
+				tc.target), [][]string{{"false"}})
+		})
+	}
+
+	code, _ := doAPIV2Request(t, ts, adminClient, "PUT", "databases/nodb/zone/",
+		setZoneConfigRequest{Config: "gc: {ttlseconds: 1234}"})
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestCancelSessionV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer ts.Stopper().Stop(ctx)
+
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	var sessionID string
+	require.NoError(t, conn.QueryRowContext(ctx,
+		"SELECT session_id FROM [SHOW session_id]").Scan(&sessionID))
+
+	adminClient, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	nonAdminClient, err := ts.GetAuthenticatedHTTPClient(false)
+	require.NoError(t, err)
+
+	// A non-admin user cannot cancel the session of an admin user.
+	path := fmt.Sprintf("sessions/%s/cancel/", sessionID)
+	code, _ := doAPIV2Request(t, ts, nonAdminClient, "POST", path, nil)
+	require.Equal(t, http.StatusForbidden, code)
+
+	code, body := doAPIV2Request(t, ts, adminClient, "POST", path, nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+	_, err = conn.ExecContext(ctx, "SELECT 1")
+	require.Error(t, err)
+
+	code, _ = doAPIV2Request(t, ts, adminClient, "POST", path, nil)
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestDrainNodeV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer ts.Stopper().Stop(ctx)
+
+	adminClient, err := ts.GetAdminAuthenticatedHTTPClient()
+	require.NoError(t, err)
+	nonAdminClient, err := ts.GetAuthenticatedHTTPClient(false)
+	require.NoError(t, err)
+
+	code, _ := doAPIV2Request(t, ts, nonAdminClient, "POST", "nodes/local/drain/", nil)
+	require.Equal(t, http.StatusForbidden, code)
+
+	code, body := doAPIV2Request(t, ts, adminClient, "POST", "nodes/local/drain/", drainNodeRequest{})
+	require.Equal(t, http.StatusOK, code, string(body))
+	var resp drainNodeResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.True(t, resp.IsDraining)
+}
+
+func TestOpenAPISpecV2(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	ts, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer ts.Stopper().Stop(ctx)
+
+	client, err := ts.GetHTTPClient()
+	require.NoError(t, err)
+	code, body := doAPIV2Request(t, ts, client, "GET", "openapi.json", nil)
+	require.Equal(t, http.StatusOK, code)
+
+	var doc openAPIDocument
+	require.NoError(t, json.Unmarshal(body, &doc))
+	require.Equal(t, "2.0", doc.Swagger)
+
+	op, ok := doc.Paths["/jobs/{job_id}/pause/"]["post"]
+	require.True(t, ok)
+	require.Equal(t, "pauseJob", op.OperationID)
+	require.Equal(t, "CONTROLJOB", op.RequiredRoleOption)
+	require.Equal(t, []openAPIParameter{
+		{Name: "job_id", In: "path", Type: "string", Pattern: "[0-9]+", Required: true},
+	}, op.Parameters)
+	require.NotEmpty(t, op.Security)
+
+	op, ok = doc.Paths["/nodes/{node_id}/drain/"]["post"]
+	require.True(t, ok)
+	require.Equal(t, "admin", op.RequiredRole)
+	require.Len(t, op.Parameters, 2)
+	require.Equal(t, "body", op.Parameters[1].In)
+	require.False(t, op.Parameters[1].Required)
+	require.Equal(t, &openAPISchema{
+		Type:       "object",
+		Properties: map[string]openAPISchema{"shutdown": {Type: "boolean"}},
+	}, op.Parameters[1].Schema)
+
+	op, ok = doc.Paths["/cluster/settings/{setting_name}/"]["put"]
+	require.True(t, ok)
+	require.Equal(t, []string{"value"}, op.Parameters[len(op.Parameters)-1].Schema.Required)
+
+	// Operation IDs are unique.
+	ids := make(map[string]bool)
+	for _, ops := range doc.Paths {
+		for _, op := range ops {
+			require.False(t, ids[op.OperationID], op.OperationID)
+			ids[op.OperationID] = true
+		}
+	}
+	require.True(t, ids["post_login"])
+}
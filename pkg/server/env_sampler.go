@@ -37,6 +37,7 @@ type sampleEnvironmentCfg struct {
 	heapProfileDirName   string
 	runtime              *status.RuntimeStatSampler
 	sessionRegistry      *sql.SessionRegistry
+	cpuProfileRecorder   heapprofiler.CPUProfileRecorder
 }
 
 // startSampleEnvironment starts a periodic loop that samples the environment and,
@@ -49,6 +50,7 @@ func startSampleEnvironment(
 	heapProfileDirName string,
 	runtimeSampler *status.RuntimeStatSampler,
 	sessionRegistry *sql.SessionRegistry,
+	cpuProfileRecorder heapprofiler.CPUProfileRecorder,
 ) error {
 	cfg := sampleEnvironmentCfg{
 		st:                   settings,
@@ -58,6 +60,7 @@ func startSampleEnvironment(
 		heapProfileDirName:   heapProfileDirName,
 		runtime:              runtimeSampler,
 		sessionRegistry:      sessionRegistry,
+		cpuProfileRecorder:   cpuProfileRecorder,
 	}
 	// Immediately record summaries once on server startup.
 
@@ -90,6 +93,7 @@ func startSampleEnvironment(
 	var nonGoAllocProfiler *heapprofiler.NonGoAllocProfiler
 	var statsProfiler *heapprofiler.StatsProfiler
 	var queryProfiler *heapprofiler.ActiveQueryProfiler
+	var cpuProfiler *heapprofiler.CPUProfiler
 	if cfg.heapProfileDirName != "" {
 		hasValidDumpDir := true
 		if err := os.MkdirAll(cfg.heapProfileDirName, 0755); err != nil {
@@ -120,6 +124,10 @@ func startSampleEnvironment(
 			if err != nil {
 				log.Warningf(ctx, "failed to start query profiler worker: %v", err)
 			}
+			cpuProfiler, err = heapprofiler.NewCPUProfiler(ctx, cfg.heapProfileDirName, cfg.st, cfg.cpuProfileRecorder)
+			if err != nil {
+				log.Warningf(ctx, "failed to start CPU profiler worker: %v", err)
+			}
 		}
 	}
 
@@ -188,6 +196,9 @@ func startSampleEnvironment(
 					if queryProfiler != nil {
 						queryProfiler.MaybeDumpQueries(ctx, cfg.sessionRegistry, cfg.st)
 					}
+					if cpuProfiler != nil {
+						cpuProfiler.MaybeTakeProfile(ctx, cfg.stopper)
+					}
 				}
 			}
 		})
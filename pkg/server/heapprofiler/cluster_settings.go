@@ -10,7 +10,11 @@
 
 package heapprofiler
 
-import "github.com/cockroachdb/cockroach/pkg/settings"
+import (
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+)
 
 // ActiveQueryDumpsEnabled wraps "diagnostics.active_query_dumps.enabled"
 //
@@ -26,3 +30,34 @@ var ActiveQueryDumpsEnabled = settings.RegisterBoolSetting(
 	"experimental: enable dumping of anonymized active queries to disk when node is under memory pressure",
 	true,
 ).WithPublic()
+
+// CPUProfileInterval wraps "server.cpu_profile.continuous.interval".
+//
+// server.cpu_profile.continuous.interval enables the periodic collection of
+// CPU profiles with statement labels, whose samples are attributed to
+// statement fingerprints.
+var CPUProfileInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"server.cpu_profile.continuous.interval",
+	"if nonzero, take a CPU profile with pprof labels at this interval and attribute its samples "+
+		"to statement fingerprints (see cpu_nanos in crdb_internal.node_statement_statistics); "+
+		"on-demand CPU profiles cannot be taken while a continuous profile is in progress",
+	0,
+	settings.NonNegativeDuration,
+).WithPublic()
+
+// CPUProfileDuration wraps "server.cpu_profile.continuous.duration".
+var CPUProfileDuration = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"server.cpu_profile.continuous.duration",
+	"the duration of each continuous CPU profile",
+	10*time.Second,
+	settings.PositiveDuration,
+)
+
+var maxCombinedCPUProfFileSize = settings.RegisterByteSizeSetting(
+	settings.TenantWritable,
+	"server.cpu_profile.total_dump_size_limit",
+	"maximum combined disk size of preserved continuous CPU profiles",
+	128<<20, // 128MiB
+)
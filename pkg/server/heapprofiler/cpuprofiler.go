@@ -0,0 +1,174 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package heapprofiler
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/debug"
+	"github.com/cockroachdb/cockroach/pkg/server/dumpstore"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/errors"
+	"github.com/google/pprof/profile"
+)
+
+// CPUProfiler is used to take CPU profiles continuously.
+//
+// MaybeTakeProfile() is supposed to be called periodically. A profile with
+// pprof labels is taken in the background every
+// server.cpu_profile.continuous.interval. The goroutines executing SQL
+// statements carry the fingerprint ID of the statement, the application
+// name and the tenant in their labels, so the samples of each profile can
+// be attributed to statement fingerprints by a CPUProfileRecorder.
+// Profiles are also GCed periodically. The latest is always kept, and a
+// couple of the ones with the largest CPU usage are also kept.
+type CPUProfiler struct {
+	profiler
+	st       *cluster.Settings
+	recorder CPUProfileRecorder
+
+	// running is set to 1 while a profile is being taken.
+	running int32
+}
+
+// CPUProfileRecorder is notified of the continuous CPU profiles taken by a
+// CPUProfiler.
+type CPUProfileRecorder interface {
+	// SetContinuousProfiling is called with whether continuous profiling is
+	// enabled every time the CPUProfiler checks whether to take a profile.
+	// Statements need to carry their pprof labels for as long as it is
+	// enabled, so that statements that started before a profile is taken
+	// are attributed too.
+	SetContinuousProfiling(enabled bool)
+	// RecordProfile is called with every profile taken.
+	RecordProfile(p *profile.Profile)
+}
+
+const (
+	// CPUFileNamePrefix is the prefix of files containing pprof data.
+	CPUFileNamePrefix = "cpuprof"
+	// CPUFileNameSuffix is the suffix of files containing pprof data.
+	CPUFileNameSuffix = ".pprof"
+)
+
+// NewCPUProfiler creates a CPUProfiler. dir is the directory in which
+// profiles are to be stored. recorder, if not nil, is notified of every
+// profile taken.
+func NewCPUProfiler(
+	ctx context.Context, dir string, st *cluster.Settings, recorder CPUProfileRecorder,
+) (*CPUProfiler, error) {
+	if dir == "" {
+		return nil, errors.AssertionFailedf("need to specify dir for NewCPUProfiler")
+	}
+
+	dumpStore := dumpstore.NewStore(dir, maxCombinedCPUProfFileSize, st)
+
+	cp := &CPUProfiler{
+		profiler: profiler{
+			store: newProfileStore(dumpStore, CPUFileNamePrefix, CPUFileNameSuffix, st),
+		},
+		st:       st,
+		recorder: recorder,
+	}
+	return cp, nil
+}
+
+// MaybeTakeProfile starts taking a CPU profile in the background if
+// continuous profiling is enabled and the last profile is old enough.
+func (o *CPUProfiler) MaybeTakeProfile(ctx context.Context, stopper *stop.Stopper) {
+	interval := CPUProfileInterval.Get(&o.st.SV)
+	if o.recorder != nil {
+		o.recorder.SetContinuousProfiling(interval != 0)
+	}
+	if interval == 0 {
+		return
+	}
+	now := o.now()
+	if now.Sub(o.lastProfileTime) < interval {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&o.running, 0, 1) {
+		return
+	}
+	o.lastProfileTime = now
+	if err := stopper.RunAsyncTask(ctx, "cpu-profiler", func(ctx context.Context) {
+		defer atomic.StoreInt32(&o.running, 0)
+		o.takeProfile(ctx, stopper, now)
+	}); err != nil {
+		atomic.StoreInt32(&o.running, 0)
+	}
+}
+
+// takeProfile takes a CPU profile, attributes its samples to statement
+// fingerprints, and writes it to the profile store.
+func (o *CPUProfiler) takeProfile(ctx context.Context, stopper *stop.Stopper, now time.Time) {
+	var buf bytes.Buffer
+	if err := debug.CPUProfileDo(o.st, cluster.CPUProfileWithLabels, func() error {
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+		select {
+		case <-stopper.ShouldQuiesce():
+		case <-time.After(CPUProfileDuration.Get(&o.st.SV)):
+		}
+		return nil
+	}); err != nil {
+		// This happens when a CPU profile is requested by a user in the
+		// meantime; we'll try again at the next interval.
+		log.VEventf(ctx, 1, "unable to take continuous CPU profile: %v", err)
+		return
+	}
+
+	p, err := profile.Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		log.Warningf(ctx, "error parsing CPU profile: %v", err)
+		return
+	}
+	if o.recorder != nil {
+		o.recorder.RecordProfile(p)
+	}
+
+	if o.knobs.dontWriteProfiles {
+		return
+	}
+	path := o.store.makeNewFileName(now, totalCPUNanos(p))
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		log.Warningf(ctx, "error writing CPU profile %s: %v", path, err)
+		return
+	}
+	o.store.gcProfiles(ctx, now)
+}
+
+// totalCPUNanos returns the CPU time of all the samples of a CPU profile.
+func totalCPUNanos(p *profile.Profile) int64 {
+	valueIdx := -1
+	for i, st := range p.SampleType {
+		if st.Type == "cpu" {
+			valueIdx = i
+		}
+	}
+	if valueIdx < 0 {
+		return 0
+	}
+	var total int64
+	for _, s := range p.Sample {
+		total += s.Value[valueIdx]
+	}
+	return total
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package heapprofiler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/errors"
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCPUProfiler(t *testing.T) {
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+
+	_, err := NewCPUProfiler(ctx, "", st, nil /* recorder */)
+	require.EqualError(t, err, "need to specify dir for NewCPUProfiler")
+
+	profiler, err := NewCPUProfiler(ctx, heapProfilerDirName, st, nil /* recorder */)
+	require.NoError(t, err)
+	require.NotNil(t, profiler)
+}
+
+func TestCPUProfilerMaybeTakeProfile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	CPUProfileDuration.Override(ctx, &st.SV, time.Millisecond)
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+
+	var currentTime time.Time
+	cp, err := NewCPUProfiler(ctx, heapProfilerDirName, st, nil /* recorder */)
+	require.NoError(t, err)
+	cp.knobs = testingKnobs{
+		now:               func() time.Time { return currentTime },
+		dontWriteProfiles: true,
+	}
+	waitForProfile := func() {
+		testutils.SucceedsSoon(t, func() error {
+			if atomic.LoadInt32(&cp.running) != 0 {
+				return errors.New("profile still running")
+			}
+			return nil
+		})
+	}
+
+	// No profile is taken while continuous profiling is disabled.
+	currentTime = (time.Time{}).Add(time.Hour)
+	cp.MaybeTakeProfile(ctx, stopper)
+	require.True(t, cp.lastProfileTime.IsZero())
+
+	CPUProfileInterval.Override(ctx, &st.SV, time.Minute)
+	for i, tc := range []struct {
+		secs      int // The time at which MaybeTakeProfile is called.
+		expLatest int // The time of the latest profile after the call.
+	}{
+		{0, 0},     // we always take the first profile
+		{30, 0},    // within the interval; no profile
+		{60, 60},   // the interval has elapsed
+		{100, 60},  // within the interval; no profile
+		{200, 200}, // the interval has elapsed
+	} {
+		currentTime = (time.Time{}).Add(time.Hour + time.Duration(tc.secs)*time.Second)
+		cp.MaybeTakeProfile(ctx, stopper)
+		waitForProfile()
+		expLatest := (time.Time{}).Add(time.Hour + time.Duration(tc.expLatest)*time.Second)
+		require.Equal(t, expLatest, cp.lastProfileTime, i)
+	}
+}
+
+func TestTotalCPUNanos(t *testing.T) {
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		Sample: []*profile.Sample{
+			{Value: []int64{1, 10000000}},
+			{Value: []int64{3, 30000000}},
+		},
+	}
+	require.Equal(t, int64(40000000), totalCPUNanos(p))
+
+	p.SampleType = p.SampleType[:1]
+	require.Equal(t, int64(0), totalCPUNanos(p))
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/status"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/logtags"
+)
+
+const maxOTLPMetricsInterval = 15 * time.Minute
+
+var (
+	// otlpMetricsEndpoint is host:port, if any, of the OpenTelemetry
+	// collector to which metrics are pushed.
+	otlpMetricsEndpoint = settings.RegisterStringSetting(
+		settings.TenantWritable,
+		"external.otlp.metrics.endpoint",
+		"if nonempty, push server metrics to the OpenTelemetry collector at the specified host:port using OTLP/gRPC",
+		"",
+	).WithPublic()
+	// otlpMetricsInterval is how often metrics are pushed to the
+	// collector, if enabled.
+	otlpMetricsInterval = settings.RegisterDurationSetting(
+		settings.TenantWritable,
+		"external.otlp.metrics.interval",
+		"the interval at which metrics are pushed to the OpenTelemetry collector (if enabled)",
+		10*time.Second,
+		settings.NonNegativeDurationWithMaximum(maxOTLPMetricsInterval),
+	).WithPublic()
+	// otlpMetricsInsecure disables TLS on the connection to the
+	// collector.
+	otlpMetricsInsecure = settings.RegisterBoolSetting(
+		settings.TenantWritable,
+		"external.otlp.metrics.insecure",
+		"if set, connect to the OpenTelemetry collector without TLS",
+		false,
+	).WithPublic()
+	// otlpMetricsHistogramBuckets determines the buckets of the
+	// histograms pushed to the collector.
+	otlpMetricsHistogramBuckets = settings.RegisterEnumSetting(
+		settings.TenantWritable,
+		"external.otlp.metrics.histogram_buckets",
+		"the buckets of the histograms pushed to the OpenTelemetry collector: "+
+			"explicit uses the buckets exposed to Prometheus, power_of_two merges them into "+
+			"buckets with power-of-two bounds; both are pushed as explicit-bucket histograms, "+
+			"exponential histograms are not supported",
+		"explicit",
+		map[int64]string{
+			int64(metric.OTLPExplicitBuckets):   "explicit",
+			int64(metric.OTLPPowerOfTwoBuckets): "power_of_two",
+		},
+	).WithPublic()
+)
+
+// maybeStartOTLPMetricsExporter starts pushing metrics to an
+// OpenTelemetry collector once external.otlp.metrics.endpoint is set.
+// The given attributes identify the server in the pushed metrics.
+func maybeStartOTLPMetricsExporter(
+	ctx context.Context,
+	stopper *stop.Stopper,
+	st *cluster.Settings,
+	recorder *status.MetricsRecorder,
+	resourceAttrs map[string]string,
+) {
+	var once sync.Once
+	otlpMetricsEndpoint.SetOnChange(&st.SV, func(context.Context) {
+		if otlpMetricsEndpoint.Get(&st.SV) != "" {
+			once.Do(func() {
+				startOTLPMetricsExporter(ctx, stopper, st, recorder, resourceAttrs)
+			})
+		}
+	})
+}
+
+func startOTLPMetricsExporter(
+	ctx context.Context,
+	stopper *stop.Stopper,
+	st *cluster.Settings,
+	recorder *status.MetricsRecorder,
+	resourceAttrs map[string]string,
+) {
+	ctx = logtags.AddTag(ctx, "otlp metrics exporter", nil)
+	pm := metric.MakePrometheusExporter()
+	oe := metric.MakeOTLPExporter(&pm, resourceAttrs)
+
+	_ = stopper.RunAsyncTask(ctx, "otlp-metrics-exporter", func(ctx context.Context) {
+		defer func() {
+			if err := oe.Close(); err != nil {
+				log.Warningf(ctx, "error closing connection to OTLP collector: %v", err)
+			}
+		}()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(otlpMetricsInterval.Get(&st.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+				endpoint := otlpMetricsEndpoint.Get(&st.SV)
+				if endpoint != "" {
+					opts := metric.OTLPExporterOptions{
+						Insecure:   otlpMetricsInsecure.Get(&st.SV),
+						Histograms: metric.OTLPHistogramBuckets(otlpMetricsHistogramBuckets.Get(&st.SV)),
+						Timeout:    10 * time.Second,
+					}
+					if err := recorder.ExportToOTLP(ctx, endpoint, &pm, &oe, opts); err != nil {
+						log.Infof(ctx, "error pushing metrics to OTLP collector: %s", err)
+					}
+				}
+			}
+		}
+	})
+}
@@ -1330,6 +1330,7 @@ func (s *Server) PreStart(ctx context.Context) error {
 		s.cfg.HeapProfileDirName,
 		s.runtime,
 		s.status.sessionRegistry,
+		s.sqlServer.execCfg.StatementCPUUsage,
 	); err != nil {
 		return err
 	}
@@ -1342,6 +1343,11 @@ func (s *Server) PreStart(ctx context.Context) error {
 			})
 		}
 	})
+	maybeStartOTLPMetricsExporter(s.AnnotateCtx(context.Background()), s.stopper, s.st, s.recorder,
+		map[string]string{
+			"node_id":   s.NodeID().String(),
+			"tenant_id": strconv.FormatUint(roachpb.SystemTenantID.ToUint64(), 10),
+		})
 
 	// Start the protected timestamp subsystem. Note that this needs to happen
 	// before the modeOperational switch below, as the protected timestamps
@@ -1499,6 +1505,9 @@ func (s *Server) PreStart(ctx context.Context) error {
 		s.cfg.AmbientCtx, s.recorder, base.DefaultMetricsSampleInterval, ts.Resolution10s, s.stopper,
 	)
 
+	// Begin evaluating the alerting rules against the recorded time series.
+	s.startAlerting(workersCtx)
+
 	return maybeImportTS(ctx, s)
 }
 
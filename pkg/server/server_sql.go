@@ -71,6 +71,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/gcjob/gcjobnotifier"
 	"github.com/cockroachdb/cockroach/pkg/sql/optionalnodeliveness"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/planpin"
 	"github.com/cockroachdb/cockroach/pkg/sql/querycache"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scdeps"
 	"github.com/cockroachdb/cockroach/pkg/sql/schemachanger/scrun"
@@ -359,13 +360,14 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		return nil, err
 	}
 	execCfg := &sql.ExecutorConfig{}
-	codec := keys.MakeSQLCodec(cfg.SQLConfig.TenantID)
+	codecTenantID := cfg.SQLConfig.TenantID
 	if knobs := cfg.TestingKnobs.TenantTestingKnobs; knobs != nil {
 		override := knobs.(*sql.TenantTestingKnobs).TenantIDCodecOverride
 		if override != (roachpb.TenantID{}) {
-			codec = keys.MakeSQLCodec(override)
+			codecTenantID = override
 		}
 	}
+	codec := keys.MakeSQLCodec(codecTenantID)
 	// Create blob service for inter-node file sharing.
 	blobService, err := blobs.NewBlobService(cfg.Settings.ExternalIODir)
 	if err != nil {
@@ -693,6 +695,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		CompactEngineSpanFunc:   compactEngineSpanFunc,
 		TraceCollector:          traceCollector,
 		TenantUsageServer:       cfg.tenantUsageServer,
+		StatementCPUUsage:       sql.NewStatementCPUUsage(codecTenantID),
 
 		DistSQLPlanner: sql.NewDistSQLPlanner(
 			ctx,
@@ -812,6 +815,7 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 
 	distSQLServer.ServerConfig.SQLStatsController = pgServer.SQLServer.GetSQLStatsController()
 	distSQLServer.ServerConfig.IndexUsageStatsController = pgServer.SQLServer.GetIndexUsageStatsController()
+	execCfg.IndexAdvisor = sql.NewIndexAdvisor(execCfg)
 
 	// Now that we have a pgwire.Server (which has a sql.Server), we can close a
 	// circular dependency between the rowexec.Server and sql.Server and set
@@ -866,6 +870,8 @@ func newSQLServer(ctx context.Context, cfg sqlServerArgs) (*SQLServer, error) {
 		cfg.Settings,
 	)
 	execCfg.StmtDiagnosticsRecorder = stmtDiagnosticsRegistry
+	planPinRegistry := planpin.NewRegistry(cfg.circularInternalExecutor, cfg.Settings)
+	execCfg.PlanPinRegistry = planPinRegistry
 
 	{
 		// We only need to attach a version upgrade hook if we're the system
@@ -1118,6 +1124,8 @@ func (s *SQLServer) preStart(
 		return err
 	}
 	s.stmtDiagnosticsRegistry.Start(ctx, stopper)
+	s.execCfg.PlanPinRegistry.Start(ctx, stopper)
+	s.execCfg.IndexAdvisor.Start(ctx, stopper)
 
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
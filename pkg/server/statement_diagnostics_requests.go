@@ -82,7 +82,8 @@ func (s *statusServer) CreateStatementDiagnosticsReport(
 	}
 
 	err := s.stmtDiagnosticsRequester.InsertRequest(
-		ctx, req.StatementFingerprint, req.MinExecutionLatency, req.ExpiresAfter,
+		ctx, req.StatementFingerprint, req.SamplingProbability, int(req.NumSamples),
+		req.OnPlanChange, req.MinExecutionLatency, req.ExpiresAfter,
 	)
 	if err != nil {
 		return nil, err
@@ -273,7 +273,7 @@ func (b *baseStatusServer) checkCancelPrivilege(
 		// Check if the user has permission to see the session.
 		session, err := findSession(b.sessionRegistry.SerializeAll())
 		if err != nil {
-			return serverError(ctx, err)
+			return status.Error(codes.NotFound, err.Error())
 		}
 
 		sessionUser := security.MakeSQLUsernameFromPreNormalizedString(session.Username)
@@ -431,9 +431,19 @@ type StmtDiagnosticsRequester interface {
 	// and the bundle is not generated for them.
 	// - expiresAfter, if non-zero, indicates for how long the request should
 	// stay active.
+	// - samplingProbability, if non-zero, is the probability with which a
+	// query that matches the fingerprint is traced.
+	// - numSamples, if greater than one, is the number of bundles collected
+	// before the request is completed.
+	// - onPlanChange, if set, restricts the collection to the queries whose
+	// plan differs from the one of the previous query with the same
+	// fingerprint on the same node.
 	InsertRequest(
 		ctx context.Context,
 		stmtFingerprint string,
+		samplingProbability float64,
+		numSamples int,
+		onPlanChange bool,
 		minExecutionLatency time.Duration,
 		expiresAfter time.Duration,
 	) error
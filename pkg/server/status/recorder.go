@@ -296,6 +296,22 @@ func (mr *MetricsRecorder) ExportToGraphite(
 	return graphiteExporter.Push(ctx, endpoint)
 }
 
+// ExportToOTLP sends the current metric values to an OpenTelemetry
+// collector. pm must be the PrometheusExporter the OTLPExporter was
+// created with. As with ExportToGraphite, the caller provides a
+// separate PrometheusExporter to avoid races with
+// mr.promMu.prometheusExporter.
+func (mr *MetricsRecorder) ExportToOTLP(
+	ctx context.Context,
+	endpoint string,
+	pm *metric.PrometheusExporter,
+	oe *metric.OTLPExporter,
+	opts metric.OTLPExporterOptions,
+) error {
+	mr.scrapeIntoPrometheus(pm)
+	return oe.Push(ctx, endpoint, opts)
+}
+
 // GetTimeSeriesData serializes registered metrics for consumption by
 // CockroachDB's time series system.
 func (mr *MetricsRecorder) GetTimeSeriesData() []tspb.TimeSeriesData {
@@ -337,6 +353,35 @@ func (mr *MetricsRecorder) GetTimeSeriesData() []tspb.TimeSeriesData {
 	return data
 }
 
+// GetTimeSeriesNames returns the names of the time series recorded by
+// GetTimeSeriesData, keyed by the name under which the corresponding value is
+// exported to Prometheus.
+func (mr *MetricsRecorder) GetTimeSeriesNames() map[string]string {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	if mr.mu.nodeRegistry == nil {
+		// We haven't yet processed initialization information; do nothing.
+		if log.V(1) {
+			log.Warning(context.TODO(), "MetricsRecorder.GetTimeSeriesNames() called before NodeID allocation")
+		}
+		return nil
+	}
+
+	names := make(map[string]string)
+	eachRecordableValue(mr.mu.nodeRegistry, func(name string, _ float64) {
+		names[metric.ExportedName(name)] = fmt.Sprintf(nodeTimeSeriesPrefix, name)
+	})
+	// All stores have the same metrics.
+	for _, r := range mr.mu.storeRegistries {
+		eachRecordableValue(r, func(name string, _ float64) {
+			names[metric.ExportedName(name)] = fmt.Sprintf(storeTimeSeriesPrefix, name)
+		})
+		break
+	}
+	return names
+}
+
 // GetMetricsMetadata returns the metadata from all metrics tracked in the node's
 // nodeRegistry and a randomly selected storeRegistry.
 func (mr *MetricsRecorder) GetMetricsMetadata() map[string]metric.Metadata {
@@ -13,6 +13,7 @@ package server
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -315,6 +316,7 @@ func startTenantInternal(
 		args.HeapProfileDirName,
 		args.runtime,
 		args.sessionRegistry,
+		s.execCfg.StatementCPUUsage,
 	); err != nil {
 		return nil, nil, nil, "", "", err
 	}
@@ -330,6 +332,12 @@ func startTenantInternal(
 		return nil, nil, nil, "", "", err
 	}
 
+	maybeStartOTLPMetricsExporter(background, args.stopper, args.Settings, args.recorder,
+		map[string]string{
+			"tenant_id":   strconv.FormatUint(sqlCfg.TenantID.ToUint64(), 10),
+			"instance_id": s.SQLInstanceID().String(),
+		})
+
 	externalUsageFn := func(ctx context.Context) multitenant.ExternalUsage {
 		userTimeMillis, _, err := status.GetCPUTime(ctx)
 		if err != nil {
@@ -0,0 +1,152 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/resolver"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/explain"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/planpin"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
+)
+
+// alterStatementFingerprintNode represents an ALTER STATEMENT FINGERPRINT
+// statement.
+type alterStatementFingerprintNode struct {
+	n           *tree.AlterStatementFingerprint
+	fingerprint func() (string, error)
+	planGist    func() (string, error)
+	hints       func() (string, error)
+}
+
+// AlterStatementFingerprint pins or unpins the indexes used by the plans of a
+// statement fingerprint.
+// Privileges: admin.
+func (p *planner) AlterStatementFingerprint(
+	ctx context.Context, n *tree.AlterStatementFingerprint,
+) (planNode, error) {
+	if err := p.RequireAdminRole(ctx, "ALTER STATEMENT FINGERPRINT"); err != nil {
+		return nil, err
+	}
+	if p.execCfg.PlanPinRegistry == nil {
+		return nil, errors.AssertionFailedf("plan pin registry not available")
+	}
+	node := &alterStatementFingerprintNode{n: n}
+	var err error
+	if node.fingerprint, err = p.TypeAsString(ctx, n.Fingerprint, "ALTER STATEMENT FINGERPRINT"); err != nil {
+		return nil, err
+	}
+	if n.PlanGist != nil {
+		if node.planGist, err = p.TypeAsString(ctx, n.PlanGist, "ALTER STATEMENT FINGERPRINT"); err != nil {
+			return nil, err
+		}
+	}
+	if n.Hints != nil {
+		if node.hints, err = p.TypeAsString(ctx, n.Hints, "ALTER STATEMENT FINGERPRINT"); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func (n *alterStatementFingerprintNode) startExec(params runParams) error {
+	fingerprint, err := n.fingerprint()
+	if err != nil {
+		return err
+	}
+	id, err := planpin.ParseFingerprintID(fingerprint)
+	if err != nil {
+		return err
+	}
+	registry := params.ExecCfg().PlanPinRegistry
+	switch {
+	case n.n.Unpin:
+		return registry.Unpin(params.ctx, id)
+
+	case n.hints != nil:
+		hints, err := n.hints()
+		if err != nil {
+			return err
+		}
+		indexes, err := params.p.resolveIndexHints(params.ctx, hints)
+		if err != nil {
+			return err
+		}
+		return registry.Pin(params.ctx, id, indexes, "" /* planGist */)
+
+	default:
+		gist, err := n.planGist()
+		if err != nil {
+			return err
+		}
+		decoded, err := explain.DecodePlanGistToIndexes(gist, &params.p.optPlanningCtx.catalog)
+		if err != nil {
+			return pgerror.Wrapf(err, pgcode.InvalidParameterValue, "invalid plan gist %q", gist)
+		}
+		if len(decoded) == 0 {
+			return pgerror.Newf(pgcode.InvalidParameterValue,
+				"plan gist %q does not access any table through a single index", gist)
+		}
+		indexes := make(planpin.Indexes, len(decoded))
+		for table, index := range decoded {
+			indexes[descpb.ID(table)] = descpb.IndexID(index)
+		}
+		return registry.Pin(params.ctx, id, indexes, gist)
+	}
+}
+
+// resolveIndexHints resolves the tables and indexes of the given
+// comma-separated list of table@index hints. Tables are resolved like in any
+// other statement, so that pins are keyed by table ID.
+func (p *planner) resolveIndexHints(ctx context.Context, hints string) (planpin.Indexes, error) {
+	parsed, err := planpin.ParseHints(hints)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(planpin.Indexes, len(parsed))
+	for _, h := range parsed {
+		tn, err := parser.ParseQualifiedTableName(h.Table)
+		if err != nil {
+			return nil, pgerror.Wrapf(err, pgcode.InvalidParameterValue,
+				"invalid table name %q in index hint", h.Table)
+		}
+		flags := tree.ObjectLookupFlagsWithRequiredTableKind(tree.ResolveRequireTableDesc)
+		_, desc, err := resolver.ResolveExistingTableObject(ctx, p, tn, flags)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := indexes[desc.GetID()]; ok {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"multiple index hints for table %q", h.Table)
+		}
+		idx, err := desc.FindIndexWithName(string(h.Index))
+		if err != nil {
+			return nil, pgerror.Newf(pgcode.UndefinedObject,
+				"index %q not found in table %q", h.Index, h.Table)
+		}
+		if idx.IsPartial() || idx.GetType() == descpb.IndexDescriptor_INVERTED {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"partial and inverted indexes cannot be pinned: %q", h.Index)
+		}
+		indexes[desc.GetID()] = idx.GetID()
+	}
+	return indexes, nil
+}
+
+func (*alterStatementFingerprintNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterStatementFingerprintNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterStatementFingerprintNode) Close(context.Context)        {}
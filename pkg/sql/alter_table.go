@@ -437,6 +437,11 @@ func (n *alterTableNode) startExec(params runParams) error {
 				// 	return err
 				// }
 
+			case *tree.ExcludeConstraintTableDef:
+				return unimplemented.NewWithIssueDetail(46657,
+					"alter table add constraint exclude",
+					"exclusion constraints can only be added in CREATE TABLE")
+
 			default:
 				return errors.AssertionFailedf(
 					"unsupported constraint: %T", t.ConstraintDef)
@@ -793,11 +798,11 @@ func (n *alterTableNode) startExec(params runParams) error {
 				return pgerror.Newf(pgcode.DuplicateObject,
 					"duplicate constraint name: %q", tree.ErrString(&t.NewName))
 			}
-			// If this is a unique or primary constraint, renames of the constraint
-			// lead to renames of the underlying index. Ensure that no index with this
+			// If this is a unique, primary or exclusion constraint, renames of the
+			// constraint lead to renames of the underlying index. Ensure that no index with this
 			// new name exists. This is what postgres does.
 			switch details.Kind {
-			case descpb.ConstraintTypeUnique, descpb.ConstraintTypePK:
+			case descpb.ConstraintTypeUnique, descpb.ConstraintTypePK, descpb.ConstraintTypeExclusion:
 				if catalog.FindNonDropIndex(n.tableDesc, func(idx catalog.Index) bool {
 					return idx.GetName() == string(t.NewName)
 				}) != nil {
@@ -1381,6 +1386,9 @@ func validateConstraintNameIsNotUsed(
 	case *tree.ForeignKeyConstraintTableDef:
 		name = d.Name
 		hasIfNotExists = d.IfNotExists
+	case *tree.ExcludeConstraintTableDef:
+		name = d.Name
+		hasIfNotExists = d.IfNotExists
 	case *tree.UniqueConstraintTableDef:
 		name = d.Name
 		hasIfNotExists = d.IfNotExists
@@ -0,0 +1,448 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/errors"
+)
+
+// This file implements rule-based SQL audit logging. The cluster
+// setting sql.log.audit_rules contains a list of rules, separated by
+// semicolons or newlines. Each rule is a list of space-separated
+// filters of the form key=value, where value can be a comma-separated
+// list of alternatives. A statement matches a rule if it matches all
+// the filters in the rule. The supported filters are:
+//
+//  - role: the current user is, or is a member of, one of the roles.
+//  - app: the application name is one of the values. A value ending
+//    with '*' matches any application name with that prefix.
+//  - type: the statement type is one of DDL, DML, DCL or TCL.
+//  - database: the statement accesses a table in one of the
+//    databases, or accesses no table and the current database is one
+//    of the databases.
+//  - schema: the statement accesses a table in one of the schemas.
+//  - access: the statement reads (read) or writes (write) a table
+//    matching the database and schema filters.
+//  - channel: the logging channel to which the matching statements
+//    are reported (default SENSITIVE_ACCESS). This is not a filter.
+//
+// For example, "role=admin type=DDL" reports all DDL statements
+// executed by members of the admin role, and "schema=pii access=read"
+// reports every read of a table in a schema called pii.
+//
+// Each statement that matches a rule is reported with an AuditRuleMatch
+// event.
+
+var auditRules = settings.RegisterValidatedStringSetting(
+	settings.TenantWritable,
+	"sql.log.audit_rules",
+	"rules selecting the SQL statements to report to the audit log, separated by semicolons; "+
+		"each rule is a space-separated list of key=value filters with keys "+
+		"role, app, type, database, schema, access and channel",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := parseAuditRules(s)
+		return err
+	},
+).WithPublic()
+
+// auditRule is a parsed rule from the sql.log.audit_rules cluster
+// setting. Empty filter lists match everything.
+type auditRule struct {
+	// raw is the text of the rule, as reported in the logging events.
+	raw string
+
+	roles     []security.SQLUsername
+	apps      []string
+	stmtTypes []tree.StatementType
+	databases []string
+	schemas   []string
+	// reads and writes are set if the rule has an access filter.
+	reads, writes bool
+
+	channel logpb.Channel
+}
+
+// needsRoles returns whether the evaluation of the rule requires the
+// role memberships of the current user.
+func (r *auditRule) needsRoles() bool {
+	return len(r.roles) > 0
+}
+
+// hasObjectFilters returns whether the rule filters on the tables
+// accessed by the statement.
+func (r *auditRule) hasObjectFilters() bool {
+	return len(r.schemas) > 0 || r.reads || r.writes
+}
+
+var auditStmtTypes = map[string]tree.StatementType{
+	"DDL": tree.TypeDDL,
+	"DML": tree.TypeDML,
+	"DCL": tree.TypeDCL,
+	"TCL": tree.TypeTCL,
+}
+
+// parseAuditRules parses the value of the sql.log.audit_rules cluster
+// setting.
+func parseAuditRules(s string) ([]auditRule, error) {
+	var rules []auditRule
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		rule, err := parseAuditRule(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid audit rule %q", line)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseAuditRule(s string) (auditRule, error) {
+	r := auditRule{
+		raw:     strings.Join(strings.Fields(s), " "),
+		channel: logpb.Channel_SENSITIVE_ACCESS,
+	}
+	seen := make(map[string]bool)
+	numFilters := 0
+	for _, f := range strings.Fields(s) {
+		eq := strings.IndexByte(f, '=')
+		if eq <= 0 || eq == len(f)-1 {
+			return r, errors.Newf("expected key=value, found %q", f)
+		}
+		key, value := strings.ToLower(f[:eq]), f[eq+1:]
+		if seen[key] {
+			return r, errors.Newf("duplicate key %q", key)
+		}
+		seen[key] = true
+		if key != "channel" {
+			numFilters++
+		}
+		for _, v := range strings.Split(value, ",") {
+			if v == "" {
+				return r, errors.Newf("empty value for key %q", key)
+			}
+			switch key {
+			case "role":
+				u, err := security.MakeSQLUsernameFromUserInput(v, security.UsernameValidation)
+				if err != nil {
+					return r, errors.Wrapf(err, "invalid role %q", v)
+				}
+				r.roles = append(r.roles, u)
+			case "app":
+				r.apps = append(r.apps, v)
+			case "type":
+				t, ok := auditStmtTypes[strings.ToUpper(v)]
+				if !ok {
+					return r, errors.Newf("unknown statement type %q (expected DDL, DML, DCL or TCL)", v)
+				}
+				r.stmtTypes = append(r.stmtTypes, t)
+			case "database":
+				r.databases = append(r.databases, lexbase.NormalizeName(v))
+			case "schema":
+				r.schemas = append(r.schemas, lexbase.NormalizeName(v))
+			case "access":
+				switch strings.ToLower(v) {
+				case "read":
+					r.reads = true
+				case "write":
+					r.writes = true
+				default:
+					return r, errors.Newf("unknown access mode %q (expected read or write)", v)
+				}
+			case "channel":
+				ch, ok := logpb.Channel_value[strings.ToUpper(v)]
+				if !ok || strings.Contains(value, ",") {
+					return r, errors.Newf("unknown logging channel %q", v)
+				}
+				r.channel = logpb.Channel(ch)
+			default:
+				return r, errors.Newf("unknown key %q", key)
+			}
+		}
+	}
+	if numFilters == 0 {
+		return r, errors.New("rule must contain at least one filter")
+	}
+	return r, nil
+}
+
+// parsedAuditRules caches the result of parsing a value of the
+// sql.log.audit_rules cluster setting.
+type parsedAuditRules struct {
+	raw   string
+	rules []auditRule
+	// needsRoles is set if any of the rules filters on roles.
+	needsRoles bool
+}
+
+var auditRulesCache atomic.Value // *parsedAuditRules
+
+// getAuditRules returns the audit rules configured in the current
+// value of the sql.log.audit_rules cluster setting. The value is
+// parsed only when it changes.
+func getAuditRules(sv *settings.Values) *parsedAuditRules {
+	raw := auditRules.Get(sv)
+	if cached, ok := auditRulesCache.Load().(*parsedAuditRules); ok && cached.raw == raw {
+		return cached
+	}
+	// The value was validated when the setting was changed, so errors
+	// can only come from values that were written by an older version
+	// with a different syntax. We ignore the invalid rules in that case.
+	rules, _ := parseAuditRules(raw)
+	res := &parsedAuditRules{raw: raw, rules: rules}
+	for i := range rules {
+		if rules[i].needsRoles() {
+			res.needsRoles = true
+		}
+	}
+	auditRulesCache.Store(res)
+	return res
+}
+
+// auditRoleCache is stored in extraTxnState and caches the role
+// memberships of the current user for the evaluation of the audit
+// rules. Like HasAdminRoleCache, it does not have to be reset when a
+// transaction restarts.
+type auditRoleCache struct {
+	// roles contains the current user and all the roles it is directly
+	// or indirectly a member of.
+	roles map[security.SQLUsername]bool
+
+	// isSet is used to determine if the value for caching is set or not.
+	isSet bool
+}
+
+// auditRuleInput is the information about a statement that audit rules
+// are matched against.
+type auditRuleInput struct {
+	roles       map[security.SQLUsername]bool
+	appName     string
+	stmtType    tree.StatementType
+	curDatabase string
+	tables      []auditTable
+}
+
+// auditTable is a table accessed by the statement being audited.
+type auditTable struct {
+	id       descpb.ID
+	database string
+	schema   string
+	name     string
+	writing  bool
+}
+
+// matches returns whether the statement described by in matches the
+// rule, along with the tables accessed by the statement that match the
+// object filters of the rule.
+func (r *auditRule) matches(in *auditRuleInput) (bool, []auditTable) {
+	if len(r.stmtTypes) > 0 {
+		found := false
+		for _, t := range r.stmtTypes {
+			if t == in.stmtType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	if len(r.apps) > 0 {
+		found := false
+		for _, a := range r.apps {
+			if strings.HasSuffix(a, "*") {
+				found = strings.HasPrefix(in.appName, strings.TrimSuffix(a, "*"))
+			} else {
+				found = a == in.appName
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	if len(r.roles) > 0 {
+		found := false
+		for _, role := range r.roles {
+			if in.roles[role] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	if !r.hasObjectFilters() && len(r.databases) > 0 && len(in.tables) == 0 {
+		// Statements that do not access tables are matched against the
+		// current database.
+		return containsString(r.databases, in.curDatabase), nil
+	}
+	if !r.hasObjectFilters() && len(r.databases) == 0 {
+		return true, nil
+	}
+	var matched []auditTable
+	for _, t := range in.tables {
+		if len(r.databases) > 0 && !containsString(r.databases, t.database) {
+			continue
+		}
+		if len(r.schemas) > 0 && !containsString(r.schemas, t.schema) {
+			continue
+		}
+		if (r.reads || r.writes) && !((r.writes && t.writing) || (r.reads && !t.writing)) {
+			continue
+		}
+		matched = append(matched, t)
+	}
+	return len(matched) > 0, matched
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// maybeRecordAccessForAuditRules records the access to the given
+// descriptor for the evaluation of the audit rules, if any audit rule
+// is configured. It is called from maybeAudit().
+func (p *planner) maybeRecordAccessForAuditRules(desc catalog.Descriptor, writing bool) {
+	if _, ok := desc.(catalog.TableDescriptor); !ok {
+		return
+	}
+	if len(getAuditRules(&p.execCfg.Settings.SV).rules) == 0 {
+		return
+	}
+	p.curPlan.accessedTables = append(p.curPlan.accessedTables, auditEvent{desc: desc, writing: writing})
+}
+
+// maybeCacheAuditRoles populates the audit role cache if any audit rule
+// filters on roles. It must be called before the execution of the
+// statement, since role memberships can only be looked up in a valid
+// transaction. Audit logging must not cause statements to fail, so an
+// error looking up the role memberships is logged, and the rules filtering
+// on roles then only match the user itself.
+func (p *planner) maybeCacheAuditRoles(ctx context.Context, cache *auditRoleCache) {
+	if cache.isSet || !getAuditRules(&p.execCfg.Settings.SV).needsRoles {
+		return
+	}
+	memberOf, err := p.MemberOfWithAdminOption(ctx, p.User())
+	if err != nil {
+		log.Warningf(ctx, "unable to look up the roles of %s for audit rules: %v", p.User(), err)
+		cache.roles = map[security.SQLUsername]bool{p.User(): true}
+		return
+	}
+	roles := make(map[security.SQLUsername]bool, len(memberOf)+1)
+	roles[p.User()] = true
+	for role := range memberOf {
+		roles[role] = true
+	}
+	cache.roles = roles
+	cache.isSet = true
+}
+
+// auditTables returns the tables accessed by the current statement,
+// deduplicated and sorted by ID.
+func (p *planner) auditTables(ctx context.Context) []auditTable {
+	if len(p.curPlan.accessedTables) == 0 {
+		return nil
+	}
+	byID := make(map[descpb.ID]int, len(p.curPlan.accessedTables))
+	var tables []auditTable
+	for _, ev := range p.curPlan.accessedTables {
+		if i, ok := byID[ev.desc.GetID()]; ok {
+			tables[i].writing = tables[i].writing || ev.writing
+			continue
+		}
+		t := auditTable{id: ev.desc.GetID(), name: ev.desc.GetName(), writing: ev.writing}
+		tn, err := p.getQualifiedTableName(ctx, ev.desc.(catalog.TableDescriptor))
+		if err != nil {
+			log.Warningf(ctx, "name for table ID %d not found: %v", ev.desc.GetID(), err)
+		} else {
+			t.database = tn.Catalog()
+			t.schema = tn.Schema()
+			t.name = tn.FQString()
+		}
+		byID[t.id] = len(tables)
+		tables = append(tables, t)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].id < tables[j].id })
+	return tables
+}
+
+// logAuditRuleMatches reports the current statement with an
+// AuditRuleMatch event on the channel of every audit rule it matches.
+func (p *planner) logAuditRuleMatches(
+	ctx context.Context,
+	rules []auditRule,
+	roles *auditRoleCache,
+	execDetails eventpb.CommonSQLExecDetails,
+) {
+	in := auditRuleInput{
+		roles:       roles.roles,
+		appName:     p.SessionData().ApplicationName,
+		stmtType:    p.stmt.AST.StatementType(),
+		curDatabase: p.SessionData().Database,
+	}
+	for i := range rules {
+		// Only resolve the names of the accessed tables if needed.
+		if rules[i].hasObjectFilters() || len(rules[i].databases) > 0 {
+			in.tables = p.auditTables(ctx)
+			break
+		}
+	}
+	for i := range rules {
+		ok, tables := rules[i].matches(&in)
+		if !ok {
+			continue
+		}
+		ev := &eventpb.AuditRuleMatch{
+			CommonSQLExecDetails: execDetails,
+			Rule:                 rules[i].raw,
+			StatementType:        strings.TrimPrefix(in.stmtType.String(), "Type"),
+		}
+		if len(tables) > 0 {
+			ev.AccessMode = "r"
+			ev.TableNames = make([]string, len(tables))
+			for j, t := range tables {
+				ev.TableNames[j] = t.name
+				if t.writing {
+					ev.AccessMode = "rw"
+				}
+			}
+		}
+		*ev.CommonSQLDetails() = p.getCommonSQLEventDetails()
+		log.StructuredEventOnChannel(ctx, rules[i].channel, ev)
+	}
+}
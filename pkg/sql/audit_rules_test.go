@@ -0,0 +1,261 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"encoding/json"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/cockroachdb/redact"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuditRules(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	rules, err := parseAuditRules(`role=admin type=DDL; schema=PII access=read channel=sql_exec
+		app=myapp*,other  database=db1`)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	require.Equal(t, "role=admin type=DDL", rules[0].raw)
+	require.Equal(t, []security.SQLUsername{security.AdminRoleName()}, rules[0].roles)
+	require.Equal(t, []tree.StatementType{tree.TypeDDL}, rules[0].stmtTypes)
+	require.Equal(t, logpb.Channel_SENSITIVE_ACCESS, rules[0].channel)
+
+	require.Equal(t, []string{"pii"}, rules[1].schemas)
+	require.True(t, rules[1].reads)
+	require.False(t, rules[1].writes)
+	require.Equal(t, logpb.Channel_SQL_EXEC, rules[1].channel)
+
+	require.Equal(t, []string{"myapp*", "other"}, rules[2].apps)
+	require.Equal(t, []string{"db1"}, rules[2].databases)
+
+	for _, tc := range []struct {
+		rule string
+		err  string
+	}{
+		{"role", `expected key=value, found "role"`},
+		{"role=", `expected key=value, found "role="`},
+		{"color=red", `unknown key "color"`},
+		{"type=DQL", `unknown statement type "DQL"`},
+		{"access=delete", `unknown access mode "delete"`},
+		{"type=DDL channel=nope", `unknown logging channel "nope"`},
+		{"channel=SQL_EXEC", `rule must contain at least one filter`},
+		{"role=a role=b", `duplicate key "role"`},
+		{"app=a,,b", `empty value for key "app"`},
+	} {
+		t.Run(tc.rule, func(t *testing.T) {
+			_, err := parseAuditRules(tc.rule)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestAuditRuleMatches(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	piiRead := auditTable{id: 52, database: "db", schema: "pii", name: "db.pii.t"}
+	piiWrite := auditTable{id: 53, database: "db", schema: "pii", name: "db.pii.u", writing: true}
+	publicRead := auditTable{id: 54, database: "db", schema: "public", name: "db.public.v"}
+	admin := map[security.SQLUsername]bool{security.RootUserName(): true, security.AdminRoleName(): true}
+	user := map[security.SQLUsername]bool{security.TestUserName(): true}
+
+	for _, tc := range []struct {
+		rule    string
+		in      auditRuleInput
+		matches bool
+		tables  []auditTable
+	}{
+		{"role=admin type=DDL", auditRuleInput{roles: admin, stmtType: tree.TypeDDL}, true, nil},
+		{"role=admin type=DDL", auditRuleInput{roles: user, stmtType: tree.TypeDDL}, false, nil},
+		{"role=admin type=DDL", auditRuleInput{roles: admin, stmtType: tree.TypeDML}, false, nil},
+		{"app=my* type=DDL,DML", auditRuleInput{appName: "myapp", stmtType: tree.TypeDML}, true, nil},
+		{"app=my*", auditRuleInput{appName: "yourapp"}, false, nil},
+		{"app=my", auditRuleInput{appName: "myapp"}, false, nil},
+		{"database=db", auditRuleInput{curDatabase: "db"}, true, nil},
+		{"database=db", auditRuleInput{curDatabase: "other"}, false, nil},
+		{"database=db", auditRuleInput{curDatabase: "other", tables: []auditTable{publicRead}},
+			true, []auditTable{publicRead}},
+		{"schema=pii access=read", auditRuleInput{tables: []auditTable{piiRead, piiWrite, publicRead}},
+			true, []auditTable{piiRead}},
+		{"schema=pii access=read,write", auditRuleInput{tables: []auditTable{piiRead, piiWrite, publicRead}},
+			true, []auditTable{piiRead, piiWrite}},
+		{"schema=pii", auditRuleInput{tables: []auditTable{publicRead}}, false, nil},
+		{"schema=pii", auditRuleInput{curDatabase: "db"}, false, nil},
+	} {
+		t.Run(tc.rule, func(t *testing.T) {
+			rule, err := parseAuditRule(tc.rule)
+			require.NoError(t, err)
+			matches, tables := rule.matches(&tc.in)
+			require.Equal(t, tc.matches, matches)
+			require.Equal(t, tc.tables, tables)
+		})
+	}
+}
+
+// TestAuditRulesLog verifies that statements matching the audit rules
+// are reported to the sensitive access log.
+func TestAuditRulesLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := log.ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	cleanup := installSensitiveAccessLogFileSink(sc, t)
+	defer cleanup()
+
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.Background())
+
+	db := sqlutils.MakeSQLRunner(sqlDB)
+	db.Exec(t, `SET CLUSTER SETTING sql.log.audit_rules = 'role=admin type=DDL; schema=pii access=read'`)
+	db.ExpectErr(t, `invalid audit rule "color=red"`, `SET CLUSTER SETTING sql.log.audit_rules = 'color=red'`)
+
+	db.Exec(t, `CREATE SCHEMA pii`)
+	db.Exec(t, `CREATE TABLE pii.t (x INT)`)
+	db.Exec(t, `INSERT INTO pii.t VALUES (1), (2)`)
+	db.Exec(t, `SELECT * FROM pii.t`)
+
+	testutils.SucceedsSoon(t, func() error {
+		log.Flush()
+		for _, re := range []*regexp.Regexp{
+			regexp.MustCompile(`"EventType":"audit_rule_match","Statement":"CREATE TABLE.*"Rule":"‹role=admin type=DDL›","StatementType":"DDL"`),
+			regexp.MustCompile(`"EventType":"audit_rule_match","Statement":"SELECT \* FROM.*"NumRows":2.*"Rule":"‹schema=pii access=read›","StatementType":"DML","TableNames":\["‹defaultdb.pii.t›"\],"AccessMode":"r"`),
+		} {
+			entries, err := log.FetchEntriesFromFiles(0, math.MaxInt64, 10000, re,
+				log.WithMarkedSensitiveData)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return errors.Newf("no entries found for %s", re)
+			}
+		}
+		return nil
+	})
+
+	// The INSERT writes to the table and must not match the read rule.
+	insertRe := regexp.MustCompile(`"EventType":"audit_rule_match","Statement":"INSERT INTO`)
+	entries, err := log.FetchEntriesFromFiles(0, math.MaxInt64, 10000, insertRe,
+		log.WithMarkedSensitiveData)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// auditRuleMatchInterceptor collects the AuditRuleMatch events logged.
+type auditRuleMatchInterceptor struct {
+	syncutil.Mutex
+	channels []logpb.Channel
+	events   []eventpb.AuditRuleMatch
+}
+
+func (i *auditRuleMatchInterceptor) Intercept(entry []byte) {
+	var e logpb.Entry
+	if err := json.Unmarshal(entry, &e); err != nil || e.StructuredEnd == 0 {
+		return
+	}
+	payload := e.Message[e.StructuredStart:e.StructuredEnd]
+	if !strings.Contains(payload, `"EventType":"audit_rule_match"`) {
+		return
+	}
+	var ev eventpb.AuditRuleMatch
+	if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+		return
+	}
+	i.Lock()
+	defer i.Unlock()
+	i.channels = append(i.channels, e.Channel)
+	i.events = append(i.events, ev)
+}
+
+// TestAuditRulesEvent verifies that the AuditRuleMatch event is emitted
+// on the channel of the matched rule, for users matching a rule through
+// their role memberships.
+func TestAuditRulesEvent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	interceptor := &auditRuleMatchInterceptor{}
+	defer log.InterceptWith(ctx, interceptor)()
+
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	db := sqlutils.MakeSQLRunner(sqlDB)
+	db.Exec(t, `CREATE ROLE auditees`)
+	db.Exec(t, `CREATE USER testuser`)
+	db.Exec(t, `GRANT auditees TO testuser`)
+	db.Exec(t, `CREATE TABLE t (x INT)`)
+	db.Exec(t, `GRANT SELECT ON t TO testuser`)
+	db.Exec(t, `SET CLUSTER SETTING sql.log.audit_rules = 'role=auditees type=DML channel=sql_exec'`)
+
+	pgURL, cleanupFunc := sqlutils.PGUrl(
+		t, s.ServingSQLAddr(), "TestAuditRulesEvent-testuser", url.User("testuser"),
+	)
+	defer cleanupFunc()
+	testuserDB, err := gosql.Open("postgres", pgURL.String())
+	require.NoError(t, err)
+	defer testuserDB.Close()
+	// Use a single connection so that the session variable set below
+	// applies to the statements that follow.
+	testuserDB.SetMaxOpenConns(1)
+	testuser := sqlutils.MakeSQLRunner(testuserDB)
+
+	// root is not a member of the role, so its statements do not match the
+	// rule, and neither do the statements of other types.
+	db.Exec(t, `SELECT * FROM t WHERE x = 1`)
+	testuser.Exec(t, `SET application_name = 'audited'`)
+	testuser.Exec(t, `SELECT * FROM t WHERE x = 2`)
+
+	testutils.SucceedsSoon(t, func() error {
+		interceptor.Lock()
+		defer interceptor.Unlock()
+		if len(interceptor.events) == 0 {
+			return errors.New("no audit rule match event")
+		}
+		return nil
+	})
+
+	interceptor.Lock()
+	defer interceptor.Unlock()
+	require.Len(t, interceptor.events, 1)
+	require.Equal(t, logpb.Channel_SQL_EXEC, interceptor.channels[0])
+	ev := interceptor.events[0]
+	require.Equal(t, "audit_rule_match", ev.EventType)
+	require.Equal(t, "role=auditees type=DML channel=sql_exec", redact.RedactableString(ev.Rule).StripMarkers())
+	require.Equal(t, "DML", ev.StatementType)
+	require.Equal(t, "testuser", redact.RedactableString(ev.User).StripMarkers())
+	require.Equal(t, "audited", redact.RedactableString(ev.ApplicationName).StripMarkers())
+	require.Contains(t, ev.Statement.StripMarkers(), "SELECT * FROM ")
+	require.Contains(t, ev.Statement.StripMarkers(), "x = 2")
+}
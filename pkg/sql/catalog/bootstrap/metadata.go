@@ -321,6 +321,11 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	// Tables introduced in 22.1.
 	target.AddDescriptorForSystemTenant(systemschema.TenantSettingsTable)
 
+	// Tables introduced in 22.2.
+	target.AddDescriptor(systemschema.StatementPlanPinsTable)
+	target.AddDescriptor(systemschema.StatementPlanRegressionsTable)
+	target.AddDescriptor(systemschema.MaterializedViewRefreshesTable)
+
 	// Adding a new system table? It should be added here to the metadata schema,
 	// and also created as a migration for older clusters.
 }
@@ -84,6 +84,9 @@ const (
 	SQLInstancesTableName                  SystemTableName = "sql_instances"
 	SpanConfigurationsTableName            SystemTableName = "span_configurations"
 	TenantSettingsTableName                SystemTableName = "tenant_settings"
+	StatementPlanPinsTableName             SystemTableName = "statement_plan_pins"
+	StatementPlanRegressionsTableName      SystemTableName = "statement_plan_regressions"
+	MaterializedViewRefreshesTableName     SystemTableName = "materialized_view_refreshes"
 )
 
 // Oid for virtual database and table.
@@ -155,6 +158,7 @@ const (
 	CrdbInternalActiveRangeFeedsTable
 	CrdbInternalTenantUsageDetailsViewID
 	CrdbInternalPgCatalogTableIsImplementedTableID
+	CrdbInternalIndexRecommendationsTableID
 	InformationSchemaID
 	InformationSchemaAdministrableRoleAuthorizationsID
 	InformationSchemaApplicableRolesID
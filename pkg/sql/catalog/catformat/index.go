@@ -95,6 +95,17 @@ func indexForDisplay(
 	}
 
 	f := tree.NewFmtCtx(formatFlags)
+	if displayMode == IndexDisplayDefOnly && index.IsExclusion() {
+		// An index which backs an exclusion constraint is created along with the
+		// constraint, so the constraint is displayed instead.
+		f.WriteString("CONSTRAINT ")
+		f.FormatNameP(&index.Name)
+		f.WriteByte(' ')
+		if err := FormatExclusionConstraint(ctx, table, index, f, semaCtx, sessionData); err != nil {
+			return "", err
+		}
+		return f.CloseAndGetString(), nil
+	}
 	if displayMode == IndexDisplayShowCreate {
 		f.WriteString("CREATE ")
 	}
@@ -177,6 +188,47 @@ func indexForDisplay(
 	return f.CloseAndGetString(), nil
 }
 
+// FormatExclusionConstraint formats the exclusion constraint backed by an
+// index, e.g.:
+//
+//   EXCLUDE USING gist (a WITH =, tstzrange(b, c) WITH &&) WHERE d > 0
+//
+func FormatExclusionConstraint(
+	ctx context.Context,
+	table catalog.TableDescriptor,
+	index *descpb.IndexDescriptor,
+	f *tree.FmtCtx,
+	semaCtx *tree.SemaContext,
+	sessionData *sessiondata.SessionData,
+) error {
+	elems, err := schemaexpr.ExclusionConstraintElemsForDisplay(table, index.ExclusionElements)
+	if err != nil {
+		return err
+	}
+	f.WriteString("EXCLUDE USING gist (")
+	f.FormatNode(&elems)
+	f.WriteByte(')')
+	if index.IsPartial() {
+		predFmtFlag := tree.FmtParsable
+		if f.HasFlags(tree.FmtPGCatalog) {
+			predFmtFlag = tree.FmtPGCatalog
+		}
+		pred, err := schemaexpr.FormatExprForDisplay(ctx, table, index.Predicate, semaCtx, sessionData, predFmtFlag)
+		if err != nil {
+			return err
+		}
+		f.WriteString(" WHERE ")
+		if f.HasFlags(tree.FmtPGCatalog) {
+			f.WriteString("(")
+			f.WriteString(pred)
+			f.WriteString(")")
+		} else {
+			f.WriteString(pred)
+		}
+	}
+	return nil
+}
+
 // FormatIndexElements formats the key columns an index. If the column is an
 // inaccessible computed column, the computed column expression is formatted.
 // Otherwise, the column name is formatted. Each column is separated by commas
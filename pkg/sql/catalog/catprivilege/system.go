@@ -63,6 +63,9 @@ var (
 		catconstants.SQLInstancesTableName,
 		catconstants.SpanConfigurationsTableName,
 		catconstants.TenantSettingsTableName,
+		catconstants.StatementPlanPinsTableName,
+		catconstants.StatementPlanRegressionsTableName,
+		catconstants.MaterializedViewRefreshesTableName,
 	}
 
 	systemSuperuserPrivileges = func() map[descpb.NameInfo]privilege.List {
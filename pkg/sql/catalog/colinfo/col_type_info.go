@@ -105,7 +105,8 @@ func ValidateColumnDefType(t *types.T) error {
 	case types.BitFamily, types.IntFamily, types.FloatFamily, types.BoolFamily, types.BytesFamily, types.DateFamily,
 		types.INetFamily, types.IntervalFamily, types.JsonFamily, types.OidFamily, types.TimeFamily,
 		types.TimestampFamily, types.TimestampTZFamily, types.UuidFamily, types.TimeTZFamily,
-		types.GeographyFamily, types.GeometryFamily, types.EnumFamily, types.Box2DFamily:
+		types.GeographyFamily, types.GeometryFamily, types.EnumFamily, types.Box2DFamily,
+		types.TSQueryFamily, types.TSVectorFamily, types.RangeFamily, types.MultirangeFamily:
 		// These types are OK.
 
 	default:
@@ -123,7 +124,7 @@ func ColumnTypeIsIndexable(t *types.T) bool {
 	}
 	// Some inverted index types also have a key encoding, but we don't
 	// want to support those yet. See #50659.
-	return !MustBeValueEncoded(t) && !ColumnTypeIsInvertedIndexable(t)
+	return !MustBeValueEncoded(t) && !ColumnTypeIsOnlyInvertedIndexable(t)
 }
 
 // ColumnTypeIsInvertedIndexable returns whether the type t is valid to be indexed
@@ -134,7 +135,21 @@ func ColumnTypeIsInvertedIndexable(t *types.T) bool {
 	}
 	family := t.Family()
 	return family == types.JsonFamily || family == types.ArrayFamily ||
-		family == types.GeographyFamily || family == types.GeometryFamily
+		family == types.GeographyFamily || family == types.GeometryFamily ||
+		family == types.TSVectorFamily || family == types.RangeFamily ||
+		family == types.MultirangeFamily
+}
+
+// ColumnTypeIsOnlyInvertedIndexable returns whether the type t can only be
+// indexed using an inverted index. Range and multirange types can be indexed
+// by both forward indexes, which support equality and ordering, and inverted
+// indexes, which support overlap and containment.
+func ColumnTypeIsOnlyInvertedIndexable(t *types.T) bool {
+	if !ColumnTypeIsInvertedIndexable(t) {
+		return false
+	}
+	family := t.Family()
+	return family != types.RangeFamily && family != types.MultirangeFamily
 }
 
 // MustBeValueEncoded returns true if columns of the given kind can only be value
@@ -148,7 +163,8 @@ func MustBeValueEncoded(semanticType *types.T) bool {
 		default:
 			return MustBeValueEncoded(semanticType.ArrayContents())
 		}
-	case types.JsonFamily, types.TupleFamily, types.GeographyFamily, types.GeometryFamily:
+	case types.JsonFamily, types.TupleFamily, types.GeographyFamily, types.GeometryFamily,
+		types.TSQueryFamily, types.TSVectorFamily:
 		return true
 	}
 	return false
@@ -80,6 +80,8 @@ const (
 	ConstraintTypeUnique ConstraintType = "UNIQUE"
 	// ConstraintTypeCheck identifies a CHECK constraint.
 	ConstraintTypeCheck ConstraintType = "CHECK"
+	// ConstraintTypeExclusion identifies an EXCLUDE constraint.
+	ConstraintTypeExclusion ConstraintType = "EXCLUDE"
 )
 
 // ConstraintDetail describes a constraint.
@@ -93,7 +95,7 @@ type ConstraintDetail struct {
 	Details      string
 	Unvalidated  bool
 
-	// Only populated for PK and Unique Constraints with an index.
+	// Only populated for PK, Exclusion and Unique Constraints with an index.
 	Index *IndexDescriptor
 
 	// Only populated for Unique Constraints without an index.
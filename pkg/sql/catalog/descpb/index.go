@@ -28,6 +28,11 @@ func (desc *IndexDescriptor) IsPartial() bool {
 	return desc.Predicate != ""
 }
 
+// IsExclusion returns true if the index backs an exclusion constraint.
+func (desc *IndexDescriptor) IsExclusion() bool {
+	return len(desc.ExclusionElements) > 0
+}
+
 // ExplicitColumnStartIdx returns the start index of any explicit columns.
 func (desc *IndexDescriptor) ExplicitColumnStartIdx() int {
 	start := int(desc.Partitioning.NumImplicitColumns)
@@ -307,6 +307,11 @@ type TableDescriptor interface {
 	// created at, for materialized views and CREATE TABLE AS. Only valid if
 	// IsAs or MaterializedView returns true.
 	GetCreateAsOfTime() hlc.Timestamp
+	// GetMaterializedViewRefreshTime returns the timestamp as of which the data
+	// of a materialized view was last computed in full, ignoring incremental
+	// refreshes. It is empty if the view holds no data or if the timestamp is
+	// unknown. Only valid if MaterializedView returns true.
+	GetMaterializedViewRefreshTime() hlc.Timestamp
 
 	// GetViewQuery returns this view's CREATE VIEW declaration. Only valid if
 	// IsView is true.
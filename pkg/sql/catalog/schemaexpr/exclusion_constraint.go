@@ -0,0 +1,244 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package schemaexpr
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/errors"
+)
+
+// exclusionRangeFunctions maps the functions which can build a range out of a
+// pair of columns in an exclusion constraint to the type family of the
+// columns.
+var exclusionRangeFunctions = map[string]types.Family{
+	"tstzrange": types.TimestampTZFamily,
+	"tsrange":   types.TimestampFamily,
+	"daterange": types.DateFamily,
+	"int4range": types.IntFamily,
+	"int8range": types.IntFamily,
+	"numrange":  types.DecimalFamily,
+}
+
+// ValidateExclusionConstraintElems verifies that the elements of an exclusion
+// constraint are supported, and returns their descriptor representation.
+//
+// The supported elements are:
+//
+//   - col WITH =, where col has an indexable type.
+//   - col WITH &&, where col is a GEOMETRY or GEOGRAPHY column. Unlike in
+//     PostGIS, two shapes overlap only if they intersect, not if their
+//     bounding boxes do.
+//   - col WITH &&, where col is a range or multirange column.
+//   - tstzrange(start, end) WITH &&, where start and end are TIMESTAMPTZ
+//     columns which form the half-open range [start, end). A NULL bound is
+//     unbounded. tsrange, daterange, int4range, int8range and numrange are
+//     supported for columns of the corresponding types.
+//
+func ValidateExclusionConstraintElems(
+	desc catalog.TableDescriptor, elems tree.ExcludeElemList,
+) ([]descpb.ExclusionElement, error) {
+	res := make([]descpb.ExclusionElement, len(elems))
+	for i := range elems {
+		elem := &elems[i]
+		if elem.Direction != tree.DefaultDirection || elem.NullsOrder != tree.DefaultNullsOrder {
+			return nil, pgerror.New(pgcode.FeatureNotSupported,
+				"exclusion constraint elements cannot specify an ordering")
+		}
+		switch elem.Operator.Symbol {
+		case treecmp.EQ:
+			if elem.Expr != nil {
+				return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+					"expression %s is not supported in an exclusion constraint", elem.Expr)
+			}
+			col, err := exclusionConstraintColumn(desc, elem.Column)
+			if err != nil {
+				return nil, err
+			}
+			if !colinfo.ColumnTypeIsIndexable(col.GetType()) {
+				return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+					"column %s of type %s cannot be compared with = in an exclusion constraint",
+					col.GetName(), col.GetType().SQLString())
+			}
+			res[i] = descpb.ExclusionElement{
+				ColumnIDs: []descpb.ColumnID{col.GetID()},
+				Operator:  descpb.ExclusionElement_EQUAL,
+			}
+
+		case treecmp.Overlaps:
+			if elem.Expr != nil {
+				colIDs, err := exclusionConstraintRangeColumns(desc, elem.Expr)
+				if err != nil {
+					return nil, err
+				}
+				res[i] = descpb.ExclusionElement{
+					ColumnIDs: colIDs,
+					Operator:  descpb.ExclusionElement_OVERLAPS,
+				}
+				break
+			}
+			col, err := exclusionConstraintColumn(desc, elem.Column)
+			if err != nil {
+				return nil, err
+			}
+			switch col.GetType().Family() {
+			case types.GeometryFamily, types.GeographyFamily,
+				types.RangeFamily, types.MultirangeFamily:
+			default:
+				return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+					"column %s of type %s cannot be compared with && in an exclusion constraint",
+					col.GetName(), col.GetType().SQLString())
+			}
+			res[i] = descpb.ExclusionElement{
+				ColumnIDs: []descpb.ColumnID{col.GetID()},
+				Operator:  descpb.ExclusionElement_OVERLAPS,
+			}
+
+		default:
+			return nil, unimplemented.NewWithIssueDetailf(46657,
+				"exclude using "+elem.Operator.String(),
+				"operator %s is not supported in exclusion constraints", elem.Operator)
+		}
+	}
+	return res, nil
+}
+
+// exclusionConstraintColumn returns the accessible column with the given name.
+func exclusionConstraintColumn(
+	desc catalog.TableDescriptor, name tree.Name,
+) (catalog.Column, error) {
+	col, err := desc.FindColumnWithName(name)
+	if err != nil {
+		return nil, err
+	}
+	if col.IsInaccessible() {
+		return nil, pgerror.Newf(pgcode.UndefinedColumn,
+			"column %q is inaccessible and cannot be referenced", col.GetName())
+	}
+	return col, nil
+}
+
+// exclusionConstraintRangeColumns returns the IDs of the start and end columns
+// of a range function call in an exclusion constraint, e.g.
+// tstzrange(start, end).
+func exclusionConstraintRangeColumns(
+	desc catalog.TableDescriptor, expr tree.Expr,
+) ([]descpb.ColumnID, error) {
+	unsupportedErr := pgerror.Newf(pgcode.FeatureNotSupported,
+		"expression %s is not supported in an exclusion constraint", expr)
+	unsupportedErr = errors.WithHint(unsupportedErr,
+		"ranges must be built from two columns, e.g. tstzrange(start, end)")
+
+	fn, ok := expr.(*tree.FuncExpr)
+	if !ok || fn.Type != 0 || fn.Filter != nil || fn.WindowDef != nil || fn.OrderBy != nil {
+		return nil, unsupportedErr
+	}
+	fnName, ok := fn.Func.FunctionReference.(*tree.UnresolvedName)
+	if !ok || fnName.NumParts != 1 {
+		return nil, unsupportedErr
+	}
+	family, ok := exclusionRangeFunctions[fnName.Parts[0]]
+	if !ok || (len(fn.Exprs) != 2 && len(fn.Exprs) != 3) {
+		return nil, unsupportedErr
+	}
+	if len(fn.Exprs) == 3 {
+		if bounds, ok := fn.Exprs[2].(*tree.StrVal); !ok || bounds.RawString() != "[)" {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"only '[)' bounds are supported for ranges in an exclusion constraint")
+		}
+	}
+	colIDs := make([]descpb.ColumnID, 2)
+	for i := range colIDs {
+		colName, ok := fn.Exprs[i].(*tree.UnresolvedName)
+		if !ok || colName.NumParts != 1 || colName.Star {
+			return nil, unsupportedErr
+		}
+		col, err := exclusionConstraintColumn(desc, tree.Name(colName.Parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		if col.GetType().Family() != family {
+			return nil, pgerror.Newf(pgcode.DatatypeMismatch,
+				"column %s of type %s cannot be used in %s",
+				col.GetName(), col.GetType().SQLString(), fnName.Parts[0])
+		}
+		colIDs[i] = col.GetID()
+	}
+	if colIDs[0] == colIDs[1] {
+		return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+			"the bounds of a range in an exclusion constraint must be different columns")
+	}
+	return colIDs, nil
+}
+
+// ExclusionConstraintElemsForDisplay returns the elements of an exclusion
+// constraint in the form they are written in an EXCLUDE clause.
+func ExclusionConstraintElemsForDisplay(
+	desc catalog.TableDescriptor, elems []descpb.ExclusionElement,
+) (tree.ExcludeElemList, error) {
+	res := make(tree.ExcludeElemList, len(elems))
+	for i := range elems {
+		names, err := desc.NamesForColumnIDs(elems[i].ColumnIDs)
+		if err != nil {
+			return nil, err
+		}
+		switch elems[i].Operator {
+		case descpb.ExclusionElement_EQUAL:
+			res[i].Operator = treecmp.MakeComparisonOperator(treecmp.EQ)
+		case descpb.ExclusionElement_OVERLAPS:
+			res[i].Operator = treecmp.MakeComparisonOperator(treecmp.Overlaps)
+		default:
+			return nil, errors.AssertionFailedf("unknown exclusion operator %s", elems[i].Operator)
+		}
+		if len(names) == 1 {
+			res[i].Column = tree.Name(names[0])
+			continue
+		}
+		col, err := desc.FindColumnWithID(elems[i].ColumnIDs[0])
+		if err != nil {
+			return nil, err
+		}
+		var fnName string
+		switch col.GetType().Family() {
+		case types.TimestampTZFamily:
+			fnName = "tstzrange"
+		case types.TimestampFamily:
+			fnName = "tsrange"
+		case types.DateFamily:
+			fnName = "daterange"
+		case types.IntFamily:
+			fnName = "int8range"
+			if col.GetType().Width() == 32 {
+				fnName = "int4range"
+			}
+		case types.DecimalFamily:
+			fnName = "numrange"
+		default:
+			return nil, errors.AssertionFailedf(
+				"unexpected type %s for the bounds of a range", col.GetType().SQLString())
+		}
+		res[i].Expr = &tree.FuncExpr{
+			Func: tree.ResolvableFunctionReference{FunctionReference: tree.NewUnresolvedName(fnName)},
+			Exprs: tree.Exprs{
+				tree.NewUnresolvedName(names[0]),
+				tree.NewUnresolvedName(names[1]),
+			},
+		}
+	}
+	return res, nil
+}
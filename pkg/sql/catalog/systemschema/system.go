@@ -411,10 +411,14 @@ CREATE TABLE system.statement_diagnostics_requests(
 	requested_at TIMESTAMPTZ NOT NULL,
 	min_execution_latency INTERVAL NULL,
 	expires_at TIMESTAMPTZ NULL,
+	sampling_probability FLOAT NULL,
+	num_samples INT8 NULL,
+	collected_samples INT8 NULL,
+	on_plan_change BOOL NULL,
 	CONSTRAINT "primary" PRIMARY KEY (id),
 	INDEX completed_idx_v2 (completed, id) STORING (statement_fingerprint, min_execution_latency, expires_at),
 
-	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at, min_execution_latency, expires_at)
+	FAMILY "primary" (id, completed, statement_fingerprint, statement_diagnostics_id, requested_at, min_execution_latency, expires_at, sampling_probability, num_samples, collected_samples, on_plan_change)
 );`
 
 	StatementDiagnosticsTableSchema = `
@@ -667,6 +671,39 @@ CREATE TABLE system.tenant_settings (
 	CONSTRAINT "primary" PRIMARY KEY (tenant_id, name),
 	FAMILY (tenant_id, name, value, last_updated, value_type, reason)
 );`
+
+	StatementPlanPinsTableSchema = `
+CREATE TABLE system.statement_plan_pins (
+	fingerprint_id BYTES NOT NULL,
+	-- The plan gist that the pinned indexes were taken from, if any.
+	plan_gist      STRING,
+	-- The pinned indexes, as a comma-separated list of table_id@index_id.
+	hints          STRING NOT NULL,
+	created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT "primary" PRIMARY KEY (fingerprint_id),
+	FAMILY "primary" (fingerprint_id, plan_gist, hints, created_at)
+);`
+
+	StatementPlanRegressionsTableSchema = `
+CREATE TABLE system.statement_plan_regressions (
+	fingerprint_id BYTES NOT NULL,
+	app_name       STRING NOT NULL,
+	-- The hash of the plan last reported as a regression of the fingerprint.
+	plan_hash      BYTES NOT NULL,
+	reported_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT "primary" PRIMARY KEY (fingerprint_id, app_name),
+	FAMILY "primary" (fingerprint_id, app_name, plan_hash, reported_at)
+);`
+
+	MaterializedViewRefreshesTableSchema = `
+CREATE TABLE system.materialized_view_refreshes (
+	view_id      INT8 NOT NULL,
+	-- The HLC timestamp as of which the data of the view was last refreshed
+	-- incrementally.
+	refreshed_at DECIMAL NOT NULL,
+	CONSTRAINT "primary" PRIMARY KEY (view_id),
+	FAMILY "primary" (view_id, refreshed_at)
+);`
 )
 
 func pk(name string) descpb.IndexDescriptor {
@@ -1769,12 +1806,17 @@ var (
 				{Name: "requested_at", ID: 5, Type: types.TimestampTZ, Nullable: false},
 				{Name: "min_execution_latency", ID: 6, Type: types.Interval, Nullable: true},
 				{Name: "expires_at", ID: 7, Type: types.TimestampTZ, Nullable: true},
+				{Name: "sampling_probability", ID: 8, Type: types.Float, Nullable: true},
+				{Name: "num_samples", ID: 9, Type: types.Int, Nullable: true},
+				{Name: "collected_samples", ID: 10, Type: types.Int, Nullable: true},
+				{Name: "on_plan_change", ID: 11, Type: types.Bool, Nullable: true},
 			},
 			[]descpb.ColumnFamilyDescriptor{
 				{
-					Name:        "primary",
-					ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at", "min_execution_latency", "expires_at"},
-					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7},
+					Name: "primary",
+					ColumnNames: []string{"id", "completed", "statement_fingerprint", "statement_diagnostics_id", "requested_at", "min_execution_latency", "expires_at",
+						"sampling_probability", "num_samples", "collected_samples", "on_plan_change"},
+					ColumnIDs: []descpb.ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
 				},
 			},
 			pk("id"),
@@ -2352,6 +2394,108 @@ var (
 				Version:      descpb.StrictIndexColumnIDGuaranteesVersion,
 			},
 		))
+
+	// StatementPlanPinsTable is the descriptor for the statement plan pins
+	// table. It contains the indexes pinned for statement fingerprints with
+	// ALTER STATEMENT FINGERPRINT ... PIN INDEX HINTS.
+	StatementPlanPinsTable = registerSystemTable(
+		StatementPlanPinsTableSchema,
+		systemTable(
+			catconstants.StatementPlanPinsTableName,
+			descpb.InvalidID, // dynamically assigned
+			[]descpb.ColumnDescriptor{
+				{Name: "fingerprint_id", ID: 1, Type: types.Bytes},
+				{Name: "plan_gist", ID: 2, Type: types.String, Nullable: true},
+				{Name: "hints", ID: 3, Type: types.String},
+				{Name: "created_at", ID: 4, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"fingerprint_id", "plan_gist", "hints", "created_at"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4},
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:                tabledesc.LegacyPrimaryKeyIndexName,
+				ID:                  1,
+				Unique:              true,
+				KeyColumnNames:      []string{"fingerprint_id"},
+				KeyColumnDirections: singleASC,
+				KeyColumnIDs:        singleID1,
+				Version:             descpb.StrictIndexColumnIDGuaranteesVersion,
+			},
+		))
+
+	// StatementPlanRegressionsTable is the descriptor for the statement plan
+	// regressions table. It records the plan regressions reported for
+	// statement fingerprints, so that a regression is reported by a single
+	// node of the cluster.
+	StatementPlanRegressionsTable = registerSystemTable(
+		StatementPlanRegressionsTableSchema,
+		systemTable(
+			catconstants.StatementPlanRegressionsTableName,
+			descpb.InvalidID, // dynamically assigned
+			[]descpb.ColumnDescriptor{
+				{Name: "fingerprint_id", ID: 1, Type: types.Bytes},
+				{Name: "app_name", ID: 2, Type: types.String},
+				{Name: "plan_hash", ID: 3, Type: types.Bytes},
+				{Name: "reported_at", ID: 4, Type: types.TimestampTZ, DefaultExpr: &nowTZString},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"fingerprint_id", "app_name", "plan_hash", "reported_at"},
+					ColumnIDs:   []descpb.ColumnID{1, 2, 3, 4},
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:           tabledesc.LegacyPrimaryKeyIndexName,
+				ID:             1,
+				Unique:         true,
+				KeyColumnNames: []string{"fingerprint_id", "app_name"},
+				KeyColumnDirections: []descpb.IndexDescriptor_Direction{
+					descpb.IndexDescriptor_ASC,
+					descpb.IndexDescriptor_ASC,
+				},
+				KeyColumnIDs: []descpb.ColumnID{1, 2},
+				Version:      descpb.StrictIndexColumnIDGuaranteesVersion,
+			},
+		))
+
+	// MaterializedViewRefreshesTable is the descriptor for the materialized
+	// view refreshes table. It contains the timestamps of the last incremental
+	// refresh of materialized views, which are kept out of the view
+	// descriptors so that refreshing a view does not bump its version.
+	MaterializedViewRefreshesTable = registerSystemTable(
+		MaterializedViewRefreshesTableSchema,
+		systemTable(
+			catconstants.MaterializedViewRefreshesTableName,
+			descpb.InvalidID, // dynamically assigned
+			[]descpb.ColumnDescriptor{
+				{Name: "view_id", ID: 1, Type: types.Int},
+				{Name: "refreshed_at", ID: 2, Type: types.Decimal},
+			},
+			[]descpb.ColumnFamilyDescriptor{
+				{
+					Name:        "primary",
+					ID:          0,
+					ColumnNames: []string{"view_id", "refreshed_at"},
+					ColumnIDs:   []descpb.ColumnID{1, 2},
+				},
+			},
+			descpb.IndexDescriptor{
+				Name:                tabledesc.LegacyPrimaryKeyIndexName,
+				ID:                  1,
+				Unique:              true,
+				KeyColumnNames:      []string{"view_id"},
+				KeyColumnDirections: singleASC,
+				KeyColumnIDs:        singleID1,
+				Version:             descpb.StrictIndexColumnIDGuaranteesVersion,
+			},
+		))
 )
 
 type descRefByName struct {
@@ -140,9 +140,11 @@ type Index interface {
 	IsDisabled() bool
 	IsSharded() bool
 	IsCreatedExplicitly() bool
+	IsExclusion() bool
 	GetPredicate() string
 	GetType() descpb.IndexDescriptor_Type
 	GetGeoConfig() geoindex.Config
+	GetExclusionElements() []descpb.ExclusionElement
 	GetVersion() descpb.IndexDescriptorVersion
 	GetEncodingType() descpb.IndexDescriptorEncodingType
 
@@ -107,6 +107,17 @@ func (w index) IsCreatedExplicitly() bool {
 	return w.desc.CreatedExplicitly
 }
 
+// IsExclusion returns true iff the index backs an exclusion constraint.
+func (w index) IsExclusion() bool {
+	return w.desc.IsExclusion()
+}
+
+// GetExclusionElements returns the elements of the exclusion constraint backed
+// by the index, or nil if the index does not back an exclusion constraint.
+func (w index) GetExclusionElements() []descpb.ExclusionElement {
+	return w.desc.ExclusionElements
+}
+
 // GetPredicate returns the empty string when the index is not partial,
 // otherwise it returns the corresponding expression of the partial index.
 // Columns are referred to in the expression by their name.
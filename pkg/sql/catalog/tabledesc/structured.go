@@ -146,7 +146,7 @@ func (desc *wrapper) IndexKeysPerRow(idx catalog.Index) int {
 //
 func BuildIndexName(tableDesc *Mutable, idx *descpb.IndexDescriptor) (string, error) {
 	// An index name has a segment for the table name, each key column, and a
-	// final word (either "idx" or "key").
+	// final word (either "idx", "key" or "excl").
 	segments := make([]string, 0, len(idx.KeyColumnNames)+2)
 
 	// Add the table name segment.
@@ -186,6 +186,8 @@ func BuildIndexName(tableDesc *Mutable, idx *descpb.IndexDescriptor) (string, er
 	// Add the final segment.
 	if idx.Unique {
 		segments = append(segments, "key")
+	} else if idx.IsExclusion() {
+		segments = append(segments, "excl")
 	} else {
 		segments = append(segments, "idx")
 	}
@@ -704,7 +706,7 @@ func (desc *Mutable) allocateIndexIDs(columnNames map[string]descpb.ColumnID) er
 			}
 			idx.IndexDesc().Name = name
 		}
-		if idx.GetConstraintID() == 0 && idx.IsUnique() {
+		if idx.GetConstraintID() == 0 && (idx.IsUnique() || idx.IsExclusion()) {
 			idx.IndexDesc().ConstraintID = desc.NextConstraintID
 			desc.NextConstraintID++
 		}
@@ -1361,6 +1363,11 @@ func (desc *Mutable) DropConstraint(
 			}
 		}
 
+	case descpb.ConstraintTypeExclusion:
+		return unimplemented.NewWithIssueDetailf(46657, "drop-constraint-exclude",
+			"cannot drop EXCLUDE constraint %q using ALTER TABLE DROP CONSTRAINT, use DROP INDEX CASCADE instead",
+			tree.ErrNameStringP(&detail.Index.Name))
+
 	case descpb.ConstraintTypeCheck:
 		if detail.CheckConstraint.Validity == descpb.ConstraintValidity_Validating {
 			return unimplemented.NewWithIssueDetailf(42844, "drop-constraint-check-mutation",
@@ -1451,7 +1458,7 @@ func (desc *Mutable) RenameConstraint(
 	renameFK func(*Mutable, *descpb.ForeignKeyConstraint, string) error,
 ) error {
 	switch detail.Kind {
-	case descpb.ConstraintTypePK:
+	case descpb.ConstraintTypePK, descpb.ConstraintTypeExclusion:
 		for _, tableRef := range desc.DependedOnBy {
 			if tableRef.IndexID != detail.Index.ID {
 				continue
@@ -1770,6 +1777,12 @@ func (desc *Mutable) MakeMutationComplete(m descpb.DescriptorMutation) error {
 			// indexes with the new indexes that have been backfilled already.
 			desc.SetPrimaryIndex(t.MaterializedViewRefresh.NewPrimaryIndex)
 			desc.SetPublicNonPrimaryIndexes(t.MaterializedViewRefresh.NewIndexes)
+			// Remember the timestamp the view data was computed at, so that the
+			// next incremental refresh knows which changes to apply.
+			desc.MaterializedViewRefreshTime = hlc.Timestamp{}
+			if t.MaterializedViewRefresh.ShouldBackfill {
+				desc.MaterializedViewRefreshTime = t.MaterializedViewRefresh.AsOf
+			}
 		}
 
 	case descpb.DescriptorMutation_DROP:
@@ -305,7 +305,8 @@ func (desc *wrapper) collectConstraintInfo(
 ) (map[string]descpb.ConstraintDetail, error) {
 	info := make(map[string]descpb.ConstraintDetail)
 
-	// Indexes provide PK and Unique constraints that are enforced by an index.
+	// Indexes provide PK, Unique and Exclusion constraints that are enforced by
+	// an index.
 	for _, indexI := range desc.NonDropIndexes() {
 		index := indexI.IndexDesc()
 		if index.ID == desc.PrimaryIndex.ID {
@@ -358,6 +359,24 @@ func (desc *wrapper) collectConstraintInfo(
 			detail.Columns = index.KeyColumnNames
 			detail.Index = index
 			info[index.Name] = detail
+		} else if index.IsExclusion() {
+			if _, ok := info[index.Name]; ok {
+				return nil, pgerror.Newf(pgcode.DuplicateObject,
+					"duplicate constraint name: %q", index.Name)
+			}
+			detail := descpb.ConstraintDetail{
+				Kind:         descpb.ConstraintTypeExclusion,
+				ConstraintID: index.ConstraintID,
+			}
+			for _, elem := range index.ExclusionElements {
+				names, err := desc.NamesForColumnIDs(elem.ColumnIDs)
+				if err != nil {
+					return nil, err
+				}
+				detail.Columns = append(detail.Columns, names...)
+			}
+			detail.Index = index
+			info[index.Name] = detail
 		}
 	}
 
@@ -701,7 +701,7 @@ func maybeAddConstraintIDs(desc *descpb.TableDescriptor) (hasChanged bool) {
 	}
 	for i := range desc.Indexes {
 		idx := &desc.Indexes[i]
-		if idx.Unique && idx.ConstraintID == 0 {
+		if (idx.Unique || idx.IsExclusion()) && idx.ConstraintID == 0 {
 			idx.ConstraintID = nextConstraintID()
 			constraintIndexes[idx.ID] = idx
 		}
@@ -736,7 +736,7 @@ func maybeAddConstraintIDs(desc *descpb.TableDescriptor) (hasChanged bool) {
 		if idx := mutation.GetIndex(); idx != nil &&
 			idx.ConstraintID == 0 &&
 			mutation.Direction == descpb.DescriptorMutation_ADD &&
-			idx.Unique {
+			(idx.Unique || idx.IsExclusion()) {
 			idx.ConstraintID = nextConstraintID()
 			constraintIndexes[idx.ID] = idx
 		} else if pkSwap := mutation.GetPrimaryKeySwap(); pkSwap != nil {
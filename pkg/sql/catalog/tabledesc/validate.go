@@ -1104,6 +1104,35 @@ func (desc *wrapper) validateTableIndexes(columnNames map[string]descpb.ColumnID
 					idx.GetName(), idx.GetPredicate())
 			}
 		}
+		if idx.IsExclusion() {
+			if idx.IsUnique() || idx.Primary() {
+				return errors.Newf("exclusion constraint %q must be backed by a non-unique secondary index",
+					idx.GetName())
+			}
+			for _, elem := range idx.GetExclusionElements() {
+				switch elem.Operator {
+				case descpb.ExclusionElement_EQUAL:
+					if len(elem.ColumnIDs) != 1 {
+						return errors.Newf("exclusion constraint %q has an equality element with %d columns",
+							idx.GetName(), len(elem.ColumnIDs))
+					}
+				case descpb.ExclusionElement_OVERLAPS:
+					if len(elem.ColumnIDs) != 1 && len(elem.ColumnIDs) != 2 {
+						return errors.Newf("exclusion constraint %q has an overlaps element with %d columns",
+							idx.GetName(), len(elem.ColumnIDs))
+					}
+				default:
+					return errors.Newf("exclusion constraint %q has unknown operator %s",
+						idx.GetName(), elem.Operator)
+				}
+				for _, colID := range elem.ColumnIDs {
+					if _, exists := columnsByID[colID]; !exists {
+						return errors.Newf("exclusion constraint %q contains unknown column ID %d",
+							idx.GetName(), colID)
+					}
+				}
+			}
+		}
 
 		if !idx.IsMutation() {
 			if idx.IndexDesc().UseDeletePreservingEncoding {
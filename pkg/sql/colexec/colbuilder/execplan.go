@@ -175,10 +175,19 @@ func supportedNatively(spec *execinfrapb.ProcessorSpec) error {
 
 	case spec.Core.JoinReader != nil:
 		if !spec.Core.JoinReader.IsIndexJoin() {
-			return errLookupJoinUnsupported
+			if len(spec.Input) != 1 {
+				return errLookupJoinUnsupported
+			}
+			return supportedLookupJoin(spec.Core.JoinReader, spec.Input[0].ColumnTypes)
 		}
 		return nil
 
+	case spec.Core.InvertedJoiner != nil:
+		// Inverted joins look up the rows of an inverted index with the spans
+		// derived from an inverted expression, which the ColLookupJoin can't
+		// do, so the row-based invertedJoiner gets wrapped.
+		return errInvertedJoinUnsupported
+
 	case spec.Core.Filterer != nil:
 		return nil
 
@@ -235,6 +244,69 @@ func supportedNatively(spec *execinfrapb.ProcessorSpec) error {
 	}
 }
 
+// supportedLookupJoin checks whether the lookup join described by spec can be
+// executed by the ColLookupJoin operator.
+func supportedLookupJoin(spec *execinfrapb.JoinReaderSpec, inputTypes []*types.T) error {
+	if !spec.LookupExpr.Empty() || !spec.RemoteLookupExpr.Empty() {
+		return errLookupExprUnsupported
+	}
+	if len(spec.LookupColumns) == 0 {
+		return errors.Newf("lookup joins without lookup columns are not supported")
+	}
+	switch spec.Type {
+	case descpb.InnerJoin:
+	case descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin:
+		if !spec.OnExpr.Empty() {
+			return errors.Newf("can't plan vectorized non-inner lookup joins with ON expressions")
+		}
+	default:
+		return errors.Newf("lookup join type %s is not supported", spec.Type)
+	}
+	if spec.LeftJoinWithPairedJoiner || spec.OutputGroupContinuationForLeftRow {
+		return errPairedLookupJoinUnsupported
+	}
+	table := &spec.Table
+	if len(table.Mutations) > 0 {
+		// The row-by-row joinReader outputs all deletable columns of the
+		// table whereas the ColLookupJoin only fetches the readable ones.
+		return errors.Newf("lookup joins into tables with mutations are not supported")
+	}
+	// Without any mutations, the active indexes are the primary index followed
+	// by the secondary indexes.
+	var index *descpb.IndexDescriptor
+	if spec.IndexIdx == 0 {
+		index = &table.PrimaryIndex
+	} else if int(spec.IndexIdx) <= len(table.Indexes) {
+		index = &table.Indexes[spec.IndexIdx-1]
+	} else {
+		return errors.Newf("invalid index %d", spec.IndexIdx)
+	}
+	if index.Type != descpb.IndexDescriptor_FORWARD || len(spec.LookupColumns) > len(index.KeyColumnIDs) {
+		return errors.Newf("lookup joins into index %s are not supported", index.Name)
+	}
+	for i, inputColIdx := range spec.LookupColumns {
+		if int(inputColIdx) >= len(inputTypes) {
+			return errors.Newf("invalid lookup column %d", inputColIdx)
+		}
+		var keyType *types.T
+		for j := range table.Columns {
+			if table.Columns[j].ID == index.KeyColumnIDs[i] {
+				keyType = table.Columns[j].Type
+				break
+			}
+		}
+		// The hash join on the lookup columns requires the same physical
+		// representation of the input and the index key columns.
+		inputType := inputTypes[inputColIdx]
+		if keyType == nil || inputType.Width() != keyType.Width() ||
+			typeconv.TypeFamilyToCanonicalTypeFamily(inputType.Family()) !=
+				typeconv.TypeFamilyToCanonicalTypeFamily(keyType.Family()) {
+			return errors.Newf("lookup joins with mismatched lookup column types are not supported")
+		}
+	}
+	return nil
+}
+
 var (
 	errCoreUnsupportedNatively        = errors.New("unsupported processor core")
 	errLocalPlanNodeWrap              = errors.New("LocalPlanNode core needs to be wrapped")
@@ -251,6 +323,9 @@ var (
 	errExperimentalWrappingProhibited = errors.New("wrapping for non-JoinReader and non-LocalPlanNode cores is prohibited in vectorize=experimental_always")
 	errWrappedCast                    = errors.New("mismatched types in NewColOperator and unsupported casts")
 	errLookupJoinUnsupported          = errors.New("lookup join reader is unsupported in vectorized")
+	errLookupExprUnsupported          = errors.New("lookup joins with lookup expressions are unsupported in vectorized")
+	errPairedLookupJoinUnsupported    = errors.New("paired lookup joins are unsupported in vectorized")
+	errInvertedJoinUnsupported        = errors.New("inverted joins are unsupported in vectorized")
 )
 
 func canWrap(mode sessiondatapb.VectorizeExecMode, spec *execinfrapb.ProcessorSpec) error {
@@ -666,7 +741,12 @@ func NewColOperator(
 	core := &spec.Core
 	post := &spec.Post
 
-	if err = supportedNatively(spec); err != nil {
+	err = supportedNatively(spec)
+	if err == nil && core.JoinReader != nil && !core.JoinReader.IsIndexJoin() &&
+		!colfetcher.VectorizeLookupJoinsEnabled.Get(&flowCtx.Cfg.Settings.SV) {
+		err = errLookupJoinUnsupported
+	}
+	if err != nil {
 		inputTypes := make([][]*types.T, len(spec.Input))
 		for inputIdx, input := range spec.Input {
 			inputTypes[inputIdx] = make([]*types.T, len(input.ColumnTypes))
@@ -736,7 +816,51 @@ func NewColOperator(
 				return r, err
 			}
 			if !core.JoinReader.IsIndexJoin() {
-				return r, errors.AssertionFailedf("lookup join reader is unsupported in vectorized")
+				// We have to create a separate account in order for the
+				// cFetcher to be able to precisely track the size of its
+				// output batch. This memory account is "streaming" in its
+				// nature, so we create an unlimited one.
+				cFetcherMemAcc := args.MonitorRegistry.CreateUnlimitedMemAccount(
+					ctx, flowCtx, "cfetcher" /* opName */, spec.ProcessorID,
+				)
+				kvFetcherMemAcc := args.MonitorRegistry.CreateUnlimitedMemAccount(
+					ctx, flowCtx, "kvfetcher" /* opName */, spec.ProcessorID,
+				)
+				// The lookup joiner buffers a bounded number of input rows,
+				// so their memory account is unlimited.
+				lookupBufferMemAcc := args.MonitorRegistry.CreateUnlimitedMemAccount(
+					ctx, flowCtx, "lookup-buffer" /* opName */, spec.ProcessorID,
+				)
+				// The rows looked up for the buffered input rows are subject
+				// to the memory limit, and when they don't fit, the lookup
+				// joiner performs the lookups for fewer input rows at a time.
+				lookupJoinerMemAcc, lookupJoinerMemMonitorName := args.MonitorRegistry.CreateMemAccountForSpillStrategy(
+					ctx, flowCtx, "lookup-joiner" /* opName */, spec.ProcessorID,
+				)
+				inputTypes := make([]*types.T, len(spec.Input[0].ColumnTypes))
+				copy(inputTypes, spec.Input[0].ColumnTypes)
+				lookupJoinOp, err := colfetcher.NewColLookupJoin(
+					ctx, getStreamingAllocator(ctx, args),
+					colmem.NewAllocator(ctx, cFetcherMemAcc, factory),
+					colmem.NewAllocator(ctx, lookupBufferMemAcc, factory),
+					colmem.NewAllocator(ctx, lookupJoinerMemAcc, factory),
+					lookupJoinerMemMonitorName, kvFetcherMemAcc, flowCtx, args.ExprHelper,
+					inputs[0].Root, core.JoinReader, post, inputTypes,
+				)
+				if err != nil {
+					return r, err
+				}
+				result.finishScanPlanning(lookupJoinOp, lookupJoinOp.ResultTypes)
+				if !core.JoinReader.OnExpr.Empty() {
+					// Only inner lookup joins can have the ON expression, so
+					// we can simply plan a filter on top of the joiner.
+					if err = result.planAndMaybeWrapFilter(
+						ctx, flowCtx, args, spec.ProcessorID, core.JoinReader.OnExpr, factory,
+					); err != nil {
+						return r, err
+					}
+				}
+				break
 			}
 			// We have to create a separate account in order for the cFetcher to
 			// be able to precisely track the size of its output batch. This
@@ -39,19 +39,73 @@ func NewColSpanAssembler(
 	index catalog.Index,
 	inputTypes []*types.T,
 	neededColOrdsInWholeTable util.FastIntSet,
+) ColSpanAssembler {
+	// The input of an index join contains exactly the key columns of the index
+	// in the same order.
+	keyCols := make([]int, index.NumKeyColumns())
+	for i := range keyCols {
+		keyCols[i] = i
+	}
+	colFamStartKeys, colFamEndKeys := getColFamilyEncodings(neededColOrdsInWholeTable, table, index)
+	return newColSpanAssembler(
+		codec, allocator, table, index, inputTypes, keyCols, colFamStartKeys, colFamEndKeys,
+	)
+}
+
+// NewColSpanAssemblerForLookupJoin returns a ColSpanAssembler operator that is
+// able to generate lookup spans over a prefix of the key columns of the index
+// from input batches.
+// - lookupCols contains the ordinals of the input columns that hold the values
+// of the first len(lookupCols) key columns of the index.
+// - neededColOrdsInWholeTable has the same meaning as in NewColSpanAssembler.
+func NewColSpanAssemblerForLookupJoin(
+	codec keys.SQLCodec,
+	allocator *colmem.Allocator,
+	table catalog.TableDescriptor,
+	index catalog.Index,
+	inputTypes []*types.T,
+	lookupCols []uint32,
+	neededColOrdsInWholeTable util.FastIntSet,
+) ColSpanAssembler {
+	keyCols := make([]int, len(lookupCols))
+	for i := range lookupCols {
+		keyCols[i] = int(lookupCols[i])
+	}
+	// Each span can only be split into column family spans if it is known to
+	// match at most one row, which is only the case when the whole primary key
+	// is constrained.
+	var colFamStartKeys, colFamEndKeys []roachpb.Key
+	if index.Primary() && len(keyCols) == index.NumKeyColumns() {
+		colFamStartKeys, colFamEndKeys = getColFamilyEncodings(neededColOrdsInWholeTable, table, index)
+	}
+	return newColSpanAssembler(
+		codec, allocator, table, index, inputTypes, keyCols, colFamStartKeys, colFamEndKeys,
+	)
+}
+
+// newColSpanAssembler returns a ColSpanAssembler operator that encodes the
+// input columns keyCols as the corresponding key columns of the index.
+func newColSpanAssembler(
+	codec keys.SQLCodec,
+	allocator *colmem.Allocator,
+	table catalog.TableDescriptor,
+	index catalog.Index,
+	inputTypes []*types.T,
+	keyCols []int,
+	colFamStartKeys, colFamEndKeys []roachpb.Key,
 ) ColSpanAssembler {
 	base := spanAssemblerPool.Get().(*spanAssemblerBase)
-	base.colFamStartKeys, base.colFamEndKeys = getColFamilyEncodings(neededColOrdsInWholeTable, table, index)
+	base.colFamStartKeys, base.colFamEndKeys = colFamStartKeys, colFamEndKeys
 	keyPrefix := rowenc.MakeIndexKeyPrefix(codec, table.GetID(), index.GetID())
 	base.scratchKey = append(base.scratchKey[:0], keyPrefix...)
 	base.prefixLength = len(keyPrefix)
 	base.allocator = allocator
 
-	// Add span encoders to encode each primary key column as bytes. The
+	// Add span encoders to encode each key column as bytes. The
 	// ColSpanAssembler will later append these together to form valid spans.
-	for i := 0; i < index.NumKeyColumns(); i++ {
+	for i, colIdx := range keyCols {
 		asc := index.GetKeyColumnDirection(i) == descpb.IndexDescriptor_ASC
-		base.spanEncoders = append(base.spanEncoders, newSpanEncoder(allocator, inputTypes[i], asc, i))
+		base.spanEncoders = append(base.spanEncoders, newSpanEncoder(allocator, inputTypes[colIdx], asc, colIdx))
 	}
 	if cap(base.spanCols) < len(base.spanEncoders) {
 		base.spanCols = make([]*coldata.Bytes, len(base.spanEncoders))
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
 )
 
 // cFetcherTableArgs describes the information about the index we're fetching
@@ -202,6 +203,136 @@ func populateTableArgsLegacy(
 	return args, neededColumns, resolver.HydrateTypeSlice(ctx, args.typs)
 }
 
+// populateTableArgsForLookupJoin is similar to populateTableArgsLegacy, but it
+// is used by the ColLookupJoin whose output (before the post-processing) is
+// formed by the input columns followed by all columns of the table (the latter
+// are omitted for semi and anti joins). The set of the table columns to fetch
+// is determined based on the post-processing spec unless allColumnsNeeded is
+// true, and the key columns of the index used for the lookup are always
+// fetched. The column references in post are adjusted accordingly.
+// - lookupKeyColOrds contains the ordinals among the fetched columns of the
+// first numLookupCols key columns of the index.
+func populateTableArgsForLookupJoin(
+	ctx context.Context,
+	flowCtx *execinfra.FlowCtx,
+	table catalog.TableDescriptor,
+	index catalog.Index,
+	hasSystemColumns bool,
+	inputTypes []*types.T,
+	numLookupCols int,
+	outputTableCols bool,
+	allColumnsNeeded bool,
+	post *execinfrapb.PostProcessSpec,
+	helper *colexecargs.ExprHelper,
+) (_ *cFetcherTableArgs, neededColumns util.FastIntSet, lookupKeyColOrds []uint32, _ error) {
+	args := cFetcherTableArgsPool.Get().(*cFetcherTableArgs)
+
+	readableColumns := table.ReadableColumns()
+	numTableCols := len(readableColumns)
+	var systemColumns []catalog.Column
+	if hasSystemColumns {
+		systemColumns = table.SystemColumns()
+		numTableCols += len(systemColumns)
+	}
+	numInputCols := len(inputTypes)
+	numOutputCols := numInputCols
+	if outputTableCols {
+		numOutputCols += numTableCols
+	}
+
+	var err error
+	// Make sure that render expressions are deserialized right away so that we
+	// don't have to re-parse them multiple times.
+	if post.RenderExprs != nil {
+		typs := make([]*types.T, 0, numOutputCols)
+		typs = append(typs, inputTypes...)
+		if outputTableCols {
+			for _, col := range readableColumns {
+				typs = append(typs, col.GetType())
+			}
+			for _, col := range systemColumns {
+				typs = append(typs, col.GetType())
+			}
+		}
+		for i := range post.RenderExprs {
+			post.RenderExprs[i].LocalExpr, err = helper.ProcessExpr(post.RenderExprs[i], flowCtx.EvalCtx, typs)
+			if err != nil {
+				return args, neededColumns, nil, err
+			}
+		}
+	}
+
+	if allColumnsNeeded {
+		neededColumns.AddRange(0, numTableCols-1)
+	} else if outputTableCols {
+		neededOutputColumns := getNeededColumns(post, numOutputCols)
+		for idx, ok := neededOutputColumns.Next(numInputCols); ok; idx, ok = neededOutputColumns.Next(idx + 1) {
+			neededColumns.Add(idx - numInputCols)
+		}
+	}
+	// The key columns used for the lookup are always needed in order to
+	// perform the join.
+	readableColIdxMap := catalog.ColumnIDToOrdinalMap(readableColumns)
+	lookupKeyColIDs := make([]descpb.ColumnID, numLookupCols)
+	for i := range lookupKeyColIDs {
+		lookupKeyColIDs[i] = index.GetKeyColumnID(i)
+		ord, ok := readableColIdxMap.Get(lookupKeyColIDs[i])
+		if !ok {
+			return args, neededColumns, nil, errors.AssertionFailedf(
+				"lookup column %d is not readable", lookupKeyColIDs[i],
+			)
+		}
+		neededColumns.Add(ord)
+	}
+
+	colIDs := make([]descpb.ColumnID, 0, neededColumns.Len())
+	for idx, ok := neededColumns.Next(0); ok; idx, ok = neededColumns.Next(idx + 1) {
+		if idx < len(readableColumns) {
+			colIDs = append(colIDs, readableColumns[idx].GetID())
+		} else {
+			colIDs = append(colIDs, systemColumns[idx-len(readableColumns)].GetID())
+		}
+	}
+
+	// Remap the post processing spec. Note that the input columns keep their
+	// ordinals.
+	if outputTableCols && len(colIDs) != numTableCols {
+		idxMap := make([]int, numOutputCols)
+		for i := 0; i < numInputCols; i++ {
+			idxMap[i] = i
+		}
+		newIdx := numInputCols
+		for idx, ok := neededColumns.Next(0); ok; idx, ok = neededColumns.Next(idx + 1) {
+			idxMap[numInputCols+idx] = newIdx
+			newIdx++
+		}
+		remapPostProcessSpec(post, idxMap, flowCtx.PreserveFlowSpecs)
+	}
+
+	*args = cFetcherTableArgs{
+		typs: args.typs,
+	}
+	if err := rowenc.InitIndexFetchSpec(
+		&args.spec, flowCtx.Codec(), table, index, colIDs,
+	); err != nil {
+		return nil, util.FastIntSet{}, nil, err
+	}
+	args.populateTypes(args.spec.FetchedColumns)
+	for i := range args.spec.FetchedColumns {
+		args.ColIdxMap.Set(args.spec.FetchedColumns[i].ColumnID, i)
+	}
+	lookupKeyColOrds = make([]uint32, numLookupCols)
+	for i, colID := range lookupKeyColIDs {
+		ord, _ := args.ColIdxMap.Get(colID)
+		lookupKeyColOrds[i] = uint32(ord)
+	}
+
+	// Before we can safely use types from the table descriptor, we need to
+	// make sure they are hydrated.
+	resolver := flowCtx.NewTypeResolver(flowCtx.Txn)
+	return args, neededColumns, lookupKeyColOrds, resolver.HydrateTypeSlice(ctx, args.typs)
+}
+
 // getNeededColumns returns the set of needed columns that a processor core must
 // output because these columns are used by the post-processing stage. It is
 // assumed that the render expressions, if any, have already been deserialized.
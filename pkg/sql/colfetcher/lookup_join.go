@@ -0,0 +1,692 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecargs"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecjoin"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecspan"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecop"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// VectorizeLookupJoinsEnabled determines whether lookup joins are executed
+// natively by the vectorized engine (via ColLookupJoin) rather than by wrapping
+// the row-by-row joinReader processor. Inverted joins are not lookup joins in
+// this sense: they are always executed by wrapping the invertedJoiner.
+var VectorizeLookupJoinsEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.distsql.vectorize_lookup_joins.enabled",
+	"set to true to execute supported lookup joins natively in the vectorized engine",
+	false,
+)
+
+// ColLookupJoin operators are used to execute lookup joins that look up the
+// rows of an index using the equality columns of the input. The input rows are
+// buffered in chunks, the lookup spans of the rows of a chunk are batched into
+// a single scan, and the looked up rows are then joined with the buffered input
+// rows via an in-memory hash join. The buffered input rows are used as the
+// probe side of the hash join, so the ordering of the input is maintained.
+//
+// The looked up rows form the build side of the hash join, which is subject to
+// a memory limit. When they don't fit, the lookups of the chunk are performed
+// for fewer input rows at a time, down to a single input row, whose looked up
+// rows are joined with it as they are fetched, without being buffered.
+type ColLookupJoin struct {
+	colexecop.InitHelper
+	colexecop.OneInputNode
+
+	state lookupJoinState
+
+	// spanAssembler is used to construct the lookup spans for each chunk of
+	// input rows.
+	spanAssembler colexecspan.ColSpanAssembler
+
+	// chunk buffers the input rows for which the lookups are currently being
+	// performed.
+	chunk *colexecutils.AppendOnlyBufferedBatch
+	// chunkSize tracks the estimated size of the rows buffered in chunk.
+	chunkSize int64
+	// inputBatchSizeLimit is the limit on chunkSize. Once it is reached, the
+	// lookups are performed for the buffered rows.
+	inputBatchSizeLimit int64
+	// inputDone indicates whether the input has been fully consumed.
+	inputDone bool
+
+	// lookupStartIdx and lookupEndIdx delimit the rows of chunk whose lookups
+	// are currently being performed.
+	lookupStartIdx, lookupEndIdx int
+	// maxLookupRows is the maximum number of rows of chunk whose lookups are
+	// performed at once. It is halved every time the rows looked up for them
+	// don't fit into the memory limit of the joiner, and it is never increased
+	// since the following input rows are likely to look up as many rows.
+	maxLookupRows int
+	// scanStarted indicates whether the spans of the current lookups were
+	// handed to the fetcher.
+	scanStarted bool
+	// joinerEmitted indicates whether the joiner emitted a batch for the
+	// current lookups, after which they cannot be retried for fewer rows.
+	joinerEmitted bool
+	// joinerMemMonitorName is the name of the memory monitor of the joiner,
+	// which identifies the out of memory errors it raises.
+	joinerMemMonitorName string
+
+	// probeSource and fetchSource are the inputs of the joiner.
+	probeSource *lookupJoinChunkSource
+	fetchSource *lookupJoinFetchSource
+	joiner      colexecop.ResettableOperator
+
+	// streaming contains the state used to join a single input row with the
+	// rows looked up for it as they are fetched.
+	streaming struct {
+		joinType   descpb.JoinType
+		lookupCols []uint32
+		allocator  *colmem.Allocator
+		// fetched is the last batch of looked up rows, of which the rows
+		// starting at fetchedIdx are not joined yet.
+		fetched    coldata.Batch
+		fetchedIdx int
+		// fetchDone indicates whether all of the looked up rows were fetched.
+		fetchDone bool
+		// matched indicates whether any row was looked up for the input row.
+		matched bool
+		// done indicates whether the join of the input row is complete.
+		done        bool
+		output      coldata.Batch
+		outputTypes []*types.T
+	}
+
+	flowCtx *execinfra.FlowCtx
+	cf      *cFetcher
+
+	// tracingSpan is created when the stats should be collected for the query
+	// execution, and it will be finished when closing the operator.
+	tracingSpan *tracing.Span
+	mu          struct {
+		syncutil.Mutex
+		// rowsRead contains the number of total rows this ColLookupJoin has
+		// looked up so far.
+		rowsRead int64
+	}
+	// ResultTypes is the slice of resulting column types from this operator.
+	ResultTypes []*types.T
+}
+
+var _ colexecop.KVReader = &ColLookupJoin{}
+var _ execinfra.Releasable = &ColLookupJoin{}
+var _ colexecop.ClosableOperator = &ColLookupJoin{}
+
+// Init initializes a ColLookupJoin.
+func (s *ColLookupJoin) Init(ctx context.Context) {
+	if !s.InitHelper.Init(ctx) {
+		return
+	}
+	// If tracing is enabled, we need to start a child span so that the only
+	// contention events present in the recording would be because of this
+	// cFetcher. Note that ProcessorSpan method itself will check whether
+	// tracing is enabled.
+	s.Ctx, s.tracingSpan = execinfra.ProcessorSpan(s.Ctx, "collookupjoin")
+	s.Input.Init(s.Ctx)
+	s.joiner.Init(s.Ctx)
+}
+
+type lookupJoinState uint8
+
+const (
+	lookupJoinBuffering lookupJoinState = iota
+	lookupJoinLookingUp
+	lookupJoinJoining
+	lookupJoinStreaming
+	lookupJoinDone
+)
+
+// Next is part of the Operator interface.
+func (s *ColLookupJoin) Next() coldata.Batch {
+	for {
+		switch s.state {
+		case lookupJoinBuffering:
+			s.chunk.ResetInternalBatch()
+			s.chunkSize = 0
+			for !s.inputDone && s.chunkSize < s.inputBatchSizeLimit {
+				batch := s.Input.Next()
+				n := batch.Length()
+				if n == 0 {
+					s.inputDone = true
+					break
+				}
+				s.chunk.AppendTuples(batch, 0 /* startIdx */, n)
+				s.chunkSize += colmem.GetProportionalBatchMemSize(batch, int64(n))
+			}
+			if s.chunk.Length() == 0 {
+				// No lookups left to perform.
+				s.state = lookupJoinDone
+				continue
+			}
+			s.lookupStartIdx, s.lookupEndIdx = 0, 0
+			s.state = lookupJoinLookingUp
+		case lookupJoinLookingUp:
+			s.lookupStartIdx = s.lookupEndIdx
+			if s.lookupStartIdx >= s.chunk.Length() {
+				// The lookups of the whole chunk have been performed.
+				s.state = lookupJoinBuffering
+				continue
+			}
+			s.lookupEndIdx = s.lookupStartIdx + s.maxLookupRows
+			if s.lookupEndIdx > s.chunk.Length() {
+				s.lookupEndIdx = s.chunk.Length()
+			}
+			if s.maxLookupRows == 1 {
+				s.startStreaming()
+				s.state = lookupJoinStreaming
+				continue
+			}
+			s.startScan()
+			s.probeSource.reset(s.lookupStartIdx, s.lookupEndIdx)
+			s.joinerEmitted = false
+			s.state = lookupJoinJoining
+		case lookupJoinJoining:
+			var batch coldata.Batch
+			if err := colexecerror.CatchVectorizedRuntimeError(func() {
+				batch = s.joiner.Next()
+			}); err != nil {
+				if s.joinerEmitted || !sqlerrors.IsOutOfMemoryError(err) ||
+					!strings.Contains(err.Error(), s.joinerMemMonitorName) {
+					// Either not an out of memory error of the joiner, or some
+					// output was already emitted for the current lookups, so
+					// we propagate the error further.
+					colexecerror.InternalError(err)
+				}
+				// The rows looked up for the current input rows don't fit into
+				// the memory limit of the joiner, so we perform the lookups
+				// again for half as many input rows.
+				s.finishLookups()
+				s.maxLookupRows = (s.lookupEndIdx - s.lookupStartIdx) / 2
+				if s.maxLookupRows < 1 {
+					s.maxLookupRows = 1
+				}
+				s.lookupEndIdx = s.lookupStartIdx
+				s.state = lookupJoinLookingUp
+				continue
+			}
+			if batch.Length() == 0 {
+				// The join of the current input rows is complete.
+				s.finishLookups()
+				s.state = lookupJoinLookingUp
+				continue
+			}
+			s.joinerEmitted = true
+			return batch
+		case lookupJoinStreaming:
+			if batch := s.nextStreaming(); batch.Length() > 0 {
+				return batch
+			}
+			s.finishLookups()
+			s.state = lookupJoinLookingUp
+		case lookupJoinDone:
+			// Eagerly close the lookup joiner. Note that closeInternal() is
+			// idempotent, so it's ok if it'll be closed again.
+			s.closeInternal()
+			return coldata.ZeroBatch
+		}
+	}
+}
+
+// startScan starts the scan of the rows looked up for the current input rows.
+func (s *ColLookupJoin) startScan() {
+	// NULL values don't match anything, so no spans are generated for the
+	// input rows with a NULL lookup key. They are still part of the probe side
+	// of the join, which emits them for outer and anti joins.
+	runStartIdx := s.lookupStartIdx
+	for i := s.lookupStartIdx; i < s.lookupEndIdx; i++ {
+		if s.lookupKeyHasNull(i) {
+			s.spanAssembler.ConsumeBatch(s.chunk, runStartIdx, i)
+			runStartIdx = i + 1
+		}
+	}
+	s.spanAssembler.ConsumeBatch(s.chunk, runStartIdx, s.lookupEndIdx)
+	// Multiple input rows can have the same lookup key, so we deduplicate the
+	// spans in order to look up each row only once. This also sorts the spans
+	// which allows lower layers to optimize iteration over the data. The order
+	// in which the looked up rows are fetched doesn't matter since they form
+	// the build side of the join.
+	spans := s.spanAssembler.GetSpans()
+	if len(spans) == 0 {
+		// All of the lookup keys are NULL, so there is nothing to look up.
+		s.spanAssembler.AccountForSpans()
+		return
+	}
+	spans, _ = roachpb.MergeSpans(&spans)
+	s.cf.setEstimatedRowCount(uint64(s.lookupEndIdx - s.lookupStartIdx))
+	// Note that the fetcher takes ownership of the spans slice - it will
+	// modify it and perform the memory accounting. We don't double count for
+	// any memory of spans because the spanAssembler released all of the
+	// relevant memory from its account in GetSpans().
+	if err := s.cf.StartScan(
+		s.Ctx,
+		s.flowCtx.Txn,
+		spans,
+		nil,   /* bsHeader */
+		false, /* limitBatches */
+		rowinfra.NoBytesLimit,
+		rowinfra.NoRowLimit,
+		s.flowCtx.EvalCtx.TestingKnobs.ForceProductionBatchSizes,
+	); err != nil {
+		colexecerror.InternalError(err)
+	}
+	s.scanStarted = true
+}
+
+// finishLookups releases the resources used by the lookups of the current
+// input rows.
+func (s *ColLookupJoin) finishLookups() {
+	// The joiner might have short-circuited or failed without consuming all of
+	// the looked up rows, so we close the fetcher explicitly.
+	s.cf.Close(s.Ctx)
+	if s.scanStarted {
+		// We now have to tell the ColSpanAssembler to account for the spans
+		// slice since it still has the references to it.
+		s.spanAssembler.AccountForSpans()
+		s.scanStarted = false
+	}
+	s.joiner.Reset(s.Ctx)
+}
+
+// startStreaming starts the lookup of the single input row at lookupStartIdx,
+// whose looked up rows are joined with it as they are fetched.
+func (s *ColLookupJoin) startStreaming() {
+	st := &s.streaming
+	st.fetched, st.fetchedIdx = nil, 0
+	st.fetchDone, st.matched, st.done = false, false, false
+	if s.lookupKeyHasNull(s.lookupStartIdx) {
+		// NULL values don't match anything, so there is nothing to look up for
+		// the input row.
+		st.fetchDone = true
+		return
+	}
+	s.startScan()
+}
+
+// lookupKeyHasNull returns whether the lookup key of the input row at rowIdx
+// in the current chunk contains a NULL value.
+func (s *ColLookupJoin) lookupKeyHasNull(rowIdx int) bool {
+	for _, colIdx := range s.streaming.lookupCols {
+		if s.chunk.ColVec(int(colIdx)).Nulls().NullAt(rowIdx) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextStreaming returns the next batch of the join of the input row at
+// lookupStartIdx with the rows looked up for it, or a zero-length batch once
+// the join is complete. All of the looked up rows match the input row since
+// they were fetched with the spans of its lookup key.
+func (s *ColLookupJoin) nextStreaming() coldata.Batch {
+	st := &s.streaming
+	for !st.done {
+		if st.fetched == nil || st.fetchedIdx >= st.fetched.Length() {
+			if st.fetchDone {
+				st.done = true
+				if !st.matched && (st.joinType == descpb.LeftOuterJoin || st.joinType == descpb.LeftAntiJoin) {
+					return s.emitStreaming(nil /* fetched */, 0 /* fetchedIdx */, 1 /* n */)
+				}
+				break
+			}
+			st.fetched, st.fetchedIdx = s.fetchSource.Next(), 0
+			st.fetchDone = st.fetched.Length() == 0
+			continue
+		}
+		st.matched = true
+		switch st.joinType {
+		case descpb.LeftSemiJoin:
+			st.done = true
+			return s.emitStreaming(nil /* fetched */, 0 /* fetchedIdx */, 1 /* n */)
+		case descpb.LeftAntiJoin:
+			st.done = true
+		default:
+			batch := s.emitStreaming(st.fetched, st.fetchedIdx, st.fetched.Length()-st.fetchedIdx)
+			st.fetchedIdx += batch.Length()
+			return batch
+		}
+	}
+	return coldata.ZeroBatch
+}
+
+// emitStreaming returns a batch of at most n rows made of the input row at
+// lookupStartIdx followed, if the join outputs the table columns, by the
+// looked up rows of fetched starting at fetchedIdx, or by NULLs if fetched is
+// nil.
+func (s *ColLookupJoin) emitStreaming(fetched coldata.Batch, fetchedIdx, n int) coldata.Batch {
+	st := &s.streaming
+	st.output, _ = st.allocator.ResetMaybeReallocate(
+		st.outputTypes, st.output, n, math.MaxInt64, /* maxBatchMemSize */
+	)
+	if n > st.output.Capacity() {
+		n = st.output.Capacity()
+	}
+	numInputCols := len(s.probeSource.typs)
+	st.allocator.PerformOperation(st.output.ColVecs(), func() {
+		for i := 0; i < numInputCols; i++ {
+			src, dst := s.chunk.ColVec(i), st.output.ColVec(i)
+			for j := 0; j < n; j++ {
+				dst.Copy(coldata.SliceArgs{
+					Src:         src,
+					DestIdx:     j,
+					SrcStartIdx: s.lookupStartIdx,
+					SrcEndIdx:   s.lookupStartIdx + 1,
+				})
+			}
+		}
+		for i := numInputCols; i < len(st.outputTypes); i++ {
+			dst := st.output.ColVec(i)
+			if fetched == nil {
+				dst.Nulls().SetNullRange(0, n)
+				continue
+			}
+			dst.Copy(coldata.SliceArgs{
+				Src:         fetched.ColVec(i - numInputCols),
+				SrcStartIdx: fetchedIdx,
+				SrcEndIdx:   fetchedIdx + n,
+			})
+		}
+	})
+	st.output.SetLength(n)
+	return st.output
+}
+
+// lookupJoinChunkSource is an operator that emits the input rows buffered by
+// the ColLookupJoin in batches of at most coldata.BatchSize() rows.
+type lookupJoinChunkSource struct {
+	colexecop.ZeroInputNode
+	colexecop.NonExplainable
+
+	typs   []*types.T
+	chunk  coldata.Batch
+	window coldata.Batch
+	// emitted is the index of the next row of the chunk to emit, and endIdx
+	// is the index past the last one.
+	emitted, endIdx int
+}
+
+var _ colexecop.Operator = &lookupJoinChunkSource{}
+
+// Init is part of the Operator interface.
+func (s *lookupJoinChunkSource) Init(context.Context) {}
+
+// Next is part of the Operator interface.
+func (s *lookupJoinChunkSource) Next() coldata.Batch {
+	if s.emitted >= s.endIdx {
+		return coldata.ZeroBatch
+	}
+	endIdx := s.emitted + coldata.BatchSize()
+	if endIdx > s.endIdx {
+		endIdx = s.endIdx
+	}
+	colexecutils.MakeWindowIntoBatch(s.window, s.chunk, s.emitted, endIdx, s.typs)
+	s.emitted = endIdx
+	return s.window
+}
+
+// reset makes the source emit the rows of the chunk in [startIdx, endIdx).
+func (s *lookupJoinChunkSource) reset(startIdx, endIdx int) {
+	s.emitted, s.endIdx = startIdx, endIdx
+}
+
+// lookupJoinFetchSource is an operator that emits the rows looked up by the
+// cFetcher of the ColLookupJoin.
+type lookupJoinFetchSource struct {
+	colexecop.ZeroInputNode
+	colexecop.NonExplainable
+
+	s *ColLookupJoin
+}
+
+var _ colexecop.Operator = &lookupJoinFetchSource{}
+
+// Init is part of the Operator interface.
+func (s *lookupJoinFetchSource) Init(context.Context) {}
+
+// Next is part of the Operator interface.
+func (s *lookupJoinFetchSource) Next() coldata.Batch {
+	if !s.s.scanStarted {
+		// There was nothing to look up for the current input rows.
+		return coldata.ZeroBatch
+	}
+	batch, err := s.s.cf.NextBatch(s.s.Ctx)
+	if err != nil {
+		colexecerror.InternalError(err)
+	}
+	if batch.Selection() != nil {
+		colexecerror.InternalError(
+			errors.AssertionFailedf("unexpected selection vector on the batch coming from CFetcher"))
+	}
+	s.s.mu.Lock()
+	s.s.mu.rowsRead += int64(batch.Length())
+	s.s.mu.Unlock()
+	return batch
+}
+
+// DrainMeta is part of the colexecop.MetadataSource interface.
+func (s *ColLookupJoin) DrainMeta() []execinfrapb.ProducerMetadata {
+	var trailingMeta []execinfrapb.ProducerMetadata
+	if tfs := execinfra.GetLeafTxnFinalState(s.Ctx, s.flowCtx.Txn); tfs != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{LeafTxnFinalState: tfs})
+	}
+	meta := execinfrapb.GetProducerMeta()
+	meta.Metrics = execinfrapb.GetMetricsMeta()
+	meta.Metrics.BytesRead = s.GetBytesRead()
+	meta.Metrics.RowsRead = s.GetRowsRead()
+	trailingMeta = append(trailingMeta, *meta)
+	if trace := execinfra.GetTraceData(s.Ctx); trace != nil {
+		trailingMeta = append(trailingMeta, execinfrapb.ProducerMetadata{TraceData: trace})
+	}
+	return trailingMeta
+}
+
+// GetBytesRead is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetBytesRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cf.getBytesRead()
+}
+
+// GetRowsRead is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetRowsRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mu.rowsRead
+}
+
+// GetCumulativeContentionTime is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetCumulativeContentionTime() time.Duration {
+	return execinfra.GetCumulativeContentionTime(s.Ctx)
+}
+
+// GetScanStats is part of the colexecop.KVReader interface.
+func (s *ColLookupJoin) GetScanStats() execinfra.ScanStats {
+	return execinfra.GetScanStats(s.Ctx)
+}
+
+// NewColLookupJoin creates a new ColLookupJoin operator. Only lookup joins with
+// equality lookup columns (and optionally an ON expression for inner joins)
+// are supported; the ON expression is not evaluated by the ColLookupJoin and
+// has to be planned on top of it by the caller. Lookup joins with lookup
+// expressions and paired lookup joins are executed by the row-based
+// joinReader, and inverted joins by the row-based invertedJoiner.
+// - allocator is used by the span assembler.
+// - fetcherAllocator is used by the cFetcher.
+// - bufferAllocator is used to buffer the input rows and to allocate the
+// output batches of the input rows joined one at a time, and it should use an
+// unlimited memory account. The number of buffered input rows is bounded.
+// - joinerAllocator is used by the hash joiner to buffer the looked up rows,
+// and it should use a limited memory account of the memory monitor with the
+// name joinerMemMonitorName.
+func NewColLookupJoin(
+	ctx context.Context,
+	allocator *colmem.Allocator,
+	fetcherAllocator *colmem.Allocator,
+	bufferAllocator *colmem.Allocator,
+	joinerAllocator *colmem.Allocator,
+	joinerMemMonitorName string,
+	kvFetcherMemAcc *mon.BoundAccount,
+	flowCtx *execinfra.FlowCtx,
+	helper *colexecargs.ExprHelper,
+	input colexecop.Operator,
+	spec *execinfrapb.JoinReaderSpec,
+	post *execinfrapb.PostProcessSpec,
+	inputTypes []*types.T,
+) (*ColLookupJoin, error) {
+	// NB: we hit this with a zero NodeID (but !ok) with multi-tenancy.
+	if nodeID, ok := flowCtx.NodeID.OptionalNodeID(); nodeID == 0 && ok {
+		return nil, errors.Errorf("attempting to create a ColLookupJoin with uninitialized NodeID")
+	}
+	if !spec.LookupExpr.Empty() || !spec.RemoteLookupExpr.Empty() {
+		return nil, errors.AssertionFailedf("lookup expressions are not supported for vectorized lookup joins")
+	}
+	if len(spec.LookupColumns) == 0 {
+		return nil, errors.AssertionFailedf("lookup columns must be specified for vectorized lookup joins")
+	}
+	switch spec.Type {
+	case descpb.InnerJoin:
+	case descpb.LeftOuterJoin, descpb.LeftSemiJoin, descpb.LeftAntiJoin:
+		if !spec.OnExpr.Empty() {
+			return nil, errors.AssertionFailedf("ON expressions are only supported for vectorized inner lookup joins")
+		}
+	default:
+		return nil, errors.AssertionFailedf("unsupported join type %s for vectorized lookup joins", spec.Type)
+	}
+
+	table := flowCtx.TableDescriptor(&spec.Table)
+	index := table.ActiveIndexes()[spec.IndexIdx]
+	if len(spec.LookupColumns) > index.NumKeyColumns() {
+		return nil, errors.AssertionFailedf(
+			"%d lookup columns for index with %d key columns", len(spec.LookupColumns), index.NumKeyColumns(),
+		)
+	}
+	tableArgs, neededColumns, lookupKeyColOrds, err := populateTableArgsForLookupJoin(
+		ctx, flowCtx, table, index, spec.HasSystemColumns, inputTypes,
+		len(spec.LookupColumns), spec.Type.ShouldIncludeRightColsInOutput(),
+		!spec.OnExpr.Empty(), /* allColumnsNeeded */
+		post, helper,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := cFetcherPool.Get().(*cFetcher)
+	fetcher.cFetcherArgs = cFetcherArgs{
+		spec.LockingStrength,
+		spec.LockingWaitPolicy,
+		flowCtx.EvalCtx.SessionData().LockTimeout,
+		execinfra.GetWorkMemLimit(flowCtx),
+		// Note that the correct estimated row count will be set by the lookup
+		// joiner for each set of spans to read.
+		0,     /* estimatedRowCount */
+		false, /* reverse */
+		flowCtx.TraceKV,
+	}
+	if err = fetcher.Init(
+		fetcherAllocator, kvFetcherMemAcc, tableArgs,
+	); err != nil {
+		fetcher.Release()
+		return nil, err
+	}
+
+	spanAssembler := colexecspan.NewColSpanAssemblerForLookupJoin(
+		flowCtx.Codec(), allocator, table, index, inputTypes, spec.LookupColumns, neededColumns,
+	)
+
+	op := &ColLookupJoin{
+		OneInputNode:         colexecop.NewOneInputNode(input),
+		spanAssembler:        spanAssembler,
+		chunk:                colexecutils.NewAppendOnlyBufferedBatch(bufferAllocator, inputTypes, nil /* colsToStore */),
+		inputBatchSizeLimit:  inputBatchSizeLimit,
+		maxLookupRows:        math.MaxInt32,
+		joinerMemMonitorName: joinerMemMonitorName,
+		flowCtx:              flowCtx,
+		cf:                   fetcher,
+	}
+	op.probeSource = &lookupJoinChunkSource{
+		typs:   inputTypes,
+		chunk:  op.chunk,
+		window: bufferAllocator.NewMemBatchNoCols(inputTypes, coldata.BatchSize()),
+	}
+	op.fetchSource = &lookupJoinFetchSource{s: op}
+	hjSpec := colexecjoin.MakeHashJoinerSpec(
+		spec.Type,
+		spec.LookupColumns,
+		lookupKeyColOrds,
+		inputTypes,
+		tableArgs.typs,
+		spec.LookupColumnsAreKey,
+	)
+	op.joiner = colexecjoin.NewHashJoiner(
+		joinerAllocator, bufferAllocator, hjSpec, op.probeSource, op.fetchSource,
+		colexecjoin.HashJoinerInitialNumBuckets,
+	)
+	op.ResultTypes = spec.Type.MakeOutputTypes(inputTypes, tableArgs.typs)
+	op.streaming.joinType = spec.Type
+	op.streaming.lookupCols = spec.LookupColumns
+	op.streaming.allocator = bufferAllocator
+	op.streaming.outputTypes = op.ResultTypes
+	return op, nil
+}
+
+// Release implements the execinfra.Releasable interface.
+func (s *ColLookupJoin) Release() {
+	s.cf.Release()
+	s.spanAssembler.Release()
+	*s = ColLookupJoin{}
+}
+
+// Close implements the colexecop.Closer interface.
+func (s *ColLookupJoin) Close(context.Context) error {
+	s.closeInternal()
+	if s.tracingSpan != nil {
+		s.tracingSpan.Finish()
+		s.tracingSpan = nil
+	}
+	return nil
+}
+
+// closeInternal is a subset of Close() which doesn't finish the operator's
+// span.
+func (s *ColLookupJoin) closeInternal() {
+	// Note that we're using the context of the ColLookupJoin rather than the
+	// argument of Close() because the ColLookupJoin derives its own tracing
+	// span.
+	ctx := s.EnsureCtx()
+	s.cf.Close(ctx)
+	if s.spanAssembler != nil {
+		// spanAssembler can be nil if Release() has already been called.
+		s.spanAssembler.Close()
+	}
+}
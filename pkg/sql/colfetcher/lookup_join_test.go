@@ -0,0 +1,177 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package colfetcher_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/col/coldata"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/desctestutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexecargs"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexec/colexectestutils"
+	"github.com/cockroachdb/cockroach/pkg/sql/colexecop"
+	"github.com/cockroachdb/cockroach/pkg/sql/colfetcher"
+	"github.com/cockroachdb/cockroach/pkg/sql/colmem"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfra"
+	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/errors"
+)
+
+// TestColLookupJoin verifies that the lookup joins supported by the
+// ColLookupJoin are planned natively when the vectorized lookup joins are
+// enabled and that they produce the expected output, including when the rows
+// looked up for the buffered input rows don't fit into the memory limit.
+func TestColLookupJoin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.t (a INT, b INT, c STRING, PRIMARY KEY (a, b));
+INSERT INTO t.t VALUES (1, 1, 'a'), (2, 1, 'b'), (2, 2, 'c'), (2, 3, 'd'), (4, 1, 'e');
+`); err != nil {
+		t.Fatal(err)
+	}
+	desc := desctestutils.TestingGetPublicTableDescriptor(kvDB, keys.SystemSQLCodec, "t", "t")
+
+	st := cluster.MakeTestingClusterSettings()
+	colfetcher.VectorizeLookupJoinsEnabled.Override(ctx, &st.SV, true)
+	evalCtx := tree.MakeTestingEvalContext(st)
+	defer evalCtx.Stop(ctx)
+	testMemAcc := evalCtx.Mon.MakeBoundAccount()
+	defer testMemAcc.Close(ctx)
+	testAllocator := colmem.NewAllocator(ctx, &testMemAcc, coldata.StandardColumnFactory)
+
+	inputTypes := []*types.T{types.Int}
+	tableTypes := []*types.T{types.Int, types.Int, types.String}
+	// The input contains a key with a single matching row, a key with several
+	// matching rows (twice), a key without matching rows, and a NULL key.
+	input := colexectestutils.Tuples{{1}, {2}, {3}, {nil}, {2}, {4}}
+	for _, tc := range []struct {
+		joinType descpb.JoinType
+		verifier colexectestutils.VerifierType
+		expected colexectestutils.Tuples
+	}{
+		{
+			joinType: descpb.InnerJoin,
+			verifier: colexectestutils.UnorderedVerifier,
+			expected: colexectestutils.Tuples{
+				{1, 1, 1, "a"},
+				{2, 2, 1, "b"}, {2, 2, 2, "c"}, {2, 2, 3, "d"},
+				{2, 2, 1, "b"}, {2, 2, 2, "c"}, {2, 2, 3, "d"},
+				{4, 4, 1, "e"},
+			},
+		},
+		{
+			joinType: descpb.LeftOuterJoin,
+			verifier: colexectestutils.UnorderedVerifier,
+			expected: colexectestutils.Tuples{
+				{1, 1, 1, "a"},
+				{2, 2, 1, "b"}, {2, 2, 2, "c"}, {2, 2, 3, "d"},
+				{3, nil, nil, nil},
+				{nil, nil, nil, nil},
+				{2, 2, 1, "b"}, {2, 2, 2, "c"}, {2, 2, 3, "d"},
+				{4, 4, 1, "e"},
+			},
+		},
+		{
+			joinType: descpb.LeftSemiJoin,
+			verifier: colexectestutils.OrderedVerifier,
+			expected: colexectestutils.Tuples{{1}, {2}, {2}, {4}},
+		},
+		{
+			joinType: descpb.LeftAntiJoin,
+			verifier: colexectestutils.OrderedVerifier,
+			expected: colexectestutils.Tuples{{3}, {nil}},
+		},
+	} {
+		for _, forceDiskSpill := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/forceDiskSpill=%t", tc.joinType, forceDiskSpill), func(t *testing.T) {
+				txn := kv.NewTxn(ctx, s.DB(), s.NodeID())
+				flowCtx := &execinfra.FlowCtx{
+					EvalCtx: &evalCtx,
+					Cfg: &execinfra.ServerConfig{
+						Settings: st,
+					},
+					Txn:    txn,
+					NodeID: evalCtx.NodeID,
+				}
+				// When forcing disk spilling, the memory limit of the joiner
+				// is 1 byte, so the input rows are joined one at a time.
+				flowCtx.Cfg.TestingKnobs.ForceDiskSpill = forceDiskSpill
+				var monitorRegistry colexecargs.MonitorRegistry
+				defer monitorRegistry.Close(ctx)
+				var results []*colexecargs.NewColOperatorResult
+				var memAccounts []*mon.BoundAccount
+				defer func() {
+					for _, r := range results {
+						r.TestCleanupNoError(t)
+					}
+					for _, acc := range memAccounts {
+						acc.Close(ctx)
+					}
+				}()
+				colexectestutils.RunTestsWithTyps(
+					t, testAllocator, []colexectestutils.Tuples{input}, [][]*types.T{inputTypes},
+					tc.expected, tc.verifier,
+					func(inputs []colexecop.Operator) (colexecop.Operator, error) {
+						streamingMemAcc := evalCtx.Mon.MakeBoundAccount()
+						memAccounts = append(memAccounts, &streamingMemAcc)
+						spec := &execinfrapb.ProcessorSpec{
+							Input: []execinfrapb.InputSyncSpec{{ColumnTypes: inputTypes}},
+							Core: execinfrapb.ProcessorCoreUnion{
+								JoinReader: &execinfrapb.JoinReaderSpec{
+									Table:         *desc.TableDesc(),
+									LookupColumns: []uint32{0},
+									Type:          tc.joinType,
+								},
+							},
+							ResultTypes: tc.joinType.MakeOutputTypes(inputTypes, tableTypes),
+						}
+						args := &colexecargs.NewColOperatorArgs{
+							Spec:                spec,
+							Inputs:              []colexecargs.OpWithMetaInfo{{Root: inputs[0]}},
+							StreamingMemAccount: &streamingMemAcc,
+							MonitorRegistry:     &monitorRegistry,
+						}
+						r, err := colbuilder.NewColOperator(ctx, flowCtx, args)
+						if err != nil {
+							return nil, err
+						}
+						results = append(results, r)
+						if _, ok := r.Root.(*colfetcher.ColLookupJoin); !ok {
+							return nil, errors.Newf("expected a ColLookupJoin, found %T", r.Root)
+						}
+						return r.Root, nil
+					},
+				)
+			})
+		}
+	}
+}
@@ -392,6 +392,7 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 		KvDB:             cfg.DB,
 		SQLIDContainer:   cfg.NodeID,
 		JobRegistry:      s.cfg.JobRegistry,
+		TakeStmtCPUNanos: cfg.StatementCPUUsage.TakeCPUNanos,
 		Knobs:            cfg.SQLStatsTestingKnobs,
 		FlushCounter:     serverMetrics.StatsMetrics.SQLStatsFlushStarted,
 		FailureCounter:   serverMetrics.StatsMetrics.SQLStatsFlushFailure,
@@ -400,6 +401,9 @@ func NewServer(cfg *ExecutorConfig, pool *mon.BytesMonitor) *Server {
 
 	s.sqlStats = persistedSQLStats
 	s.sqlStatsController = persistedSQLStats.GetController(cfg.SQLStatusServer)
+	if cfg.StatementCPUUsage != nil {
+		cfg.StatementCPUUsage.lastReset = s.sqlStats.GetLastReset
+	}
 	s.indexUsageStatsController = idxusage.NewController(cfg.SQLStatusServer)
 	return s
 }
@@ -928,6 +932,7 @@ func (s *Server) newConnExecutor(
 	ex.transitionCtx.sessionTracing = &ex.sessionTracing
 
 	ex.extraTxnState.hasAdminRoleCache = HasAdminRoleCache{}
+	ex.extraTxnState.auditRoleCache = auditRoleCache{}
 
 	ex.extraTxnState.atomicAutoRetryCounter = new(int32)
 
@@ -1375,6 +1380,11 @@ type connExecutor struct {
 		// has admin privilege. hasAdminRoleCache is set for the first statement
 		// in a transaction.
 		hasAdminRoleCache HasAdminRoleCache
+
+		// auditRoleCache is used to cache the role memberships of the user
+		// running the transaction when audit rules filter on roles. It is
+		// set for the first statement in a transaction.
+		auditRoleCache auditRoleCache
 	}
 
 	// sessionDataStack contains the user-configurable connection variables.
@@ -1604,6 +1614,7 @@ func (ns *prepStmtNamespace) resetTo(
 func (ex *connExecutor) resetExtraTxnState(ctx context.Context, ev txnEvent) error {
 	ex.extraTxnState.jobs = nil
 	ex.extraTxnState.hasAdminRoleCache = HasAdminRoleCache{}
+	ex.extraTxnState.auditRoleCache = auditRoleCache{}
 	ex.extraTxnState.schemaChangerState = SchemaChangerState{
 		mode: ex.sessionData().NewSchemaChangerMode,
 	}
@@ -117,7 +117,11 @@ func (ex *connExecutor) execStmt(
 		ev, payload = ex.execStmtInNoTxnState(ctx, ast)
 
 	case stateOpen:
-		if ex.server.cfg.Settings.CPUProfileType() == cluster.CPUProfileWithLabels {
+		// The labels are attached while continuous CPU profiling is enabled
+		// too, not only while a profile is being taken, so that statements
+		// that started before the profile are attributed.
+		if ex.server.cfg.Settings.CPUProfileType() == cluster.CPUProfileWithLabels ||
+			ex.server.cfg.StatementCPUUsage.continuousProfilingEnabled() {
 			remoteAddr := "internal"
 			if rAddr := ex.sessionData().RemoteAddr; rAddr != nil {
 				remoteAddr = rAddr.String()
@@ -129,10 +133,14 @@ func (ex *connExecutor) execStmt(
 				stmtNoConstants = formatStatementHideConstants(ast)
 			}
 			labels := pprof.Labels(
-				"appname", ex.sessionData().ApplicationName,
+				appNameProfileLabel, ex.sessionData().ApplicationName,
 				"addr", remoteAddr,
 				"stmt.tag", ast.StatementTag(),
 				"stmt.no.constants", stmtNoConstants,
+				stmtExecIDProfileLabel, ex.server.cfg.StatementCPUUsage.nextExecIDProfileLabelValue(),
+				stmtFingerprintIDProfileLabel, stmtFingerprintIDProfileLabelValue(
+					stmtNoConstants, ex.implicitTxn(), ex.sessionData().Database),
+				tenantProfileLabel, ex.server.cfg.StatementCPUUsage.tenantProfileLabelValue(),
 			)
 			pprof.Do(ctx, labels, func(ctx context.Context) {
 				ev, payload, err = ex.execStmtInOpenState(ctx, parserStmt, prepared, pinfo, res, canAutoCommit)
@@ -987,6 +995,11 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 			ex.extraTxnState.hasAdminRoleCache.IsSet = true
 		}
 	}
+	// Likewise, the role memberships needed by the audit rules are looked
+	// up before execution. Audit rules do not apply to internal statements.
+	if ex.executorType != executorTypeInternal {
+		ex.planner.maybeCacheAuditRoles(ctx, &ex.extraTxnState.auditRoleCache)
+	}
 	// Prepare the plan. Note, the error is processed below. Everything
 	// between here and there needs to happen even if there's an error.
 	err := ex.makeExecPlan(ctx, planner)
@@ -1014,6 +1027,7 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 			res.Err(),
 			ex.statsCollector.PhaseTimes().GetSessionPhaseTime(sessionphase.SessionQueryReceived),
 			&ex.extraTxnState.hasAdminRoleCache,
+			&ex.extraTxnState.auditRoleCache,
 			ex.server.TelemetryLoggingMetrics,
 		)
 	}()
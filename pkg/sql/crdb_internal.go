@@ -160,6 +160,7 @@ var crdbInternal = virtualSchema{
 		catconstants.CrdbInternalActiveRangeFeedsTable:              crdbInternalActiveRangeFeedsTable,
 		catconstants.CrdbInternalTenantUsageDetailsViewID:           crdbInternalTenantUsageDetailsView,
 		catconstants.CrdbInternalPgCatalogTableIsImplementedTableID: crdbInternalPgCatalogTableIsImplementedTable,
+		catconstants.CrdbInternalIndexRecommendationsTableID:        crdbInternalIndexRecommendationsTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -1005,7 +1006,8 @@ CREATE TABLE crdb_internal.node_statement_statistics (
   full_scan           BOOL NOT NULL,
   sample_plan         JSONB,
   database_name       STRING NOT NULL,
-  exec_node_ids       INT[] NOT NULL
+  exec_node_ids       INT[] NOT NULL,
+  cpu_nanos           INT NOT NULL
 )`,
 	populate: func(ctx context.Context, p *planner, _ catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
 		hasViewActivityOrViewActivityRedacted, err := p.HasViewActivityOrViewActivityRedactedRole(ctx)
@@ -1052,6 +1054,13 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 				}
 			}
 
+			// The CPU time attributed to the statement by the continuous CPU
+			// profiler, if enabled.
+			var cpuNanos int64
+			if u := p.execCfg.StatementCPUUsage; u != nil {
+				cpuNanos = u.CPUNanos(stats.ID, stats.Key.App)
+			}
+
 			err := addRow(
 				tree.NewDInt(tree.DInt(nodeID)),                           // node_id
 				tree.NewDString(stats.Key.App),                            // application_name
@@ -1094,6 +1103,7 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 				tree.NewDJSON(samplePlan),           // sample_plan
 				tree.NewDString(stats.Key.Database), // database_name
 				execNodeIDs,                         // exec_node_ids
+				tree.NewDInt(tree.DInt(cpuNanos)),   // cpu_nanos
 			)
 			if err != nil {
 				return err
@@ -5071,6 +5081,102 @@ CREATE TABLE crdb_internal.index_usage_statistics (
 	},
 }
 
+var crdbInternalIndexRecommendationsTable = virtualSchemaTable{
+	comment: `index recommendations for the workload of the cluster, and secondary ` +
+		`indexes that have not been read recently (cluster RPC; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.index_recommendations (
+  type                 STRING NOT NULL,
+  table_id             INT NOT NULL,
+  table_name           STRING NOT NULL,
+  index_name           STRING,
+  sql                  STRING NOT NULL,
+  fingerprint_count    INT,
+  estimated_savings    FLOAT,
+  estimated_write_cost FLOAT,
+  last_read            TIMESTAMPTZ
+);`,
+	populate: func(ctx context.Context, p *planner, dbContext catalog.DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		hasViewActivityOrViewActivityRedacted, err := p.HasViewActivityOrViewActivityRedactedRole(ctx)
+		if err != nil {
+			return err
+		}
+		if !hasViewActivityOrViewActivityRedacted {
+			return pgerror.Newf(pgcode.InsufficientPrivilege,
+				"user %s does not have %s or %s privilege", p.User(), roleoption.VIEWACTIVITY, roleoption.VIEWACTIVITYREDACTED)
+		}
+
+		if advisor := p.execCfg.IndexAdvisor; advisor != nil {
+			recs := advisor.Recommendations()
+			for i := range recs {
+				rec := &recs[i]
+				recType := "index creation"
+				indexName := tree.DNull
+				if rec.ExistingIndex != "" {
+					recType = "index replacement"
+					indexName = tree.NewDString(string(rec.ExistingIndex))
+				}
+				if err := addRow(
+					tree.NewDString(recType),                      // type
+					tree.NewDInt(tree.DInt(rec.TableID)),          // table_id
+					tree.NewDString(rec.TableName.Table()),        // table_name
+					indexName,                                     // index_name
+					tree.NewDString(rec.SQL()),                    // sql
+					tree.NewDInt(tree.DInt(rec.fingerprintCount)), // fingerprint_count
+					tree.NewDFloat(tree.DFloat(rec.savings)),      // estimated_savings
+					tree.NewDFloat(tree.DFloat(rec.writeCost)),    // estimated_write_cost
+					tree.DNull, // last_read
+				); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Perform RPC Fanout.
+		stats, err :=
+			p.extendedEvalCtx.SQLStatusServer.IndexUsageStatistics(ctx, &serverpb.IndexUsageStatisticsRequest{})
+		if err != nil {
+			return err
+		}
+		indexStats := idxusage.NewLocalIndexUsageStatsFromExistingStats(&idxusage.Config{}, stats.Statistics)
+		now := timeutil.Now()
+		threshold := unusedIndexThreshold.Get(&p.execCfg.Settings.SV)
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual,
+			func(db catalog.DatabaseDescriptor, scName string, table catalog.TableDescriptor) error {
+				tn := tree.MakeTableNameWithSchema(tree.Name(db.GetName()), tree.Name(scName), tree.Name(table.GetName()))
+				for _, idx := range table.PublicNonPrimaryIndexes() {
+					stats := indexStats.Get(roachpb.TableID(table.GetID()), roachpb.IndexID(idx.GetID()))
+					if !isUnusedIndex(idx, stats.LastRead, now, threshold) {
+						continue
+					}
+					dropCmd := tree.DropIndex{
+						IndexList: []*tree.TableIndexName{{Table: tn, Index: tree.UnrestrictedName(idx.GetName())}},
+					}
+					lastRead := tree.DNull
+					if !stats.LastRead.IsZero() {
+						if lastRead, err = tree.MakeDTimestampTZ(stats.LastRead, time.Nanosecond); err != nil {
+							return err
+						}
+					}
+					if err := addRow(
+						tree.NewDString("index drop"),          // type
+						tree.NewDInt(tree.DInt(table.GetID())), // table_id
+						tree.NewDString(table.GetName()),       // table_name
+						tree.NewDString(idx.GetName()),         // index_name
+						tree.NewDString(dropCmd.String()+";"),  // sql
+						tree.DNull,                             // fingerprint_count
+						tree.DNull,                             // estimated_savings
+						tree.DNull,                             // estimated_write_cost
+						lastRead,                               // last_read
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	},
+}
+
 // crdb_internal.cluster_statement_statistics contains cluster-wide statement statistics
 // that have not yet been flushed to disk.
 var crdbInternalClusterStmtStatsTable = virtualSchemaTable{
@@ -14,6 +14,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/featureflag"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
@@ -281,6 +282,12 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 		}
 	}
 
+	if n.UsingExtremes {
+		if err := checkCreateStatsUsingExtremes(ctx, n.p.ExecCfg().Settings, tableDesc, n.ColumnNames, colStats); err != nil {
+			return nil, err
+		}
+	}
+
 	// Evaluate the AS OF time, if any.
 	var asOfTimestamp *hlc.Timestamp
 	if n.Options.AsOf.Expr != nil {
@@ -316,11 +323,58 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 			Statement:       eventLogStatement,
 			AsOf:            asOfTimestamp,
 			MaxFractionIdle: n.Options.Throttling,
+			UsingExtremes:   n.UsingExtremes,
 		},
 		Progress: jobspb.CreateStatsProgress{},
 	}, nil
 }
 
+// checkCreateStatsUsingExtremes verifies that partial statistics can be
+// collected on the extremes of the requested column: exactly one column must
+// be requested, and it must be the first key column of a forward index so that
+// the values outside the bounds of its histogram can be scanned directly.
+func checkCreateStatsUsingExtremes(
+	ctx context.Context,
+	st *cluster.Settings,
+	desc catalog.TableDescriptor,
+	columnNames tree.NameList,
+	colStats []jobspb.CreateStatsDetails_ColStat,
+) error {
+	if !st.Version.IsActive(ctx, clusterversion.PartialTableStatistics) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"creating partial statistics is not supported until version upgrade is finalized")
+	}
+	if len(columnNames) != 1 {
+		return pgerror.New(pgcode.InvalidParameterValue,
+			"creating partial statistics USING EXTREMES requires exactly one column")
+	}
+	if !colStats[0].HasHistogram {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"cannot create partial statistics on column %q because it does not support histograms",
+			columnNames[0])
+	}
+	if findIndexForExtremes(desc, colStats[0].ColumnIDs[0]) == nil {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"cannot create partial statistics on column %q because it is not the first key column of a non-partial forward index",
+			columnNames[0])
+	}
+	return nil
+}
+
+// findIndexForExtremes returns a public, non-partial, forward index whose first
+// key column is the given column, preferring the primary index. It returns nil
+// if there is no such index.
+func findIndexForExtremes(desc catalog.TableDescriptor, colID descpb.ColumnID) catalog.Index {
+	for _, idx := range desc.ActiveIndexes() {
+		if idx.GetType() != descpb.IndexDescriptor_FORWARD || idx.IsPartial() ||
+			idx.NumKeyColumns() == 0 || idx.GetKeyColumnID(0) != colID {
+			continue
+		}
+		return idx
+	}
+	return nil
+}
+
 // maxNonIndexCols is the maximum number of non-index columns that we will use
 // when choosing a default set of column statistics.
 const maxNonIndexCols = 100
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/docs"
 	"github.com/cockroachdb/cockroach/pkg/geo/geoindex"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -1966,6 +1967,21 @@ func NewTableDesc(
 					return nil, err
 				}
 			}
+		case *tree.ExcludeConstraintTableDef:
+			if !st.Version.IsActive(ctx, clusterversion.ExclusionConstraints) {
+				return nil, pgerror.New(pgcode.FeatureNotSupported,
+					"exclusion constraints are not supported until version upgrade is finalized")
+			}
+			idx, err := makeExclusionConstraintIndex(
+				ctx, &desc, d, &n.Table, indexEncodingVersion, semaCtx,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if err := desc.AddSecondaryIndex(idx); err != nil {
+				return nil, err
+			}
+
 		case *tree.CheckConstraintTableDef, *tree.ForeignKeyConstraintTableDef, *tree.FamilyTableDef:
 			// pass, handled below.
 
@@ -2140,7 +2156,7 @@ func NewTableDesc(
 				}
 			}
 
-		case *tree.IndexTableDef, *tree.FamilyTableDef, *tree.LikeTableDef:
+		case *tree.IndexTableDef, *tree.ExcludeConstraintTableDef, *tree.FamilyTableDef, *tree.LikeTableDef:
 			// Pass, handled above.
 
 		case *tree.CheckConstraintTableDef:
@@ -2443,6 +2459,114 @@ func rowLevelTTLAutomaticColumnExpr(intervalExpr tree.Expr) tree.Expr {
 	}
 }
 
+// makeExclusionConstraintIndex returns the descriptor of the index which backs
+// the exclusion constraint d of a new table. The key columns of the index are
+// the columns compared with = followed by the columns of the first element
+// compared with &&, so that the checks which enforce the constraint can use
+// the index to find conflicting rows. If that element is a GEOMETRY or
+// GEOGRAPHY column, the index is an inverted index.
+func makeExclusionConstraintIndex(
+	ctx context.Context,
+	desc *tabledesc.Mutable,
+	d *tree.ExcludeConstraintTableDef,
+	tn *tree.TableName,
+	version descpb.IndexDescriptorVersion,
+	semaCtx *tree.SemaContext,
+) (descpb.IndexDescriptor, error) {
+	if d.Name != "" {
+		if idx, _ := desc.FindIndexWithName(d.Name.String()); idx != nil {
+			return descpb.IndexDescriptor{}, pgerror.Newf(pgcode.DuplicateRelation,
+				"duplicate index name: %q", d.Name)
+		}
+	}
+	if desc.PartitionAllBy {
+		return descpb.IndexDescriptor{}, pgerror.New(pgcode.FeatureNotSupported,
+			"exclusion constraints are not supported on tables which are implicitly partitioned with PARTITION ALL BY or LOCALITY REGIONAL BY ROW",
+		)
+	}
+	elems, err := schemaexpr.ValidateExclusionConstraintElems(desc, d.Elems)
+	if err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+	idx := descpb.IndexDescriptor{
+		Name:              string(d.Name),
+		Version:           version,
+		ExclusionElements: elems,
+	}
+
+	var keyColIDs catalog.TableColSet
+	var columns tree.IndexElemList
+	addKeyColumn := func(colID descpb.ColumnID) error {
+		if keyColIDs.Contains(colID) {
+			return nil
+		}
+		keyColIDs.Add(colID)
+		col, err := desc.FindColumnWithID(colID)
+		if err != nil {
+			return err
+		}
+		columns = append(columns, tree.IndexElem{Column: col.ColName(), Direction: tree.Ascending})
+		return nil
+	}
+	for i := range elems {
+		if elems[i].Operator == descpb.ExclusionElement_EQUAL {
+			if err := addKeyColumn(elems[i].ColumnIDs[0]); err != nil {
+				return descpb.IndexDescriptor{}, err
+			}
+		}
+	}
+	for i := range elems {
+		if elems[i].Operator != descpb.ExclusionElement_OVERLAPS {
+			continue
+		}
+		if len(elems[i].ColumnIDs) == 2 {
+			for _, colID := range elems[i].ColumnIDs {
+				if err := addKeyColumn(colID); err != nil {
+					return descpb.IndexDescriptor{}, err
+				}
+			}
+			break
+		}
+		colID := elems[i].ColumnIDs[0]
+		if keyColIDs.Contains(colID) {
+			break
+		}
+		if err := addKeyColumn(colID); err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		col, err := desc.FindColumnWithID(colID)
+		if err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		idx.Type = descpb.IndexDescriptor_INVERTED
+		switch col.GetType().Family() {
+		case types.GeometryFamily:
+			config, err := geoindex.GeometryIndexConfigForSRID(col.GetType().GeoSRIDOrZero())
+			if err != nil {
+				return descpb.IndexDescriptor{}, err
+			}
+			idx.GeoConfig = *config
+		case types.GeographyFamily:
+			idx.GeoConfig = *geoindex.DefaultGeographyIndexConfig()
+		}
+		break
+	}
+	if err := idx.FillColumns(columns); err != nil {
+		return descpb.IndexDescriptor{}, err
+	}
+
+	if d.Predicate != nil {
+		expr, err := schemaexpr.ValidatePartialIndexPredicate(
+			ctx, desc, d.Predicate, tn, semaCtx,
+		)
+		if err != nil {
+			return descpb.IndexDescriptor{}, err
+		}
+		idx.Predicate = expr
+	}
+	return idx, nil
+}
+
 // replaceLikeTableOps processes the TableDefs in the input CreateTableNode,
 // searching for LikeTableDefs. If any are found, each LikeTableDef will be
 // replaced in the output tree.TableDefs (which will be a copy of the input
@@ -2642,6 +2766,18 @@ func replaceLikeTableOpts(n *tree.CreateTable, params runParams) (tree.TableDefs
 						return nil, err
 					}
 				}
+				if idx.IsExclusion() {
+					// Exclusion constraints are copied along with their index.
+					elems, err := schemaexpr.ExclusionConstraintElemsForDisplay(td, idx.GetExclusionElements())
+					if err != nil {
+						return nil, err
+					}
+					def = &tree.ExcludeConstraintTableDef{
+						Name:      indexDef.Name,
+						Elems:     elems,
+						Predicate: indexDef.Predicate,
+					}
+				}
 				defs = append(defs, def)
 			}
 		}
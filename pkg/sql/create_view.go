@@ -237,6 +237,7 @@ func (n *createViewNode) startExec(params runParams) error {
 			desc.IsMaterializedView = true
 			desc.State = descpb.DescriptorState_ADD
 			desc.CreateAsOfTime = params.p.Txn().ReadTimestamp()
+			desc.MaterializedViewRefreshTime = desc.CreateAsOfTime
 			version := params.ExecCfg().Settings.Version.ActiveVersion(params.ctx)
 			if err := desc.AllocateIDs(params.ctx, version); err != nil {
 				return err
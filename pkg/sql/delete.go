@@ -51,7 +51,7 @@ type deleteRun struct {
 
 	// partialIndexDelValsOffset is the offset of partial index delete
 	// indicators in the source values. It is equal to the number of fetched
-	// columns.
+	// columns plus the number of passthrough columns.
 	partialIndexDelValsOffset int
 
 	// rowIdxToRetIdx is the mapping from the columns returned by the deleter
@@ -60,6 +60,11 @@ type deleteRun struct {
 	// of the mutation. Otherwise, the value at the i-th index refers to the
 	// index of the resultRowBuffer where the i-th column is to be returned.
 	rowIdxToRetIdx []int
+
+	// numPassthrough is the number of columns in addition to the set of
+	// columns of the target table being returned, that we must pass through
+	// from the input node.
+	numPassthrough int
 }
 
 var _ mutationPlanNode = &deleteNode{}
@@ -170,8 +175,12 @@ func (d *deleteNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		sourceVals = sourceVals[:d.run.partialIndexDelValsOffset]
 	}
 
+	// Remove extra columns for partial index predicate values and
+	// passthrough columns from the USING clause.
+	deleteVals := sourceVals[:len(d.run.td.rd.FetchCols)]
+
 	// Queue the deletion in the KV batch.
-	if err := d.run.td.row(params.ctx, sourceVals, pm, d.run.traceKV); err != nil {
+	if err := d.run.td.row(params.ctx, deleteVals, pm, d.run.traceKV); err != nil {
 		return err
 	}
 
@@ -186,7 +195,20 @@ func (d *deleteNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		resultValues := make(tree.Datums, d.run.td.rows.NumCols())
 		for i, retIdx := range d.run.rowIdxToRetIdx {
 			if retIdx >= 0 {
-				resultValues[retIdx] = sourceVals[i]
+				resultValues[retIdx] = deleteVals[i]
+			}
+		}
+
+		// At this point we've extracted all the RETURNING values that are part
+		// of the target table. We must now extract the columns in the RETURNING
+		// clause that refer to other tables (from the USING clause of the delete).
+		if d.run.numPassthrough > 0 {
+			passthroughBegin := len(d.run.td.rd.FetchCols)
+			passthroughEnd := passthroughBegin + d.run.numPassthrough
+			passthroughValues := sourceVals[passthroughBegin:passthroughEnd]
+
+			for i := 0; i < d.run.numPassthrough; i++ {
+				resultValues[len(resultValues)-d.run.numPassthrough+i] = passthroughValues[i]
 			}
 		}
 
@@ -1294,6 +1294,18 @@ var localScansConcurrencyLimit = settings.RegisterIntSetting(
 	settings.NonNegativeInt,
 )
 
+// parallelLocalScansProhibited returns whether the session settings prohibit
+// having concurrent TableReaders in the local plans.
+func parallelLocalScansProhibited(planCtx *PlanningCtx) bool {
+	sd := planCtx.ExtendedEvalCtx.EvalContext.SessionData()
+	// If we have locality optimized search enabled and we won't use the
+	// vectorized engine, using the parallel scans might actually be
+	// significantly worse, so we prohibit it. This is the case because if we
+	// have a local region hit, we would still execute all lookups into the
+	// remote regions and would block until all come back in the row-based flow.
+	return sd.LocalityOptimizedSearch && sd.VectorizeMode == sessiondatapb.VectorizeOff
+}
+
 // maybeParallelizeLocalScans check whether we are planning such a TableReader
 // for the local flow that would benefit (and is safe) to parallelize.
 func (dsp *DistSQLPlanner) maybeParallelizeLocalScans(
@@ -1306,17 +1318,10 @@ func (dsp *DistSQLPlanner) maybeParallelizeLocalScans(
 	// - there is still quota for running more parallel local TableReaders,
 	// then we will split all spans according to the leaseholder boundaries and
 	// will create a separate TableReader for each node.
-	sd := planCtx.ExtendedEvalCtx.EvalContext.SessionData()
-	// If we have locality optimized search enabled and we won't use the
-	// vectorized engine, using the parallel scans might actually be
-	// significantly worse, so we prohibit it. This is the case because if we
-	// have a local region hit, we would still execute all lookups into the
-	// remote regions and would block until all come back in the row-based flow.
-	prohibitParallelScans := sd.LocalityOptimizedSearch && sd.VectorizeMode == sessiondatapb.VectorizeOff
 	if len(info.reqOrdering) == 0 &&
 		info.parallelize &&
 		planCtx.parallelizeScansIfLocal &&
-		!prohibitParallelScans &&
+		!parallelLocalScansProhibited(planCtx) &&
 		dsp.parallelLocalScansSem.ApproximateQuota() > 0 &&
 		planCtx.spanIter != nil { // This condition can only be false in tests.
 		parallelizeLocal = true
@@ -1426,6 +1431,10 @@ func (dsp *DistSQLPlanner) planTableReaders(
 		}
 		spanPartitions = []SpanPartition{{sqlInstanceID, info.spans}}
 	}
+	// Split the spans on each node further so that the scan can use multiple
+	// cores of the node.
+	var splitWithinNodes bool
+	spanPartitions, splitWithinNodes = dsp.maybeSplitSpanPartitionsWithinNodes(planCtx, info, spanPartitions)
 
 	corePlacement := make([]physicalplan.ProcessorCorePlacement, len(spanPartitions))
 	for i, sp := range spanPartitions {
@@ -1466,9 +1475,11 @@ func (dsp *DistSQLPlanner) planTableReaders(
 	p.PlanToStreamColMap = identityMap(make([]int, len(typs)), len(typs))
 	p.SetMergeOrdering(dsp.convertOrdering(info.reqOrdering, p.PlanToStreamColMap))
 
-	if parallelizeLocal {
+	if parallelizeLocal && !splitWithinNodes {
 		// If we planned multiple table readers, we need to merge the streams
-		// into one.
+		// into one. When the spans were split for intra-node parallelism, we
+		// keep the streams separate so that the processors planned on top of
+		// the scan are run concurrently too.
 		p.AddSingleGroupStage(dsp.gatewaySQLInstanceID, execinfrapb.ProcessorCoreUnion{Noop: &execinfrapb.NoopCoreSpec{}}, execinfrapb.PostProcessSpec{}, p.GetResultTypes())
 	}
 
@@ -1704,12 +1715,15 @@ func (dsp *DistSQLPlanner) planAggregators(
 
 	// We either have a local stage on each stream followed by a final stage, or
 	// just a final stage. We only use a local stage if:
-	//  - the previous stage is distributed on multiple nodes, and
+	//  - the previous stage is distributed on multiple nodes (or has multiple
+	//    streams on a single node with intra-node parallelism enabled, in which
+	//    case the final stage might be planned on the gateway only), and
 	//  - all aggregation functions support it, and
 	//  - no function is performing distinct aggregation.
 	//  TODO(radu): we could relax this by splitting the aggregation into two
 	//  different paths and joining on the results.
-	multiStage := prevStageNode == 0
+	multiStage := prevStageNode == 0 ||
+		(len(p.ResultRouters) > 1 && intraNodeParallelism.Get(&dsp.st.SV) > 1)
 	if multiStage {
 		for _, e := range info.aggregations {
 			if e.Distinct {
@@ -2080,9 +2094,17 @@ func (dsp *DistSQLPlanner) planAggregators(
 			}
 		}
 
-		// We have multiple streams, so we definitely have a processor planned
-		// on a remote node.
-		stageID := p.NewStage(true /* containsRemoteProcessor */, info.allowPartialDistribution)
+		// The final stage processors are placed on the nodes of the result
+		// routers. With intra-node parallelism, the multiple streams can all
+		// be on the gateway, so we check whether any of them is remote.
+		containsRemoteProcessor := false
+		for _, resultProc := range p.ResultRouters {
+			if p.Processors[resultProc].SQLInstanceID != p.GatewaySQLInstanceID {
+				containsRemoteProcessor = true
+				break
+			}
+		}
+		stageID := p.NewStage(containsRemoteProcessor, info.allowPartialDistribution)
 
 		// We have one final stage processor for each result router. This is a
 		// somewhat arbitrary decision; we could have a different number of nodes
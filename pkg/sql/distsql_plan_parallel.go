@@ -0,0 +1,272 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient/kvcoord"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// maxIntraNodeParallelism is the upper bound on the number of TableReaders
+// that are planned on a single node for a single scan.
+const maxIntraNodeParallelism = 64
+
+// intraNodeParallelism determines the maximum number of TableReaders that are
+// planned on a single node for each scan. When it is greater than one, the
+// spans assigned to each node are split further according to the histogram
+// on the first key column of the scanned index, so that a scan (and the
+// processors planned on top of it) can use multiple cores of the node even if
+// all of its data lives in a single range. Without such a histogram, the spans
+// are split at the boundaries of the ranges they cover.
+var intraNodeParallelism = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.distsql.intra_node_parallelism",
+	"maximum number of concurrent table readers planned on a single node for a "+
+		"scan; the spans are split according to table statistics, or at range "+
+		"boundaries if there are none (1 disables intra-node parallelism)",
+	1,
+	func(v int64) error {
+		if v < 1 || v > maxIntraNodeParallelism {
+			return errors.Errorf("value must be between 1 and %d", maxIntraNodeParallelism)
+		}
+		return nil
+	},
+).WithPublic()
+
+// maybeSplitSpanPartitionsWithinNodes splits each of the span partitions into
+// multiple partitions assigned to the same SQL instance, if intra-node
+// parallelism is enabled and it is safe to do so for the given scan. The
+// returned boolean indicates whether any partition was split.
+//
+// Splitting is only done for scans without a required ordering, without a
+// hard limit, and that are not reverse scans; such scans benefit little from
+// parallelism since all rows would have to be merged in order anyway.
+func (dsp *DistSQLPlanner) maybeSplitSpanPartitionsWithinNodes(
+	planCtx *PlanningCtx, info *tableReaderPlanningInfo, spanPartitions []SpanPartition,
+) ([]SpanPartition, bool) {
+	degree := int(intraNodeParallelism.Get(&dsp.st.SV))
+	if degree <= 1 ||
+		len(info.reqOrdering) != 0 ||
+		info.reverse ||
+		!info.parallelize ||
+		info.post.Limit != 0 ||
+		planCtx.ExtendedEvalCtx == nil {
+		return spanPartitions, false
+	}
+	if planCtx.isLocal && (!planCtx.parallelizeScansIfLocal || parallelLocalScansProhibited(planCtx)) {
+		// Local plans can only have concurrent processors when the plan is
+		// safe to be run using a leaf txn.
+		return spanPartitions, false
+	}
+	splitKeys := dsp.getIntraNodeSplitKeys(planCtx, info, degree)
+	if len(splitKeys) == 0 {
+		// Without a histogram, the spans can still be split at the boundaries
+		// of the ranges they cover, which never fall in the middle of a row.
+		splitKeys = dsp.getRangeBoundarySplitKeys(planCtx, spanPartitions, degree)
+	}
+	if len(splitKeys) == 0 {
+		return spanPartitions, false
+	}
+	var newPartitions []SpanPartition
+	for _, sp := range spanPartitions {
+		for _, spans := range splitSpansAtKeys(sp.Spans, splitKeys) {
+			if len(spans) > 0 {
+				newPartitions = append(newPartitions, SpanPartition{sp.SQLInstanceID, spans})
+			}
+		}
+	}
+	if len(newPartitions) == len(spanPartitions) {
+		return spanPartitions, false
+	}
+	if planCtx.isLocal {
+		// The additional TableReaders in the local plans are subject to the
+		// same quota as the ones created when parallelizing the scans across
+		// the leaseholders.
+		extra := uint64(len(newPartitions) - len(spanPartitions))
+		alloc, err := dsp.parallelLocalScansSem.TryAcquire(planCtx.ctx, extra)
+		if err != nil {
+			return spanPartitions, false
+		}
+		planCtx.onFlowCleanup = append(planCtx.onFlowCleanup, alloc.Release)
+	}
+	return newPartitions, true
+}
+
+// getIntraNodeSplitKeys returns up to degree-1 keys of the scanned index that
+// divide the table into parts containing roughly the same number of rows. The
+// keys are determined from the most recent histogram on the first key column
+// of the index and are sorted in the key order. nil is returned if there is
+// no such histogram.
+func (dsp *DistSQLPlanner) getIntraNodeSplitKeys(
+	planCtx *PlanningCtx, info *tableReaderPlanningInfo, degree int,
+) []roachpb.Key {
+	fetchSpec := &info.spec.FetchSpec
+	statsCache := planCtx.ExtendedEvalCtx.ExecCfg.TableStatsCache
+	if statsCache == nil || len(fetchSpec.KeyAndSuffixColumns) == 0 {
+		return nil
+	}
+	firstCol := &fetchSpec.KeyAndSuffixColumns[0]
+	tableStats, err := statsCache.GetTableStats(planCtx.ctx, info.desc)
+	if err != nil {
+		log.VEventf(planCtx.ctx, 2, "could not get table statistics for intra-node parallelism: %v", err)
+		return nil
+	}
+	// The statistics are ordered from the most recent to the oldest.
+	var histogram []tree.Datum
+	var counts []float64
+	for _, stat := range tableStats {
+		if len(stat.ColumnIDs) != 1 || stat.ColumnIDs[0] != firstCol.ColumnID || len(stat.Histogram) == 0 {
+			continue
+		}
+		for _, b := range stat.Histogram {
+			if b.UpperBound == tree.DNull || !b.UpperBound.ResolvedType().Equivalent(firstCol.Type) {
+				// Inverted indexes have histograms on the encoded keys which
+				// don't match the column type.
+				continue
+			}
+			histogram = append(histogram, b.UpperBound)
+			counts = append(counts, b.NumRange+b.NumEq)
+		}
+		break
+	}
+	if len(histogram) == 0 {
+		return nil
+	}
+	var total float64
+	for _, c := range counts {
+		total += c
+	}
+	dir, err := firstCol.Direction.ToEncodingDirection()
+	if err != nil {
+		return nil
+	}
+	prefix := rowenc.MakeIndexKeyPrefix(planCtx.ExtendedEvalCtx.Codec, info.desc.GetID(), fetchSpec.IndexID)
+	splitKeys := make([]roachpb.Key, 0, degree-1)
+	var cumulative float64
+	bucketIdx := 0
+	for q := 1; q < degree; q++ {
+		target := total * float64(q) / float64(degree)
+		for bucketIdx < len(histogram) && cumulative+counts[bucketIdx] < target {
+			cumulative += counts[bucketIdx]
+			bucketIdx++
+		}
+		if bucketIdx >= len(histogram) {
+			break
+		}
+		// The split key is the upper bound of the bucket in which the quantile
+		// falls.
+		key, err := keyside.Encode(append(roachpb.Key(nil), prefix...), histogram[bucketIdx], dir)
+		if err != nil {
+			return nil
+		}
+		splitKeys = append(splitKeys, key)
+		cumulative += counts[bucketIdx]
+		bucketIdx++
+	}
+	sort.Slice(splitKeys, func(i, j int) bool { return splitKeys[i].Compare(splitKeys[j]) < 0 })
+	// Remove duplicates.
+	res := splitKeys[:0]
+	for i := range splitKeys {
+		if i == 0 || !splitKeys[i].Equal(splitKeys[i-1]) {
+			res = append(res, splitKeys[i])
+		}
+	}
+	return res
+}
+
+// getRangeBoundarySplitKeys returns up to degree-1 keys, sorted in the key
+// order, that divide the ranges covered by the given span partitions into
+// groups of roughly the same number of ranges. The keys are boundaries of
+// these ranges. nil is returned if the spans are all within a single range.
+func (dsp *DistSQLPlanner) getRangeBoundarySplitKeys(
+	planCtx *PlanningCtx, spanPartitions []SpanPartition, degree int,
+) []roachpb.Key {
+	it := planCtx.spanIter
+	if it == nil {
+		return nil
+	}
+	var boundaries []roachpb.Key
+	for _, sp := range spanPartitions {
+		for _, span := range sp.Spans {
+			if len(span.EndKey) == 0 {
+				continue
+			}
+			for it.Seek(planCtx.ctx, span, kvcoord.Ascending); ; it.Next(planCtx.ctx) {
+				if !it.Valid() {
+					log.VEventf(planCtx.ctx, 2, "could not resolve ranges for intra-node parallelism: %v", it.Error())
+					return nil
+				}
+				if !it.NeedAnother() {
+					break
+				}
+				boundaries = append(boundaries, roachpb.Key(it.Desc().EndKey))
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Compare(boundaries[j]) < 0 })
+	return pickSplitKeys(boundaries, degree)
+}
+
+// pickSplitKeys returns up to degree-1 of the given sorted range boundaries,
+// deduplicated, that divide the ranges into groups of roughly the same size.
+func pickSplitKeys(boundaries []roachpb.Key, degree int) []roachpb.Key {
+	res := boundaries[:0]
+	for i := range boundaries {
+		if i == 0 || !boundaries[i].Equal(boundaries[i-1]) {
+			res = append(res, boundaries[i])
+		}
+	}
+	if len(res) < degree {
+		return res
+	}
+	// There are len(res)+1 ranges, and the i-th group ends with the range at
+	// index i*(len(res)+1)/degree-1.
+	numRanges := len(res) + 1
+	picked := make([]roachpb.Key, 0, degree-1)
+	for i := 1; i < degree; i++ {
+		picked = append(picked, res[i*numRanges/degree-1])
+	}
+	return picked
+}
+
+// splitSpansAtKeys divides the given ordered spans into len(splitKeys)+1
+// groups: the i-th group contains the parts of the spans that are between
+// splitKeys[i-1] (inclusive) and splitKeys[i] (exclusive). splitKeys must be
+// sorted. Some of the returned groups might be empty.
+func splitSpansAtKeys(spans roachpb.Spans, splitKeys []roachpb.Key) []roachpb.Spans {
+	res := make([]roachpb.Spans, len(splitKeys)+1)
+	for _, span := range spans {
+		// Find the first split key that is greater than the start of the span.
+		i := sort.Search(len(splitKeys), func(i int) bool {
+			return splitKeys[i].Compare(span.Key) > 0
+		})
+		if len(span.EndKey) == 0 {
+			// This is a point span.
+			res[i] = append(res[i], span)
+			continue
+		}
+		start := span.Key
+		for ; i < len(splitKeys) && splitKeys[i].Compare(span.EndKey) < 0; i++ {
+			res[i] = append(res[i], roachpb.Span{Key: start, EndKey: splitKeys[i]})
+			start = splitKeys[i]
+		}
+		res[i] = append(res[i], roachpb.Span{Key: start, EndKey: span.EndKey})
+	}
+	return res
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestSplitSpansAtKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	sp := func(start, end string) roachpb.Span {
+		res := roachpb.Span{Key: roachpb.Key(start)}
+		if end != "" {
+			res.EndKey = roachpb.Key(end)
+		}
+		return res
+	}
+	keys := func(ks ...string) []roachpb.Key {
+		res := make([]roachpb.Key, len(ks))
+		for i, k := range ks {
+			res[i] = roachpb.Key(k)
+		}
+		return res
+	}
+
+	testCases := []struct {
+		spans     roachpb.Spans
+		splitKeys []roachpb.Key
+		expected  []roachpb.Spans
+	}{
+		{
+			spans:     roachpb.Spans{sp("a", "z")},
+			splitKeys: nil,
+			expected:  []roachpb.Spans{{sp("a", "z")}},
+		},
+		{
+			spans:     roachpb.Spans{sp("a", "z")},
+			splitKeys: keys("d", "m"),
+			expected:  []roachpb.Spans{{sp("a", "d")}, {sp("d", "m")}, {sp("m", "z")}},
+		},
+		{
+			// Split keys outside of the spans.
+			spans:     roachpb.Spans{sp("d", "f")},
+			splitKeys: keys("a", "x"),
+			expected:  []roachpb.Spans{nil, {sp("d", "f")}, nil},
+		},
+		{
+			// Split keys at the boundaries of the spans.
+			spans:     roachpb.Spans{sp("a", "c"), sp("e", "g")},
+			splitKeys: keys("a", "c", "g"),
+			expected:  []roachpb.Spans{nil, {sp("a", "c")}, {sp("e", "g")}, nil},
+		},
+		{
+			// Multiple spans and point spans.
+			spans:     roachpb.Spans{sp("a", ""), sp("b", "e"), sp("m", ""), sp("n", "q")},
+			splitKeys: keys("c", "m", "p"),
+			expected: []roachpb.Spans{
+				{sp("a", ""), sp("b", "c")},
+				{sp("c", "e")},
+				{sp("m", ""), sp("n", "p")},
+				{sp("p", "q")},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		res := splitSpansAtKeys(tc.spans, tc.splitKeys)
+		if !reflect.DeepEqual(res, tc.expected) {
+			t.Errorf("%d: expected %v, got %v", i, tc.expected, res)
+		}
+	}
+}
+
+func TestPickSplitKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	keys := func(ks ...string) []roachpb.Key {
+		res := make([]roachpb.Key, len(ks))
+		for i, k := range ks {
+			res[i] = roachpb.Key(k)
+		}
+		return res
+	}
+	testCases := []struct {
+		boundaries []roachpb.Key
+		degree     int
+		expected   []roachpb.Key
+	}{
+		{boundaries: nil, degree: 4, expected: nil},
+		{boundaries: keys("b", "d"), degree: 4, expected: keys("b", "d")},
+		// Duplicate boundaries of ranges covered by multiple spans.
+		{boundaries: keys("b", "b", "d"), degree: 4, expected: keys("b", "d")},
+		// 8 ranges are divided into 4 groups of 2 ranges.
+		{boundaries: keys("b", "c", "d", "e", "f", "g", "h"), degree: 4, expected: keys("c", "e", "g")},
+		// 6 ranges are divided into groups of 1, 2, 1 and 2 ranges.
+		{boundaries: keys("b", "c", "d", "e", "f"), degree: 4, expected: keys("b", "d", "e")},
+	}
+	for i, tc := range testCases {
+		res := pickSplitKeys(tc.boundaries, tc.degree)
+		if !reflect.DeepEqual(res, tc.expected) {
+			t.Errorf("%d: expected %v, got %v", i, tc.expected, res)
+		}
+	}
+}
@@ -16,12 +16,18 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
 	"github.com/cockroachdb/cockroach/pkg/sql/execinfrapb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/span"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
@@ -86,13 +92,50 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	for i, c := range scan.cols {
 		colIdxMap.Set(c.GetID(), i)
 	}
-	var sb span.Builder
-	sb.Init(planCtx.EvalContext(), planCtx.ExtendedEvalCtx.Codec, desc, scan.index)
-	scan.spans, err = sb.UnconstrainedSpans()
+
+	// The existing stats are used to estimate the expected number of rows and,
+	// for partial statistics, to find the bounds of the full histogram. In the
+	// latter case the cache is invalidated so that the stats are read from the
+	// system table, since a full statistic may have just been created.
+	statsCache := planCtx.ExtendedEvalCtx.ExecCfg.TableStatsCache
+	if details.UsingExtremes {
+		statsCache.InvalidateTableStats(planCtx.ctx, desc.GetID())
+	}
+	tableStats, err := statsCache.GetTableStats(planCtx.ctx, desc)
 	if err != nil {
 		return nil, err
 	}
-	scan.isFull = true
+
+	var fullStat *stats.TableStatistic
+	if details.UsingExtremes {
+		// Only scan the values of the requested column that lie outside the
+		// bounds of its latest full histogram.
+		colID := reqStats[0].columns[0]
+		scan.index = findIndexForExtremes(desc, colID)
+		if scan.index == nil {
+			return nil, errors.AssertionFailedf("no index found for partial statistics on column %d", colID)
+		}
+		if fullStat = findFullStatForExtremes(tableStats, colID); fullStat == nil {
+			col, err := desc.FindColumnWithID(colID)
+			if err != nil {
+				return nil, err
+			}
+			return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"column %q does not have a prerequisite full statistic with a histogram", col.GetName())
+		}
+		scan.spans, err = extremesSpans(planCtx.EvalContext(), planCtx.ExtendedEvalCtx.Codec, desc, scan.index, fullStat)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var sb span.Builder
+		sb.Init(planCtx.EvalContext(), planCtx.ExtendedEvalCtx.Codec, desc, scan.index)
+		scan.spans, err = sb.UnconstrainedSpans()
+		if err != nil {
+			return nil, err
+		}
+		scan.isFull = true
+	}
 
 	p, err := dsp.createTableReaders(planCtx, &scan)
 	if err != nil {
@@ -118,6 +161,9 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 			Columns:             make([]uint32, len(s.columns)),
 			StatName:            s.name,
 		}
+		if fullStat != nil {
+			spec.FullStatisticID = fullStat.StatisticID
+		}
 		for i, colID := range s.columns {
 			colIdx, ok := colIdxMap.Get(colID)
 			if !ok {
@@ -203,14 +249,10 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 		execinfrapb.Ordering{},
 	)
 
-	// Estimate the expected number of rows based on existing stats in the cache.
-	tableStats, err := planCtx.ExtendedEvalCtx.ExecCfg.TableStatsCache.GetTableStats(planCtx.ctx, desc)
-	if err != nil {
-		return nil, err
-	}
-
 	var rowsExpected uint64
-	if len(tableStats) > 0 {
+	// The number of rows outside the bounds of a histogram is unknown, so
+	// there is no estimate for partial statistics.
+	if len(tableStats) > 0 && fullStat == nil {
 		overhead := stats.AutomaticStatisticsFractionStaleRows.Get(&dsp.st.SV)
 		// Convert to a signed integer first to make the linter happy.
 		rowsExpected = uint64(int64(
@@ -247,6 +289,77 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	return p, nil
 }
 
+// findFullStatForExtremes returns the most recent statistic on the given
+// column which has a histogram with at least one non-NULL bucket, or nil if
+// there is none. Forecasts are skipped, since they were not collected from the
+// table.
+func findFullStatForExtremes(
+	tableStats []*stats.TableStatistic, colID descpb.ColumnID,
+) *stats.TableStatistic {
+	for _, stat := range tableStats {
+		if len(stat.ColumnIDs) != 1 || stat.ColumnIDs[0] != colID ||
+			stat.Name == jobspb.ForecastStatsName {
+			continue
+		}
+		for _, b := range stat.Histogram {
+			if b.UpperBound != tree.DNull {
+				return stat
+			}
+		}
+	}
+	return nil
+}
+
+// extremesSpans returns the spans of the given index that contain the non-NULL
+// values of its first key column lying outside the bounds of the histogram of
+// the given statistic.
+func extremesSpans(
+	evalCtx *tree.EvalContext,
+	codec keys.SQLCodec,
+	desc catalog.TableDescriptor,
+	index catalog.Index,
+	fullStat *stats.TableStatistic,
+) (roachpb.Spans, error) {
+	var lowerBound, upperBound tree.Datum
+	for _, b := range fullStat.Histogram {
+		if b.UpperBound == tree.DNull {
+			continue
+		}
+		if lowerBound == nil {
+			lowerBound = b.UpperBound
+		}
+		upperBound = b.UpperBound
+	}
+	nullKey := constraint.MakeKey(tree.DNull)
+	lowerKey, upperKey := constraint.MakeKey(lowerBound), constraint.MakeKey(upperBound)
+
+	// The spans must be ordered by the direction of the index. NULLs sort first
+	// in ascending indexes and last in descending indexes.
+	var lowerSpan, upperSpan constraint.Span
+	var cols constraint.Columns
+	var spans constraint.Spans
+	if index.GetKeyColumnDirection(0) == descpb.IndexDescriptor_ASC {
+		cols.InitSingle(opt.MakeOrderingColumn(1, false /* descending */))
+		lowerSpan.Init(nullKey, constraint.ExcludeBoundary, lowerKey, constraint.ExcludeBoundary)
+		upperSpan.Init(upperKey, constraint.ExcludeBoundary, constraint.EmptyKey, constraint.IncludeBoundary)
+		spans.InitSingleSpan(&lowerSpan)
+		spans.Append(&upperSpan)
+	} else {
+		cols.InitSingle(opt.MakeOrderingColumn(1, true /* descending */))
+		upperSpan.Init(constraint.EmptyKey, constraint.IncludeBoundary, upperKey, constraint.ExcludeBoundary)
+		lowerSpan.Init(lowerKey, constraint.ExcludeBoundary, nullKey, constraint.ExcludeBoundary)
+		spans.InitSingleSpan(&upperSpan)
+		spans.Append(&lowerSpan)
+	}
+	keyCtx := constraint.MakeKeyContext(&cols, evalCtx)
+	var c constraint.Constraint
+	c.Init(&keyCtx, &spans)
+
+	var sb span.Builder
+	sb.Init(evalCtx, codec, desc, index)
+	return sb.SpansFromConstraint(&c, span.NoopSplitter())
+}
+
 func (dsp *DistSQLPlanner) createPlanForCreateStats(
 	planCtx *PlanningCtx, jobID jobspb.JobID, details jobspb.CreateStatsDetails,
 ) (*PhysicalPlan, error) {
@@ -698,3 +698,79 @@ job 100: running schema change refers to missing descriptor(s) [500].
 		require.Equalf(t, test.expected, buf.String(), msg)
 	}
 }
+
+func TestRepair(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	t.Run("namespace", func(t *testing.T) {
+		descTable := doctor.DescriptorTable{
+			{ID: 51, DescBytes: toBytes(t, validTableDesc)},
+			{
+				ID: 52,
+				DescBytes: toBytes(t, &descpb.Descriptor{Union: &descpb.Descriptor_Database{
+					Database: &descpb.DatabaseDescriptor{Name: "db", ID: 52},
+				}}),
+			},
+		}
+		namespaceTable := doctor.NamespaceTable{
+			{NameInfo: descpb.NameInfo{ParentSchemaID: 29, Name: "t"}, ID: 51},
+			{NameInfo: descpb.NameInfo{Name: "db"}, ID: 52},
+		}
+		var buf bytes.Buffer
+		repairs, ok, err := doctor.Repair(context.Background(), clusterversion.TestingClusterVersion,
+			descTable, namespaceTable, nil /* jobsTable */, &buf)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []doctor.RepairStatement{
+			{
+				Problem: `no matching name info found in non-dropped relation "t"`,
+				SQL:     `SELECT crdb_internal.unsafe_delete_namespace_entry(0, 29, 't', 51, true);`,
+			},
+			{
+				Problem: `expected matching namespace entry, found none`,
+				SQL:     `SELECT crdb_internal.unsafe_upsert_namespace_entry(52, 29, 't', 51, true);`,
+			},
+		}, repairs)
+		require.Equal(t, `Repairing 2 descriptors and 2 namespace entries...
+  ParentID   0, ParentSchemaID 29: namespace entry "t" (51): no matching name info found in non-dropped relation "t"
+    repair: SELECT crdb_internal.unsafe_delete_namespace_entry(0, 29, 't', 51, true);
+  ParentID  52, ParentSchemaID 29: relation "t" (51): expected matching namespace entry, found none
+    repair: SELECT crdb_internal.unsafe_upsert_namespace_entry(52, 29, 't', 51, true);
+Generated 2 repair statements.
+Examining 2 descriptors and 2 namespace entries...
+`, buf.String())
+	})
+
+	t.Run("draining names", func(t *testing.T) {
+		descTable := doctor.DescriptorTable{
+			{
+				ID: 52,
+				DescBytes: toBytes(t, &descpb.Descriptor{Union: &descpb.Descriptor_Database{
+					Database: &descpb.DatabaseDescriptor{
+						Name: "db", ID: 52, Version: 1,
+						DrainingNames: []descpb.NameInfo{{Name: "old"}},
+					},
+				}}),
+				ModTime: hlc.Timestamp{WallTime: 1},
+			},
+		}
+		namespaceTable := doctor.NamespaceTable{
+			{NameInfo: descpb.NameInfo{Name: "db"}, ID: 52},
+		}
+		var buf bytes.Buffer
+		repairs, ok, err := doctor.Repair(context.Background(), clusterversion.TestingClusterVersion,
+			descTable, namespaceTable, nil /* jobsTable */, &buf)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Len(t, repairs, 1)
+		require.Equal(t, "expected matching namespace entry for draining name (0, 0, old), found none",
+			repairs[0].Problem)
+		require.Regexp(t,
+			`^SELECT crdb_internal.unsafe_upsert_descriptor\(52, decode\('[0-9a-f]+', 'hex'\), true\);$`,
+			repairs[0].SQL)
+		require.Contains(t, buf.String(),
+			`  ParentID   0, ParentSchemaID  0: database "db" (52): expected matching namespace entry for draining name (0, 0, old), found none
+    repair: SELECT crdb_internal.unsafe_upsert_descriptor(52, decode('`)
+	})
+}
@@ -0,0 +1,214 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package doctor
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
+	"github.com/cockroachdb/cockroach/pkg/sql/lexbase"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// RepairStatement is a SQL statement which repairs a problem found in the
+// system tables.
+type RepairStatement struct {
+	// Problem describes the problem repaired by the statement.
+	Problem string
+	// SQL is the repair statement.
+	SQL string
+}
+
+// Repair runs the descriptor and namespace checks of ExamineDescriptors and
+// returns the statements which repair the problems that can be fixed
+// mechanically, without loss of data:
+//   - namespace entries which don't match their descriptor are deleted,
+//   - non-dropped descriptors without a namespace entry are given one,
+//   - draining names without a matching namespace entry are removed from their
+//     descriptor,
+//   - references to missing or terminal schema change jobs are removed from
+//     table descriptors which have no mutations left for those jobs.
+//
+// Each problem is reported to stdout along with its repair. The repairs are
+// then applied to a copy of the system tables, which is examined again to
+// report the problems left for manual repair. The returned ok is true if no
+// problems are left.
+func Repair(
+	ctx context.Context,
+	version clusterversion.ClusterVersion,
+	descTable DescriptorTable,
+	namespaceTable NamespaceTable,
+	jobsTable JobsTable,
+	stdout io.Writer,
+) (repairs []RepairStatement, ok bool, err error) {
+	fmt.Fprintf(
+		stdout, "Repairing %d descriptors and %d namespace entries...\n",
+		len(descTable), len(namespaceTable))
+	descLookupFn, err := processDescriptorTable(stdout, descTable)
+	if err != nil {
+		return nil, false, err
+	}
+	addRepair := func(report func(string), problem string, sql string) {
+		report(problem)
+		fmt.Fprintf(stdout, "    repair: %s\n", sql)
+		repairs = append(repairs, RepairStatement{Problem: problem, SQL: sql})
+	}
+
+	// Delete the namespace entries which don't match their descriptor.
+	namespace := make(map[descpb.NameInfo]descpb.ID, len(namespaceTable))
+	repairedNamespaceTable := make(NamespaceTable, 0, len(namespaceTable))
+	for _, row := range namespaceTable {
+		var desc catalog.Descriptor
+		if id := descpb.ID(row.ID); id != descpb.InvalidID {
+			if desc = descLookupFn(id); desc != nil && desc.GetID() != id {
+				desc = nil
+			}
+		}
+		if err := validateNamespaceRow(row, desc); err != nil {
+			addRepair(func(msg string) { nsReport(stdout, row, "%s", msg) }, err.Error(), fmt.Sprintf(
+				"SELECT crdb_internal.unsafe_delete_namespace_entry(%d, %d, %s, %d, true);",
+				row.ParentID, row.ParentSchemaID, lexbase.EscapeSQLString(row.Name), row.ID))
+			continue
+		}
+		namespace[row.NameInfo] = descpb.ID(row.ID)
+		repairedNamespaceTable = append(repairedNamespaceTable, row)
+	}
+
+	repairedDescTable := make(DescriptorTable, 0, len(descTable))
+	for _, row := range descTable {
+		id := descpb.ID(row.ID)
+		desc := descLookupFn(id)
+		if desc == nil || desc.GetID() != id || catalog.IsSystemDescriptor(desc) {
+			repairedDescTable = append(repairedDescTable, row)
+			continue
+		}
+		report := func(msg string) { descReport(stdout, desc, "%s", msg) }
+
+		// Give non-dropped descriptors a namespace entry, unless their name is
+		// taken by another descriptor, which requires a manual repair.
+		key := descpb.NameInfo{
+			ParentID:       desc.GetParentID(),
+			ParentSchemaID: desc.GetParentSchemaID(),
+			Name:           desc.GetName(),
+		}
+		if _, found := namespace[key]; !found && !desc.Dropped() {
+			namespace[key] = id
+			repairedNamespaceTable = append(repairedNamespaceTable, NamespaceTableRow{NameInfo: key, ID: row.ID})
+			addRepair(report, "expected matching namespace entry, found none", fmt.Sprintf(
+				"SELECT crdb_internal.unsafe_upsert_namespace_entry(%d, %d, %s, %d, true);",
+				key.ParentID, key.ParentSchemaID, lexbase.EscapeSQLString(key.Name), id))
+		}
+
+		mut, problems, err := repairDescriptor(row, namespace, jobsTable)
+		if err != nil {
+			return nil, false, err
+		}
+		if mut == nil {
+			repairedDescTable = append(repairedDescTable, row)
+			continue
+		}
+		mut.MaybeIncrementVersion()
+		descBytes, err := protoutil.Marshal(mut.DescriptorProto())
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to marshal repaired descriptor %d", id)
+		}
+		for i, problem := range problems {
+			if i < len(problems)-1 {
+				report(problem)
+				continue
+			}
+			addRepair(report, problem, fmt.Sprintf(
+				"SELECT crdb_internal.unsafe_upsert_descriptor(%d, decode('%s', 'hex'), true);",
+				id, hex.EncodeToString(descBytes)))
+		}
+		repairedDescTable = append(repairedDescTable, DescriptorTableRow{
+			ID: row.ID, DescBytes: descBytes, ModTime: row.ModTime,
+		})
+	}
+	fmt.Fprintf(stdout, "Generated %d repair statements.\n", len(repairs))
+
+	// Examine the repaired system tables to report the problems which were not
+	// repaired.
+	ok, err = ExamineDescriptors(
+		ctx, version, repairedDescTable, repairedNamespaceTable, jobsTable, false /* verbose */, stdout)
+	return repairs, ok, err
+}
+
+// repairDescriptor returns a mutable copy of the descriptor with the problems
+// that can be repaired in place fixed, along with the problems, or nil if it
+// has none.
+func repairDescriptor(
+	row DescriptorTableRow, namespace map[descpb.NameInfo]descpb.ID, jobsTable JobsTable,
+) (catalog.MutableDescriptor, []string, error) {
+	var descProto descpb.Descriptor
+	if err := protoutil.Unmarshal(row.DescBytes, &descProto); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to unmarshal descriptor %d", row.ID)
+	}
+	b := descbuilder.NewBuilderWithMVCCTimestamp(&descProto, row.ModTime)
+	if b == nil {
+		return nil, nil, nil
+	}
+	b.RunPostDeserializationChanges()
+	mut := b.BuildExistingMutable()
+	id := descpb.ID(row.ID)
+
+	var problems []string
+	if drainingNames := mut.GetDrainingNames(); len(drainingNames) > 0 {
+		kept := make([]descpb.NameInfo, 0, len(drainingNames))
+		for _, dn := range drainingNames {
+			if namespace[dn] == id {
+				kept = append(kept, dn)
+				continue
+			}
+			problems = append(problems, fmt.Sprintf(
+				"expected matching namespace entry for draining name (%d, %d, %s), found none",
+				dn.ParentID, dn.ParentSchemaID, dn.Name))
+		}
+		if len(kept) < len(drainingNames) {
+			mut.SetDrainingNames(kept)
+		}
+	}
+
+	if tbl, ok := mut.(*tabledesc.Mutable); ok && len(tbl.MutationJobs) > 0 {
+		pending := make(map[descpb.MutationID]bool, len(tbl.Mutations))
+		for _, m := range tbl.Mutations {
+			pending[m.MutationID] = true
+		}
+		kept := make([]descpb.TableDescriptor_MutationJob, 0, len(tbl.MutationJobs))
+		for _, mj := range tbl.MutationJobs {
+			if pending[mj.MutationID] {
+				kept = append(kept, mj)
+				continue
+			}
+			if j, err := jobsTable.GetJobMetadata(mj.JobID); err != nil {
+				problems = append(problems, fmt.Sprintf("mutation job %d not found in system.jobs", mj.JobID))
+			} else if j.Status.Terminal() {
+				problems = append(problems, fmt.Sprintf("mutation job %d has terminal status (%s)", mj.JobID, j.Status))
+			} else {
+				kept = append(kept, mj)
+			}
+		}
+		tbl.MutationJobs = kept
+	}
+
+	if len(problems) == 0 {
+		return nil, nil, nil
+	}
+	return mut, problems, nil
+}
@@ -323,6 +323,14 @@ func (p *planner) dropIndexByName(
 		)
 	}
 
+	if idx.IsExclusion() && behavior != tree.DropCascade && constraintBehavior != ignoreIdxConstraint {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.DependentObjectsStillExist,
+				"index %q is in use as exclusion constraint", idx.GetName()),
+			"use CASCADE if you really want to drop it.",
+		)
+	}
+
 	// Check if requires CCL binary for eventual zone config removal.
 	_, zone, _, err := GetZoneConfigInTxn(
 		ctx, p.txn, p.ExecCfg().Codec, tableDesc.ID, nil /* index */, "", false,
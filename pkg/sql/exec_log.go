@@ -154,9 +154,10 @@ func (p *planner) maybeLogStatement(
 	err error,
 	queryReceived time.Time,
 	hasAdminRoleCache *HasAdminRoleCache,
+	auditRoles *auditRoleCache,
 	telemetryLoggingMetrics *TelemetryLoggingMetrics,
 ) {
-	p.maybeLogStatementInternal(ctx, execType, numRetries, txnCounter, rows, err, queryReceived, hasAdminRoleCache, telemetryLoggingMetrics)
+	p.maybeLogStatementInternal(ctx, execType, numRetries, txnCounter, rows, err, queryReceived, hasAdminRoleCache, auditRoles, telemetryLoggingMetrics)
 }
 
 func (p *planner) maybeLogStatementInternal(
@@ -166,6 +167,7 @@ func (p *planner) maybeLogStatementInternal(
 	err error,
 	startTime time.Time,
 	hasAdminRoleCache *HasAdminRoleCache,
+	auditRoles *auditRoleCache,
 	telemetryMetrics *TelemetryLoggingMetrics,
 ) {
 	// Note: if you find the code below crashing because p.execCfg == nil,
@@ -192,8 +194,14 @@ func (p *planner) maybeLogStatementInternal(
 	// a user and the user has admin privilege (is directly or indirectly a
 	// member of the admin role).
 
+	// We only evaluate the audit rules for non-internal SQL statements.
+	var configuredAuditRules []auditRule
+	if execType != executorTypeInternal {
+		configuredAuditRules = getAuditRules(&p.execCfg.Settings.SV).rules
+	}
+
 	if !logV && !logExecuteEnabled && !auditEventsDetected && !slowQueryLogEnabled &&
-		!shouldLogToAdminAuditLog && !telemetryLoggingEnabled {
+		!shouldLogToAdminAuditLog && !telemetryLoggingEnabled && len(configuredAuditRules) == 0 {
 		// Shortcut: avoid the expense of computing anything log-related
 		// if logging is not enabled by configuration.
 		return
@@ -375,6 +383,10 @@ func (p *planner) maybeLogStatementInternal(
 		p.logEventsOnlyExternally(ctx, eventLogEntry{event: &eventpb.AdminQuery{CommonSQLExecDetails: execDetails}})
 	}
 
+	if len(configuredAuditRules) != 0 {
+		p.logAuditRuleMatches(ctx, configuredAuditRules, auditRoles, execDetails)
+	}
+
 	if telemetryLoggingEnabled {
 		// We only log to the telemetry channel if enough time has elapsed from
 		// the last event emission.
@@ -417,17 +429,18 @@ func (p *planner) logEventsOnlyExternally(ctx context.Context, entries ...eventL
 // contributors who later add features do not have to remember to call
 // this to get it right.
 func (p *planner) maybeAudit(desc catalog.Descriptor, priv privilege.Kind) {
+	writing := false
+	switch priv {
+	case privilege.INSERT, privilege.DELETE, privilege.UPDATE:
+		writing = true
+	}
+	p.maybeRecordAccessForAuditRules(desc, writing)
+
 	wantedMode := desc.GetAuditMode()
 	if wantedMode == descpb.TableDescriptor_DISABLED {
 		return
 	}
-
-	switch priv {
-	case privilege.INSERT, privilege.DELETE, privilege.UPDATE:
-		p.curPlan.auditEvents = append(p.curPlan.auditEvents, auditEvent{desc: desc, writing: true})
-	default:
-		p.curPlan.auditEvents = append(p.curPlan.auditEvents, auditEvent{desc: desc, writing: false})
-	}
+	p.curPlan.auditEvents = append(p.curPlan.auditEvents, auditEvent{desc: desc, writing: writing})
 }
 
 func (p *planner) slowQueryLogReason(
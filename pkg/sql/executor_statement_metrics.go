@@ -167,6 +167,13 @@ func (ex *connExecutor) recordStatementSummary(
 		PlanHash:     planner.instrumentation.planGist.Hash(),
 	}
 
+	// The pprof labels of the statement carry the fingerprint ID of the
+	// statement as if it succeeded, so failures are recorded for their CPU
+	// usage to be attributed to the fingerprint ID of the failed statement.
+	if stmtErr != nil {
+		ex.server.cfg.StatementCPUUsage.recordFailedExecution(ctx, recordedStmtStatsKey.FingerprintID())
+	}
+
 	// We only populate the transaction fingerprint ID field if we are in an
 	// implicit transaction.
 	//
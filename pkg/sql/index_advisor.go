@@ -0,0 +1,408 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/catconstants"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/indexrec"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/scanner"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondatapb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+var indexAdvisorEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.index_advisor.enabled",
+	"if set, the statement fingerprints of the workload are periodically analyzed "+
+		"for indexes that would reduce their cost; see crdb_internal.index_recommendations",
+	true,
+).WithPublic()
+
+var indexAdvisorInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.index_advisor.interval",
+	"the interval at which the workload is analyzed for index recommendations",
+	time.Hour,
+	settings.PositiveDuration,
+)
+
+var indexAdvisorMaxStatements = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.index_advisor.max_statements",
+	"the number of statement fingerprints with the highest total latency, and the number "+
+		"of statement fingerprints with the most rows written, analyzed by the index advisor",
+	50,
+	settings.PositiveInt,
+)
+
+var indexAdvisorLookback = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.index_advisor.lookback",
+	"the window of persisted statement statistics analyzed by the index advisor",
+	24*time.Hour,
+	settings.PositiveDuration,
+)
+
+var unusedIndexThreshold = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.index_advisor.unused_index_threshold",
+	"the duration for which a secondary index must not have been read before "+
+		"it is recommended to be dropped",
+	7*24*time.Hour,
+	settings.PositiveDuration,
+).WithPublic()
+
+// indexRowWriteCost is the estimated cost of writing a row to an index, in the
+// units of the optimizer's cost model. It is the cost the optimizer assigns to
+// a random I/O.
+const indexRowWriteCost = 4
+
+// IndexAdvisor periodically runs the index recommendation engine over the
+// most expensive statement fingerprints in the statement statistics persisted
+// by all the nodes of the cluster. It merges the recommendations of all the
+// statements, and keeps the ones whose estimated savings for the workload
+// exceed the estimated cost of maintaining the index for the writes of the
+// workload.
+type IndexAdvisor struct {
+	execCfg *ExecutorConfig
+
+	mu struct {
+		syncutil.Mutex
+		recs []workloadIndexRecommendation
+	}
+}
+
+// workloadIndexRecommendation is an index recommendation for the workload as a
+// whole.
+type workloadIndexRecommendation struct {
+	indexrec.Recommendation
+	// fingerprintCount is the number of statement fingerprints whose plans use
+	// the recommended index.
+	fingerprintCount int
+	// savings is the estimated reduction of the cost of the workload.
+	savings float64
+	// writeCost is the estimated cost of maintaining the index for the writes
+	// of the workload.
+	writeCost float64
+}
+
+// advisorStmt contains the statistics of a statement fingerprint that are
+// used by the IndexAdvisor.
+type advisorStmt struct {
+	query    string
+	database string
+	count    int64
+	// latency is the total service latency of the fingerprint, in seconds.
+	latency float64
+	// rowsWritten is the total number of rows written by the fingerprint.
+	rowsWritten float64
+}
+
+// NewIndexAdvisor creates an IndexAdvisor.
+func NewIndexAdvisor(execCfg *ExecutorConfig) *IndexAdvisor {
+	return &IndexAdvisor{execCfg: execCfg}
+}
+
+// Start starts the background task of the IndexAdvisor.
+func (a *IndexAdvisor) Start(ctx context.Context, stopper *stop.Stopper) {
+	_ = stopper.RunAsyncTask(ctx, "index-advisor", func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+		timer := timeutil.NewTimer()
+		defer timer.Stop()
+		for {
+			timer.Reset(indexAdvisorInterval.Get(&a.execCfg.Settings.SV))
+			select {
+			case <-timer.C:
+				timer.Read = true
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+			if !indexAdvisorEnabled.Get(&a.execCfg.Settings.SV) {
+				continue
+			}
+			if err := a.run(ctx); err != nil {
+				log.Warningf(ctx, "failed to compute workload index recommendations: %s", err)
+			}
+		}
+	})
+}
+
+// Recommendations returns the index recommendations computed by the last run
+// of the IndexAdvisor, ordered by decreasing net savings.
+func (a *IndexAdvisor) Recommendations() []workloadIndexRecommendation {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.mu.recs
+}
+
+func (a *IndexAdvisor) run(ctx context.Context) error {
+	stmts, err := a.collectStmts(ctx)
+	if err != nil {
+		return err
+	}
+	stmts = topAdvisorStmts(stmts, int(indexAdvisorMaxStatements.Get(&a.execCfg.Settings.SV)))
+
+	var merged indexRecMerger
+	for _, s := range stmts {
+		res, err := a.adviseStmt(ctx, s)
+		if err != nil {
+			// Not all fingerprints can be turned back into a statement that can be
+			// planned, for example if the type of a hidden constant cannot be
+			// inferred.
+			log.VEventf(ctx, 2, "skipping index recommendations for %q: %s", s.query, err)
+			continue
+		}
+		merged.add(s, res)
+	}
+	recs := merged.finish(nil /* results */)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mu.recs = recs
+	return nil
+}
+
+// collectStmts collects the statistics of the statement fingerprints in
+// system.statement_statistics that were aggregated within the lookback window,
+// excluding failed and internal statements. Statistics that were not flushed
+// yet are not included.
+func (a *IndexAdvisor) collectStmts(ctx context.Context) (_ []*advisorStmt, retErr error) {
+	const query = `
+SELECT
+  metadata->>'query',
+  metadata->>'db',
+  sum((statistics->'statistics'->>'cnt')::INT8)::INT8,
+  COALESCE(sum(
+    (statistics->'statistics'->'svcLat'->>'mean')::FLOAT8 * (statistics->'statistics'->>'cnt')::FLOAT8
+  ), 0),
+  COALESCE(sum(
+    (statistics->'statistics'->'rowsWritten'->>'mean')::FLOAT8 * (statistics->'statistics'->>'cnt')::FLOAT8
+  ), 0)
+FROM system.statement_statistics
+WHERE aggregated_ts >= $1
+  AND app_name NOT LIKE $2
+  AND NOT (metadata->>'failed')::BOOL
+GROUP BY fingerprint_id, metadata->>'query', metadata->>'db'`
+	cutoff := timeutil.Now().Add(-indexAdvisorLookback.Get(&a.execCfg.Settings.SV))
+	it, err := a.execCfg.InternalExecutor.QueryIteratorEx(
+		ctx, "index-advisor-stmts", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.NodeUserName()},
+		query, cutoff, catconstants.InternalAppNamePrefix+"%",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { retErr = errors.CombineErrors(retErr, it.Close()) }()
+
+	var stmts []*advisorStmt
+	var ok bool
+	for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+		row := it.Cur()
+		stmts = append(stmts, &advisorStmt{
+			query:       string(tree.MustBeDString(row[0])),
+			database:    string(tree.MustBeDString(row[1])),
+			count:       int64(tree.MustBeDInt(row[2])),
+			latency:     float64(tree.MustBeDFloat(row[3])),
+			rowsWritten: float64(tree.MustBeDFloat(row[4])),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stmts, nil
+}
+
+// topAdvisorStmts returns the n statements with the highest total latency,
+// along with up to n of the remaining statements that wrote the most rows.
+func topAdvisorStmts(stmts []*advisorStmt, n int) []*advisorStmt {
+	if len(stmts) <= n {
+		return stmts
+	}
+	sort.Slice(stmts, func(i, j int) bool {
+		return stmts[i].latency > stmts[j].latency
+	})
+	top := stmts[:n:n]
+	rest := append([]*advisorStmt(nil), stmts[n:]...)
+	sort.Slice(rest, func(i, j int) bool {
+		return rest[i].rowsWritten > rest[j].rowsWritten
+	})
+	for i := 0; i < len(rest) && i < n && rest[i].rowsWritten > 0; i++ {
+		top = append(top, rest[i])
+	}
+	return top
+}
+
+// adviseStmt plans the statement of the given fingerprint, with the hidden
+// constants replaced by placeholders, and returns its index recommendations.
+func (a *IndexAdvisor) adviseStmt(
+	ctx context.Context, s *advisorStmt,
+) (res stmtIndexRecommendations, err error) {
+	sql, numPlaceholders, err := scanner.ReplaceHiddenConstants(s.query)
+	if err != nil {
+		return res, err
+	}
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return res, err
+	}
+	switch stmt.AST.(type) {
+	case *tree.Select, *tree.Insert, *tree.Update, *tree.Delete, *tree.Merge:
+	default:
+		return res, nil
+	}
+
+	err = a.execCfg.DB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		ip, cleanup := NewInternalPlanner(
+			"index-advisor",
+			txn,
+			security.RootUserName(),
+			&MemoryMetrics{},
+			a.execCfg,
+			sessiondatapb.SessionData{},
+		)
+		defer cleanup()
+		p := ip.(*planner)
+		p.SessionData().Database = s.database
+		p.SessionData().ReorderJoinsLimit = opt.DefaultJoinOrderLimit
+		if err := p.semaCtx.Placeholders.Init(numPlaceholders, nil /* typeHints */); err != nil {
+			return err
+		}
+		p.stmt = makeStatement(stmt, ClusterWideID{} /* queryID */)
+		p.runWithOptions(resolveFlags{skipCache: true}, func() {
+			res, err = p.optPlanningCtx.makeWorkloadIndexRecommendation(ctx)
+		})
+		return err
+	})
+	return res, err
+}
+
+// indexRecMerger merges the index recommendations of the statements of the
+// workload.
+type indexRecMerger struct {
+	recs []*workloadIndexRecommendation
+	// byKey indexes recs by the table and the key columns of the index.
+	byKey map[string]*workloadIndexRecommendation
+	// writes is the number of rows written to each table.
+	writes map[cat.StableID]float64
+}
+
+// add merges the recommendations for the given statement. The estimated
+// savings of the statement are split evenly between its recommendations.
+func (m *indexRecMerger) add(s *advisorStmt, res stmtIndexRecommendations) {
+	if m.byKey == nil {
+		m.byKey = make(map[string]*workloadIndexRecommendation)
+		m.writes = make(map[cat.StableID]float64)
+	}
+	for _, id := range res.mutatedTables {
+		m.writes[id] += s.rowsWritten
+	}
+	if len(res.recs) == 0 {
+		return
+	}
+	savings := float64(res.cost-res.costWithRecs) * float64(s.count)
+	if savings <= 0 {
+		return
+	}
+	savings /= float64(len(res.recs))
+	for i := range res.recs {
+		rec := &res.recs[i]
+		key := indexRecKey(rec)
+		merged, ok := m.byKey[key]
+		if !ok {
+			merged = &workloadIndexRecommendation{Recommendation: *rec}
+			merged.Storing = nil
+			m.byKey[key] = merged
+			m.recs = append(m.recs, merged)
+		}
+		for _, col := range rec.Storing {
+			if !containsName(merged.Storing, col) {
+				merged.Storing = append(merged.Storing, col)
+			}
+		}
+		merged.fingerprintCount++
+		merged.savings += savings
+	}
+}
+
+// finish appends the merged recommendations whose savings exceed their write
+// cost to results, ordered by decreasing net savings.
+func (m *indexRecMerger) finish(
+	results []workloadIndexRecommendation,
+) []workloadIndexRecommendation {
+	for _, rec := range m.recs {
+		// An index replacement does not add an index to maintain, so its write
+		// cost is only the cost of maintaining the new stored columns, which is
+		// ignored.
+		if rec.ExistingIndex == "" {
+			rec.writeCost = m.writes[rec.TableID] * indexRowWriteCost
+		}
+		if rec.savings > rec.writeCost {
+			results = append(results, *rec)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].savings-results[i].writeCost > results[j].savings-results[j].writeCost
+	})
+	return results
+}
+
+// indexRecKey returns a key that identifies the recommended index by its table
+// and key columns, ignoring its stored columns.
+func indexRecKey(rec *indexrec.Recommendation) string {
+	keyRec := *rec
+	keyRec.Storing = nil
+	return fmt.Sprintf("%d/%s", rec.TableID, keyRec.SQL())
+}
+
+func containsName(names []tree.Name, name tree.Name) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnusedIndex returns whether the given secondary index has not been read
+// for at least the given threshold, and can therefore be dropped. Unique
+// indexes are never considered unused, since they enforce a constraint.
+//
+// Index usage statistics are kept in memory, so an index that was last read
+// before a node restart can be considered unused.
+func isUnusedIndex(idx catalog.Index, lastRead, now time.Time, threshold time.Duration) bool {
+	if idx.Primary() || idx.IsUnique() {
+		return false
+	}
+	cutoff := now.Add(-threshold)
+	if createdAt := idx.CreatedAt(); !createdAt.IsZero() && createdAt.After(cutoff) {
+		return false
+	}
+	return lastRead.Before(cutoff)
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/indexrec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/persistedsqlstats"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopAdvisorStmts(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	stmts := []*advisorStmt{
+		{query: "a", latency: 1},
+		{query: "b", latency: 5},
+		{query: "c", latency: 2, rowsWritten: 10},
+		{query: "d", latency: 0.5, rowsWritten: 100},
+		{query: "e", latency: 0.1},
+	}
+	var queries []string
+	for _, s := range topAdvisorStmts(stmts, 2) {
+		queries = append(queries, s.query)
+	}
+	// The two statements with the highest latency, followed by the remaining
+	// statements that wrote rows.
+	require.Equal(t, []string{"b", "c", "d"}, queries)
+}
+
+func TestIndexRecMerger(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	const tabID, otherTabID = cat.StableID(52), cat.StableID(53)
+	rec := func(tab cat.StableID, col string, storing ...tree.Name) indexrec.Recommendation {
+		return indexrec.Recommendation{
+			TableID:   tab,
+			TableName: tree.MakeUnqualifiedTableName("t"),
+			Columns:   []tree.IndexElem{{Column: tree.Name(col)}},
+			Storing:   storing,
+		}
+	}
+
+	var m indexRecMerger
+	// Two statements benefit from an index on t(a), with different stored
+	// columns.
+	m.add(&advisorStmt{count: 10}, stmtIndexRecommendations{
+		recs: []indexrec.Recommendation{rec(tabID, "a", "b")},
+		cost: 100, costWithRecs: 10,
+	})
+	m.add(&advisorStmt{count: 1}, stmtIndexRecommendations{
+		recs: []indexrec.Recommendation{rec(tabID, "a", "c"), rec(otherTabID, "x")},
+		cost: 100, costWithRecs: 80,
+	})
+	// Writes to the other table outweigh the savings of its index.
+	m.add(&advisorStmt{count: 100, rowsWritten: 100}, stmtIndexRecommendations{
+		cost: 1, costWithRecs: 1, mutatedTables: []cat.StableID{otherTabID},
+	})
+
+	recs := m.finish(nil /* results */)
+	require.Len(t, recs, 1)
+	require.Equal(t, tabID, recs[0].TableID)
+	require.Equal(t, "CREATE INDEX ON t (a) STORING (b, c);", recs[0].SQL())
+	require.Equal(t, 2, recs[0].fingerprintCount)
+	require.Equal(t, float64(910), recs[0].savings)
+	require.Equal(t, float64(0), recs[0].writeCost)
+}
+
+// TestIndexAdvisorPersistedStats checks that the IndexAdvisor analyzes the
+// persisted statement statistics, and recommends indexes on fully qualified
+// tables.
+func TestIndexAdvisorPersistedStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	sqlDB.Exec(t, `CREATE TABLE t (a INT PRIMARY KEY, b INT)`)
+	for i := 0; i < 3; i++ {
+		sqlDB.Exec(t, `SELECT * FROM t WHERE b = $1`, i)
+	}
+	const query = `SELECT * FROM t WHERE b = $1`
+
+	execCfg := s.ExecutorConfig().(ExecutorConfig)
+	advisor := NewIndexAdvisor(&execCfg)
+	findStmt := func() *advisorStmt {
+		stmts, err := advisor.collectStmts(ctx)
+		require.NoError(t, err)
+		for _, stmt := range stmts {
+			if stmt.query == query {
+				return stmt
+			}
+		}
+		return nil
+	}
+	// The statistics that were not flushed yet are not analyzed.
+	require.Nil(t, findStmt())
+
+	s.SQLServer().(*Server).GetSQLStatsProvider().(*persistedsqlstats.PersistedSQLStats).Flush(ctx)
+	stmt := findStmt()
+	require.NotNil(t, stmt)
+	require.Equal(t, "defaultdb", stmt.database)
+	require.Equal(t, int64(3), stmt.count)
+
+	res, err := advisor.adviseStmt(ctx, stmt)
+	require.NoError(t, err)
+	require.Len(t, res.recs, 1)
+	require.Equal(t, "CREATE INDEX ON defaultdb.public.t (b);", res.recs[0].SQL())
+}
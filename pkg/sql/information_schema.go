@@ -1271,6 +1271,11 @@ https://www.postgresql.org/docs/9.5/infoschema-table-constraints.html`,
 				tbNameStr := tree.NewDString(table.GetName())
 
 				for conName, c := range conInfo {
+					// Like Postgres, exclude exclusion constraints, which are not
+					// defined by the SQL standard.
+					if c.Kind == descpb.ConstraintTypeExclusion {
+						continue
+					}
 					if err := addRow(
 						dbNameStr,                       // constraint_catalog
 						scNameStr,                       // constraint_schema
@@ -323,8 +323,8 @@ func (ih *instrumentationHelper) Finish(
 	if ih.collectBundle {
 		ie := p.extendedEvalCtx.ExecCfg.InternalExecutor
 		phaseTimes := statsCollector.PhaseTimes()
-		if ih.stmtDiagnosticsRecorder.IsExecLatencyConditionMet(
-			ih.diagRequestID, ih.diagRequest, phaseTimes.GetServiceLatencyNoOverhead(),
+		if ih.stmtDiagnosticsRecorder.IsConditionMet(
+			ih.diagRequestID, ih.diagRequest, phaseTimes.GetServiceLatencyNoOverhead(), ih.planGist.String(),
 		) {
 			placeholders := p.extendedEvalCtx.Placeholders
 			ob := ih.emitExplainAnalyzePlanToOutputBuilder(
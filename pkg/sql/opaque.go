@@ -113,6 +113,8 @@ func planOpaque(ctx context.Context, p *planner, stmt tree.Statement) (planNode,
 		return p.AlterRoleSet(ctx, n)
 	case *tree.AlterSequence:
 		return p.AlterSequence(ctx, n)
+	case *tree.AlterStatementFingerprint:
+		return p.AlterStatementFingerprint(ctx, n)
 	case *tree.CloseCursor:
 		return p.CloseCursor(ctx, n)
 	case *tree.CommentOnColumn:
@@ -255,6 +257,7 @@ func init() {
 		&tree.AlterSequence{},
 		&tree.AlterRole{},
 		&tree.AlterRoleSet{},
+		&tree.AlterStatementFingerprint{},
 		&tree.CloseCursor{},
 		&tree.CommentOnColumn{},
 		&tree.CommentOnDatabase{},
@@ -313,6 +316,7 @@ func init() {
 
 		// CCL statements (without Export which has an optimizer operator).
 		&tree.AlterBackup{},
+		&tree.DropBackups{},
 		&tree.Backup{},
 		&tree.ShowBackup{},
 		&tree.Restore{},
@@ -134,6 +134,14 @@ type Table interface {
 	// i < UniqueCount.
 	Unique(i UniqueOrdinal) UniqueConstraint
 
+	// ExclusionConstraintCount returns the number of exclusion constraints
+	// defined on this table.
+	ExclusionConstraintCount() int
+
+	// ExclusionConstraint returns the ith exclusion constraint defined on this
+	// table, where i < ExclusionConstraintCount.
+	ExclusionConstraint(i int) ExclusionConstraint
+
 	// Zone returns a table's zone.
 	Zone() Zone
 }
@@ -306,3 +314,53 @@ type UniqueOrdinal = int
 // UniqueOrdinals identifies a list of unique constraints (in the context of
 // a Table).
 type UniqueOrdinals = []UniqueOrdinal
+
+// ExclusionOperator is the operator with which an element of an exclusion
+// constraint is compared between two rows.
+type ExclusionOperator uint8
+
+const (
+	// ExclusionEq compares a single column with =.
+	ExclusionEq ExclusionOperator = iota
+
+	// ExclusionOverlaps compares a single GEOMETRY or GEOGRAPHY column with &&,
+	// which is true if the shapes intersect. It also compares a pair of columns
+	// which form the half-open range [start, end), where a NULL bound is
+	// unbounded, with the same columns of the other row.
+	ExclusionOverlaps
+)
+
+// ExclusionConstraint represents an exclusion constraint, which guarantees
+// that no two rows of a table conflict on all of the elements of the
+// constraint. For example, the following constraint ensures that no two
+// bookings of the same room overlap in time:
+//   CREATE TABLE bookings (
+//     room INT, start TIMESTAMPTZ, finish TIMESTAMPTZ,
+//     EXCLUDE USING gist (room WITH =, tstzrange(start, finish) WITH &&)
+//   )
+// Like a unique constraint without an index, the optimizer enforces an
+// exclusion constraint by adding a check as a postquery to any query that
+// inserts into or updates the columns of the constraint.
+type ExclusionConstraint interface {
+	// Name of the exclusion constraint.
+	Name() string
+
+	// ElementCount returns the number of elements in this constraint.
+	ElementCount() int
+
+	// ElementOperator returns the operator of the ith element.
+	ElementOperator(i int) ExclusionOperator
+
+	// ElementColumnCount returns the number of columns in the ith element. It is
+	// 2 for a range of two columns and 1 otherwise.
+	ElementColumnCount(i int) int
+
+	// ElementColumnOrdinal returns the table column ordinal of the jth column of
+	// the ith element.
+	ElementColumnOrdinal(tab Table, i, j int) int
+
+	// Predicate returns the partial predicate expression and true if the
+	// constraint is a partial exclusion constraint. If it is not, the empty
+	// string and false are returned.
+	Predicate() (string, bool)
+}
@@ -212,6 +212,62 @@ func DecodePlanGistToPlan(s string, cat cat.Catalog) (plan *Plan, retErr error)
 	return plan, nil
 }
 
+// DecodePlanGistToIndexes decodes a gist and returns the index used to access
+// each table in the plan, keyed by table ID. Tables that are accessed through
+// more than one index in the plan, or that can no longer be resolved, are
+// omitted.
+func DecodePlanGistToIndexes(
+	gist string, catalog cat.Catalog,
+) (map[cat.StableID]cat.StableID, error) {
+	plan, err := DecodePlanGistToPlan(gist, catalog)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make(map[cat.StableID]cat.StableID)
+	ambiguous := make(map[cat.StableID]struct{})
+	add := func(table cat.Table, index cat.Index) {
+		if table == nil || index == nil {
+			return
+		}
+		if _, ok := ambiguous[table.ID()]; ok {
+			return
+		}
+		if prev, ok := indexes[table.ID()]; ok && prev != index.ID() {
+			delete(indexes, table.ID())
+			ambiguous[table.ID()] = struct{}{}
+			return
+		}
+		indexes[table.ID()] = index.ID()
+	}
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		if n == nil {
+			return
+		}
+		switch a := n.args.(type) {
+		case *scanArgs:
+			add(a.Table, a.Index)
+		case *lookupJoinArgs:
+			add(a.Table, a.Index)
+		case *invertedJoinArgs:
+			add(a.Table, a.Index)
+		}
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(plan.Root)
+	for i := range plan.Subqueries {
+		if n, ok := plan.Subqueries[i].Root.(*Node); ok {
+			walk(n)
+		}
+	}
+	for _, n := range plan.Checks {
+		walk(n)
+	}
+	return indexes, nil
+}
+
 func (f *PlanGistFactory) decodeOp() execOperator {
 	val, err := f.buffer.ReadByte()
 	if err != nil || val == 0 {
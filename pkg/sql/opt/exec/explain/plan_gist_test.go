@@ -12,6 +12,7 @@ package explain_test
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
@@ -20,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/opttester"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/testcat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/datadriven"
 )
@@ -146,3 +148,54 @@ func TestPlanGistHashEquivalency(t *testing.T) {
 		t.Errorf("gists should be different! %s == %s", gist1.String(), gist2.String())
 	}
 }
+
+func TestDecodePlanGistToIndexes(t *testing.T) {
+	catalog := testcat.New()
+	if _, err := catalog.ExecuteDDL(
+		"CREATE TABLE abc (a INT PRIMARY KEY, b INT, c INT, INDEX b_idx (b), INDEX c_idx (c))",
+	); err != nil {
+		t.Fatal(err)
+	}
+	tab := catalog.Table(tree.NewUnqualifiedTableName("abc"))
+	indexID := func(name tree.Name) cat.StableID {
+		for i := 0; i < tab.IndexCount(); i++ {
+			if tab.Index(i).Name() == name {
+				return tab.Index(i).ID()
+			}
+		}
+		t.Fatalf("index %s not found", name)
+		return 0
+	}
+
+	testCases := []struct {
+		query    string
+		expected map[cat.StableID]cat.StableID
+	}{
+		{
+			query:    "SELECT * FROM abc WHERE a = 1",
+			expected: map[cat.StableID]cat.StableID{tab.ID(): indexID("abc_pkey")},
+		},
+		{
+			query:    "SELECT a FROM abc WHERE b = 1",
+			expected: map[cat.StableID]cat.StableID{tab.ID(): indexID("b_idx")},
+		},
+		{
+			// The table is accessed through two different indexes, so it is
+			// omitted.
+			query:    "SELECT a FROM abc WHERE b = 1 UNION SELECT a FROM abc WHERE c = 1",
+			expected: map[cat.StableID]cat.StableID{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			gist := makeGist(opttester.New(catalog, tc.query), t)
+			indexes, err := explain.DecodePlanGistToIndexes(gist.String(), catalog)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.expected, indexes) {
+				t.Errorf("expected %v, got %v", tc.expected, indexes)
+			}
+		})
+	}
+}
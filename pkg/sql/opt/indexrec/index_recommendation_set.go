@@ -171,11 +171,8 @@ func (irs *IndexRecommendationSet) Output() []string {
 			if indexRec.existingIndex != nil {
 				recTypeStr = "index replacement"
 			}
-			indexCols := indexRec.indexCols()
-			storing := indexRec.storingColumns()
-
 			indexRecType := fmt.Sprintf("%d. type: %s", indexRecOrd, recTypeStr)
-			indexRecSQL := indexRec.indexRecommendationString(indexCols, storing)
+			indexRecSQL := indexRec.indexRecommendationString()
 			output = append(output, indexRecType, indexRecSQL)
 			indexRecOrd++
 		}
@@ -183,6 +180,69 @@ func (irs *IndexRecommendationSet) Output() []string {
 	return output
 }
 
+// Recommendation describes a single index recommendation in a form that
+// allows the recommendations for different statements to be merged.
+type Recommendation struct {
+	// TableID is the ID of the table the index is recommended for.
+	TableID cat.StableID
+	// TableName is the name of the table the index is recommended for. It is
+	// unqualified unless it is qualified by the caller.
+	TableName tree.TableName
+	// Columns are the key columns of the recommended index.
+	Columns []tree.IndexElem
+	// Storing are the stored columns of the recommended index.
+	Storing []tree.Name
+	// Inverted is true if the recommended index is an inverted index.
+	Inverted bool
+	// Unique is true if the recommended index must be unique, because it
+	// replaces an existing unique index.
+	Unique bool
+	// ExistingIndex is the name of the existing index with the same key columns
+	// that the recommended index replaces. It is empty if the recommendation is
+	// an index creation.
+	ExistingIndex tree.UnrestrictedName
+}
+
+// SQL returns the SQL command(s) needed to follow the recommendation.
+func (r *Recommendation) SQL() string {
+	var sb strings.Builder
+	createCmd := tree.CreateIndex{
+		Table:    r.TableName,
+		Columns:  r.Columns,
+		Storing:  r.Storing,
+		Unique:   r.Unique,
+		Inverted: r.Inverted,
+	}
+	sb.WriteString(createCmd.String() + ";")
+	if r.ExistingIndex != "" {
+		dropCmd := tree.DropIndex{
+			IndexList: []*tree.TableIndexName{{Table: r.TableName, Index: r.ExistingIndex}},
+		}
+		sb.WriteString(" " + dropCmd.String() + ";")
+	}
+	return sb.String()
+}
+
+// Recommendations returns the index recommendations of the set, ordered by
+// table name.
+func (irs *IndexRecommendationSet) Recommendations() []Recommendation {
+	sortedTables := make([]cat.Table, 0, len(irs.indexRecs))
+	for t := range irs.indexRecs {
+		sortedTables = append(sortedTables, t)
+	}
+	sort.Slice(sortedTables, func(i, j int) bool {
+		return sortedTables[i].Name() < sortedTables[j].Name()
+	})
+
+	var recs []Recommendation
+	for _, t := range sortedTables {
+		for i := range irs.indexRecs[t] {
+			recs = append(recs, irs.indexRecs[t][i].recommendation())
+		}
+	}
+	return recs
+}
+
 // indexRecommendation stores the information pertaining to a single index
 // recommendation.
 type indexRecommendation struct {
@@ -248,7 +308,7 @@ func (ir *indexRecommendation) addStoredColOrds(scannedColOrds util.FastIntSet)
 }
 
 // indexCols returns the explicit key columns of the index, used in
-// indexRecommendationString.
+// recommendation.
 func (ir *indexRecommendation) indexCols() []tree.IndexElem {
 	indexCols := make([]tree.IndexElem, len(ir.index.cols))
 
@@ -272,7 +332,7 @@ func (ir *indexRecommendation) indexCols() []tree.IndexElem {
 }
 
 // storingColumns returns the stored columns of an index recommendation, used in
-// indexRecommendationString.
+// recommendation.
 func (ir *indexRecommendation) storingColumns() []tree.Name {
 	storingLen := ir.newStoredColOrds.Len()
 	if storingLen == 0 {
@@ -286,39 +346,30 @@ func (ir *indexRecommendation) storingColumns() []tree.Name {
 	return storingCols
 }
 
-// indexRecommendationString returns the string output for an index
-// recommendation, containing the SQL command(s) needed to follow this
-// recommendation.
-func (ir *indexRecommendation) indexRecommendationString(
-	indexCols []tree.IndexElem, storing []tree.Name,
-) string {
-	var sb strings.Builder
-	tableName := tree.NewUnqualifiedTableName(ir.index.tab.Name())
-
-	var dropCmd tree.DropIndex
-	unique := false
+// recommendation returns the exported form of the index recommendation.
+func (ir *indexRecommendation) recommendation() Recommendation {
+	rec := Recommendation{
+		TableID:   ir.index.tab.ID(),
+		TableName: tree.MakeUnqualifiedTableName(ir.index.tab.Name()),
+		Columns:   ir.indexCols(),
+		Storing:   ir.storingColumns(),
+		Inverted:  ir.index.IsInverted(),
+	}
 	if ir.existingIndex != nil {
-		sb.WriteString("   SQL commands: ")
-		indexName := tree.UnrestrictedName(ir.existingIndex.Name())
-		dropCmd.IndexList = []*tree.TableIndexName{{Table: *tableName, Index: indexName}}
-
+		rec.ExistingIndex = tree.UnrestrictedName(ir.existingIndex.Name())
 		// Maintain uniqueness if the existing index is unique.
-		unique = ir.existingIndex.IsUnique()
-	} else {
-		sb.WriteString("   SQL command: ")
+		rec.Unique = ir.existingIndex.IsUnique()
 	}
+	return rec
+}
 
-	createCmd := tree.CreateIndex{
-		Table:    *tableName,
-		Columns:  indexCols,
-		Storing:  storing,
-		Unique:   unique,
-		Inverted: ir.index.IsInverted(),
-	}
-	sb.WriteString(createCmd.String() + ";")
-	if len(dropCmd.IndexList) > 0 {
-		sb.WriteString(" " + dropCmd.String() + ";")
+// indexRecommendationString returns the string output for an index
+// recommendation, containing the SQL command(s) needed to follow this
+// recommendation.
+func (ir *indexRecommendation) indexRecommendationString() string {
+	rec := ir.recommendation()
+	if rec.ExistingIndex != "" {
+		return "   SQL commands: " + rec.SQL()
 	}
-
-	return sb.String()
+	return "   SQL command: " + rec.SQL()
 }
@@ -111,13 +111,27 @@ func TryFilterInvertedIndex(
 		}
 		typ = types.Geometry
 	} else {
-		filterPlanner = &jsonOrArrayFilterPlanner{
-			tabID:           tabID,
-			index:           index,
-			computedColumns: computedColumns,
-		}
 		col := index.InvertedColumn().InvertedSourceColumnOrdinal()
 		typ = factory.Metadata().Table(tabID).Column(col).DatumType()
+		if typ.Family() == types.TSVectorFamily {
+			filterPlanner = &tsqueryFilterPlanner{
+				tabID:           tabID,
+				index:           index,
+				computedColumns: computedColumns,
+			}
+		} else if isRangeType(typ) {
+			filterPlanner = &rangeFilterPlanner{
+				tabID:           tabID,
+				index:           index,
+				computedColumns: computedColumns,
+			}
+		} else {
+			filterPlanner = &jsonOrArrayFilterPlanner{
+				tabID:           tabID,
+				index:           index,
+				computedColumns: computedColumns,
+			}
+		}
 	}
 
 	var invertedExpr inverted.Expression
@@ -192,11 +206,22 @@ func TryJoinInvertedIndex(
 			getSpanExpr: getSpanExprForGeometryIndex,
 		}
 	} else {
-		joinPlanner = &jsonOrArrayJoinPlanner{
-			factory:   factory,
-			tabID:     tabID,
-			index:     index,
-			inputCols: inputCols,
+		col := index.InvertedColumn().InvertedSourceColumnOrdinal()
+		typ := factory.Metadata().Table(tabID).Column(col).DatumType()
+		if isRangeType(typ) {
+			joinPlanner = &rangeJoinPlanner{
+				factory:   factory,
+				tabID:     tabID,
+				index:     index,
+				inputCols: inputCols,
+			}
+		} else {
+			joinPlanner = &jsonOrArrayJoinPlanner{
+				factory:   factory,
+				tabID:     tabID,
+				index:     index,
+				inputCols: inputCols,
+			}
 		}
 	}
 
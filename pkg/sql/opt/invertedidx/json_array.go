@@ -162,7 +162,7 @@ type jsonOrArrayInvertedExpr struct {
 var _ tree.TypedExpr = &jsonOrArrayInvertedExpr{}
 
 // jsonOrArrayDatumsToInvertedExpr implements invertedexpr.DatumsToInvertedExpr for
-// JSON and Array columns.
+// JSON and Array columns, as well as range and multirange columns.
 type jsonOrArrayDatumsToInvertedExpr struct {
 	evalCtx      *tree.EvalContext
 	colTypes     []*types.T
@@ -225,10 +225,16 @@ func NewJSONOrArrayDatumsToInvertedExpr(
 			var spanExpr *inverted.SpanExpression
 			if d, ok := nonIndexParam.(tree.Datum); ok {
 				var invertedExpr inverted.Expression
-				switch t.Operator.Symbol {
-				case treecmp.ContainedBy:
+				switch indexType := t.TypedLeft().ResolvedType(); {
+				case isRangeType(indexType):
+					if t.Operator.Symbol != treecmp.Overlaps && t.Operator.Symbol != treecmp.Contains &&
+						t.Operator.Symbol != treecmp.ContainedBy {
+						return nil, fmt.Errorf("%s cannot be index-accelerated", t)
+					}
+					invertedExpr = getInvertedExprForRangeIndex(evalCtx, t.Operator.Symbol, indexType, d)
+				case t.Operator.Symbol == treecmp.ContainedBy:
 					invertedExpr = getInvertedExprForJSONOrArrayIndexForContainedBy(evalCtx, d)
-				case treecmp.Contains:
+				case t.Operator.Symbol == treecmp.Contains:
 					invertedExpr = getInvertedExprForJSONOrArrayIndexForContaining(evalCtx, d)
 				default:
 					return nil, fmt.Errorf("%s cannot be index-accelerated", t)
@@ -277,6 +283,9 @@ func (g *jsonOrArrayDatumsToInvertedExpr) Convert(
 			if d == tree.DNull {
 				return nil, nil
 			}
+			if indexType := t.TypedLeft().ResolvedType(); isRangeType(indexType) {
+				return getInvertedExprForRangeIndex(g.evalCtx, t.Operator.Symbol, indexType, d), nil
+			}
 			switch t.Operator.Symbol {
 			case treecmp.Contains:
 				return getInvertedExprForJSONOrArrayIndexForContaining(g.evalCtx, d), nil
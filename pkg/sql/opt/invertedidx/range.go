@@ -0,0 +1,222 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package invertedidx
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/invertedexpr"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// isRangeType returns true if the given type is a range or multirange type.
+func isRangeType(typ *types.T) bool {
+	return typ.Family() == types.RangeFamily || typ.Family() == types.MultirangeFamily
+}
+
+// commuteRangeOperator returns the operator which is equivalent to op when
+// its arguments are swapped.
+func commuteRangeOperator(op treecmp.ComparisonOperatorSymbol) treecmp.ComparisonOperatorSymbol {
+	switch op {
+	case treecmp.Contains:
+		return treecmp.ContainedBy
+	case treecmp.ContainedBy:
+		return treecmp.Contains
+	}
+	return op
+}
+
+// getInvertedExprForRangeIndex gets an inverted.Expression that constrains an
+// inverted index on a range or multirange column of type colType according to
+// the given comparison operator and constant, i.e. for the predicate
+// col <op> d. Returns nil if d is NULL.
+func getInvertedExprForRangeIndex(
+	evalCtx *tree.EvalContext, op treecmp.ComparisonOperatorSymbol, colType *types.T, d tree.Datum,
+) inverted.Expression {
+	if d == tree.DNull {
+		return nil
+	}
+	if !isRangeType(d.ResolvedType()) {
+		// The constant is an element of the range, which is contained by the
+		// range with the element as its only value.
+		rangeType := colType
+		if colType.Family() == types.MultirangeFamily {
+			rangeType = colType.MultirangeContents()
+		}
+		r, err := tree.NewDRange(rangeType, d, d, true /* lowerInc */, true /* upperInc */)
+		if err != nil {
+			panic(err)
+		}
+		d = r
+	}
+	var invertedExpr inverted.Expression
+	var err error
+	switch op {
+	case treecmp.Overlaps:
+		invertedExpr, err = rowenc.EncodeOverlappingInvertedIndexSpans(evalCtx, d)
+	case treecmp.Contains:
+		invertedExpr, err = rowenc.EncodeContainingInvertedIndexSpans(evalCtx, d)
+	case treecmp.ContainedBy:
+		invertedExpr, err = rowenc.EncodeContainedInvertedIndexSpans(evalCtx, d)
+	}
+	if err != nil {
+		panic(err)
+	}
+	return invertedExpr
+}
+
+type rangeFilterPlanner struct {
+	tabID           opt.TableID
+	index           cat.Index
+	computedColumns map[opt.ColumnID]opt.ScalarExpr
+}
+
+var _ invertedFilterPlanner = &rangeFilterPlanner{}
+
+// extractInvertedFilterConditionFromLeaf is part of the invertedFilterPlanner
+// interface.
+func (r *rangeFilterPlanner) extractInvertedFilterConditionFromLeaf(
+	evalCtx *tree.EvalContext, expr opt.ScalarExpr,
+) (
+	invertedExpr inverted.Expression,
+	remainingFilters opt.ScalarExpr,
+	_ *invertedexpr.PreFiltererStateForInvertedFilterer,
+) {
+	switch t := expr.(type) {
+	case *memo.OverlapsExpr:
+		invertedExpr = r.extractRangeCondition(evalCtx, t.Left, t.Right, treecmp.Overlaps)
+	case *memo.ContainsExpr:
+		invertedExpr = r.extractRangeCondition(evalCtx, t.Left, t.Right, treecmp.Contains)
+	case *memo.ContainedByExpr:
+		invertedExpr = r.extractRangeCondition(evalCtx, t.Left, t.Right, treecmp.ContainedBy)
+	}
+
+	if invertedExpr == nil {
+		// An inverted expression could not be extracted.
+		return inverted.NonInvertedColExpression{}, expr, nil
+	}
+
+	// If the extracted inverted expression is not tight then remaining filters
+	// must be applied after the inverted index scan.
+	if !invertedExpr.IsTight() {
+		remainingFilters = expr
+	}
+
+	// We do not currently support pre-filtering for range indexes, so the
+	// returned pre-filter state is nil.
+	return invertedExpr, remainingFilters, nil
+}
+
+// extractRangeCondition extracts an InvertedExpression representing an
+// inverted filter over the planner's inverted index, based on the given left
+// and right arguments of a &&, @> or <@ expression. One of the arguments must
+// be the indexed range or multirange column and the other a constant. Returns
+// nil if no inverted filter could be extracted.
+func (r *rangeFilterPlanner) extractRangeCondition(
+	evalCtx *tree.EvalContext, left, right opt.ScalarExpr, op treecmp.ComparisonOperatorSymbol,
+) inverted.Expression {
+	var indexColumn, constantVal opt.ScalarExpr
+	if isIndexColumn(r.tabID, r.index, left, r.computedColumns) && memo.CanExtractConstDatum(right) {
+		indexColumn, constantVal = left, right
+	} else if isIndexColumn(r.tabID, r.index, right, r.computedColumns) && memo.CanExtractConstDatum(left) {
+		// When the second argument is the index column, we get the equivalent
+		// InvertedExpression for right <op> left.
+		indexColumn, constantVal = right, left
+		op = commuteRangeOperator(op)
+	} else {
+		return nil
+	}
+	d := memo.ExtractConstDatum(constantVal)
+	if op != treecmp.Contains && !isRangeType(d.ResolvedType()) {
+		// Only containment of elements can be index-accelerated.
+		return nil
+	}
+	return getInvertedExprForRangeIndex(evalCtx, op, indexColumn.DataType(), d)
+}
+
+type rangeJoinPlanner struct {
+	factory   *norm.Factory
+	tabID     opt.TableID
+	index     cat.Index
+	inputCols opt.ColSet
+}
+
+var _ invertedJoinPlanner = &rangeJoinPlanner{}
+
+// extractInvertedJoinConditionFromLeaf is part of the invertedJoinPlanner
+// interface.
+func (r *rangeJoinPlanner) extractInvertedJoinConditionFromLeaf(
+	ctx context.Context, expr opt.ScalarExpr,
+) opt.ScalarExpr {
+	var left, right opt.ScalarExpr
+	var op treecmp.ComparisonOperatorSymbol
+	switch t := expr.(type) {
+	case *memo.OverlapsExpr:
+		left, right, op = t.Left, t.Right, treecmp.Overlaps
+	case *memo.ContainsExpr:
+		left, right, op = t.Left, t.Right, treecmp.Contains
+	case *memo.ContainedByExpr:
+		left, right, op = t.Left, t.Right, treecmp.ContainedBy
+	default:
+		return nil
+	}
+
+	var val opt.ScalarExpr
+	commuteArgs := false
+	if isIndexColumn(r.tabID, r.index, left, nil /* computedColumns */) {
+		val = right
+	} else if isIndexColumn(r.tabID, r.index, right, nil /* computedColumns */) {
+		val = left
+		commuteArgs = true
+		op = commuteRangeOperator(op)
+	} else {
+		return nil
+	}
+	if op != treecmp.Contains && !isRangeType(val.DataType()) {
+		// Only containment of elements can be index-accelerated.
+		return nil
+	}
+
+	// The non-indexed argument should either come from the input or be a
+	// constant.
+	var p props.Shared
+	memo.BuildSharedProps(val, &p, r.factory.EvalContext())
+	if !p.OuterCols.Empty() {
+		if !p.OuterCols.SubsetOf(r.inputCols) {
+			return nil
+		}
+	} else if !memo.CanExtractConstDatum(val) {
+		return nil
+	}
+
+	// If commuteArgs is true, we construct a new equivalent expression so that
+	// the left argument is the indexed column.
+	if commuteArgs {
+		switch op {
+		case treecmp.Overlaps:
+			return r.factory.ConstructOverlaps(right, left)
+		case treecmp.Contains:
+			return r.factory.ConstructContains(right, left)
+		default:
+			return r.factory.ConstructContainedBy(right, left)
+		}
+	}
+	return expr
+}
@@ -1113,6 +1113,23 @@ func (f *ExprFmtCtx) formatScalarPrivate(scalar opt.ScalarExpr) {
 
 	case *UniqueChecksItem:
 		tab := f.Memo.metadata.TableMeta(t.Table)
+		if t.Exclusion {
+			constraint := tab.Table.ExclusionConstraint(t.CheckOrdinal)
+			fmt.Fprintf(f.Buffer, ": %s(", tab.Alias.ObjectName)
+			n := 0
+			for i := 0; i < constraint.ElementCount(); i++ {
+				for j := 0; j < constraint.ElementColumnCount(i); j++ {
+					if n > 0 {
+						f.Buffer.WriteByte(',')
+					}
+					col := tab.Table.Column(constraint.ElementColumnOrdinal(tab.Table, i, j))
+					f.Buffer.WriteString(string(col.ColName()))
+					n++
+				}
+			}
+			f.Buffer.WriteByte(')')
+			break
+		}
 		constraint := tab.Table.Unique(t.CheckOrdinal)
 		fmt.Fprintf(f.Buffer, ": %s(", tab.Alias.ObjectName)
 		for i := 0; i < constraint.ColumnCount(); i++ {
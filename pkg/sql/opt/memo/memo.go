@@ -131,6 +131,10 @@ type Memo struct {
 	// memEstimate is the approximate memory usage of the memo, in bytes.
 	memEstimate int64
 
+	// pinnedIndexesVersion is the version of the indexes pinned for the
+	// statement when the memo was built; see SetPinnedIndexesVersion.
+	pinnedIndexesVersion uint64
+
 	// The following are selected fields from SessionData which can affect
 	// planning. We need to cross-check these before reusing a cached memo.
 	// NOTE: If you add new fields here, be sure to add them to the relevant
@@ -284,6 +288,20 @@ func (m *Memo) HasPlaceholders() bool {
 	return rel.Relational().HasPlaceholder
 }
 
+// SetPinnedIndexesVersion records the version of the indexes that were pinned
+// for the statement when the memo was built (see optbuilder.PinnedIndexes).
+// The version is opaque to the memo; it allows callers to detect cached memos
+// that were built with outdated pinned indexes.
+func (m *Memo) SetPinnedIndexesVersion(version uint64) {
+	m.pinnedIndexesVersion = version
+}
+
+// PinnedIndexesVersion returns the version recorded by
+// SetPinnedIndexesVersion.
+func (m *Memo) PinnedIndexesVersion() uint64 {
+	return m.pinnedIndexesVersion
+}
+
 // IsStale returns true if the memo has been invalidated by changes to any of
 // its dependencies. Once a memo is known to be stale, it must be ejected from
 // any query cache or prepared statement and replaced with a recompiled memo
@@ -16,9 +16,11 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/geo/geoindex"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -647,9 +649,10 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 
 					// If this column is invertable, the histogram describes the inverted index
 					// entries, and we need to create a new stat for it, and not apply a histogram
-					// to the source column.
+					// to the source column. Columns which can also be forward indexed, like
+					// range columns, may have a histogram of the column values instead.
 					invertedColOrds := invertedIndexCols[stat.ColumnOrdinal(0)]
-					if len(invertedColOrds) == 0 {
+					if len(invertedColOrds) == 0 || !isInvertedIndexHistogram(stat.Histogram()) {
 						colStat.Histogram = &props.Histogram{}
 						colStat.Histogram.Init(sb.evalCtx, col, stat.Histogram())
 					} else {
@@ -2905,6 +2908,13 @@ func max(a, b float64) float64 {
 	return b
 }
 
+// isInvertedIndexHistogram returns true if the given histogram describes the
+// entries of an inverted index, which are encoded as bytes, rather than the
+// values of the column.
+func isInvertedIndexHistogram(hist []cat.HistogramBucket) bool {
+	return len(hist) == 0 || hist[0].UpperBound.ResolvedType().Family() == types.BytesFamily
+}
+
 //////////////////////////////////////////////////
 // Helper functions for selectivity calculation //
 //////////////////////////////////////////////////
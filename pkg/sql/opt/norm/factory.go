@@ -11,6 +11,8 @@
 package norm
 
 import (
+	"fmt"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
@@ -297,6 +299,97 @@ func (f *Factory) AssignPlaceholders(from *memo.Memo) (err error) {
 	return nil
 }
 
+// ParameterizePlaceholders is used to build a generic plan for a prepared
+// Memo, which is optimized once without knowing the placeholder values and is
+// reused for all executions. It makes a copy of the given memo in which each
+// Select on top of a Scan with placeholders in its filters is replaced by an
+// inner join between a single-row Values operator that produces the
+// placeholder values and the Scan. For example:
+//
+//   SELECT * FROM t WHERE k = $1
+//   =>
+//   SELECT t.* FROM (VALUES ($1)) AS v(p1) INNER JOIN t ON k = p1
+//
+// This allows the exploration rules to generate lookup joins into the indexes
+// that are constrained by the placeholders, even though the values of the
+// placeholders are not known until execution.
+func (f *Factory) ParameterizePlaceholders(from *memo.Memo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			// This code allows us to propagate errors without adding lots of checks
+			// for `if err != nil` throughout the construction code. This is only
+			// possible because the code does not update shared state and does not
+			// manipulate locks.
+			if ok, e := errorutil.ShouldCatch(r); ok {
+				err = e
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	var replaceFn ReplaceFunc
+	replaceFn = func(e opt.Expr) opt.Expr {
+		if sel, ok := e.(*memo.SelectExpr); ok {
+			if scan, ok := sel.Input.(*memo.ScanExpr); ok {
+				props := sel.Relational()
+				if props.HasPlaceholder && !props.HasSubquery && !props.VolatilitySet.HasVolatile() {
+					return f.parameterizeSelect(sel.Filters, replaceFn(scan).(memo.RelExpr))
+				}
+			}
+		}
+		return f.CopyAndReplaceDefault(e, replaceFn)
+	}
+	f.CopyAndReplace(from.RootExpr().(memo.RelExpr), from.RootProps(), replaceFn)
+
+	return nil
+}
+
+// parameterizeSelect constructs an inner join between a single-row Values
+// operator producing the placeholders referenced by the given filters and the
+// given input. The placeholders in the filters are replaced with references to
+// the columns of the Values operator, and the filters become the ON condition
+// of the join. See ParameterizePlaceholders for more details.
+func (f *Factory) parameterizeSelect(filters memo.FiltersExpr, input memo.RelExpr) memo.RelExpr {
+	md := f.Metadata()
+	var placeholders memo.ScalarListExpr
+	var cols opt.ColList
+	colsByIdx := make(map[tree.PlaceholderIdx]opt.ColumnID)
+	var replaceFn ReplaceFunc
+	replaceFn = func(e opt.Expr) opt.Expr {
+		if p, ok := e.(*memo.PlaceholderExpr); ok {
+			idx := p.Value.(*tree.Placeholder).Idx
+			col, ok := colsByIdx[idx]
+			if !ok {
+				col = md.AddColumn(fmt.Sprintf("$%d", idx+1), p.DataType())
+				colsByIdx[idx] = col
+				cols = append(cols, col)
+				placeholders = append(placeholders, f.ConstructPlaceholder(p.Value))
+			}
+			return f.ConstructVariable(col)
+		}
+		return f.CopyAndReplaceDefault(e, replaceFn)
+	}
+	on := make(memo.FiltersExpr, len(filters))
+	for i := range filters {
+		on[i] = f.ConstructFiltersItem(replaceFn(filters[i].Condition).(opt.ScalarExpr))
+	}
+
+	typs := make([]*types.T, len(cols))
+	for i, col := range cols {
+		typs[i] = md.ColumnMeta(col).Type
+	}
+	values := f.ConstructValues(
+		memo.ScalarListExpr{f.ConstructTuple(placeholders, types.MakeTuple(typs))},
+		&memo.ValuesPrivate{
+			Cols: cols,
+			ID:   md.NextUniqueID(),
+		},
+	)
+	join := f.ConstructInnerJoin(values, input, on, memo.EmptyJoinPrivate)
+	return f.ConstructProject(join, memo.EmptyProjectionsExpr, input.Relational().OutputCols)
+}
+
 // CheckConstructorStackDepth panics in test builds if the constructor stack
 // depth is not zero. The stack depth should be 0 after a top-level constructor
 // function returns. It is used to verify that the stack depth is correctly
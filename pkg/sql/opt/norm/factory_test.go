@@ -158,3 +158,50 @@ func TestCopyAndReplaceWithScan(t *testing.T) {
 		})
 	}
 }
+
+// Test that ParameterizePlaceholders allows the optimizer to use a lookup join
+// into an index constrained by placeholders, without knowing their values.
+func TestParameterizePlaceholders(t *testing.T) {
+	cat := testcat.New()
+	if _, err := cat.ExecuteDDL("CREATE TABLE cde (c INT PRIMARY KEY, d INT, e INT, INDEX(d))"); err != nil {
+		t.Fatal(err)
+	}
+
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	for _, query := range []string{
+		"SELECT * FROM cde WHERE c = $1",
+		"SELECT c FROM cde WHERE d = $1 AND e > 0",
+		"SELECT * FROM cde WHERE c = $1 AND d = $2 + 1",
+	} {
+		t.Run(query, func(t *testing.T) {
+			var o xform.Optimizer
+			testutils.BuildQuery(t, &o, cat, &evalCtx, query)
+
+			m := o.Factory().DetachMemo()
+
+			o.Init(&evalCtx, cat)
+			if err := o.Factory().ParameterizePlaceholders(m); err != nil {
+				t.Fatal(err)
+			}
+			e, err := o.Optimize()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var hasLookupJoin bool
+			var walk func(e opt.Expr)
+			walk = func(e opt.Expr) {
+				if e.Op() == opt.LookupJoinOp {
+					hasLookupJoin = true
+				}
+				for i, n := 0, e.ChildCount(); i < n; i++ {
+					walk(e.Child(i))
+				}
+			}
+			walk(e)
+			if !hasLookupJoin {
+				t.Errorf("expected optimizer to choose lookup-join, got:\n%s", o.FormatExpr(e, memo.ExprFmtHideAll))
+			}
+		})
+	}
+}
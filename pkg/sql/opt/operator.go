@@ -144,6 +144,7 @@ var ComparisonOpReverseMap = map[Operator]treecmp.ComparisonOperatorSymbol{
 	JsonSomeExistsOp: treecmp.JSONSomeExists,
 	JsonAllExistsOp:  treecmp.JSONAllExists,
 	OverlapsOp:       treecmp.Overlaps,
+	TSMatchesOp:      treecmp.TSMatches,
 	BBoxCoversOp:     treecmp.RegMatch,
 	BBoxIntersectsOp: treecmp.Overlaps,
 }
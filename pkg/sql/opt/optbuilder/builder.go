@@ -68,6 +68,13 @@ type Builder struct {
 	// This is used when re-preparing invalidated queries.
 	KeepPlaceholders bool
 
+	// PinnedIndexes is a control knob: if set, scans of the tables it references
+	// are forced to use the pinned index, unless the query itself contains an
+	// index hint for the scan. It is set when indexes have been pinned for the
+	// statement's fingerprint with ALTER STATEMENT FINGERPRINT ... PIN INDEX
+	// HINTS.
+	PinnedIndexes PinnedIndexes
+
 	// -- Results --
 	//
 	// These fields are set during the building process and can be used after
@@ -146,6 +153,31 @@ type Builder struct {
 	areAllTableMutationsSimpleInserts map[cat.StableID]bool
 }
 
+// PinnedIndexes maps the IDs of tables to the IDs of the indexes that scans
+// of the tables are forced to use.
+type PinnedIndexes map[cat.StableID]cat.StableID
+
+// indexOrdinal returns the ordinal of the index pinned for the given table, if
+// there is one and it still exists. Inverted and partial indexes are never
+// returned, since whether they can be used depends on the constants in the
+// query's filters, which differ between executions of the same fingerprint.
+func (p PinnedIndexes) indexOrdinal(tab cat.Table) (int, bool) {
+	id, ok := p[tab.ID()]
+	if !ok {
+		return 0, false
+	}
+	for i := 0; i < tab.IndexCount(); i++ {
+		idx := tab.Index(i)
+		if idx.ID() == id {
+			if _, isPartial := idx.Predicate(); isPartial || idx.IsInverted() {
+				return 0, false
+			}
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 // New creates a new Builder structure initialized with the given
 // parsed SQL statement.
 func New(
@@ -267,7 +299,7 @@ func (b *Builder) buildStmt(
 	if b.insideViewDef {
 		// A blocklist of statements that can't be used from inside a view.
 		switch stmt := stmt.(type) {
-		case *tree.Delete, *tree.Insert, *tree.Update, *tree.CreateTable, *tree.CreateView,
+		case *tree.Delete, *tree.Insert, *tree.Update, *tree.Merge, *tree.CreateTable, *tree.CreateView,
 			*tree.Split, *tree.Unsplit, *tree.Relocate, *tree.RelocateRange,
 			*tree.ControlJobs, *tree.ControlSchedules, *tree.CancelQueries, *tree.CancelSessions:
 			panic(pgerror.Newf(
@@ -298,6 +330,11 @@ func (b *Builder) buildStmt(
 			return b.buildUpdate(stmt, inScope)
 		})
 
+	case *tree.Merge:
+		return b.processWiths(stmt.With, inScope, func(inScope *scope) *scope {
+			return b.buildMerge(stmt, inScope)
+		})
+
 	case *tree.CreateTable:
 		return b.buildCreateTable(stmt, inScope)
 
@@ -0,0 +1,704 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlerrors"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+)
+
+// mergeCardinalityErrText is error text used when a target row is matched by
+// more than one source row in a MERGE statement.
+const mergeCardinalityErrText = "MERGE command cannot affect row a second time"
+
+// buildMerge builds a memo group for a MERGE statement. The source is joined
+// to the target table using the ON condition (a left join if there are any
+// WHEN NOT MATCHED clauses, and an inner join otherwise), and an "action"
+// column is projected that records which WHEN clause applies to each row. For
+// example:
+//
+//   CREATE TABLE abc (a INT PRIMARY KEY, b INT, c INT)
+//   MERGE INTO abc USING xyz ON a = x
+//   WHEN MATCHED AND z > 0 THEN DELETE
+//   WHEN MATCHED THEN UPDATE SET b = y
+//   WHEN NOT MATCHED THEN INSERT VALUES (x, y, z)
+//
+// builds an input expression similar to this SQL:
+//
+//   SELECT
+//     x, y, z, a, b, c,
+//     CASE
+//       WHEN a IS NOT NULL AND z > 0 THEN 1
+//       WHEN a IS NOT NULL THEN 2
+//       WHEN a IS NULL THEN 3
+//       ELSE 0
+//     END AS action
+//   FROM xyz LEFT JOIN abc ON a = x
+//
+// The join is wrapped in an EnsureUpsertDistinctOn on the primary key of the
+// target table, which raises an error if any target row is matched by more
+// than one source row.
+//
+// Rows are then routed to the existing mutation operators based on the action
+// column. INSERT and UPDATE clauses share a single Upsert operator (or an
+// Insert or Update operator if only one kind is present), with the insert and
+// update values selected by CASE expressions on the action column. DELETE
+// clauses use a Delete operator. If the statement both deletes and writes
+// rows, the input is buffered in a WITH binding that is read by each of the
+// mutations, and the statement returns the total number of affected rows.
+func (b *Builder) buildMerge(merge *tree.Merge, inScope *scope) (outScope *scope) {
+	// Find which table we're working on, check the permissions.
+	tab, depName, alias, refColumns := b.resolveTableForMutation(merge.Table, privilege.SELECT)
+
+	if refColumns != nil {
+		panic(pgerror.Newf(pgcode.Syntax,
+			"cannot specify a list of column IDs with MERGE"))
+	}
+
+	// Sort the WHEN clauses by the kind of mutation they require. Actions are
+	// numbered by the 1-based position of the clause in the statement, since
+	// the first clause whose condition is satisfied wins.
+	var insertActions, updateActions, deleteActions []int
+	hasNotMatched := false
+	for i, when := range merge.Whens {
+		if !when.Matched {
+			hasNotMatched = true
+		}
+		switch when.Action {
+		case tree.MergeActionInsert:
+			insertActions = append(insertActions, i+1)
+		case tree.MergeActionUpdate:
+			updateActions = append(updateActions, i+1)
+		case tree.MergeActionDelete:
+			deleteActions = append(deleteActions, i+1)
+		}
+	}
+	if len(insertActions) > 0 {
+		b.checkPrivilege(depName, tab, privilege.INSERT)
+	}
+	if len(updateActions) > 0 {
+		b.checkPrivilege(depName, tab, privilege.UPDATE)
+	}
+	if len(deleteActions) > 0 {
+		b.checkPrivilege(depName, tab, privilege.DELETE)
+	}
+
+	// Check if this table has already been mutated in another subquery.
+	b.checkMultipleMutations(tab, false /* simpleInsert */)
+
+	var mb mutationBuilder
+	mb.init(b, "merge", tab, alias)
+
+	// Build the input expression, which joins the source to the target table
+	// and projects the action column.
+	canaryCol, actionCol := mb.buildInputForMerge(inScope, merge, hasNotMatched)
+
+	hasWrites := len(insertActions) > 0 || len(updateActions) > 0
+	switch {
+	case hasWrites && len(deleteActions) > 0:
+		return b.buildMergeWithDelete(
+			&mb, merge.Whens, insertActions, updateActions, deleteActions, canaryCol, actionCol,
+		)
+
+	case hasWrites:
+		mb.buildMergeWrite(
+			merge.Whens, insertActions, updateActions, canaryCol, actionCol, nil, /* returning */
+		)
+
+	case len(deleteActions) > 0:
+		mb.buildMergeActionFilter(actionCol, deleteActions)
+		mb.buildDelete(nil /* returning */)
+
+	default:
+		// All clauses are DO NOTHING, so no rows are affected.
+		mb.buildMergeActionFilter(actionCol, nil /* actions */)
+		return b.buildMergeRowCount([]memo.RelExpr{mb.outScope.expr})
+	}
+
+	return mb.outScope
+}
+
+// buildInputForMerge constructs the input expression of a MERGE statement, as
+// described in the buildMerge comment. It returns the canary column, which is
+// null for source rows that did not match any target row, and the action
+// column.
+func (mb *mutationBuilder) buildInputForMerge(
+	inScope *scope, merge *tree.Merge, hasNotMatched bool,
+) (canaryCol, actionCol opt.ColumnID) {
+	var indexFlags *tree.IndexFlags
+	if source, ok := merge.Table.(*tree.AliasedTableExpr); ok && source.IndexFlags != nil {
+		indexFlags = source.IndexFlags
+	}
+
+	sourceScope := mb.b.buildDataSource(merge.Source, nil /* indexFlags */, noRowLocking, inScope)
+
+	// Fetch columns from different instance of the table metadata, so that it's
+	// possible to remap columns, as in this example:
+	//
+	//   MERGE INTO abc USING xyz ON a = x WHEN MATCHED THEN UPDATE SET a = b
+	//
+	// NOTE: Include mutation columns, but be careful to never use them for any
+	//       reason other than as "fetch columns". See buildScan comment.
+	mb.fetchScope = mb.b.buildScan(
+		mb.b.addTable(mb.tab, &mb.alias),
+		tableOrdinals(mb.tab, columnKinds{
+			includeMutations:       true,
+			includeSystem:          true,
+			includeInverted:        false,
+			includeVirtualComputed: true,
+		}),
+		indexFlags,
+		noRowLocking,
+		inScope,
+	)
+
+	// Set list of columns that will be fetched by the input expression.
+	mb.setFetchColIDs(mb.fetchScope.cols)
+
+	// Check that the same table name is not used on both sides.
+	mb.b.validateJoinTableNames(sourceScope, mb.fetchScope)
+
+	// Join the source to the target table. Columns from both sides are visible
+	// to the ON condition. We create a new scope so that fetchScope is not
+	// modified. It will be used later to build partial index predicate
+	// expressions, and we do not want ambiguities with source column names.
+	mb.outScope = inScope.push()
+	mb.outScope.appendColumnsFromScope(sourceScope)
+	mb.outScope.appendColumnsFromScope(mb.fetchScope)
+
+	mb.b.semaCtx.Properties.Require(
+		exprKindOn.String(), tree.RejectGenerators|tree.RejectWindowApplications,
+	)
+	mb.outScope.context = exprKindOn
+	on := mb.b.buildScalar(
+		mb.outScope.resolveAndRequireType(merge.On, types.Bool), mb.outScope, nil, nil, nil,
+	)
+	filters := memo.FiltersExpr{mb.b.factory.ConstructFiltersItem(on)}
+	if hasNotMatched {
+		mb.outScope.expr = mb.b.factory.ConstructLeftJoin(
+			sourceScope.expr, mb.fetchScope.expr, filters, memo.EmptyJoinPrivate,
+		)
+	} else {
+		mb.outScope.expr = mb.b.factory.ConstructInnerJoin(
+			sourceScope.expr, mb.fetchScope.expr, filters, memo.EmptyJoinPrivate,
+		)
+	}
+
+	// Record a not-null "canary" column. After the left-join, this will be null
+	// if the source row did not match a target row, or not null otherwise.
+	primaryIndex := mb.tab.Index(cat.PrimaryIndex)
+	canaryCol = mb.fetchColIDs[findNotNullIndexCol(primaryIndex)]
+
+	// Ensure that each target row is matched by at most one source row.
+	// Otherwise, the same row could be affected more than once. Unmatched
+	// source rows have null primary key values, and are never considered
+	// duplicates of each other.
+	var pkCols opt.ColSet
+	for i, n := 0, primaryIndex.KeyColumnCount(); i < n; i++ {
+		pkCols.Add(mb.fetchColIDs[primaryIndex.Column(i).Ordinal()])
+	}
+	mb.outScope.ordering = nil
+	mb.outScope = mb.b.buildDistinctOn(
+		pkCols, mb.outScope, true /* nullsAreDistinct */, mergeCardinalityErrText,
+	)
+
+	// Project the action column. It is 0 for rows to which no WHEN clause
+	// applies.
+	canary := mb.outScope.getColumn(canaryCol)
+	caseExpr := &tree.CaseExpr{Else: tree.NewDInt(0)}
+	for i, when := range merge.Whens {
+		op := treecmp.IsNotDistinctFrom
+		if when.Matched {
+			op = treecmp.IsDistinctFrom
+		}
+		var cond tree.Expr = &tree.ComparisonExpr{
+			Operator: treecmp.MakeComparisonOperator(op),
+			Left:     canary,
+			Right:    tree.DNull,
+		}
+		if when.Cond != nil {
+			cond = &tree.AndExpr{Left: cond, Right: when.Cond}
+		}
+		caseExpr.Whens = append(caseExpr.Whens, &tree.When{
+			Cond: cond,
+			Val:  tree.NewDInt(tree.DInt(i + 1)),
+		})
+	}
+
+	scalarProps := &mb.b.semaCtx.Properties
+	defer scalarProps.Restore(*scalarProps)
+	mb.b.semaCtx.Properties.Require("MERGE WHEN", tree.RejectSpecial)
+
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+	texpr := mb.outScope.resolveAndRequireType(caseExpr, types.Int)
+
+	// Use an anonymous name because the column cannot be referenced in other
+	// expressions.
+	colName := scopeColName("").WithMetadataName("merge_action")
+	scopeCol := projectionsScope.addColumn(colName, texpr)
+	mb.b.buildScalar(texpr, mb.outScope, projectionsScope, scopeCol, nil)
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+
+	return canaryCol, scopeCol.id
+}
+
+// buildMergeActionFilter filters the input to the rows with one of the given
+// action numbers.
+func (mb *mutationBuilder) buildMergeActionFilter(actionCol opt.ColumnID, actions []int) {
+	var filter opt.ScalarExpr
+	if len(actions) == 0 {
+		filter = memo.FalseSingleton
+	} else {
+		elems := make(memo.ScalarListExpr, len(actions))
+		elemTypes := make([]*types.T, len(actions))
+		for i, action := range actions {
+			elems[i] = mb.b.factory.ConstructConstVal(tree.NewDInt(tree.DInt(action)), types.Int)
+			elemTypes[i] = types.Int
+		}
+		filter = mb.b.factory.ConstructIn(
+			mb.b.factory.ConstructVariable(actionCol),
+			mb.b.factory.ConstructTuple(elems, types.MakeTuple(elemTypes)),
+		)
+	}
+	mb.outScope.expr = mb.b.factory.ConstructSelect(
+		mb.outScope.expr,
+		memo.FiltersExpr{mb.b.factory.ConstructFiltersItem(filter)},
+	)
+}
+
+// buildMergeWrite builds the Upsert, Insert or Update operator that applies
+// the given INSERT and UPDATE clauses of a MERGE statement.
+func (mb *mutationBuilder) buildMergeWrite(
+	whens tree.MergeWhens,
+	insertActions, updateActions []int,
+	canaryCol, actionCol opt.ColumnID,
+	returning tree.ReturningExprs,
+) {
+	writeActions := append(insertActions[:len(insertActions):len(insertActions)], updateActions...)
+	mb.buildMergeActionFilter(actionCol, writeActions)
+
+	// Build the insert and update values.
+	mb.addMergeValueCols(whens, insertActions, updateActions, actionCol)
+
+	if len(insertActions) > 0 {
+		// Add default columns that were not explicitly specified by name or
+		// implicitly targeted by input columns. Also add any computed columns.
+		mb.addSynthesizedColsForMergeInsert()
+	}
+	if len(updateActions) > 0 {
+		// Add additional columns for computed expressions that may depend on the
+		// updated columns.
+		mb.addSynthesizedColsForUpdate()
+	}
+
+	switch {
+	case len(insertActions) > 0 && len(updateActions) > 0:
+		mb.canaryColID = canaryCol
+		mb.buildUpsert(returning)
+
+	case len(updateActions) > 0:
+		mb.buildUpdate(returning)
+
+	default:
+		// Only unmatched rows are inserted, so there are no existing values to
+		// fetch.
+		for i := range mb.fetchColIDs {
+			mb.fetchColIDs[i] = 0
+		}
+		mb.buildInsert(returning)
+	}
+}
+
+// addMergeValueCols projects a column for each table column that is given a
+// value by the INSERT or UPDATE clauses of a MERGE statement. If a column is
+// given values by more than one clause, a CASE expression on the action column
+// selects the value of the clause that applies to each row. For example:
+//
+//   WHEN MATCHED AND z > 0 THEN UPDATE SET b = 1
+//   WHEN MATCHED THEN UPDATE SET b = 2
+//
+// projects the update value:
+//
+//   CASE WHEN action = 1 THEN 1 WHEN action = 2 THEN 2 ELSE b END
+//
+// The insert and update columns are recorded in insertColIDs and updateColIDs.
+func (mb *mutationBuilder) addMergeValueCols(
+	whens tree.MergeWhens, insertActions, updateActions []int, actionCol opt.ColumnID,
+) {
+	// Determine the value expressions of each INSERT clause, indexed by table
+	// column ordinal and then by clause.
+	n := mb.tab.ColumnCount()
+	insertExprs := make([][]tree.Expr, n)
+	for i, action := range insertActions {
+		when := whens[action-1]
+		mb.targetColList = mb.targetColList[:0]
+		mb.targetColSet = opt.ColSet{}
+		if when.Values != nil {
+			if len(when.Columns) != 0 {
+				mb.addTargetNamedColsForInsert(when.Columns)
+				mb.checkNumCols(len(mb.targetColList), len(when.Values))
+			} else {
+				mb.addTargetTableColsForInsert(len(when.Values))
+			}
+		}
+		for j, colID := range mb.targetColList {
+			ord := mb.tabID.ColumnOrdinal(colID)
+			if insertExprs[ord] == nil {
+				insertExprs[ord] = make([]tree.Expr, len(insertActions))
+			}
+			insertExprs[ord][i] = when.Values[j]
+		}
+	}
+
+	// Determine the value expressions of each UPDATE clause in the same way.
+	updateExprs := make([][]tree.Expr, n)
+	for i, action := range updateActions {
+		when := whens[action-1]
+		for _, set := range when.Exprs {
+			if _, ok := set.Expr.(*tree.Subquery); ok && set.Tuple {
+				panic(unimplemented.Newf("merge update subquery",
+					"subqueries are not supported as the source of a multiple-column SET in MERGE"))
+			}
+		}
+		mb.targetColList = mb.targetColList[:0]
+		mb.targetColSet = opt.ColSet{}
+		mb.addTargetColsForUpdate(when.Exprs)
+		j := 0
+		addExpr := func(expr tree.Expr) {
+			ord := mb.tabID.ColumnOrdinal(mb.targetColList[j])
+			if updateExprs[ord] == nil {
+				updateExprs[ord] = make([]tree.Expr, len(updateActions))
+			}
+			updateExprs[ord][i] = expr
+			j++
+		}
+		for _, set := range when.Exprs {
+			if set.Tuple {
+				for _, expr := range set.Expr.(*tree.Tuple).Exprs {
+					addExpr(expr)
+				}
+			} else {
+				addExpr(set.Expr)
+			}
+		}
+	}
+	mb.targetColList = mb.targetColList[:0]
+	mb.targetColSet = opt.ColSet{}
+
+	// Value expressions should reject aggregates, generators, etc.
+	scalarProps := &mb.b.semaCtx.Properties
+	defer scalarProps.Restore(*scalarProps)
+	mb.b.semaCtx.Properties.Require("MERGE", tree.RejectSpecial)
+
+	// MERGE input columns are accessible to the value expressions.
+	inScope := mb.outScope
+	action := inScope.getColumn(actionCol)
+
+	projectionsScope := mb.outScope.replace()
+	projectionsScope.appendColumnsFromScope(mb.outScope)
+
+	addCol := func(
+		ord int, exprs []tree.Expr, actions []int, elseExpr tree.Expr, isUpdate bool,
+	) opt.ColumnID {
+		targetCol := mb.tab.Column(ord)
+		targetColID := mb.tabID.ColumnID(ord)
+		targetColName := targetCol.ColName()
+
+		whens := make([]*tree.When, 0, len(exprs))
+		for i, expr := range exprs {
+			if expr == nil {
+				// The column is not given a value by this clause. Updated rows keep
+				// the existing value, and inserted rows use the default value.
+				if isUpdate {
+					continue
+				}
+				expr = tree.DefaultVal{}
+			}
+			if _, ok := expr.(tree.DefaultVal); ok {
+				expr = mb.parseDefaultExpr(targetColID)
+			} else if targetCol.IsGeneratedAlwaysAsIdentity() {
+				// GENERATED ALWAYS AS IDENTITY columns are not allowed to be
+				// explicitly written to.
+				if isUpdate {
+					panic(sqlerrors.NewGeneratedAlwaysAsIdentityColumnUpdateError(string(targetColName)))
+				}
+				panic(sqlerrors.NewGeneratedAlwaysAsIdentityColumnOverrideError(string(targetColName)))
+			}
+			whens = append(whens, &tree.When{
+				Cond: &tree.ComparisonExpr{
+					Operator: treecmp.MakeComparisonOperator(treecmp.EQ),
+					Left:     action,
+					Right:    tree.NewDInt(tree.DInt(actions[i])),
+				},
+				Val: expr,
+			})
+		}
+
+		// There is no need for a CASE expression if there is only one clause.
+		var expr tree.Expr
+		if len(exprs) == 1 {
+			expr = whens[0].Val
+		} else {
+			expr = &tree.CaseExpr{Whens: whens, Else: elseExpr}
+		}
+
+		// Add new column to the projections scope. It is important to use the
+		// real column reference name, as this column may later be referred to by
+		// a computed column.
+		colName := scopeColName(targetColName)
+		if isUpdate {
+			colName = colName.WithMetadataName(string(targetColName) + "_new")
+		}
+		texpr := inScope.resolveType(expr, targetCol.DatumType())
+		scopeCol := projectionsScope.addColumn(colName, texpr)
+		mb.b.buildScalar(texpr, inScope, projectionsScope, scopeCol, nil)
+		return scopeCol.id
+	}
+
+	for ord := 0; ord < n; ord++ {
+		if insertExprs[ord] != nil {
+			mb.insertColIDs[ord] = addCol(
+				ord, insertExprs[ord], insertActions, tree.DNull, false, /* isUpdate */
+			)
+		}
+		if updateExprs[ord] != nil {
+			fetchCol := inScope.getColumn(mb.fetchColIDs[ord])
+			mb.updateColIDs[ord] = addCol(
+				ord, updateExprs[ord], updateActions, fetchCol, true, /* isUpdate */
+			)
+		}
+	}
+
+	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
+	mb.outScope = projectionsScope
+
+	// Add assignment casts for insert and update columns.
+	mb.addAssignmentCasts(mb.insertColIDs)
+	mb.addAssignmentCasts(mb.updateColIDs)
+}
+
+// addSynthesizedColsForMergeInsert is similar to addSynthesizedColsForInsert,
+// but hides the names of all columns other than the insert columns while the
+// default and computed columns are built. The input of a MERGE contains fetch,
+// source and update columns that may have the same names as the insert
+// columns, and computed column expressions must refer to the insert columns.
+func (mb *mutationBuilder) addSynthesizedColsForMergeInsert() {
+	insertCols := mb.insertColIDs.ToSet()
+	names := make(map[opt.ColumnID]scopeColumn, len(mb.outScope.cols))
+	for i := range mb.outScope.cols {
+		col := &mb.outScope.cols[i]
+		if !insertCols.Contains(col.id) {
+			names[col.id] = *col
+			col.clearName()
+		}
+	}
+
+	mb.addSynthesizedColsForInsert()
+
+	// Restore the hidden names.
+	for i := range mb.outScope.cols {
+		col := &mb.outScope.cols[i]
+		if orig, ok := names[col.id]; ok {
+			col.name = orig.name
+			col.table = orig.table
+		}
+	}
+}
+
+// buildMergeWithDelete builds a MERGE statement that both deletes rows and
+// inserts or updates rows. The input is buffered in a WITH binding, which is
+// read by a Delete operator and by an Upsert, Insert or Update operator, each
+// bound in its own WITH binding:
+//
+//   WITH
+//     input AS (<merge input>),
+//     del AS (DELETE ... FROM input WHERE action IN (...)),
+//     ups AS (UPSERT ... FROM input WHERE action IN (...))
+//   SELECT count(*) FROM (SELECT FROM del UNION ALL SELECT FROM ups)
+//
+// The statement returns the total number of deleted, inserted and updated
+// rows.
+//
+// The two mutations never modify the same row of the table, since every
+// target row is matched by at most one source row and is routed to a single
+// mutation. This is not true if the table has a self-referencing foreign key
+// with a cascading action, since the cascades of one mutation can modify rows
+// written by the other, so this shape is rejected like any other statement
+// which modifies the same table multiple times.
+func (b *Builder) buildMergeWithDelete(
+	mb *mutationBuilder,
+	whens tree.MergeWhens,
+	insertActions, updateActions, deleteActions []int,
+	canaryCol, actionCol opt.ColumnID,
+) (outScope *scope) {
+	if hasSelfReferencingCascade(mb.tab) &&
+		!multipleModificationsOfTableEnabled.Get(&b.evalCtx.Settings.SV) {
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"MERGE with both DELETE and INSERT or UPDATE clauses is not supported on table %q, "+
+				"which has a self-referencing foreign key with a cascading action; this is to "+
+				"prevent data corruption, see documentation of "+
+				"sql.multiple_modifications_of_table.enabled", mb.tab.Name(),
+		))
+	}
+
+	// Buffer the input so that the source is only read once.
+	inputID := b.factory.Memo().NextWithID()
+	b.factory.Metadata().AddWithBinding(inputID, mb.outScope.expr)
+	b.addCTE(&cteSource{
+		name: tree.AliasClause{Alias: "merge_input"},
+		cols: mb.outScope.makePresentationWithHiddenCols(),
+		expr: mb.outScope.expr,
+		id:   inputID,
+		mtr:  tree.MaterializeClause{Set: true, Materialize: true},
+	})
+
+	// Each mutation returns one (empty) row for each row that it affects. The
+	// rows are counted to determine the number of affected rows.
+	returning := tree.ReturningExprs{}
+	var mutations []memo.RelExpr
+
+	delMB, _, delActionCol := mb.buildMergeInputScan(inputID, canaryCol, actionCol)
+	delMB.buildMergeActionFilter(delActionCol, deleteActions)
+	delMB.buildDelete(returning)
+	mutations = append(mutations, b.buildMergeMutationCTE("merge_delete", delMB.outScope.expr))
+
+	writeMB, writeCanaryCol, writeActionCol := mb.buildMergeInputScan(inputID, canaryCol, actionCol)
+	writeMB.buildMergeWrite(
+		whens, insertActions, updateActions, writeCanaryCol, writeActionCol, returning,
+	)
+	mutations = append(mutations, b.buildMergeMutationCTE("merge_write", writeMB.outScope.expr))
+
+	return b.buildMergeRowCount(mutations)
+}
+
+// hasSelfReferencingCascade returns true if the table has a foreign key which
+// references the table itself with an ON DELETE or ON UPDATE action other than
+// NO ACTION or RESTRICT.
+func hasSelfReferencingCascade(tab cat.Table) bool {
+	isCascading := func(action tree.ReferenceAction) bool {
+		return action != tree.NoAction && action != tree.Restrict
+	}
+	for i, n := 0, tab.InboundForeignKeyCount(); i < n; i++ {
+		fk := tab.InboundForeignKey(i)
+		if fk.OriginTableID() != tab.ID() {
+			continue
+		}
+		if isCascading(fk.DeleteReferenceAction()) || isCascading(fk.UpdateReferenceAction()) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildMergeInputScan returns a new mutationBuilder for one of the mutations
+// built by buildMergeWithDelete. Its input is a WithScan of the buffered MERGE
+// input, and its fetch columns are remapped to the WithScan columns. The
+// remapped canary and action columns are also returned.
+func (mb *mutationBuilder) buildMergeInputScan(
+	inputID opt.WithID, canaryCol, actionCol opt.ColumnID,
+) (_ *mutationBuilder, newCanaryCol, newActionCol opt.ColumnID) {
+	newMB := &mutationBuilder{}
+	newMB.init(mb.b, mb.opName, mb.tab, mb.alias)
+
+	inCols := make(opt.ColList, len(mb.outScope.cols))
+	outCols := make(opt.ColList, len(mb.outScope.cols))
+	var colMap opt.ColMap
+	newMB.outScope = mb.b.allocScope()
+	for i := range mb.outScope.cols {
+		// Similar to appendColumnsFromScope, but with re-numbering the column
+		// IDs.
+		col := mb.outScope.cols[i]
+		inCols[i] = col.id
+		outCols[i] = mb.md.AddColumn(mb.md.ColumnMeta(col.id).Alias, col.typ)
+		colMap.Set(int(col.id), int(outCols[i]))
+		col.scalar = nil
+		col.id = outCols[i]
+		newMB.outScope.cols = append(newMB.outScope.cols, col)
+	}
+	newMB.outScope.expr = mb.b.factory.ConstructWithScan(&memo.WithScanPrivate{
+		With:    inputID,
+		Name:    "merge_input",
+		InCols:  inCols,
+		OutCols: outCols,
+		ID:      mb.md.NextUniqueID(),
+	})
+
+	remap := func(id opt.ColumnID) opt.ColumnID {
+		newID, ok := colMap.Get(int(id))
+		if !ok {
+			return 0
+		}
+		return opt.ColumnID(newID)
+	}
+
+	// The fetch scope is only used for name resolution, so it needs no
+	// expression.
+	newMB.fetchScope = mb.b.allocScope()
+	for i := range mb.fetchScope.cols {
+		col := mb.fetchScope.cols[i]
+		col.scalar = nil
+		col.id = remap(col.id)
+		newMB.fetchScope.cols = append(newMB.fetchScope.cols, col)
+	}
+	for i, id := range mb.fetchColIDs {
+		if id != 0 {
+			newMB.fetchColIDs[i] = remap(id)
+		}
+	}
+
+	return newMB, remap(canaryCol), remap(actionCol)
+}
+
+// buildMergeMutationCTE binds the given mutation expression in a WITH binding
+// and returns a WithScan of the binding.
+func (b *Builder) buildMergeMutationCTE(name tree.Name, expr memo.RelExpr) memo.RelExpr {
+	id := b.factory.Memo().NextWithID()
+	b.factory.Metadata().AddWithBinding(id, expr)
+	b.addCTE(&cteSource{
+		name: tree.AliasClause{Alias: name},
+		expr: expr,
+		id:   id,
+	})
+	return b.factory.ConstructWithScan(&memo.WithScanPrivate{
+		With: id,
+		Name: string(name),
+		ID:   b.factory.Metadata().NextUniqueID(),
+	})
+}
+
+// buildMergeRowCount returns a scope with a single row that contains the total
+// number of rows returned by the given expressions, which must have no columns
+// in common.
+func (b *Builder) buildMergeRowCount(inputs []memo.RelExpr) (outScope *scope) {
+	input := inputs[0]
+	for _, right := range inputs[1:] {
+		input = b.factory.ConstructUnionAll(input, right, &memo.SetPrivate{})
+	}
+
+	outScope = b.allocScope()
+	countCol := b.synthesizeColumn(outScope, scopeColName("count"), types.Int, nil, nil)
+	aggs := memo.AggregationsExpr{
+		b.factory.ConstructAggregationsItem(b.factory.ConstructCountRows(), countCol.id),
+	}
+	outScope.expr = b.factory.ConstructScalarGroupBy(input, aggs, &memo.GroupingPrivate{})
+	return outScope
+}
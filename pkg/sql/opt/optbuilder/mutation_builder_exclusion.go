@@ -0,0 +1,274 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+)
+
+// buildExclusionChecksForInsert builds check queries for an insert which
+// enforce the exclusion constraints of the table. It is also used for upserts,
+// since any row of an upsert may be newly inserted.
+func (mb *mutationBuilder) buildExclusionChecksForInsert() {
+	if mb.tab.ExclusionConstraintCount() == 0 {
+		return
+	}
+
+	mb.ensureWithID()
+	for i, n := 0, mb.tab.ExclusionConstraintCount(); i < n; i++ {
+		if check, ok := mb.buildExclusionCheck(i); ok {
+			mb.uniqueChecks = append(mb.uniqueChecks, check)
+		}
+	}
+}
+
+// buildExclusionChecksForUpdate builds check queries for an update which
+// enforce the exclusion constraints of the table. Constraints which do not
+// reference any of the updated columns do not need a check.
+func (mb *mutationBuilder) buildExclusionChecksForUpdate() {
+	if mb.tab.ExclusionConstraintCount() == 0 {
+		return
+	}
+
+	mb.ensureWithID()
+	for i, n := 0, mb.tab.ExclusionConstraintCount(); i < n; i++ {
+		if !mb.exclusionColsUpdated(i) {
+			continue
+		}
+		// Like the uniqueness checks, the check simply verifies that the newly
+		// inserted or updated rows do not conflict with any other row, so it
+		// works for updates as well.
+		if check, ok := mb.buildExclusionCheck(i); ok {
+			mb.uniqueChecks = append(mb.uniqueChecks, check)
+		}
+	}
+}
+
+// exclusionColsUpdated returns true if any of the columns of an exclusion
+// constraint, or any of the columns referenced by its partial predicate, are
+// being updated (according to updateColIDs).
+func (mb *mutationBuilder) exclusionColsUpdated(exclusionOrdinal int) bool {
+	ec := mb.tab.ExclusionConstraint(exclusionOrdinal)
+
+	for i, n := 0, ec.ElementCount(); i < n; i++ {
+		for j, m := 0, ec.ElementColumnCount(i); j < m; j++ {
+			if ord := ec.ElementColumnOrdinal(mb.tab, i, j); mb.updateColIDs[ord] != 0 {
+				return true
+			}
+		}
+	}
+
+	if predStr, isPartial := ec.Predicate(); isPartial {
+		pred, err := parser.ParseExpr(predStr)
+		if err != nil {
+			panic(err)
+		}
+		typedPred := mb.fetchScope.resolveAndRequireType(pred, types.Bool)
+
+		var predCols opt.ColSet
+		mb.b.buildScalar(typedPred, mb.fetchScope, nil, nil, &predCols)
+		for colID, ok := predCols.Next(0); ok; colID, ok = predCols.Next(colID + 1) {
+			ord := mb.md.ColumnMeta(colID).Table.ColumnOrdinal(colID)
+			if mb.updateColIDs[ord] != 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// buildExclusionCheck builds a check query for the given exclusion constraint.
+// The check is a self semi-join, with the new values on the left and the
+// existing values on the right, which returns the new rows that conflict with
+// another row on every element of the constraint. For example, for:
+//
+//   EXCLUDE USING gist (room WITH =, tstzrange(s, e) WITH &&)
+//
+// the semi-join filters are:
+//
+//   new.room = existing.room AND
+//   (new.s IS NULL OR existing.e IS NULL OR new.s < existing.e) AND
+//   (existing.s IS NULL OR new.e IS NULL OR existing.s < new.e) AND
+//   (new.s IS NULL OR new.e IS NULL OR new.s < new.e) AND
+//   (existing.s IS NULL OR existing.e IS NULL OR existing.s < existing.e) AND
+//   new.pk != existing.pk
+//
+// The last two range filters exclude empty ranges, which never overlap.
+//
+// Returns false if no check is needed.
+func (mb *mutationBuilder) buildExclusionCheck(
+	exclusionOrdinal int,
+) (memo.UniqueChecksItem, bool) {
+	f := mb.b.factory
+	ec := mb.tab.ExclusionConstraint(exclusionOrdinal)
+
+	var eqOrds util.FastIntSet
+	for i, n := 0, ec.ElementCount(); i < n; i++ {
+		if ec.ElementOperator(i) == cat.ExclusionEq {
+			ord := ec.ElementColumnOrdinal(mb.tab, i, 0)
+			eqOrds.Add(ord)
+			// If we are setting NULL values for one of the equality columns, like
+			// when this mutation is the result of a SET NULL cascade action, the
+			// new rows cannot conflict with any other row.
+			if memo.OutputColumnIsAlwaysNull(mb.outScope.expr, mb.mapToReturnColID(ord)) {
+				return memo.UniqueChecksItem{}, false
+			}
+		}
+	}
+
+	// Find the primary key columns that are not compared with =. If there
+	// aren't any, two different rows can never conflict, so we don't need a
+	// check.
+	primaryOrds := getIndexLaxKeyOrdinals(mb.tab.Index(cat.PrimaryIndex))
+	primaryOrds.DifferenceWith(eqOrds)
+	if primaryOrds.Empty() {
+		return memo.UniqueChecksItem{}, false
+	}
+
+	scanScope, scanOrdinals := mb.buildCheckTableScan()
+	withScanScope, _ := mb.buildCheckInputScan(checkInputScanNewVals, scanOrdinals)
+
+	newVal := func(ord int) opt.ScalarExpr {
+		return f.ConstructVariable(withScanScope.cols[ord].id)
+	}
+	existingVal := func(ord int) opt.ScalarExpr {
+		return f.ConstructVariable(scanScope.cols[ord].id)
+	}
+	// lessThanOrNull builds (a IS NULL OR b IS NULL OR a < b), which is true
+	// if the start bound a is before the end bound b, where a NULL bound is
+	// unbounded.
+	lessThanOrNull := func(a, b opt.ScalarExpr) opt.ScalarExpr {
+		return f.ConstructOr(
+			f.ConstructOr(
+				f.ConstructIs(a, memo.NullSingleton),
+				f.ConstructIs(b, memo.NullSingleton),
+			),
+			f.ConstructLt(a, b),
+		)
+	}
+
+	var semiJoinFilters memo.FiltersExpr
+	keyCols := make(opt.ColList, 0, ec.ElementCount())
+	for i, n := 0, ec.ElementCount(); i < n; i++ {
+		switch {
+		case ec.ElementOperator(i) == cat.ExclusionEq:
+			ord := ec.ElementColumnOrdinal(mb.tab, i, 0)
+			semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(
+				f.ConstructEq(newVal(ord), existingVal(ord)),
+			))
+
+		case ec.ElementColumnCount(i) == 1 && isRangeColumn(mb.tab, ec.ElementColumnOrdinal(mb.tab, i, 0)):
+			// A range or multirange column, which overlaps if the ranges share a
+			// point. Empty ranges and NULLs never overlap.
+			ord := ec.ElementColumnOrdinal(mb.tab, i, 0)
+			semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(
+				f.ConstructOverlaps(newVal(ord), existingVal(ord)),
+			))
+
+		case ec.ElementColumnCount(i) == 1:
+			// A GEOMETRY or GEOGRAPHY column, which overlaps if the shapes
+			// intersect.
+			ord := ec.ElementColumnOrdinal(mb.tab, i, 0)
+			args := memo.ScalarListExpr{newVal(ord), existingVal(ord)}
+			props, overload, ok := memo.FindFunction(&args, "st_intersects")
+			if !ok {
+				panic(errors.AssertionFailedf("could not find overload for st_intersects"))
+			}
+			semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(
+				f.ConstructFunction(args, &memo.FunctionPrivate{
+					Name:       "st_intersects",
+					Typ:        types.Bool,
+					Properties: props,
+					Overload:   overload,
+				}),
+			))
+
+		default:
+			// A range [start, end) built from two columns. Two non-empty ranges
+			// overlap if each one starts before the other one ends.
+			startOrd := ec.ElementColumnOrdinal(mb.tab, i, 0)
+			endOrd := ec.ElementColumnOrdinal(mb.tab, i, 1)
+			semiJoinFilters = append(semiJoinFilters,
+				f.ConstructFiltersItem(lessThanOrNull(newVal(startOrd), existingVal(endOrd))),
+				f.ConstructFiltersItem(lessThanOrNull(existingVal(startOrd), newVal(endOrd))),
+				f.ConstructFiltersItem(lessThanOrNull(newVal(startOrd), newVal(endOrd))),
+				f.ConstructFiltersItem(lessThanOrNull(existingVal(startOrd), existingVal(endOrd))),
+			)
+		}
+
+		// Collect the columns that will be shown in the error message if there
+		// is a violation resulting from this check.
+		for j, m := 0, ec.ElementColumnCount(i); j < m; j++ {
+			keyCols = append(keyCols, withScanScope.cols[ec.ElementColumnOrdinal(mb.tab, i, j)].id)
+		}
+	}
+
+	// If the constraint is partial, filter both the new rows and the existing
+	// rows by the predicate.
+	if predStr, isPartial := ec.Predicate(); isPartial {
+		pred, err := parser.ParseExpr(predStr)
+		if err != nil {
+			panic(err)
+		}
+
+		typedPred := withScanScope.resolveAndRequireType(pred, types.Bool)
+		withScanPred := mb.b.buildScalar(typedPred, withScanScope, nil, nil, nil)
+		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(withScanPred))
+
+		typedPred = scanScope.resolveAndRequireType(pred, types.Bool)
+		scanPred := mb.b.buildScalar(typedPred, scanScope, nil, nil, nil)
+		semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(scanPred))
+	}
+
+	// Prevent rows from matching themselves in the semi join:
+	//    (new_pk1 != existing_pk1) OR (new_pk2 != existing_pk2) OR ...
+	var pkFilter opt.ScalarExpr
+	for i, ok := primaryOrds.Next(0); ok; i, ok = primaryOrds.Next(i + 1) {
+		pkFilterLocal := f.ConstructNe(newVal(i), existingVal(i))
+		if pkFilter == nil {
+			pkFilter = pkFilterLocal
+		} else {
+			pkFilter = f.ConstructOr(pkFilter, pkFilterLocal)
+		}
+	}
+	semiJoinFilters = append(semiJoinFilters, f.ConstructFiltersItem(pkFilter))
+
+	semiJoin := f.ConstructSemiJoin(withScanScope.expr, scanScope.expr, semiJoinFilters, memo.EmptyJoinPrivate)
+
+	// Create a Project that passes-through only the key columns, so that
+	// normalization rules can prune any unnecessary columns.
+	project := f.ConstructProject(semiJoin, nil /* projections */, keyCols.ToSet())
+
+	return f.ConstructUniqueChecksItem(project, &memo.UniqueChecksItemPrivate{
+		Table:        mb.tabID,
+		CheckOrdinal: exclusionOrdinal,
+		Exclusion:    true,
+		KeyCols:      keyCols,
+		OpName:       mb.opName,
+	}), true
+}
+
+// isRangeColumn returns true if the column with the given ordinal is a range
+// or multirange column.
+func isRangeColumn(tab cat.Table, ord int) bool {
+	switch tab.Column(ord).DatumType().Family() {
+	case types.RangeFamily, types.MultirangeFamily:
+		return true
+	}
+	return false
+}
@@ -267,7 +267,7 @@ func (h *uniqueCheckHelper) init(mb *mutationBuilder, uniqueOrdinal int) bool {
 	// Build the scan that will serve as the right side of the semi join in the
 	// uniqueness check. We need to build the scan now so that we can use its
 	// FDs below.
-	h.scanScope, h.scanOrdinals = h.buildTableScan()
+	h.scanScope, h.scanOrdinals = mb.buildCheckTableScan()
 
 	// Check that the columns in the unique constraint aren't already known to
 	// form a lax key. This can happen if there is a unique index on a superset of
@@ -394,24 +394,24 @@ func (h *uniqueCheckHelper) buildInsertionCheck() memo.UniqueChecksItem {
 	})
 }
 
-// buildTableScan builds a Scan of the table. The ordinals of the columns
-// scanned are also returned.
-func (h *uniqueCheckHelper) buildTableScan() (outScope *scope, ordinals []int) {
-	tabMeta := h.mb.b.addTable(h.mb.tab, tree.NewUnqualifiedTableName(h.mb.tab.Name()))
+// buildCheckTableScan builds a Scan of the table for a uniqueness or exclusion
+// check. The ordinals of the columns scanned are also returned.
+func (mb *mutationBuilder) buildCheckTableScan() (outScope *scope, ordinals []int) {
+	tabMeta := mb.b.addTable(mb.tab, tree.NewUnqualifiedTableName(mb.tab.Name()))
 	ordinals = tableOrdinals(tabMeta.Table, columnKinds{
 		includeMutations:       false,
 		includeSystem:          false,
 		includeInverted:        false,
 		includeVirtualComputed: true,
 	})
-	return h.mb.b.buildScan(
+	return mb.b.buildScan(
 		tabMeta,
 		ordinals,
 		// After the update we can't guarantee that the constraints are unique
 		// (which is why we need the uniqueness checks in the first place).
 		&tree.IndexFlags{IgnoreUniqueWithoutIndexKeys: true},
 		noRowLocking,
-		h.mb.b.allocScope(),
+		mb.b.allocScope(),
 	), ordinals
 }
 
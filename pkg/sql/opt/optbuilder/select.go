@@ -589,6 +589,14 @@ func (b *Builder) buildScan(
 		private.Flags.NoIndexJoin = true
 		private.Flags.NoZigzagJoin = true
 	}
+	if b.PinnedIndexes != nil && indexFlags == nil && !private.Flags.NoIndexJoin {
+		// Apply the index pinned for this table and statement. Index hints in
+		// the query take precedence over the pinned indexes.
+		if idx, ok := b.PinnedIndexes.indexOrdinal(tab); ok {
+			private.Flags.ForceIndex = true
+			private.Flags.Index = idx
+		}
+	}
 
 	b.addCheckConstraintsForTable(tabMeta)
 	b.addComputedColsForTable(tabMeta)
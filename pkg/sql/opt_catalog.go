@@ -632,6 +632,8 @@ type optTable struct {
 
 	uniqueConstraints []optUniqueConstraint
 
+	exclusionConstraints []optExclusionConstraint
+
 	outboundFKs []optForeignKeyConstraint
 	inboundFKs  []optForeignKeyConstraint
 
@@ -864,6 +866,15 @@ func newOptTable(
 				})
 			}
 		}
+
+		if idx.IsExclusion() && !idx.IsMutation() {
+			ot.exclusionConstraints = append(ot.exclusionConstraints, optExclusionConstraint{
+				name:      idx.GetName(),
+				table:     ot.ID(),
+				elements:  idx.GetExclusionElements(),
+				predicate: idx.GetPredicate(),
+			})
+		}
 	}
 
 	_ = ot.desc.ForeachOutboundFK(func(fk *descpb.ForeignKeyConstraint) error {
@@ -1157,6 +1168,16 @@ func (ot *optTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	return &ot.uniqueConstraints[i]
 }
 
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (ot *optTable) ExclusionConstraintCount() int {
+	return len(ot.exclusionConstraints)
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (ot *optTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	return &ot.exclusionConstraints[i]
+}
+
 // Zone is part of the cat.Table interface.
 func (ot *optTable) Zone() cat.Zone {
 	return ot.zone
@@ -1687,6 +1708,63 @@ func (u *optUniqueConstraint) UniquenessGuaranteedByAnotherIndex() bool {
 	return u.uniquenessGuaranteedByAnotherIndex
 }
 
+// optExclusionConstraint implements cat.ExclusionConstraint and represents an
+// exclusion constraint, which is backed by an index.
+type optExclusionConstraint struct {
+	name string
+
+	table     cat.StableID
+	elements  []descpb.ExclusionElement
+	predicate string
+}
+
+var _ cat.ExclusionConstraint = &optExclusionConstraint{}
+
+// Name is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Name() string {
+	return e.name
+}
+
+// ElementCount is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ElementCount() int {
+	return len(e.elements)
+}
+
+// ElementOperator is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ElementOperator(i int) cat.ExclusionOperator {
+	switch e.elements[i].Operator {
+	case descpb.ExclusionElement_EQUAL:
+		return cat.ExclusionEq
+	case descpb.ExclusionElement_OVERLAPS:
+		return cat.ExclusionOverlaps
+	default:
+		panic(errors.AssertionFailedf("unknown exclusion operator %s", e.elements[i].Operator))
+	}
+}
+
+// ElementColumnCount is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ElementColumnCount(i int) int {
+	return len(e.elements[i].ColumnIDs)
+}
+
+// ElementColumnOrdinal is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) ElementColumnOrdinal(tab cat.Table, i, j int) int {
+	if tab.ID() != e.table {
+		panic(errors.AssertionFailedf(
+			"invalid table %d passed to ElementColumnOrdinal (expected %d)",
+			tab.ID(), e.table,
+		))
+	}
+	optTab := convertTableToOptTable(tab)
+	ord, _ := optTab.lookupColumnOrdinal(e.elements[i].ColumnIDs[j])
+	return ord
+}
+
+// Predicate is part of the cat.ExclusionConstraint interface.
+func (e *optExclusionConstraint) Predicate() (string, bool) {
+	return e.predicate, e.predicate != ""
+}
+
 // optForeignKeyConstraint implements cat.ForeignKeyConstraint and represents a
 // foreign key relationship. Both the origin and the referenced table store the
 // same optForeignKeyConstraint (as an outbound and inbound reference,
@@ -2067,6 +2145,16 @@ func (ot *optVirtualTable) Unique(i cat.UniqueOrdinal) cat.UniqueConstraint {
 	panic(errors.AssertionFailedf("no unique constraints"))
 }
 
+// ExclusionConstraintCount is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionConstraintCount() int {
+	return 0
+}
+
+// ExclusionConstraint is part of the cat.Table interface.
+func (ot *optVirtualTable) ExclusionConstraint(i int) cat.ExclusionConstraint {
+	panic(errors.AssertionFailedf("no exclusion constraints"))
+}
+
 // Zone is part of the cat.Table interface.
 func (ot *optVirtualTable) Zone() cat.Zone {
 	panic(errors.AssertionFailedf("no zone"))
@@ -55,6 +55,8 @@ func TestScanner(t *testing.T) {
 		{`;`, []int{';'}},
 		{`+`, []int{'+'}},
 		{`-`, []int{'-'}},
+		{`-|-`, []int{RANGE_ADJACENT}},
+		{`-|`, []int{'-', '|'}},
 		{`*`, []int{'*'}},
 		{`/`, []int{'/'}},
 		{`//`, []int{FLOORDIV}},
@@ -63,6 +65,7 @@ func TestScanner(t *testing.T) {
 		{`$`, []int{'$'}},
 		{`&`, []int{'&'}},
 		{`&&`, []int{AND_AND}},
+		{`@@`, []int{AT_AT}},
 		{`|`, []int{'|'}},
 		{`||`, []int{CONCAT}},
 		{`|/`, []int{SQRT}},
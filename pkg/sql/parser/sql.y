@@ -495,6 +495,12 @@ func (u *sqlSymUnion) updateExpr() *tree.UpdateExpr {
 func (u *sqlSymUnion) updateExprs() tree.UpdateExprs {
     return u.val.(tree.UpdateExprs)
 }
+func (u *sqlSymUnion) mergeWhen() *tree.MergeWhen {
+    return u.val.(*tree.MergeWhen)
+}
+func (u *sqlSymUnion) mergeWhens() tree.MergeWhens {
+    return u.val.(tree.MergeWhens)
+}
 func (u *sqlSymUnion) limit() *tree.Limit {
     return u.val.(*tree.Limit)
 }
@@ -591,6 +597,12 @@ func (u *sqlSymUnion) idxElem() tree.IndexElem {
 func (u *sqlSymUnion) idxElems() tree.IndexElemList {
     return u.val.(tree.IndexElemList)
 }
+func (u *sqlSymUnion) excludeElem() tree.ExcludeElem {
+    return u.val.(tree.ExcludeElem)
+}
+func (u *sqlSymUnion) excludeElems() tree.ExcludeElemList {
+    return u.val.(tree.ExcludeElemList)
+}
 func (u *sqlSymUnion) dropBehavior() tree.DropBehavior {
     return u.val.(tree.DropBehavior)
 }
@@ -791,7 +803,7 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 // Ordinary key words in alphabetical order.
 %token <str> ABORT ABSOLUTE ACCESS ACTION ADD ADMIN AFTER AGGREGATE
 %token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND AND_AND ANY ANNOTATE_TYPE ARRAY AS ASC
-%token <str> ASENSITIVE ASYMMETRIC AT ATTRIBUTE AUTHORIZATION AUTOMATIC AVAILABILITY
+%token <str> ASENSITIVE ASYMMETRIC AT AT_AT ATTRIBUTE AUTHORIZATION AUTOMATIC AVAILABILITY
 
 %token <str> BACKUP BACKUPS BACKWARD BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BINARY BIT
 %token <str> BUCKET_COUNT
@@ -815,10 +827,10 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %token <str> EXISTS EXECUTE EXECUTION EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT EXPERIMENTAL_RELOCATE
-%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION
+%token <str> EXPIRATION EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION EXTREMES
 
 %token <str> FAILURE FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
-%token <str> FILES FILTER
+%token <str> FILES FILTER FINGERPRINT
 %token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE FORCE_INDEX FORCE_ZIGZAG
 %token <str> FOREIGN FORWARD FROM FULL FUNCTION FUNCTIONS
 
@@ -826,15 +838,15 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %token <str> GEOMETRYCOLLECTION GEOMETRYCOLLECTIONM GEOMETRYCOLLECTIONZ GEOMETRYCOLLECTIONZM
 %token <str> GLOBAL GOAL GRANT GRANTS GREATEST GROUP GROUPING GROUPS
 
-%token <str> HAVING HASH HIGH HISTOGRAM HOLD HOUR
+%token <str> HAVING HASH HIGH HINTS HISTOGRAM HOLD HOUR
 
 %token <str> IDENTITY
 %token <str> IF IFERROR IFNULL IGNORE_FOREIGN_KEYS ILIKE IMMEDIATE IMPORT IN INCLUDE
-%token <str> INCLUDING INCREMENT INCREMENTAL INCREMENTAL_LOCATION
+%token <str> INCLUDING INCREMENT INCREMENTAL INCREMENTAL_LOCATION INCREMENTALLY
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INHERITS INJECT INITIALLY
 %token <str> INNER INSENSITIVE INSERT INT INTEGER
-%token <str> INTERSECT INTERVAL INTO INTO_DB INVERTED IS ISERROR ISNULL ISOLATION
+%token <str> INTERSECT INTERVAL INTO INTO_DB INTO_TABLE INVERTED IS ISERROR ISNULL ISOLATION
 
 %token <str> JOB JOBS JOIN JSON JSONB JSON_SOME_EXISTS JSON_ALL_EXISTS
 
@@ -845,7 +857,7 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %token <str> LINESTRING LINESTRINGM LINESTRINGZ LINESTRINGZM
 %token <str> LIST LOCAL LOCALITY LOCALTIME LOCALTIMESTAMP LOCKED LOGIN LOOKUP LOW LSHIFT
 
-%token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH
+%token <str> MATCH MATCHED MATERIALIZED MERGE MINVALUE MAXVALUE METHOD MINUTE MODIFYCLUSTERSETTING MONTH
 %token <str> MULTILINESTRING MULTILINESTRINGM MULTILINESTRINGZ MULTILINESTRINGZM
 %token <str> MULTIPOINT MULTIPOINTM MULTIPOINTZ MULTIPOINTZM
 %token <str> MULTIPOLYGON MULTIPOLYGONM MULTIPOLYGONZ MULTIPOLYGONZM
@@ -855,38 +867,38 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %token <str> NOSQLLOGIN NO_INDEX_JOIN NO_ZIGZAG_JOIN NO_FULL_SCAN NONE NONVOTERS NORMAL NOT NOTHING NOTNULL
 %token <str> NOVIEWACTIVITY NOVIEWACTIVITYREDACTED NOVIEWCLUSTERSETTING NOWAIT NULL NULLIF NULLS NUMERIC
 
-%token <str> OF OFF OFFSET OID OIDS OIDVECTOR OLD_KMS ON ONLY OPT OPTION OPTIONS OR
+%token <str> OF OFF OFFSET OID OIDS OIDVECTOR OLDER OLD_KMS ON ONLY OPT OPTION OPTIONS OR
 %token <str> ORDER ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OWNED OWNER OPERATOR
 
 %token <str> PARENT PARTIAL PARTITION PARTITIONS PASSWORD PAUSE PAUSED PHYSICAL PLACEMENT PLACING
-%token <str> PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
+%token <str> PIN PLAN PLANS POINT POINTM POINTZ POINTZM POLYGON POLYGONM POLYGONZ POLYGONZM
 %token <str> POSITION PRECEDING PRECISION PREPARE PRESERVE PRIMARY PRIOR PRIORITY PRIVILEGES
 %token <str> PROCEDURAL PUBLIC PUBLICATION
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL REASON REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
+%token <str> RANGE RANGES RANGE_ADJACENT READ REAL REASON REASSIGN RECURSIVE RECURRING REF REFERENCES REFRESH
 %token <str> REGCLASS REGION REGIONAL REGIONS REGNAMESPACE REGPROC REGPROCEDURE REGROLE REGTYPE REINDEX
 %token <str> RELATIVE RELOCATE REMOVE_PATH RENAME REPEATABLE REPLACE REPLICATION
 %token <str> RELEASE RESET RESTORE RESTRICT RESTRICTED RESUME RETURNING RETRY REVISION_HISTORY
 %token <str> REVOKE RIGHT ROLE ROLES ROLLBACK ROLLUP ROUTINES ROW ROWS RSHIFT RULE RUNNING
 
-%token <str> SAVEPOINT SCANS SCATTER SCHEDULE SCHEDULES SCROLL SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAMPLE_FRACTION SAVEPOINT SCANS SCATTER SCHEDULE SCHEDULES SCROLL SCHEMA SCHEMAS SCHEMA_ONLY SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
 %token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
 %token <str> SHARE SHOW SIMILAR SIMPLE SKIP SKIP_LOCALITIES_CHECK SKIP_MISSING_FOREIGN_KEYS
-%token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
+%token <str> SKIP_MISSING_SEQUENCES SKIP_MISSING_SEQUENCE_OWNERS SKIP_MISSING_VIEWS SMALLINT SMALLSERIAL SNAPSHOT SOME SPAN_FILTER SPLIT SQL
 %token <str> SQLLOGIN
 
-%token <str> START STATE STATISTICS STATUS STDIN STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING
+%token <str> START STATE STATEMENT STATISTICS STATUS STDIN STREAM STRICT STRING STORAGE STORE STORED STORING SUBSTRING
 %token <str> SURVIVE SURVIVAL SYMMETRIC SYNTAX SYSTEM SQRT SUBSCRIPTION STATEMENTS
 
-%token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE TEXT THEN
+%token <str> TABLE TABLES TABLESPACE TEMP TEMPLATE TEMPORARY TENANT TESTING_RELOCATE TEXT THAN THEN
 %token <str> TIES TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE
 %token <str> TRANSACTION TRANSACTIONS TRANSFER TREAT TRIGGER TRIM TRUE
 %token <str> TRUNCATE TRUSTED TYPE TYPES
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNSPLIT
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNPIN UNSPLIT
 %token <str> UPDATE UPSERT UNTIL USE USER USERS USING UUID
 
 %token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIEWACTIVITY VIEWACTIVITYREDACTED
@@ -928,6 +940,8 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %type <tree.Statement> alter_stmt
 %type <tree.Statement> alter_changefeed_stmt
 %type <tree.Statement> alter_backup_stmt
+%type <tree.Statement> alter_statement_fingerprint_stmt
+%type <tree.Statement> drop_backups_stmt
 %type <tree.Statement> alter_ddl_stmt
 %type <tree.Statement> alter_table_stmt
 %type <tree.Statement> alter_index_stmt
@@ -1062,6 +1076,7 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %type <tree.Statement> deallocate_stmt
 %type <tree.Statement> grant_stmt
 %type <tree.Statement> insert_stmt
+%type <tree.Statement> merge_stmt
 %type <tree.Statement> import_stmt
 %type <tree.Statement> pause_stmt pause_jobs_stmt pause_schedules_stmt pause_all_jobs_stmt
 %type <*tree.Select>   for_schedules_clause
@@ -1273,6 +1288,9 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %type <[]string> session_var_parts
 %type <tree.SelectExprs> target_list
 %type <tree.UpdateExprs> set_clause_list
+%type <tree.MergeWhens> merge_when_list
+%type <*tree.MergeWhen> merge_when_clause merge_matched_action merge_not_matched_action
+%type <tree.Expr> opt_merge_when_cond
 %type <*tree.UpdateExpr> set_clause multiple_set_clause
 %type <tree.ArraySubscripts> array_subscripts
 %type <tree.GroupBy> group_clause
@@ -1281,7 +1299,7 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %type <*tree.Limit> select_limit opt_select_limit
 %type <tree.TableNames> relation_expr_list
 %type <tree.ReturningClause> returning_clause
-%type <empty> opt_using_clause
+%type <tree.TableExprs> opt_using_clause
 %type <tree.RefreshDataOption> opt_clear_data
 
 %type <[]tree.SequenceOption> sequence_option_list opt_sequence_option_list
@@ -1303,7 +1321,7 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %type <types.IntervalTypeMetadata> opt_interval_qualifier interval_qualifier interval_second
 %type <tree.Expr> overlay_placing
 
-%type <bool> opt_unique opt_concurrently opt_cluster opt_without_index
+%type <bool> opt_unique opt_concurrently opt_cluster opt_without_index opt_using_extremes
 %type <bool> opt_index_access_method
 
 %type <*tree.Limit> limit_clause offset_clause opt_limit_clause
@@ -1347,6 +1365,8 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %type <bool> opt_ordinality opt_compact
 %type <*tree.Order> sortby
 %type <tree.IndexElem> index_elem index_elem_options create_as_param
+%type <tree.ExcludeElem> exclude_elem
+%type <tree.ExcludeElemList> exclude_elem_list
 %type <tree.TableExpr> table_ref numeric_table_ref func_table
 %type <tree.Exprs> rowsfrom_list
 %type <tree.Expr> rowsfrom_item
@@ -1477,7 +1497,7 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %nonassoc  '<' '>' '=' LESS_EQUALS GREATER_EQUALS NOT_EQUALS
 %nonassoc  '~' BETWEEN IN LIKE ILIKE SIMILAR NOT_REGMATCH REGIMATCH NOT_REGIMATCH NOT_LA
 %nonassoc  ESCAPE              // ESCAPE must be just above LIKE/ILIKE/SIMILAR
-%nonassoc  CONTAINS CONTAINED_BY '?' JSON_SOME_EXISTS JSON_ALL_EXISTS
+%nonassoc  CONTAINS CONTAINED_BY '?' JSON_SOME_EXISTS JSON_ALL_EXISTS AT_AT
 %nonassoc  OVERLAPS
 %left      POSTFIXOP           // dummy for postfix OP rules
 // To support target_elem without AS, we must give IDENT an explicit priority
@@ -1509,7 +1529,7 @@ func (u *sqlSymUnion) fetchCursor() *tree.FetchCursor {
 %left      '|'
 %left      '#'
 %left      '&'
-%left      LSHIFT RSHIFT INET_CONTAINS_OR_EQUALS INET_CONTAINED_BY_OR_EQUALS AND_AND SQRT CBRT
+%left      LSHIFT RSHIFT INET_CONTAINS_OR_EQUALS INET_CONTAINED_BY_OR_EQUALS AND_AND RANGE_ADJACENT SQRT CBRT
 %left      OPERATOR // if changing the last token before OPERATOR, change all instances of %prec <last token>
 %left      '+' '-'
 %left      '*' '/' FLOORDIV '%'
@@ -1575,6 +1595,7 @@ stmt:
 alter_stmt:
   alter_ddl_stmt      // help texts in sub-rule
 | alter_role_stmt     // EXTEND WITH HELP: ALTER ROLE
+| alter_statement_fingerprint_stmt // EXTEND WITH HELP: ALTER STATEMENT FINGERPRINT
 | alter_unsupported_stmt
 | ALTER error         // SHOW HELP: ALTER
 
@@ -2697,6 +2718,7 @@ alter_attribute_action:
 // %Category: Misc
 // %Text:
 // REFRESH MATERIALIZED VIEW [CONCURRENTLY] view_name [WITH [NO] DATA]
+// REFRESH MATERIALIZED VIEW [CONCURRENTLY] view_name INCREMENTALLY
 refresh_stmt:
   REFRESH MATERIALIZED VIEW opt_concurrently view_name opt_clear_data
   {
@@ -2706,6 +2728,14 @@ refresh_stmt:
       RefreshDataOption: $6.refreshDataOption(),
     }
   }
+| REFRESH MATERIALIZED VIEW opt_concurrently view_name INCREMENTALLY
+  {
+    $$.val = &tree.RefreshMaterializedView{
+      Name: $5.unresolvedObjectName(),
+      Concurrently: $4.bool(),
+      Incrementally: true,
+    }
+  }
 | REFRESH error // SHOW HELP: REFRESH
 
 opt_clear_data:
@@ -3055,6 +3085,10 @@ opt_with_schedule_options:
 //    skip_localities_check: ignore difference of zone configuration between restore cluster and backup cluster
 //    debug_pause_on: describes the events that the job should pause itself on for debugging purposes.
 //    new_db_name: renames the restored database. only applies to database restores
+//    into_table: renames the restored table. only applies to single table restores
+//    span_filter: restore only the primary index spans matching a primary key predicate
+//    schema_only: restore the schema of the backed up objects without their data
+//    sample_fraction: restore a deterministic sample of the data of each table
 // %SeeAlso: BACKUP, WEBDOCS/restore.html
 restore_stmt:
   RESTORE FROM list_of_string_or_placeholder_opt_list opt_as_of_clause opt_with_restore_options
@@ -3114,12 +3148,13 @@ restore_stmt:
       Options: *($9.restoreOptions()),
     }
   }
-| RESTORE targets FROM REPLICATION STREAM FROM string_or_placeholder_opt_list opt_as_of_clause
+| RESTORE targets FROM REPLICATION STREAM FROM string_or_placeholder_opt_list opt_as_of_clause opt_with_options
   {
    $$.val = &tree.StreamIngestion{
      Targets: $2.targetList(),
      From: $7.stringOrPlaceholderOptList(),
      AsOf: $8.asOfClause(),
+     Options: $9.kvOptions(),
    }
   }
 | RESTORE error // SHOW HELP: RESTORE
@@ -3218,6 +3253,22 @@ restore_options:
   {
     $$.val = &tree.RestoreOptions{NewDBName: $3.expr()}
   }
+| INTO_TABLE '=' string_or_placeholder
+  {
+    $$.val = &tree.RestoreOptions{IntoTable: $3.expr()}
+  }
+| SPAN_FILTER '=' string_or_placeholder
+  {
+    $$.val = &tree.RestoreOptions{SpanFilter: $3.expr()}
+  }
+| SCHEMA_ONLY
+  {
+    $$.val = &tree.RestoreOptions{SchemaOnly: true}
+  }
+| SAMPLE_FRACTION '=' string_or_placeholder
+  {
+    $$.val = &tree.RestoreOptions{SampleFraction: $3.expr()}
+  }
 | INCREMENTAL_LOCATION '=' string_or_placeholder_opt_list
 	{
 		$$.val = &tree.RestoreOptions{IncrementalStorage: $3.stringOrPlaceholderOptList()}
@@ -3748,15 +3799,20 @@ create_ddl_stmt:
 // %Text:
 // CREATE STATISTICS <statisticname>
 //   [ON <colname> [, ...]]
-//   FROM <tablename> [AS OF SYSTEM TIME <expr>]
+//   FROM <tablename> [USING EXTREMES] [AS OF SYSTEM TIME <expr>]
+//
+// USING EXTREMES collects partial statistics on the values of a single
+// column that lie outside the bounds of its latest full histogram, and
+// merges them into that histogram.
 create_stats_stmt:
-  CREATE STATISTICS statistics_name opt_stats_columns FROM create_stats_target opt_create_stats_options
+  CREATE STATISTICS statistics_name opt_stats_columns FROM create_stats_target opt_using_extremes opt_create_stats_options
   {
     $$.val = &tree.CreateStats{
       Name: tree.Name($3),
       ColumnNames: $4.nameList(),
       Table: $6.tblExpr(),
-      Options: *$7.createStatsOptions(),
+      UsingExtremes: $7.bool(),
+      Options: *$8.createStatsOptions(),
     }
   }
 | CREATE STATISTICS error // SHOW HELP: CREATE STATISTICS
@@ -3771,6 +3827,16 @@ opt_stats_columns:
     $$.val = tree.NameList(nil)
   }
 
+opt_using_extremes:
+  USING EXTREMES
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
 create_stats_target:
   table_name
   {
@@ -3956,7 +4022,7 @@ replication_options:
 
 // %Help: DELETE - delete rows from a table
 // %Category: DML
-// %Text: DELETE FROM <tablename> [WHERE <expr>]
+// %Text: DELETE FROM <tablename> [USING <tables...>] [WHERE <expr>]
 //               [ORDER BY <exprs...>]
 //               [LIMIT <expr>]
 //               [RETURNING <exprs...>]
@@ -3967,6 +4033,7 @@ delete_stmt:
     $$.val = &tree.Delete{
       With: $1.with(),
       Table: $4.tblExpr(),
+      Using: $5.tblExprs(),
       Where: tree.NewWhere(tree.AstWhere, $6.expr()),
       OrderBy: $7.orderBy(),
       Limit: $8.limit(),
@@ -3976,8 +4043,14 @@ delete_stmt:
 | opt_with_clause DELETE error // SHOW HELP: DELETE
 
 opt_using_clause:
-  USING from_list { return unimplementedWithIssueDetail(sqllex, 40963, "delete using") }
-| /* EMPTY */ { }
+  USING from_list
+  {
+    $$.val = $2.tblExprs()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.TableExprs{}
+  }
 
 
 // %Help: DISCARD - reset the session to its initial state
@@ -4003,6 +4076,7 @@ drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULES
+| drop_backups_stmt  // EXTEND WITH HELP: DROP BACKUPS
 | drop_unsupported   {}
 | DROP error         // SHOW HELP: DROP
 
@@ -4328,6 +4402,7 @@ preparable_stmt:
 | explain_stmt   // EXTEND WITH HELP: EXPLAIN
 | import_stmt    // EXTEND WITH HELP: IMPORT
 | insert_stmt    // EXTEND WITH HELP: INSERT
+| merge_stmt     // EXTEND WITH HELP: MERGE
 | pause_stmt     // help texts in sub-rule
 | reset_stmt     // help texts in sub-rule
 | restore_stmt   // EXTEND WITH HELP: RESTORE
@@ -4408,6 +4483,44 @@ alter_changefeed_cmd:
     }
   }
 
+// %Help: ALTER STATEMENT FINGERPRINT - pin or unpin the indexes used by a statement
+// %Category: Misc
+// %Text:
+// ALTER STATEMENT FINGERPRINT <fingerprint_id> PIN INDEX HINTS <hints>
+// ALTER STATEMENT FINGERPRINT <fingerprint_id> PIN INDEX HINTS FROM PLAN <plan_gist>
+// ALTER STATEMENT FINGERPRINT <fingerprint_id> UNPIN INDEX HINTS
+//
+// The fingerprint ID is the hex-encoded ID shown in
+// crdb_internal.statement_statistics. The indexes given by the hints as a
+// comma-separated list of <table>@<index>, or used by the plan gist, are
+// forced when planning statements with that fingerprint. The rest of the
+// plan, such as the join order, is not pinned.
+//
+// %SeeAlso: EXPLAIN, SHOW STATEMENTS
+alter_statement_fingerprint_stmt:
+  ALTER STATEMENT FINGERPRINT string_or_placeholder PIN INDEX HINTS string_or_placeholder
+  {
+    $$.val = &tree.AlterStatementFingerprint{
+      Fingerprint: $4.expr(),
+      Hints: $8.expr(),
+    }
+  }
+| ALTER STATEMENT FINGERPRINT string_or_placeholder PIN INDEX HINTS FROM PLAN string_or_placeholder
+  {
+    $$.val = &tree.AlterStatementFingerprint{
+      Fingerprint: $4.expr(),
+      PlanGist: $10.expr(),
+    }
+  }
+| ALTER STATEMENT FINGERPRINT string_or_placeholder UNPIN INDEX HINTS
+  {
+    $$.val = &tree.AlterStatementFingerprint{
+      Fingerprint: $4.expr(),
+      Unpin: true,
+    }
+  }
+| ALTER STATEMENT error // SHOW HELP: ALTER STATEMENT FINGERPRINT
+
 // %Help: ALTER BACKUP - alter an existing backup's encryption keys
 // %Category: CCL
 // %Text:
@@ -4464,6 +4577,42 @@ backup_kms:
     }
 	}
 
+// %Help: DROP BACKUPS - remove old backups from a backup collection
+// %Category: CCL
+// %Text:
+// DROP BACKUPS IN <collection> OLDER THAN <interval>
+//        [ WITH <option> [= <value>] [, ...] ]
+//
+// Backups that are needed to restore to any time within the interval, and the
+// backup chain pointed to by the LATEST file, are always retained.
+//
+// Collection:
+//    "[scheme]://[host]/[path to backup collection]?[parameters]"
+//
+// Options:
+//    incremental_location: also remove incremental backups stored at this location
+//    dry_run: only report the backup chains that would be removed
+//
+// %SeeAlso: BACKUP, SHOW BACKUPS, CREATE SCHEDULE FOR BACKUP
+drop_backups_stmt:
+  DROP BACKUPS IN string_or_placeholder OLDER THAN a_expr opt_with_options
+  {
+    $$.val = &tree.DropBackups{
+      In: $4.expr(),
+      OlderThan: $7.expr(),
+      Options: $8.kvOptions(),
+    }
+  }
+| DROP BACKUP IN string_or_placeholder OLDER THAN a_expr opt_with_options
+  {
+    $$.val = &tree.DropBackups{
+      In: $4.expr(),
+      OlderThan: $7.expr(),
+      Options: $8.kvOptions(),
+    }
+  }
+| DROP BACKUPS error // SHOW HELP: DROP BACKUPS
+
 // %Help: PREPARE - prepare a statement for later execution
 // %Category: Misc
 // %Text: PREPARE <name> [ ( <types...> ) ] AS <query>
@@ -7562,9 +7711,40 @@ constraint_elem:
       Actions: $10.referenceActions(),
     }
   }
-| EXCLUDE USING error
+| EXCLUDE USING name '(' exclude_elem_list ')' opt_where_clause
+  {
+    switch $3 {
+      case "gist":
+      case "btree", "gin", "hash", "spgist", "brin":
+        return unimplementedWithIssueDetail(sqllex, 46657, "exclude using " + $3)
+      default:
+        sqllex.Error("unrecognized access method: " + $3)
+        return 1
+    }
+    $$.val = &tree.ExcludeConstraintTableDef{
+      Elems: $5.excludeElems(),
+      Predicate: $7.expr(),
+    }
+  }
+
+exclude_elem_list:
+  exclude_elem
+  {
+    $$.val = tree.ExcludeElemList{$1.excludeElem()}
+  }
+| exclude_elem_list ',' exclude_elem
+  {
+    $$.val = append($1.excludeElems(), $3.excludeElem())
+  }
+
+exclude_elem:
+  index_elem WITH all_op
   {
-    return unimplementedWithIssueDetail(sqllex, 46657, "add constraint exclude using")
+    op, ok := $3.op().(treecmp.ComparisonOperator)
+    if !ok {
+      return unimplementedWithIssueDetail(sqllex, 46657, "exclude using non-comparison operator")
+    }
+    $$.val = tree.ExcludeElem{IndexElem: $1.idxElem(), Operator: op}
   }
 
 
@@ -9641,6 +9821,102 @@ multiple_set_clause:
     $$.val = &tree.UpdateExpr{Tuple: true, Names: $2.nameList(), Expr: $5.expr()}
   }
 
+// %Help: MERGE - conditionally insert, update, or delete rows of a table
+// %Category: DML
+// %Text:
+// MERGE INTO <tablename> [[AS] <name>]
+//        USING <source> ON <expr>
+//        WHEN [NOT] MATCHED [AND <expr>] THEN <action> [...]
+//
+// Actions:
+//   WHEN MATCHED:
+//     UPDATE SET ... | DELETE | DO NOTHING
+//   WHEN NOT MATCHED:
+//     INSERT [( <colnames...> )] VALUES ( <exprs...> ) | INSERT DEFAULT VALUES | DO NOTHING
+//
+// For each row of the source, the first WHEN clause whose condition holds is
+// applied. A row of the table cannot be matched by more than one source row.
+// %SeeAlso: INSERT, UPSERT, UPDATE, DELETE
+merge_stmt:
+  opt_with_clause MERGE INTO table_expr_opt_alias_idx USING table_ref ON a_expr merge_when_list
+  {
+    $$.val = &tree.Merge{
+      With: $1.with(),
+      Table: $4.tblExpr(),
+      Source: $6.tblExpr(),
+      On: $8.expr(),
+      Whens: $9.mergeWhens(),
+    }
+  }
+| opt_with_clause MERGE error // SHOW HELP: MERGE
+
+merge_when_list:
+  merge_when_clause
+  {
+    $$.val = tree.MergeWhens{$1.mergeWhen()}
+  }
+| merge_when_list merge_when_clause
+  {
+    $$.val = append($1.mergeWhens(), $2.mergeWhen())
+  }
+
+merge_when_clause:
+  WHEN MATCHED opt_merge_when_cond THEN merge_matched_action
+  {
+    when := $5.mergeWhen()
+    when.Matched = true
+    when.Cond = $3.expr()
+    $$.val = when
+  }
+| WHEN NOT MATCHED opt_merge_when_cond THEN merge_not_matched_action
+  {
+    when := $6.mergeWhen()
+    when.Cond = $4.expr()
+    $$.val = when
+  }
+
+opt_merge_when_cond:
+  AND a_expr
+  {
+    $$.val = $2.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
+merge_matched_action:
+  UPDATE SET set_clause_list
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionUpdate, Exprs: $3.updateExprs()}
+  }
+| DELETE
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionDelete}
+  }
+| DO NOTHING
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionDoNothing}
+  }
+
+merge_not_matched_action:
+  INSERT VALUES '(' expr_list ')'
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionInsert, Values: $4.exprs()}
+  }
+| INSERT '(' insert_column_list ')' VALUES '(' expr_list ')'
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionInsert, Columns: $3.nameList(), Values: $7.exprs()}
+  }
+| INSERT DEFAULT VALUES
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionInsert}
+  }
+| DO NOTHING
+  {
+    $$.val = &tree.MergeWhen{Action: tree.MergeActionDoNothing}
+  }
+
 // %Help: REASSIGN OWNED BY - change ownership of all objects
 // %Category: Priv
 // %Text: REASSIGN OWNED BY {<name> | CURRENT_USER | SESSION_USER}[,...]
@@ -11626,6 +11902,10 @@ a_expr:
   {
     $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.ContainedBy), Left: $1.expr(), Right: $3.expr()}
   }
+| a_expr AT_AT a_expr
+  {
+    $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.TSMatches), Left: $1.expr(), Right: $3.expr()}
+  }
 | a_expr '=' a_expr
   {
     $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.EQ), Left: $1.expr(), Right: $3.expr()}
@@ -11674,6 +11954,10 @@ a_expr:
   {
     $$.val = &tree.FuncExpr{Func: tree.WrapFunction("inet_contains_or_equals"), Exprs: tree.Exprs{$1.expr(), $3.expr()}}
   }
+| a_expr RANGE_ADJACENT a_expr
+  {
+    $$.val = &tree.FuncExpr{Func: tree.WrapFunction("range_adjacent"), Exprs: tree.Exprs{$1.expr(), $3.expr()}}
+  }
 | a_expr LESS_EQUALS a_expr
   {
     $$.val = &tree.ComparisonExpr{Operator: treecmp.MakeComparisonOperator(treecmp.LE), Left: $1.expr(), Right: $3.expr()}
@@ -12849,6 +13133,7 @@ all_op:
 | REGIMATCH { $$.val = treecmp.MakeComparisonOperator(treecmp.RegIMatch) }
 | NOT_REGIMATCH { $$.val = treecmp.MakeComparisonOperator(treecmp.NotRegIMatch) }
 | AND_AND { $$.val = treecmp.MakeComparisonOperator(treecmp.Overlaps) }
+| AT_AT { $$.val = treecmp.MakeComparisonOperator(treecmp.TSMatches) }
 | '~' { $$.val = tree.MakeUnaryOperator(tree.UnaryComplement) }
 | SQRT { $$.val = tree.MakeUnaryOperator(tree.UnarySqrt) }
 | CBRT { $$.val = tree.MakeUnaryOperator(tree.UnaryCbrt) }
@@ -13837,9 +14122,11 @@ unreserved_keyword:
 | EXPLAIN
 | EXPORT
 | EXTENSION
+| EXTREMES
 | FAILURE
 | FILES
 | FILTER
+| FINGERPRINT
 | FIRST
 | FOLLOWING
 | FORCE
@@ -13862,6 +14149,7 @@ unreserved_keyword:
 | GROUPS
 | HASH
 | HIGH
+| HINTS
 | HISTOGRAM
 | HOLD
 | HOUR
@@ -13873,11 +14161,13 @@ unreserved_keyword:
 | INCREMENT
 | INCREMENTAL
 | INCREMENTAL_LOCATION
+| INCREMENTALLY
 | INDEXES
 | INHERITS
 | INJECT
 | INSERT
 | INTO_DB
+| INTO_TABLE
 | INVERTED
 | ISOLATION
 | JOB
@@ -13907,6 +14197,7 @@ unreserved_keyword:
 | LOOKUP
 | LOW
 | MATCH
+| MATCHED
 | MATERIALIZED
 | MAXVALUE
 | MERGE
@@ -13958,6 +14249,7 @@ unreserved_keyword:
 | OF
 | OFF
 | OIDS
+| OLDER
 | OLD_KMS
 | OPERATOR
 | OPT
@@ -13976,6 +14268,7 @@ unreserved_keyword:
 | PAUSE
 | PAUSED
 | PHYSICAL
+| PIN
 | PLACEMENT
 | PLAN
 | PLANS
@@ -14037,11 +14330,13 @@ unreserved_keyword:
 | SETTING
 | SETTINGS
 | STATUS
+| SAMPLE_FRACTION
 | SAVEPOINT
 | SCANS
 | SCATTER
 | SCHEMA
 | SCHEMAS
+| SCHEMA_ONLY
 | SCRUB
 | SEARCH
 | SECOND
@@ -14063,11 +14358,13 @@ unreserved_keyword:
 | SKIP_MISSING_SEQUENCE_OWNERS
 | SKIP_MISSING_VIEWS
 | SNAPSHOT
+| SPAN_FILTER
 | SPLIT
 | SQL
 | SQLLOGIN
 | START
 | STATE
+| STATEMENT
 | STATEMENTS
 | STATISTICS
 | STDIN
@@ -14090,6 +14387,7 @@ unreserved_keyword:
 | TENANT
 | TESTING_RELOCATE
 | TEXT
+| THAN
 | TIES
 | TRACE
 | TRANSACTION
@@ -14105,6 +14403,7 @@ unreserved_keyword:
 | UNCOMMITTED
 | UNKNOWN
 | UNLOGGED
+| UNPIN
 | UNSPLIT
 | UNTIL
 | UPDATE
@@ -780,7 +780,6 @@ var (
 
 	// Avoid unused warning for constants.
 	_ = conTypeTrigger
-	_ = conTypeExclusion
 
 	fkActionNone       = tree.NewDString("a")
 	fkActionRestrict   = tree.NewDString("r")
@@ -948,6 +947,25 @@ func populateTableConstraints(
 			}
 			condef = tree.NewDString(f.CloseAndGetString())
 
+		case descpb.ConstraintTypeExclusion:
+			oid = h.ExclusionConstraintOid(db.GetID(), scName, table.GetID(), con.Index.ID)
+			contype = conTypeExclusion
+			conindid = h.IndexOid(table.GetID(), con.Index.ID)
+			var colIDs descpb.ColumnIDs
+			for _, elem := range con.Index.ExclusionElements {
+				colIDs = append(colIDs, elem.ColumnIDs...)
+			}
+			if conkey, err = colIDArrayToDatum(colIDs); err != nil {
+				return err
+			}
+			f := tree.NewFmtCtx(tree.FmtPGCatalog)
+			if err := catformat.FormatExclusionConstraint(
+				ctx, table, con.Index, f, p.SemaCtx(), p.SessionData(),
+			); err != nil {
+				return err
+			}
+			condef = tree.NewDString(f.CloseAndGetString())
+
 		case descpb.ConstraintTypeCheck:
 			oid = h.CheckConstraintOid(db.GetID(), scName, table.GetID(), con.CheckConstraint)
 			contype = conTypeCheck
@@ -2737,17 +2755,17 @@ https://www.postgresql.org/docs/9.5/catalog-pg-trigger.html`,
 }
 
 var (
-	typTypeBase      = tree.NewDString("b")
-	typTypeComposite = tree.NewDString("c")
-	typTypeDomain    = tree.NewDString("d")
-	typTypeEnum      = tree.NewDString("e")
-	typTypePseudo    = tree.NewDString("p")
-	typTypeRange     = tree.NewDString("r")
+	typTypeBase       = tree.NewDString("b")
+	typTypeComposite  = tree.NewDString("c")
+	typTypeDomain     = tree.NewDString("d")
+	typTypeEnum       = tree.NewDString("e")
+	typTypeMultirange = tree.NewDString("m")
+	typTypePseudo     = tree.NewDString("p")
+	typTypeRange      = tree.NewDString("r")
 
 	// Avoid unused warning for constants.
 	_ = typTypeDomain
 	_ = typTypePseudo
-	_ = typTypeRange
 
 	// See https://www.postgresql.org/docs/9.6/static/catalog-pg-type.html#CATALOG-TYPCATEGORY-TABLE.
 	typCategoryArray       = tree.NewDString("A")
@@ -2769,7 +2787,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typCategoryEnum
 	_ = typCategoryGeometric
-	_ = typCategoryRange
 	_ = typCategoryBitString
 
 	typDelim = tree.NewDString(",")
@@ -2870,6 +2887,12 @@ func addPGTypeRow(
 		builtinPrefix = "enum_"
 		typType = typTypeEnum
 	}
+	if typ.Family() == types.RangeFamily {
+		typType = typTypeRange
+	}
+	if typ.Family() == types.MultirangeFamily {
+		typType = typTypeMultirange
+	}
 	if cat == typCategoryPseudo {
 		typType = typTypePseudo
 	}
@@ -4046,6 +4069,8 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.GeographyFamily:   typCategoryUserDefined,
 	types.GeometryFamily:    typCategoryUserDefined,
 	types.JsonFamily:        typCategoryUserDefined,
+	types.TSQueryFamily:     typCategoryUserDefined,
+	types.TSVectorFamily:    typCategoryUserDefined,
 	types.DecimalFamily:     typCategoryNumeric,
 	types.StringFamily:      typCategoryString,
 	types.TimestampFamily:   typCategoryDateTime,
@@ -4053,6 +4078,8 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.ArrayFamily:       typCategoryArray,
 	types.TupleFamily:       typCategoryPseudo,
 	types.OidFamily:         typCategoryNumeric,
+	types.RangeFamily:       typCategoryRange,
+	types.MultirangeFamily:  typCategoryRange,
 	types.UuidFamily:        typCategoryUserDefined,
 	types.INetFamily:        typCategoryNetworkAddr,
 	types.UnknownFamily:     typCategoryUnknown,
@@ -4276,6 +4303,7 @@ const (
 	enumEntryTypeTag
 	rewriteTypeTag
 	dbSchemaRoleTypeTag
+	exclusionConstraintTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -4395,6 +4423,17 @@ func (h oidHasher) UniqueConstraintOid(
 	return h.getOid()
 }
 
+func (h oidHasher) ExclusionConstraintOid(
+	dbID descpb.ID, scName string, tableID descpb.ID, indexID descpb.IndexID,
+) *tree.DOid {
+	h.writeTypeTag(exclusionConstraintTypeTag)
+	h.writeDB(dbID)
+	h.writeSchema(scName)
+	h.writeTable(tableID)
+	h.writeIndex(indexID)
+	return h.getOid()
+}
+
 func (h oidHasher) BuiltinOid(name string, builtin *tree.Overload) *tree.DOid {
 	h.writeTypeTag(functionTypeTag)
 	h.writeStr(name)
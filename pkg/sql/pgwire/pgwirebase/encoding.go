@@ -495,6 +495,30 @@ func DecodeDatum(
 				return nil, err
 			}
 			return tree.ParseDJSON(string(b))
+		case oid.T_tsquery:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return tree.ParseDTSQuery(string(b))
+		case oid.T_tsvector:
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			return tree.ParseDTSVector(string(b))
+		}
+		if t.Family() == types.RangeFamily {
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			d, _, err := tree.ParseDRange(evalCtx, string(b), t)
+			return d, err
+		}
+		if t.Family() == types.MultirangeFamily {
+			if err := validateStringBytes(b); err != nil {
+				return nil, err
+			}
+			d, _, err := tree.ParseDMultirange(evalCtx, string(b), t)
+			return d, err
 		}
 		if t.Family() == types.ArrayFamily {
 			// Arrays come in in their string form, so we parse them as such and later
@@ -781,6 +805,12 @@ func DecodeDatum(
 			if t.Family() == types.ArrayFamily {
 				return decodeBinaryArray(evalCtx, t.ArrayContents(), b, code)
 			}
+			if t.Family() == types.RangeFamily {
+				return decodeBinaryRange(evalCtx, t, b)
+			}
+			if t.Family() == types.MultirangeFamily {
+				return decodeBinaryMultirange(evalCtx, t, b)
+			}
 		}
 	default:
 		return nil, errors.AssertionFailedf(
@@ -1037,6 +1067,87 @@ func decodeBinaryTuple(evalCtx *tree.EvalContext, t *types.T, b []byte) (tree.Da
 
 }
 
+// decodeBinaryRange decodes the binary representation of a range, which is a
+// flags byte followed by the length-prefixed binary representation of each
+// bound which is not unbounded.
+func decodeBinaryRange(evalCtx *tree.EvalContext, t *types.T, b []byte) (tree.Datum, error) {
+	if len(b) < 1 {
+		return nil, NewProtocolViolationErrorf("no data to decode")
+	}
+	flags := b[0]
+	b = b[1:]
+	if flags&PGBinaryRangeEmpty != 0 {
+		return tree.NewDEmptyRange(t), nil
+	}
+	decodeBound := func(inf bool) (tree.Datum, error) {
+		if inf {
+			return tree.DNull, nil
+		}
+		if len(b) < 4 {
+			return nil, NewProtocolViolationErrorf("insufficient data: %d", len(b))
+		}
+		vlen := int32(binary.BigEndian.Uint32(b))
+		b = b[4:]
+		if vlen < 0 || int(vlen) > len(b) {
+			return nil, NewInvalidBinaryRepresentationErrorf("incorrect binary data")
+		}
+		elem, err := DecodeDatum(evalCtx, t.RangeContents(), FormatBinary, b[:vlen])
+		b = b[vlen:]
+		return elem, err
+	}
+	lower, err := decodeBound(flags&PGBinaryRangeLowerInf != 0)
+	if err != nil {
+		return nil, err
+	}
+	upper, err := decodeBound(flags&PGBinaryRangeUpperInf != 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 0 {
+		return nil, NewInvalidBinaryRepresentationErrorf("incorrect binary data")
+	}
+	return tree.NewDRange(
+		t, lower, upper, flags&PGBinaryRangeLowerInc != 0, flags&PGBinaryRangeUpperInc != 0,
+	)
+}
+
+// decodeBinaryMultirange decodes the binary representation of a multirange,
+// which is the number of ranges followed by the length-prefixed binary
+// representation of each range.
+func decodeBinaryMultirange(
+	evalCtx *tree.EvalContext, t *types.T, b []byte,
+) (tree.Datum, error) {
+	if len(b) < 4 {
+		return nil, NewProtocolViolationErrorf("insufficient data: %d", len(b))
+	}
+	numRanges := int32(binary.BigEndian.Uint32(b))
+	b = b[4:]
+	if numRanges < 0 {
+		return nil, NewInvalidBinaryRepresentationErrorf("incorrect binary data")
+	}
+	var ranges []*tree.DRange
+	for i := int32(0); i < numRanges; i++ {
+		if len(b) < 4 {
+			return nil, NewProtocolViolationErrorf("insufficient data: %d", len(b))
+		}
+		vlen := int32(binary.BigEndian.Uint32(b))
+		b = b[4:]
+		if vlen < 0 || int(vlen) > len(b) {
+			return nil, NewInvalidBinaryRepresentationErrorf("incorrect binary data")
+		}
+		r, err := decodeBinaryRange(evalCtx, t.MultirangeContents(), b[:vlen])
+		if err != nil {
+			return nil, err
+		}
+		b = b[vlen:]
+		ranges = append(ranges, r.(*tree.DRange))
+	}
+	if len(b) != 0 {
+		return nil, NewInvalidBinaryRepresentationErrorf("incorrect binary data")
+	}
+	return tree.NewDMultirange(t, ranges...), nil
+}
+
 var invalidUTF8Error = pgerror.Newf(pgcode.CharacterNotInRepertoire, "invalid UTF-8 sequence")
 
 var (
@@ -1052,3 +1163,18 @@ const (
 	// AF_NET + 1.
 	PGBinaryIPv6family byte = 3
 )
+
+// The flags of the binary representation of a range, which are the same as in
+// Postgres.
+const (
+	// PGBinaryRangeEmpty is set if the range is empty.
+	PGBinaryRangeEmpty byte = 0x01
+	// PGBinaryRangeLowerInc is set if the lower bound is inclusive.
+	PGBinaryRangeLowerInc byte = 0x02
+	// PGBinaryRangeUpperInc is set if the upper bound is inclusive.
+	PGBinaryRangeUpperInc byte = 0x04
+	// PGBinaryRangeLowerInf is set if there is no lower bound.
+	PGBinaryRangeLowerInf byte = 0x08
+	// PGBinaryRangeUpperInf is set if there is no upper bound.
+	PGBinaryRangeUpperInf byte = 0x10
+)
@@ -242,6 +242,20 @@ func writeTextDatumNotNull(
 	case *tree.DJSON:
 		b.writeLengthPrefixedString(v.JSON.String())
 
+	case *tree.DTSQuery:
+		b.writeLengthPrefixedString(v.TSQuery.String())
+
+	case *tree.DTSVector:
+		b.writeLengthPrefixedString(v.TSVector.String())
+
+	case *tree.DRange:
+		b.textFormatter.FormatNode(v)
+		b.writeFromFmtCtx(b.textFormatter)
+
+	case *tree.DMultirange:
+		b.textFormatter.FormatNode(v)
+		b.writeFromFmtCtx(b.textFormatter)
+
 	case *tree.DTuple:
 		b.textFormatter.FormatNode(v)
 		b.writeFromFmtCtx(b.textFormatter)
@@ -687,6 +701,58 @@ func writeBinaryDatumNotNull(
 		lengthToWrite := b.Len() - (initialLen + 4)
 		b.putInt32AtIndex(initialLen /* index to write at */, int32(lengthToWrite))
 
+	case *tree.DRange:
+		initialLen := b.Len()
+
+		// Reserve bytes for writing length later.
+		b.putInt32(int32(0))
+
+		var flags byte
+		if v.Empty {
+			flags |= pgwirebase.PGBinaryRangeEmpty
+		} else {
+			if v.LowerInc {
+				flags |= pgwirebase.PGBinaryRangeLowerInc
+			}
+			if v.UpperInc {
+				flags |= pgwirebase.PGBinaryRangeUpperInc
+			}
+			if v.Lower == tree.DNull {
+				flags |= pgwirebase.PGBinaryRangeLowerInf
+			}
+			if v.Upper == tree.DNull {
+				flags |= pgwirebase.PGBinaryRangeUpperInf
+			}
+		}
+		b.writeByte(flags)
+		if !v.Empty {
+			// Each bound which is not unbounded is written with its length prefix.
+			for _, bound := range []tree.Datum{v.Lower, v.Upper} {
+				if bound != tree.DNull {
+					b.writeBinaryDatum(ctx, bound, sessionLoc, v.ResolvedType().RangeContents())
+				}
+			}
+		}
+
+		lengthToWrite := b.Len() - (initialLen + 4)
+		b.putInt32AtIndex(initialLen /* index to write at */, int32(lengthToWrite))
+
+	case *tree.DMultirange:
+		initialLen := b.Len()
+
+		// Reserve bytes for writing length later.
+		b.putInt32(int32(0))
+
+		// The number of ranges is followed by the length-prefixed binary
+		// representation of each range.
+		b.putInt32(int32(len(v.Ranges)))
+		for _, r := range v.Ranges {
+			b.writeBinaryDatum(ctx, r, sessionLoc, v.ResolvedType().MultirangeContents())
+		}
+
+		lengthToWrite := b.Len() - (initialLen + 4)
+		b.putInt32AtIndex(initialLen /* index to write at */, int32(lengthToWrite))
+
 	case *tree.DVoid:
 		b.putInt32(0)
 
@@ -310,6 +310,10 @@ type planTop struct {
 	// current statement is causing an auditing event. See exec_log.go.
 	auditEvents []auditEvent
 
+	// accessedTables records the tables used by the current statement
+	// when audit rules are configured. See audit_rules.go.
+	accessedTables []auditEvent
+
 	// flags is populated during planning and execution.
 	flags planFlags
 
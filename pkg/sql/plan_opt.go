@@ -14,11 +14,13 @@ import (
 	"context"
 	"strings"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/colinfo"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/execbuilder"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/explain"
@@ -99,7 +101,7 @@ func (p *planner) prepareUsingOptimizer(ctx context.Context) (planFlags, error)
 			if !pm.TypeHints.Identical(p.semaCtx.Placeholders.TypeHints) {
 				opc.log(ctx, "query cache hit but type hints don't match")
 			} else {
-				isStale, err := cachedData.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog)
+				isStale, err := opc.memoIsStale(ctx, cachedData.Memo)
 				if err != nil {
 					return 0, err
 				}
@@ -306,13 +308,13 @@ func (opc *optPlanningCtx) reset() {
 	opc.optimizer.Init(p.EvalContext(), &opc.catalog)
 	opc.flags = 0
 
-	// We only allow memo caching for SELECT/INSERT/UPDATE/DELETE. We could
+	// We only allow memo caching for SELECT/INSERT/UPDATE/DELETE/MERGE. We could
 	// support it for all statements in principle, but it would increase the
 	// surface of potential issues (conditions we need to detect to invalidate a
 	// cached memo).
 	switch p.stmt.AST.(type) {
 	case *tree.ParenSelect, *tree.Select, *tree.SelectClause, *tree.UnionClause, *tree.ValuesClause,
-		*tree.Insert, *tree.Update, *tree.Delete, *tree.CannedOptPlan:
+		*tree.Insert, *tree.Update, *tree.Delete, *tree.Merge, *tree.CannedOptPlan:
 		// If the current transaction has uncommitted DDL statements, we cannot rely
 		// on descriptor versions for detecting a "stale" memo. This is because
 		// descriptor versions are bumped at most once per transaction, even if there
@@ -334,6 +336,56 @@ func (opc *optPlanningCtx) reset() {
 	}
 }
 
+// pinnedIndexes returns the indexes pinned for the statement's fingerprint
+// with ALTER STATEMENT FINGERPRINT ... PIN INDEX HINTS, if any, and records
+// the version of the pins in the given memo, which is about to be built. The
+// statement wrapped by an EXPLAIN uses the pins of that statement.
+func (opc *optPlanningCtx) pinnedIndexes(m *memo.Memo) optbuilder.PinnedIndexes {
+	p := opc.p
+	registry := p.execCfg.PlanPinRegistry
+	if registry == nil {
+		return nil
+	}
+	// Record the version before looking up the pins: if they change
+	// concurrently, the memo is conservatively considered stale.
+	m.SetPinnedIndexesVersion(registry.Version())
+	if registry.Empty() {
+		return nil
+	}
+	stmtNoConstants := p.stmt.StmtNoConstants
+	switch t := p.stmt.AST.(type) {
+	case *tree.Explain:
+		stmtNoConstants = formatStatementHideConstants(t.Statement)
+	case *tree.ExplainAnalyze:
+		stmtNoConstants = formatStatementHideConstants(t.Statement)
+	}
+	id := roachpb.ConstructStatementFingerprintID(
+		stmtNoConstants, false /* failed */, p.extendedEvalCtx.TxnImplicit, p.SessionData().Database,
+	)
+	indexes := registry.Lookup(id)
+	if len(indexes) == 0 {
+		return nil
+	}
+	res := make(optbuilder.PinnedIndexes, len(indexes))
+	for table, index := range indexes {
+		res[cat.StableID(table)] = cat.StableID(index)
+	}
+	return res
+}
+
+// memoIsStale returns whether the given cached memo is stale (see
+// memo.Memo.IsStale), or whether the pinned indexes may have changed since it
+// was built. Any change to the pinned indexes invalidates all cached memos,
+// which avoids computing statement fingerprints when reusing memos; pins are
+// expected to change rarely.
+func (opc *optPlanningCtx) memoIsStale(ctx context.Context, m *memo.Memo) (bool, error) {
+	registry := opc.p.execCfg.PlanPinRegistry
+	if registry != nil && registry.Version() != m.PinnedIndexesVersion() {
+		return true, nil
+	}
+	return m.IsStale(ctx, opc.p.EvalContext(), &opc.catalog)
+}
+
 func (opc *optPlanningCtx) log(ctx context.Context, msg string) {
 	if log.VDepth(1, 1) {
 		log.InfofDepth(ctx, 1, "%s: %s", log.Safe(msg), opc.p.stmt)
@@ -379,6 +431,7 @@ func (opc *optPlanningCtx) buildReusableMemo(ctx context.Context) (_ *memo.Memo,
 	f := opc.optimizer.Factory()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
 	bld.KeepPlaceholders = true
+	bld.PinnedIndexes = opc.pinnedIndexes(f.Memo())
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -457,6 +510,96 @@ func (opc *optPlanningCtx) reuseMemo(cachedMemo *memo.Memo) (*memo.Memo, error)
 	return f.Memo(), nil
 }
 
+// numCustomPlansBeforeGeneric is the number of executions of a prepared
+// statement that use custom plans before the generic plan is considered with
+// plan_cache_mode=auto. This matches Postgres.
+const numCustomPlansBeforeGeneric = 5
+
+// buildPreparedExecMemo returns a fully optimized memo for the execution of a
+// prepared statement with placeholders. Depending on the plan_cache_mode
+// session setting, it is either a custom memo, which is optimized for the
+// placeholder values of the current execution, or the generic memo, which is
+// optimized once without knowing the placeholder values and reused for all
+// executions.
+//
+// With plan_cache_mode=auto, custom plans are used for the first few
+// executions. After that, the generic plan is used if its estimated cost is no
+// worse than the average estimated cost of the custom plans; otherwise,
+// another custom plan is built.
+func (opc *optPlanningCtx) buildPreparedExecMemo(
+	ctx context.Context, prepared *PreparedStatement,
+) (*memo.Memo, error) {
+	switch opc.p.SessionData().PlanCacheMode {
+	case sessiondatapb.PlanCacheModeForceGeneric:
+		return opc.buildGenericMemo(ctx, prepared)
+
+	case sessiondatapb.PlanCacheModeAuto:
+		if prepared.numCustomPlans >= numCustomPlansBeforeGeneric {
+			generic, err := opc.buildGenericMemo(ctx, prepared)
+			if err != nil {
+				return nil, err
+			}
+			genericCost := generic.RootExpr().(memo.RelExpr).Cost()
+			avgCustomCost := prepared.customPlansCost / memo.Cost(prepared.numCustomPlans)
+			if !avgCustomCost.Less(genericCost) {
+				opc.log(ctx, "using generic plan")
+				return generic, nil
+			}
+			opc.log(ctx, "generic plan is more expensive than custom plans")
+		}
+		custom, err := opc.reuseMemo(prepared.Memo)
+		if err != nil {
+			return nil, err
+		}
+		prepared.numCustomPlans++
+		prepared.customPlansCost += custom.RootExpr().(memo.RelExpr).Cost()
+		return custom, nil
+
+	default:
+		return opc.reuseMemo(prepared.Memo)
+	}
+}
+
+// buildGenericMemo returns the fully optimized generic memo of the prepared
+// statement, building it if it doesn't exist yet or if it is stale. The
+// generic memo is stored in the prepared statement; like prepared.Memo, it is
+// not modified once built.
+func (opc *optPlanningCtx) buildGenericMemo(
+	ctx context.Context, prepared *PreparedStatement,
+) (*memo.Memo, error) {
+	p := opc.p
+	if prepared.GenericMemo != nil {
+		isStale, err := prepared.GenericMemo.IsStale(ctx, p.EvalContext(), &opc.catalog)
+		if err != nil {
+			return nil, err
+		}
+		if !isStale {
+			opc.log(ctx, "reusing generic memo")
+			return prepared.GenericMemo, nil
+		}
+		prepared.resetGenericMemo(ctx)
+	}
+
+	// Stable operators are not folded, since the generic memo is reused across
+	// executions.
+	f := opc.optimizer.Factory()
+	if err := f.ParameterizePlaceholders(prepared.Memo); err != nil {
+		return nil, err
+	}
+	if _, err := opc.optimizer.Optimize(); err != nil {
+		return nil, err
+	}
+	// Detach the generic memo from the factory and transfer its ownership to
+	// the prepared statement.
+	generic := opc.optimizer.DetachMemo()
+	if err := prepared.memAcc.Grow(ctx, generic.MemoryEstimate()); err != nil {
+		return nil, err
+	}
+	prepared.GenericMemo = generic
+	opc.log(ctx, "built generic memo")
+	return generic, nil
+}
+
 // buildExecMemo creates a fully optimized memo, possibly reusing a previously
 // cached memo as a starting point.
 //
@@ -471,7 +614,7 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 
 		// If the prepared memo has been invalidated by schema or other changes,
 		// re-prepare it.
-		if isStale, err := prepared.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog); err != nil {
+		if isStale, err := opc.memoIsStale(ctx, prepared.Memo); err != nil {
 			return nil, err
 		} else if isStale {
 			prepared.Memo, err = opc.buildReusableMemo(ctx)
@@ -479,8 +622,14 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 			if err != nil {
 				return nil, err
 			}
+			// The generic plan and the costs of the custom plans were based on
+			// the stale memo.
+			prepared.resetGenericMemo(ctx)
 		}
 		opc.log(ctx, "reusing cached memo")
+		if !prepared.Memo.IsOptimized() && prepared.Memo.HasPlaceholders() {
+			return opc.buildPreparedExecMemo(ctx, prepared)
+		}
 		memo, err := opc.reuseMemo(prepared.Memo)
 		return memo, err
 	}
@@ -489,7 +638,7 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 		// Consult the query cache.
 		cachedData, ok := p.execCfg.QueryCache.Find(&p.queryCacheSession, opc.p.stmt.SQL)
 		if ok {
-			if isStale, err := cachedData.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog); err != nil {
+			if isStale, err := opc.memoIsStale(ctx, cachedData.Memo); err != nil {
 				return nil, err
 			} else if isStale {
 				cachedData.Memo, err = opc.buildReusableMemo(ctx)
@@ -520,6 +669,7 @@ func (opc *optPlanningCtx) buildExecMemo(ctx context.Context) (_ *memo.Memo, _ e
 	f := opc.optimizer.Factory()
 	f.FoldingControl().AllowStableFolds()
 	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	bld.PinnedIndexes = opc.pinnedIndexes(f.Memo())
 	if err := bld.Build(); err != nil {
 		return nil, err
 	}
@@ -722,3 +872,113 @@ func (opc *optPlanningCtx) makeQueryIndexRecommendation() error {
 
 	return nil
 }
+
+// stmtIndexRecommendations is the result of makeWorkloadIndexRecommendation.
+type stmtIndexRecommendations struct {
+	recs []indexrec.Recommendation
+	// cost and costWithRecs are the estimated costs of the statement without
+	// and with the recommended indexes.
+	cost, costWithRecs memo.Cost
+	// mutatedTables are the IDs of the tables written to by the statement.
+	mutatedTables []cat.StableID
+}
+
+// makeWorkloadIndexRecommendation is used by the IndexAdvisor to find the
+// index recommendations for the statement in the planner. Unlike
+// makeQueryIndexRecommendation, it also estimates the cost of the statement
+// with the existing indexes, and it does not prepare the optimizer to build an
+// executable plan afterwards.
+func (opc *optPlanningCtx) makeWorkloadIndexRecommendation(
+	ctx context.Context,
+) (res stmtIndexRecommendations, _ error) {
+	p := opc.p
+	opc.reset()
+	f := opc.optimizer.Factory()
+	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, p.stmt.AST)
+	if err := bld.Build(); err != nil {
+		return res, err
+	}
+	savedMemo := opc.optimizer.DetachMemo()
+	res.mutatedTables = appendMutatedTables(nil, savedMemo.Metadata(), savedMemo.RootExpr())
+
+	// optimize optimizes a copy of the saved memo. If normalizeOnly is true,
+	// only normalization rules are applied.
+	optimize := func(hypTables map[cat.StableID]cat.Table, normalizeOnly bool) (memo.RelExpr, error) {
+		opc.optimizer.Init(p.EvalContext(), &opc.catalog)
+		f.FoldingControl().AllowStableFolds()
+		f.CopyAndReplace(
+			savedMemo.RootExpr().(memo.RelExpr),
+			savedMemo.RootProps(),
+			f.CopyWithoutAssigningPlaceholders,
+		)
+		if hypTables != nil {
+			opc.optimizer.Memo().Metadata().UpdateTableMeta(hypTables)
+		}
+		if normalizeOnly {
+			opc.optimizer.NotifyOnMatchedRule(func(ruleName opt.RuleName) bool {
+				return ruleName.IsNormalize()
+			})
+		}
+		root, err := opc.optimizer.Optimize()
+		if err != nil {
+			return nil, err
+		}
+		return root.(memo.RelExpr), nil
+	}
+
+	root, err := optimize(nil /* hypTables */, false /* normalizeOnly */)
+	if err != nil {
+		return res, err
+	}
+	res.cost = root.Cost()
+
+	// See makeQueryIndexRecommendation for why the candidates are determined
+	// from the fully normalized memo.
+	if _, err := optimize(nil /* hypTables */, true /* normalizeOnly */); err != nil {
+		return res, err
+	}
+	indexCandidates := indexrec.FindIndexCandidateSet(f.Memo().RootExpr(), f.Metadata())
+	_, hypTables := indexrec.BuildOptAndHypTableMaps(indexCandidates)
+
+	if root, err = optimize(hypTables, false /* normalizeOnly */); err != nil {
+		return res, err
+	}
+	res.costWithRecs = root.Cost()
+	indexRecommendations := indexrec.FindIndexRecommendationSet(f.Memo().RootExpr(), f.Metadata())
+	res.recs = indexRecommendations.Recommendations()
+	// The recommendations are merged across the statements of all databases,
+	// so the table names are fully qualified.
+	for i := range res.recs {
+		ds, _, err := opc.catalog.ResolveDataSourceByID(ctx, cat.Flags{}, res.recs[i].TableID)
+		if err != nil {
+			return res, err
+		}
+		if res.recs[i].TableName, err = opc.catalog.FullyQualifiedName(ctx, ds); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// appendMutatedTables appends the IDs of the tables written to by the given
+// expression to ids.
+func appendMutatedTables(ids []cat.StableID, md *opt.Metadata, expr opt.Expr) []cat.StableID {
+	var tabID opt.TableID
+	switch t := expr.(type) {
+	case *memo.InsertExpr:
+		tabID = t.Table
+	case *memo.UpdateExpr:
+		tabID = t.Table
+	case *memo.UpsertExpr:
+		tabID = t.Table
+	case *memo.DeleteExpr:
+		tabID = t.Table
+	}
+	if tabID != 0 {
+		ids = append(ids, md.Table(tabID).ID())
+	}
+	for i, n := 0, expr.ChildCount(); i < n; i++ {
+		ids = appendMutatedTables(ids, md, expr.Child(i))
+	}
+	return ids
+}
@@ -110,6 +110,9 @@ func (evalCtx *extendedEvalContext) copyFromExecCfg(execCfg *ExecutorConfig) {
 	evalCtx.DB = execCfg.DB
 	evalCtx.SQLLivenessReader = execCfg.SQLLiveness
 	evalCtx.CompactEngineSpan = execCfg.CompactEngineSpanFunc
+	if execCfg.StmtDiagnosticsRecorder != nil {
+		evalCtx.StmtDiagnosticsRequestInserter = execCfg.StmtDiagnosticsRecorder.InsertRequest
+	}
 	evalCtx.TestingKnobs = execCfg.EvalContextTestingKnobs
 	evalCtx.ClusterID = execCfg.ClusterID()
 	evalCtx.ClusterName = execCfg.RPCContext.ClusterName()
@@ -0,0 +1,332 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package planpin maintains the set of statement fingerprints whose index
+// choices have been pinned with ALTER STATEMENT FINGERPRINT ... PIN INDEX
+// HINTS.
+package planpin
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/tablepoller"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+)
+
+var pollingInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.plan_pins.poll_interval",
+	"rate at which the planpin.Registry polls system.statement_plan_pins, set to zero to disable",
+	10*time.Second,
+	settings.NonNegativeDuration,
+)
+
+// Indexes maps the IDs of tables to the ID of the index that must be used
+// when scanning them.
+type Indexes map[descpb.ID]descpb.IndexID
+
+// Registry maintains a view on system.statement_plan_pins and provides a fast
+// lookup of the indexes pinned for a statement fingerprint during planning.
+type Registry struct {
+	mu struct {
+		// NOTE: This lock can't be held while the registry runs any statements
+		// internally; it'd deadlock.
+		syncutil.RWMutex
+		pins map[roachpb.StmtFingerprintID]Indexes
+		// version is incremented whenever pins changes. It allows planning to
+		// detect cached memos that were built with outdated pins without
+		// computing the fingerprints of their statements.
+		version uint64
+	}
+	st     *cluster.Settings
+	ie     sqlutil.InternalExecutor
+	poller *tablepoller.Poller
+}
+
+// NewRegistry constructs a new Registry.
+func NewRegistry(ie sqlutil.InternalExecutor, st *cluster.Settings) *Registry {
+	r := &Registry{
+		ie: ie,
+		st: st,
+	}
+	r.poller = tablepoller.New(tablepoller.Config{
+		OpName:      "plan-pin-poll",
+		Description: "statement plan pins",
+		Interval:    pollingInterval,
+		Settings:    st,
+		IE:          ie,
+		Mu:          &r.mu,
+		Poll:        r.pollPins,
+	})
+	return r
+}
+
+// Start will start the polling loop for the Registry.
+func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	r.poller.Start(ctx, stopper)
+}
+
+// Version returns the current version of the pins. It changes whenever pins
+// are added, changed or removed.
+func (r *Registry) Version() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mu.version
+}
+
+// Empty returns whether no indexes are pinned. It allows callers to avoid
+// computing statement fingerprints in the common case.
+func (r *Registry) Empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.mu.pins) == 0
+}
+
+// Lookup returns the indexes pinned for the given statement fingerprint, if
+// any.
+func (r *Registry) Lookup(id roachpb.StmtFingerprintID) Indexes {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mu.pins[id]
+}
+
+// Pin pins the given indexes for the statement fingerprint, replacing any
+// existing pin. planGist is the plan gist the indexes were taken from, if
+// any; it is only recorded for reference.
+func (r *Registry) Pin(
+	ctx context.Context, id roachpb.StmtFingerprintID, indexes Indexes, planGist string,
+) error {
+	if err := r.checkVersion(ctx); err != nil {
+		return err
+	}
+	if len(indexes) == 0 {
+		return errors.AssertionFailedf("no indexes to pin")
+	}
+	var gist interface{}
+	if planGist != "" {
+		gist = planGist
+	}
+	if _, err := r.ie.ExecEx(ctx, "plan-pin-upsert", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"UPSERT INTO system.statement_plan_pins (fingerprint_id, plan_gist, hints, created_at) "+
+			"VALUES ($1, $2, $3, now())",
+		tree.NewDBytes(tree.DBytes(encodeFingerprintID(id))), gist, formatIndexes(indexes),
+	); err != nil {
+		return err
+	}
+	// Manually update the local registry so that the pin takes effect on this
+	// node right away, without waiting for the poller.
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.poller.BumpEpochLocked()
+	if r.mu.pins == nil {
+		r.mu.pins = make(map[roachpb.StmtFingerprintID]Indexes)
+	}
+	r.mu.pins[id] = indexes
+	r.mu.version++
+	return nil
+}
+
+// Unpin removes the pin for the given statement fingerprint. It returns an
+// error if there is no such pin.
+func (r *Registry) Unpin(ctx context.Context, id roachpb.StmtFingerprintID) error {
+	if err := r.checkVersion(ctx); err != nil {
+		return err
+	}
+	n, err := r.ie.ExecEx(ctx, "plan-pin-delete", nil, /* txn */
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		"DELETE FROM system.statement_plan_pins WHERE fingerprint_id = $1",
+		tree.NewDBytes(tree.DBytes(encodeFingerprintID(id))),
+	)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.poller.BumpEpochLocked()
+	if _, ok := r.mu.pins[id]; ok {
+		delete(r.mu.pins, id)
+		r.mu.version++
+	}
+	r.mu.Unlock()
+	if n == 0 {
+		return pgerror.Newf(pgcode.UndefinedObject,
+			"no indexes are pinned for statement fingerprint %s", FormatFingerprintID(id))
+	}
+	return nil
+}
+
+func (r *Registry) checkVersion(ctx context.Context) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.StatementPlanPinsTable) {
+		return pgerror.New(pgcode.FeatureNotSupported,
+			"plan pinning is not supported until version upgrade is finalized")
+	}
+	return nil
+}
+
+// pollPins reads system.statement_plan_pins and replaces r.mu.pins with its
+// contents.
+func (r *Registry) pollPins(ctx context.Context) error {
+	if !r.st.Version.IsActive(ctx, clusterversion.StatementPlanPinsTable) {
+		return nil
+	}
+	rows, err := r.poller.QueryLocked(ctx,
+		"SELECT fingerprint_id, hints FROM system.statement_plan_pins")
+	if err != nil {
+		return err
+	}
+	defer r.mu.Unlock()
+
+	pins := make(map[roachpb.StmtFingerprintID]Indexes, len(rows))
+	for _, row := range rows {
+		_, id, err := encoding.DecodeUint64Ascending([]byte(tree.MustBeDBytes(row[0])))
+		if err != nil {
+			return err
+		}
+		indexes, err := parseIndexes(string(tree.MustBeDString(row[1])))
+		if err != nil {
+			log.Warningf(ctx, "ignoring invalid pin for statement fingerprint %s: %s",
+				FormatFingerprintID(roachpb.StmtFingerprintID(id)), err)
+			continue
+		}
+		pins[roachpb.StmtFingerprintID(id)] = indexes
+	}
+	if !pinsEqual(pins, r.mu.pins) {
+		r.mu.pins = pins
+		r.mu.version++
+	}
+	return nil
+}
+
+func pinsEqual(a, b map[roachpb.StmtFingerprintID]Indexes) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, ia := range a {
+		ib, ok := b[id]
+		if !ok || len(ia) != len(ib) {
+			return false
+		}
+		for table, index := range ia {
+			if other, ok := ib[table]; !ok || other != index {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Hint is an index hint given to ALTER STATEMENT FINGERPRINT ... PIN INDEX
+// HINTS, before the table and index names are resolved.
+type Hint struct {
+	// Table is the possibly qualified name of the table.
+	Table string
+	// Index is the name of the index.
+	Index tree.Name
+}
+
+// ParseHints parses a comma-separated list of table@index hints, as in
+// 'orders@orders_customer_idx, public.customers@customers_pkey'.
+func ParseHints(hints string) ([]Hint, error) {
+	var res []Hint
+	for _, h := range strings.Split(hints, ",") {
+		h = strings.TrimSpace(h)
+		parts := strings.Split(h, "@")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+				"invalid index hint %q: expected table@index", h)
+		}
+		res = append(res, Hint{Table: parts[0], Index: tree.Name(parts[1])})
+	}
+	return res, nil
+}
+
+// formatIndexes formats indexes as stored in the hints column of
+// system.statement_plan_pins: a comma-separated list of table_id@index_id,
+// ordered by table ID.
+func formatIndexes(indexes Indexes) string {
+	tables := make([]descpb.ID, 0, len(indexes))
+	for table := range indexes {
+		tables = append(tables, table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i] < tables[j] })
+	var b strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%d@%d", table, indexes[table])
+	}
+	return b.String()
+}
+
+// parseIndexes is the inverse of formatIndexes.
+func parseIndexes(s string) (Indexes, error) {
+	indexes := make(Indexes)
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(pair, "@")
+		if len(parts) != 2 {
+			return nil, errors.Newf("invalid pinned index %q", pair)
+		}
+		table, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pinned index %q", pair)
+		}
+		index, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pinned index %q", pair)
+		}
+		indexes[descpb.ID(table)] = descpb.IndexID(index)
+	}
+	return indexes, nil
+}
+
+// ParseFingerprintID parses a statement fingerprint ID in the hex format used
+// by crdb_internal.statement_statistics and the DB Console.
+func ParseFingerprintID(s string) (roachpb.StmtFingerprintID, error) {
+	b, err := hex.DecodeString(s)
+	if err == nil && len(b) == 8 {
+		var id uint64
+		if _, id, err = encoding.DecodeUint64Ascending(b); err == nil {
+			return roachpb.StmtFingerprintID(id), nil
+		}
+	}
+	return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+		"invalid statement fingerprint %q: expected 16 hexadecimal digits", s)
+}
+
+// FormatFingerprintID is the inverse of ParseFingerprintID.
+func FormatFingerprintID(id roachpb.StmtFingerprintID) string {
+	return hex.EncodeToString(encodeFingerprintID(id))
+}
+
+func encodeFingerprintID(id roachpb.StmtFingerprintID) []byte {
+	return encoding.EncodeUint64Ascending(make([]byte, 0, 8), uint64(id))
+}
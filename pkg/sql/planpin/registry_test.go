@@ -0,0 +1,75 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package planpin
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hints, err := ParseHints("orders@orders_customer_idx, public.customers@primary")
+	require.NoError(t, err)
+	require.Equal(t, []Hint{
+		{Table: "orders", Index: "orders_customer_idx"},
+		{Table: "public.customers", Index: "primary"},
+	}, hints)
+
+	for _, invalid := range []string{
+		"",
+		"orders",
+		"orders@",
+		"@idx",
+		"orders@a@b",
+	} {
+		_, err := ParseHints(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestFormatIndexes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	indexes := Indexes{104: 2, 53: 1}
+	s := formatIndexes(indexes)
+	require.Equal(t, "53@1,104@2", s)
+	parsed, err := parseIndexes(s)
+	require.NoError(t, err)
+	require.Equal(t, indexes, parsed)
+
+	for _, invalid := range []string{"", "53", "53@", "a@1", "53@1@2"} {
+		_, err := parseIndexes(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestParseFingerprintID(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	id := roachpb.ConstructStatementFingerprintID(
+		"SELECT _ FROM t", false /* failed */, true /* implicitTxn */, "defaultdb",
+	)
+	s := FormatFingerprintID(id)
+	require.Len(t, s, 16)
+	parsed, err := ParseFingerprintID(s)
+	require.NoError(t, err)
+	require.Equal(t, id, parsed)
+
+	for _, invalid := range []string{"", "abc", "zzzzzzzzzzzzzzzz", "0011223344556677aa"} {
+		_, err := ParseFingerprintID(invalid)
+		require.Error(t, err, invalid)
+	}
+}
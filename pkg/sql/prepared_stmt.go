@@ -50,6 +50,19 @@ type PreparedStatement struct {
 	// if it is used by the optimizer as a starting point.
 	Memo *memo.Memo
 
+	// GenericMemo is the fully optimized memo of the generic plan of the
+	// prepared statement, which is built without knowing the values of the
+	// placeholders. It is nil until the generic plan is first needed (see
+	// plan_cache_mode).
+	GenericMemo *memo.Memo
+
+	// numCustomPlans and customPlansCost track the number of custom plans that
+	// were built for the executions of this prepared statement and their total
+	// estimated cost. They are used to decide whether the generic plan should
+	// be used with plan_cache_mode=auto.
+	numCustomPlans  int
+	customPlansCost memo.Cost
+
 	// refCount keeps track of the number of references to this PreparedStatement.
 	// New references are registered through incRef().
 	// Once refCount hits 0 (through calls to decRef()), the following memAcc is
@@ -74,13 +87,28 @@ func (p *PreparedStatement) MemoryEstimate() int64 {
 	// Account for the memory used by this prepared statement:
 	//   1. Size of the prepare metadata.
 	//   2. Size of the prepared memo, if using the cost-based optimizer.
+	//   3. Size of the memo of the generic plan, if it was built.
 	size := p.PrepareMetadata.MemoryEstimate()
 	if p.Memo != nil {
 		size += p.Memo.MemoryEstimate()
 	}
+	if p.GenericMemo != nil {
+		size += p.GenericMemo.MemoryEstimate()
+	}
 	return size
 }
 
+// resetGenericMemo discards the generic memo of the prepared statement, as
+// well as the statistics of its custom plans.
+func (p *PreparedStatement) resetGenericMemo(ctx context.Context) {
+	if p.GenericMemo != nil {
+		p.memAcc.Shrink(ctx, p.GenericMemo.MemoryEstimate())
+		p.GenericMemo = nil
+	}
+	p.numCustomPlans = 0
+	p.customPlansCost = 0
+}
+
 func (p *PreparedStatement) decRef(ctx context.Context) {
 	if p.refCount <= 0 {
 		log.Fatal(ctx, "corrupt PreparedStatement refcount")
@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -158,6 +159,10 @@ func RandDatumWithNullChance(rng *rand.Rand, typ *types.T, nullChance int) tree.
 			return nil
 		}
 		return &tree.DJSON{JSON: j}
+	case types.TSQueryFamily:
+		return tree.NewDTSQuery(tsearch.RandomTSQuery(rng))
+	case types.TSVectorFamily:
+		return tree.NewDTSVector(tsearch.RandomTSVector(rng))
 	case types.TupleFamily:
 		tuple := tree.DTuple{D: make(tree.Datums, len(typ.TupleContents()))}
 		for i := range typ.TupleContents() {
@@ -224,6 +229,30 @@ func RandDatumWithNullChance(rng *rand.Rand, typ *types.T, nullChance int) tree.
 		return tree.DNull
 	case types.ArrayFamily:
 		return RandArray(rng, typ, 0)
+	case types.RangeFamily:
+		if rng.Intn(10) == 0 {
+			return tree.NewDEmptyRange(typ)
+		}
+		// A NULL bound makes the range unbounded.
+		lower := RandDatumWithNullChance(rng, typ.RangeContents(), 5)
+		upper := RandDatumWithNullChance(rng, typ.RangeContents(), 5)
+		lowerInc, upperInc := rng.Intn(2) == 0, rng.Intn(2) == 0
+		r, err := tree.NewDRange(typ, lower, upper, lowerInc, upperInc)
+		if err != nil {
+			// The lower bound was greater than the upper bound, so swap them.
+			r, err = tree.NewDRange(typ, upper, lower, lowerInc, upperInc)
+			if err != nil {
+				// A bound of a discrete range could not be canonicalized.
+				return tree.NewDEmptyRange(typ)
+			}
+		}
+		return r
+	case types.MultirangeFamily:
+		ranges := make([]*tree.DRange, rng.Intn(4))
+		for i := range ranges {
+			ranges[i] = RandDatumWithNullChance(rng, typ.MultirangeContents(), 0).(*tree.DRange)
+		}
+		return tree.NewDMultirange(typ, ranges...)
 	case types.AnyFamily:
 		return RandDatumWithNullChance(rng, RandType(rng), nullChance)
 	case types.EnumFamily:
@@ -13,6 +13,7 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/catalog/tabledesc"
@@ -20,11 +21,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgnotice"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/errors"
 )
 
 type refreshMaterializedViewNode struct {
 	n    *tree.RefreshMaterializedView
 	desc *tabledesc.Mutable
+	// incremental is set if the view is refreshed incrementally.
+	incremental *incrementalViewQuery
 }
 
 func (p *planner) RefreshMaterializedView(
@@ -68,7 +72,17 @@ func (p *planner) RefreshMaterializedView(
 		)
 	}
 
-	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+	node := &refreshMaterializedViewNode{n: n, desc: desc}
+	if n.Incrementally {
+		if !p.ExecCfg().Settings.Version.IsActive(ctx, clusterversion.IncrementalMaterializedViewRefresh) {
+			return nil, pgerror.New(pgcode.FeatureNotSupported,
+				"incremental materialized view refresh is not supported until version upgrade is finalized")
+		}
+		if node.incremental, err = p.planIncrementalViewRefresh(ctx, desc); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
 }
 
 func (n *refreshMaterializedViewNode) startExec(params runParams) error {
@@ -89,6 +103,21 @@ func (n *refreshMaterializedViewNode) startExec(params runParams) error {
 		)
 	}
 
+	if n.incremental != nil {
+		// The view can only be refreshed incrementally if the changes made to the
+		// tables it depends on since its last refresh are still available.
+		// Otherwise, fall back to a full refresh.
+		err := n.refreshIncrementally(params, params.p.Txn().ReadTimestamp())
+		var unavailableErr *errIncrementalRefreshUnavailable
+		if !errors.As(err, &unavailableErr) {
+			return err
+		}
+		params.p.BufferClientNotice(
+			params.ctx,
+			pgnotice.Newf("materialized view %q is fully refreshed: %s", n.desc.Name, unavailableErr.reason),
+		)
+	}
+
 	// Prepare the new set of indexes by cloning all existing indexes on the view.
 	newPrimaryIndex := n.desc.GetPrimaryIndex().IndexDescDeepCopy()
 	newIndexes := make([]descpb.IndexDescriptor, len(n.desc.PublicNonPrimaryIndexes()))
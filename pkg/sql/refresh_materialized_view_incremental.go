@@ -0,0 +1,936 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/kv/kvclient"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/iterutil"
+	"github.com/cockroachdb/errors"
+)
+
+// incrementalRefreshMaxChangedRows bounds the number of base table rows that
+// REFRESH MATERIALIZED VIEW ... INCREMENTALLY applies changes for. Past this
+// point, recomputing the view from scratch is cheaper than computing the
+// changes to apply to it.
+var incrementalRefreshMaxChangedRows = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.materialized_view.incremental_refresh.max_changed_rows",
+	"maximum number of rows of the tables a materialized view depends on that can change "+
+		"between two refreshes for REFRESH MATERIALIZED VIEW ... INCREMENTALLY to apply "+
+		"only the changes; the view is fully refreshed when more rows changed",
+	10000,
+	settings.NonNegativeInt,
+)
+
+// incrementalAggregates are the aggregate functions that can be used in a
+// materialized view refreshed incrementally. They are all decomposable: their
+// value over a group can be recomputed from the rows of that group alone.
+var incrementalAggregates = map[string]struct{}{
+	"avg":        {},
+	"bool_and":   {},
+	"bool_or":    {},
+	"count":      {},
+	"count_rows": {},
+	"every":      {},
+	"max":        {},
+	"min":        {},
+	"sum":        {},
+	"sum_int":    {},
+}
+
+// incrementalViewQuery is the decomposition of the query of a materialized
+// view that can be refreshed incrementally. The query is restricted to
+// filters, projections and inner joins over tables, optionally followed by a
+// grouping with decomposable aggregates.
+//
+// The view is refreshed by first finding the primary keys of all the rows of
+// the source tables that were modified since the last refresh. For views
+// without aggregation, which must hold the primary keys of their source rows,
+// every view row derived from a modified source row is then replaced by the
+// rows derived from the source rows as of the refresh timestamp. For views
+// with aggregation, every group containing a modified source row, either
+// before or after the modification, is recomputed. In both cases, the view
+// rows to replace are looked up in an index of the view on the columns they
+// are keyed by.
+type incrementalViewQuery struct {
+	clause *tree.SelectClause
+	// sources are the tables the query reads from.
+	sources []incrementalViewSource
+	// aggregate is set if the query groups or aggregates its input.
+	aggregate bool
+	// groupCols are the ordinals of the output columns of the query that hold
+	// the expressions the query groups by. It is only set if aggregate is set.
+	groupCols []int
+	// groupIndex is the index of the view whose leading key columns are the
+	// columns at groupCols. It is only set if groupCols is.
+	groupIndex catalog.Index
+}
+
+// incrementalViewSource is a table the query of a materialized view reads
+// from.
+type incrementalViewSource struct {
+	desc catalog.TableDescriptor
+	// name is the name the view query uses to refer to the table, which is
+	// either its alias or its fully qualified name.
+	name string
+	// alias is the name the view query qualifies the columns of the table
+	// with, which is either its alias or its unqualified name.
+	alias string
+	// keyCols are the ordinals of the output columns of the query that hold
+	// the primary key columns of the table, in the order of the primary key.
+	// It is only set if the query does not aggregate.
+	keyCols []int
+	// keyIndex is the index of the view whose leading key columns are the
+	// columns at keyCols. It is only set if keyCols is.
+	keyIndex catalog.Index
+}
+
+// planIncrementalViewRefresh checks that the query of the given materialized
+// view can be refreshed incrementally, and decomposes it.
+func (p *planner) planIncrementalViewRefresh(
+	ctx context.Context, desc catalog.TableDescriptor,
+) (*incrementalViewQuery, error) {
+	notSupported := func(format string, args ...interface{}) error {
+		return errors.WithHint(
+			pgerror.Newf(pgcode.FeatureNotSupported,
+				"materialized view %q cannot be refreshed incrementally: %s",
+				desc.GetName(), fmt.Sprintf(format, args...)),
+			"only views made of filters, projections, inner joins and decomposable "+
+				"aggregates can be refreshed incrementally; use REFRESH MATERIALIZED VIEW instead",
+		)
+	}
+	for _, idx := range desc.PublicNonPrimaryIndexes() {
+		if idx.IsPartial() {
+			return nil, notSupported("index %q is a partial index", idx.GetName())
+		}
+	}
+
+	stmt, err := parser.ParseOne(desc.GetViewQuery())
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := stmt.AST.(*tree.Select)
+	if !ok {
+		return nil, notSupported("the view query is not a SELECT")
+	}
+	for {
+		paren, ok := sel.Select.(*tree.ParenSelect)
+		if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+			break
+		}
+		sel = paren.Select
+	}
+	if sel.With != nil {
+		return nil, notSupported("the view query has a WITH clause")
+	}
+	if sel.OrderBy != nil || sel.Limit != nil {
+		return nil, notSupported("the view query has an ORDER BY or LIMIT clause")
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok {
+		return nil, notSupported("the view query is not a simple SELECT")
+	}
+	if clause.Distinct || clause.DistinctOn != nil {
+		return nil, notSupported("the view query uses DISTINCT")
+	}
+	if clause.Window != nil {
+		return nil, notSupported("the view query has a WINDOW clause")
+	}
+
+	q := &incrementalViewQuery{clause: clause}
+	var addSources func(expr tree.TableExpr) error
+	addSources = func(expr tree.TableExpr) error {
+		switch t := expr.(type) {
+		case *tree.ParenTableExpr:
+			return addSources(t.Expr)
+		case *tree.JoinTableExpr:
+			if t.JoinType != "" && t.JoinType != tree.AstInner && t.JoinType != tree.AstCross {
+				return notSupported("the view query uses a %s JOIN", t.JoinType)
+			}
+			if err := addSources(t.Left); err != nil {
+				return err
+			}
+			return addSources(t.Right)
+		case *tree.AliasedTableExpr:
+			name, ok := t.Expr.(*tree.UnresolvedObjectName)
+			if !ok || t.Ordinality || t.Lateral || len(t.As.Cols) > 0 {
+				return notSupported("the view query reads from %s", tree.AsString(t))
+			}
+			tn := name.ToTableName()
+			_, table, err := p.Descriptors().GetImmutableTableByName(
+				ctx, p.txn, &tn, tree.ObjectLookupFlagsWithRequired(),
+			)
+			if err != nil {
+				return err
+			}
+			if !table.IsTable() || table.IsVirtualTable() {
+				return notSupported("%q is not a table", table.GetName())
+			}
+			src := incrementalViewSource{
+				desc: table, name: tree.AsString(&tn), alias: string(tn.ObjectName),
+			}
+			if t.As.Alias != "" {
+				src.name = t.As.Alias.String()
+				src.alias = string(t.As.Alias)
+			}
+			q.sources = append(q.sources, src)
+			return nil
+		default:
+			return notSupported("the view query reads from %s", tree.AsString(expr))
+		}
+	}
+	for _, expr := range clause.From.Tables {
+		if err := addSources(expr); err != nil {
+			return nil, err
+		}
+	}
+	if len(q.sources) == 0 {
+		return nil, notSupported("the view query does not read from any table")
+	}
+
+	v := incrementalViewExprVisitor{searchPath: p.CurrentSearchPath()}
+	for _, expr := range clause.Exprs {
+		tree.WalkExprConst(&v, expr.Expr)
+	}
+	if v.err == nil && clause.Where != nil {
+		tree.WalkExprConst(&v, clause.Where.Expr)
+	}
+	if v.err == nil && clause.Having != nil {
+		tree.WalkExprConst(&v, clause.Having.Expr)
+	}
+	if v.err != nil {
+		return nil, notSupported("%v", v.err)
+	}
+	// Any aggregate in the WHERE clause is rejected when the view is created,
+	// so the visitor only finds them in the output columns or in HAVING.
+	q.aggregate = v.foundAggregate || len(clause.GroupBy) > 0 || clause.Having != nil
+
+	for _, expr := range clause.GroupBy {
+		v = incrementalViewExprVisitor{searchPath: p.CurrentSearchPath()}
+		if tree.WalkExprConst(&v, expr); v.err != nil {
+			return nil, notSupported("%v", v.err)
+		}
+		ord, ok := groupByOutputColumn(clause, expr)
+		if !ok {
+			return nil, notSupported(
+				"GROUP BY expression %s is not one of the columns of the view", tree.AsString(expr))
+		}
+		q.groupCols = append(q.groupCols, ord)
+	}
+
+	// The view rows to replace are looked up by the columns holding the groups
+	// they belong to, or the primary keys of the source rows they are derived
+	// from, which requires an index on these columns.
+	if q.aggregate {
+		if len(q.groupCols) > 0 {
+			if q.groupIndex = findViewIndex(desc, q.groupCols); q.groupIndex == nil {
+				return nil, notSupported("the view has no index on its GROUP BY columns %s",
+					viewColumnNames(desc, q.groupCols))
+			}
+		}
+		return q, nil
+	}
+	for i := range q.sources {
+		src := &q.sources[i]
+		pk := src.desc.GetPrimaryIndex()
+		for j := 0; j < pk.NumKeyColumns(); j++ {
+			ord, ok := sourceColumnOutput(clause, src.alias, pk.GetKeyColumnName(j), len(q.sources) > 1)
+			if !ok {
+				return nil, notSupported(
+					"primary key column %s of table %q is not one of the columns of the view",
+					tree.NameString(pk.GetKeyColumnName(j)), src.desc.GetName())
+			}
+			src.keyCols = append(src.keyCols, ord)
+		}
+		if src.keyIndex = findViewIndex(desc, src.keyCols); src.keyIndex == nil {
+			return nil, notSupported("the view has no index on the columns %s holding the primary key of table %q",
+				viewColumnNames(desc, src.keyCols), src.desc.GetName())
+		}
+	}
+	return q, nil
+}
+
+// sourceColumnOutput returns the ordinal of the output column of the given
+// SELECT clause which is a plain reference to the given column of the table
+// the query refers to by the given alias. If qualified is set, the reference
+// must be qualified by the alias.
+func sourceColumnOutput(
+	clause *tree.SelectClause, alias string, col string, qualified bool,
+) (int, bool) {
+	for i := range clause.Exprs {
+		name, ok := clause.Exprs[i].Expr.(*tree.UnresolvedName)
+		if !ok || name.Star || name.Parts[0] != col {
+			continue
+		}
+		if (name.NumParts == 1 && !qualified) || (name.NumParts > 1 && name.Parts[1] == alias) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// findViewIndex returns the public secondary index of the given view whose
+// leading key columns are the columns at the given ordinals, in any order, or
+// nil if there is no such index.
+func findViewIndex(desc catalog.TableDescriptor, ords []int) catalog.Index {
+	cols := desc.VisibleColumns()
+	var want catalog.TableColSet
+	for _, ord := range ords {
+		want.Add(cols[ord].GetID())
+	}
+	for _, idx := range desc.PublicNonPrimaryIndexes() {
+		if idx.NumKeyColumns() < want.Len() {
+			continue
+		}
+		// The key columns of an index are distinct, so the leading ones are the
+		// wanted columns if they all are wanted columns.
+		match := true
+		for i := 0; i < want.Len() && match; i++ {
+			match = want.Contains(idx.GetKeyColumnID(i))
+		}
+		if match {
+			return idx
+		}
+	}
+	return nil
+}
+
+// viewColumnNames formats the names of the columns of the given view at the
+// given ordinals.
+func viewColumnNames(desc catalog.TableDescriptor, ords []int) string {
+	cols := desc.VisibleColumns()
+	names := make([]string, len(ords))
+	for i, ord := range ords {
+		names[i] = tree.NameString(cols[ord].GetName())
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+// groupByOutputColumn returns the ordinal of the output column of the given
+// SELECT clause which holds the given GROUP BY expression.
+func groupByOutputColumn(clause *tree.SelectClause, expr tree.Expr) (int, bool) {
+	switch t := expr.(type) {
+	case *tree.NumVal:
+		// GROUP BY 1 refers to the first output column.
+		ord, err := t.AsInt64()
+		if err != nil || ord < 1 || int(ord) > len(clause.Exprs) {
+			return 0, false
+		}
+		return int(ord - 1), true
+	case *tree.UnresolvedName:
+		if t.NumParts == 1 {
+			for i := range clause.Exprs {
+				if clause.Exprs[i].As == tree.UnrestrictedName(t.Parts[0]) {
+					return i, true
+				}
+			}
+		}
+	}
+	str := tree.Serialize(expr)
+	for i := range clause.Exprs {
+		if tree.Serialize(clause.Exprs[i].Expr) == str {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// incrementalViewExprVisitor checks that an expression of the query of a
+// materialized view can be refreshed incrementally.
+type incrementalViewExprVisitor struct {
+	searchPath     sessiondata.SearchPath
+	foundAggregate bool
+	err            error
+}
+
+var _ tree.Visitor = &incrementalViewExprVisitor{}
+
+// VisitPre is part of the tree.Visitor interface.
+func (v *incrementalViewExprVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if v.err != nil {
+		return false, expr
+	}
+	switch t := expr.(type) {
+	case *tree.Subquery:
+		v.err = errors.New("the view query contains a subquery")
+		return false, expr
+	case *tree.FuncExpr:
+		if t.WindowDef != nil {
+			v.err = errors.New("the view query contains a window function")
+			return false, expr
+		}
+		def, err := t.Func.Resolve(v.searchPath)
+		if err != nil {
+			v.err = err
+			return false, expr
+		}
+		switch def.Class {
+		case tree.AggregateClass:
+			if _, ok := incrementalAggregates[def.Name]; !ok || t.Type == tree.DistinctFuncType {
+				v.err = errors.Newf("aggregate %s is not decomposable", tree.AsString(t))
+				return false, expr
+			}
+			v.foundAggregate = true
+		case tree.GeneratorClass, tree.WindowClass:
+			v.err = errors.Newf("function %s is not supported", def.Name)
+			return false, expr
+		}
+		// Functions whose result can change without the tables the view depends
+		// on changing, like now(), would make the rows of the view that were not
+		// recomputed stale.
+		for _, o := range def.Definition {
+			if ov, ok := o.(*tree.Overload); ok && ov.Volatility > tree.VolatilityImmutable {
+				v.err = errors.Newf("function %s is not immutable", def.Name)
+				return false, expr
+			}
+		}
+	}
+	return true, expr
+}
+
+// VisitPost is part of the tree.Visitor interface.
+func (v *incrementalViewExprVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// errIncrementalRefreshUnavailable is returned when a materialized view has
+// to be fully refreshed because the changes made since its last refresh
+// cannot be determined or would be too expensive to apply.
+type errIncrementalRefreshUnavailable struct {
+	reason string
+}
+
+func (e *errIncrementalRefreshUnavailable) Error() string {
+	return e.reason
+}
+
+// refreshIncrementally applies the changes made to the tables the view of n
+// depends on between the last refresh of the view and asOf.
+func (n *refreshMaterializedViewNode) refreshIncrementally(
+	params runParams, asOf hlc.Timestamp,
+) error {
+	ctx, p := params.ctx, params.p
+	execCfg := p.ExecCfg()
+	q := n.incremental
+
+	lastRefresh, err := n.lastRefreshTime(params)
+	if err != nil {
+		return err
+	}
+	if lastRefresh.IsEmpty() {
+		return &errIncrementalRefreshUnavailable{
+			reason: "the view has no data, or was last refreshed by a previous version",
+		}
+	}
+
+	// Find the primary keys of the rows modified since the last refresh. The
+	// revisions are read from the MVCC history of the tables, which requires
+	// that it was not garbage collected yet.
+	maxChangedRows := incrementalRefreshMaxChangedRows.Get(&execCfg.Settings.SV)
+	var numChangedRows int64
+	changedRows := make(map[descpb.ID][]tree.Datums)
+	for _, src := range q.sources {
+		if _, ok := changedRows[src.desc.GetID()]; ok {
+			continue
+		}
+		// Changing the primary key of a table rewrites all of its rows in a new
+		// index, where the history of the rows that were deleted before the
+		// change cannot be found.
+		pkRow, err := execCfg.InternalExecutor.QueryRowEx(
+			ctx, "refresh-view-primary-index", nil, /* txn */
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			fmt.Sprintf(`SELECT index_id FROM crdb_internal.table_indexes AS OF SYSTEM TIME '%s'
+WHERE descriptor_id = $1 AND index_type = 'primary'`, lastRefresh.AsOfSystemTime()),
+			int(src.desc.GetID()),
+		)
+		if err != nil {
+			return err
+		}
+		if pkRow == nil || descpb.IndexID(tree.MustBeDInt(pkRow[0])) != src.desc.GetPrimaryIndexID() {
+			return &errIncrementalRefreshUnavailable{
+				reason: fmt.Sprintf("the primary key of table %q changed since the last refresh", src.desc.GetName()),
+			}
+		}
+		_, zone, _, err := GetZoneConfigInTxn(
+			ctx, p.txn, execCfg.Codec, src.desc.GetID(), nil /* index */, "" /* partition */, false, /* getInheritedDefault */
+		)
+		if err != nil {
+			return err
+		}
+		ttl := time.Duration(zone.GC.TTLSeconds) * time.Second
+		if asOf.GoTime().Sub(lastRefresh.GoTime()) >= ttl {
+			return &errIncrementalRefreshUnavailable{
+				reason: fmt.Sprintf("the changes made to table %q since the last refresh "+
+					"may have been garbage collected", src.desc.GetName()),
+			}
+		}
+		rows, ok, err := changedPrimaryKeys(
+			ctx, execCfg, src.desc, lastRefresh, asOf, maxChangedRows-numChangedRows,
+		)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &errIncrementalRefreshUnavailable{
+				reason: fmt.Sprintf("more than %d rows changed since the last refresh", maxChangedRows),
+			}
+		}
+		numChangedRows += int64(len(rows))
+		changedRows[src.desc.GetID()] = rows
+	}
+
+	var changed []string
+	for _, src := range q.sources {
+		rows := changedRows[src.desc.GetID()]
+		if len(rows) == 0 {
+			continue
+		}
+		pk := src.desc.GetPrimaryIndex()
+		cols := make([]string, pk.NumKeyColumns())
+		for i := range cols {
+			cols[i] = src.name + "." + tree.NameString(pk.GetKeyColumnName(i))
+		}
+		changed = append(changed, formatInTuples(cols, rows))
+	}
+	if len(changed) > 0 {
+		pred, err := parser.ParseExpr(strings.Join(changed, " OR "))
+		if err != nil {
+			return err
+		}
+		if err := n.applyViewChanges(params, lastRefresh, asOf, changedRows, pred); err != nil {
+			return err
+		}
+	}
+
+	// The timestamp of the refresh is recorded outside of the view descriptor,
+	// so that refreshing the view does not bump its version and invalidate the
+	// leases on it.
+	_, err = execCfg.InternalExecutor.ExecEx(
+		ctx, "refresh-view-record", p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`UPSERT INTO system.materialized_view_refreshes (view_id, refreshed_at) VALUES ($1, $2)`,
+		int(n.desc.GetID()), tree.TimestampToDecimalDatum(asOf),
+	)
+	return err
+}
+
+// lastRefreshTime returns the timestamp as of which the data of the view of n
+// was last computed, either fully, as recorded in the view descriptor, or
+// incrementally, as recorded in system.materialized_view_refreshes. It is
+// empty if the view has to be fully refreshed.
+func (n *refreshMaterializedViewNode) lastRefreshTime(params runParams) (hlc.Timestamp, error) {
+	// A full refresh WITH NO DATA clears the timestamp in the descriptor, but
+	// leaves the one of the previous incremental refresh in place.
+	lastRefresh := n.desc.GetMaterializedViewRefreshTime()
+	if lastRefresh.IsEmpty() {
+		return lastRefresh, nil
+	}
+	row, err := params.p.ExecCfg().InternalExecutor.QueryRowEx(
+		params.ctx, "refresh-view-last-refresh", params.p.txn,
+		sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+		`SELECT refreshed_at FROM system.materialized_view_refreshes WHERE view_id = $1`,
+		int(n.desc.GetID()),
+	)
+	if err != nil || row == nil {
+		return lastRefresh, err
+	}
+	d := tree.MustBeDDecimal(row[0])
+	refreshedAt, err := tree.DecimalToHLC(&d.Decimal)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	lastRefresh.Forward(refreshedAt)
+	return lastRefresh, nil
+}
+
+// applyViewChanges replaces the rows of the view that are derived from the
+// source rows matching changed, whose primary keys are in changedRows.
+func (n *refreshMaterializedViewNode) applyViewChanges(
+	params runParams,
+	lastRefresh, asOf hlc.Timestamp,
+	changedRows map[descpb.ID][]tree.Datums,
+	changed tree.Expr,
+) error {
+	ctx, p := params.ctx, params.p
+	q := n.incremental
+	ie := p.ExecCfg().InternalExecutor
+	override := sessiondata.InternalExecutorOverride{User: security.RootUserName()}
+
+	viewCols := n.desc.VisibleColumns()
+	// lookupView reads the rows of the view whose columns at the given
+	// ordinals are not distinct from one of the given keys, in the order of the
+	// public columns of the view. The rows are looked up in the given index,
+	// whose leading key columns are these columns. If the index is nil, all the
+	// rows of the view are read.
+	lookupView := func(idx catalog.Index, ords []int, keys []tree.Datums) ([]tree.Datums, error) {
+		cols := make([]string, 0, len(n.desc.PublicColumns()))
+		for _, col := range n.desc.PublicColumns() {
+			cols = append(cols, "v."+tree.NameString(col.GetName()))
+		}
+		query := fmt.Sprintf(`SELECT %s FROM [%d AS v]`, strings.Join(cols, ", "), n.desc.GetID())
+		if idx != nil {
+			keyCols := make([]string, len(ords))
+			for i, ord := range ords {
+				keyCols[i] = "v." + tree.NameString(viewCols[ord].GetName())
+			}
+			query += fmt.Sprintf(`@[%d] WHERE %s`, idx.GetID(), formatKeyPredicate(keyCols, keys))
+		}
+		return ie.QueryBufferedEx(ctx, "refresh-view-lookup", p.txn, override, query)
+	}
+	// runQuery runs the view query restricted to the input rows matching pred,
+	// as of the given timestamp.
+	runQuery := func(opName string, ts hlc.Timestamp, pred tree.Expr, extraCols string) ([]tree.Datums, error) {
+		restricted := *q.clause
+		restricted.Where = tree.NewWhere(tree.AstWhere, andExprs(q.clause.Where, pred))
+		return ie.QueryBufferedEx(ctx, opName, nil /* txn */, override, fmt.Sprintf(
+			`SELECT *%s FROM (%s) AS OF SYSTEM TIME '%s'`,
+			extraCols, tree.Serialize(&restricted), ts.AsOfSystemTime(),
+		))
+	}
+
+	var toDelete, toInsert []tree.Datums
+	var err error
+	if !q.aggregate {
+		// Every row of the view holds the primary keys of the source rows it is
+		// derived from, so the rows derived from the changed source rows are
+		// looked up by these keys, and replaced by the rows derived from them as
+		// of now. A view row derived from changed rows of several sources is
+		// found once per source; its hidden row ID tells the copies apart from
+		// duplicate rows of the view.
+		seen := make(map[string]struct{})
+		for i := range q.sources {
+			src := &q.sources[i]
+			keys := changedRows[src.desc.GetID()]
+			if len(keys) == 0 {
+				continue
+			}
+			rows, err := lookupView(src.keyIndex, src.keyCols, keys)
+			if err != nil {
+				return err
+			}
+			for _, r := range rows {
+				key := datumsKey(r)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				toDelete = append(toDelete, r)
+			}
+		}
+		if toInsert, err = runQuery(
+			"refresh-view-new-rows", asOf, changed, ", unique_rowid()",
+		); err != nil {
+			return err
+		}
+	} else {
+		// Find the groups the changed source rows belonged to, either as of the
+		// last refresh or as of now, and recompute them.
+		var pred tree.Expr = tree.DBoolTrue
+		var groupRows []tree.Datums
+		if len(q.groupCols) > 0 {
+			groupExprs := make([]string, len(q.groupCols))
+			for i, ord := range q.groupCols {
+				groupExprs[i] = tree.Serialize(q.clause.Exprs[ord].Expr)
+			}
+			groupQuery := tree.SelectClause{
+				Distinct: true,
+				From:     q.clause.From,
+				Where:    tree.NewWhere(tree.AstWhere, andExprs(q.clause.Where, changed)),
+			}
+			for _, ord := range q.groupCols {
+				groupQuery.Exprs = append(groupQuery.Exprs, tree.SelectExpr{Expr: q.clause.Exprs[ord].Expr})
+			}
+			groups := make(map[string]tree.Datums)
+			for _, ts := range []hlc.Timestamp{lastRefresh, asOf} {
+				rows, err := ie.QueryBufferedEx(ctx, "refresh-view-groups", nil /* txn */, override,
+					fmt.Sprintf(`%s AS OF SYSTEM TIME '%s'`, tree.Serialize(&groupQuery), ts.AsOfSystemTime()),
+				)
+				if err != nil {
+					return err
+				}
+				for _, r := range rows {
+					groups[datumsKey(r)] = r
+				}
+			}
+			if len(groups) == 0 {
+				return nil
+			}
+			groupRows = make([]tree.Datums, 0, len(groups))
+			for _, r := range groups {
+				groupRows = append(groupRows, r)
+			}
+			if pred, err = parser.ParseExpr(formatKeyPredicate(groupExprs, groupRows)); err != nil {
+				return err
+			}
+		}
+		if toDelete, err = lookupView(q.groupIndex, q.groupCols, groupRows); err != nil {
+			return err
+		}
+		if toInsert, err = runQuery("refresh-view-new-groups", asOf, pred, ", unique_rowid()"); err != nil {
+			return err
+		}
+	}
+	return n.writeViewRows(params, toDelete, toInsert)
+}
+
+// writeViewRows deletes and inserts the given rows of the view. The rows hold
+// the values of all the public columns of the view.
+func (n *refreshMaterializedViewNode) writeViewRows(
+	params runParams, toDelete, toInsert []tree.Datums,
+) error {
+	ctx, p := params.ctx, params.p
+	execCfg := p.ExecCfg()
+	internal := p.SessionData().Internal
+	desc := n.desc.ImmutableCopy().(catalog.TableDescriptor)
+	traceKV := p.ExtendedEvalContext().Tracing.KVTracingEnabled()
+	// The view has no partial indexes, so an empty PartialIndexUpdateHelper
+	// updates all of its indexes.
+	var pm row.PartialIndexUpdateHelper
+
+	writeRows := func(tw tableWriter, rows []tree.Datums) error {
+		if err := tw.init(ctx, p.txn, p.EvalContext(), &execCfg.Settings.SV); err != nil {
+			return err
+		}
+		defer tw.close(ctx)
+		for _, r := range rows {
+			if err := p.cancelChecker.Check(); err != nil {
+				return err
+			}
+			if err := tw.row(ctx, r, pm, traceKV); err != nil {
+				return err
+			}
+		}
+		return tw.finalize(ctx)
+	}
+
+	if len(toDelete) > 0 {
+		rd := row.MakeDeleter(
+			execCfg.Codec, desc, desc.PublicColumns(), &execCfg.Settings.SV, internal,
+			execCfg.GetRowMetrics(internal),
+		)
+		if err := writeRows(&tableDeleter{rd: rd, alloc: p.alloc}, toDelete); err != nil {
+			return err
+		}
+	}
+	if len(toInsert) > 0 {
+		ri, err := row.MakeInserter(
+			ctx, p.txn, execCfg.Codec, desc, desc.PublicColumns(), p.alloc,
+			&execCfg.Settings.SV, internal, execCfg.GetRowMetrics(internal),
+		)
+		if err != nil {
+			return err
+		}
+		if err := writeRows(&tableInserter{ri: ri}, toInsert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changedPrimaryKeysPageSize is the target size of the pages of revisions read
+// by changedPrimaryKeys.
+const changedPrimaryKeysPageSize = 4 << 20 // 4 MiB
+
+// changedPrimaryKeys returns the primary keys of the rows of the given table
+// that were modified in the (from, to] time interval, including the rows that
+// were deleted. The revisions are read in pages, and the second return value
+// is false if more than maxRows rows were modified, in which case reading
+// stops early.
+func changedPrimaryKeys(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	table catalog.TableDescriptor,
+	from, to hlc.Timestamp,
+	maxRows int64,
+) (_ []tree.Datums, ok bool, _ error) {
+	pk := table.GetPrimaryIndex()
+	colTypes := make([]*types.T, pk.NumKeyColumns())
+	colDirs := make([]descpb.IndexDescriptor_Direction, pk.NumKeyColumns())
+	for i := range colTypes {
+		col, err := table.FindColumnWithID(pk.GetKeyColumnID(i))
+		if err != nil {
+			return nil, false, err
+		}
+		colTypes[i] = col.GetType()
+		colDirs[i] = pk.GetKeyColumnDirection(i)
+	}
+
+	var alloc tree.DatumAlloc
+	var res []tree.Datums
+	// The keys of a row are contiguous, so only the last row key needs to be
+	// remembered to skip the other column families of the row, including
+	// across pages.
+	var lastRowKey roachpb.Key
+	vals := make([]rowenc.EncDatum, len(colTypes))
+	span := table.PrimaryIndexSpan(execCfg.Codec)
+	ok = true
+	err := kvclient.GetAllRevisionsPaginated(
+		ctx, execCfg.DB, span.Key, span.EndKey, from, to, changedPrimaryKeysPageSize,
+		func(revisions []kvclient.VersionedValues) error {
+			for _, rev := range revisions {
+				// Each row is stored in one key per column family, which all share
+				// the primary key of the row as prefix.
+				remaining, _, err := rowenc.DecodeIndexKey(execCfg.Codec, colTypes, vals, colDirs, rev.Key)
+				if err != nil {
+					return err
+				}
+				rowKey := rev.Key[:len(rev.Key)-len(remaining)]
+				if rowKey.Equal(lastRowKey) {
+					continue
+				}
+				lastRowKey = rowKey
+				if int64(len(res)) >= maxRows {
+					ok = false
+					return iterutil.StopIteration()
+				}
+				datums := make(tree.Datums, len(vals))
+				for i := range vals {
+					if err := vals[i].EnsureDecoded(colTypes[i], &alloc); err != nil {
+						return err
+					}
+					datums[i] = vals[i].Datum
+				}
+				res = append(res, datums)
+			}
+			return nil
+		},
+	)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return res, true, nil
+}
+
+// andExprs returns the conjunction of the given WHERE clause, if any, and of
+// the given expression.
+func andExprs(where *tree.Where, expr tree.Expr) tree.Expr {
+	if where == nil {
+		return expr
+	}
+	return &tree.AndExpr{
+		Left:  &tree.ParenExpr{Expr: where.Expr},
+		Right: &tree.ParenExpr{Expr: expr},
+	}
+}
+
+// formatTuple formats the given datums as a SQL tuple.
+func formatTuple(row tree.Datums) string {
+	var buf strings.Builder
+	buf.WriteByte('(')
+	for i, d := range row {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(tree.Serialize(d))
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// formatInTuples returns a predicate that is true if the given columns, none
+// of which can be NULL, are equal to one of the given rows.
+func formatInTuples(cols []string, rows []tree.Datums) string {
+	tuples := make([]string, len(rows))
+	for i, r := range rows {
+		tuples[i] = formatTuple(r)
+		if len(r) == 1 {
+			tuples[i] = tree.Serialize(r[0])
+		}
+	}
+	lhs := cols[0]
+	if len(cols) > 1 {
+		lhs = "(" + strings.Join(cols, ", ") + ")"
+	}
+	return fmt.Sprintf("%s IN (%s)", lhs, strings.Join(tuples, ", "))
+}
+
+// formatKeyPredicate returns a predicate that is true if the given
+// expressions are not distinct from one of the given rows. The rows are
+// grouped by the expressions they hold NULL for, so that the predicate is a
+// disjunction of a few IN lists, which an index on the expressions constrains
+// to point lookups, rather than of one comparison per row.
+func formatKeyPredicate(exprs []string, rows []tree.Datums) string {
+	type nullPattern struct {
+		nulls, nonNulls []int
+		rows            []tree.Datums
+	}
+	patterns := make(map[string]*nullPattern)
+	var keys []string
+	for _, r := range rows {
+		var key strings.Builder
+		for _, d := range r {
+			if d == tree.DNull {
+				key.WriteByte('n')
+			} else {
+				key.WriteByte('v')
+			}
+		}
+		pat, ok := patterns[key.String()]
+		if !ok {
+			pat = &nullPattern{}
+			for i, d := range r {
+				if d == tree.DNull {
+					pat.nulls = append(pat.nulls, i)
+				} else {
+					pat.nonNulls = append(pat.nonNulls, i)
+				}
+			}
+			patterns[key.String()] = pat
+			keys = append(keys, key.String())
+		}
+		nonNull := make(tree.Datums, len(pat.nonNulls))
+		for i, ord := range pat.nonNulls {
+			nonNull[i] = r[ord]
+		}
+		pat.rows = append(pat.rows, nonNull)
+	}
+
+	preds := make([]string, len(keys))
+	for i, key := range keys {
+		pat := patterns[key]
+		var conds []string
+		if len(pat.nonNulls) > 0 {
+			cols := make([]string, len(pat.nonNulls))
+			for j, ord := range pat.nonNulls {
+				cols[j] = "(" + exprs[ord] + ")"
+			}
+			conds = append(conds, formatInTuples(cols, pat.rows))
+		}
+		for _, ord := range pat.nulls {
+			conds = append(conds, fmt.Sprintf("(%s) IS NULL", exprs[ord]))
+		}
+		preds[i] = "(" + strings.Join(conds, " AND ") + ")"
+	}
+	return strings.Join(preds, " OR ")
+}
+
+// datumsKey returns a key that is equal for two rows if and only if they
+// hold the same values.
+func datumsKey(row tree.Datums) string {
+	return formatTuple(row)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/unique"
 	"github.com/cockroachdb/errors"
 )
@@ -551,11 +552,13 @@ func EncodeInvertedIndexPrefixKeys(
 
 // EncodeInvertedIndexTableKeys produces one inverted index key per element in
 // the input datum, which should be a container (either JSON or Array). For
-// JSON, "element" means unique path through the document. Each output key is
-// prefixed by inKey, and is guaranteed to be lexicographically sortable, but
-// not guaranteed to be round-trippable during decoding. If the input Datum
-// is (SQL) NULL, no inverted index keys will be produced, because inverted
-// indexes cannot and do not need to satisfy the predicate col IS NULL.
+// JSON, "element" means unique path through the document. For ranges and
+// multiranges, "element" means a cell covering the values of the range (see
+// range_index_encoding.go). Each output key is prefixed by inKey, and is
+// guaranteed to be lexicographically sortable, but not guaranteed to be
+// round-trippable during decoding. If the input Datum is (SQL) NULL, no
+// inverted index keys will be produced, because inverted indexes cannot and do
+// not need to satisfy the predicate col IS NULL.
 //
 // This function does not return keys for empty arrays or for NULL array
 // elements unless the version is at least
@@ -577,17 +580,21 @@ func EncodeInvertedIndexTableKeys(
 		return json.EncodeInvertedIndexKeys(inKey, val.(*tree.DJSON).JSON)
 	case types.ArrayFamily:
 		return encodeArrayInvertedIndexTableKeys(val.(*tree.DArray), inKey, version, false /* excludeNulls */)
+	case types.TSVectorFamily:
+		return tsearch.EncodeInvertedIndexKeys(inKey, val.(*tree.DTSVector).TSVector)
+	case types.RangeFamily, types.MultirangeFamily:
+		return encodeRangeInvertedIndexTableKeys(datum, inKey)
 	}
 	return nil, errors.AssertionFailedf("trying to apply inverted index to unsupported type %s", datum.ResolvedType())
 }
 
 // EncodeContainingInvertedIndexSpans returns the spans that must be scanned in
 // the inverted index to evaluate a contains (@>) predicate with the given
-// datum, which should be a container (either JSON or Array). These spans
-// should be used to find the objects in the index that contain the given json
-// or array. In other words, if we have a predicate x @> y, this function
-// should use the value of y to find the spans to scan in an inverted index on
-// x.
+// datum, which should be a container (JSON, Array, range or multirange).
+// These spans should be used to find the objects in the index that contain the
+// given json, array or range. In other words, if we have a predicate x @> y,
+// this function should use the value of y to find the spans to scan in an
+// inverted index on x.
 //
 // The spans are returned in an inverted.SpanExpression, which represents the
 // set operations that must be applied on the spans read during execution. See
@@ -604,6 +611,8 @@ func EncodeContainingInvertedIndexSpans(
 		return json.EncodeContainingInvertedIndexSpans(nil /* inKey */, val.(*tree.DJSON).JSON)
 	case types.ArrayFamily:
 		return encodeContainingArrayInvertedIndexSpans(val.(*tree.DArray), nil /* inKey */)
+	case types.RangeFamily, types.MultirangeFamily:
+		return encodeContainingRangeInvertedIndexSpans(datum, nil /* inKey */)
 	default:
 		return nil, errors.AssertionFailedf(
 			"trying to apply inverted index to unsupported type %s", datum.ResolvedType(),
@@ -613,11 +622,11 @@ func EncodeContainingInvertedIndexSpans(
 
 // EncodeContainedInvertedIndexSpans returns the spans that must be scanned in
 // the inverted index to evaluate a contained by (<@) predicate with the given
-// datum, which should be a container (either an Array or JSON). These spans
-// should be used to find the objects in the index that could be contained by
-// the given json or array. In other words, if we have a predicate x <@ y, this
-// function should use the value of y to find the spans to scan in an inverted
-// index on x.
+// datum, which should be a container (an Array, JSON, range or multirange).
+// These spans should be used to find the objects in the index that could be
+// contained by the given json, array or range. In other words, if we have a
+// predicate x <@ y, this function should use the value of y to find the spans
+// to scan in an inverted index on x.
 //
 // The spans are returned in an inverted.SpanExpression, which represents the
 // set operations that must be applied on the spans read during execution. The
@@ -635,6 +644,8 @@ func EncodeContainedInvertedIndexSpans(
 		return encodeContainedArrayInvertedIndexSpans(val.(*tree.DArray), nil /* inKey */)
 	case types.JsonFamily:
 		return json.EncodeContainedInvertedIndexSpans(nil /* inKey */, val.(*tree.DJSON).JSON)
+	case types.RangeFamily, types.MultirangeFamily:
+		return encodeContainedRangeInvertedIndexSpans(datum, nil /* inKey */)
 	default:
 		return nil, errors.AssertionFailedf(
 			"trying to apply inverted index to unsupported type %s", datum.ResolvedType(),
@@ -637,6 +637,142 @@ func TestEncodeContainedArrayInvertedIndexSpans(t *testing.T) {
 	}
 }
 
+func TestEncodeRangeInvertedIndexSpans(t *testing.T) {
+	testCases := []struct {
+		indexedValue string
+		op           string
+		value        string
+		containsKeys bool
+		expected     bool
+	}{
+		// This test uses EncodeInvertedIndexTableKeys and the span encoding
+		// function of each operator to determine if the spans produced from the
+		// value will correctly include or exclude the indexed value, indicated by
+		// containsKeys. Then, if indexedValue <op> value, expected is true.
+
+		// The spans of overlapping ranges always include each other, as do the
+		// spans of ranges which are close to each other.
+		{`{[1,5)}`, `&&`, `{[4,8)}`, true, true},
+		{`{[1,5)}`, `&&`, `{[5,8)}`, true, false},
+		{`{(,)}`, `&&`, `{[1000,2000)}`, true, true},
+		{`{[1,3), [1000,1001)}`, `&&`, `{[1000,2000)}`, true, true},
+		{`{[1,2)}`, `&&`, `{[1000,2000)}`, false, false},
+		{`{}`, `&&`, `{[1,5)}`, false, false},
+		{`{[1,2)}`, `&&`, `{}`, false, false},
+
+		{`{[1,10)}`, `@>`, `{[2,5)}`, true, true},
+		{`{[1,10)}`, `@>`, `{[2,5), [1000,2000)}`, false, false},
+		{`{[1,10)}`, `@>`, `{}`, true, true},
+		{`{}`, `@>`, `{}`, true, true},
+		{`{}`, `@>`, `{[1,2)}`, false, false},
+
+		{`{[2,5)}`, `<@`, `{[1,10)}`, true, true},
+		{`{[1,20)}`, `<@`, `{[1,10), [15,30)}`, true, false},
+		{`{}`, `<@`, `{[1,10)}`, true, true},
+		{`{}`, `<@`, `{}`, true, true},
+		{`{[1,2)}`, `<@`, `{}`, false, false},
+		{`{[1,2)}`, `<@`, `{[1000,2000)}`, false, false},
+	}
+
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+	parseMultirange := func(s string) *tree.DMultirange {
+		mr, _, err := tree.ParseDMultirange(&evalCtx, s, types.Int8Multirange)
+		if err != nil {
+			t.Fatalf("Failed to parse multirange %s: %v", s, err)
+		}
+		return mr
+	}
+
+	eval := func(indexedValue *tree.DMultirange, op string, value *tree.DMultirange) bool {
+		switch op {
+		case `&&`:
+			return indexedValue.Overlaps(value)
+		case `@>`:
+			return indexedValue.Contains(value)
+		case `<@`:
+			return value.Contains(indexedValue)
+		}
+		t.Fatalf("unexpected operator %s", op)
+		return false
+	}
+
+	runTest := func(indexedValue *tree.DMultirange, op string, value *tree.DMultirange, expectContainsKeys bool) {
+		keys, err := EncodeInvertedIndexTableKeys(indexedValue, nil, descpb.PrimaryIndexWithStoredColumnsVersion)
+		require.NoError(t, err)
+
+		var invertedExpr inverted.Expression
+		switch op {
+		case `&&`:
+			invertedExpr, err = EncodeOverlappingInvertedIndexSpans(&evalCtx, value)
+		case `@>`:
+			invertedExpr, err = EncodeContainingInvertedIndexSpans(&evalCtx, value)
+		case `<@`:
+			invertedExpr, err = EncodeContainedInvertedIndexSpans(&evalCtx, value)
+		}
+		require.NoError(t, err)
+
+		spanExpr, ok := invertedExpr.(*inverted.SpanExpression)
+		if !ok {
+			t.Fatalf("invertedExpr %v is not a SpanExpression", invertedExpr)
+		}
+
+		// Range spans are only tight when nothing overlaps the empty value, or
+		// when everything contains it.
+		expectTight := value.IsEmpty() && op != `<@`
+		if spanExpr.Tight != expectTight {
+			t.Errorf("For %s %s, expected tight=%t, but got %t", op, value, expectTight, spanExpr.Tight)
+		}
+
+		// Check if the indexedValue is included by the spans.
+		containsKeys, err := spanExpr.ContainsKeys(keys)
+		require.NoError(t, err)
+
+		if containsKeys != expectContainsKeys {
+			if expectContainsKeys {
+				t.Errorf("expected spans of %s %s to include %s but they did not", op, value, indexedValue)
+			} else {
+				t.Errorf("expected spans of %s %s not to include %s but they did", op, value, indexedValue)
+			}
+		}
+	}
+
+	// Run pre-defined test cases from above.
+	for _, c := range testCases {
+		indexedValue, value := parseMultirange(c.indexedValue), parseMultirange(c.value)
+
+		// First check that evaluating `indexedValue <op> value` matches the
+		// expected result.
+		if res := eval(indexedValue, c.op, value); res != c.expected {
+			t.Fatalf(
+				"expected value of %s %s %s did not match actual value. Expected: %v. Got: %v",
+				c.indexedValue, c.op, c.value, c.expected, res,
+			)
+		}
+
+		runTest(indexedValue, c.op, value, c.containsKeys)
+	}
+
+	// Run a set of randomly generated test cases.
+	rng, _ := randutil.NewTestRand()
+	for i := 0; i < 100; i++ {
+		typ := types.MultirangeTypes[rng.Intn(len(types.MultirangeTypes))]
+
+		// Generate two random multiranges and evaluate the result of each
+		// operator.
+		left := randgen.RandDatum(rng, typ, false /* nullOk */).(*tree.DMultirange)
+		right := randgen.RandDatum(rng, typ, false /* nullOk */).(*tree.DMultirange)
+
+		// We cannot check for false positives with these tests (due to the fact
+		// that the spans are not tight), so we will only test for false
+		// negatives.
+		for _, op := range []string{`&&`, `@>`, `<@`} {
+			if eval(left, op, right) {
+				runTest(left, op, right, true)
+			}
+		}
+	}
+}
+
 // ExtractIndexKey constructs the index (primary) key for a row from any index
 // key/value entry, including secondary indexes.
 //
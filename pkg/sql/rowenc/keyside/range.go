@@ -0,0 +1,178 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package keyside
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// The markers used in the key encoding of ranges. They are chosen so that the
+// encoding sorts in the same order as tree.DRange.Compare: the empty range
+// sorts first, an unbounded lower bound sorts before any other lower bound and
+// an unbounded upper bound sorts after any other upper bound. For bounds with
+// the same value, an inclusive lower bound sorts before an exclusive one, and
+// an exclusive upper bound sorts before an inclusive one.
+const (
+	rangeEmptyMarker    = 0
+	rangeNonEmptyMarker = 1
+
+	rangeLowerInfMarker = 0
+	rangeBoundedMarker  = 1
+	rangeUpperInfMarker = 2
+
+	rangeLowerIncMarker = 0
+	rangeLowerExcMarker = 1
+	rangeUpperExcMarker = 0
+	rangeUpperIncMarker = 1
+)
+
+// encodeRangeKey generates an ordered key encoding of a range. The bounds of
+// the range are encoded in ascending order, using the markers above, and the
+// result is then encoded as bytes in the given direction. Encoding the range
+// as a single value allows it to be skipped like any other key value.
+func encodeRangeKey(b []byte, r *tree.DRange, dir encoding.Direction) ([]byte, error) {
+	var inner []byte
+	if r.Empty {
+		inner = encoding.EncodeVarintAscending(inner, rangeEmptyMarker)
+	} else {
+		inner = encoding.EncodeVarintAscending(inner, rangeNonEmptyMarker)
+		var err error
+		if inner, err = encodeRangeBound(
+			inner, r.Lower, rangeLowerInfMarker, r.LowerInc, rangeLowerIncMarker, rangeLowerExcMarker,
+		); err != nil {
+			return nil, err
+		}
+		if inner, err = encodeRangeBound(
+			inner, r.Upper, rangeUpperInfMarker, r.UpperInc, rangeUpperIncMarker, rangeUpperExcMarker,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if dir == encoding.Ascending {
+		return encoding.EncodeBytesAscending(b, inner), nil
+	}
+	return encoding.EncodeBytesDescending(b, inner), nil
+}
+
+// encodeRangeBound encodes one of the bounds of a non-empty range in
+// ascending order.
+func encodeRangeBound(
+	b []byte, val tree.Datum, infMarker int64, inclusive bool, incMarker, excMarker int64,
+) ([]byte, error) {
+	if val == tree.DNull {
+		return encoding.EncodeVarintAscending(b, infMarker), nil
+	}
+	b = encoding.EncodeVarintAscending(b, rangeBoundedMarker)
+	b, err := Encode(b, val, encoding.Ascending)
+	if err != nil {
+		return nil, err
+	}
+	if inclusive {
+		return encoding.EncodeVarintAscending(b, incMarker), nil
+	}
+	return encoding.EncodeVarintAscending(b, excMarker), nil
+}
+
+// decodeRangeKey decodes a range key generated by encodeRangeKey.
+func decodeRangeKey(
+	a *tree.DatumAlloc, t *types.T, key []byte, dir encoding.Direction,
+) (tree.Datum, []byte, error) {
+	var inner []byte
+	var err error
+	if dir == encoding.Ascending {
+		key, inner, err = encoding.DecodeBytesAscending(key, nil)
+	} else {
+		key, inner, err = encoding.DecodeBytesDescending(key, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var marker int64
+	if inner, marker, err = encoding.DecodeVarintAscending(inner); err != nil {
+		return nil, nil, err
+	}
+	if marker == rangeEmptyMarker {
+		return tree.NewDEmptyRange(t), key, nil
+	}
+	var bounds [2]tree.Datum
+	var inclusive [2]bool
+	for i, incMarker := range []int64{rangeLowerIncMarker, rangeUpperIncMarker} {
+		if inner, marker, err = encoding.DecodeVarintAscending(inner); err != nil {
+			return nil, nil, err
+		}
+		if marker != rangeBoundedMarker {
+			bounds[i] = tree.DNull
+			continue
+		}
+		if bounds[i], inner, err = Decode(a, t.RangeContents(), inner, encoding.Ascending); err != nil {
+			return nil, nil, err
+		}
+		if inner, marker, err = encoding.DecodeVarintAscending(inner); err != nil {
+			return nil, nil, err
+		}
+		inclusive[i] = marker == incMarker
+	}
+	if len(inner) != 0 {
+		return nil, nil, errors.AssertionFailedf("invalid range encoding (%d trailing bytes)", len(inner))
+	}
+	r, err := tree.NewDRange(t, bounds[0], bounds[1], inclusive[0], inclusive[1])
+	return r, key, err
+}
+
+// encodeMultirangeKey generates an ordered key encoding of a multirange. The
+// key encodings of its ranges are concatenated in ascending order, which sorts
+// in the same order as tree.DMultirange.Compare since the key encoding of a
+// range is never a prefix of the encoding of another range, and the result is
+// then encoded as bytes in the given direction.
+func encodeMultirangeKey(b []byte, m *tree.DMultirange, dir encoding.Direction) ([]byte, error) {
+	var inner []byte
+	for _, r := range m.Ranges {
+		var err error
+		if inner, err = encodeRangeKey(inner, r, encoding.Ascending); err != nil {
+			return nil, err
+		}
+	}
+	if dir == encoding.Ascending {
+		return encoding.EncodeBytesAscending(b, inner), nil
+	}
+	return encoding.EncodeBytesDescending(b, inner), nil
+}
+
+// decodeMultirangeKey decodes a multirange key generated by
+// encodeMultirangeKey.
+func decodeMultirangeKey(
+	a *tree.DatumAlloc, t *types.T, key []byte, dir encoding.Direction,
+) (tree.Datum, []byte, error) {
+	var inner []byte
+	var err error
+	if dir == encoding.Ascending {
+		key, inner, err = encoding.DecodeBytesAscending(key, nil)
+	} else {
+		key, inner, err = encoding.DecodeBytesDescending(key, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ranges []*tree.DRange
+	for len(inner) > 0 {
+		var r tree.Datum
+		if r, inner, err = decodeRangeKey(a, t.MultirangeContents(), inner, encoding.Ascending); err != nil {
+			return nil, nil, err
+		}
+		ranges = append(ranges, r.(*tree.DRange))
+	}
+	return tree.NewDMultirange(t, ranges...), key, nil
+}
@@ -0,0 +1,307 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package rowenc
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/unique"
+	"github.com/cockroachdb/errors"
+)
+
+// Inverted indexes on range and multirange columns map the values of the
+// range element type onto the uint64 domain, which is divided into a
+// hierarchy of cells: the single cell at level 0 spans the entire domain, and
+// each cell at level L is split into two cells at level L+1. Each range is
+// covered by at most rangeIndexMaxCells cells of the finest possible level,
+// and an index entry is written for each of these cells. Two ranges can only
+// overlap if one of the cells covering the first range is equal to, an
+// ancestor of, or a descendant of one of the cells covering the second range,
+// so an overlap query scans the ancestors of the cells covering the queried
+// range, as well as the spans of their descendants.
+//
+// Cells are identified in the same way as S2 cells: the id of a cell at
+// level L has its lowest set bit at position 63-L, and the bits above it are
+// the path from the root. This means that the ids of the descendants of a cell
+// form a contiguous span around the id of the cell. The id 0 is never used for
+// a cell, and is used to index empty ranges and multiranges.
+//
+// The mapping of element values onto the domain is monotonic but not
+// necessarily injective (e.g. for decimals, which are mapped to floats), so
+// the spans are never tight and the original filter must be applied to the
+// rows they return.
+
+const (
+	// rangeIndexMaxLevel is the level of the smallest cells, each of which
+	// spans two values of the domain.
+	rangeIndexMaxLevel = 63
+
+	// rangeIndexMaxCells is the maximum number of cells used to cover a single
+	// range.
+	rangeIndexMaxCells = 4
+
+	// rangeIndexEmptyCellID is the cell id used to index empty ranges and
+	// multiranges.
+	rangeIndexEmptyCellID = 0
+)
+
+// rangeIndexCellLSB returns the lowest set bit of the ids of the cells at the
+// given level.
+func rangeIndexCellLSB(level int) uint64 {
+	return uint64(1) << (rangeIndexMaxLevel - level)
+}
+
+// rangeIndexCellID returns the id of the cell at the given level which
+// contains the value v.
+func rangeIndexCellID(v uint64, level int) uint64 {
+	lsb := rangeIndexCellLSB(level)
+	return (v &^ (2*lsb - 1)) | lsb
+}
+
+// rangeIndexElementValue maps an element of a range onto the uint64 domain
+// of the cells. The mapping preserves the order of the elements.
+func rangeIndexElementValue(d tree.Datum) (uint64, error) {
+	const signBit = uint64(1) << 63
+	switch t := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DInt:
+		return uint64(*t) ^ signBit, nil
+	case *tree.DDate:
+		return uint64(t.UnixEpochDays()) ^ signBit, nil
+	case *tree.DTimestamp:
+		return uint64(t.UnixMicro()) ^ signBit, nil
+	case *tree.DTimestampTZ:
+		return uint64(t.UnixMicro()) ^ signBit, nil
+	case *tree.DDecimal:
+		// Decimals which are out of the range of a float64 are converted to an
+		// infinity, so the error can be ignored.
+		f, _ := t.Float64()
+		if math.IsNaN(f) {
+			// NaN sorts before all other decimals.
+			return 0, nil
+		}
+		b := math.Float64bits(f)
+		if b&signBit != 0 {
+			return ^b, nil
+		}
+		return b | signBit, nil
+	}
+	return 0, errors.AssertionFailedf("unexpected range element type %s", d.ResolvedType())
+}
+
+// rangeIndexIntervals returns the closed intervals of the uint64 domain which
+// contain the values of the given range or multirange. Returns ok=false if the
+// range or multirange is empty.
+func rangeIndexIntervals(d tree.Datum) (intervals [][2]uint64, ok bool, err error) {
+	var ranges []*tree.DRange
+	switch t := tree.UnwrapDatum(nil, d).(type) {
+	case *tree.DRange:
+		ranges = []*tree.DRange{t}
+	case *tree.DMultirange:
+		ranges = t.Ranges
+	default:
+		return nil, false, errors.AssertionFailedf("unexpected range type %s", d.ResolvedType())
+	}
+	for _, r := range ranges {
+		if r.Empty {
+			continue
+		}
+		lo, hi := uint64(0), uint64(math.MaxUint64)
+		if r.Lower != tree.DNull {
+			if lo, err = rangeIndexElementValue(r.Lower); err != nil {
+				return nil, false, err
+			}
+		}
+		if r.Upper != tree.DNull {
+			if hi, err = rangeIndexElementValue(r.Upper); err != nil {
+				return nil, false, err
+			}
+		}
+		intervals = append(intervals, [2]uint64{lo, hi})
+	}
+	return intervals, len(intervals) > 0, nil
+}
+
+// rangeIndexCovering returns the ids of the cells which cover the closed
+// interval [lo, hi]. The cells all have the finest level for which at most
+// rangeIndexMaxCells cells are needed.
+func rangeIndexCovering(lo, hi uint64) []uint64 {
+	level := rangeIndexMaxLevel
+	for ; level > 0; level-- {
+		shift := uint(64 - level)
+		if (hi>>shift)-(lo>>shift) < rangeIndexMaxCells {
+			break
+		}
+	}
+	lsb := rangeIndexCellLSB(level)
+	last := rangeIndexCellID(hi, level)
+	cells := make([]uint64, 0, rangeIndexMaxCells)
+	for id := rangeIndexCellID(lo, level); ; id += 2 * lsb {
+		cells = append(cells, id)
+		if id == last {
+			break
+		}
+	}
+	return cells
+}
+
+// encodeRangeInvertedIndexTableKeys returns the inverted index keys for the
+// given range or multirange, one per cell covering its values. The input inKey
+// is prefixed to all returned keys.
+func encodeRangeInvertedIndexTableKeys(val tree.Datum, inKey []byte) ([][]byte, error) {
+	intervals, ok, err := rangeIndexIntervals(val)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return [][]byte{encodeRangeInvertedIndexKey(inKey, rangeIndexEmptyCellID)}, nil
+	}
+	var outKeys [][]byte
+	for _, interval := range intervals {
+		for _, id := range rangeIndexCovering(interval[0], interval[1]) {
+			outKeys = append(outKeys, encodeRangeInvertedIndexKey(inKey, id))
+		}
+	}
+	return unique.UniquifyByteSlices(outKeys), nil
+}
+
+// encodeRangeInvertedIndexKey returns the inverted index key for the cell
+// with the given id, prefixed by inKey.
+func encodeRangeInvertedIndexKey(inKey []byte, id uint64) []byte {
+	outKey := make([]byte, len(inKey), len(inKey)+encoding.MaxVarintLen)
+	copy(outKey, inKey)
+	return encoding.EncodeUvarintAscending(outKey, id)
+}
+
+// encodeOverlappingRangeInvertedIndexSpans returns the spans that must be
+// scanned in the inverted index to find the ranges or multiranges which may
+// overlap the closed interval [lo, hi]. The input inKey is prefixed to all
+// returned keys.
+func encodeOverlappingRangeInvertedIndexSpans(inKey []byte, lo, hi uint64) inverted.Expression {
+	var invertedExpr inverted.Expression
+	addSpan := func(span inverted.Span) {
+		spanExpr := inverted.ExprForSpan(span, false /* tight */)
+		if invertedExpr == nil {
+			invertedExpr = spanExpr
+		} else {
+			invertedExpr = inverted.Or(invertedExpr, spanExpr)
+		}
+	}
+	for _, id := range rangeIndexCovering(lo, hi) {
+		// Scan the cell and all of its descendants, which form a contiguous span
+		// of ids.
+		cellLevel := rangeIndexMaxLevel - bits.TrailingZeros64(id)
+		lsb := rangeIndexCellLSB(cellLevel)
+		addSpan(inverted.Span{
+			Start: encodeRangeInvertedIndexKey(inKey, id-lsb+1),
+			End:   inverted.EncVal(roachpb.Key(encodeRangeInvertedIndexKey(inKey, id+lsb-1)).PrefixEnd()),
+		})
+		// Scan each of the ancestors of the cell.
+		for level := 0; level < cellLevel; level++ {
+			addSpan(inverted.MakeSingleValSpan(
+				encodeRangeInvertedIndexKey(inKey, rangeIndexCellID(id, level)),
+			))
+		}
+	}
+	return invertedExpr
+}
+
+// EncodeOverlappingInvertedIndexSpans returns the spans that must be scanned
+// in the inverted index to evaluate an overlaps (&&) predicate with the given
+// datum, which should be a range or a multirange. In other words, if we have
+// a predicate x && y, this function should use the value of y to find the
+// spans to scan in an inverted index on x.
+//
+// The spans are returned in an inverted.SpanExpression, which represents the
+// set operations that must be applied on the spans read during execution. The
+// span expression returned will never be tight.
+func EncodeOverlappingInvertedIndexSpans(
+	evalCtx *tree.EvalContext, val tree.Datum,
+) (invertedExpr inverted.Expression, err error) {
+	if val == tree.DNull {
+		return nil, nil
+	}
+	intervals, ok, err := rangeIndexIntervals(val)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Nothing overlaps an empty range.
+		return &inverted.SpanExpression{Tight: true, Unique: true}, nil
+	}
+	for _, interval := range intervals {
+		spanExpr := encodeOverlappingRangeInvertedIndexSpans(nil /* inKey */, interval[0], interval[1])
+		if invertedExpr == nil {
+			invertedExpr = spanExpr
+		} else {
+			invertedExpr = inverted.Or(invertedExpr, spanExpr)
+		}
+	}
+	return invertedExpr, nil
+}
+
+// encodeContainingRangeInvertedIndexSpans returns the spans that must be
+// scanned in the inverted index to evaluate a contains (@>) predicate with
+// the given range or multirange. A non-empty range or multirange can only be
+// contained by ranges which overlap each of its ranges.
+func encodeContainingRangeInvertedIndexSpans(
+	val tree.Datum, inKey []byte,
+) (invertedExpr inverted.Expression, err error) {
+	intervals, ok, err := rangeIndexIntervals(val)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// All ranges contain the empty range. Return a SpanExpression that
+		// requires a full scan of the inverted index.
+		invertedExpr = inverted.ExprForSpan(
+			inverted.MakeSingleValSpan(inKey), true, /* tight */
+		)
+		return invertedExpr, nil
+	}
+	for _, interval := range intervals {
+		spanExpr := encodeOverlappingRangeInvertedIndexSpans(inKey, interval[0], interval[1])
+		if invertedExpr == nil {
+			invertedExpr = spanExpr
+		} else {
+			invertedExpr = inverted.And(invertedExpr, spanExpr)
+		}
+	}
+	return invertedExpr, nil
+}
+
+// encodeContainedRangeInvertedIndexSpans returns the spans that must be
+// scanned in the inverted index to evaluate a contained by (<@) predicate with
+// the given range or multirange. A range or multirange is contained by val if
+// it is empty, or if it overlaps val.
+func encodeContainedRangeInvertedIndexSpans(
+	val tree.Datum, inKey []byte,
+) (invertedExpr inverted.Expression, err error) {
+	// The empty range should always be added to the spans, since it is
+	// contained by everything.
+	invertedExpr = inverted.ExprForSpan(
+		inverted.MakeSingleValSpan(encodeRangeInvertedIndexKey(inKey, rangeIndexEmptyCellID)),
+		false, /* tight */
+	)
+	intervals, _, err := rangeIndexIntervals(val)
+	if err != nil {
+		return nil, err
+	}
+	for _, interval := range intervals {
+		spanExpr := encodeOverlappingRangeInvertedIndexSpans(inKey, interval[0], interval[1])
+		invertedExpr = inverted.Or(invertedExpr, spanExpr)
+	}
+	return invertedExpr, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/errors"
 )
 
@@ -78,6 +79,14 @@ func Encode(appendTo []byte, colID ColumnIDDelta, val tree.Datum, scratch []byte
 			return nil, err
 		}
 		return encoding.EncodeJSONValue(appendTo, uint32(colID), encoded), nil
+	case *tree.DTSQuery:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), tsearch.EncodeTSQuery(scratch[:0], t.TSQuery)), nil
+	case *tree.DTSVector:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), tsearch.EncodeTSVector(scratch[:0], t.TSVector)), nil
+	case *tree.DRange:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(rangeString(t))), nil
+	case *tree.DMultirange:
+		return encoding.EncodeBytesValue(appendTo, uint32(colID), []byte(rangeString(t))), nil
 	case *tree.DArray:
 		a, err := encodeArray(t, scratch)
 		if err != nil {
@@ -118,3 +127,11 @@ func MakeColumnIDDelta(previous, current descpb.ColumnID) ColumnIDDelta {
 	}
 	return ColumnIDDelta(current - previous)
 }
+
+// rangeString returns the text representation of a range or a multirange,
+// which is used as its value encoding. Unlike the key encoding of a range, it
+// preserves the exact values of the bounds, such as the trailing zeros of
+// decimals.
+func rangeString(r tree.Datum) string {
+	return tree.AsStringWithFlags(r, tree.FmtPgwireText)
+}
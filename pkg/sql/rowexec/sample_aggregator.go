@@ -489,6 +489,33 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				columnIDs[i] = s.sampledCols[c]
 			}
 
+			stat := &stats.TableStatisticProto{
+				TableID:       s.tableID,
+				Name:          si.spec.StatName,
+				ColumnIDs:     columnIDs,
+				RowCount:      uint64(si.numRows),
+				DistinctCount: uint64(s.getDistinctCount(&si, true /* includeNulls */)),
+				NullCount:     uint64(si.numNulls),
+				AvgSize:       uint64(s.getAvgSize(&si)),
+				HistogramData: histogram,
+			}
+			if si.spec.FullStatisticID != 0 {
+				// This is a partial statistic on the extremes of the column, so
+				// merge it into the full statistic it was collected against before
+				// the latter is deleted below.
+				var err error
+				if stat, err = stats.MergeExtremesStatistic(
+					ctx,
+					s.FlowCtx.Cfg.Executor,
+					txn,
+					s.tableID,
+					si.spec.FullStatisticID,
+					stat,
+				); err != nil {
+					return err
+				}
+			}
+
 			// Delete old stats that have been superseded.
 			if err := stats.DeleteOldStatsForColumns(
 				ctx,
@@ -507,13 +534,13 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				s.FlowCtx.Cfg.Executor,
 				txn,
 				s.tableID,
-				si.spec.StatName,
+				stat.Name,
 				columnIDs,
-				si.numRows,
-				s.getDistinctCount(&si, true /* includeNulls */),
-				si.numNulls,
-				s.getAvgSize(&si),
-				histogram); err != nil {
+				int64(stat.RowCount),
+				int64(stat.DistinctCount),
+				int64(stat.NullCount),
+				int64(stat.AvgSize),
+				stat.HistogramData); err != nil {
 				return err
 			}
 
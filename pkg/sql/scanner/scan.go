@@ -366,6 +366,10 @@ func (s *Scanner) Scan(lval ScanSymType) {
 			s.pos++
 			lval.SetID(lexbase.CONTAINS)
 			return
+		case '@': // @@
+			s.pos++
+			lval.SetID(lexbase.AT_AT)
+			return
 		}
 		return
 
@@ -390,6 +394,13 @@ func (s *Scanner) Scan(lval ScanSymType) {
 			s.pos++
 			lval.SetID(lexbase.FETCHVAL)
 			return
+		case '|': // -|
+			if s.peekN(1) == '-' {
+				// -|-
+				s.pos += 2
+				lval.SetID(lexbase.RANGE_ADJACENT)
+				return
+			}
 		}
 		return
 
@@ -1064,6 +1075,66 @@ func LastLexicalToken(sql string) (lastTok int, ok bool) {
 	}
 }
 
+// ReplaceHiddenConstants turns a statement fingerprint, which is formatted with
+// its constants hidden (_ and '_'), into a statement that can be parsed and
+// planned, by replacing each hidden constant with a new placeholder. Elided
+// tuple elements (__more<n>__) are replaced by a single placeholder, and elided
+// rows of a VALUES clause are dropped. It returns the rewritten statement along
+// with the total number of placeholders in it.
+func ReplaceHiddenConstants(sql string) (_ string, numPlaceholders int, _ error) {
+	var s Scanner
+	s.Init(sql)
+	var toks []fakeSym
+	var ends []int
+	for {
+		var lval fakeSym
+		s.Scan(&lval)
+		if lval.id == lexbase.ERROR {
+			return "", 0, fmt.Errorf("scan error: %s", lval.s)
+		}
+		if lval.id == 0 {
+			break
+		}
+		if lval.id == lexbase.PLACEHOLDER {
+			if n, err := strconv.Atoi(lval.s); err == nil && n > numPlaceholders {
+				numPlaceholders = n
+			}
+		}
+		toks = append(toks, lval)
+		ends = append(ends, s.pos)
+	}
+
+	// Quoted identifiers, like "_", are never hidden constants.
+	isUnquotedIdent := func(tok fakeSym) bool {
+		return tok.id == lexbase.IDENT && sql[tok.pos] != '"'
+	}
+	isArity := func(tok fakeSym) bool {
+		return isUnquotedIdent(tok) && strings.HasPrefix(tok.s, "__more") && strings.HasSuffix(tok.s, "__")
+	}
+	var buf strings.Builder
+	last := 0
+	for i := 0; i < len(toks); i++ {
+		tok := toks[i]
+		if isArity(tok) && i >= 2 && toks[i-1].id == '(' && toks[i-2].id == ',' &&
+			i+1 < len(toks) && toks[i+1].id == ')' {
+			// Drop the elided rows of a VALUES clause, which would otherwise have
+			// the wrong arity.
+			buf.WriteString(sql[last:toks[i-2].pos])
+			last = ends[i+1]
+			i++
+			continue
+		}
+		if (isUnquotedIdent(tok) || tok.id == lexbase.SCONST) && tok.s == "_" || isArity(tok) {
+			numPlaceholders++
+			buf.WriteString(sql[last:tok.pos])
+			fmt.Fprintf(&buf, "$%d", numPlaceholders)
+			last = ends[i]
+		}
+	}
+	buf.WriteString(sql[last:])
+	return buf.String(), numPlaceholders, nil
+}
+
 // fakeSym is a simplified symbol type for use by
 // HasMultipleStatements.
 type fakeSym struct {
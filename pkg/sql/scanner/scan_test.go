@@ -113,3 +113,29 @@ func TestLastLexicalToken(t *testing.T) {
 		})
 	}
 }
+
+func TestReplaceHiddenConstants(t *testing.T) {
+	testCases := []struct {
+		in              string
+		expected        string
+		numPlaceholders int
+	}{
+		{`SELECT a FROM t`, `SELECT a FROM t`, 0},
+		{`SELECT a FROM t WHERE b = _`, `SELECT a FROM t WHERE b = $1`, 1},
+		{`SELECT a FROM t WHERE b = '_' AND c > $1`, `SELECT a FROM t WHERE b = $2 AND c > $1`, 2},
+		{`SELECT a_b FROM t WHERE c IN (_, _, __more3__)`, `SELECT a_b FROM t WHERE c IN ($1, $2, $3)`, 3},
+		{`INSERT INTO t VALUES (_, _), (__more10__)`, `INSERT INTO t VALUES ($1, $2)`, 2},
+		{`SELECT "_" FROM t LIMIT _`, `SELECT "_" FROM t LIMIT $1`, 1},
+		{`SELECT "__more3__" FROM t`, `SELECT "__more3__" FROM t`, 0},
+	}
+	for _, tc := range testCases {
+		actual, n, err := ReplaceHiddenConstants(tc.in)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.in, err)
+		}
+		if actual != tc.expected || n != tc.numPlaceholders {
+			t.Errorf("%q: expected %q (%d placeholders), got %q (%d placeholders)",
+				tc.in, tc.expected, tc.numPlaceholders, actual, n)
+		}
+	}
+}
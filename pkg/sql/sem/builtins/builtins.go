@@ -124,6 +124,7 @@ const (
 	categoryJSON                = "JSONB"
 	categoryMultiRegion         = "Multi-region"
 	categoryMultiTenancy        = "Multi-tenancy"
+	categoryRange               = "Range"
 	categorySequences           = "Sequence"
 	categorySpatial             = "Spatial"
 	categoryString              = "String and byte"
@@ -3670,33 +3671,19 @@ value if you rely on the HLC for accuracy.`,
 	})),
 
 	// Full text search functions.
-	"ts_match_qv":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"ts_match_vq":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"tsvector_cmp":                   makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"tsvector_concat":                makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_debug":                       makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_headline":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_lexize":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"websearch_to_tsquery":           makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"array_to_tsvector":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"get_current_ts_config":          makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"numnode":                        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"plainto_tsquery":                makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"phraseto_tsquery":               makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"querytree":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"setweight":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"strip":                          makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"to_tsquery":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"to_tsvector":                    makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"json_to_tsvector":               makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"jsonb_to_tsvector":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_delete":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_filter":                      makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"ts_rank":                        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_rank_cd":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"ts_rewrite":                     makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"tsquery_phrase":                 makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
-	"tsvector_to_array":              makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"tsvector_update_trigger":        makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 	"tsvector_update_trigger_column": makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 7821, Category: categoryFullTextSearch}),
 
@@ -6300,6 +6287,58 @@ table's zone configuration this will return NULL.`,
 			Volatility: tree.VolatilityVolatile,
 		},
 	),
+	"crdb_internal.request_statement_bundle": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlocklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"stmtFingerprint", types.String},
+				{"samplingProbability", types.Float},
+				{"minExecutionLatency", types.Interval},
+				{"expiresAfter", types.Interval},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return requestStatementBundle(
+					evalCtx, args, 0 /* numSamples */, false, /* onPlanChange */
+				)
+			},
+			Info: `Used to request statement bundle for a given statement fingerprint
+that has execution latency greater than the 'minExecutionLatency'. If the
+'expiresAfter' argument is empty, then the statement bundle request never
+expires until the statement bundle is collected. Each execution of the
+fingerprint is traced with the probability 'samplingProbability' (zero
+traces every execution).`,
+			Volatility: tree.VolatilityVolatile,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"stmtFingerprint", types.String},
+				{"samplingProbability", types.Float},
+				{"minExecutionLatency", types.Interval},
+				{"expiresAfter", types.Interval},
+				{"numSamples", types.Int},
+				{"onPlanChange", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				numSamples := int(tree.MustBeDInt(args[4]))
+				onPlanChange := bool(tree.MustBeDBool(args[5]))
+				return requestStatementBundle(evalCtx, args, numSamples, onPlanChange)
+			},
+			Info: `Used to request 'numSamples' statement bundles for a given statement
+fingerprint that have execution latency greater than the 'minExecutionLatency'.
+If 'onPlanChange' is true, only the executions whose plan differs from the plan
+of the previous execution of the fingerprint on the same node are collected.
+If the 'expiresAfter' argument is empty, then the statement bundle request
+never expires until the statement bundles are collected. Each execution of the
+fingerprint is traced with the probability 'samplingProbability' (zero traces
+every execution).`,
+			Volatility: tree.VolatilityVolatile,
+		},
+	),
 	// Deletes the underlying spans backing a table, only
 	// if the user provides explicit acknowledgement of the
 	// form "I acknowledge this will irrevocably delete all revisions
@@ -9027,3 +9066,49 @@ func prettyStatement(p tree.PrettyCfg, stmt string) (string, error) {
 	}
 	return formattedStmt.String(), nil
 }
+
+// requestStatementBundle inserts a statement diagnostics request for the
+// fingerprint, sampling probability, minimum execution latency and expiration
+// in the first four arguments.
+func requestStatementBundle(
+	evalCtx *tree.EvalContext, args tree.Datums, numSamples int, onPlanChange bool,
+) (tree.Datum, error) {
+	if evalCtx.StmtDiagnosticsRequestInserter == nil {
+		return nil, errors.AssertionFailedf("statement diagnostics request inserter not set")
+	}
+	ctx := evalCtx.Ctx()
+	hasViewActivity, err := evalCtx.SessionAccessor.HasRoleOption(ctx, roleoption.VIEWACTIVITY)
+	if err != nil {
+		return nil, err
+	}
+	if !hasViewActivity {
+		return nil, pgerror.Newf(pgcode.InsufficientPrivilege,
+			"requesting statement bundle requires %s option", roleoption.VIEWACTIVITY)
+	}
+	isAdmin, err := evalCtx.SessionAccessor.HasAdminRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		hasViewActivityRedacted, err := evalCtx.SessionAccessor.HasRoleOption(ctx, roleoption.VIEWACTIVITYREDACTED)
+		if err != nil {
+			return nil, err
+		}
+		if hasViewActivityRedacted {
+			return nil, pgerror.Newf(pgcode.InsufficientPrivilege,
+				"users with %s option cannot request statement bundles", roleoption.VIEWACTIVITYREDACTED)
+		}
+	}
+
+	stmtFingerprint := string(tree.MustBeDString(args[0]))
+	samplingProbability := float64(tree.MustBeDFloat(args[1]))
+	minExecutionLatency := time.Duration(tree.MustBeDInterval(args[2]).Nanos())
+	expiresAfter := time.Duration(tree.MustBeDInterval(args[3]).Nanos())
+	if err := evalCtx.StmtDiagnosticsRequestInserter(
+		ctx, stmtFingerprint, samplingProbability, numSamples, onPlanChange,
+		minExecutionLatency, expiresAfter,
+	); err != nil {
+		return nil, err
+	}
+	return tree.DBoolTrue, nil
+}
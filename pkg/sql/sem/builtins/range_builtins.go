@@ -0,0 +1,442 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package builtins
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/errors"
+)
+
+func initRangeBuiltins() {
+	// Add all rangeBuiltins to the builtins map after a sanity check.
+	for k, v := range rangeBuiltins {
+		if existing, exists := builtins[k]; exists {
+			if k != "lower" && k != "upper" {
+				panic("duplicate builtin: " + k)
+			}
+			// lower and upper are also string builtins. Like in Postgres, the
+			// string overloads are preferred when an argument of unknown type,
+			// such as NULL or a placeholder, makes the call ambiguous.
+			for i := range existing.overloads {
+				existing.overloads[i].PreferredOverload = true
+			}
+			existing.overloads = append(existing.overloads, v.overloads...)
+			builtins[k] = existing
+			continue
+		}
+		v.props.Category = categoryRange
+		builtins[k] = v
+	}
+
+	// Add the multirange overloads of the range builtins, and the multirange
+	// builtins.
+	for k, v := range multirangeBuiltins {
+		if existing, exists := builtins[k]; exists {
+			existing.overloads = append(existing.overloads, v.overloads...)
+			builtins[k] = existing
+			continue
+		}
+		v.props.Category = categoryRange
+		builtins[k] = v
+	}
+
+	// Add a constructor for each range and multirange type, named after the
+	// type.
+	for i, t := range types.RangeTypes {
+		for _, def := range []struct {
+			typ *types.T
+			def builtinDefinition
+		}{
+			{t, makeRangeConstructorBuiltin(t)},
+			{types.MultirangeTypes[i], makeMultirangeConstructorBuiltin(types.MultirangeTypes[i])},
+		} {
+			name := def.typ.Name()
+			if _, exists := builtins[name]; exists {
+				panic("duplicate builtin: " + name)
+			}
+			builtins[name] = def.def
+		}
+	}
+}
+
+// makeRangeConstructorBuiltin returns the definition of the constructor of
+// the given range type, which takes the lower and upper bounds, and
+// optionally the inclusivity of the bounds.
+func makeRangeConstructorBuiltin(t *types.T) builtinDefinition {
+	elemType := t.RangeContents()
+	return makeBuiltin(
+		tree.FunctionProperties{NullableArgs: true, Category: categoryRange},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"lower", elemType}, {"upper", elemType}},
+			ReturnType: tree.FixedReturnType(t),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tree.NewDRange(t, args[0], args[1], true /* lowerInc */, false /* upperInc */)
+			},
+			Info: "Constructs a range with an inclusive lower bound and an exclusive upper " +
+				"bound. A NULL bound makes the range unbounded on that side.",
+			Volatility: tree.VolatilityImmutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"lower", elemType}, {"upper", elemType}, {"bounds", types.String},
+			},
+			ReturnType: tree.FixedReturnType(t),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if args[2] == tree.DNull {
+					return nil, pgerror.New(pgcode.NullValueNotAllowed,
+						"range constructor flags argument must not be null")
+				}
+				lowerInc, upperInc, err := parseRangeBoundFlags(string(tree.MustBeDString(args[2])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDRange(t, args[0], args[1], lowerInc, upperInc)
+			},
+			Info: "Constructs a range with the given bounds, whose inclusivity is given by " +
+				"'[]', '[)', '(]' or '()'. A NULL bound makes the range unbounded on that side.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	)
+}
+
+// makeMultirangeConstructorBuiltin returns the definition of the constructor
+// of the given multirange type, which takes any number of ranges.
+func makeMultirangeConstructorBuiltin(t *types.T) builtinDefinition {
+	return makeBuiltin(
+		tree.FunctionProperties{NullableArgs: true, Category: categoryRange},
+		tree.Overload{
+			Types:      tree.VariadicType{VarType: t.MultirangeContents()},
+			ReturnType: tree.FixedReturnType(t),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				ranges := make([]*tree.DRange, len(args))
+				for i := range args {
+					if args[i] == tree.DNull {
+						return nil, pgerror.New(pgcode.NullValueNotAllowed,
+							"multirange values cannot contain null members")
+					}
+					ranges[i] = tree.MustBeDRange(args[i])
+				}
+				return tree.NewDMultirange(t, ranges...), nil
+			},
+			Info:       "Constructs a multirange which contains the values of the given ranges.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	)
+}
+
+// parseRangeBoundFlags parses the bounds argument of a range constructor.
+func parseRangeBoundFlags(s string) (lowerInc, upperInc bool, _ error) {
+	if len(s) == 2 && (s[0] == '[' || s[0] == '(') && (s[1] == ']' || s[1] == ')') {
+		return s[0] == '[', s[1] == ']', nil
+	}
+	return false, false, errors.WithHint(
+		pgerror.New(pgcode.Syntax, "invalid range bound flags"),
+		`Valid values are "[]", "[)", "(]", and "()".`,
+	)
+}
+
+// makeRangeBuiltin returns the definition of a builtin with an overload for
+// each range type. The argument types and the return type of each overload
+// are derived from the range type.
+func makeRangeBuiltin(
+	argNames []string,
+	retType func(t *types.T) *types.T,
+	info string,
+	fn func(args []*tree.DRange) (tree.Datum, error),
+) builtinDefinition {
+	overloads := make([]tree.Overload, 0, len(types.RangeTypes))
+	for _, t := range types.RangeTypes {
+		argTypes := make(tree.ArgTypes, len(argNames))
+		for i, name := range argNames {
+			argTypes[i].Name = name
+			argTypes[i].Typ = t
+		}
+		overloads = append(overloads, tree.Overload{
+			Types:      argTypes,
+			ReturnType: tree.FixedReturnType(retType(t)),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				ranges := make([]*tree.DRange, len(args))
+				for i := range args {
+					ranges[i] = tree.MustBeDRange(args[i])
+				}
+				return fn(ranges)
+			},
+			Info:       info,
+			Volatility: tree.VolatilityImmutable,
+		})
+	}
+	return makeBuiltin(defProps(), overloads...)
+}
+
+// makeMultirangeBuiltin returns the definition of a builtin with an overload
+// for each multirange type. The argument types and the return type of each
+// overload are derived from the multirange type.
+func makeMultirangeBuiltin(
+	argNames []string,
+	retType func(t *types.T) *types.T,
+	info string,
+	fn func(args []*tree.DMultirange) (tree.Datum, error),
+) builtinDefinition {
+	overloads := make([]tree.Overload, 0, len(types.MultirangeTypes))
+	for _, t := range types.MultirangeTypes {
+		argTypes := make(tree.ArgTypes, len(argNames))
+		for i, name := range argNames {
+			argTypes[i].Name = name
+			argTypes[i].Typ = t
+		}
+		overloads = append(overloads, tree.Overload{
+			Types:      argTypes,
+			ReturnType: tree.FixedReturnType(retType(t)),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				multiranges := make([]*tree.DMultirange, len(args))
+				for i := range args {
+					multiranges[i] = tree.MustBeDMultirange(args[i])
+				}
+				return fn(multiranges)
+			},
+			Info:       info,
+			Volatility: tree.VolatilityImmutable,
+		})
+	}
+	return makeBuiltin(defProps(), overloads...)
+}
+
+// rangeContents returns the element type of the given range type.
+func rangeContents(t *types.T) *types.T { return t.RangeContents() }
+
+// multirangeElemType returns the element type of the ranges of the given
+// multirange type.
+func multirangeElemType(t *types.T) *types.T { return t.MultirangeContents().RangeContents() }
+
+// multirangeContents returns the range type of the given multirange type.
+func multirangeContents(t *types.T) *types.T { return t.MultirangeContents() }
+
+// rangeType returns the given range type.
+func rangeType(t *types.T) *types.T { return t }
+
+// boolType returns the BOOL type for any range type.
+func boolType(*types.T) *types.T { return types.Bool }
+
+// rangeBuiltins contains the range built-in functions indexed by name, except
+// for the constructors of the range types, which are added by
+// initRangeBuiltins.
+//
+// For use in other packages, see AllBuiltinNames and GetBuiltinProperties().
+var rangeBuiltins = map[string]builtinDefinition{
+	"lower": makeRangeBuiltin([]string{"range"}, rangeContents,
+		"Returns the lower bound of the range, or NULL if the range is empty or has "+
+			"no lower bound.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			if args[0].Empty {
+				return tree.DNull, nil
+			}
+			return args[0].Lower, nil
+		},
+	),
+
+	"upper": makeRangeBuiltin([]string{"range"}, rangeContents,
+		"Returns the upper bound of the range, or NULL if the range is empty or has "+
+			"no upper bound.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			if args[0].Empty {
+				return tree.DNull, nil
+			}
+			return args[0].Upper, nil
+		},
+	),
+
+	"isempty": makeRangeBuiltin([]string{"range"}, boolType,
+		"Returns true if the range is empty.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(args[0].Empty)), nil
+		},
+	),
+
+	"lower_inc": makeRangeBuiltin([]string{"range"}, boolType,
+		"Returns true if the lower bound of the range is inclusive.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(args[0].LowerInc)), nil
+		},
+	),
+
+	"upper_inc": makeRangeBuiltin([]string{"range"}, boolType,
+		"Returns true if the upper bound of the range is inclusive.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(args[0].UpperInc)), nil
+		},
+	),
+
+	"lower_inf": makeRangeBuiltin([]string{"range"}, boolType,
+		"Returns true if the range has no lower bound.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(!args[0].Empty && args[0].Lower == tree.DNull)), nil
+		},
+	),
+
+	"upper_inf": makeRangeBuiltin([]string{"range"}, boolType,
+		"Returns true if the range has no upper bound.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(!args[0].Empty && args[0].Upper == tree.DNull)), nil
+		},
+	),
+
+	"range_merge": makeRangeBuiltin([]string{"range1", "range2"}, rangeType,
+		"Returns the smallest range which includes both of the ranges.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			return args[0].Merge(args[1]), nil
+		},
+	),
+
+	"range_adjacent": makeRangeBuiltin([]string{"range1", "range2"}, boolType,
+		"Returns true if the ranges are adjacent, which is also available as the -|- "+
+			"operator.",
+		func(args []*tree.DRange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(args[0].IsAdjacentTo(args[1]))), nil
+		},
+	),
+}
+
+// multirangeBuiltins contains the multirange built-in functions and the
+// multirange overloads of the range built-in functions indexed by name, except
+// for the constructors of the multirange types, which are added by
+// initRangeBuiltins. Like in Postgres, the bounds of a multirange are the
+// bounds of its span.
+var multirangeBuiltins = map[string]builtinDefinition{
+	"lower": makeMultirangeBuiltin([]string{"multirange"}, multirangeElemType,
+		"Returns the lower bound of the multirange, or NULL if the multirange is "+
+			"empty or has no lower bound.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			return args[0].Span().Lower, nil
+		},
+	),
+
+	"upper": makeMultirangeBuiltin([]string{"multirange"}, multirangeElemType,
+		"Returns the upper bound of the multirange, or NULL if the multirange is "+
+			"empty or has no upper bound.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			return args[0].Span().Upper, nil
+		},
+	),
+
+	"isempty": makeMultirangeBuiltin([]string{"multirange"}, boolType,
+		"Returns true if the multirange is empty.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(args[0].IsEmpty())), nil
+		},
+	),
+
+	"lower_inc": makeMultirangeBuiltin([]string{"multirange"}, boolType,
+		"Returns true if the lower bound of the multirange is inclusive.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(args[0].Span().LowerInc)), nil
+		},
+	),
+
+	"upper_inc": makeMultirangeBuiltin([]string{"multirange"}, boolType,
+		"Returns true if the upper bound of the multirange is inclusive.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			return tree.MakeDBool(tree.DBool(args[0].Span().UpperInc)), nil
+		},
+	),
+
+	"lower_inf": makeMultirangeBuiltin([]string{"multirange"}, boolType,
+		"Returns true if the multirange has no lower bound.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			span := args[0].Span()
+			return tree.MakeDBool(tree.DBool(!span.Empty && span.Lower == tree.DNull)), nil
+		},
+	),
+
+	"upper_inf": makeMultirangeBuiltin([]string{"multirange"}, boolType,
+		"Returns true if the multirange has no upper bound.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			span := args[0].Span()
+			return tree.MakeDBool(tree.DBool(!span.Empty && span.Upper == tree.DNull)), nil
+		},
+	),
+
+	"range_merge": makeMultirangeBuiltin([]string{"multirange"}, multirangeContents,
+		"Returns the smallest range which includes the whole multirange.",
+		func(args []*tree.DMultirange) (tree.Datum, error) {
+			return args[0].Span(), nil
+		},
+	),
+
+	"range_adjacent": makeBuiltin(defProps(), multirangeAdjacentOverloads()...),
+
+	"multirange": makeBuiltin(defProps(), multirangeFromRangeOverloads()...),
+}
+
+// multirangeAdjacentOverloads returns the overloads of range_adjacent which
+// take a multirange and either a range or a multirange.
+func multirangeAdjacentOverloads() []tree.Overload {
+	const info = "Returns true if the multiranges or the range and the multirange are " +
+		"adjacent, which is also available as the -|- operator."
+	overloads := make([]tree.Overload, 0, 3*len(types.MultirangeTypes))
+	for _, t := range types.MultirangeTypes {
+		r := t.MultirangeContents()
+		overloads = append(overloads,
+			tree.Overload{
+				Types:      tree.ArgTypes{{"multirange1", t}, {"multirange2", t}},
+				ReturnType: tree.FixedReturnType(types.Bool),
+				Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+					m1, m2 := tree.MustBeDMultirange(args[0]), tree.MustBeDMultirange(args[1])
+					return tree.MakeDBool(tree.DBool(m1.IsAdjacentTo(m2))), nil
+				},
+				Info:       info,
+				Volatility: tree.VolatilityImmutable,
+			},
+			tree.Overload{
+				Types:      tree.ArgTypes{{"range", r}, {"multirange", t}},
+				ReturnType: tree.FixedReturnType(types.Bool),
+				Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+					m := tree.MustBeDMultirange(args[1])
+					return tree.MakeDBool(tree.DBool(m.IsAdjacentToRange(tree.MustBeDRange(args[0])))), nil
+				},
+				Info:       info,
+				Volatility: tree.VolatilityImmutable,
+			},
+			tree.Overload{
+				Types:      tree.ArgTypes{{"multirange", t}, {"range", r}},
+				ReturnType: tree.FixedReturnType(types.Bool),
+				Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+					m := tree.MustBeDMultirange(args[0])
+					return tree.MakeDBool(tree.DBool(m.IsAdjacentToRange(tree.MustBeDRange(args[1])))), nil
+				},
+				Info:       info,
+				Volatility: tree.VolatilityImmutable,
+			},
+		)
+	}
+	return overloads
+}
+
+// multirangeFromRangeOverloads returns the overloads of the multirange
+// builtin, which returns a multirange containing a single range.
+func multirangeFromRangeOverloads() []tree.Overload {
+	overloads := make([]tree.Overload, 0, len(types.MultirangeTypes))
+	for _, t := range types.MultirangeTypes {
+		t := t
+		overloads = append(overloads, tree.Overload{
+			Types:      tree.ArgTypes{{"range", t.MultirangeContents()}},
+			ReturnType: tree.FixedReturnType(t),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tree.NewDMultirange(t, tree.MustBeDRange(args[0])), nil
+			},
+			Info:       "Returns a multirange containing just the given range.",
+			Volatility: tree.VolatilityImmutable,
+		})
+	}
+	return overloads
+}
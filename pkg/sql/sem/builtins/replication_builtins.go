@@ -44,30 +44,40 @@ var replicationBuiltins = map[string]builtinDefinition{
 			},
 			ReturnType: tree.FixedReturnType(types.Int),
 			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
-				mgr, err := streaming.GetReplicationStreamManager(evalCtx)
-				if err != nil {
-					return nil, err
-				}
-
-				streamID := streaming.StreamID(*args[0].(*tree.DInt))
-				cutoverTime := args[1].(*tree.DTimestampTZ).Time
-				cutoverTimestamp := hlc.Timestamp{WallTime: cutoverTime.UnixNano()}
-				err = mgr.CompleteStreamIngestion(evalCtx, evalCtx.Txn, streamID, cutoverTimestamp)
-				if err != nil {
-					return nil, err
-				}
-				return tree.NewDInt(tree.DInt(streamID)), err
+				return completeStreamIngestion(evalCtx, args, "" /* reverseStreamAddress */)
 			},
 			Info: "This function can be used to signal a running stream ingestion job to complete. " +
 				"The job will eventually stop ingesting, revert to the specified timestamp and leave the " +
 				"cluster in a consistent state. The specified timestamp can only be specified up to the" +
-				" microsecond. " +
+				" microsecond, and may be ahead of the latest resolved time of the job, in which case " +
+				"the job keeps ingesting until it has resolved the specified timestamp. " +
 				"This function does not wait for the job to reach a terminal state, " +
 				"but instead returns the job id as soon as it has signaled the job to complete. " +
 				"This builtin can be used in conjunction with SHOW JOBS WHEN COMPLETE to ensure that the" +
 				" job has left the cluster in a consistent state.",
 			Volatility: tree.VolatilityVolatile,
 		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"job_id", types.Int},
+				{"cutover_ts", types.TimestampTZ},
+				{"reverse_stream_address", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return completeStreamIngestion(evalCtx, args, string(tree.MustBeDString(args[2])))
+			},
+			Info: "This function can be used to signal a running stream ingestion job to complete, " +
+				"and to then replicate the tenant back into the cluster it was ingesting from. " +
+				"The job will eventually stop ingesting, revert to the specified timestamp and leave the " +
+				"cluster in a consistent state, after which it starts a stream ingestion job for the " +
+				"tenant in the source cluster which consumes a replication stream from " +
+				"reverse_stream_address, the address of this cluster as seen from the source cluster. " +
+				"The tenant must no longer be serving traffic in the source cluster. " +
+				"This function does not wait for the job to reach a terminal state, " +
+				"but instead returns the job id as soon as it has signaled the job to complete.",
+			Volatility: tree.VolatilityVolatile,
+		},
 	),
 
 	"crdb_internal.start_replication_stream": makeBuiltin(
@@ -205,3 +215,23 @@ var replicationBuiltins = map[string]builtinDefinition{
 		},
 	),
 }
+
+// completeStreamIngestion signals the stream ingestion job identified by the
+// first argument to cut over to the time given by the second argument.
+func completeStreamIngestion(
+	evalCtx *tree.EvalContext, args tree.Datums, reverseStreamAddress string,
+) (tree.Datum, error) {
+	mgr, err := streaming.GetReplicationStreamManager(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	streamID := streaming.StreamID(*args[0].(*tree.DInt))
+	cutoverTime := args[1].(*tree.DTimestampTZ).Time
+	cutoverTimestamp := hlc.Timestamp{WallTime: cutoverTime.UnixNano()}
+	err = mgr.CompleteStreamIngestion(evalCtx, evalCtx.Txn, streamID, cutoverTimestamp, reverseStreamAddress)
+	if err != nil {
+		return nil, err
+	}
+	return tree.NewDInt(tree.DInt(streamID)), nil
+}
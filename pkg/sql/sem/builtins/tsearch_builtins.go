@@ -0,0 +1,353 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package builtins
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
+)
+
+func initTSearchBuiltins() {
+	// Add all tsearchBuiltins to the builtins map after a sanity check.
+	for k, v := range tsearchBuiltins {
+		if _, exists := builtins[k]; exists {
+			panic("duplicate builtin: " + k)
+		}
+		v.props.Category = categoryFullTextSearch
+		builtins[k] = v
+	}
+}
+
+// tsearchConfigInfo is appended to the description of the functions which
+// take an optional text search configuration.
+var tsearchConfigInfo = fmt.Sprintf(
+	"The supported configurations are 'simple' and 'english'. If no configuration is "+
+		"specified, '%s' is used.", tsearch.DefaultConfig,
+)
+
+// float4Array is the type of the weights argument of ts_rank.
+var float4Array = types.MakeArray(types.Float4)
+
+// makeTSearchNormalizeBuiltin returns the definition of a builtin which
+// normalizes a document or a query into a tsvector or a tsquery, with and
+// without an explicit text search configuration.
+func makeTSearchNormalizeBuiltin(
+	argName string,
+	retType *types.T,
+	info string,
+	fn func(config string, s string) (tree.Datum, error),
+) builtinDefinition {
+	return makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{argName, types.String}},
+			ReturnType: tree.FixedReturnType(retType),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return fn(tsearch.DefaultConfig, string(tree.MustBeDString(args[0])))
+			},
+			Info:       info + " " + tsearchConfigInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"config", types.String}, {argName, types.String}},
+			ReturnType: tree.FixedReturnType(retType),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return fn(string(tree.MustBeDString(args[0])), string(tree.MustBeDString(args[1])))
+			},
+			Info:       info + " " + tsearchConfigInfo,
+			Volatility: tree.VolatilityImmutable,
+		},
+	)
+}
+
+// makeTSRankOverload returns an overload of ts_rank with the given argument
+// types, which must include a tsvector and a tsquery argument and optionally
+// weights and a normalization.
+func makeTSRankOverload(argTypes tree.ArgTypes) tree.Overload {
+	return tree.Overload{
+		Types:      argTypes,
+		ReturnType: tree.FixedReturnType(types.Float4),
+		Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+			weights := tsearch.DefaultWeights
+			normalization := 0
+			var v *tree.DTSVector
+			var q *tree.DTSQuery
+			for i, arg := range argTypes {
+				switch arg.Name {
+				case "weights":
+					arr := tree.MustBeDArray(args[i])
+					if arr.Len() < len(weights) {
+						return nil, pgerror.New(pgcode.ArraySubscript, "array of weight is too short")
+					}
+					for j := range weights {
+						if arr.Array[j] == tree.DNull {
+							return nil, pgerror.New(pgcode.NullValueNotAllowed, "array of weight must not contain nulls")
+						}
+						weights[j] = float32(tree.MustBeDFloat(arr.Array[j]))
+					}
+				case "vector":
+					v = tree.MustBeDTSVector(args[i])
+				case "query":
+					q = tree.MustBeDTSQuery(args[i])
+				case "normalization":
+					normalization = int(tree.MustBeDInt(args[i]))
+				}
+			}
+			rank, err := tsearch.Rank(weights, v.TSVector, q.TSQuery, normalization)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDFloat(tree.DFloat(rank)), nil
+		},
+		Info: "Ranks the vector for the query, based on the frequency of its matching " +
+			"lexemes and, for queries requiring all their lexemes, on how close to each " +
+			"other they appear. The weights of the D, C, B and A positions default to " +
+			"{0.1, 0.2, 0.4, 1.0}. The normalization is a bit mask of the ways the rank " +
+			"is divided by the length of the document: 1 divides by 1 + the logarithm " +
+			"of the length, 2 by the length, 8 by the number of unique lexemes, 16 by " +
+			"1 + the logarithm of the number of unique lexemes and 32 by the rank + 1.",
+		Volatility: tree.VolatilityImmutable,
+	}
+}
+
+// tsearchBuiltins contains the full text search built-in functions indexed by
+// name.
+//
+// For use in other packages, see AllBuiltinNames and GetBuiltinProperties().
+var tsearchBuiltins = map[string]builtinDefinition{
+	"to_tsvector": makeTSearchNormalizeBuiltin("document", types.TSVector,
+		"Normalizes the words of the document into a tsvector of lexemes and their positions.",
+		func(config string, document string) (tree.Datum, error) {
+			v, err := tsearch.ToTSVector(config, document)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSVector(v), nil
+		},
+	),
+
+	"to_tsquery": makeTSearchNormalizeBuiltin("query", types.TSQuery,
+		"Parses the query, written in the tsquery syntax, and normalizes its words into lexemes.",
+		func(config string, query string) (tree.Datum, error) {
+			q, err := tsearch.ToTSQuery(config, query)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSQuery(q), nil
+		},
+	),
+
+	"plainto_tsquery": makeTSearchNormalizeBuiltin("text", types.TSQuery,
+		"Normalizes the words of the text into a tsquery matching the documents "+
+			"which contain all of them.",
+		func(config string, text string) (tree.Datum, error) {
+			q, err := tsearch.PlainToTSQuery(config, text)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSQuery(q), nil
+		},
+	),
+
+	"phraseto_tsquery": makeTSearchNormalizeBuiltin("text", types.TSQuery,
+		"Normalizes the words of the text into a tsquery matching the documents "+
+			"which contain them as a phrase.",
+		func(config string, text string) (tree.Datum, error) {
+			q, err := tsearch.PhraseToTSQuery(config, text)
+			if err != nil {
+				return nil, err
+			}
+			return tree.NewDTSQuery(q), nil
+		},
+	),
+
+	"ts_rank": makeBuiltin(defProps(),
+		makeTSRankOverload(tree.ArgTypes{
+			{"vector", types.TSVector}, {"query", types.TSQuery},
+		}),
+		makeTSRankOverload(tree.ArgTypes{
+			{"vector", types.TSVector}, {"query", types.TSQuery}, {"normalization", types.Int},
+		}),
+		makeTSRankOverload(tree.ArgTypes{
+			{"weights", float4Array}, {"vector", types.TSVector}, {"query", types.TSQuery},
+		}),
+		makeTSRankOverload(tree.ArgTypes{
+			{"weights", float4Array}, {"vector", types.TSVector}, {"query", types.TSQuery},
+			{"normalization", types.Int},
+		}),
+	),
+
+	"ts_match_vq": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}, {"query", types.TSQuery}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tree.MakeDBool(tree.DBool(tsearch.EvalTSQuery(
+					tree.MustBeDTSQuery(args[1]).TSQuery, tree.MustBeDTSVector(args[0]).TSVector,
+				))), nil
+			},
+			Info:       "Returns whether the vector matches the query. Equivalent to vector @@ query.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"ts_match_qv": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"query", types.TSQuery}, {"vector", types.TSVector}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tree.MakeDBool(tree.DBool(tsearch.EvalTSQuery(
+					tree.MustBeDTSQuery(args[0]).TSQuery, tree.MustBeDTSVector(args[1]).TSVector,
+				))), nil
+			},
+			Info:       "Returns whether the vector matches the query. Equivalent to query @@ vector.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"numnode": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"query", types.TSQuery}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tree.NewDInt(tree.DInt(tree.MustBeDTSQuery(args[0]).NumNodes())), nil
+			},
+			Info:       "Returns the number of lexemes and operators in the query.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"strip": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tree.NewDTSVector(tree.MustBeDTSVector(args[0]).Strip()), nil
+			},
+			Info:       "Returns the vector without the positions and weights of its lexemes.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"setweight": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}, {"weight", types.String}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				v, err := tree.MustBeDTSVector(args[0]).SetWeight(string(tree.MustBeDString(args[1])))
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSVector(v), nil
+			},
+			Info:       "Returns the vector with the weight of all its positions set to the given one of A, B, C or D.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"tsvector_concat": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector1", types.TSVector}, {"vector2", types.TSVector}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return tree.NewDTSVector(
+					tree.MustBeDTSVector(args[0]).Concat(tree.MustBeDTSVector(args[1]).TSVector),
+				), nil
+			},
+			Info: "Concatenates the vectors. The positions of the second vector are shifted " +
+				"after the largest position of the first one.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"tsvector_to_array": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"vector", types.TSVector}},
+			ReturnType: tree.FixedReturnType(types.StringArray),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				arr := tree.NewDArray(types.String)
+				for _, lexeme := range tree.MustBeDTSVector(args[0]).Lexemes() {
+					if err := arr.Append(tree.NewDString(lexeme)); err != nil {
+						return nil, err
+					}
+				}
+				return arr, nil
+			},
+			Info:       "Returns the lexemes of the vector.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"array_to_tsvector": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"lexemes", types.StringArray}},
+			ReturnType: tree.FixedReturnType(types.TSVector),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				arr := tree.MustBeDArray(args[0])
+				lexemes := make([]string, arr.Len())
+				for i, d := range arr.Array {
+					if d == tree.DNull {
+						return nil, pgerror.New(pgcode.NullValueNotAllowed, "lexeme array may not contain nulls")
+					}
+					lexemes[i] = string(tree.MustBeDString(d))
+				}
+				v, err := tsearch.MakeTSVector(lexemes)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSVector(v), nil
+			},
+			Info:       "Returns the vector of the given lexemes, without positions.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+
+	"tsquery_phrase": makeBuiltin(defProps(),
+		tree.Overload{
+			Types:      tree.ArgTypes{{"query1", types.TSQuery}, {"query2", types.TSQuery}},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				q, err := tsearch.MakePhrase(
+					tree.MustBeDTSQuery(args[0]).TSQuery, tree.MustBeDTSQuery(args[1]).TSQuery, 1,
+				)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSQuery(q), nil
+			},
+			Info:       "Returns the query matching query1 followed by query2. Equivalent to query1 <-> query2.",
+			Volatility: tree.VolatilityImmutable,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"query1", types.TSQuery}, {"query2", types.TSQuery}, {"distance", types.Int},
+			},
+			ReturnType: tree.FixedReturnType(types.TSQuery),
+			Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				q, err := tsearch.MakePhrase(
+					tree.MustBeDTSQuery(args[0]).TSQuery, tree.MustBeDTSQuery(args[1]).TSQuery,
+					int(tree.MustBeDInt(args[2])),
+				)
+				if err != nil {
+					return nil, err
+				}
+				return tree.NewDTSQuery(q), nil
+			},
+			Info: "Returns the query matching query1 followed by query2 at the given distance. " +
+				"Equivalent to query1 <N> query2.",
+			Volatility: tree.VolatilityImmutable,
+		},
+	),
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+// AlterStatementFingerprint represents an ALTER STATEMENT FINGERPRINT
+// statement, which pins or unpins the indexes used by the plans of a statement
+// fingerprint. Exactly one of Hints, PlanGist and Unpin is set.
+type AlterStatementFingerprint struct {
+	// Fingerprint is the hex-encoded statement fingerprint ID.
+	Fingerprint Expr
+	// Hints is a comma-separated list of table@index hints.
+	Hints Expr
+	// PlanGist is a plan gist whose indexes are pinned.
+	PlanGist Expr
+	Unpin    bool
+}
+
+var _ Statement = &AlterStatementFingerprint{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterStatementFingerprint) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER STATEMENT FINGERPRINT ")
+	ctx.FormatNode(node.Fingerprint)
+	switch {
+	case node.Unpin:
+		ctx.WriteString(" UNPIN INDEX HINTS")
+	case node.PlanGist != nil:
+		ctx.WriteString(" PIN INDEX HINTS FROM PLAN ")
+		ctx.FormatNode(node.PlanGist)
+	default:
+		ctx.WriteString(" PIN INDEX HINTS ")
+		ctx.FormatNode(node.Hints)
+	}
+}
@@ -132,6 +132,10 @@ type RestoreOptions struct {
 	DebugPauseOn              Expr
 	NewDBName                 Expr
 	IncrementalStorage        StringOrPlaceholderOptList
+	IntoTable                 Expr
+	SpanFilter                Expr
+	SchemaOnly                bool
+	SampleFraction            Expr
 }
 
 var _ NodeFormatter = &RestoreOptions{}
@@ -190,6 +194,31 @@ func (node *Restore) Format(ctx *FmtCtx) {
 	}
 }
 
+// DropBackups represents a DROP BACKUPS statement, which removes the backup
+// chains in a collection that are not needed to restore to any time within
+// the retention window.
+type DropBackups struct {
+	// In is the URI of the backup collection.
+	In Expr
+	// OlderThan is the retention window, as an interval.
+	OlderThan Expr
+	Options   KVOptions
+}
+
+var _ Statement = &DropBackups{}
+
+// Format implements the NodeFormatter interface.
+func (node *DropBackups) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP BACKUPS IN ")
+	ctx.FormatNode(node.In)
+	ctx.WriteString(" OLDER THAN ")
+	ctx.FormatNode(node.OlderThan)
+	if len(node.Options) > 0 {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // KVOption is a key-value option.
 type KVOption struct {
 	Key   Name
@@ -396,6 +425,29 @@ func (o *RestoreOptions) Format(ctx *FmtCtx) {
 		ctx.WriteString("incremental_location = ")
 		ctx.FormatNode(&o.IncrementalStorage)
 	}
+
+	if o.IntoTable != nil {
+		maybeAddSep()
+		ctx.WriteString("into_table = ")
+		ctx.FormatNode(o.IntoTable)
+	}
+
+	if o.SpanFilter != nil {
+		maybeAddSep()
+		ctx.WriteString("span_filter = ")
+		ctx.FormatNode(o.SpanFilter)
+	}
+
+	if o.SchemaOnly {
+		maybeAddSep()
+		ctx.WriteString("schema_only")
+	}
+
+	if o.SampleFraction != nil {
+		maybeAddSep()
+		ctx.WriteString("sample_fraction = ")
+		ctx.FormatNode(o.SampleFraction)
+	}
 }
 
 // CombineWith merges other backup options into this backup options struct.
@@ -485,6 +537,32 @@ func (o *RestoreOptions) CombineWith(other *RestoreOptions) error {
 		return errors.New("incremental_location option specified multiple times")
 	}
 
+	if o.IntoTable == nil {
+		o.IntoTable = other.IntoTable
+	} else if other.IntoTable != nil {
+		return errors.New("into_table specified multiple times")
+	}
+
+	if o.SpanFilter == nil {
+		o.SpanFilter = other.SpanFilter
+	} else if other.SpanFilter != nil {
+		return errors.New("span_filter specified multiple times")
+	}
+
+	if o.SchemaOnly {
+		if other.SchemaOnly {
+			return errors.New("schema_only specified multiple times")
+		}
+	} else {
+		o.SchemaOnly = other.SchemaOnly
+	}
+
+	if o.SampleFraction == nil {
+		o.SampleFraction = other.SampleFraction
+	} else if other.SampleFraction != nil {
+		return errors.New("sample_fraction specified multiple times")
+	}
+
 	return nil
 }
 
@@ -502,5 +580,9 @@ func (o RestoreOptions) IsDefault() bool {
 		o.SkipLocalitiesCheck == options.SkipLocalitiesCheck &&
 		o.DebugPauseOn == options.DebugPauseOn &&
 		o.NewDBName == options.NewDBName &&
-		cmp.Equal(o.IncrementalStorage, options.IncrementalStorage)
+		cmp.Equal(o.IncrementalStorage, options.IncrementalStorage) &&
+		o.IntoTable == options.IntoTable &&
+		o.SpanFilter == options.SpanFilter &&
+		o.SchemaOnly == options.SchemaOnly &&
+		o.SampleFraction == options.SampleFraction
 }
@@ -499,6 +499,20 @@ var (
 		types.UUIDArray,
 		types.INet,
 		types.Jsonb,
+		types.TSQuery,
+		types.TSVector,
+		types.Int4Range,
+		types.Int8Range,
+		types.NumRange,
+		types.TSRange,
+		types.TSTZRange,
+		types.DateRange,
+		types.Int4Multirange,
+		types.Int8Multirange,
+		types.NumMultirange,
+		types.TSMultirange,
+		types.TSTZMultirange,
+		types.DateMultirange,
 		types.VarBit,
 		types.AnyEnum,
 		types.AnyEnumArray,
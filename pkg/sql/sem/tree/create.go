@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/roleoption"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree/treecmp"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/pretty"
@@ -368,6 +369,7 @@ func (*IndexTableDef) tableDef()                {}
 func (*FamilyTableDef) tableDef()               {}
 func (*ForeignKeyConstraintTableDef) tableDef() {}
 func (*CheckConstraintTableDef) tableDef()      {}
+func (*ExcludeConstraintTableDef) tableDef()    {}
 func (*LikeTableDef) tableDef()                 {}
 
 // TableDefs represents a list of table definitions.
@@ -1014,6 +1016,7 @@ type ConstraintTableDef interface {
 func (*UniqueConstraintTableDef) constraintTableDef()     {}
 func (*ForeignKeyConstraintTableDef) constraintTableDef() {}
 func (*CheckConstraintTableDef) constraintTableDef()      {}
+func (*ExcludeConstraintTableDef) constraintTableDef()    {}
 
 // UniqueConstraintTableDef represents a unique constraint within a CREATE
 // TABLE statement.
@@ -1224,6 +1227,75 @@ func (node *CheckConstraintTableDef) Format(ctx *FmtCtx) {
 	ctx.WriteByte(')')
 }
 
+// ExcludeConstraintTableDef represents an exclusion constraint within a
+// CREATE TABLE statement, e.g.
+//
+//   EXCLUDE USING gist (room WITH =, tstzrange(start, finish) WITH &&)
+//
+// Only the gist access method is supported.
+type ExcludeConstraintTableDef struct {
+	Name        Name
+	Elems       ExcludeElemList
+	Predicate   Expr
+	IfNotExists bool
+}
+
+// SetName implements the ConstraintTableDef interface.
+func (node *ExcludeConstraintTableDef) SetName(name Name) {
+	node.Name = name
+}
+
+// SetIfNotExists implements the ConstraintTableDef interface.
+func (node *ExcludeConstraintTableDef) SetIfNotExists() {
+	node.IfNotExists = true
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExcludeConstraintTableDef) Format(ctx *FmtCtx) {
+	if node.Name != "" {
+		ctx.WriteString("CONSTRAINT ")
+		if node.IfNotExists {
+			ctx.WriteString("IF NOT EXISTS ")
+		}
+		ctx.FormatNode(&node.Name)
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteString("EXCLUDE USING gist (")
+	ctx.FormatNode(&node.Elems)
+	ctx.WriteByte(')')
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
+}
+
+// ExcludeElem is an element of an exclusion constraint: an index element and
+// the operator used to compare it between two rows.
+type ExcludeElem struct {
+	IndexElem
+	Operator treecmp.ComparisonOperator
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ExcludeElem) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.IndexElem)
+	ctx.WriteString(" WITH ")
+	ctx.WriteString(node.Operator.String())
+}
+
+// ExcludeElemList is a list of exclusion constraint elements.
+type ExcludeElemList []ExcludeElem
+
+// Format implements the NodeFormatter interface.
+func (l *ExcludeElemList) Format(ctx *FmtCtx) {
+	for i := range *l {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		ctx.FormatNode(&(*l)[i])
+	}
+}
+
 // FamilyTableDef represents a family definition within a CREATE TABLE
 // statement.
 type FamilyTableDef struct {
@@ -1982,6 +2054,9 @@ type RefreshMaterializedView struct {
 	Name              *UnresolvedObjectName
 	Concurrently      bool
 	RefreshDataOption RefreshDataOption
+	// Incrementally is set if the view should be refreshed by applying only
+	// the changes made to the tables it depends on since its last refresh.
+	Incrementally bool
 }
 
 // TelemetryCounter returns the telemetry counter to increment
@@ -2019,6 +2094,9 @@ func (node *RefreshMaterializedView) Format(ctx *FmtCtx) {
 	case RefreshDataClear:
 		ctx.WriteString(" WITH NO DATA")
 	}
+	if node.Incrementally {
+		ctx.WriteString(" INCREMENTALLY")
+	}
 }
 
 // CreateStats represents a CREATE STATISTICS statement.
@@ -2026,7 +2104,11 @@ type CreateStats struct {
 	Name        Name
 	ColumnNames NameList
 	Table       TableExpr
-	Options     CreateStatsOptions
+	// UsingExtremes is set for CREATE STATISTICS ... USING EXTREMES, which
+	// collects partial statistics on the values outside the bounds of the
+	// latest full histogram.
+	UsingExtremes bool
+	Options       CreateStatsOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -2042,6 +2124,10 @@ func (node *CreateStats) Format(ctx *FmtCtx) {
 	ctx.WriteString(" FROM ")
 	ctx.FormatNode(node.Table)
 
+	if node.UsingExtremes {
+		ctx.WriteString(" USING EXTREMES")
+	}
+
 	if !node.Options.Empty() {
 		ctx.WriteString(" WITH OPTIONS ")
 		ctx.FormatNode(&node.Options)
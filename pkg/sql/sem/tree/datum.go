@@ -41,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timetz"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uint128"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
@@ -3453,6 +3454,1078 @@ func (d *DBox2D) Size() uintptr {
 	return unsafe.Sizeof(*d) + unsafe.Sizeof(d.CartesianBoundingBox)
 }
 
+// DTSVector is the Datum representation of the TSVector type.
+type DTSVector struct {
+	tsearch.TSVector
+}
+
+// NewDTSVector returns a new TSVector Datum.
+func NewDTSVector(v tsearch.TSVector) *DTSVector {
+	return &DTSVector{TSVector: v}
+}
+
+// ParseDTSVector attempts to parse `str` as a TSVector type.
+func ParseDTSVector(str string) (*DTSVector, error) {
+	v, err := tsearch.ParseTSVector(str)
+	if err != nil {
+		return nil, MakeParseError(str, types.TSVector, err)
+	}
+	return &DTSVector{TSVector: v}, nil
+}
+
+// AsDTSVector attempts to retrieve a *DTSVector from an Expr, returning a
+// *DTSVector and a flag signifying whether the assertion was successful. The
+// function should be used instead of direct type assertions wherever a
+// *DTSVector wrapped by a *DOidWrapper is possible.
+func AsDTSVector(e Expr) (*DTSVector, bool) {
+	switch t := e.(type) {
+	case *DTSVector:
+		return t, true
+	case *DOidWrapper:
+		return AsDTSVector(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDTSVector attempts to retrieve a *DTSVector from an Expr, panicking
+// if the assertion fails.
+func MustBeDTSVector(e Expr) *DTSVector {
+	v, ok := AsDTSVector(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DTSVector, found %T", e))
+	}
+	return v
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTSVector) ResolvedType() *types.T {
+	return types.TSVector
+}
+
+// Compare implements the Datum interface.
+func (d *DTSVector) Compare(ctx *EvalContext, other Datum) int {
+	res, err := d.CompareError(ctx, other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// CompareError implements the Datum interface.
+func (d *DTSVector) CompareError(ctx *EvalContext, other Datum) (int, error) {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1, nil
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DTSVector)
+	if !ok {
+		return 0, makeUnsupportedComparisonMessage(d, other)
+	}
+	return d.TSVector.Compare(v.TSVector), nil
+}
+
+// Prev implements the Datum interface.
+func (d *DTSVector) Prev(ctx *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DTSVector) Next(ctx *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DTSVector) IsMax(_ *EvalContext) bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DTSVector) IsMin(_ *EvalContext) bool {
+	return d.Len() == 0
+}
+
+// Max implements the Datum interface.
+func (d *DTSVector) Max(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DTSVector) Min(_ *EvalContext) (Datum, bool) {
+	return &DTSVector{}, true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTSVector) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTSVector) Format(ctx *FmtCtx) {
+	s := d.TSVector.String()
+	if ctx.flags.HasFlags(fmtRawStrings) {
+		ctx.WriteString(s)
+	} else {
+		lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, s, ctx.flags.EncodeFlags())
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DTSVector) Size() uintptr {
+	return unsafe.Sizeof(*d) + d.TSVector.Size()
+}
+
+// DTSQuery is the Datum representation of the TSQuery type.
+type DTSQuery struct {
+	tsearch.TSQuery
+}
+
+// NewDTSQuery returns a new TSQuery Datum.
+func NewDTSQuery(q tsearch.TSQuery) *DTSQuery {
+	return &DTSQuery{TSQuery: q}
+}
+
+// ParseDTSQuery attempts to parse `str` as a TSQuery type.
+func ParseDTSQuery(str string) (*DTSQuery, error) {
+	q, err := tsearch.ParseTSQuery(str)
+	if err != nil {
+		return nil, MakeParseError(str, types.TSQuery, err)
+	}
+	return &DTSQuery{TSQuery: q}, nil
+}
+
+// AsDTSQuery attempts to retrieve a *DTSQuery from an Expr, returning a
+// *DTSQuery and a flag signifying whether the assertion was successful. The
+// function should be used instead of direct type assertions wherever a
+// *DTSQuery wrapped by a *DOidWrapper is possible.
+func AsDTSQuery(e Expr) (*DTSQuery, bool) {
+	switch t := e.(type) {
+	case *DTSQuery:
+		return t, true
+	case *DOidWrapper:
+		return AsDTSQuery(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDTSQuery attempts to retrieve a *DTSQuery from an Expr, panicking
+// if the assertion fails.
+func MustBeDTSQuery(e Expr) *DTSQuery {
+	q, ok := AsDTSQuery(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DTSQuery, found %T", e))
+	}
+	return q
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (*DTSQuery) ResolvedType() *types.T {
+	return types.TSQuery
+}
+
+// Compare implements the Datum interface.
+func (d *DTSQuery) Compare(ctx *EvalContext, other Datum) int {
+	res, err := d.CompareError(ctx, other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// CompareError implements the Datum interface.
+func (d *DTSQuery) CompareError(ctx *EvalContext, other Datum) (int, error) {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1, nil
+	}
+	q, ok := UnwrapDatum(ctx, other).(*DTSQuery)
+	if !ok {
+		return 0, makeUnsupportedComparisonMessage(d, other)
+	}
+	return d.TSQuery.Compare(q.TSQuery), nil
+}
+
+// Prev implements the Datum interface.
+func (d *DTSQuery) Prev(ctx *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DTSQuery) Next(ctx *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DTSQuery) IsMax(_ *EvalContext) bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DTSQuery) IsMin(_ *EvalContext) bool {
+	return d.NumNodes() == 0
+}
+
+// Max implements the Datum interface.
+func (d *DTSQuery) Max(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DTSQuery) Min(_ *EvalContext) (Datum, bool) {
+	return &DTSQuery{}, true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DTSQuery) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DTSQuery) Format(ctx *FmtCtx) {
+	s := d.TSQuery.String()
+	if ctx.flags.HasFlags(fmtRawStrings) {
+		ctx.WriteString(s)
+	} else {
+		lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, s, ctx.flags.EncodeFlags())
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DTSQuery) Size() uintptr {
+	return unsafe.Sizeof(*d) + d.TSQuery.Size()
+}
+
+// DRange is the Datum representation of the range types. A range is either
+// empty, or has a lower and an upper bound, each of which may be inclusive,
+// exclusive or unbounded. Ranges of discrete element types (INT4RANGE,
+// INT8RANGE and DATERANGE) are always stored in the canonical [) form.
+type DRange struct {
+	typ *types.T
+
+	// Lower and Upper are the bounds of the range. DNull is used for an
+	// unbounded (infinite) bound.
+	Lower, Upper Datum
+	// LowerInc and UpperInc are true if the corresponding bound is inclusive.
+	// They are always false for unbounded bounds.
+	LowerInc, UpperInc bool
+	// Empty is true if the range contains no values, in which case the bounds
+	// are unset.
+	Empty bool
+}
+
+// NewDEmptyRange returns a new empty range of the given range type.
+func NewDEmptyRange(typ *types.T) *DRange {
+	return &DRange{typ: typ, Lower: DNull, Upper: DNull, Empty: true}
+}
+
+// NewDRange returns a new range of the given range type. DNull is used for an
+// unbounded bound. The range is canonicalized, so a range of a discrete
+// element type always has an inclusive lower bound and an exclusive upper
+// bound, and a range which contains no values is returned as the empty range.
+func NewDRange(
+	typ *types.T, lower, upper Datum, lowerInc, upperInc bool,
+) (*DRange, error) {
+	lower, upper = UnwrapDatum(nil, lower), UnwrapDatum(nil, upper)
+	if lower == DNull {
+		lowerInc = false
+	}
+	if upper == DNull {
+		upperInc = false
+	}
+	if lower != DNull && upper != DNull {
+		cmp := compareRangeElements(lower, upper)
+		if cmp > 0 {
+			return nil, pgerror.New(pgcode.DataException,
+				"range lower bound must be less than or equal to range upper bound")
+		}
+		if cmp == 0 && !(lowerInc && upperInc) {
+			return NewDEmptyRange(typ), nil
+		}
+	}
+
+	if isDiscreteRangeType(typ) {
+		var err error
+		if lower != DNull && !lowerInc {
+			if lower, err = nextRangeElement(lower); err != nil {
+				return nil, err
+			}
+			lowerInc = true
+		}
+		if upper != DNull && upperInc {
+			if upper, err = nextRangeElement(upper); err != nil {
+				return nil, err
+			}
+			upperInc = false
+		}
+		if lower != DNull && upper != DNull && compareRangeElements(lower, upper) >= 0 {
+			return NewDEmptyRange(typ), nil
+		}
+	}
+
+	if typ.Oid() == oid.T_int4range {
+		for _, b := range []Datum{lower, upper} {
+			if i, ok := b.(*DInt); ok && (*i > math.MaxInt32 || *i < math.MinInt32) {
+				return nil, ErrInt4OutOfRange
+			}
+		}
+	}
+
+	return &DRange{
+		typ:      typ,
+		Lower:    lower,
+		Upper:    upper,
+		LowerInc: lowerInc,
+		UpperInc: upperInc,
+	}, nil
+}
+
+// isDiscreteRangeType returns true if the elements of the given range type
+// are discrete, in which case ranges of the type are canonicalized.
+func isDiscreteRangeType(typ *types.T) bool {
+	switch typ.Oid() {
+	case oid.T_int4range, oid.T_int8range, oid.T_daterange:
+		return true
+	}
+	return false
+}
+
+// nextRangeElement returns the element following the given element of a
+// discrete range type.
+func nextRangeElement(d Datum) (Datum, error) {
+	switch t := d.(type) {
+	case *DInt:
+		if *t == math.MaxInt64 {
+			return nil, ErrIntOutOfRange
+		}
+		return NewDInt(*t + 1), nil
+	case *DDate:
+		// The successor of an infinite date is itself.
+		n, err := t.AddDays(1)
+		if err != nil {
+			return nil, err
+		}
+		return NewDDate(n), nil
+	}
+	return nil, errors.AssertionFailedf("unexpected discrete range element %T", d)
+}
+
+// compareRangeElements compares two non-NULL elements of a range. Unlike
+// Datum.Compare, it does not need an EvalContext, since both elements always
+// have the same type.
+func compareRangeElements(a, b Datum) int {
+	switch t := a.(type) {
+	case *DTimestamp:
+		return compareTimes(t.Time, b.(*DTimestamp).Time)
+	case *DTimestampTZ:
+		return compareTimes(t.Time, b.(*DTimestampTZ).Time)
+	}
+	return a.Compare(nil /* ctx */, b)
+}
+
+func compareTimes(a, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	}
+	if b.Before(a) {
+		return 1
+	}
+	return 0
+}
+
+// rangeBound is one of the bounds of a non-empty range.
+type rangeBound struct {
+	// val is the value of the bound, or DNull if the bound is unbounded.
+	val       Datum
+	inclusive bool
+	lower     bool
+}
+
+func (d *DRange) lowerBound() rangeBound {
+	return rangeBound{val: d.Lower, inclusive: d.LowerInc, lower: true}
+}
+
+func (d *DRange) upperBound() rangeBound {
+	return rangeBound{val: d.Upper, inclusive: d.UpperInc, lower: false}
+}
+
+// compareRangeBounds compares two range bounds, each of which may be a lower
+// or an upper bound. It returns -1 if b1 is before b2, 1 if b1 is after b2,
+// and 0 if they are the same. This matches range_cmp_bounds in Postgres.
+func compareRangeBounds(b1, b2 rangeBound) int {
+	if b1.val == DNull && b2.val == DNull {
+		// Two unbounded bounds are equal if they are on the same side.
+		if b1.lower == b2.lower {
+			return 0
+		}
+		if b1.lower {
+			return -1
+		}
+		return 1
+	}
+	if b1.val == DNull {
+		if b1.lower {
+			return -1
+		}
+		return 1
+	}
+	if b2.val == DNull {
+		if b2.lower {
+			return 1
+		}
+		return -1
+	}
+
+	if cmp := compareRangeElements(b1.val, b2.val); cmp != 0 {
+		return cmp
+	}
+	// The values are equal, so the result depends on the inclusivity and the
+	// side of the bounds. An exclusive lower bound is just after the value, and
+	// an exclusive upper bound is just before the value.
+	switch {
+	case !b1.inclusive && !b2.inclusive:
+		if b1.lower == b2.lower {
+			return 0
+		}
+		if b1.lower {
+			return 1
+		}
+		return -1
+	case !b1.inclusive:
+		if b1.lower {
+			return 1
+		}
+		return -1
+	case !b2.inclusive:
+		if b2.lower {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// Overlaps returns true if the two ranges have any values in common.
+func (d *DRange) Overlaps(other *DRange) bool {
+	if d.Empty || other.Empty {
+		return false
+	}
+	if compareRangeBounds(d.lowerBound(), other.lowerBound()) >= 0 &&
+		compareRangeBounds(d.lowerBound(), other.upperBound()) <= 0 {
+		return true
+	}
+	if compareRangeBounds(other.lowerBound(), d.lowerBound()) >= 0 &&
+		compareRangeBounds(other.lowerBound(), d.upperBound()) <= 0 {
+		return true
+	}
+	return false
+}
+
+// Contains returns true if every value of the other range is in the range.
+func (d *DRange) Contains(other *DRange) bool {
+	if other.Empty {
+		return true
+	}
+	if d.Empty {
+		return false
+	}
+	return compareRangeBounds(d.lowerBound(), other.lowerBound()) <= 0 &&
+		compareRangeBounds(d.upperBound(), other.upperBound()) >= 0
+}
+
+// ContainsElement returns true if the given non-NULL value is in the range.
+func (d *DRange) ContainsElement(elem Datum) bool {
+	if d.Empty {
+		return false
+	}
+	elem = UnwrapDatum(nil, elem)
+	if d.Lower != DNull {
+		cmp := compareRangeElements(d.Lower, elem)
+		if cmp > 0 || (cmp == 0 && !d.LowerInc) {
+			return false
+		}
+	}
+	if d.Upper != DNull {
+		cmp := compareRangeElements(d.Upper, elem)
+		if cmp < 0 || (cmp == 0 && !d.UpperInc) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAdjacentTo returns true if the two ranges are adjacent, which means that
+// they do not overlap, but there are no values between them.
+func (d *DRange) IsAdjacentTo(other *DRange) bool {
+	if d.Empty || other.Empty {
+		return false
+	}
+	// Ranges of discrete types are canonicalized, so the ranges are adjacent
+	// iff the upper bound of one range has the same value as the lower bound
+	// of the other range, and exactly one of the bounds is inclusive.
+	boundsAdjacent := func(upper, lower rangeBound) bool {
+		return upper.val != DNull && lower.val != DNull &&
+			upper.inclusive != lower.inclusive &&
+			compareRangeElements(upper.val, lower.val) == 0
+	}
+	return boundsAdjacent(d.upperBound(), other.lowerBound()) ||
+		boundsAdjacent(other.upperBound(), d.lowerBound())
+}
+
+// Merge returns the smallest range which includes both ranges.
+func (d *DRange) Merge(other *DRange) *DRange {
+	if d.Empty {
+		return other
+	}
+	if other.Empty {
+		return d
+	}
+	res := *d
+	if compareRangeBounds(other.lowerBound(), d.lowerBound()) < 0 {
+		res.Lower, res.LowerInc = other.Lower, other.LowerInc
+	}
+	if compareRangeBounds(other.upperBound(), d.upperBound()) > 0 {
+		res.Upper, res.UpperInc = other.Upper, other.UpperInc
+	}
+	return &res
+}
+
+// Union returns the union of the two ranges. It returns an error if the
+// union is not contiguous, that is, if the ranges neither overlap nor are
+// adjacent.
+func (d *DRange) Union(other *DRange) (*DRange, error) {
+	if d.Empty || other.Empty || d.Overlaps(other) || d.IsAdjacentTo(other) {
+		return d.Merge(other), nil
+	}
+	return nil, pgerror.New(pgcode.DataException, "result of range union would not be contiguous")
+}
+
+// Intersect returns the range of the values which are in both ranges.
+func (d *DRange) Intersect(other *DRange) *DRange {
+	if !d.Overlaps(other) {
+		return NewDEmptyRange(d.typ)
+	}
+	res := *d
+	if compareRangeBounds(other.lowerBound(), d.lowerBound()) > 0 {
+		res.Lower, res.LowerInc = other.Lower, other.LowerInc
+	}
+	if compareRangeBounds(other.upperBound(), d.upperBound()) < 0 {
+		res.Upper, res.UpperInc = other.Upper, other.UpperInc
+	}
+	return &res
+}
+
+// Difference returns the range of the values which are in the range, but not
+// in the other range. It returns an error if the difference is not
+// contiguous, that is, if the other range is strictly inside the range.
+func (d *DRange) Difference(other *DRange) (*DRange, error) {
+	pieces := d.subtract(other)
+	switch len(pieces) {
+	case 0:
+		return NewDEmptyRange(d.typ), nil
+	case 1:
+		return pieces[0], nil
+	}
+	return nil, pgerror.New(pgcode.DataException, "result of range difference would not be contiguous")
+}
+
+// subtract returns the non-empty ranges, in order, which contain the values
+// of the range that are not in the other range. There are at most two of
+// them.
+func (d *DRange) subtract(other *DRange) []*DRange {
+	if d.Empty {
+		return nil
+	}
+	if !d.Overlaps(other) {
+		return []*DRange{d}
+	}
+	var res []*DRange
+	// The bounds of the pieces are bounds of canonical ranges with their
+	// inclusivity flipped, so the pieces are canonical as well.
+	if compareRangeBounds(d.lowerBound(), other.lowerBound()) < 0 {
+		res = append(res, &DRange{
+			typ:      d.typ,
+			Lower:    d.Lower,
+			LowerInc: d.LowerInc,
+			Upper:    other.Lower,
+			UpperInc: !other.LowerInc,
+		})
+	}
+	if compareRangeBounds(other.upperBound(), d.upperBound()) < 0 {
+		res = append(res, &DRange{
+			typ:      d.typ,
+			Lower:    other.Upper,
+			LowerInc: !other.UpperInc,
+			Upper:    d.Upper,
+			UpperInc: d.UpperInc,
+		})
+	}
+	return res
+}
+
+// AsDRange attempts to retrieve a *DRange from an Expr, returning a *DRange
+// and a flag signifying whether the assertion was successful. The function
+// should be used instead of direct type assertions wherever a *DRange wrapped
+// by a *DOidWrapper is possible.
+func AsDRange(e Expr) (*DRange, bool) {
+	switch t := e.(type) {
+	case *DRange:
+		return t, true
+	case *DOidWrapper:
+		return AsDRange(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDRange attempts to retrieve a *DRange from an Expr, panicking if the
+// assertion fails.
+func MustBeDRange(e Expr) *DRange {
+	r, ok := AsDRange(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DRange, found %T", e))
+	}
+	return r
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DRange) ResolvedType() *types.T {
+	return d.typ
+}
+
+// Compare implements the Datum interface.
+func (d *DRange) Compare(ctx *EvalContext, other Datum) int {
+	res, err := d.CompareError(ctx, other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// CompareError implements the Datum interface. Empty ranges sort first, and
+// other ranges are ordered by their lower bounds, and then by their upper
+// bounds.
+func (d *DRange) CompareError(ctx *EvalContext, other Datum) (int, error) {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1, nil
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DRange)
+	if !ok || v.typ.Oid() != d.typ.Oid() {
+		return 0, makeUnsupportedComparisonMessage(d, other)
+	}
+	if d.Empty || v.Empty {
+		switch {
+		case d.Empty && v.Empty:
+			return 0, nil
+		case d.Empty:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+	if cmp := compareRangeBounds(d.lowerBound(), v.lowerBound()); cmp != 0 {
+		return cmp, nil
+	}
+	return compareRangeBounds(d.upperBound(), v.upperBound()), nil
+}
+
+// Prev implements the Datum interface.
+func (d *DRange) Prev(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DRange) Next(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DRange) IsMax(_ *EvalContext) bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DRange) IsMin(_ *EvalContext) bool {
+	return d.Empty
+}
+
+// Max implements the Datum interface.
+func (d *DRange) Max(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DRange) Min(_ *EvalContext) (Datum, bool) {
+	return NewDEmptyRange(d.typ), true
+}
+
+// IsComposite implements the CompositeDatum interface.
+func (d *DRange) IsComposite() bool {
+	for _, b := range []Datum{d.Lower, d.Upper} {
+		if cdatum, ok := b.(CompositeDatum); ok && cdatum.IsComposite() {
+			return true
+		}
+	}
+	return false
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DRange) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DRange) Format(ctx *FmtCtx) {
+	if ctx.HasAnyFlags(fmtPgwireFormat | fmtRawStrings) {
+		d.formatRaw(ctx)
+		return
+	}
+	s := AsStringWithFlags(d, fmtRawStrings, FmtDataConversionConfig(ctx.dataConversionConfig))
+	lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, s, ctx.flags.EncodeFlags())
+}
+
+// formatRaw writes the text representation of the range, which is the same
+// as in Postgres, for example [1,5) or (,"2022-01-01 00:00:00"].
+func (d *DRange) formatRaw(ctx *FmtCtx) {
+	if d.Empty {
+		ctx.WriteString("empty")
+		return
+	}
+	if d.LowerInc {
+		ctx.WriteByte('[')
+	} else {
+		ctx.WriteByte('(')
+	}
+	if d.Lower != DNull {
+		pgwireFormatStringInRange(&ctx.Buffer, AsStringWithFlags(
+			d.Lower, FmtBareStrings, FmtDataConversionConfig(ctx.dataConversionConfig),
+		))
+	}
+	ctx.WriteByte(',')
+	if d.Upper != DNull {
+		pgwireFormatStringInRange(&ctx.Buffer, AsStringWithFlags(
+			d.Upper, FmtBareStrings, FmtDataConversionConfig(ctx.dataConversionConfig),
+		))
+	}
+	if d.UpperInc {
+		ctx.WriteByte(']')
+	} else {
+		ctx.WriteByte(')')
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DRange) Size() uintptr {
+	sz := unsafe.Sizeof(*d)
+	if d.Lower != DNull {
+		sz += d.Lower.Size()
+	}
+	if d.Upper != DNull {
+		sz += d.Upper.Size()
+	}
+	return sz
+}
+
+// DMultirange is the Datum representation of the multirange types. A
+// multirange is an ordered list of non-empty ranges which neither overlap nor
+// are adjacent to each other.
+type DMultirange struct {
+	typ *types.T
+
+	// Ranges are the ranges of the multirange, ordered by their lower bounds.
+	Ranges []*DRange
+}
+
+// NewDMultirange returns a new multirange of the given multirange type which
+// contains the values of the given ranges. The ranges are normalized, so
+// empty ranges are dropped, and ranges which overlap or are adjacent are
+// merged.
+func NewDMultirange(typ *types.T, ranges ...*DRange) *DMultirange {
+	sorted := make([]*DRange, 0, len(ranges))
+	for _, r := range ranges {
+		if !r.Empty {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return compareRangeBounds(sorted[i].lowerBound(), sorted[j].lowerBound()) < 0
+	})
+	res := &DMultirange{typ: typ}
+	for _, r := range sorted {
+		if n := len(res.Ranges); n > 0 {
+			if last := res.Ranges[n-1]; last.Overlaps(r) || last.IsAdjacentTo(r) {
+				res.Ranges[n-1] = last.Merge(r)
+				continue
+			}
+		}
+		res.Ranges = append(res.Ranges, r)
+	}
+	return res
+}
+
+// IsEmpty returns true if the multirange contains no values.
+func (d *DMultirange) IsEmpty() bool {
+	return len(d.Ranges) == 0
+}
+
+// Span returns the smallest range which includes all the ranges of the
+// multirange.
+func (d *DMultirange) Span() *DRange {
+	if d.IsEmpty() {
+		return NewDEmptyRange(d.typ.MultirangeContents())
+	}
+	return d.Ranges[0].Merge(d.Ranges[len(d.Ranges)-1])
+}
+
+// Overlaps returns true if the two multiranges have any values in common.
+func (d *DMultirange) Overlaps(other *DMultirange) bool {
+	for _, r := range other.Ranges {
+		if d.OverlapsRange(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// OverlapsRange returns true if the multirange and the range have any values
+// in common.
+func (d *DMultirange) OverlapsRange(other *DRange) bool {
+	for _, r := range d.Ranges {
+		if r.Overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns true if every value of the other multirange is in the
+// multirange.
+func (d *DMultirange) Contains(other *DMultirange) bool {
+	for _, r := range other.Ranges {
+		if !d.ContainsRange(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsRange returns true if every value of the range is in the
+// multirange. Since the ranges of a multirange are not adjacent, a non-empty
+// range must be contained in a single one of them.
+func (d *DMultirange) ContainsRange(other *DRange) bool {
+	if other.Empty {
+		return true
+	}
+	for _, r := range d.Ranges {
+		if r.Contains(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsElement returns true if the given non-NULL value is in the
+// multirange.
+func (d *DMultirange) ContainsElement(elem Datum) bool {
+	for _, r := range d.Ranges {
+		if r.ContainsElement(elem) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdjacentTo returns true if the two multiranges are adjacent, which means
+// that the last range of one of them is adjacent to the first range of the
+// other one. This matches multirange_adjacent_multirange in Postgres.
+func (d *DMultirange) IsAdjacentTo(other *DMultirange) bool {
+	if d.IsEmpty() || other.IsEmpty() {
+		return false
+	}
+	return d.Ranges[len(d.Ranges)-1].IsAdjacentTo(other.Ranges[0]) ||
+		other.Ranges[len(other.Ranges)-1].IsAdjacentTo(d.Ranges[0])
+}
+
+// IsAdjacentToRange returns true if the range is adjacent to the first or the
+// last range of the multirange.
+func (d *DMultirange) IsAdjacentToRange(other *DRange) bool {
+	if d.IsEmpty() {
+		return false
+	}
+	return other.IsAdjacentTo(d.Ranges[0]) || other.IsAdjacentTo(d.Ranges[len(d.Ranges)-1])
+}
+
+// Union returns the multirange of the values which are in either multirange.
+func (d *DMultirange) Union(other *DMultirange) *DMultirange {
+	ranges := make([]*DRange, 0, len(d.Ranges)+len(other.Ranges))
+	ranges = append(ranges, d.Ranges...)
+	ranges = append(ranges, other.Ranges...)
+	return NewDMultirange(d.typ, ranges...)
+}
+
+// Intersect returns the multirange of the values which are in both
+// multiranges.
+func (d *DMultirange) Intersect(other *DMultirange) *DMultirange {
+	var ranges []*DRange
+	for _, r := range d.Ranges {
+		for _, o := range other.Ranges {
+			if r.Overlaps(o) {
+				ranges = append(ranges, r.Intersect(o))
+			}
+		}
+	}
+	return NewDMultirange(d.typ, ranges...)
+}
+
+// Difference returns the multirange of the values which are in the
+// multirange, but not in the other multirange.
+func (d *DMultirange) Difference(other *DMultirange) *DMultirange {
+	var ranges []*DRange
+	for _, r := range d.Ranges {
+		pieces := []*DRange{r}
+		for _, o := range other.Ranges {
+			var next []*DRange
+			for _, p := range pieces {
+				next = append(next, p.subtract(o)...)
+			}
+			pieces = next
+		}
+		ranges = append(ranges, pieces...)
+	}
+	return NewDMultirange(d.typ, ranges...)
+}
+
+// AsDMultirange attempts to retrieve a *DMultirange from an Expr, returning a
+// *DMultirange and a flag signifying whether the assertion was successful.
+// The function should be used instead of direct type assertions wherever a
+// *DMultirange wrapped by a *DOidWrapper is possible.
+func AsDMultirange(e Expr) (*DMultirange, bool) {
+	switch t := e.(type) {
+	case *DMultirange:
+		return t, true
+	case *DOidWrapper:
+		return AsDMultirange(t.Wrapped)
+	}
+	return nil, false
+}
+
+// MustBeDMultirange attempts to retrieve a *DMultirange from an Expr,
+// panicking if the assertion fails.
+func MustBeDMultirange(e Expr) *DMultirange {
+	m, ok := AsDMultirange(e)
+	if !ok {
+		panic(errors.AssertionFailedf("expected *DMultirange, found %T", e))
+	}
+	return m
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DMultirange) ResolvedType() *types.T {
+	return d.typ
+}
+
+// Compare implements the Datum interface.
+func (d *DMultirange) Compare(ctx *EvalContext, other Datum) int {
+	res, err := d.CompareError(ctx, other)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// CompareError implements the Datum interface. Multiranges are compared range
+// by range, and a multirange which is a prefix of another one sorts first, so
+// the empty multirange sorts before all others.
+func (d *DMultirange) CompareError(ctx *EvalContext, other Datum) (int, error) {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1, nil
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DMultirange)
+	if !ok || v.typ.Oid() != d.typ.Oid() {
+		return 0, makeUnsupportedComparisonMessage(d, other)
+	}
+	for i := 0; i < len(d.Ranges) && i < len(v.Ranges); i++ {
+		if cmp := d.Ranges[i].Compare(ctx, v.Ranges[i]); cmp != 0 {
+			return cmp, nil
+		}
+	}
+	switch {
+	case len(d.Ranges) < len(v.Ranges):
+		return -1, nil
+	case len(d.Ranges) > len(v.Ranges):
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Prev implements the Datum interface.
+func (d *DMultirange) Prev(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Next implements the Datum interface.
+func (d *DMultirange) Next(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// IsMax implements the Datum interface.
+func (d *DMultirange) IsMax(_ *EvalContext) bool {
+	return false
+}
+
+// IsMin implements the Datum interface.
+func (d *DMultirange) IsMin(_ *EvalContext) bool {
+	return d.IsEmpty()
+}
+
+// Max implements the Datum interface.
+func (d *DMultirange) Max(_ *EvalContext) (Datum, bool) {
+	return nil, false
+}
+
+// Min implements the Datum interface.
+func (d *DMultirange) Min(_ *EvalContext) (Datum, bool) {
+	return NewDMultirange(d.typ), true
+}
+
+// IsComposite implements the CompositeDatum interface.
+func (d *DMultirange) IsComposite() bool {
+	for _, r := range d.Ranges {
+		if r.IsComposite() {
+			return true
+		}
+	}
+	return false
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DMultirange) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DMultirange) Format(ctx *FmtCtx) {
+	if ctx.HasAnyFlags(fmtPgwireFormat | fmtRawStrings) {
+		d.formatRaw(ctx)
+		return
+	}
+	s := AsStringWithFlags(d, fmtRawStrings, FmtDataConversionConfig(ctx.dataConversionConfig))
+	lexbase.EncodeSQLStringWithFlags(&ctx.Buffer, s, ctx.flags.EncodeFlags())
+}
+
+// formatRaw writes the text representation of the multirange, which is the
+// same as in Postgres, for example {[1,3),[5,7)}.
+func (d *DMultirange) formatRaw(ctx *FmtCtx) {
+	ctx.WriteByte('{')
+	for i, r := range d.Ranges {
+		if i > 0 {
+			ctx.WriteByte(',')
+		}
+		r.formatRaw(ctx)
+	}
+	ctx.WriteByte('}')
+}
+
+// Size implements the Datum interface.
+func (d *DMultirange) Size() uintptr {
+	sz := unsafe.Sizeof(*d) + uintptr(cap(d.Ranges))*unsafe.Sizeof((*DRange)(nil))
+	for _, r := range d.Ranges {
+		sz += r.Size()
+	}
+	return sz
+}
+
 // DJSON is the JSON Datum.
 type DJSON struct{ json.JSON }
 
@@ -3574,8 +4647,11 @@ func AsJSON(
 	case *DTimestamp:
 		// This is RFC3339Nano, but without the TZ fields.
 		return json.FromString(t.UTC().Format("2006-01-02T15:04:05.999999999")), nil
-	case *DDate, *DUuid, *DOid, *DInterval, *DBytes, *DIPAddr, *DTime, *DTimeTZ, *DBitArray, *DBox2D:
+	case *DDate, *DUuid, *DOid, *DInterval, *DBytes, *DIPAddr, *DTime, *DTimeTZ, *DBitArray, *DBox2D,
+		*DTSVector, *DTSQuery:
 		return json.FromString(AsStringWithFlags(t, FmtBareStrings, FmtDataConversionConfig(dcc))), nil
+	case *DRange, *DMultirange:
+		return json.FromString(AsStringWithFlags(t, FmtPgwireText, FmtDataConversionConfig(dcc))), nil
 	case *DGeometry:
 		return json.FromSpatialObject(t.Geometry.SpatialObject(), geo.DefaultGeoJSONDecimalDigits)
 	case *DGeography:
@@ -5433,6 +6509,14 @@ func NewDefaultDatum(evalCtx *EvalContext, t *types.T) (d Datum, err error) {
 		return dNullJSON, nil
 	case types.TimeTZFamily:
 		return dZeroTimeTZ, nil
+	case types.TSVectorFamily:
+		return &DTSVector{}, nil
+	case types.TSQueryFamily:
+		return &DTSQuery{}, nil
+	case types.RangeFamily:
+		return NewDEmptyRange(t), nil
+	case types.MultirangeFamily:
+		return NewDMultirange(t), nil
 	case types.GeometryFamily, types.GeographyFamily, types.Box2DFamily:
 		// TODO(otan): force Geometry/Geography to not allow `NOT NULL` columns to
 		// make this impossible.
@@ -5548,6 +6632,10 @@ var baseDatumTypeSizes = map[types.Family]struct {
 	types.INetFamily:           {unsafe.Sizeof(DIPAddr{}), fixedSize},
 	types.OidFamily:            {unsafe.Sizeof(DInt(0)), fixedSize},
 	types.EnumFamily:           {unsafe.Sizeof(DEnum{}), variableSize},
+	types.TSQueryFamily:        {unsafe.Sizeof(DTSQuery{}), variableSize},
+	types.TSVectorFamily:       {unsafe.Sizeof(DTSVector{}), variableSize},
+	types.RangeFamily:          {unsafe.Sizeof(DRange{}), variableSize},
+	types.MultirangeFamily:     {unsafe.Sizeof(DMultirange{}), variableSize},
 
 	types.VoidFamily: {sz: unsafe.Sizeof(DVoid{}), variable: fixedSize},
 	// TODO(jordan,justin): This seems suspicious.
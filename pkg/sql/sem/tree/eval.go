@@ -50,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/cockroach/pkg/util/tsearch"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/cockroachdb/errors"
 	"github.com/lib/pq/oid"
@@ -513,10 +514,55 @@ func initNonArrayToNonArrayConcatenation() {
 	}
 }
 
+// initRangeSetOperators initializes the union (+), intersection (*) and
+// difference (-) operators of the range and multirange types.
+func initRangeSetOperators() {
+	addOp := func(op treebin.BinaryOperatorSymbol, typ *types.T, fn TwoArgFn) {
+		BinOps[op] = append(BinOps[op], &BinOp{
+			LeftType:   typ,
+			RightType:  typ,
+			ReturnType: typ,
+			Fn:         fn,
+			Volatility: VolatilityImmutable,
+		})
+	}
+	for _, t := range types.RangeTypes {
+		addOp(treebin.Plus, t, func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+			res, err := MustBeDRange(left).Union(MustBeDRange(right))
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		})
+		addOp(treebin.Mult, t, func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+			return MustBeDRange(left).Intersect(MustBeDRange(right)), nil
+		})
+		addOp(treebin.Minus, t, func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+			res, err := MustBeDRange(left).Difference(MustBeDRange(right))
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		})
+	}
+	for _, t := range types.MultirangeTypes {
+		addOp(treebin.Plus, t, func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+			return MustBeDMultirange(left).Union(MustBeDMultirange(right)), nil
+		})
+		addOp(treebin.Mult, t, func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+			return MustBeDMultirange(left).Intersect(MustBeDMultirange(right)), nil
+		})
+		addOp(treebin.Minus, t, func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+			return MustBeDMultirange(left).Difference(MustBeDMultirange(right)), nil
+		})
+	}
+}
+
 func init() {
 	initArrayElementConcatenation()
 	initArrayToArrayConcatenation()
 	initNonArrayToNonArrayConcatenation()
+	initRangeSetOperators()
 }
 
 func init() {
@@ -2069,8 +2115,97 @@ func cmpOpFixups(
 		panic(errors.AssertionFailedf("could not find cmp op %s(%s,%s)", op, t, t))
 	}
 
+	// Range comparisons. Each range type gets its own overloads since ranges
+	// of different element types are not comparable.
+	for _, t := range types.RangeTypes {
+		cmpOps[treecmp.EQ] = append(cmpOps[treecmp.EQ], makeEqFn(t, t, VolatilityImmutable))
+		cmpOps[treecmp.LT] = append(cmpOps[treecmp.LT], makeLtFn(t, t, VolatilityImmutable))
+		cmpOps[treecmp.LE] = append(cmpOps[treecmp.LE], makeLeFn(t, t, VolatilityImmutable))
+		cmpOps[treecmp.IsNotDistinctFrom] = append(
+			cmpOps[treecmp.IsNotDistinctFrom], makeIsFn(t, t, VolatilityImmutable),
+		)
+		cmpOps[treecmp.In] = append(cmpOps[treecmp.In], makeEvalTupleIn(t, VolatilityLeakProof))
+
+		cmpOps[treecmp.Overlaps] = append(cmpOps[treecmp.Overlaps],
+			makeRangeCmpOp(t, t, func(left, right Datum) bool {
+				return MustBeDRange(left).Overlaps(MustBeDRange(right))
+			}),
+		)
+		cmpOps[treecmp.Contains] = append(cmpOps[treecmp.Contains],
+			makeRangeCmpOp(t, t, func(left, right Datum) bool {
+				return MustBeDRange(left).Contains(MustBeDRange(right))
+			}),
+			makeRangeCmpOp(t, t.RangeContents(), func(left, right Datum) bool {
+				return MustBeDRange(left).ContainsElement(right)
+			}),
+		)
+		cmpOps[treecmp.ContainedBy] = append(cmpOps[treecmp.ContainedBy],
+			makeRangeCmpOp(t, t, func(left, right Datum) bool {
+				return MustBeDRange(right).Contains(MustBeDRange(left))
+			}),
+			makeRangeCmpOp(t.RangeContents(), t, func(left, right Datum) bool {
+				return MustBeDRange(right).ContainsElement(left)
+			}),
+		)
+	}
+
+	// Multirange comparisons. Multiranges can also be compared with their
+	// range type and with the element type of their range type.
+	for _, t := range types.MultirangeTypes {
+		r := t.MultirangeContents()
+		elem := r.RangeContents()
+		cmpOps[treecmp.EQ] = append(cmpOps[treecmp.EQ], makeEqFn(t, t, VolatilityImmutable))
+		cmpOps[treecmp.LT] = append(cmpOps[treecmp.LT], makeLtFn(t, t, VolatilityImmutable))
+		cmpOps[treecmp.LE] = append(cmpOps[treecmp.LE], makeLeFn(t, t, VolatilityImmutable))
+		cmpOps[treecmp.IsNotDistinctFrom] = append(
+			cmpOps[treecmp.IsNotDistinctFrom], makeIsFn(t, t, VolatilityImmutable),
+		)
+		cmpOps[treecmp.In] = append(cmpOps[treecmp.In], makeEvalTupleIn(t, VolatilityLeakProof))
+
+		cmpOps[treecmp.Overlaps] = append(cmpOps[treecmp.Overlaps],
+			makeRangeCmpOp(t, t, func(left, right Datum) bool {
+				return MustBeDMultirange(left).Overlaps(MustBeDMultirange(right))
+			}),
+			makeRangeCmpOp(t, r, func(left, right Datum) bool {
+				return MustBeDMultirange(left).OverlapsRange(MustBeDRange(right))
+			}),
+			makeRangeCmpOp(r, t, func(left, right Datum) bool {
+				return MustBeDMultirange(right).OverlapsRange(MustBeDRange(left))
+			}),
+		)
+		cmpOps[treecmp.Contains] = append(cmpOps[treecmp.Contains],
+			makeRangeCmpOp(t, t, func(left, right Datum) bool {
+				return MustBeDMultirange(left).Contains(MustBeDMultirange(right))
+			}),
+			makeRangeCmpOp(t, r, func(left, right Datum) bool {
+				return MustBeDMultirange(left).ContainsRange(MustBeDRange(right))
+			}),
+			makeRangeCmpOp(t, elem, func(left, right Datum) bool {
+				return MustBeDMultirange(left).ContainsElement(right)
+			}),
+			makeRangeCmpOp(r, t, func(left, right Datum) bool {
+				return NewDMultirange(t, MustBeDRange(left)).Contains(MustBeDMultirange(right))
+			}),
+		)
+		cmpOps[treecmp.ContainedBy] = append(cmpOps[treecmp.ContainedBy],
+			makeRangeCmpOp(t, t, func(left, right Datum) bool {
+				return MustBeDMultirange(right).Contains(MustBeDMultirange(left))
+			}),
+			makeRangeCmpOp(r, t, func(left, right Datum) bool {
+				return MustBeDMultirange(right).ContainsRange(MustBeDRange(left))
+			}),
+			makeRangeCmpOp(elem, t, func(left, right Datum) bool {
+				return MustBeDMultirange(right).ContainsElement(left)
+			}),
+			makeRangeCmpOp(t, r, func(left, right Datum) bool {
+				return NewDMultirange(t, MustBeDRange(right)).Contains(MustBeDMultirange(left))
+			}),
+		)
+	}
+
 	// Array equality comparisons.
-	for _, t := range append(types.Scalar, types.AnyEnum) {
+	arrayElemTypes := append(append(types.Scalar, types.AnyEnum), types.RangeTypes...)
+	for _, t := range append(arrayElemTypes, types.MultirangeTypes...) {
 		cmpOps[treecmp.EQ] = append(cmpOps[treecmp.EQ], &CmpOp{
 			LeftType:   types.MakeArray(t),
 			RightType:  types.MakeArray(t),
@@ -2178,6 +2313,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeEqFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeEqFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeEqFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeEqFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeEqFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeEqFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeEqFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2234,6 +2371,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeLtFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeLtFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeLtFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeLtFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeLtFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeLtFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeLtFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2290,6 +2429,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeLeFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeLeFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeLeFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeLeFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeLeFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeLeFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeLeFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2363,6 +2504,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeIsFn(types.TimeTZ, types.TimeTZ, VolatilityLeakProof),
 		makeIsFn(types.Timestamp, types.Timestamp, VolatilityLeakProof),
 		makeIsFn(types.TimestampTZ, types.TimestampTZ, VolatilityLeakProof),
+		makeIsFn(types.TSQuery, types.TSQuery, VolatilityImmutable),
+		makeIsFn(types.TSVector, types.TSVector, VolatilityImmutable),
 		makeIsFn(types.Uuid, types.Uuid, VolatilityLeakProof),
 		makeIsFn(types.VarBit, types.VarBit, VolatilityLeakProof),
 
@@ -2421,6 +2564,8 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 		makeEvalTupleIn(types.TimeTZ, VolatilityLeakProof),
 		makeEvalTupleIn(types.Timestamp, VolatilityLeakProof),
 		makeEvalTupleIn(types.TimestampTZ, VolatilityLeakProof),
+		makeEvalTupleIn(types.TSQuery, VolatilityLeakProof),
+		makeEvalTupleIn(types.TSVector, VolatilityLeakProof),
 		makeEvalTupleIn(types.Uuid, VolatilityLeakProof),
 		makeEvalTupleIn(types.VarBit, VolatilityLeakProof),
 	},
@@ -2635,6 +2780,29 @@ var CmpOps = cmpOpFixups(map[treecmp.ComparisonOperatorSymbol]cmpOpOverload{
 			},
 		)...,
 	),
+
+	treecmp.TSMatches: {
+		&CmpOp{
+			LeftType:  types.TSVector,
+			RightType: types.TSQuery,
+			Fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(tsearch.EvalTSQuery(
+					MustBeDTSQuery(right).TSQuery, MustBeDTSVector(left).TSVector,
+				))), nil
+			},
+			Volatility: VolatilityImmutable,
+		},
+		&CmpOp{
+			LeftType:  types.TSQuery,
+			RightType: types.TSVector,
+			Fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+				return MakeDBool(DBool(tsearch.EvalTSQuery(
+					MustBeDTSQuery(left).TSQuery, MustBeDTSVector(right).TSVector,
+				))), nil
+			},
+			Volatility: VolatilityImmutable,
+		},
+	},
 })
 
 const experimentalBox2DClusterSettingName = "sql.spatial.experimental_box2d_comparison_operators.enabled"
@@ -2660,6 +2828,20 @@ func checkExperimentalBox2DComparisonOperatorEnabled(ctx *EvalContext) error {
 	return nil
 }
 
+// makeRangeCmpOp returns a comparison operator over a range or multirange
+// type and either the same type, a related range or multirange type, or the
+// element type of the range.
+func makeRangeCmpOp(left, right *types.T, op func(left, right Datum) bool) *CmpOp {
+	return &CmpOp{
+		LeftType:  left,
+		RightType: right,
+		Fn: func(_ *EvalContext, left Datum, right Datum) (Datum, error) {
+			return MakeDBool(DBool(op(left, right))), nil
+		},
+		Volatility: VolatilityImmutable,
+	}
+}
+
 func makeBox2DComparisonOperators(op func(lhs, rhs *geo.CartesianBoundingBox) bool) cmpOpOverload {
 	return cmpOpOverload{
 		&CmpOp{
@@ -3372,6 +3554,19 @@ type CompactEngineSpanFunc func(
 	ctx context.Context, nodeID, storeID int32, startKey, endKey []byte,
 ) error
 
+// StmtDiagnosticsRequestInsertFunc is used by the builtins to insert a
+// statement diagnostics request. It is a function rather than an interface to
+// avoid a circular dependency on the stmtdiagnostics package.
+type StmtDiagnosticsRequestInsertFunc func(
+	ctx context.Context,
+	stmtFingerprint string,
+	samplingProbability float64,
+	numSamples int,
+	onPlanChange bool,
+	minExecutionLatency time.Duration,
+	expiresAfter time.Duration,
+) error
+
 // EvalSessionAccessor is a limited interface to access session variables.
 type EvalSessionAccessor interface {
 	// SetSessionVar sets a session variable to a new value. If isLocal is true,
@@ -3712,6 +3907,10 @@ type EvalContext struct {
 
 	// CompactEngineSpan is used to force compaction of a span in a store.
 	CompactEngineSpan CompactEngineSpanFunc
+
+	// StmtDiagnosticsRequestInserter is used to request the collection of
+	// statement diagnostics bundles.
+	StmtDiagnosticsRequestInserter StmtDiagnosticsRequestInsertFunc
 }
 
 // MakeTestingEvalContext returns an EvalContext that includes a MemoryMonitor.
@@ -4766,6 +4965,26 @@ func (t *DTimestampTZ) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DTSQuery) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DTSVector) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DRange) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
+// Eval implements the TypedExpr interface.
+func (t *DMultirange) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DTuple) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
@@ -0,0 +1,221 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tree
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+var malformedRangeError = pgerror.Newf(pgcode.InvalidTextRepresentation, "malformed range literal")
+
+// ParseDRange parses the string-form of a range, such as `[1,5)`, `(,10]`
+// or `empty`, into a range of the given range type.
+//
+// The dependsOnContext return value indicates if we had to consult the
+// ParseTimeContext (either for the time or the local timezone).
+func ParseDRange(
+	ctx ParseTimeContext, s string, t *types.T,
+) (_ *DRange, dependsOnContext bool, _ error) {
+	ret, dependsOnContext, err := doParseDRange(ctx, s, t)
+	if err != nil {
+		return nil, false, MakeParseError(s, t, err)
+	}
+	return ret, dependsOnContext, nil
+}
+
+// doParseDRange does most of the work of ParseDRange, except the error it
+// returns isn't prettified as a parsing error.
+func doParseDRange(
+	ctx ParseTimeContext, s string, t *types.T,
+) (_ *DRange, dependsOnContext bool, _ error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "empty") {
+		return NewDEmptyRange(t), false, nil
+	}
+	if len(s) < 3 {
+		return nil, false, malformedRangeError
+	}
+
+	var lowerInc, upperInc bool
+	switch s[0] {
+	case '[':
+		lowerInc = true
+	case '(':
+	default:
+		return nil, false, malformedRangeError
+	}
+	switch s[len(s)-1] {
+	case ']':
+		upperInc = true
+	case ')':
+	default:
+		return nil, false, malformedRangeError
+	}
+
+	rest := s[1 : len(s)-1]
+	var bounds [2]Datum
+	for i := range bounds {
+		str, quoted, remaining, err := parseRangeBound(rest)
+		if err != nil {
+			return nil, false, err
+		}
+		if i == 0 {
+			if len(remaining) == 0 || remaining[0] != ',' {
+				return nil, false, malformedRangeError
+			}
+			remaining = remaining[1:]
+		} else if len(remaining) != 0 {
+			return nil, false, malformedRangeError
+		}
+		rest = remaining
+
+		if str == "" && !quoted {
+			// An omitted bound is unbounded.
+			bounds[i] = DNull
+			continue
+		}
+		d, depends, err := ParseAndRequireString(t.RangeContents(), str, ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		dependsOnContext = dependsOnContext || depends
+		bounds[i] = d
+	}
+
+	r, err := NewDRange(t, bounds[0], bounds[1], lowerInc, upperInc)
+	if err != nil {
+		return nil, false, err
+	}
+	return r, dependsOnContext, nil
+}
+
+// parseRangeBound parses a single bound from the start of s, which ends at the
+// first unquoted comma, or at the end of s. Double quotes may be used to
+// include special characters in the bound, and a double quote or a backslash
+// can be escaped by a backslash or, within double quotes, by doubling it. It
+// returns the bound, whether any part of it was quoted, and the remainder of
+// s.
+func parseRangeBound(s string) (bound string, quoted bool, remaining string, _ error) {
+	var b strings.Builder
+	inQuote := false
+	i := 0
+	for ; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '\\':
+			i++
+			if i >= len(s) {
+				return "", false, "", malformedRangeError
+			}
+			b.WriteByte(s[i])
+		case ch == '"' && inQuote && i+1 < len(s) && s[i+1] == '"':
+			i++
+			b.WriteByte('"')
+		case ch == '"':
+			inQuote = !inQuote
+			quoted = true
+		case !inQuote && (ch == ',' || ch == '(' || ch == ')' || ch == '[' || ch == ']'):
+			if ch != ',' {
+				return "", false, "", malformedRangeError
+			}
+			return b.String(), quoted, s[i:], nil
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	if inQuote {
+		return "", false, "", malformedRangeError
+	}
+	return b.String(), quoted, s[i:], nil
+}
+
+var malformedMultirangeError = pgerror.Newf(pgcode.InvalidTextRepresentation, "malformed multirange literal")
+
+// ParseDMultirange parses the string-form of a multirange, such as
+// `{[1,3), [5,7)}` or `{}`, into a multirange of the given multirange type.
+//
+// The dependsOnContext return value indicates if we had to consult the
+// ParseTimeContext (either for the time or the local timezone).
+func ParseDMultirange(
+	ctx ParseTimeContext, s string, t *types.T,
+) (_ *DMultirange, dependsOnContext bool, _ error) {
+	ret, dependsOnContext, err := doParseDMultirange(ctx, s, t)
+	if err != nil {
+		return nil, false, MakeParseError(s, t, err)
+	}
+	return ret, dependsOnContext, nil
+}
+
+// doParseDMultirange does most of the work of ParseDMultirange, except the
+// error it returns isn't prettified as a parsing error.
+func doParseDMultirange(
+	ctx ParseTimeContext, s string, t *types.T,
+) (_ *DMultirange, dependsOnContext bool, _ error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, false, malformedMultirangeError
+	}
+	rest := strings.TrimSpace(s[1 : len(s)-1])
+	var ranges []*DRange
+	for len(rest) > 0 {
+		str, remaining, err := splitMultirangeElement(rest)
+		if err != nil {
+			return nil, false, err
+		}
+		r, depends, err := doParseDRange(ctx, str, t.MultirangeContents())
+		if err != nil {
+			return nil, false, err
+		}
+		dependsOnContext = dependsOnContext || depends
+		ranges = append(ranges, r)
+
+		rest = strings.TrimSpace(remaining)
+		if len(rest) > 0 {
+			if rest[0] != ',' {
+				return nil, false, malformedMultirangeError
+			}
+			rest = strings.TrimSpace(rest[1:])
+			if len(rest) == 0 {
+				return nil, false, malformedMultirangeError
+			}
+		}
+	}
+	return NewDMultirange(t, ranges...), dependsOnContext, nil
+}
+
+// splitMultirangeElement returns the range literal at the start of s, which
+// is either `empty` or ends at the first unquoted closing bracket or
+// parenthesis, and the remainder of s.
+func splitMultirangeElement(s string) (elem string, remaining string, _ error) {
+	const empty = "empty"
+	if len(s) >= len(empty) && strings.EqualFold(s[:len(empty)], empty) {
+		return s[:len(empty)], s[len(empty):], nil
+	}
+	if s[0] != '[' && s[0] != '(' {
+		return "", "", malformedMultirangeError
+	}
+	inQuote := false
+	for i := 1; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\':
+			i++
+		case ch == '"':
+			inQuote = !inQuote
+		case !inQuote && (ch == ']' || ch == ')'):
+			return s[:i+1], s[i+1:], nil
+		}
+	}
+	return "", "", malformedMultirangeError
+}
@@ -148,7 +148,7 @@ func (d *DArray) pgwireFormat(ctx *FmtCtx) {
 	}
 }
 
-var tupleQuoteSet, arrayQuoteSet asciiSet
+var tupleQuoteSet, arrayQuoteSet, rangeQuoteSet asciiSet
 
 func init() {
 	var ok bool
@@ -160,6 +160,10 @@ func init() {
 	if !ok {
 		panic("array asciiset")
 	}
+	rangeQuoteSet, ok = makeASCIISet(" \t\v\f\r\n()[],\"\\")
+	if !ok {
+		panic("range asciiset")
+	}
 }
 
 func pgwireQuoteStringInTuple(in string) bool {
@@ -204,6 +208,32 @@ func pgwireFormatStringInArray(ctx *FmtCtx, in string) {
 	}
 }
 
+func pgwireQuoteStringInRange(in string) bool {
+	return in == "" || rangeQuoteSet.in(in)
+}
+
+// pgwireFormatStringInRange writes a bound of a range, which is quoted if it
+// is empty or contains any special characters.
+func pgwireFormatStringInRange(buf *bytes.Buffer, in string) {
+	quote := pgwireQuoteStringInRange(in)
+	if quote {
+		buf.WriteByte('"')
+	}
+	// Loop through each unicode code point.
+	for _, r := range in {
+		if r == '"' || r == '\\' {
+			// Strings in ranges double " and \.
+			buf.WriteByte(byte(r))
+			buf.WriteByte(byte(r))
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+	if quote {
+		buf.WriteByte('"')
+	}
+}
+
 // From: https://github.com/golang/go/blob/master/src/strings/strings.go
 
 // asciiSet is a 32-byte value, where each bit represents the presence of a
@@ -15,6 +15,7 @@ type StreamIngestion struct {
 	Targets TargetList
 	From    StringOrPlaceholderOptList
 	AsOf    AsOfClause
+	Options KVOptions
 }
 
 var _ Statement = &StreamIngestion{}
@@ -30,4 +31,8 @@ func (node *StreamIngestion) Format(ctx *FmtCtx) {
 		ctx.WriteString(" ")
 		ctx.FormatNode(&node.AsOf)
 	}
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
 }
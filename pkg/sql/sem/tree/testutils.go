@@ -81,6 +81,17 @@ func SampleDatum(t *types.T) Datum {
 		return j
 	case types.OidFamily:
 		return NewDOid(DInt(1009))
+	case types.TSQueryFamily:
+		q, _ := ParseDTSQuery(`fat & rat`)
+		return q
+	case types.TSVectorFamily:
+		v, _ := ParseDTSVector(`fat:1 rat:2`)
+		return v
+	case types.RangeFamily:
+		r, _ := NewDRange(t, SampleDatum(t.RangeContents()), DNull, true /* lowerInc */, false /* upperInc */)
+		return r
+	case types.MultirangeFamily:
+		return NewDMultirange(t, SampleDatum(t.MultirangeContents()).(*DRange))
 	case types.Box2DFamily:
 		b := geo.NewCartesianBoundingBox().AddPoint(1, 2).AddPoint(3, 4)
 		return NewDBox2D(*b)
@@ -225,3 +225,48 @@ func NewSchemaChangerModeFromString(val string) (_ NewSchemaChangerMode, ok bool
 		return 0, false
 	}
 }
+
+// PlanCacheMode controls whether prepared statements with placeholders are
+// executed using a custom plan, which is optimized for the placeholder values
+// of each execution, or a generic plan, which is optimized once without
+// knowing the placeholder values and is reused for all executions.
+// NB: The values of the enums must be stable across releases.
+type PlanCacheMode int64
+
+const (
+	// PlanCacheModeForceCustom means that a custom plan is always used.
+	PlanCacheModeForceCustom PlanCacheMode = 0
+	// PlanCacheModeForceGeneric means that a generic plan is always used.
+	PlanCacheModeForceGeneric PlanCacheMode = 1
+	// PlanCacheModeAuto means that custom plans are used for the first few
+	// executions, after which the generic plan is used if its estimated cost
+	// is no worse than the average estimated cost of the custom plans.
+	PlanCacheModeAuto PlanCacheMode = 2
+)
+
+func (m PlanCacheMode) String() string {
+	switch m {
+	case PlanCacheModeForceCustom:
+		return "force_custom_plan"
+	case PlanCacheModeForceGeneric:
+		return "force_generic_plan"
+	case PlanCacheModeAuto:
+		return "auto"
+	default:
+		return fmt.Sprintf("invalid (%d)", m)
+	}
+}
+
+// PlanCacheModeFromString converts a string into a PlanCacheMode.
+func PlanCacheModeFromString(val string) (_ PlanCacheMode, ok bool) {
+	switch strings.ToUpper(val) {
+	case "FORCE_CUSTOM_PLAN":
+		return PlanCacheModeForceCustom, true
+	case "FORCE_GENERIC_PLAN":
+		return PlanCacheModeForceGeneric, true
+	case "AUTO":
+		return PlanCacheModeAuto, true
+	default:
+		return 0, false
+	}
+}
@@ -141,9 +141,17 @@ func (s *PersistedSQLStats) doFlushSingleTxnStats(
 func (s *PersistedSQLStats) doFlushSingleStmtStats(
 	ctx context.Context, stats *roachpb.CollectedStatementStatistics, aggregatedTs time.Time,
 ) error {
+	// The CPU time is taken outside of the transaction so that it is not
+	// lost if the transaction is retried. It is only attributed to the first
+	// flushed statistics of the fingerprint and application.
+	var cpuNanos int64
+	if s.cfg.TakeStmtCPUNanos != nil {
+		cpuNanos = s.cfg.TakeStmtCPUNanos(stats.ID, stats.Key.App)
+	}
 	return s.cfg.KvDB.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
 		// Explicitly copy the stats so that this closure is retryable.
 		scopedStats := *stats
+		scopedStats.Stats.CPUNanos += cpuNanos
 
 		serializedFingerprintID := sqlstatsutil.EncodeUint64ToBytes(uint64(scopedStats.ID))
 		serializedTransactionFingerprintID := sqlstatsutil.EncodeUint64ToBytes(uint64(scopedStats.Key.TransactionFingerprintID))
@@ -0,0 +1,337 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package persistedsqlstats
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/clusterversion"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats/persistedsqlstats/sqlstatsutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// PlanRegressionDetectionEnabled is the cluster setting that controls whether
+// statement fingerprints are watched for plan changes that regress latency.
+var PlanRegressionDetectionEnabled = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.stats.plan_regression_detection.enabled",
+	"if set, a plan_regression event is logged when a statement fingerprint "+
+		"switches to a query plan with a significantly higher mean latency",
+	true, /* defaultValue */
+).WithPublic()
+
+// PlanRegressionDetectionInterval is the cluster setting that controls how
+// often the in-memory SQL stats are checked for plan regressions.
+var PlanRegressionDetectionInterval = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"sql.stats.plan_regression_detection.interval",
+	"the interval at which SQL execution statistics are checked for plan regressions",
+	time.Minute,
+	settings.PositiveDuration,
+)
+
+// PlanRegressionLatencyRatio is the cluster setting that controls by how much
+// the mean latency of a new plan has to exceed the mean latency of a
+// previously used plan for the plan change to be reported.
+var PlanRegressionLatencyRatio = settings.RegisterFloatSetting(
+	settings.TenantWritable,
+	"sql.stats.plan_regression_detection.latency_ratio",
+	"the ratio between the mean latencies of a new and a previously used query plan "+
+		"of a statement fingerprint above which the plan change is reported as a regression",
+	2.0,
+	func(f float64) error {
+		if f <= 1 {
+			return errors.Newf("%f is not greater than 1", f)
+		}
+		return nil
+	},
+).WithPublic()
+
+// PlanRegressionMinExecutionCount is the cluster setting that controls how
+// many times a plan has to be executed before its mean latency is considered
+// by the plan regression detection.
+var PlanRegressionMinExecutionCount = settings.RegisterIntSetting(
+	settings.TenantWritable,
+	"sql.stats.plan_regression_detection.min_execution_count",
+	"the number of executions of a query plan needed before its mean latency "+
+		"is considered by the plan regression detection",
+	10,
+	settings.PositiveInt,
+)
+
+// maxPlanBaselines bounds the number of statement fingerprints whose
+// best-known plan is remembered by the plan regression detector. The
+// fingerprints that were least recently executed are forgotten first.
+const maxPlanBaselines = 10000
+
+// planRegressionReportTTL is the time after which a plan regression reported
+// by a node of the cluster may be reported again, by any node, if the
+// fingerprint regresses to the same plan again.
+const planRegressionReportTTL = time.Hour
+
+type planRegressionKey struct {
+	fingerprintID roachpb.StmtFingerprintID
+	app           string
+}
+
+// planStats summarizes the statistics of a statement fingerprint executed
+// with one query plan.
+type planStats struct {
+	key         planRegressionKey
+	stmt        string
+	planHash    uint64
+	planGist    string
+	meanLatency float64
+	count       int64
+	lastExec    time.Time
+}
+
+// planBaseline is what the plan regression detector remembers about a
+// statement fingerprint.
+type planBaseline struct {
+	// best is the plan with the lowest mean latency seen so far.
+	best planStats
+	// reportedPlanHash is the hash of the plan that was last reported as a
+	// regression, so that it is reported only once. It is zero if the current
+	// plan is not a regression.
+	reportedPlanHash uint64
+}
+
+// planRegressionDetector detects statement fingerprints whose most recently
+// used plan is slower than a plan used before. It remembers the best plan of
+// each fingerprint across resets of the in-memory SQL stats.
+type planRegressionDetector struct {
+	st *cluster.Settings
+
+	// baselines maps the planRegressionKey of each fingerprint to its
+	// *planBaseline. It holds at most maxPlanBaselines entries, and evicts the
+	// least recently checked fingerprints first.
+	baselines *cache.UnorderedCache
+}
+
+func newPlanRegressionDetector(st *cluster.Settings) *planRegressionDetector {
+	return &planRegressionDetector{
+		st: st,
+		baselines: cache.NewUnorderedCache(cache.Config{
+			Policy: cache.CacheLRU,
+			ShouldEvict: func(size int, _, _ interface{}) bool {
+				return size > maxPlanBaselines
+			},
+		}),
+	}
+}
+
+func (s *PersistedSQLStats) startPlanRegressionDetector(ctx context.Context, stopper *stop.Stopper) {
+	_ = stopper.RunAsyncTask(ctx, "sql-stats-plan-regression-detector", func(ctx context.Context) {
+		d := newPlanRegressionDetector(s.cfg.Settings)
+		timer := timeutil.NewTimer()
+		defer timer.Stop()
+		for {
+			timer.Reset(PlanRegressionDetectionInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-timer.C:
+				timer.Read = true
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+			if !PlanRegressionDetectionEnabled.Get(&s.cfg.Settings.SV) {
+				continue
+			}
+			var stats []planStats
+			if err := s.SQLStats.IterateStatementStats(ctx, &sqlstats.IteratorOptions{},
+				func(ctx context.Context, stmt *roachpb.CollectedStatementStatistics) error {
+					if stmt.Key.Failed || stmt.Key.PlanHash == 0 {
+						return nil
+					}
+					ps := planStats{
+						key:         planRegressionKey{fingerprintID: stmt.ID, app: stmt.Key.App},
+						stmt:        stmt.Key.Query,
+						planHash:    stmt.Key.PlanHash,
+						meanLatency: stmt.Stats.ServiceLat.Mean,
+						count:       stmt.Stats.Count,
+						lastExec:    stmt.Stats.LastExecTimestamp,
+					}
+					if len(stmt.Stats.PlanGists) > 0 {
+						ps.planGist = stmt.Stats.PlanGists[0]
+					}
+					stats = append(stats, ps)
+					return nil
+				}); err != nil {
+				log.Warningf(ctx, "failed to check SQL stats for plan regressions: %s", err)
+				continue
+			}
+			for _, r := range d.check(stats) {
+				// Every node checks its own statistics, so the same regression
+				// is usually detected by several nodes. Only the node that
+				// claims it reports it.
+				claimed, err := s.claimPlanRegression(ctx, r.key, r.planHash)
+				if err != nil {
+					// Rather lose the deduplication than the event.
+					log.Warningf(ctx, "failed to claim plan regression: %s", err)
+					claimed = true
+				}
+				if claimed {
+					log.StructuredEvent(ctx, r.event)
+				}
+			}
+		}
+	})
+}
+
+// claimPlanRegression records in system.statement_plan_regressions that the
+// given plan regression of a fingerprint is reported, and returns whether the
+// caller should report it. It returns false if the same regression was already
+// reported, by any node, within planRegressionReportTTL.
+func (s *PersistedSQLStats) claimPlanRegression(
+	ctx context.Context, key planRegressionKey, planHash uint64,
+) (bool, error) {
+	if !s.cfg.Settings.Version.IsActive(ctx, clusterversion.StatementPlanPinsTable) {
+		return true, nil
+	}
+	return claimPlanRegression(ctx, s.cfg.InternalExecutor, key, planHash, planRegressionReportTTL)
+}
+
+func claimPlanRegression(
+	ctx context.Context,
+	ie sqlutil.InternalExecutor,
+	key planRegressionKey,
+	planHash uint64,
+	ttl time.Duration,
+) (bool, error) {
+	override := sessiondata.InternalExecutorOverride{User: security.NodeUserName()}
+	expiredBefore := timeutil.Now().Add(-ttl)
+	// Forget the regressions reported long ago, so that the table only holds
+	// the fingerprints that regressed recently.
+	if _, err := ie.ExecEx(ctx, "delete-plan-regressions", nil /* txn */, override,
+		`DELETE FROM system.statement_plan_regressions
+WHERE reported_at < $1
+LIMIT 1000`,
+		expiredBefore,
+	); err != nil {
+		return false, err
+	}
+	rowsAffected, err := ie.ExecEx(ctx, "claim-plan-regression", nil /* txn */, override,
+		`INSERT INTO system.statement_plan_regressions
+  (fingerprint_id, app_name, plan_hash, reported_at)
+VALUES ($1, $2, $3, now())
+ON CONFLICT (fingerprint_id, app_name) DO UPDATE
+SET plan_hash = excluded.plan_hash, reported_at = excluded.reported_at
+WHERE statement_plan_regressions.plan_hash != excluded.plan_hash
+  OR statement_plan_regressions.reported_at < $4`,
+		sqlstatsutil.EncodeUint64ToBytes(uint64(key.fingerprintID)),
+		key.app,
+		sqlstatsutil.EncodeUint64ToBytes(planHash),
+		expiredBefore,
+	)
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// planRegression is a plan regression detected by a planRegressionDetector.
+type planRegression struct {
+	key      planRegressionKey
+	planHash uint64
+	event    *eventpb.PlanRegression
+}
+
+// check updates the baselines with the given statistics, and returns the
+// fingerprints whose most recently executed plan regressed.
+func (d *planRegressionDetector) check(stats []planStats) []planRegression {
+	ratio := PlanRegressionLatencyRatio.Get(&d.st.SV)
+	minCount := PlanRegressionMinExecutionCount.Get(&d.st.SV)
+
+	byKey := make(map[planRegressionKey][]planStats)
+	for _, ps := range stats {
+		if ps.count < minCount {
+			continue
+		}
+		byKey[ps.key] = append(byKey[ps.key], ps)
+	}
+
+	var regressions []planRegression
+	for key, plans := range byKey {
+		// The current plan is the one that was executed most recently.
+		current := plans[0]
+		for _, ps := range plans[1:] {
+			if ps.lastExec.After(current.lastExec) {
+				current = ps
+			}
+		}
+		// The previous plan is the fastest plan other than the current one,
+		// among the plans in the stats and the remembered baseline.
+		var previous planStats
+		var hasPrevious bool
+		candidates := plans
+		baseline := &planBaseline{}
+		if v, ok := d.baselines.Get(key); ok {
+			baseline = v.(*planBaseline)
+			candidates = append(candidates, baseline.best)
+		} else {
+			d.baselines.Add(key, baseline)
+		}
+		best := current
+		for _, ps := range candidates {
+			if ps.meanLatency < best.meanLatency {
+				best = ps
+			}
+			if ps.planHash == current.planHash {
+				continue
+			}
+			if !hasPrevious || ps.meanLatency < previous.meanLatency {
+				previous, hasPrevious = ps, true
+			}
+		}
+
+		baseline.best = best
+
+		if !hasPrevious || current.meanLatency <= ratio*previous.meanLatency {
+			baseline.reportedPlanHash = 0
+			continue
+		}
+		if baseline.reportedPlanHash == current.planHash {
+			continue
+		}
+		baseline.reportedPlanHash = current.planHash
+		ev := &eventpb.PlanRegression{
+			StatementFingerprintID: hex.EncodeToString(
+				sqlstatsutil.EncodeUint64ToBytes(uint64(key.fingerprintID))),
+			Statement:           current.stmt,
+			ApplicationName:     key.app,
+			PreviousPlanGist:    previous.planGist,
+			PreviousMeanLatency: float32(previous.meanLatency * 1000),
+			PlanGist:            current.planGist,
+			MeanLatency:         float32(current.meanLatency * 1000),
+			ExecutionCount:      current.count,
+		}
+		regressions = append(regressions, planRegression{
+			key:      key,
+			planHash: current.planHash,
+			event:    ev,
+		})
+	}
+	return regressions
+}
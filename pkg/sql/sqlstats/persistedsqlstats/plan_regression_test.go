@@ -0,0 +1,145 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package persistedsqlstats
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRegressionDetector(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	PlanRegressionMinExecutionCount.Override(ctx, &st.SV, 5)
+	d := newPlanRegressionDetector(st)
+
+	key := planRegressionKey{fingerprintID: 0x0102030405060708, app: "app"}
+	now := time.Now()
+	plan := func(hash uint64, meanLatency float64, count int64, lastExec time.Time) planStats {
+		return planStats{
+			key:         key,
+			stmt:        "SELECT _ FROM t WHERE a = _",
+			planHash:    hash,
+			planGist:    fmt.Sprintf("gist%d", hash),
+			meanLatency: meanLatency,
+			count:       count,
+			lastExec:    lastExec,
+		}
+	}
+
+	// A single plan is never a regression.
+	require.Empty(t, d.check([]planStats{plan(1, 0.01, 10, now)}))
+
+	// A new plan that is slightly slower is not a regression.
+	require.Empty(t, d.check([]planStats{
+		plan(1, 0.01, 10, now),
+		plan(2, 0.015, 10, now.Add(time.Second)),
+	}))
+
+	// A new plan that has not been executed enough times is ignored.
+	require.Empty(t, d.check([]planStats{
+		plan(1, 0.01, 10, now),
+		plan(3, 1, 2, now.Add(2*time.Second)),
+	}))
+
+	// The in-memory stats were reset, so the best plan is only known from the
+	// baseline. The new plan is more than twice as slow.
+	regressions := d.check([]planStats{plan(3, 0.05, 10, now.Add(3*time.Second))})
+	require.Len(t, regressions, 1)
+	require.Equal(t, key, regressions[0].key)
+	require.Equal(t, uint64(3), regressions[0].planHash)
+	ev := regressions[0].event
+	require.Equal(t, "0102030405060708", ev.StatementFingerprintID)
+	require.Equal(t, "app", ev.ApplicationName)
+	require.Equal(t, "gist1", ev.PreviousPlanGist)
+	require.Equal(t, "gist3", ev.PlanGist)
+	require.InDelta(t, 10, ev.PreviousMeanLatency, 0.001)
+	require.InDelta(t, 50, ev.MeanLatency, 0.001)
+	require.Equal(t, int64(10), ev.ExecutionCount)
+
+	// The same regression is only reported once.
+	require.Empty(t, d.check([]planStats{plan(3, 0.06, 20, now.Add(4*time.Second))}))
+
+	// Switching back to the good plan clears the report, and regressing again
+	// is reported again.
+	require.Empty(t, d.check([]planStats{
+		plan(3, 0.06, 20, now.Add(4*time.Second)),
+		plan(1, 0.01, 10, now.Add(5*time.Second)),
+	}))
+	require.Len(t, d.check([]planStats{
+		plan(1, 0.01, 10, now.Add(5*time.Second)),
+		plan(3, 0.06, 30, now.Add(6*time.Second)),
+	}), 1)
+
+	// A higher ratio suppresses the report.
+	PlanRegressionLatencyRatio.Override(ctx, &st.SV, 10)
+	d = newPlanRegressionDetector(st)
+	require.Empty(t, d.check([]planStats{
+		plan(1, 0.01, 10, now),
+		plan(3, 0.06, 30, now.Add(time.Second)),
+	}))
+
+	// The least recently checked fingerprints are forgotten first.
+	d = newPlanRegressionDetector(st)
+	for i := 0; i <= maxPlanBaselines; i++ {
+		ps := plan(1, 0.01, 10, now)
+		ps.key.fingerprintID += roachpb.StmtFingerprintID(i)
+		d.check([]planStats{ps})
+	}
+	require.Equal(t, maxPlanBaselines, d.baselines.Len())
+	_, ok := d.baselines.Get(key)
+	require.False(t, ok)
+}
+
+func TestClaimPlanRegression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	ctx := context.Background()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	ie := s.InternalExecutor().(sqlutil.InternalExecutor)
+
+	key := planRegressionKey{fingerprintID: 0x0102030405060708, app: "app"}
+	claim := func(key planRegressionKey, planHash uint64, ttl time.Duration) bool {
+		claimed, err := claimPlanRegression(ctx, ie, key, planHash, ttl)
+		require.NoError(t, err)
+		return claimed
+	}
+
+	// The first node to claim a regression reports it, the other nodes that
+	// detect the same regression do not.
+	require.True(t, claim(key, 1, time.Hour))
+	require.False(t, claim(key, 1, time.Hour))
+
+	// Regressions to other plans, and of other fingerprints, are claimed
+	// separately.
+	require.True(t, claim(key, 2, time.Hour))
+	require.False(t, claim(key, 2, time.Hour))
+	require.True(t, claim(planRegressionKey{fingerprintID: key.fingerprintID, app: "other"}, 2, time.Hour))
+
+	// A regression can be reported again once its report expired.
+	require.True(t, claim(key, 2, 0 /* ttl */))
+}
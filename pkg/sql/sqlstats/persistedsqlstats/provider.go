@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlstats"
@@ -46,6 +47,12 @@ type Config struct {
 	SQLIDContainer   *base.SQLIDContainer
 	JobRegistry      *jobs.Registry
 
+	// TakeStmtCPUNanos, if set, returns the CPU time attributed to the
+	// statements with the given fingerprint ID and application name since
+	// it was last called for them. It is persisted with the statement
+	// statistics.
+	TakeStmtCPUNanos func(roachpb.StmtFingerprintID, string) int64
+
 	// Metrics.
 	FlushCounter   *metric.Counter
 	FlushDuration  *metric.Histogram
@@ -103,6 +110,7 @@ func New(cfg *Config, memSQLStats *sslocal.SQLStats) *PersistedSQLStats {
 // Start implements sqlstats.Provider interface.
 func (s *PersistedSQLStats) Start(ctx context.Context, stopper *stop.Stopper) {
 	s.startSQLStatsFlushLoop(ctx, stopper)
+	s.startPlanRegressionDetector(ctx, stopper)
 	s.jobMonitor.start(ctx, stopper)
 }
 
@@ -97,7 +97,8 @@ func TestSQLStatsJsonEncoding(t *testing.T) {
            "sqDiff": {{.Float}}
          },
          "nodes": [{{joinInts .IntArray}}],
-         "planGists": [{{joinStrings .StringArray}}]
+         "planGists": [{{joinStrings .StringArray}}],
+         "cpuNanos": {{.Int64}}
        },
        "execution_statistics": {
          "cnt": {{.Int64}},
@@ -212,7 +213,8 @@ func TestSQLStatsJsonEncoding(t *testing.T) {
            "sqDiff": {{.Float}}
          },
          "nodes": [{{joinInts .IntArray}}]
-         "planGists": [{{joinStrings .StringArray}}]
+         "planGists": [{{joinStrings .StringArray}}],
+         "cpuNanos": {{.Int64}}
        },
        "execution_statistics": {
          "cnt": {{.Int64}},
@@ -271,6 +271,7 @@ func (s *innerStmtStats) jsonFields() jsonFields {
 		{"rowsWritten", (*numericStats)(&s.RowsWritten)},
 		{"nodes", (*int64Array)(&s.Nodes)},
 		{"planGists", (*stringArray)(&s.PlanGists)},
+		{"cpuNanos", (*jsonInt)(&s.CPUNanos)},
 	}
 }
 
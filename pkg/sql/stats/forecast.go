@@ -0,0 +1,592 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/errors"
+)
+
+// UseStatisticsForecasts controls whether statistics forecasts are generated
+// from the history of collected statistics and used by the optimizer.
+var UseStatisticsForecasts = settings.RegisterBoolSetting(
+	settings.TenantWritable,
+	"sql.stats.forecasts.enabled",
+	"when true, statistics forecasted from the history of collected statistics are used by the optimizer",
+	true,
+).WithPublic()
+
+const (
+	// minObservationsForForecast is the minimum number of collected statistics
+	// on a set of columns needed to forecast statistics on those columns.
+	minObservationsForForecast = 3
+
+	// maxForecastDistance is the farthest into the future, relative to the most
+	// recent collected statistic, that a forecast is made.
+	maxForecastDistance = 7 * 24 * time.Hour
+
+	// minGoodnessOfFit is the minimum R² of the linear regression of a
+	// quantity over time for the regression to be used in a forecast.
+	minGoodnessOfFit = 0.95
+
+	// maxForecastHistogramBuckets is the maximum number of buckets in a
+	// forecasted histogram.
+	maxForecastHistogramBuckets = 200
+)
+
+// ForecastTableStatistics forecasts statistics for each set of columns of the
+// given collected statistics, which must be ordered by creation time, most
+// recent first. Each forecast is made for the expected time of the next
+// collection, by fitting a linear trend to the row count, distinct count, null
+// count, average size and histogram of the collected statistics. Sets of
+// columns with too few collected statistics, or whose row count does not
+// follow a linear trend, are not forecasted.
+func ForecastTableStatistics(ctx context.Context, observed []*TableStatistic) []*TableStatistic {
+	// Group the collected statistics by column set, keeping them ordered by
+	// creation time within each group.
+	var groups [][]*TableStatistic
+	for _, stat := range observed {
+		if stat.Name == jobspb.ForecastStatsName {
+			continue
+		}
+		found := false
+		for i := range groups {
+			if areEqual(groups[i][0].ColumnIDs, stat.ColumnIDs) {
+				groups[i] = append(groups[i], stat)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []*TableStatistic{stat})
+		}
+	}
+
+	var forecasts []*TableStatistic
+	for _, group := range groups {
+		forecast, err := forecastColumnStatistics(group)
+		if err != nil {
+			log.VEventf(
+				ctx, 2, "could not forecast statistics on columns %v of table %d: %v",
+				group[0].ColumnIDs, group[0].TableID, err,
+			)
+			continue
+		}
+		if forecast != nil {
+			forecasts = append(forecasts, forecast)
+		}
+	}
+	return forecasts
+}
+
+// forecastColumnStatistics forecasts a statistic from the given collected
+// statistics on the same set of columns, ordered most recent first. It returns
+// nil if the forecast would be identical to the most recent statistic.
+func forecastColumnStatistics(observed []*TableStatistic) (*TableStatistic, error) {
+	if len(observed) < minObservationsForForecast {
+		return nil, errors.Newf(
+			"%d statistics are not enough to forecast, need at least %d",
+			len(observed), minObservationsForForecast,
+		)
+	}
+	latest := observed[0]
+	oldest := observed[len(observed)-1]
+	interval := latest.CreatedAt.Sub(oldest.CreatedAt) / time.Duration(len(observed)-1)
+	if interval <= 0 {
+		return nil, errors.New("statistics were all collected at the same time")
+	}
+	if interval > maxForecastDistance {
+		interval = maxForecastDistance
+	}
+
+	// Times are measured in seconds relative to the most recent statistic.
+	x := make([]float64, len(observed))
+	for i, stat := range observed {
+		x[i] = stat.CreatedAt.Sub(latest.CreatedAt).Seconds()
+	}
+	at := interval.Seconds()
+	quantity := func(get func(stat *TableStatistic) uint64) []float64 {
+		y := make([]float64, len(observed))
+		for i, stat := range observed {
+			y[i] = float64(get(stat))
+		}
+		return y
+	}
+
+	rowCount, ok := forecastQuantity(x, quantity(func(s *TableStatistic) uint64 { return s.RowCount }), at)
+	if !ok {
+		return nil, errors.New("row count does not follow a linear trend")
+	}
+	orLatest := func(get func(stat *TableStatistic) uint64) float64 {
+		if v, ok := forecastQuantity(x, quantity(get), at); ok {
+			return v
+		}
+		return float64(get(latest))
+	}
+	nullCount := orLatest(func(s *TableStatistic) uint64 { return s.NullCount })
+	distinctCount := orLatest(func(s *TableStatistic) uint64 { return s.DistinctCount })
+	avgSize := orLatest(func(s *TableStatistic) uint64 { return s.AvgSize })
+
+	// Keep the forecasted counts consistent with each other.
+	rowCount = math.Max(math.Round(rowCount), 0)
+	nullCount = math.Min(math.Max(math.Round(nullCount), 0), rowCount)
+	nonNullRowCount := rowCount - nullCount
+	var minDistinct, maxDistinct float64
+	if nullCount > 0 {
+		minDistinct, maxDistinct = 1, 1
+	}
+	if nonNullRowCount > 0 {
+		minDistinct++
+		maxDistinct += nonNullRowCount
+	}
+	distinctCount = math.Min(math.Max(math.Round(distinctCount), minDistinct), maxDistinct)
+	avgSize = math.Max(math.Round(avgSize), 0)
+
+	forecast := &TableStatistic{
+		TableStatisticProto: TableStatisticProto{
+			TableID:       latest.TableID,
+			Name:          jobspb.ForecastStatsName,
+			ColumnIDs:     latest.ColumnIDs,
+			CreatedAt:     latest.CreatedAt.Add(interval),
+			RowCount:      uint64(rowCount),
+			DistinctCount: uint64(distinctCount),
+			NullCount:     uint64(nullCount),
+			AvgSize:       uint64(avgSize),
+		},
+	}
+	changed := forecast.RowCount != latest.RowCount ||
+		forecast.DistinctCount != latest.DistinctCount ||
+		forecast.NullCount != latest.NullCount ||
+		forecast.AvgSize != latest.AvgSize
+
+	if latest.HistogramData != nil {
+		histChanged, err := forecastHistogram(forecast, observed, x, at)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || histChanged
+	}
+	if !changed {
+		return nil, nil
+	}
+	return forecast, nil
+}
+
+// forecastHistogram sets the histogram of the forecast. If the histograms of
+// the collected statistics are over a numeric, date or timestamp type and
+// their quantile functions follow a linear trend, the forecasted quantile
+// function is converted back to a histogram. Otherwise, the histogram of the
+// most recent statistic is scaled to the forecasted counts. It returns whether
+// the quantile function differs from the one of the most recent statistic.
+func forecastHistogram(
+	forecast *TableStatistic, observed []*TableStatistic, x []float64, at float64,
+) (changed bool, _ error) {
+	latest := observed[0]
+	colType := latest.HistogramData.ColumnType
+	nonNullRowCount := float64(forecast.RowCount - forecast.NullCount)
+	nonNullDistinctCount := float64(forecast.DistinctCount)
+	if forecast.NullCount > 0 {
+		nonNullDistinctCount--
+	}
+	latestBuckets := nonNullBuckets(latest.Histogram)
+	if nonNullRowCount == 0 || len(latestBuckets) == 0 {
+		return false, forecast.setHistogram(colType, nil /* buckets */)
+	}
+
+	values, ok := forecastQuantiles(observed, x, at)
+	if ok {
+		// Compare the forecasted quantile function with the most recent one.
+		latestQuantiles, _ := makeQuantileFunction(latestBuckets)
+		k := len(values) - 1
+		for j, v := range values {
+			if w := latestQuantiles.eval(float64(j) / float64(k)); math.Abs(v-w) > 1e-9*math.Max(1, math.Abs(w)) {
+				changed = true
+				break
+			}
+		}
+	}
+	if !changed {
+		return false, forecast.setHistogram(
+			colType, scaleHistogram(latestBuckets, nonNullRowCount, nonNullDistinctCount),
+		)
+	}
+	buckets, err := histogramFromQuantiles(colType, values, nonNullRowCount, nonNullDistinctCount)
+	if err != nil {
+		return false, err
+	}
+	return true, forecast.setHistogram(colType, buckets)
+}
+
+// setHistogram sets both the encoded and the decoded histogram of the
+// statistic from the given non-NULL buckets.
+func (s *TableStatistic) setHistogram(colType *types.T, buckets []cat.HistogramBucket) error {
+	h := histogram{buckets: buckets}
+	histogramData, err := h.toHistogramData(colType)
+	if err != nil {
+		return err
+	}
+	s.HistogramData = &histogramData
+	s.Histogram = make([]cat.HistogramBucket, 0, len(buckets)+1)
+	if s.NullCount > 0 {
+		// As in parseStats, a fake bucket for NULL is added to make histograms
+		// easier to work with.
+		s.Histogram = append(s.Histogram, cat.HistogramBucket{
+			NumEq:      float64(s.NullCount),
+			UpperBound: tree.DNull,
+		})
+	}
+	s.Histogram = append(s.Histogram, buckets...)
+	return nil
+}
+
+// nonNullBuckets returns the buckets of the decoded histogram, without the
+// fake NULL bucket.
+func nonNullBuckets(hist []cat.HistogramBucket) []cat.HistogramBucket {
+	if len(hist) > 0 && hist[0].UpperBound == tree.DNull {
+		return hist[1:]
+	}
+	return hist
+}
+
+// scaleHistogram returns a copy of the given buckets scaled to the given
+// non-NULL row and distinct counts.
+func scaleHistogram(
+	buckets []cat.HistogramBucket, nonNullRowCount, nonNullDistinctCount float64,
+) []cat.HistogramBucket {
+	var rowCount, distinctRange, distinctEq float64
+	for _, b := range buckets {
+		rowCount += b.NumEq + b.NumRange
+		distinctRange += b.DistinctRange
+		if b.NumEq > 0 {
+			distinctEq++
+		}
+	}
+	rowFactor, distinctFactor := float64(1), float64(1)
+	if rowCount > 0 {
+		rowFactor = nonNullRowCount / rowCount
+	}
+	if distinctRange > 0 {
+		distinctFactor = math.Max(nonNullDistinctCount-distinctEq, 0) / distinctRange
+	}
+	scaled := make([]cat.HistogramBucket, len(buckets))
+	for i, b := range buckets {
+		scaled[i] = cat.HistogramBucket{
+			NumEq:         b.NumEq * rowFactor,
+			NumRange:      b.NumRange * rowFactor,
+			DistinctRange: math.Min(b.DistinctRange*distinctFactor, b.NumRange*rowFactor),
+			UpperBound:    b.UpperBound,
+		}
+	}
+	return scaled
+}
+
+// forecastQuantiles fits a linear trend to the quantile functions of the
+// histograms of the collected statistics at evenly spaced fractions of rows,
+// and returns the forecasted values at those fractions. It returns false if
+// the histograms cannot be converted to quantile functions or do not follow a
+// linear trend.
+func forecastQuantiles(observed []*TableStatistic, x []float64, at float64) ([]float64, bool) {
+	latest := observed[0]
+	if latest.HistogramData.ColumnType == nil {
+		return nil, false
+	}
+	quantiles := make([]quantileFunction, len(observed))
+	for i, stat := range observed {
+		if stat.HistogramData == nil || !stat.HistogramData.ColumnType.Equivalent(latest.HistogramData.ColumnType) {
+			return nil, false
+		}
+		var ok bool
+		if quantiles[i], ok = makeQuantileFunction(nonNullBuckets(stat.Histogram)); !ok {
+			return nil, false
+		}
+	}
+
+	k := len(nonNullBuckets(latest.Histogram)) - 1
+	if k < 1 {
+		k = 1
+	} else if k > maxForecastHistogramBuckets-1 {
+		k = maxForecastHistogramBuckets - 1
+	}
+	values := make([]float64, k+1)
+	y := make([]float64, len(observed))
+	var ssResSum, ssTotSum float64
+	for j := range values {
+		p := float64(j) / float64(k)
+		for i := range quantiles {
+			y[i] = quantiles[i].eval(p)
+		}
+		var ssRes, ssTot float64
+		values[j], ssRes, ssTot = fitLinear(x, y, at)
+		ssResSum += ssRes
+		ssTotSum += ssTot
+		if j > 0 && values[j] < values[j-1] {
+			// Quantile functions are non-decreasing.
+			values[j] = values[j-1]
+		}
+	}
+	if goodnessOfFit(ssResSum, ssTotSum) < minGoodnessOfFit {
+		return nil, false
+	}
+	return values, true
+}
+
+// histogramFromQuantiles builds histogram buckets from the values of a
+// quantile function at evenly spaced fractions of rows, so that each bucket
+// after the first one is assigned the same share of the non-NULL rows. The
+// first bucket, which has no range, is assigned the average number of rows per
+// distinct value. Distinct values beyond the bucket upper bounds are spread
+// among the bucket ranges in proportion to their widths.
+func histogramFromQuantiles(
+	colType *types.T, values []float64, nonNullRowCount, nonNullDistinctCount float64,
+) ([]cat.HistogramBucket, error) {
+	discrete := colType.Family() == types.IntFamily || colType.Family() == types.DateFamily
+	share := nonNullRowCount / float64(len(values)-1)
+	buckets := make([]cat.HistogramBucket, 0, len(values))
+	bounds := make([]float64, 0, len(values))
+	var total float64
+	for j, v := range values {
+		if discrete || colType.Family() == types.TimestampFamily ||
+			colType.Family() == types.TimestampTZFamily {
+			v = math.Round(v)
+		}
+		rows := share
+		if j == 0 {
+			rows = nonNullRowCount / math.Max(nonNullDistinctCount, 1)
+		}
+		total += rows
+		if n := len(bounds); n > 0 && v <= bounds[n-1] {
+			buckets[n-1].NumEq += rows
+			continue
+		}
+		d, err := histogramValueFromFloat(colType, v)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, cat.HistogramBucket{NumEq: rows, UpperBound: d})
+		bounds = append(bounds, v)
+	}
+	// Scale the buckets so that they add up to the non-NULL row count.
+	for i := range buckets {
+		buckets[i].NumEq *= nonNullRowCount / total
+	}
+
+	// Spread the distinct values that are not upper bounds among the ranges.
+	remDistinctCount := nonNullDistinctCount - float64(len(buckets))
+	if remDistinctCount <= 0 || len(buckets) < 2 {
+		return buckets, nil
+	}
+	width := bounds[len(bounds)-1] - bounds[0]
+	for i := 1; i < len(buckets); i++ {
+		distinctRange := remDistinctCount * (bounds[i] - bounds[i-1]) / width
+		if discrete {
+			distinctRange = math.Min(distinctRange, bounds[i]-bounds[i-1]-1)
+		}
+		// The rows in the bucket are spread evenly among its distinct values.
+		rows := buckets[i].NumEq
+		buckets[i].DistinctRange = distinctRange
+		buckets[i].NumRange = rows * distinctRange / (distinctRange + 1)
+		buckets[i].NumEq = rows - buckets[i].NumRange
+	}
+	return buckets, nil
+}
+
+// quantileFunction is a piecewise linear approximation of the quantile
+// function of a histogram over a numeric domain: it maps fractions of non-NULL
+// rows, from 0 to 1, to values. Points are ordered by fraction.
+type quantileFunction []quantilePoint
+
+type quantilePoint struct {
+	p, v float64
+}
+
+// makeQuantileFunction converts the given non-NULL histogram buckets into a
+// quantile function, assuming that the rows within the range of each bucket
+// are uniformly distributed. It returns false if the histogram is empty or if
+// its upper bounds cannot be converted to floats.
+func makeQuantileFunction(buckets []cat.HistogramBucket) (quantileFunction, bool) {
+	var total float64
+	for _, b := range buckets {
+		total += b.NumEq + b.NumRange
+	}
+	if total <= 0 {
+		return nil, false
+	}
+	q := make(quantileFunction, 0, 2*len(buckets)+1)
+	var cumulative float64
+	for i, b := range buckets {
+		v, ok := histogramValueToFloat(b.UpperBound)
+		if !ok {
+			return nil, false
+		}
+		if i == 0 {
+			q = append(q, quantilePoint{p: 0, v: v})
+		}
+		// The range of the bucket ends just below the upper bound, and the rows
+		// equal to the upper bound follow it.
+		cumulative += b.NumRange
+		q = append(q, quantilePoint{p: cumulative / total, v: v})
+		cumulative += b.NumEq
+		q = append(q, quantilePoint{p: cumulative / total, v: v})
+	}
+	return q, true
+}
+
+// eval returns the value of the quantile function at the given fraction.
+func (q quantileFunction) eval(p float64) float64 {
+	i := sort.Search(len(q), func(i int) bool { return q[i].p >= p })
+	if i == 0 {
+		return q[0].v
+	}
+	if i == len(q) {
+		return q[len(q)-1].v
+	}
+	lo, hi := q[i-1], q[i]
+	if hi.p == lo.p {
+		return hi.v
+	}
+	return lo.v + (hi.v-lo.v)*(p-lo.p)/(hi.p-lo.p)
+}
+
+// histogramValueToFloat converts a histogram upper bound of a numeric, date or
+// timestamp type to a float. Dates are converted to days and timestamps to
+// microseconds since the Unix epoch.
+func histogramValueToFloat(d tree.Datum) (float64, bool) {
+	var f float64
+	switch t := d.(type) {
+	case *tree.DInt:
+		f = float64(*t)
+	case *tree.DFloat:
+		f = float64(*t)
+	case *tree.DDecimal:
+		var err error
+		if f, err = t.Float64(); err != nil {
+			return 0, false
+		}
+	case *tree.DDate:
+		if !t.IsFinite() {
+			return 0, false
+		}
+		f = float64(t.UnixEpochDays())
+	case *tree.DTimestamp:
+		f = float64(t.Unix())*1e6 + float64(t.Nanosecond()/1000)
+	case *tree.DTimestampTZ:
+		f = float64(t.Unix())*1e6 + float64(t.Nanosecond()/1000)
+	default:
+		return 0, false
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// histogramValueFromFloat is the inverse of histogramValueToFloat for the
+// given type.
+func histogramValueFromFloat(colType *types.T, f float64) (tree.Datum, error) {
+	switch colType.Family() {
+	case types.IntFamily:
+		var lo, hi float64
+		switch colType.Width() {
+		case 16:
+			lo, hi = math.MinInt16, math.MaxInt16
+		case 32:
+			lo, hi = math.MinInt32, math.MaxInt32
+		default:
+			// The largest float below 2^63 that converts to an int64.
+			lo, hi = math.MinInt64, math.Nextafter(math.MaxInt64, 0)
+		}
+		return tree.NewDInt(tree.DInt(math.Min(math.Max(math.Round(f), lo), hi))), nil
+	case types.FloatFamily:
+		return tree.NewDFloat(tree.DFloat(f)), nil
+	case types.DecimalFamily:
+		d := &tree.DDecimal{}
+		if _, err := d.SetFloat64(f); err != nil {
+			return nil, err
+		}
+		return d, nil
+	case types.DateFamily:
+		date, err := pgdate.MakeDateFromUnixEpoch(int64(math.Round(f)))
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDDate(date), nil
+	case types.TimestampFamily:
+		return tree.MakeDTimestamp(timeutil.Unix(0, int64(math.Round(f))*1000), time.Microsecond)
+	case types.TimestampTZFamily:
+		return tree.MakeDTimestampTZ(timeutil.Unix(0, int64(math.Round(f))*1000), time.Microsecond)
+	}
+	return nil, errors.AssertionFailedf("cannot forecast histogram of type %s", colType.SQLString())
+}
+
+// forecastQuantity fits a linear trend to the given observations and returns
+// the value predicted at the given time, and whether the trend fits the
+// observations well enough to be used.
+func forecastQuantity(x, y []float64, at float64) (float64, bool) {
+	v, ssRes, ssTot := fitLinear(x, y, at)
+	return v, goodnessOfFit(ssRes, ssTot) >= minGoodnessOfFit
+}
+
+// fitLinear fits y = a + b*x to the given observations by ordinary least
+// squares. It returns the value predicted at the given x, the sum of squared
+// residuals and the total sum of squares of the observations. The x values
+// must not all be equal.
+func fitLinear(x, y []float64, at float64) (v, ssRes, ssTot float64) {
+	constant := true
+	for i := range y {
+		constant = constant && y[i] == y[0]
+	}
+	if constant {
+		// Avoid rounding errors for the common case of a quantity that does not
+		// change over time.
+		return y[0], 0, 0
+	}
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var sxx, sxy float64
+	for i := range x {
+		sxx += (x[i] - meanX) * (x[i] - meanX)
+		sxy += (x[i] - meanX) * (y[i] - meanY)
+	}
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+	for i := range x {
+		res := y[i] - (intercept + slope*x[i])
+		ssRes += res * res
+		ssTot += (y[i] - meanY) * (y[i] - meanY)
+	}
+	return intercept + slope*at, ssRes, ssTot
+}
+
+// goodnessOfFit returns the coefficient of determination (R²) of a fit. A
+// fit of constant observations is perfect.
+func goodnessOfFit(ssRes, ssTot float64) float64 {
+	if ssTot == 0 {
+		return 1
+	}
+	return 1 - ssRes/ssTot
+}
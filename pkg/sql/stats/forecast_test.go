@@ -0,0 +1,174 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestFitLinear(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	testData := []struct {
+		x, y    []float64
+		at      float64
+		expV    float64
+		expGood bool
+	}{
+		{ // Perfect linear trend.
+			x: []float64{-2, -1, 0}, y: []float64{100, 200, 300}, at: 1, expV: 400, expGood: true,
+		},
+		{ // Constant observations.
+			x: []float64{-2, -1, 0}, y: []float64{0.1, 0.1, 0.1}, at: 1, expV: 0.1, expGood: true,
+		},
+		{ // Noisy observations.
+			x: []float64{-3, -2, -1, 0}, y: []float64{100, 500, 50, 400}, at: 1, expGood: false,
+		},
+	}
+	for i, tc := range testData {
+		v, ok := forecastQuantity(tc.x, tc.y, tc.at)
+		if ok != tc.expGood {
+			t.Fatalf("%d: expected good fit %t, got %t", i, tc.expGood, ok)
+		}
+		if ok && math.Abs(v-tc.expV) > 1e-9 {
+			t.Errorf("%d: expected %f, got %f", i, tc.expV, v)
+		}
+	}
+}
+
+func TestQuantileFunction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	q, ok := makeQuantileFunction([]cat.HistogramBucket{
+		{NumEq: 10, UpperBound: tree.NewDInt(0)},
+		{NumRange: 80, NumEq: 10, DistinctRange: 9, UpperBound: tree.NewDInt(10)},
+	})
+	if !ok {
+		t.Fatal("expected quantile function")
+	}
+	for _, tc := range []struct{ p, v float64 }{
+		{0, 0}, {0.1, 0}, {0.5, 5}, {0.9, 10}, {1, 10},
+	} {
+		if v := q.eval(tc.p); math.Abs(v-tc.v) > 1e-9 {
+			t.Errorf("expected q(%f) = %f, got %f", tc.p, tc.v, v)
+		}
+	}
+
+	if _, ok := makeQuantileFunction([]cat.HistogramBucket{
+		{NumEq: 1, UpperBound: tree.NewDString("a")},
+	}); ok {
+		t.Error("expected no quantile function for strings")
+	}
+}
+
+func TestForecastTableStatistics(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+	ctx := context.Background()
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	// makeStat returns a statistic on column 1 collected after the given
+	// number of hours, with rowCount rows uniformly distributed in [0, max].
+	makeStat := func(t *testing.T, hours int, rowCount, max int64) *TableStatistic {
+		stat := &TableStatistic{
+			TableStatisticProto: TableStatisticProto{
+				TableID:       100,
+				StatisticID:   uint64(hours + 1),
+				Name:          jobspb.AutoStatsName,
+				ColumnIDs:     []descpb.ColumnID{1},
+				CreatedAt:     start.Add(time.Duration(hours) * time.Hour),
+				RowCount:      uint64(rowCount),
+				DistinctCount: uint64(max + 1),
+				AvgSize:       8,
+			},
+		}
+		buckets := []cat.HistogramBucket{
+			{NumEq: 1, UpperBound: tree.NewDInt(0)},
+			{NumRange: float64(rowCount - 2), NumEq: 1, DistinctRange: float64(max - 1), UpperBound: tree.NewDInt(tree.DInt(max))},
+		}
+		if err := stat.setHistogram(types.Int, buckets); err != nil {
+			t.Fatal(err)
+		}
+		return stat
+	}
+
+	t.Run("growing", func(t *testing.T) {
+		observed := []*TableStatistic{
+			makeStat(t, 2, 3000, 2999), makeStat(t, 1, 2000, 1999), makeStat(t, 0, 1000, 999),
+		}
+		forecasts := ForecastTableStatistics(ctx, observed)
+		if len(forecasts) != 1 {
+			t.Fatalf("expected 1 forecast, got %d", len(forecasts))
+		}
+		f := forecasts[0]
+		if f.Name != jobspb.ForecastStatsName {
+			t.Errorf("expected name %s, got %s", jobspb.ForecastStatsName, f.Name)
+		}
+		if exp := start.Add(3 * time.Hour); !f.CreatedAt.Equal(exp) {
+			t.Errorf("expected forecast at %s, got %s", exp, f.CreatedAt)
+		}
+		if f.RowCount != 4000 || f.DistinctCount != 4000 || f.NullCount != 0 || f.AvgSize != 8 {
+			t.Errorf("unexpected counts: %+v", f.TableStatisticProto)
+		}
+		last := f.Histogram[len(f.Histogram)-1].UpperBound
+		if v := int64(*last.(*tree.DInt)); v != 3999 {
+			t.Errorf("expected histogram upper bound 3999, got %d", v)
+		}
+		var rows float64
+		for _, b := range f.Histogram {
+			rows += b.NumEq + b.NumRange
+		}
+		if math.Abs(rows-4000) > 1e-6 {
+			t.Errorf("expected histogram with 4000 rows, got %f", rows)
+		}
+		if len(f.HistogramData.Buckets) != len(f.Histogram) {
+			t.Errorf("expected %d encoded buckets, got %d", len(f.Histogram), len(f.HistogramData.Buckets))
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		observed := []*TableStatistic{
+			makeStat(t, 2, 1000, 999), makeStat(t, 1, 1000, 999), makeStat(t, 0, 1000, 999),
+		}
+		if forecasts := ForecastTableStatistics(ctx, observed); len(forecasts) != 0 {
+			t.Errorf("expected no forecasts, got %d", len(forecasts))
+		}
+	})
+
+	t.Run("noisy", func(t *testing.T) {
+		observed := []*TableStatistic{
+			makeStat(t, 3, 1000, 999), makeStat(t, 2, 5000, 999), makeStat(t, 1, 300, 999), makeStat(t, 0, 4000, 999),
+		}
+		if forecasts := ForecastTableStatistics(ctx, observed); len(forecasts) != 0 {
+			t.Errorf("expected no forecasts, got %d", len(forecasts))
+		}
+	})
+
+	t.Run("too few", func(t *testing.T) {
+		observed := []*TableStatistic{makeStat(t, 1, 2000, 1999), makeStat(t, 0, 1000, 999)}
+		if forecasts := ForecastTableStatistics(ctx, observed); len(forecasts) != 0 {
+			t.Errorf("expected no forecasts, got %d", len(forecasts))
+		}
+	})
+}
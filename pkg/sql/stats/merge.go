@@ -0,0 +1,128 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/errors"
+)
+
+// MergeExtremesStatistic merges a partial statistic, collected by CREATE
+// STATISTICS ... USING EXTREMES on the values outside the bounds of the
+// histogram of the full statistic with the given ID, into that full
+// statistic. The full statistic is read from system.table_statistics using the
+// given transaction, so this must be called before the full statistic is
+// deleted by DeleteOldStatsForColumns.
+func MergeExtremesStatistic(
+	ctx context.Context,
+	executor sqlutil.InternalExecutor,
+	txn *kv.Txn,
+	tableID descpb.ID,
+	fullStatisticID uint64,
+	partial *TableStatisticProto,
+) (*TableStatisticProto, error) {
+	row, err := executor.QueryRow(
+		ctx, "get-full-statistic", txn,
+		`SELECT "rowCount", "distinctCount", "nullCount", "avgSize", histogram
+       FROM system.table_statistics
+      WHERE "tableID" = $1 AND "statisticID" = $2`,
+		tableID, fullStatisticID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, errors.Errorf(
+			"full statistic %d for table %d no longer exists", fullStatisticID, tableID,
+		)
+	}
+	full := TableStatisticProto{
+		TableID:       tableID,
+		StatisticID:   fullStatisticID,
+		ColumnIDs:     partial.ColumnIDs,
+		RowCount:      uint64(*row[0].(*tree.DInt)),
+		DistinctCount: uint64(*row[1].(*tree.DInt)),
+		NullCount:     uint64(*row[2].(*tree.DInt)),
+		AvgSize:       uint64(*row[3].(*tree.DInt)),
+	}
+	if row[4] != tree.DNull {
+		full.HistogramData = &HistogramData{}
+		if err := protoutil.Unmarshal([]byte(*row[4].(*tree.DBytes)), full.HistogramData); err != nil {
+			return nil, err
+		}
+	}
+	return mergeExtremesStatistic(&full, partial), nil
+}
+
+// mergeExtremesStatistic merges the partial statistic into the full one. The
+// partial statistic only contains non-NULL values outside the bounds of the
+// full histogram, so its rows and distinct values are added to those of the
+// full statistic, and its histogram buckets are placed before the first or
+// after the last bucket of the full histogram.
+func mergeExtremesStatistic(full, partial *TableStatisticProto) *TableStatisticProto {
+	merged := *full
+	merged.Name = partial.Name
+	merged.StatisticID = 0
+	merged.RowCount = full.RowCount + partial.RowCount
+	merged.DistinctCount = full.DistinctCount + partial.DistinctCount
+	merged.NullCount = full.NullCount + partial.NullCount
+	if merged.RowCount > 0 {
+		merged.AvgSize = uint64(math.Ceil(
+			(float64(full.AvgSize)*float64(full.RowCount) +
+				float64(partial.AvgSize)*float64(partial.RowCount)) / float64(merged.RowCount),
+		))
+	}
+	if full.HistogramData == nil || partial.HistogramData == nil ||
+		len(partial.HistogramData.Buckets) == 0 {
+		return &merged
+	}
+
+	fullBuckets := full.HistogramData.Buckets
+	if len(fullBuckets) == 0 {
+		merged.HistogramData = partial.HistogramData
+		return &merged
+	}
+	lowerBound := fullBuckets[0].UpperBound
+
+	// Histogram upper bounds are encoded in ascending key order, so they can be
+	// compared directly. The partial statistic does not contain values within
+	// the bounds of the full histogram, so every partial bucket is either below
+	// its first bucket or above its last one. Note that the range of the first
+	// partial bucket above the full histogram may include values below it if
+	// the partial statistic found values on both sides; these are attributed to
+	// the upper range, which is a good enough approximation for the optimizer.
+	buckets := make([]HistogramData_Bucket, 0, len(fullBuckets)+len(partial.HistogramData.Buckets))
+	var upper []HistogramData_Bucket
+	for _, b := range partial.HistogramData.Buckets {
+		if bytes.Compare(b.UpperBound, lowerBound) < 0 {
+			buckets = append(buckets, b)
+		} else {
+			upper = append(upper, b)
+		}
+	}
+	buckets = append(buckets, fullBuckets...)
+	buckets = append(buckets, upper...)
+
+	merged.HistogramData = &HistogramData{
+		ColumnType: full.HistogramData.ColumnType,
+		Buckets:    buckets,
+		Version:    full.HistogramData.Version,
+	}
+	return &merged
+}
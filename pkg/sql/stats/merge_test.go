@@ -0,0 +1,90 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/catalog/descpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowenc/keyside"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestMergeExtremesStatistic(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	bucket := func(upper int, numEq, numRange int64) HistogramData_Bucket {
+		enc, err := keyside.Encode(nil, tree.NewDInt(tree.DInt(upper)), encoding.Ascending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return HistogramData_Bucket{NumEq: numEq, NumRange: numRange, UpperBound: enc}
+	}
+	full := &TableStatisticProto{
+		TableID:       100,
+		StatisticID:   1,
+		Name:          "full",
+		ColumnIDs:     []descpb.ColumnID{1},
+		RowCount:      110,
+		DistinctCount: 101,
+		NullCount:     10,
+		AvgSize:       4,
+		HistogramData: &HistogramData{
+			ColumnType: types.Int,
+			Buckets:    []HistogramData_Bucket{bucket(0, 1, 0), bucket(100, 1, 98)},
+		},
+	}
+	partial := &TableStatisticProto{
+		TableID:       100,
+		Name:          "partial",
+		ColumnIDs:     []descpb.ColumnID{1},
+		RowCount:      30,
+		DistinctCount: 30,
+		AvgSize:       8,
+		HistogramData: &HistogramData{
+			ColumnType: types.Int,
+			Buckets: []HistogramData_Bucket{
+				bucket(-10, 1, 0), bucket(-1, 1, 8), bucket(110, 1, 9), bucket(120, 1, 9),
+			},
+		},
+	}
+
+	merged := mergeExtremesStatistic(full, partial)
+	if merged.Name != "partial" || merged.StatisticID != 0 {
+		t.Errorf("unexpected name or ID: %q, %d", merged.Name, merged.StatisticID)
+	}
+	if merged.RowCount != 140 || merged.DistinctCount != 131 || merged.NullCount != 10 {
+		t.Errorf("unexpected counts: %+v", merged)
+	}
+	// (110*4 + 30*8) / 140 = 4.86, rounded up.
+	if merged.AvgSize != 5 {
+		t.Errorf("expected average size 5, got %d", merged.AvgSize)
+	}
+	expected := []HistogramData_Bucket{
+		bucket(-10, 1, 0), bucket(-1, 1, 8), bucket(0, 1, 0), bucket(100, 1, 98),
+		bucket(110, 1, 9), bucket(120, 1, 9),
+	}
+	if !reflect.DeepEqual(merged.HistogramData.Buckets, expected) {
+		t.Errorf("expected buckets %+v, got %+v", expected, merged.HistogramData.Buckets)
+	}
+
+	// Without values outside of the bounds, the full histogram is kept.
+	merged = mergeExtremesStatistic(full, &TableStatisticProto{Name: "partial"})
+	if merged.RowCount != full.RowCount || merged.HistogramData != full.HistogramData {
+		t.Errorf("expected the full statistic to be unchanged, got %+v", merged)
+	}
+}
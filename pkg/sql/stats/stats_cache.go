@@ -648,5 +648,12 @@ ORDER BY "createdAt" DESC
 		return nil, err
 	}
 
+	if UseStatisticsForecasts.Get(&sc.Settings.SV) {
+		// Forecasts are more recent than all collected statistics, so they go
+		// first to be preferred by the optimizer.
+		forecasts := ForecastTableStatistics(ctx, statsList)
+		statsList = append(forecasts, statsList...)
+	}
+
 	return statsList, nil
 }
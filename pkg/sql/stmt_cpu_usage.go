@@ -0,0 +1,255 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/google/pprof/profile"
+)
+
+// The pprof labels attached to the goroutines executing a statement while
+// a CPU profile with labels is taken or continuous CPU profiling is
+// enabled. See execStmt.
+const (
+	appNameProfileLabel           = "appname"
+	stmtExecIDProfileLabel        = "stmt.exec.id"
+	stmtFingerprintIDProfileLabel = "stmt.fingerprint.id"
+	tenantProfileLabel            = "tenant"
+)
+
+// maxStatementCPUUsageEntries is the maximum number of statement
+// fingerprints for which CPU usage is tracked. The CPU usage of other
+// fingerprints is dropped.
+const maxStatementCPUUsageEntries = 10000
+
+// maxFailedStatementExecutions is the maximum number of failed statement
+// executions remembered between two profiles. The CPU usage of the other
+// failed executions is attributed to the fingerprint of the statement as if
+// it had succeeded.
+const maxFailedStatementExecutions = 10000
+
+type stmtCPUUsageKey struct {
+	fingerprintID roachpb.StmtFingerprintID
+	appName       string
+}
+
+// StatementCPUUsage accumulates the CPU time attributed to statement
+// fingerprints in the CPU profiles taken by the continuous CPU profiler.
+// The CPU time is exposed in the cpu_nanos column of
+// crdb_internal.node_statement_statistics and persisted along with the
+// statement statistics when they are flushed, after which it is cleared.
+type StatementCPUUsage struct {
+	// tenantLabel is the value of the tenant label of the statements
+	// executed by this SQL server.
+	tenantLabel string
+	// lastReset returns the last time the in-memory statement statistics
+	// were reset. It is set by NewServer.
+	lastReset func() time.Time
+
+	// continuousProfiling is set to 1 while continuous CPU profiling is
+	// enabled.
+	continuousProfiling int32
+	// lastExecID is used to generate the values of the execution ID label.
+	lastExecID uint64
+
+	mu struct {
+		syncutil.Mutex
+		cpuNanos map[stmtCPUUsageKey]int64
+		// failedExecs maps the execution ID labels of the failed statement
+		// executions since the last profile to the fingerprint ID of the
+		// failed statement.
+		failedExecs map[string]roachpb.StmtFingerprintID
+		// resetAt is the last reset of the statement statistics observed.
+		resetAt time.Time
+	}
+}
+
+// NewStatementCPUUsage creates a StatementCPUUsage for the statements of
+// the given tenant.
+func NewStatementCPUUsage(tenantID roachpb.TenantID) *StatementCPUUsage {
+	u := &StatementCPUUsage{tenantLabel: tenantID.String()}
+	u.mu.cpuNanos = make(map[stmtCPUUsageKey]int64)
+	u.mu.failedExecs = make(map[string]roachpb.StmtFingerprintID)
+	return u
+}
+
+// SetContinuousProfiling implements the heapprofiler.CPUProfileRecorder
+// interface.
+func (u *StatementCPUUsage) SetContinuousProfiling(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&u.continuousProfiling, v)
+}
+
+// continuousProfilingEnabled returns whether statements need to carry their
+// pprof labels for their CPU usage to be attributed.
+func (u *StatementCPUUsage) continuousProfilingEnabled() bool {
+	return u != nil && atomic.LoadInt32(&u.continuousProfiling) == 1
+}
+
+// stmtFingerprintIDProfileLabelValue returns the value of the fingerprint ID
+// label for a statement. This is the fingerprint ID of the statement if it
+// succeeds, which is the one reported in the statement statistics.
+func stmtFingerprintIDProfileLabelValue(
+	stmtNoConstants string, implicitTxn bool, database string,
+) string {
+	id := roachpb.ConstructStatementFingerprintID(
+		stmtNoConstants, false /* failed */, implicitTxn, database)
+	return strconv.FormatUint(uint64(id), 16)
+}
+
+// nextExecIDProfileLabelValue returns the value of the execution ID label
+// for a new statement execution.
+func (u *StatementCPUUsage) nextExecIDProfileLabelValue() string {
+	if u == nil {
+		return ""
+	}
+	return strconv.FormatUint(atomic.AddUint64(&u.lastExecID, 1), 16)
+}
+
+// recordFailedExecution records that the statement execution labeled in
+// ctx failed, so that its CPU usage is attributed to the fingerprint ID of
+// the failed statement rather than to the one in its labels.
+func (u *StatementCPUUsage) recordFailedExecution(
+	ctx context.Context, id roachpb.StmtFingerprintID,
+) {
+	if u == nil {
+		return
+	}
+	execID, ok := pprof.Label(ctx, stmtExecIDProfileLabel)
+	if !ok {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.mu.failedExecs) < maxFailedStatementExecutions {
+		u.mu.failedExecs[execID] = id
+	}
+}
+
+// tenantProfileLabelValue returns the value of the tenant label for the
+// statements executed by this SQL server.
+func (u *StatementCPUUsage) tenantProfileLabelValue() string {
+	if u == nil {
+		return ""
+	}
+	return u.tenantLabel
+}
+
+// RecordProfile implements the heapprofiler.CPUProfileRecorder interface.
+// It attributes the CPU time of the samples of the given CPU profile to the
+// statement fingerprints found in their labels, or to the fingerprint of the
+// failed statement for the executions that failed. Samples without a
+// fingerprint, or of statements of other tenants, are ignored.
+//
+// The failed executions recorded so far are forgotten afterwards, so an
+// execution that fails after the profile was recorded is attributed as if it
+// had succeeded.
+func (u *StatementCPUUsage) RecordProfile(p *profile.Profile) {
+	valueIdx := -1
+	for i, st := range p.SampleType {
+		if st.Type == "cpu" && st.Unit == "nanoseconds" {
+			valueIdx = i
+		}
+	}
+	if valueIdx < 0 {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.maybeResetLocked()
+	defer func() {
+		u.mu.failedExecs = make(map[string]roachpb.StmtFingerprintID)
+	}()
+	for _, s := range p.Sample {
+		if !hasProfileLabel(s, tenantProfileLabel, u.tenantLabel) {
+			continue
+		}
+		ids := s.Label[stmtFingerprintIDProfileLabel]
+		if len(ids) == 0 {
+			continue
+		}
+		id, err := strconv.ParseUint(ids[0], 16, 64)
+		if err != nil {
+			continue
+		}
+		key := stmtCPUUsageKey{fingerprintID: roachpb.StmtFingerprintID(id)}
+		if execIDs := s.Label[stmtExecIDProfileLabel]; len(execIDs) > 0 {
+			if failedID, ok := u.mu.failedExecs[execIDs[0]]; ok {
+				key.fingerprintID = failedID
+			}
+		}
+		if appNames := s.Label[appNameProfileLabel]; len(appNames) > 0 {
+			key.appName = appNames[0]
+		}
+		if _, ok := u.mu.cpuNanos[key]; !ok && len(u.mu.cpuNanos) >= maxStatementCPUUsageEntries {
+			continue
+		}
+		u.mu.cpuNanos[key] += s.Value[valueIdx]
+	}
+}
+
+func hasProfileLabel(s *profile.Sample, key, value string) bool {
+	for _, v := range s.Label[key] {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CPUNanos returns the CPU time attributed to the statements with the
+// given fingerprint ID and application name.
+func (u *StatementCPUUsage) CPUNanos(id roachpb.StmtFingerprintID, appName string) int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.maybeResetLocked()
+	return u.mu.cpuNanos[stmtCPUUsageKey{fingerprintID: id, appName: appName}]
+}
+
+// TakeCPUNanos returns the CPU time attributed to the statements with the
+// given fingerprint ID and application name, and clears it so that it is
+// only persisted once. It is used when the statement statistics are
+// flushed.
+func (u *StatementCPUUsage) TakeCPUNanos(id roachpb.StmtFingerprintID, appName string) int64 {
+	if u == nil {
+		return 0
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.maybeResetLocked()
+	key := stmtCPUUsageKey{fingerprintID: id, appName: appName}
+	cpuNanos := u.mu.cpuNanos[key]
+	delete(u.mu.cpuNanos, key)
+	return cpuNanos
+}
+
+// maybeResetLocked clears the CPU time attributed to the statements if
+// the statement statistics were reset since the last call.
+func (u *StatementCPUUsage) maybeResetLocked() {
+	if u.lastReset == nil {
+		return
+	}
+	if lastReset := u.lastReset(); lastReset.After(u.mu.resetAt) {
+		u.mu.cpuNanos = make(map[stmtCPUUsageKey]int64)
+		u.mu.resetAt = lastReset
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"runtime/pprof"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/google/pprof/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementCPUUsage(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	tenantID := roachpb.MakeTenantID(10)
+	u := NewStatementCPUUsage(tenantID)
+	var lastReset time.Time
+	u.lastReset = func() time.Time { return lastReset }
+
+	const stmt = "SELECT _ FROM t"
+	id := roachpb.ConstructStatementFingerprintID(stmt, false /* failed */, true /* implicitTxn */, "defaultdb")
+	idLabel := stmtFingerprintIDProfileLabelValue(stmt, true /* implicitTxn */, "defaultdb")
+	require.Equal(t, strconv.FormatUint(uint64(id), 16), idLabel)
+
+	sample := func(tenant, fingerprintID, appName string, cpuNanos int64) *profile.Sample {
+		s := &profile.Sample{
+			Value: []int64{1, cpuNanos},
+			Label: map[string][]string{},
+		}
+		if tenant != "" {
+			s.Label[tenantProfileLabel] = []string{tenant}
+		}
+		if fingerprintID != "" {
+			s.Label[stmtFingerprintIDProfileLabel] = []string{fingerprintID}
+		}
+		if appName != "" {
+			s.Label[appNameProfileLabel] = []string{appName}
+		}
+		return s
+	}
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "samples", Unit: "count"},
+			{Type: "cpu", Unit: "nanoseconds"},
+		},
+		Sample: []*profile.Sample{
+			sample("10", idLabel, "app", 100),
+			sample("10", idLabel, "app", 20),
+			sample("10", idLabel, "other", 5),
+			// Samples of other tenants and samples without a statement are
+			// ignored.
+			sample("11", idLabel, "app", 1000),
+			sample("10", "", "app", 1000),
+			sample("", "", "", 1000),
+		},
+	}
+
+	u.RecordProfile(p)
+	require.Equal(t, int64(120), u.CPUNanos(id, "app"))
+	require.Equal(t, int64(5), u.CPUNanos(id, "other"))
+	require.Equal(t, int64(0), u.CPUNanos(id+1, "app"))
+
+	u.RecordProfile(p)
+	require.Equal(t, int64(240), u.CPUNanos(id, "app"))
+
+	// The CPU usage is cleared along with the statement statistics.
+	lastReset = time.Now()
+	require.Equal(t, int64(0), u.CPUNanos(id, "app"))
+	u.RecordProfile(p)
+	require.Equal(t, int64(120), u.CPUNanos(id, "app"))
+
+	// The CPU usage is only persisted once.
+	require.Equal(t, int64(120), u.TakeCPUNanos(id, "app"))
+	require.Equal(t, int64(0), u.TakeCPUNanos(id, "app"))
+
+	// The CPU usage of failed executions is attributed to the fingerprint of
+	// the failed statement.
+	failedID := roachpb.ConstructStatementFingerprintID(stmt, true /* failed */, true /* implicitTxn */, "defaultdb")
+	execID := u.nextExecIDProfileLabelValue()
+	require.NotEqual(t, execID, u.nextExecIDProfileLabelValue())
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels(stmtExecIDProfileLabel, execID))
+	u.recordFailedExecution(ctx, failedID)
+	failedSample := sample("10", idLabel, "app", 7)
+	failedSample.Label[stmtExecIDProfileLabel] = []string{execID}
+	p.Sample = append(p.Sample, failedSample)
+	u.RecordProfile(p)
+	require.Equal(t, int64(120), u.CPUNanos(id, "app"))
+	require.Equal(t, int64(7), u.CPUNanos(failedID, "app"))
+
+	// The failed executions are forgotten after each profile.
+	u.RecordProfile(p)
+	require.Equal(t, int64(247), u.CPUNanos(id, "app"))
+	require.Equal(t, int64(7), u.CPUNanos(failedID, "app"))
+}
+
+func TestStatementCPUUsageContinuousProfiling(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer log.Scope(t).Close(t)
+
+	var nilUsage *StatementCPUUsage
+	require.False(t, nilUsage.continuousProfilingEnabled())
+
+	u := NewStatementCPUUsage(roachpb.SystemTenantID)
+	require.False(t, u.continuousProfilingEnabled())
+	u.SetContinuousProfiling(true)
+	require.True(t, u.continuousProfilingEnabled())
+	u.SetContinuousProfiling(false)
+	require.False(t, u.continuousProfilingEnabled())
+}
@@ -14,6 +14,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"math/rand"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/clusterversion"
@@ -23,9 +24,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/tablepoller"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -70,31 +74,40 @@ type Registry struct {
 		// ids of unconditional requests that this node is in the process of
 		// servicing.
 		ongoing map[RequestID]Request
-
-		// epoch is observed before reading system.statement_diagnostics_requests, and then
-		// checked again before loading the tables contents. If the value changed in
-		// between, then the table contents might be stale.
-		epoch int
+		// planGists contains the plan gist of the last execution observed on this
+		// node for each request that collects bundles on plan changes.
+		planGists map[RequestID]string
 	}
 	st     *cluster.Settings
 	ie     sqlutil.InternalExecutor
 	db     *kv.DB
 	gossip gossip.OptionalGossip
+	poller *tablepoller.Poller
 
-	// gossipUpdateChan is used to notify the polling loop that a diagnostics
-	// request has been added. The gossip callback will not block sending on this
-	// channel.
+	// gossipUpdateChan is used to notify handleGossipNotifications that a
+	// diagnostics request has been added. The gossip callback will not block
+	// sending on this channel.
 	gossipUpdateChan chan RequestID
-	// gossipCancelChan is used to notify the polling loop that a diagnostics
-	// request has been canceled. The gossip callback will not block sending on
-	// this channel.
+	// gossipCancelChan is used to notify handleGossipNotifications that a
+	// diagnostics request has been canceled. The gossip callback will not block
+	// sending on this channel.
 	gossipCancelChan chan RequestID
 }
 
 // Request describes a statement diagnostics request along with some conditional
 // information.
 type Request struct {
-	fingerprint         string
+	fingerprint string
+	// samplingProbability, if non-zero, is the probability with which a
+	// matching execution is traced.
+	samplingProbability float64
+	// numSamples is the number of bundles to collect before the request is
+	// completed. Zero is equivalent to one.
+	numSamples int
+	// onPlanChange, if set, indicates that only the executions whose plan gist
+	// differs from the one of the previous execution on the same node satisfy
+	// the request.
+	onPlanChange        bool
 	minExecutionLatency time.Duration
 	expiresAt           time.Time
 }
@@ -104,7 +117,7 @@ func (r *Request) isExpired(now time.Time) bool {
 }
 
 func (r *Request) isConditional() bool {
-	return r.minExecutionLatency != 0
+	return r.minExecutionLatency != 0 || r.numSamples > 1 || r.onPlanChange
 }
 
 // NewRegistry constructs a new Registry.
@@ -119,6 +132,15 @@ func NewRegistry(
 		gossipCancelChan: make(chan RequestID, 1),
 		st:               st,
 	}
+	r.poller = tablepoller.New(tablepoller.Config{
+		OpName:      "stmt-diag-poll",
+		Description: "statement diagnostics requests",
+		Interval:    pollingInterval,
+		Settings:    st,
+		IE:          ie,
+		Mu:          &r.mu,
+		Poll:        r.pollRequests,
+	})
 	// Some tests pass a nil gossip, and gossip is not available on SQL tenant
 	// servers.
 	g, ok := gw.Optional(47893)
@@ -128,70 +150,36 @@ func NewRegistry(
 	return r
 }
 
-// Start will start the polling loop for the Registry.
+// Start will start the polling loop for the Registry, as well as the loop
+// handling gossip notifications.
 func (r *Registry) Start(ctx context.Context, stopper *stop.Stopper) {
+	r.poller.Start(ctx, stopper)
 	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
 	// NB: The only error that should occur here would be if the server were
 	// shutting down so let's swallow it.
-	_ = stopper.RunAsyncTask(ctx, "stmt-diag-poll", r.poll)
+	_ = stopper.RunAsyncTask(ctx, "stmt-diag-gossip", r.handleGossipNotifications)
 }
 
-func (r *Registry) poll(ctx context.Context) {
-	var (
-		timer               timeutil.Timer
-		lastPoll            time.Time
-		deadline            time.Time
-		pollIntervalChanged = make(chan struct{}, 1)
-		maybeResetTimer     = func() {
-			if interval := pollingInterval.Get(&r.st.SV); interval <= 0 {
-				// Setting the interval to a non-positive value stops the polling.
-				timer.Stop()
-			} else {
-				newDeadline := lastPoll.Add(interval)
-				if deadline.IsZero() || !deadline.Equal(newDeadline) {
-					deadline = newDeadline
-					timer.Reset(timeutil.Until(deadline))
-				}
-			}
-		}
-		poll = func() {
-			if err := r.pollRequests(ctx); err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				log.Warningf(ctx, "error polling for statement diagnostics requests: %s", err)
-			}
-			lastPoll = timeutil.Now()
-		}
-	)
-	pollingInterval.SetOnChange(&r.st.SV, func(ctx context.Context) {
-		select {
-		case pollIntervalChanged <- struct{}{}:
-		default:
-		}
-	})
+// handleGossipNotifications handles the requests and cancellations received
+// by gossipNotification. This happens outside of the gossip callback, which
+// must not block gossip on the registry's lock.
+func (r *Registry) handleGossipNotifications(ctx context.Context) {
 	for {
-		maybeResetTimer()
 		select {
-		case <-pollIntervalChanged:
-			continue // go back around and maybe reset the timer
 		case reqID := <-r.gossipUpdateChan:
 			if r.findRequest(reqID) {
 				continue // request already exists, don't do anything
 			}
 			// Poll the data.
+			r.poller.Notify()
 		case reqID := <-r.gossipCancelChan:
-			r.cancelRequest(reqID)
 			// No need to poll the data (unlike above) because we don't have to
 			// read anything of the system table to remove the request from the
 			// registry.
-			continue
-		case <-timer.C:
-			timer.Read = true
+			r.cancelRequest(reqID)
 		case <-ctx.Done():
 			return
 		}
-		poll()
 	}
 }
 
@@ -200,6 +188,10 @@ func (r *Registry) isMinExecutionLatencySupported(ctx context.Context) bool {
 	return r.st.Version.IsActive(ctx, clusterversion.AlterSystemStmtDiagReqs)
 }
 
+func (r *Registry) isSamplingSupported(ctx context.Context) bool {
+	return r.st.Version.IsActive(ctx, clusterversion.SampledStmtDiagReqs)
+}
+
 // RequestID is the ID of a diagnostics request, corresponding to the id
 // column in statement_diagnostics_requests.
 // A zero ID is invalid.
@@ -211,13 +203,7 @@ type CollectedInstanceID int
 
 // addRequestInternalLocked adds a request to r.mu.requestFingerprints. If the
 // request is already present or it has already expired, the call is a noop.
-func (r *Registry) addRequestInternalLocked(
-	ctx context.Context,
-	id RequestID,
-	queryFingerprint string,
-	minExecutionLatency time.Duration,
-	expiresAt time.Time,
-) {
+func (r *Registry) addRequestInternalLocked(ctx context.Context, id RequestID, req Request) {
 	if r.findRequestLocked(id) {
 		// Request already exists.
 		return
@@ -225,11 +211,14 @@ func (r *Registry) addRequestInternalLocked(
 	if r.mu.requestFingerprints == nil {
 		r.mu.requestFingerprints = make(map[RequestID]Request)
 	}
-	r.mu.requestFingerprints[id] = Request{
-		fingerprint:         queryFingerprint,
-		minExecutionLatency: minExecutionLatency,
-		expiresAt:           expiresAt,
-	}
+	r.mu.requestFingerprints[id] = req
+}
+
+// removeRequestLocked removes the request that is waiting for the right query
+// to come along from the registry.
+func (r *Registry) removeRequestLocked(requestID RequestID) {
+	delete(r.mu.requestFingerprints, requestID)
+	delete(r.mu.planGists, requestID)
 }
 
 func (r *Registry) findRequest(requestID RequestID) bool {
@@ -246,7 +235,7 @@ func (r *Registry) findRequestLocked(requestID RequestID) bool {
 	if ok {
 		if f.isExpired(timeutil.Now()) {
 			// This request has already expired.
-			delete(r.mu.requestFingerprints, requestID)
+			r.removeRequestLocked(requestID)
 		}
 		return true
 	}
@@ -259,7 +248,7 @@ func (r *Registry) findRequestLocked(requestID RequestID) bool {
 func (r *Registry) cancelRequest(requestID RequestID) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	delete(r.mu.requestFingerprints, requestID)
+	r.removeRequestLocked(requestID)
 	delete(r.mu.ongoing, requestID)
 }
 
@@ -267,16 +256,25 @@ func (r *Registry) cancelRequest(requestID RequestID) {
 func (r *Registry) InsertRequest(
 	ctx context.Context,
 	stmtFingerprint string,
+	samplingProbability float64,
+	numSamples int,
+	onPlanChange bool,
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
 ) error {
-	_, err := r.insertRequestInternal(ctx, stmtFingerprint, minExecutionLatency, expiresAfter)
+	_, err := r.insertRequestInternal(
+		ctx, stmtFingerprint, samplingProbability, numSamples, onPlanChange,
+		minExecutionLatency, expiresAfter,
+	)
 	return err
 }
 
 func (r *Registry) insertRequestInternal(
 	ctx context.Context,
 	stmtFingerprint string,
+	samplingProbability float64,
+	numSamples int,
+	onPlanChange bool,
 	minExecutionLatency time.Duration,
 	expiresAfter time.Duration,
 ) (RequestID, error) {
@@ -293,6 +291,24 @@ func (r *Registry) insertRequestInternal(
 			)
 		}
 	}
+	if !r.isSamplingSupported(ctx) {
+		if samplingProbability != 0 || numSamples > 1 || onPlanChange {
+			return 0, errors.New(
+				"sampled statement diagnostics are only supported " +
+					"after the version migrations have completed",
+			)
+		}
+	}
+	if samplingProbability < 0 || samplingProbability > 1 {
+		return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+			"expected sampling probability in range [0.0, 1.0], got %f", samplingProbability,
+		)
+	}
+	if numSamples < 0 {
+		return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+			"expected a non-negative number of samples, got %d", numSamples,
+		)
+	}
 
 	var reqID RequestID
 	var expiresAt time.Time
@@ -325,9 +341,21 @@ func (r *Registry) insertRequestInternal(
 
 		now := timeutil.Now()
 		insertColumns := "statement_fingerprint, requested_at"
-		qargs := make([]interface{}, 2, 4)
+		qargs := make([]interface{}, 2, 7)
 		qargs[0] = stmtFingerprint // statement_fingerprint
 		qargs[1] = now             // requested_at
+		if samplingProbability != 0 {
+			insertColumns += ", sampling_probability"
+			qargs = append(qargs, samplingProbability) // sampling_probability
+		}
+		if numSamples > 1 {
+			insertColumns += ", num_samples"
+			qargs = append(qargs, numSamples) // num_samples
+		}
+		if onPlanChange {
+			insertColumns += ", on_plan_change"
+			qargs = append(qargs, onPlanChange) // on_plan_change
+		}
 		if minExecutionLatency != 0 {
 			insertColumns += ", min_execution_latency"
 			qargs = append(qargs, minExecutionLatency) // min_execution_latency
@@ -365,8 +393,15 @@ func (r *Registry) insertRequestInternal(
 	// pick up the request quickly if the right query comes around, without
 	// waiting for the poller.
 	r.mu.Lock()
-	r.mu.epoch++
-	r.addRequestInternalLocked(ctx, reqID, stmtFingerprint, minExecutionLatency, expiresAt)
+	r.poller.BumpEpochLocked()
+	r.addRequestInternalLocked(ctx, reqID, Request{
+		fingerprint:         stmtFingerprint,
+		samplingProbability: samplingProbability,
+		numSamples:          numSamples,
+		onPlanChange:        onPlanChange,
+		minExecutionLatency: minExecutionLatency,
+		expiresAt:           expiresAt,
+	})
 	r.mu.Unlock()
 
 	// Notify all the other nodes that they have to poll.
@@ -429,13 +464,27 @@ func (r *Registry) CancelRequest(ctx context.Context, requestID int64) error {
 	return nil
 }
 
-// IsExecLatencyConditionMet returns true if the completed request's execution
-// latency satisfies the request's condition. If false is returned, it inlines
-// the logic of RemoveOngoing.
-func (r *Registry) IsExecLatencyConditionMet(
-	requestID RequestID, req Request, execLatency time.Duration,
+// IsConditionMet returns true if the completed request's execution satisfies
+// the request's conditions: its latency is at least the minimum execution
+// latency and, if the request captures plan changes, its plan gist differs from
+// the one of the previous execution of the fingerprint on this node. If false
+// is returned, it inlines the logic of RemoveOngoing.
+func (r *Registry) IsConditionMet(
+	requestID RequestID, req Request, execLatency time.Duration, planGist string,
 ) bool {
-	if req.minExecutionLatency <= execLatency {
+	met := req.minExecutionLatency <= execLatency
+	if req.onPlanChange {
+		r.mu.Lock()
+		prevPlanGist, ok := r.mu.planGists[requestID]
+		if r.mu.planGists == nil {
+			r.mu.planGists = make(map[RequestID]string)
+		}
+		r.mu.planGists[requestID] = planGist
+		r.mu.Unlock()
+		// The first execution observed on this node only serves as the baseline.
+		met = met && ok && prevPlanGist != planGist
+	}
+	if met {
 		return true
 	}
 	// This is a conditional request and the condition is not satisfied, so we
@@ -443,7 +492,7 @@ func (r *Registry) IsExecLatencyConditionMet(
 	if req.isExpired(timeutil.Now()) {
 		r.mu.Lock()
 		defer r.mu.Unlock()
-		delete(r.mu.requestFingerprints, requestID)
+		r.removeRequestLocked(requestID)
 	}
 	return false
 }
@@ -454,7 +503,7 @@ func (r *Registry) RemoveOngoing(requestID RequestID, req Request) {
 	defer r.mu.Unlock()
 	if req.isConditional() {
 		if req.isExpired(timeutil.Now()) {
-			delete(r.mu.requestFingerprints, requestID)
+			r.removeRequestLocked(requestID)
 		}
 	} else {
 		delete(r.mu.ongoing, requestID)
@@ -463,12 +512,13 @@ func (r *Registry) RemoveOngoing(requestID RequestID, req Request) {
 
 // ShouldCollectDiagnostics checks whether any data should be collected for the
 // given query, which is the case if the registry has a request for this
-// statement's fingerprint; in this case ShouldCollectDiagnostics will return
+// statement's fingerprint (and the execution is sampled, if the request has a
+// sampling probability); in this case ShouldCollectDiagnostics will return
 // true again on this node for the same diagnostics request only for conditional
 // requests.
 //
 // If shouldCollect is true, RemoveOngoing needs to be called (which is inlined
-// by IsExecLatencyConditionMet when that returns false).
+// by IsConditionMet when that returns false).
 func (r *Registry) ShouldCollectDiagnostics(
 	ctx context.Context, fingerprint string,
 ) (shouldCollect bool, reqID RequestID, req Request) {
@@ -483,7 +533,11 @@ func (r *Registry) ShouldCollectDiagnostics(
 	for id, f := range r.mu.requestFingerprints {
 		if f.fingerprint == fingerprint {
 			if f.isExpired(timeutil.Now()) {
-				delete(r.mu.requestFingerprints, id)
+				r.removeRequestLocked(id)
+				return false, 0, req
+			}
+			if f.samplingProbability != 0 && rand.Float64() >= f.samplingProbability {
+				// This execution wasn't sampled.
 				return false, 0, req
 			}
 			reqID = id
@@ -511,8 +565,9 @@ func (r *Registry) ShouldCollectDiagnostics(
 // traceJSON is either DNull (when collectionErr should not be nil) or a *DJSON.
 //
 // If requestID is not zero, it also marks the request as completed in
-// system.statement_diagnostics_requests. If requestID is zero, a new entry is
-// inserted.
+// system.statement_diagnostics_requests once all of its samples have been
+// collected. If requestID is zero or more samples are still to be collected
+// for the request, a new completed entry is inserted.
 //
 // collectionErr should be any error generated during the collection or
 // generation of the bundle/trace.
@@ -525,25 +580,34 @@ func (r *Registry) InsertStatementDiagnostics(
 	collectionErr error,
 ) (CollectedInstanceID, error) {
 	var diagID CollectedInstanceID
+	isSamplingSupported := r.isSamplingSupported(ctx)
+	var completed bool
 	err := r.db.Txn(ctx, func(ctx context.Context, txn *kv.Txn) error {
+		completed = false
+		lastSample := requestID != 0
 		if requestID != 0 {
+			query := "SELECT count(1) FROM system.statement_diagnostics_requests WHERE id = $1 AND completed = false"
+			if isSamplingSupported {
+				query = "SELECT IFNULL(max(IFNULL(num_samples, 1) - IFNULL(collected_samples, 0)), 0) " +
+					"FROM system.statement_diagnostics_requests WHERE id = $1 AND completed = false"
+			}
 			row, err := r.ie.QueryRowEx(ctx, "stmt-diag-check-completed", txn,
 				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
-				"SELECT count(1) FROM system.statement_diagnostics_requests WHERE id = $1 AND completed = false",
-				requestID)
+				query, requestID)
 			if err != nil {
 				return err
 			}
 			if row == nil {
 				return errors.New("failed to check completed statement diagnostics")
 			}
-			cnt := int(*row[0].(*tree.DInt))
-			if cnt == 0 {
+			remaining := int(*row[0].(*tree.DInt))
+			if remaining <= 0 {
 				// Someone else already marked the request as completed. We've traced for nothing.
 				// This can only happen once per node, per request since we're going to
 				// remove the request from the registry.
 				return nil
 			}
+			lastSample = remaining == 1
 		}
 
 		// Generate the values that will be inserted.
@@ -600,20 +664,38 @@ func (r *Registry) InsertStatementDiagnostics(
 		}
 		diagID = CollectedInstanceID(*row[0].(*tree.DInt))
 
-		if requestID != 0 {
+		var collectedSamples string
+		if isSamplingSupported {
+			collectedSamples = ", collected_samples = IFNULL(collected_samples, 0) + 1"
+		}
+		if requestID != 0 && !lastSample {
+			// Record the sample; the request stays pending until the last one is
+			// collected.
+			_, err := r.ie.ExecEx(ctx, "stmt-diag-add-sample", txn,
+				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+				"UPDATE system.statement_diagnostics_requests "+
+					"SET collected_samples = IFNULL(collected_samples, 0) + 1 WHERE id = $1",
+				requestID)
+			if err != nil {
+				return err
+			}
+		}
+		if lastSample {
 			// Mark the request from system.statement_diagnostics_request as completed.
 			_, err := r.ie.ExecEx(ctx, "stmt-diag-mark-completed", txn,
 				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
 				"UPDATE system.statement_diagnostics_requests "+
-					"SET completed = true, statement_diagnostics_id = $1 WHERE id = $2",
+					"SET completed = true, statement_diagnostics_id = $1"+collectedSamples+" WHERE id = $2",
 				diagID, requestID)
 			if err != nil {
 				return err
 			}
+			completed = true
 		} else {
 			// Insert a completed request into system.statement_diagnostics_request.
 			// This is necessary because the UI uses this table to discover completed
-			// diagnostics.
+			// diagnostics, including the samples of the requests that are still
+			// pending.
 			_, err := r.ie.ExecEx(ctx, "stmt-diag-add-completed", txn,
 				sessiondata.InternalExecutorOverride{User: security.RootUserName()},
 				"INSERT INTO system.statement_diagnostics_requests"+
@@ -629,53 +711,35 @@ func (r *Registry) InsertStatementDiagnostics(
 	if err != nil {
 		return 0, err
 	}
+	if completed {
+		// Conditional requests are left in the registry until they are satisfied,
+		// so remove the request now rather than waiting for the next poll.
+		r.mu.Lock()
+		r.removeRequestLocked(requestID)
+		r.mu.Unlock()
+	}
 	return diagID, nil
 }
 
 // pollRequests reads the pending rows from system.statement_diagnostics_requests and
 // updates r.mu.requests accordingly.
 func (r *Registry) pollRequests(ctx context.Context) error {
-	var rows []tree.Datums
 	isMinExecutionLatencySupported := r.isMinExecutionLatencySupported(ctx)
-	// Loop until we run the query without straddling an epoch increment.
-	for {
-		r.mu.Lock()
-		epoch := r.mu.epoch
-		r.mu.Unlock()
-
-		var extraColumns string
-		var extraConditions string
-		if isMinExecutionLatencySupported {
-			extraColumns = ", min_execution_latency, expires_at"
-			extraConditions = " AND (expires_at IS NULL OR expires_at > now())"
-		}
-		it, err := r.ie.QueryIteratorEx(ctx, "stmt-diag-poll", nil, /* txn */
-			sessiondata.InternalExecutorOverride{
-				User: security.RootUserName(),
-			},
-			fmt.Sprintf("SELECT id, statement_fingerprint%s FROM system.statement_diagnostics_requests "+
-				"WHERE completed = false%s", extraColumns, extraConditions))
-		if err != nil {
-			return err
-		}
-		rows = rows[:0]
-		var ok bool
-		for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
-			rows = append(rows, it.Cur())
-		}
-		if err != nil {
-			return err
-		}
-
-		r.mu.Lock()
-		// If the epoch changed it means that a request was added to the registry
-		// manually while the query was running. In that case, if we were to process
-		// the query results normally, we might remove that manually-added request.
-		if r.mu.epoch != epoch {
-			r.mu.Unlock()
-			continue
-		}
-		break
+	isSamplingSupported := r.isSamplingSupported(ctx)
+	var extraColumns string
+	var extraConditions string
+	if isMinExecutionLatencySupported {
+		extraColumns = ", min_execution_latency, expires_at"
+		extraConditions = " AND (expires_at IS NULL OR expires_at > now())"
+	}
+	if isSamplingSupported {
+		extraColumns += ", sampling_probability, num_samples, on_plan_change"
+	}
+	rows, err := r.poller.QueryLocked(ctx, fmt.Sprintf(
+		"SELECT id, statement_fingerprint%s FROM system.statement_diagnostics_requests "+
+			"WHERE completed = false%s", extraColumns, extraConditions))
+	if err != nil {
+		return err
 	}
 	defer r.mu.Unlock()
 
@@ -683,25 +747,34 @@ func (r *Registry) pollRequests(ctx context.Context) error {
 	var ids util.FastIntSet
 	for _, row := range rows {
 		id := RequestID(*row[0].(*tree.DInt))
-		stmtFingerprint := string(*row[1].(*tree.DString))
-		var minExecutionLatency time.Duration
-		var expiresAt time.Time
+		req := Request{fingerprint: string(*row[1].(*tree.DString))}
 		if isMinExecutionLatencySupported {
 			if minExecLatency, ok := row[2].(*tree.DInterval); ok {
-				minExecutionLatency = time.Duration(minExecLatency.Nanos())
+				req.minExecutionLatency = time.Duration(minExecLatency.Nanos())
 			}
 			if e, ok := row[3].(*tree.DTimestampTZ); ok {
-				expiresAt = e.Time
+				req.expiresAt = e.Time
+			}
+		}
+		if isSamplingSupported {
+			if prob, ok := row[4].(*tree.DFloat); ok {
+				req.samplingProbability = float64(*prob)
+			}
+			if n, ok := row[5].(*tree.DInt); ok {
+				req.numSamples = int(*n)
+			}
+			if onPlanChange, ok := row[6].(*tree.DBool); ok {
+				req.onPlanChange = bool(*onPlanChange)
 			}
 		}
 		ids.Add(int(id))
-		r.addRequestInternalLocked(ctx, id, stmtFingerprint, minExecutionLatency, expiresAt)
+		r.addRequestInternalLocked(ctx, id, req)
 	}
 
 	// Remove all other requests.
 	for id, req := range r.mu.requestFingerprints {
 		if !ids.Contains(int(id)) || req.isExpired(now) {
-			delete(r.mu.requestFingerprints, id)
+			r.removeRequestLocked(id)
 		}
 	}
 	return nil
@@ -20,7 +20,10 @@ import (
 func (r *Registry) InsertRequestInternal(
 	ctx context.Context, fprint string, minExecutionLatency time.Duration, expiresAfter time.Duration,
 ) (int64, error) {
-	id, err := r.insertRequestInternal(ctx, fprint, minExecutionLatency, expiresAfter)
+	id, err := r.insertRequestInternal(
+		ctx, fprint, 0 /* samplingProbability */, 0 /* numSamples */, false, /* onPlanChange */
+		minExecutionLatency, expiresAfter,
+	)
 	return int64(id), err
 }
 
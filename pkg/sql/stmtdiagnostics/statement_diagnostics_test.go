@@ -287,6 +287,66 @@ func TestDiagnosticsRequest(t *testing.T) {
 			})
 		}
 	})
+
+	// requestBundle requests bundles through the builtin and returns the ID of
+	// the request.
+	requestBundle := func(fprint string, numSamples int, onPlanChange bool) int64 {
+		_, err := db.Exec(
+			"SELECT crdb_internal.request_statement_bundle($1, 0, '0s', '1h', $2, $3)",
+			fprint, numSamples, onPlanChange,
+		)
+		require.NoError(t, err)
+		var reqID int64
+		require.NoError(t, db.QueryRow(
+			"SELECT id FROM system.statement_diagnostics_requests "+
+				"WHERE statement_fingerprint = $1 AND completed = false", fprint,
+		).Scan(&reqID))
+		return reqID
+	}
+	countBundles := func(fprint string) int {
+		var count int
+		require.NoError(t, db.QueryRow(
+			"SELECT count(*) FROM system.statement_diagnostics WHERE statement_fingerprint = $1", fprint,
+		).Scan(&count))
+		return count
+	}
+
+	// Verify that a request for multiple samples is only completed once all of
+	// the bundles are collected.
+	t.Run("multiple samples", func(t *testing.T) {
+		const fprint = "SELECT x FROM test WHERE x < _"
+		reqID := requestBundle(fprint, 2 /* numSamples */, false /* onPlanChange */)
+		_, err := db.Exec("SELECT x FROM test WHERE x < 1")
+		require.NoError(t, err)
+		checkNotCompleted(reqID)
+		require.Equal(t, 1, countBundles(fprint))
+
+		_, err = db.Exec("SELECT x FROM test WHERE x < 2")
+		require.NoError(t, err)
+		checkCompleted(reqID)
+		require.Equal(t, 2, countBundles(fprint))
+	})
+
+	// Verify that a request on plan changes is only satisfied once the plan of
+	// the fingerprint changes.
+	t.Run("plan change", func(t *testing.T) {
+		_, err := db.Exec("CREATE TABLE plan_change (x INT PRIMARY KEY, y INT)")
+		require.NoError(t, err)
+		const fprint = "SELECT x FROM plan_change WHERE y = _"
+		reqID := requestBundle(fprint, 0 /* numSamples */, true /* onPlanChange */)
+		for i := 0; i < 2; i++ {
+			_, err = db.Exec("SELECT x FROM plan_change WHERE y = 1")
+			require.NoError(t, err)
+			checkNotCompleted(reqID)
+		}
+
+		_, err = db.Exec("CREATE INDEX ON plan_change (y)")
+		require.NoError(t, err)
+		_, err = db.Exec("SELECT x FROM plan_change WHERE y = 1")
+		require.NoError(t, err)
+		checkCompleted(reqID)
+		require.Equal(t, 1, countBundles(fprint))
+	})
 }
 
 // Test that a different node can service a diagnostics request.
@@ -0,0 +1,186 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package tablepoller implements the polling loop shared by the registries
+// that keep an in-memory view of a system table on every node, like the
+// statement diagnostics and plan pin registries.
+package tablepoller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// Config configures a Poller.
+type Config struct {
+	// OpName is used as the name of the polling task and of the queries
+	// run by the Poller.
+	OpName string
+	// Description describes what is polled in log messages, e.g.
+	// "statement plan pins".
+	Description string
+	// Interval is the setting controlling the rate at which the table is
+	// polled. Setting it to zero disables polling.
+	Interval *settings.DurationSetting
+	Settings *cluster.Settings
+	IE       sqlutil.InternalExecutor
+	// Mu is the lock protecting the in-memory view refreshed by Poll.
+	Mu sync.Locker
+	// Poll refreshes the in-memory view, usually by reading the table with
+	// QueryLocked.
+	Poll func(ctx context.Context) error
+}
+
+// Poller calls Config.Poll at the interval configured by Config.Interval,
+// and whenever it is notified through Notify.
+//
+// The in-memory view may also be updated manually, e.g. when the node
+// itself writes to the table. To avoid overwriting such an update with the
+// stale results of a query that was running concurrently, the Poller keeps
+// an epoch which must be bumped with every manual update, and QueryLocked
+// retries the query if the epoch changed while it ran.
+type Poller struct {
+	cfg Config
+
+	// epoch is protected by cfg.Mu.
+	epoch int
+
+	// notifyCh is used to request a poll outside of the regular interval.
+	notifyCh chan struct{}
+}
+
+// New constructs a new Poller.
+func New(cfg Config) *Poller {
+	return &Poller{
+		cfg:      cfg,
+		notifyCh: make(chan struct{}, 1),
+	}
+}
+
+// Start will start the polling loop.
+func (p *Poller) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx, _ = stopper.WithCancelOnQuiesce(ctx)
+	// NB: The only error that should occur here would be if the server were
+	// shutting down so let's swallow it.
+	_ = stopper.RunAsyncTask(ctx, p.cfg.OpName, p.run)
+}
+
+// Notify requests a poll as soon as possible. It does not block.
+func (p *Poller) Notify() {
+	select {
+	case p.notifyCh <- struct{}{}:
+	default:
+		// A poll is already pending.
+	}
+}
+
+// BumpEpochLocked must be called, with Config.Mu held, whenever the
+// in-memory view is updated manually.
+func (p *Poller) BumpEpochLocked() {
+	p.epoch++
+}
+
+func (p *Poller) run(ctx context.Context) {
+	var (
+		timer               timeutil.Timer
+		lastPoll            time.Time
+		deadline            time.Time
+		pollIntervalChanged = make(chan struct{}, 1)
+		maybeResetTimer     = func() {
+			if interval := p.cfg.Interval.Get(&p.cfg.Settings.SV); interval <= 0 {
+				// Setting the interval to a non-positive value stops the polling.
+				timer.Stop()
+			} else {
+				newDeadline := lastPoll.Add(interval)
+				if deadline.IsZero() || !deadline.Equal(newDeadline) {
+					deadline = newDeadline
+					timer.Reset(timeutil.Until(deadline))
+				}
+			}
+		}
+	)
+	p.cfg.Interval.SetOnChange(&p.cfg.Settings.SV, func(ctx context.Context) {
+		select {
+		case pollIntervalChanged <- struct{}{}:
+		default:
+		}
+	})
+	for {
+		maybeResetTimer()
+		select {
+		case <-pollIntervalChanged:
+			continue // go back around and maybe reset the timer
+		case <-p.notifyCh:
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return
+		}
+		if err := p.cfg.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warningf(ctx, "error polling for %s: %s", p.cfg.Description, err)
+		}
+		lastPoll = timeutil.Now()
+	}
+}
+
+// QueryLocked runs the given query as root and returns all its rows. It
+// loops until the query runs without straddling an epoch increment. If no
+// error is returned, Config.Mu is held on return and the caller is
+// responsible for releasing it once the rows have been loaded in the
+// in-memory view.
+func (p *Poller) QueryLocked(
+	ctx context.Context, stmt string, qargs ...interface{},
+) ([]tree.Datums, error) {
+	var rows []tree.Datums
+	for {
+		p.cfg.Mu.Lock()
+		epoch := p.epoch
+		p.cfg.Mu.Unlock()
+
+		it, err := p.cfg.IE.QueryIteratorEx(ctx, p.cfg.OpName, nil, /* txn */
+			sessiondata.InternalExecutorOverride{User: security.RootUserName()},
+			stmt, qargs...)
+		if err != nil {
+			return nil, err
+		}
+		rows = rows[:0]
+		var ok bool
+		for ok, err = it.Next(ctx); ok; ok, err = it.Next(ctx) {
+			rows = append(rows, it.Cur())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		p.cfg.Mu.Lock()
+		// If the epoch changed it means that the in-memory view was updated
+		// manually while the query was running. In that case, if we were to
+		// process the query results normally, we might undo that update.
+		if p.epoch != epoch {
+			p.cfg.Mu.Unlock()
+			continue
+		}
+		return rows, nil
+	}
+}
@@ -16,7 +16,23 @@ import "github.com/cockroachdb/cockroach/pkg/clusterversion"
 // data type.
 // Note: please do not remove this map or IsTypeSupportedInVersion even
 // if the map becomes empty temporarily.
-var minimumTypeUsageVersions = map[*T]clusterversion.Key{}
+var minimumTypeUsageVersions = map[*T]clusterversion.Key{
+	TSQuery:   clusterversion.TSearchTypes,
+	TSVector:  clusterversion.TSearchTypes,
+	Int4Range: clusterversion.RangeTypes,
+	Int8Range: clusterversion.RangeTypes,
+	NumRange:  clusterversion.RangeTypes,
+	TSRange:   clusterversion.RangeTypes,
+	TSTZRange: clusterversion.RangeTypes,
+	DateRange: clusterversion.RangeTypes,
+
+	Int4Multirange: clusterversion.RangeTypes,
+	Int8Multirange: clusterversion.RangeTypes,
+	NumMultirange:  clusterversion.RangeTypes,
+	TSMultirange:   clusterversion.RangeTypes,
+	TSTZMultirange: clusterversion.RangeTypes,
+	DateMultirange: clusterversion.RangeTypes,
+}
 
 // IsTypeSupportedInVersion returns whether a given type is supported in the given version.
 func IsTypeSupportedInVersion(v clusterversion.ClusterVersion, t *T) bool {
@@ -32,6 +32,15 @@ func TestIsTypeSupportedInVersion(t *testing.T) {
 	}{
 		{clusterversion.TODOPreV21_2, RegRole, true},
 		{clusterversion.TODOPreV21_2, MakeArray(RegRole), true},
+		{clusterversion.SampledStmtDiagReqs, TSVector, false},
+		{clusterversion.SampledStmtDiagReqs, MakeArray(TSQuery), false},
+		{clusterversion.TSearchTypes, TSVector, true},
+		{clusterversion.TSearchTypes, TSQuery, true},
+		{clusterversion.PartialTableStatistics, TSTZRange, false},
+		{clusterversion.PartialTableStatistics, MakeArray(Int4Range), false},
+		{clusterversion.RangeTypes, DateRange, true},
+		{clusterversion.PartialTableStatistics, Int8Multirange, false},
+		{clusterversion.RangeTypes, TSTZMultirange, true},
 	}
 
 	for _, tc := range testCases {
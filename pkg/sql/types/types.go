@@ -159,6 +159,36 @@ import (
 // When these types are themselves made into arrays, the Oids become T__int2vector and
 // T__oidvector, respectively.
 //
+// Range types
+// -----------
+//
+// The element type of a range type is determined by its Oid (see
+// RangeContents).
+//
+// | SQL type          | Family         | Oid           | Element type  |
+// |-------------------|----------------|---------------|---------------|
+// | INT4RANGE         | RANGE          | T_int4range   | Int4          |
+// | INT8RANGE         | RANGE          | T_int8range   | Int           |
+// | NUMRANGE          | RANGE          | T_numrange    | Decimal       |
+// | TSRANGE           | RANGE          | T_tsrange     | Timestamp     |
+// | TSTZRANGE         | RANGE          | T_tstzrange   | TimestampTZ   |
+// | DATERANGE         | RANGE          | T_daterange   | Date          |
+//
+// Multirange types
+// ----------------
+//
+// The range type of a multirange type is determined by its Oid (see
+// MultirangeContents).
+//
+// | SQL type          | Family         | Oid              | Range type  |
+// |-------------------|----------------|------------------|-------------|
+// | INT4MULTIRANGE    | MULTIRANGE     | T_int4multirange | Int4Range   |
+// | INT8MULTIRANGE    | MULTIRANGE     | T_int8multirange | Int8Range   |
+// | NUMMULTIRANGE     | MULTIRANGE     | T_nummultirange  | NumRange    |
+// | TSMULTIRANGE      | MULTIRANGE     | T_tsmultirange   | TSRange     |
+// | TSTZMULTIRANGE    | MULTIRANGE     | T_tstzmultirange | TSTZRange   |
+// | DATEMULTIRANGE    | MULTIRANGE     | T_datemultirange | DateRange   |
+//
 // User defined types
 // ------------------
 //
@@ -454,6 +484,155 @@ var (
 		},
 	}
 
+	// TSQuery is the type of a full text search query.
+	TSQuery = &T{
+		InternalType: InternalType{
+			Family: TSQueryFamily,
+			Oid:    oid.T_tsquery,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// TSVector is the type of a document normalized for full text search.
+	TSVector = &T{
+		InternalType: InternalType{
+			Family: TSVectorFamily,
+			Oid:    oid.T_tsvector,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// Int4Range is the type of a range of 32-bit integers.
+	Int4Range = &T{
+		InternalType: InternalType{
+			Family: RangeFamily,
+			Oid:    oid.T_int4range,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// Int8Range is the type of a range of 64-bit integers.
+	Int8Range = &T{
+		InternalType: InternalType{
+			Family: RangeFamily,
+			Oid:    oid.T_int8range,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// NumRange is the type of a range of decimals.
+	NumRange = &T{
+		InternalType: InternalType{
+			Family: RangeFamily,
+			Oid:    oid.T_numrange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// TSRange is the type of a range of timestamps without time zone.
+	TSRange = &T{
+		InternalType: InternalType{
+			Family: RangeFamily,
+			Oid:    oid.T_tsrange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// TSTZRange is the type of a range of timestamps with time zone.
+	TSTZRange = &T{
+		InternalType: InternalType{
+			Family: RangeFamily,
+			Oid:    oid.T_tstzrange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// DateRange is the type of a range of dates.
+	DateRange = &T{
+		InternalType: InternalType{
+			Family: RangeFamily,
+			Oid:    oid.T_daterange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// RangeTypes contains all of the range types.
+	RangeTypes = []*T{
+		Int4Range,
+		Int8Range,
+		NumRange,
+		TSRange,
+		TSTZRange,
+		DateRange,
+	}
+
+	// Int4Multirange is the type of a multirange of 32-bit integers.
+	Int4Multirange = &T{
+		InternalType: InternalType{
+			Family: MultirangeFamily,
+			Oid:    oidext.T_int4multirange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// Int8Multirange is the type of a multirange of 64-bit integers.
+	Int8Multirange = &T{
+		InternalType: InternalType{
+			Family: MultirangeFamily,
+			Oid:    oidext.T_int8multirange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// NumMultirange is the type of a multirange of decimals.
+	NumMultirange = &T{
+		InternalType: InternalType{
+			Family: MultirangeFamily,
+			Oid:    oidext.T_nummultirange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// TSMultirange is the type of a multirange of timestamps without time
+	// zone.
+	TSMultirange = &T{
+		InternalType: InternalType{
+			Family: MultirangeFamily,
+			Oid:    oidext.T_tsmultirange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// TSTZMultirange is the type of a multirange of timestamps with time
+	// zone.
+	TSTZMultirange = &T{
+		InternalType: InternalType{
+			Family: MultirangeFamily,
+			Oid:    oidext.T_tstzmultirange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// DateMultirange is the type of a multirange of dates.
+	DateMultirange = &T{
+		InternalType: InternalType{
+			Family: MultirangeFamily,
+			Oid:    oidext.T_datemultirange,
+			Locale: &emptyLocale,
+		},
+	}
+
+	// MultirangeTypes contains all of the multirange types, in the same order
+	// as the corresponding range types in RangeTypes.
+	MultirangeTypes = []*T{
+		Int4Multirange,
+		Int8Multirange,
+		NumMultirange,
+		TSMultirange,
+		TSTZMultirange,
+		DateMultirange,
+	}
+
 	// Void is the type representing void.
 	Void = &T{
 		InternalType: InternalType{
@@ -490,6 +669,8 @@ var (
 		TimeTZ,
 		Jsonb,
 		VarBit,
+		TSQuery,
+		TSVector,
 	}
 
 	// Any is a special type used only during static analysis as a wildcard type
@@ -1314,6 +1495,63 @@ func (t *T) ArrayContents() *T {
 	return t.InternalType.ArrayContents
 }
 
+// RangeContents returns the type of the elements of a range. This is nil for
+// types that are not in the RangeFamily.
+func (t *T) RangeContents() *T {
+	if t.Family() != RangeFamily {
+		return nil
+	}
+	switch t.Oid() {
+	case oid.T_int4range:
+		return Int4
+	case oid.T_int8range:
+		return Int
+	case oid.T_numrange:
+		return Decimal
+	case oid.T_tsrange:
+		return Timestamp
+	case oid.T_tstzrange:
+		return TimestampTZ
+	case oid.T_daterange:
+		return Date
+	}
+	panic(errors.AssertionFailedf("unexpected range OID: %d", t.Oid()))
+}
+
+// MultirangeContents returns the range type of the ranges of a multirange.
+// This is nil for types that are not in the MultirangeFamily.
+func (t *T) MultirangeContents() *T {
+	if t.Family() != MultirangeFamily {
+		return nil
+	}
+	switch t.Oid() {
+	case oidext.T_int4multirange:
+		return Int4Range
+	case oidext.T_int8multirange:
+		return Int8Range
+	case oidext.T_nummultirange:
+		return NumRange
+	case oidext.T_tsmultirange:
+		return TSRange
+	case oidext.T_tstzmultirange:
+		return TSTZRange
+	case oidext.T_datemultirange:
+		return DateRange
+	}
+	panic(errors.AssertionFailedf("unexpected multirange OID: %d", t.Oid()))
+}
+
+// MakeMultirange returns the multirange type whose ranges are of the given
+// range type.
+func MakeMultirange(rangeTyp *T) *T {
+	for i, t := range RangeTypes {
+		if t.Oid() == rangeTyp.Oid() {
+			return MultirangeTypes[i]
+		}
+	}
+	panic(errors.AssertionFailedf("unexpected range type: %s", rangeTyp.SQLString()))
+}
+
 // TupleContents returns a slice containing the type of each tuple field. This
 // is nil for non-TupleFamily types.
 func (t *T) TupleContents() []*T {
@@ -1382,12 +1620,16 @@ var familyNames = map[Family]string{
 	IntFamily:            "int",
 	IntervalFamily:       "interval",
 	JsonFamily:           "jsonb",
+	MultirangeFamily:     "multirange",
 	OidFamily:            "oid",
+	RangeFamily:          "range",
 	StringFamily:         "string",
 	TimeFamily:           "time",
 	TimestampFamily:      "timestamp",
 	TimestampTZFamily:    "timestamptz",
 	TimeTZFamily:         "timetz",
+	TSQueryFamily:        "tsquery",
+	TSVectorFamily:       "tsvector",
 	TupleFamily:          "tuple",
 	UnknownFamily:        "unknown",
 	UuidFamily:           "uuid",
@@ -1457,6 +1699,9 @@ func (t *T) Name() string {
 	case OidFamily:
 		return t.SQLStandardName()
 
+	case RangeFamily, MultirangeFamily:
+		return t.PGName()
+
 	case StringFamily, CollatedStringFamily:
 		switch t.Oid() {
 		case oid.T_text:
@@ -1706,6 +1951,12 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 			return "timestamp with time zone"
 		}
 		return fmt.Sprintf("timestamp(%d) with time zone", typmod)
+	case TSQueryFamily:
+		return "tsquery"
+	case TSVectorFamily:
+		return "tsvector"
+	case RangeFamily, MultirangeFamily:
+		return t.PGName()
 	case TupleFamily:
 		if t.UserDefined() {
 			// If we have a user-defined tuple type, use its user-defined name.
@@ -1901,6 +2152,11 @@ func (t *T) Equivalent(other *T) bool {
 		if t.Oid() != other.Oid() {
 			return false
 		}
+
+	case RangeFamily, MultirangeFamily:
+		if t.Oid() != other.Oid() {
+			return false
+		}
 	}
 
 	return true
@@ -2776,8 +3032,6 @@ var postgresPredefinedTypeIssues = map[string]int{
 	"money":         41578,
 	"path":          21286,
 	"pg_lsn":        -1,
-	"tsquery":       7821,
-	"tsvector":      7821,
 	"txid_snapshot": -1,
 	"xml":           43355,
 }
@@ -34,11 +34,14 @@ var GetReplicationStreamManagerHook func(evalCtx *tree.EvalContext) (Replication
 // ReplicationStreamManager represents a collection of APIs that streaming replication supports.
 type ReplicationStreamManager interface {
 	// CompleteStreamIngestion signals a running stream ingestion job to complete.
+	// If reverseStreamAddress is not empty, the job replicates the tenant back
+	// into the source cluster from that address once it has cut over.
 	CompleteStreamIngestion(
 		evalCtx *tree.EvalContext,
 		txn *kv.Txn,
 		streamID StreamID,
 		cutoverTimestamp hlc.Timestamp,
+		reverseStreamAddress string,
 	) error
 
 	// StartReplicationStream starts a stream replication job for the specified tenant on the producer side.
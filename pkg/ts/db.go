@@ -87,6 +87,32 @@ var Resolution30mStorageTTL = settings.RegisterDurationSetting(
 	resolution30mDefaultPruneThreshold,
 ).WithPublic()
 
+// Resolution1hStorageTTL defines the maximum age of data that will be
+// retained at the 1 hour resolution. If zero, data is not rolled up into
+// this resolution.
+var Resolution1hStorageTTL = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"timeseries.storage.resolution_1h.ttl",
+	"the maximum age of time series data stored at the 1 hour resolution. Data older than "+
+		"timeseries.storage.resolution_30m.ttl is rolled up into this resolution if this is nonzero. "+
+		"Data older than this is subject to rollup and deletion.",
+	0,
+	settings.NonNegativeDuration,
+).WithPublic()
+
+// Resolution1dStorageTTL defines the maximum age of data that will be
+// retained at the 1 day resolution. If zero, data is not rolled up into
+// this resolution.
+var Resolution1dStorageTTL = settings.RegisterDurationSetting(
+	settings.TenantWritable,
+	"timeseries.storage.resolution_1d.ttl",
+	"the maximum age of time series data stored at the 1 day resolution. Data older than "+
+		"the retention of the finer resolutions is rolled up into this resolution if this is nonzero. "+
+		"Data older than this is subject to deletion.",
+	0,
+	settings.NonNegativeDuration,
+).WithPublic()
+
 // DB provides Cockroach's Time Series API.
 type DB struct {
 	db      *kv.DB
@@ -111,6 +137,8 @@ func NewDB(db *kv.DB, settings *cluster.Settings) *DB {
 			return Resolution10sStorageTTL.Get(&settings.SV).Nanoseconds()
 		},
 		Resolution30m:  func() int64 { return Resolution30mStorageTTL.Get(&settings.SV).Nanoseconds() },
+		Resolution1h:   func() int64 { return Resolution1hStorageTTL.Get(&settings.SV).Nanoseconds() },
+		Resolution1d:   func() int64 { return Resolution1dStorageTTL.Get(&settings.SV).Nanoseconds() },
 		resolution1ns:  func() int64 { return resolution1nsDefaultRollupThreshold.Nanoseconds() },
 		resolution50ns: func() int64 { return resolution50nsDefaultPruneThreshold.Nanoseconds() },
 	}
@@ -340,6 +368,30 @@ func (db *DB) PruneThreshold(r Resolution) int64 {
 	return threshold()
 }
 
+// targetRollupResolution returns the resolution that data from the given
+// resolution should be rolled up into in lieu of deletion. In addition to
+// the rollups of Resolution.TargetRollupResolution, data from
+// Resolution30m is rolled up into Resolution1h, and data from Resolution1h
+// into Resolution1d, when the retention of the target resolution is
+// configured. A resolution which is not configured is skipped.
+func (db *DB) targetRollupResolution(r Resolution) (Resolution, bool) {
+	var candidates []Resolution
+	switch r {
+	case Resolution30m:
+		candidates = []Resolution{Resolution1h, Resolution1d}
+	case Resolution1h:
+		candidates = []Resolution{Resolution1d}
+	default:
+		return r.TargetRollupResolution()
+	}
+	for _, target := range candidates {
+		if db.PruneThreshold(target) > 0 {
+			return target, true
+		}
+	}
+	return r, false
+}
+
 // Metrics gets the TimeSeriesMetrics structure used by this DB instance.
 func (db *DB) Metrics() *TimeSeriesMetrics {
 	return db.metrics
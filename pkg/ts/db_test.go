@@ -391,7 +391,7 @@ func (tm *testModelRunner) rollupWithMemoryContext(
 			},
 		)
 		for _, data := range toRecord {
-			targetResolution, _ := ts.Resolution.TargetRollupResolution()
+			targetResolution, _ := tm.DB.targetRollupResolution(ts.Resolution)
 			tm.model.Record(
 				resolutionModelKey(ts.Name, targetResolution),
 				data.source,
@@ -443,7 +443,7 @@ func (tm *testModelRunner) maintain(nowNanos int64) {
 			if !ok {
 				return data, false
 			}
-			targetResolution, hasRollup := res.TargetRollupResolution()
+			targetResolution, hasRollup := tm.DB.targetRollupResolution(res)
 			if hasRollup && tm.DB.WriteRollups() {
 				pruned := data.TimeSlice(thresholds[res], math.MaxInt64)
 				if len(pruned) != len(data) {
@@ -470,11 +470,17 @@ func (db *DB) Query(
 	// Create sourceSet, which tracks unique sources seen while querying.
 	sourceSet := make(map[string]struct{})
 
+	// Older data may have been rolled up into coarser resolutions; query
+	// these first, from the coarsest to the finest, as long as they are
+	// suitable for the requested sample duration.
 	resolutions := []Resolution{diskResolution}
-	if rollupResolution, ok := diskResolution.TargetRollupResolution(); ok {
-		if timespan.verifyDiskResolution(rollupResolution) == nil {
-			resolutions = []Resolution{rollupResolution, diskResolution}
+	for r := diskResolution; ; {
+		rollupResolution, ok := db.targetRollupResolution(r)
+		if !ok || timespan.verifyDiskResolution(rollupResolution) != nil {
+			break
 		}
+		resolutions = append([]Resolution{rollupResolution}, resolutions...)
+		r = rollupResolution
 	}
 
 	for _, resolution := range resolutions {
@@ -881,6 +887,51 @@ func (db *DB) readAllSourcesFromDatabase(
 	return b.Results[0].Rows, nil
 }
 
+// QueryLatest returns the most recent datapoint of every source of the given
+// series recorded at the given resolution between the supplied timestamps. This
+// is intended for consumers which are only interested in the current value of
+// a series, such as the evaluation of alerting rules; sources with no data in
+// the timespan are omitted.
+func (db *DB) QueryLatest(
+	ctx context.Context, seriesName string, diskResolution Resolution, startNanos, endNanos int64,
+) (map[string]tspb.TimeSeriesDatapoint, error) {
+	rows, err := db.readAllSourcesFromDatabase(ctx, seriesName, diskResolution, QueryTimespan{
+		StartNanos: startNanos,
+		EndNanos:   endNanos,
+	})
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]tspb.TimeSeriesDatapoint)
+	for _, row := range rows {
+		_, source, _, _, err := DecodeDataKey(row.Key)
+		if err != nil {
+			return nil, err
+		}
+		var data roachpb.InternalTimeSeriesData
+		if err := row.ValueProto(&data); err != nil {
+			return nil, err
+		}
+		for i := 0; i < data.SampleCount(); i++ {
+			var dp tspb.TimeSeriesDatapoint
+			if data.IsColumnar() {
+				dp.TimestampNanos = data.TimestampForOffset(data.Offset[i])
+				dp.Value = data.Last[i]
+			} else {
+				dp.TimestampNanos = data.TimestampForOffset(data.Samples[i].Offset)
+				dp.Value = data.Samples[i].Sum
+			}
+			if dp.TimestampNanos < startNanos || dp.TimestampNanos > endNanos {
+				continue
+			}
+			if prev, ok := latest[source]; !ok || dp.TimestampNanos > prev.TimestampNanos {
+				latest[source] = dp
+			}
+		}
+	}
+	return latest, nil
+}
+
 // convertKeysToSpans converts a batch of KeyValues queried from disk into a
 // map of data spans organized by source.
 func convertKeysToSpans(
@@ -13,6 +13,7 @@ package ts
 import (
 	"context"
 	"math"
+	"reflect"
 	"runtime"
 	"testing"
 	"time"
@@ -698,3 +699,37 @@ func TestQueryRollup(t *testing.T) {
 		query.assertSuccess(13, 2)
 	}
 }
+
+func TestQueryLatest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	runTestCaseMultipleFormats(t, func(t *testing.T, tm testModelRunner) {
+		tm.storeTimeSeriesData(resolution1ns, []tspb.TimeSeriesData{
+			tsd("metric.test", "source1",
+				tsdp(1, 100),
+				tsdp(15, 500),
+				tsdp(22, 200),
+			),
+			tsd("metric.test", "source2",
+				tsdp(7, 700),
+				tsdp(9, 900),
+			),
+			tsd("metric.test", "source3",
+				tsdp(45, 500),
+			),
+		})
+		tm.assertKeyCount(5)
+		tm.assertModelCorrect()
+
+		latest, err := tm.DB.QueryLatest(context.Background(), "metric.test", resolution1ns, 5, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]tspb.TimeSeriesDatapoint{
+			"source1": tsdp(22, 200),
+			"source2": tsdp(9, 900),
+		}
+		if !reflect.DeepEqual(latest, expected) {
+			t.Fatalf("got %v, expected %v", latest, expected)
+		}
+	})
+}
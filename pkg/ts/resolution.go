@@ -27,6 +27,10 @@ func (r Resolution) String() string {
 		return "10s"
 	case Resolution30m:
 		return "30m"
+	case Resolution1h:
+		return "1h"
+	case Resolution1d:
+		return "1d"
 	case resolution1ns:
 		return "1ns"
 	case resolution50ns:
@@ -46,6 +50,14 @@ const (
 	// Resolution30m stores roll-up data from a higher resolution at a sample
 	// resolution of 30 minutes.
 	Resolution30m Resolution = 2
+	// Resolution1h stores roll-up data from a higher resolution at a sample
+	// resolution of 1 hour. Data is only rolled up into this resolution if
+	// its retention is configured.
+	Resolution1h Resolution = 3
+	// Resolution1d stores roll-up data from a higher resolution at a sample
+	// resolution of 1 day. Data is only rolled up into this resolution if
+	// its retention is configured.
+	Resolution1d Resolution = 4
 	// resolution1ns stores data with a sample resolution of 1 nanosecond. Used
 	// only for testing.
 	resolution1ns Resolution = 998
@@ -64,6 +76,8 @@ const (
 var sampleDurationByResolution = map[Resolution]int64{
 	Resolution10s:     int64(time.Second * 10),
 	Resolution30m:     int64(time.Minute * 30),
+	Resolution1h:      int64(time.Hour),
+	Resolution1d:      int64(time.Hour * 24),
 	resolution1ns:     1,  // 1ns resolution only for tests.
 	resolution50ns:    50, // 50ns rollup only for tests.
 	resolutionInvalid: 10, // Invalid resolution.
@@ -76,6 +90,8 @@ var sampleDurationByResolution = map[Resolution]int64{
 var slabDurationByResolution = map[Resolution]int64{
 	Resolution10s:     int64(time.Hour),
 	Resolution30m:     int64(time.Hour * 24),
+	Resolution1h:      int64(time.Hour * 24 * 7),
+	Resolution1d:      int64(time.Hour * 24 * 180),
 	resolution1ns:     10,   // 1ns resolution only for tests.
 	resolution50ns:    1000, // 50ns rollup only for tests.
 	resolutionInvalid: 11,
@@ -106,12 +122,14 @@ func (r Resolution) SlabDuration() int64 {
 // values about a large number of samples taken over a long period, such as
 // the min, max and sum.
 func (r Resolution) IsRollup() bool {
-	return r == Resolution30m || r == resolution50ns
+	return r == Resolution30m || r == Resolution1h || r == Resolution1d || r == resolution50ns
 }
 
 // TargetRollupResolution returns a target resolution that data from this
 // resolution should be rolled up into in lieu of deletion. For example,
-// Resolution10s has a target rollup resolution of Resolution30m.
+// Resolution10s has a target rollup resolution of Resolution30m. The
+// optional rollups into Resolution1h and Resolution1d depend on the cluster
+// settings, and are determined by DB.targetRollupResolution.
 func (r Resolution) TargetRollupResolution() (Resolution, bool) {
 	switch r {
 	case Resolution10s:
@@ -138,6 +156,10 @@ func ResolutionFromProto(r tspb.TimeSeriesResolution) Resolution {
 		return Resolution10s
 	case tspb.TimeSeriesResolution_RESOLUTION_30M:
 		return Resolution30m
+	case tspb.TimeSeriesResolution_RESOLUTION_1H:
+		return Resolution1h
+	case tspb.TimeSeriesResolution_RESOLUTION_1D:
+		return Resolution1d
 	default:
 	}
 	return resolutionInvalid
@@ -143,7 +143,7 @@ func (db *DB) rollupTimeSeries(
 	thresholds := db.computeThresholds(now.WallTime)
 	for _, timeSeries := range timeSeriesList {
 		// Only process rollup if this resolution has a target rollup resolution.
-		targetResolution, hasRollup := timeSeries.Resolution.TargetRollupResolution()
+		targetResolution, hasRollup := db.targetRollupResolution(timeSeries.Resolution)
 		if !hasRollup {
 			continue
 		}
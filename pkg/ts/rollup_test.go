@@ -353,3 +353,55 @@ func TestRollupMemoryConstraint(t *testing.T) {
 		}
 	}
 }
+
+func TestTargetRollupResolution(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	db := NewDB(nil /* db */, st)
+
+	for _, tc := range []struct {
+		ttl1h, ttl1d time.Duration
+		expected     map[Resolution]Resolution
+	}{
+		{
+			expected: map[Resolution]Resolution{Resolution10s: Resolution30m},
+		},
+		{
+			ttl1h: 24 * time.Hour * 365,
+			expected: map[Resolution]Resolution{
+				Resolution10s: Resolution30m,
+				Resolution30m: Resolution1h,
+			},
+		},
+		{
+			ttl1d: 24 * time.Hour * 365 * 5,
+			expected: map[Resolution]Resolution{
+				Resolution10s: Resolution30m,
+				Resolution30m: Resolution1d,
+			},
+		},
+		{
+			ttl1h: 24 * time.Hour * 365,
+			ttl1d: 24 * time.Hour * 365 * 5,
+			expected: map[Resolution]Resolution{
+				Resolution10s: Resolution30m,
+				Resolution30m: Resolution1h,
+				Resolution1h:  Resolution1d,
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("1h=%s,1d=%s", tc.ttl1h, tc.ttl1d), func(t *testing.T) {
+			Resolution1hStorageTTL.Override(ctx, &st.SV, tc.ttl1h)
+			Resolution1dStorageTTL.Override(ctx, &st.SV, tc.ttl1d)
+			for _, r := range []Resolution{Resolution10s, Resolution30m, Resolution1h, Resolution1d} {
+				target, ok := db.targetRollupResolution(r)
+				expected, expectedOK := tc.expected[r]
+				if ok != expectedOK || (ok && target != expected) {
+					t.Errorf("%s: expected rollup into %s (%t), got %s (%t)",
+						r, expected, expectedOK, target, ok)
+				}
+			}
+		})
+	}
+}
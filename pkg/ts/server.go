@@ -316,9 +316,11 @@ func (s *Server) Query(
 }
 
 // Dump returns a stream of raw timeseries data that has been stored on the
-// server. Only data from the 10-second resolution is returned; rollup data is
-// not currently returned. Data is returned in the order it is read from disk,
-// and will thus not be totally organized by series.
+// server. By default, only data from the 10-second resolution is returned;
+// rollup data is returned for the resolutions listed in the request, with
+// the average of each rolled up sample as its value. Data is returned in the
+// order it is read from disk, and will thus not be totally organized by
+// series.
 //
 // TODO(tbg): needs testing that restricting to individual timeseries works
 // and that the date range restrictions are respected. Should be easy enough to
@@ -385,7 +387,10 @@ func (dd defaultDumper) Dump(kv *roachpb.KeyValue) error {
 		Datapoints: make([]tspb.TimeSeriesDatapoint, idata.SampleCount()),
 	}
 	for i := 0; i < idata.SampleCount(); i++ {
-		if idata.IsColumnar() {
+		if idata.IsRollup() {
+			tsdata.Datapoints[i].TimestampNanos = idata.TimestampForOffset(idata.Offset[i])
+			tsdata.Datapoints[i].Value = idata.Sum[i] / float64(idata.Count[i])
+		} else if idata.IsColumnar() {
 			tsdata.Datapoints[i].TimestampNanos = idata.TimestampForOffset(idata.Offset[i])
 			tsdata.Datapoints[i].Value = idata.Last[i]
 		} else {
@@ -20,6 +20,12 @@ import (
 
 // StructuredEvent emits a structured event to the debug log.
 func StructuredEvent(ctx context.Context, event eventpb.EventPayload) {
+	StructuredEventOnChannel(ctx, event.LoggingChannel(), event)
+}
+
+// StructuredEventOnChannel is like StructuredEvent, but emits the event
+// on the given channel instead of the channel of the event's category.
+func StructuredEventOnChannel(ctx context.Context, ch Channel, event eventpb.EventPayload) {
 	// Populate the missing common fields.
 	common := event.CommonDetails()
 	if common.Timestamp == 0 {
@@ -31,7 +37,7 @@ func StructuredEvent(ctx context.Context, event eventpb.EventPayload) {
 
 	entry := makeStructuredEntry(ctx,
 		severity.INFO,
-		event.LoggingChannel(),
+		ch,
 		// Note: we use depth 0 intentionally here, so that structured
 		// events can be reliably detected (their source filename will
 		// always be log/event_log.go).
@@ -323,6 +323,36 @@ func ApplyConfig(config logconfig.Config) (resFn func(), err error) {
 		attachSinkInfo(httpSinkInfo, &fc.Channels)
 	}
 
+	// Create the syslog sinks.
+	for _, fc := range config.Sinks.SyslogServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		syslogSinkInfo, err := newSyslogSinkInfo(*fc)
+		if err != nil {
+			return nil, err
+		}
+		attachBufferWrapper(secLoggersCtx, syslogSinkInfo, fc.CommonSinkConfig)
+		attachSinkInfo(syslogSinkInfo, &fc.Channels)
+	}
+
+	// Create the OTLP sinks.
+	for _, fc := range config.Sinks.OTLPServers {
+		if fc.Filter == severity.NONE {
+			continue
+		}
+		otlpSinkInfo, otlpSink, err := newOTLPSinkInfo(*fc)
+		if err != nil {
+			return nil, err
+		}
+		attachBufferWrapper(secLoggersCtx, otlpSinkInfo, fc.CommonSinkConfig)
+		attachSinkInfo(otlpSinkInfo, &fc.Channels)
+
+		// Close the connection to the collector when the configuration
+		// is torn down.
+		go otlpSink.closeWhenDone(secLoggersCtx)
+	}
+
 	// Prepend the interceptor sink to all channels.
 	// We prepend it because we want the interceptors
 	// to see every event before they make their way to disk/network.
@@ -392,6 +422,42 @@ func newHTTPSinkInfo(c logconfig.HTTPSinkConfig) (*sinkInfo, error) {
 	return info, nil
 }
 
+// newSyslogSinkInfo creates a new syslogSink and its accompanying
+// sinkInfo from the provided configuration.
+func newSyslogSinkInfo(c logconfig.SyslogSinkConfig) (*sinkInfo, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, err
+	}
+	info.applyFilters(c.Channels)
+	info.formatter = newFormatSyslog(info.formatter, c.Facility.Code(), *c.AppName)
+	info.sink = newSyslogSink(c.Net, c.Address, syslogSinkOptions{
+		unsafeTLS: *c.UnsafeTLS,
+		timeout:   *c.Timeout,
+	})
+	return info, nil
+}
+
+// newOTLPSinkInfo creates a new otlpSink and its accompanying sinkInfo
+// from the provided configuration.
+func newOTLPSinkInfo(c logconfig.OTLPSinkConfig) (*sinkInfo, *otlpSink, error) {
+	info := &sinkInfo{}
+	if err := info.applyConfig(c.CommonSinkConfig); err != nil {
+		return nil, nil, err
+	}
+	info.applyFilters(c.Channels)
+	info.formatter = formatOTLP{inner: info.formatter}
+	otlpSink, err := newOTLPSink(*c.Address, otlpSinkOptions{
+		insecure: *c.Insecure,
+		timeout:  *c.Timeout,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	info.sink = otlpSink
+	return info, otlpSink, nil
+}
+
 // applyFilters applies the channel filters to a sinkInfo.
 func (l *sinkInfo) applyFilters(chs logconfig.ChannelFilters) {
 	for ch, threshold := range chs.ChannelFilters {
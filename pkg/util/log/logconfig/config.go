@@ -41,6 +41,14 @@ const DefaultFluentFormat = `json-fluent-compact`
 // when not specified in a configuration.
 const DefaultHTTPFormat = `json-compact`
 
+// DefaultSyslogFormat is the entry format for syslog sinks
+// when not specified in a configuration.
+const DefaultSyslogFormat = `json-compact`
+
+// DefaultOTLPFormat is the entry format for OTLP sinks
+// when not specified in a configuration.
+const DefaultOTLPFormat = `json-compact`
+
 // DefaultConfig returns a suitable default configuration when logging
 // is meant to primarily go to files.
 func DefaultConfig() (c Config) {
@@ -113,6 +121,16 @@ type Config struct {
 	// configuration value.
 	HTTPDefaults HTTPDefaults `yaml:"http-defaults,omitempty"`
 
+	// SyslogDefaults represents the default configuration for syslog
+	// sinks, inherited when a specific syslog sink config does not
+	// provide a configuration value.
+	SyslogDefaults SyslogDefaults `yaml:"syslog-defaults,omitempty"`
+
+	// OTLPDefaults represents the default configuration for OTLP sinks,
+	// inherited when a specific OTLP sink config does not provide a
+	// configuration value.
+	OTLPDefaults OTLPDefaults `yaml:"otlp-defaults,omitempty"`
+
 	// Sinks represents the sink configurations.
 	Sinks SinkConfig `yaml:",omitempty"`
 
@@ -224,6 +242,10 @@ type SinkConfig struct {
 	FluentServers map[string]*FluentSinkConfig `yaml:"fluent-servers,omitempty"`
 	// HTTPServers represents the list of configured http sinks.
 	HTTPServers map[string]*HTTPSinkConfig `yaml:"http-servers,omitempty"`
+	// SyslogServers represents the list of configured syslog sinks.
+	SyslogServers map[string]*SyslogSinkConfig `yaml:"syslog-servers,omitempty"`
+	// OTLPServers represents the list of configured OTLP sinks.
+	OTLPServers map[string]*OTLPSinkConfig `yaml:"otlp-servers,omitempty"`
 	// Stderr represents the configuration for the stderr sink.
 	Stderr StderrSinkConfig `yaml:",omitempty"`
 }
@@ -533,6 +555,191 @@ type HTTPSinkConfig struct {
 	sinkName string
 }
 
+// SyslogDefaults represents the configuration defaults for syslog sinks.
+type SyslogDefaults struct {
+	// Facility is the syslog facility reported for the logging events,
+	// e.g. "user", "daemon" or "local0" through "local7". Defaults to
+	// "local0".
+	Facility *SyslogFacility `yaml:",omitempty"`
+
+	// AppName is the APP-NAME reported for the logging events.
+	// Defaults to "cockroach".
+	AppName *string `yaml:"app-name,omitempty"`
+
+	// UnsafeTLS enables certificate authentication to be bypassed
+	// for the "tls" protocol. Defaults to false.
+	UnsafeTLS *bool `yaml:"unsafe-tls,omitempty"`
+
+	// Timeout is the timeout for establishing a connection to the
+	// syslog server and for writing to it. Defaults to 5s.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// SyslogSinkConfig represents the configuration for one syslog sink.
+//
+// User-facing documentation follows.
+// TITLE: Output to syslog servers
+//
+// This sink type causes logging data to be sent over the network to
+// a syslog server, as messages in the
+// [RFC 5424](https://datatracker.ietf.org/doc/html/rfc5424) format.
+//
+// The severity of each logging event is mapped to the syslog severity,
+// and the name of its channel is reported as the MSGID. The formatted
+// event, using the configured `format`, is the MSG part of the syslog
+// message.
+//
+// Messages are sent as UDP datagrams with the `udp` protocol (the
+// default), and using the octet-counting framing of
+// [RFC 6587](https://datatracker.ietf.org/doc/html/rfc6587) with the
+// `tcp` and `tls` protocols.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `syslog-servers`. Example configuration:
+//
+//     sinks:
+//        syslog-servers:        # syslog configurations start here
+//           health:             # defines one sink called "health"
+//              channels: HEALTH
+//              net: tls
+//              address: syslog.example.com:6514
+//
+// Every new server sink configured automatically inherits the configurations set in the `syslog-defaults` section.
+//
+// For example:
+//
+//      syslog-defaults:
+//          facility: local3 # default: report events on the local3 facility
+//      sinks:
+//        syslog-servers:
+//          health:
+//             channels: HEALTH
+//             address: 127.0.0.1:514
+//             # This sink uses the local3 facility,
+//             # as the setting is inherited from syslog-defaults
+//             # unless overridden here.
+//
+// The default output format for syslog sinks is
+// `json-compact`. [Other supported formats.](log-formats.html)
+//
+// {{site.data.alerts.callout_info}}
+// Run `cockroach debug check-log-config` to verify the effect of defaults inheritance.
+// {{site.data.alerts.end}}
+//
+type SyslogSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelFilters `yaml:",omitempty,flow"`
+
+	// Net is the protocol for the syslog server. Can be "udp", "tcp"
+	// or "tls". Defaults to "udp".
+	Net string `yaml:",omitempty"`
+
+	// Address is the network address of the syslog server. The
+	// host/address and port parts are separated with a colon. IPv6
+	// numeric addresses should be included within square brackets,
+	// e.g.: [::1]:1234.
+	Address string `yaml:""`
+
+	// SyslogDefaults contains the defaultable fields of the config.
+	SyslogDefaults `yaml:",inline"`
+
+	// sinkName is populated during validation.
+	sinkName string
+}
+
+// OTLPDefaults represents the configuration defaults for OTLP sinks.
+type OTLPDefaults struct {
+	// Address is the network address of the OTLP/gRPC endpoint of the
+	// collector. The host/address and port parts are separated with a
+	// colon. IPv6 numeric addresses should be included within square
+	// brackets, e.g.: [::1]:4317.
+	Address *string `yaml:",omitempty"`
+
+	// Insecure disables TLS on the connection to the collector.
+	// Defaults to false.
+	Insecure *bool `yaml:",omitempty"`
+
+	// Timeout is the timeout for exporting a batch of log records to
+	// the collector. Defaults to 10s.
+	Timeout *time.Duration `yaml:",omitempty"`
+
+	// CommonSinkConfig is the configuration common to all sinks. Note
+	// that although the idiom in Go is to place embedded fields at the
+	// beginning of a struct, we purposefully deviate from the idiom
+	// here to ensure that "general" options appear after the
+	// sink-specific options in YAML config dumps.
+	CommonSinkConfig `yaml:",inline"`
+}
+
+// OTLPSinkConfig represents the configuration for one OTLP sink.
+//
+// User-facing documentation follows.
+// TITLE: Output to OpenTelemetry collectors
+//
+// This sink type causes logging data to be exported as log records to
+// an [OpenTelemetry](https://opentelemetry.io) collector, using the
+// OTLP/gRPC protocol.
+//
+// The body of each log record is the formatted event, using the
+// configured `format`. The timestamp and severity of the event are
+// mapped to the corresponding fields of the log record, and its
+// channel, source location, goroutine and logging tags are reported
+// as attributes, so that they can be used by the collector without
+// parsing the body. The logging tags are reported as attributes
+// prefixed with `tag.`.
+//
+// Unlike other sink types, OTLP sinks are buffered by default, so that
+// log records are exported in batches: a batch is exported at least
+// every 5 seconds, or when it reaches 1MiB.
+//
+// The configuration key under the `sinks` key in the YAML
+// configuration is `otlp-servers`. Example configuration:
+//
+//     sinks:
+//        otlp-servers:          # OTLP configurations start here
+//           health:             # defines one sink called "health"
+//              channels: HEALTH
+//              address: otel-collector:4317
+//
+// Every new server sink configured automatically inherits the configurations set in the `otlp-defaults` section.
+//
+// For example:
+//
+//      otlp-defaults:
+//          address: otel-collector:4317
+//      sinks:
+//        otlp-servers:
+//          health:
+//             channels: HEALTH
+//             # This sink exports to otel-collector:4317,
+//             # as the setting is inherited from otlp-defaults
+//             # unless overridden here.
+//
+// The default output format for OTLP sinks is
+// `json-compact`. [Other supported formats.](log-formats.html)
+//
+// {{site.data.alerts.callout_info}}
+// Run `cockroach debug check-log-config` to verify the effect of defaults inheritance.
+// {{site.data.alerts.end}}
+//
+type OTLPSinkConfig struct {
+	// Channels is the list of logging channels that use this sink.
+	Channels ChannelFilters `yaml:",omitempty,flow"`
+
+	// OTLPDefaults contains the defaultable fields of the config.
+	OTLPDefaults `yaml:",inline"`
+
+	// sinkName is populated during validation.
+	sinkName string
+}
+
 // IterateDirectories calls the provided fn on every directory linked to
 // by the configuration.
 func (c *Config) IterateDirectories(fn func(d string) error) error {
@@ -1107,6 +1314,56 @@ func (hsm *HTTPSinkMethod) UnmarshalYAML(fn func(interface{}) error) error {
 	return unmarshalYAMLConstrainedString(hsm, fn)
 }
 
+// SyslogFacility is a string restricted to the syslog facility keywords
+// from RFC 5424, e.g. "user" or "local0".
+type SyslogFacility string
+
+// syslogFacilities lists the syslog facility keywords, in the order of
+// their numerical codes.
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var _ constrainedString = (*SyslogFacility)(nil)
+
+// Accept implements the constrainedString interface.
+func (f *SyslogFacility) Accept(s string) {
+	*f = SyslogFacility(s)
+}
+
+// Canonicalize implements the constrainedString interface.
+func (SyslogFacility) Canonicalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// AllowedSet implements the constrainedString interface.
+func (SyslogFacility) AllowedSet() []string {
+	return syslogFacilities
+}
+
+// Code returns the numerical code of the facility, as used in the PRI
+// part of syslog messages.
+func (f SyslogFacility) Code() int {
+	for i, name := range syslogFacilities {
+		if string(f) == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// MarshalYAML implements yaml.Marshaler interface.
+func (f SyslogFacility) MarshalYAML() (interface{}, error) {
+	return string(f), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (f *SyslogFacility) UnmarshalYAML(fn func(interface{}) error) error {
+	return unmarshalYAMLConstrainedString(f, fn)
+}
+
 // constrainedString is an interface to make it easy to unmarshal
 // a string constrained to a small set of accepted values.
 type constrainedString interface {
@@ -0,0 +1,224 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/errors"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpSink exports logging events as log records to an OpenTelemetry
+// collector, over OTLP/gRPC.
+//
+// The log records are produced by the formatOTLP formatter, which
+// wraps the format configured for the sink. Each formatted entry is an
+// octet-counted frame containing an encoded LogRecord, so that the
+// records accumulated by a bufferSink are exported in a single
+// request.
+type otlpSink struct {
+	address  string
+	timeout  time.Duration
+	conn     *grpc.ClientConn
+	client   collogspb.LogsServiceClient
+	resource *resourcepb.Resource
+}
+
+type otlpSinkOptions struct {
+	insecure bool
+	timeout  time.Duration
+}
+
+func newOTLPSink(address string, opts otlpSinkOptions) (*otlpSink, error) {
+	transport := grpc.WithInsecure()
+	if !opts.insecure {
+		transport = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+	// The connection is established in the background, and
+	// re-established as needed by the gRPC client.
+	conn, err := grpc.Dial(address, transport)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to OTLP collector %s", address)
+	}
+	attrs := []*commonpb.KeyValue{otlpStringAttribute("service.name", "cockroach")}
+	if hostname, err := os.Hostname(); err == nil {
+		attrs = append(attrs, otlpStringAttribute("host.name", hostname))
+	}
+	return &otlpSink{
+		address:  address,
+		timeout:  opts.timeout,
+		conn:     conn,
+		client:   collogspb.NewLogsServiceClient(conn),
+		resource: &resourcepb.Resource{Attributes: attrs},
+	}, nil
+}
+
+// closeWhenDone closes the connection to the collector when the
+// context is canceled.
+func (s *otlpSink) closeWhenDone(ctx context.Context) {
+	<-ctx.Done()
+	if err := s.conn.Close(); err != nil {
+		fmt.Fprintf(OrigStderr, "error closing connection to OTLP collector %s: %v\n", s.address, err)
+	}
+}
+
+// active implements the logSink interface.
+func (*otlpSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (*otlpSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (*otlpSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+func (s *otlpSink) output(b []byte, _ sinkOutputOptions) error {
+	logs := &logspb.InstrumentationLibraryLogs{
+		InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+			Name: "github.com/cockroachdb/cockroach/pkg/util/log",
+		},
+	}
+	if err := forEachOctetCountedFrame(b, func(_, msg []byte) error {
+		r := &logspb.LogRecord{}
+		if err := proto.Unmarshal(msg, r); err != nil {
+			return errors.NewAssertionErrorWithWrappedErrf(err, "decoding log record")
+		}
+		logs.Logs = append(logs.Logs, r)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	_, err := s.client.Export(ctx, &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource:                   s.resource,
+			InstrumentationLibraryLogs: []*logspb.InstrumentationLibraryLogs{logs},
+		}},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "exporting logs to OTLP collector %s", s.address)
+	}
+	return nil
+}
+
+// formatOTLP wraps the format configured for an OTLP sink to produce
+// log records, whose body is the entry formatted by the wrapped
+// format.
+type formatOTLP struct {
+	inner logFormatter
+}
+
+func (f formatOTLP) formatterName() string { return f.inner.formatterName() }
+
+func (f formatOTLP) doc() string { return f.inner.doc() }
+
+// otlpSeverities maps the logging severities to the OpenTelemetry
+// severity numbers.
+var otlpSeverities = map[logpb.Severity]logspb.SeverityNumber{
+	logpb.Severity_INFO:    logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+	logpb.Severity_WARNING: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+	logpb.Severity_ERROR:   logspb.SeverityNumber_SEVERITY_NUMBER_ERROR,
+	logpb.Severity_FATAL:   logspb.SeverityNumber_SEVERITY_NUMBER_FATAL,
+}
+
+func (f formatOTLP) formatEntry(entry logEntry) *buffer {
+	formatted := f.inner.formatEntry(entry)
+	r := &logspb.LogRecord{
+		TimeUnixNano: uint64(entry.ts),
+		Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{
+			StringValue: string(bytes.TrimRight(formatted.Bytes(), "\n")),
+		}},
+	}
+	putBuffer(formatted)
+
+	if !entry.header {
+		r.SeverityNumber = otlpSeverities[entry.sev]
+		r.SeverityText = entry.sev.String()
+		r.Attributes = append(r.Attributes, otlpStringAttribute("channel", entry.ch.String()))
+	}
+	if entry.file != "" {
+		r.Attributes = append(r.Attributes,
+			otlpStringAttribute("file", entry.file),
+			otlpIntAttribute("line", int64(entry.line)))
+	}
+	r.Attributes = append(r.Attributes, otlpIntAttribute("goroutine", entry.gid))
+	for _, id := range []struct{ key, val string }{
+		{"cluster_id", entry.clusterID},
+		{"node_id", entry.nodeID},
+		{"tenant_id", entry.tenantID},
+		{"instance_id", entry.sqlInstanceID},
+	} {
+		if id.val != "" {
+			r.Attributes = append(r.Attributes, otlpStringAttribute(id.key, id.val))
+		}
+	}
+	it := formattableTagsIterator{tags: entry.payload.tags}
+	for {
+		key, val, done := it.next()
+		if done {
+			break
+		}
+		r.Attributes = append(r.Attributes, otlpStringAttribute("tag."+string(key), string(val)))
+	}
+
+	buf := getBuffer()
+	msg, err := proto.Marshal(r)
+	if err != nil {
+		// Marshaling a log record does not fail in practice. Report the
+		// error in the body of an empty record.
+		msg, _ = proto.Marshal(&logspb.LogRecord{
+			TimeUnixNano: uint64(entry.ts),
+			Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{
+				StringValue: "error encoding log record: " + err.Error(),
+			}},
+		})
+	}
+	appendOctetCountedFrame(buf, msg)
+	return buf
+}
+
+func otlpStringAttribute(key, val string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: val}},
+	}
+}
+
+func otlpIntAttribute(key string, val int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: val}},
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/grpc"
+)
+
+// fakeOTLPCollector is a LogsService server which forwards the log
+// records it receives on a channel.
+type fakeOTLPCollector struct {
+	collogspb.UnimplementedLogsServiceServer
+	records chan *logspb.LogRecord
+}
+
+func (c *fakeOTLPCollector) Export(
+	_ context.Context, req *collogspb.ExportLogsServiceRequest,
+) (*collogspb.ExportLogsServiceResponse, error) {
+	for _, rl := range req.ResourceLogs {
+		for _, il := range rl.InstrumentationLibraryLogs {
+			for _, r := range il.Logs {
+				c.records <- r
+			}
+		}
+	}
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func TestOTLPSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	collector := &fakeOTLPCollector{records: make(chan *logspb.LogRecord, 100)}
+	srv := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(srv, collector)
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	address := l.Addr().String()
+	insecure := true
+	// Disable buffering, so that the event is exported immediately.
+	noStaleness := time.Duration(0)
+	noFlushTrigger := logconfig.ByteSize(0)
+	cfg := logconfig.DefaultConfig()
+	cfg.Sinks.OTLPServers = map[string]*logconfig.OTLPSinkConfig{
+		"ops": {
+			OTLPDefaults: logconfig.OTLPDefaults{
+				Address:  &address,
+				Insecure: &insecure,
+				CommonSinkConfig: logconfig.CommonSinkConfig{
+					Buffering: logconfig.CommonBufferSinkConfigWrapper{
+						CommonBufferSinkConfig: logconfig.CommonBufferSinkConfig{
+							MaxStaleness:     &noStaleness,
+							FlushTriggerSize: &noFlushTrigger,
+						},
+					},
+				},
+			},
+			Channels: logconfig.SelectChannels(channel.OPS)},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Send a log event on the OPS channel.
+	Ops.Infof(context.Background(), "hello world")
+
+	select {
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	case r := <-collector.records:
+		require.Equal(t, "INFO", r.SeverityText)
+		require.Equal(t, logspb.SeverityNumber_SEVERITY_NUMBER_INFO, r.SeverityNumber)
+		require.Contains(t, r.Body.GetStringValue(), `"message":"hello world"`)
+		attrs := make(map[string]string)
+		for _, kv := range r.Attributes {
+			attrs[kv.Key] = kv.Value.GetStringValue()
+		}
+		require.Equal(t, "OPS", attrs["channel"])
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/exit"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+)
+
+// syslogSink represents a syslog server, to which logging events are
+// sent as RFC 5424 messages.
+//
+// The messages are produced by the formatSyslog formatter, which
+// wraps the format configured for the sink. Each formatted entry is an
+// octet-counted frame (see appendOctetCountedFrame), so that the
+// individual messages can be recovered when several entries are
+// accumulated by a bufferSink.
+type syslogSink struct {
+	// The network address of the syslog server. The network is one of
+	// "udp", "tcp" or "tls".
+	network string
+	addr    string
+
+	tlsConfig *tls.Config
+	timeout   time.Duration
+
+	// good indicates that the connection can be used.
+	good bool
+	conn net.Conn
+}
+
+type syslogSinkOptions struct {
+	unsafeTLS bool
+	timeout   time.Duration
+}
+
+func newSyslogSink(network, addr string, opts syslogSinkOptions) *syslogSink {
+	l := &syslogSink{
+		network: network,
+		addr:    addr,
+		timeout: opts.timeout,
+	}
+	if network == "tls" {
+		l.tlsConfig = &tls.Config{InsecureSkipVerify: opts.unsafeTLS}
+	}
+	return l
+}
+
+func (l *syslogSink) String() string {
+	return fmt.Sprintf("syslog:%s://%s", l.network, l.addr)
+}
+
+// active implements the logSink interface.
+func (l *syslogSink) active() bool { return true }
+
+// attachHints implements the logSink interface.
+func (l *syslogSink) attachHints(stacks []byte) []byte {
+	return stacks
+}
+
+// exitCode implements the logSink interface.
+func (l *syslogSink) exitCode() exit.Code {
+	return exit.LoggingNetCollectorUnavailable()
+}
+
+// output implements the logSink interface.
+func (l *syslogSink) output(b []byte, opts sinkOutputOptions) error {
+	// Try to write and reconnect immediately if the first write fails.
+	_ = l.tryWrite(b)
+	if l.good {
+		return nil
+	}
+
+	if err := l.ensureConn(b); err != nil {
+		return err
+	}
+	return l.tryWrite(b)
+}
+
+func (l *syslogSink) close() {
+	l.good = false
+	if l.conn != nil {
+		if err := l.conn.Close(); err != nil {
+			fmt.Fprintf(OrigStderr, "error closing syslog logger: %v\n", err)
+		}
+		l.conn = nil
+	}
+}
+
+func (l *syslogSink) ensureConn(b []byte) error {
+	if l.good {
+		return nil
+	}
+	l.close()
+	var err error
+	dialer := &net.Dialer{Timeout: l.timeout}
+	if l.network == "tls" {
+		l.conn, err = tls.DialWithDialer(dialer, "tcp", l.addr, l.tlsConfig)
+	} else {
+		l.conn, err = dialer.Dial(l.network, l.addr)
+	}
+	if err != nil {
+		fmt.Fprintf(OrigStderr, "%s: error dialing syslog server: %v\n%s", l, err, b)
+		return err
+	}
+	fmt.Fprintf(OrigStderr, "%s: connection to syslog server resumed\n", l)
+	l.good = true
+	return nil
+}
+
+func (l *syslogSink) tryWrite(b []byte) error {
+	if !l.good {
+		return errNoConn
+	}
+	if err := l.conn.SetWriteDeadline(timeutil.Now().Add(l.timeout)); err != nil {
+		// An error here is suggestive of a bug in the Go runtime.
+		fmt.Fprintf(OrigStderr, "%s: set write deadline error: %v\n%s",
+			l, err, b)
+		l.good = false
+		return err
+	}
+	return forEachOctetCountedFrame(b, func(frame, msg []byte) error {
+		// Stream transports use the octet-counting framing from RFC 6587;
+		// each datagram contains a single message with UDP (RFC 5426).
+		if l.network == "udp" {
+			frame = msg
+		}
+		n, err := l.conn.Write(frame)
+		if err != nil || n < len(frame) {
+			fmt.Fprintf(OrigStderr, "%s: logging error: %v or short write (%d/%d)\n%s",
+				l, err, n, len(frame), frame)
+			l.good = false
+			if err == nil {
+				err = io.ErrShortWrite
+			}
+		}
+		return err
+	})
+}
+
+// formatSyslog wraps the format configured for a syslog sink to
+// produce RFC 5424 messages, whose MSG part is the entry formatted by
+// the wrapped format.
+type formatSyslog struct {
+	inner    logFormatter
+	facility int
+	hostname string
+	appName  string
+	procID   string
+}
+
+func newFormatSyslog(inner logFormatter, facility int, appName string) formatSyslog {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return formatSyslog{
+		inner:    inner,
+		facility: facility,
+		hostname: syslogHeaderField(hostname, 255),
+		appName:  syslogHeaderField(appName, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+	}
+}
+
+func (f formatSyslog) formatterName() string { return f.inner.formatterName() }
+
+func (f formatSyslog) doc() string { return f.inner.doc() }
+
+// syslogSeverities maps the logging severities to the syslog
+// severity codes.
+var syslogSeverities = map[logpb.Severity]int{
+	logpb.Severity_INFO:    6, // Informational.
+	logpb.Severity_WARNING: 4, // Warning.
+	logpb.Severity_ERROR:   3, // Error.
+	logpb.Severity_FATAL:   2, // Critical.
+}
+
+func (f formatSyslog) formatEntry(entry logEntry) *buffer {
+	sev, ok := syslogSeverities[entry.sev]
+	if !ok || entry.header {
+		sev = syslogSeverities[logpb.Severity_INFO]
+	}
+	// The MSGID is the name of the channel.
+	msgID := "-"
+	if !entry.header {
+		msgID = syslogHeaderField(entry.ch.String(), 32)
+	}
+
+	msg := getBuffer()
+	defer putBuffer(msg)
+	msg.WriteByte('<')
+	msg.WriteString(strconv.Itoa(f.facility*8 + sev))
+	msg.WriteString(">1 ")
+	msg.WriteString(timeutil.Unix(0, entry.ts).UTC().Format("2006-01-02T15:04:05.000000Z07:00"))
+	msg.WriteByte(' ')
+	msg.WriteString(f.hostname)
+	msg.WriteByte(' ')
+	msg.WriteString(f.appName)
+	msg.WriteByte(' ')
+	msg.WriteString(f.procID)
+	msg.WriteByte(' ')
+	msg.WriteString(msgID)
+	// No structured data: the details of the event are part of the
+	// formatted entry.
+	msg.WriteString(" - ")
+	formatted := f.inner.formatEntry(entry)
+	msg.Write(bytes.TrimRight(formatted.Bytes(), "\n"))
+	putBuffer(formatted)
+
+	buf := getBuffer()
+	appendOctetCountedFrame(buf, msg.Bytes())
+	return buf
+}
+
+// syslogHeaderField returns s as a valid field of the header of a
+// syslog message: non-printable characters and spaces are replaced,
+// and the field is truncated to maxLen.
+func syslogHeaderField(s string, maxLen int) string {
+	if s == "" {
+		return "-"
+	}
+	b := []byte(s)
+	if len(b) > maxLen {
+		b = b[:maxLen]
+	}
+	for i, c := range b {
+		if c <= ' ' || c > '~' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// appendOctetCountedFrame appends msg to buf, prefixed by its length
+// in decimal and a space, as in the octet-counting framing of RFC
+// 6587.
+//
+// Sinks which need to send the individual entries formatted for them
+// use this framing, since a bufferSink concatenates the entries it
+// accumulates, separated by newlines, before passing them on to its
+// child sink.
+func appendOctetCountedFrame(buf *buffer, msg []byte) {
+	buf.WriteString(strconv.Itoa(len(msg)))
+	buf.WriteByte(' ')
+	buf.Write(msg)
+}
+
+// forEachOctetCountedFrame calls fn with every frame contained in b,
+// which consists of one or more octet-counted frames, possibly
+// separated by newlines. fn receives both the frame and the message it
+// contains.
+func forEachOctetCountedFrame(b []byte, fn func(frame, msg []byte) error) error {
+	for len(b) > 0 {
+		if b[0] == '\n' {
+			b = b[1:]
+			continue
+		}
+		sp := bytes.IndexByte(b, ' ')
+		if sp <= 0 {
+			return errors.AssertionFailedf("malformed log frame: %q", b)
+		}
+		n, err := strconv.Atoi(string(b[:sp]))
+		if err != nil || n < 0 || sp+1+n > len(b) {
+			return errors.AssertionFailedf("malformed log frame: %q", b)
+		}
+		frame := b[:sp+1+n]
+		if err := fn(frame, frame[sp+1:]); err != nil {
+			return err
+		}
+		b = b[len(frame):]
+	}
+	return nil
+}
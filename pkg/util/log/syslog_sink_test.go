@@ -0,0 +1,165 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package log
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log/channel"
+	"github.com/cockroachdb/cockroach/pkg/util/log/logconfig"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/stretchr/testify/require"
+)
+
+// syslogMessageRe matches the RFC 5424 messages for the "hello world"
+// events logged by the tests below, on the OPS channel at severity
+// INFO with the default facility local0 (PRI 16*8+6).
+var syslogMessageRe = regexp.MustCompile(
+	`^<134>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z \S+ cockroach \d+ OPS - \{.*"message":"hello world".*\}$`)
+
+func TestSyslogSinkUDP(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, conn.Close()) }()
+
+	cleanup := applySyslogConfig(t, sc, "udp", conn.LocalAddr().String())
+	defer cleanup()
+
+	// Send a log event on the OPS channel.
+	Ops.Infof(context.Background(), "hello world")
+
+	// Check that the event was sent as a single datagram.
+	require.NoError(t, conn.SetReadDeadline(timeutil.Now().Add(5*time.Second)))
+	buf := make([]byte, 64<<10)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Regexp(t, syslogMessageRe, string(buf[:n]))
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	sc := ScopeWithoutShowLogs(t)
+	defer sc.Close(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, l.Close()) }()
+
+	msgs := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			t.Logf("accept error: %v", err)
+			close(msgs)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = conn.SetReadDeadline(timeutil.Now().Add(5 * time.Second))
+		// Read one octet-counted frame.
+		r := bufio.NewReader(conn)
+		length, err := r.ReadString(' ')
+		if err != nil {
+			t.Logf("read error: %v", err)
+			close(msgs)
+			return
+		}
+		n, err := strconv.Atoi(length[:len(length)-1])
+		if err != nil {
+			t.Logf("invalid frame length: %q", length)
+			close(msgs)
+			return
+		}
+		msg := make([]byte, n)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			t.Logf("read error: %v", err)
+		}
+		msgs <- string(msg)
+	}()
+
+	cleanup := applySyslogConfig(t, sc, "tcp", l.Addr().String())
+	defer cleanup()
+
+	// Send a log event on the OPS channel.
+	Ops.Infof(context.Background(), "hello world")
+
+	select {
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout")
+	case msg := <-msgs:
+		require.Regexp(t, syslogMessageRe, msg)
+	}
+}
+
+// applySyslogConfig applies a logging configuration with a syslog sink
+// for the OPS channel, with the given server as target.
+func applySyslogConfig(t *testing.T, sc *TestLogScope, network, addr string) (cleanup func()) {
+	cfg := logconfig.DefaultConfig()
+	cfg.Sinks.SyslogServers = map[string]*logconfig.SyslogSinkConfig{
+		"ops": {
+			Net:      network,
+			Address:  addr,
+			Channels: logconfig.SelectChannels(channel.OPS)},
+	}
+	// Derive a full config using the same directory as the
+	// TestLogScope.
+	require.NoError(t, cfg.Validate(&sc.logDir))
+
+	// Apply the configuration.
+	TestingResetActive()
+	cleanup, err := ApplyConfig(cfg)
+	require.NoError(t, err)
+	return cleanup
+}
+
+func TestOctetCountedFrames(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	msgs := []string{"hello", "", "multi\nline", "12 34"}
+	// Frame the messages, and concatenate the frames with newlines, as
+	// done by a bufferSink.
+	var all []byte
+	for i, msg := range msgs {
+		buf := getBuffer()
+		appendOctetCountedFrame(buf, []byte(msg))
+		if i > 0 {
+			all = append(all, '\n')
+		}
+		all = append(all, buf.Bytes()...)
+		putBuffer(buf)
+	}
+
+	var res []string
+	require.NoError(t, forEachOctetCountedFrame(all, func(frame, msg []byte) error {
+		require.Equal(t, strconv.Itoa(len(msg))+" "+string(msg), string(frame))
+		res = append(res, string(msg))
+		return nil
+	}))
+	require.Equal(t, msgs, res)
+
+	require.Error(t, forEachOctetCountedFrame([]byte("hello"), func(_, _ []byte) error {
+		return nil
+	}))
+	require.Error(t, forEachOctetCountedFrame([]byte("10 hello"), func(_, _ []byte) error {
+		return nil
+	}))
+}
@@ -0,0 +1,298 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"context"
+	"crypto/tls"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	prometheusgo "github.com/prometheus/client_model/go"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var errNoOTLPEndpoint = errors.New("external.otlp.metrics.endpoint is not set")
+
+// OTLPHistogramBuckets determines the buckets of the histograms pushed
+// by an OTLPExporter.
+type OTLPHistogramBuckets int64
+
+const (
+	// OTLPExplicitBuckets exports histograms with the buckets exposed to
+	// Prometheus, i.e. one bucket per non-empty bucket of the underlying
+	// HDR histogram.
+	OTLPExplicitBuckets OTLPHistogramBuckets = iota
+	// OTLPPowerOfTwoBuckets exports histograms with the buckets exposed
+	// to Prometheus merged into buckets whose bounds are powers of two.
+	// This produces far fewer buckets, with bounds that are stable across
+	// pushes. The histograms use the explicit bounds data type, like with
+	// OTLPExplicitBuckets.
+	OTLPPowerOfTwoBuckets
+)
+
+// OTLPExporterOptions configures a push by an OTLPExporter.
+type OTLPExporterOptions struct {
+	// Insecure disables TLS on the connection to the collector.
+	Insecure bool
+	// Histograms determines the buckets of the exported histograms.
+	Histograms OTLPHistogramBuckets
+	// Timeout bounds the duration of a push.
+	Timeout time.Duration
+}
+
+// OTLPExporter scrapes PrometheusExporter for metrics, converts them
+// to OpenTelemetry metrics and pushes them to an OpenTelemetry
+// collector over OTLP/gRPC.
+//
+// Counters are exported as cumulative monotonic sums, gauges as gauges
+// and histograms as cumulative histograms with explicit bounds. The
+// Prometheus labels of the metrics become attributes of the data points.
+//
+// Histograms are never exported as OTLP exponential histograms: the
+// vendored OTLP protocol (go.opentelemetry.io/proto/otlp v0.9.0) predates
+// the ExponentialHistogram data type.
+//
+// The connection to the collector is kept open across pushes, and
+// re-established when the endpoint changes. It is NOT thread-safe.
+type OTLPExporter struct {
+	pm        *PrometheusExporter
+	resource  *resourcepb.Resource
+	startTime time.Time
+
+	// The connection to the collector, as last used by Push.
+	endpoint string
+	insecure bool
+	conn     *grpc.ClientConn
+	client   colmetricspb.MetricsServiceClient
+}
+
+// MakeOTLPExporter returns an initialized OTLP exporter. The given
+// attributes are attached to the resource of the exported metrics, in
+// addition to service.name.
+func MakeOTLPExporter(pm *PrometheusExporter, resourceAttrs map[string]string) OTLPExporter {
+	attrs := []*commonpb.KeyValue{otlpStringAttribute("service.name", "cockroach")}
+	keys := make([]string, 0, len(resourceAttrs))
+	for k := range resourceAttrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, otlpStringAttribute(k, resourceAttrs[k]))
+	}
+	return OTLPExporter{
+		pm:        pm,
+		resource:  &resourcepb.Resource{Attributes: attrs},
+		startTime: timeutil.Now(),
+	}
+}
+
+// Push metrics scraped from registry to an OpenTelemetry collector.
+func (oe *OTLPExporter) Push(ctx context.Context, endpoint string, opts OTLPExporterOptions) error {
+	if endpoint == "" {
+		return errNoOTLPEndpoint
+	}
+	// Regardless of whether Push() errors, clear metrics. Only latest
+	// metrics are pushed; cumulative metrics make up for the gap in the
+	// receiver.
+	defer oe.pm.clearMetrics()
+
+	if err := oe.ensureConn(endpoint, opts.Insecure); err != nil {
+		return err
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	families, err := oe.pm.Gather()
+	if err != nil {
+		return err
+	}
+	_, err = oe.client.Export(ctx, &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: oe.resource,
+			InstrumentationLibraryMetrics: []*metricspb.InstrumentationLibraryMetrics{{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{
+					Name: "github.com/cockroachdb/cockroach/pkg/util/metric",
+				},
+				Metrics: oe.makeMetrics(families, timeutil.Now(), opts.Histograms),
+			}},
+		}},
+	})
+	return errors.Wrapf(err, "pushing metrics to OTLP collector %s", endpoint)
+}
+
+// Close closes the connection to the collector, if any.
+func (oe *OTLPExporter) Close() error {
+	if oe.conn == nil {
+		return nil
+	}
+	err := oe.conn.Close()
+	oe.conn, oe.client = nil, nil
+	return err
+}
+
+func (oe *OTLPExporter) ensureConn(endpoint string, insecure bool) error {
+	if oe.conn != nil && oe.endpoint == endpoint && oe.insecure == insecure {
+		return nil
+	}
+	if err := oe.Close(); err != nil {
+		log.Warningf(context.Background(), "error closing connection to OTLP collector %s: %v", oe.endpoint, err)
+	}
+	transport := grpc.WithInsecure()
+	if !insecure {
+		transport = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	}
+	// The connection is established in the background, and
+	// re-established as needed by the gRPC client.
+	conn, err := grpc.Dial(endpoint, transport)
+	if err != nil {
+		return errors.Wrapf(err, "connecting to OTLP collector %s", endpoint)
+	}
+	oe.endpoint, oe.insecure = endpoint, insecure
+	oe.conn, oe.client = conn, colmetricspb.NewMetricsServiceClient(conn)
+	return nil
+}
+
+// makeMetrics converts the given metric families to OpenTelemetry
+// metrics. Families of unsupported types are skipped.
+func (oe *OTLPExporter) makeMetrics(
+	families []*prometheusgo.MetricFamily, now time.Time, buckets OTLPHistogramBuckets,
+) []*metricspb.Metric {
+	startNanos := uint64(oe.startTime.UnixNano())
+	nowNanos := uint64(now.UnixNano())
+	// Sort the families, for determinism.
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+
+	res := make([]*metricspb.Metric, 0, len(families))
+	for _, family := range families {
+		if len(family.Metric) == 0 {
+			continue
+		}
+		m := &metricspb.Metric{
+			Name:        family.GetName(),
+			Description: family.GetHelp(),
+		}
+		switch family.GetType() {
+		case prometheusgo.MetricType_COUNTER:
+			sum := &metricspb.Sum{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				IsMonotonic:            true,
+			}
+			for _, pm := range family.Metric {
+				sum.DataPoints = append(sum.DataPoints, &metricspb.NumberDataPoint{
+					Attributes:        otlpLabelAttributes(pm.Label),
+					StartTimeUnixNano: startNanos,
+					TimeUnixNano:      nowNanos,
+					Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: pm.GetCounter().GetValue()},
+				})
+			}
+			m.Data = &metricspb.Metric_Sum{Sum: sum}
+
+		case prometheusgo.MetricType_GAUGE:
+			gauge := &metricspb.Gauge{}
+			for _, pm := range family.Metric {
+				gauge.DataPoints = append(gauge.DataPoints, &metricspb.NumberDataPoint{
+					Attributes:   otlpLabelAttributes(pm.Label),
+					TimeUnixNano: nowNanos,
+					Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: pm.GetGauge().GetValue()},
+				})
+			}
+			m.Data = &metricspb.Metric_Gauge{Gauge: gauge}
+
+		case prometheusgo.MetricType_HISTOGRAM:
+			hist := &metricspb.Histogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			}
+			for _, pm := range family.Metric {
+				h := pm.GetHistogram()
+				dp := &metricspb.HistogramDataPoint{
+					Attributes:        otlpLabelAttributes(pm.Label),
+					StartTimeUnixNano: startNanos,
+					TimeUnixNano:      nowNanos,
+					Count:             h.GetSampleCount(),
+					Sum:               h.GetSampleSum(),
+				}
+				dp.ExplicitBounds, dp.BucketCounts = otlpHistogramBuckets(h, buckets)
+				hist.DataPoints = append(hist.DataPoints, dp)
+			}
+			m.Data = &metricspb.Metric_Histogram{Histogram: hist}
+
+		default:
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
+// otlpHistogramBuckets converts the cumulative buckets of a Prometheus
+// histogram to the explicit bounds and (non-cumulative) bucket counts
+// of an OpenTelemetry histogram. The last bucket count is for the
+// values above the last bound.
+func otlpHistogramBuckets(
+	h *prometheusgo.Histogram, buckets OTLPHistogramBuckets,
+) (bounds []float64, counts []uint64) {
+	var prevCumCount uint64
+	for _, b := range h.Bucket {
+		bound := b.GetUpperBound()
+		if math.IsInf(bound, +1) {
+			break
+		}
+		if buckets == OTLPPowerOfTwoBuckets && bound > 0 {
+			bound = math.Exp2(math.Ceil(math.Log2(bound)))
+		}
+		count := b.GetCumulativeCount() - prevCumCount
+		prevCumCount = b.GetCumulativeCount()
+		if n := len(bounds); n > 0 && bounds[n-1] >= bound {
+			// Merge into the previous bucket.
+			counts[n-1] += count
+			continue
+		}
+		bounds = append(bounds, bound)
+		counts = append(counts, count)
+	}
+	var overflow uint64
+	if h.GetSampleCount() > prevCumCount {
+		overflow = h.GetSampleCount() - prevCumCount
+	}
+	return bounds, append(counts, overflow)
+}
+
+func otlpLabelAttributes(labels []*prometheusgo.LabelPair) []*commonpb.KeyValue {
+	if len(labels) == 0 {
+		return nil
+	}
+	attrs := make([]*commonpb.KeyValue, len(labels))
+	for i, l := range labels {
+		attrs[i] = otlpStringAttribute(l.GetName(), l.GetValue())
+	}
+	return attrs
+}
+
+func otlpStringAttribute(key, val string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: val}},
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/gogo/protobuf/proto"
+	prometheusgo "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	r := NewRegistry()
+	r.AddLabel("registry", "one")
+
+	g := NewGauge(Metadata{Name: "some.gauge", Help: "a gauge"})
+	g.Update(12)
+	r.AddMetric(g)
+	c := NewCounter(Metadata{Name: "some.counter"})
+	c.Inc(3)
+	r.AddMetric(c)
+	h := NewHistogram(Metadata{Name: "some.histogram"}, time.Minute, 1000, 1)
+	h.RecordValue(1)
+	h.RecordValue(5)
+	h.RecordValue(100)
+	r.AddMetric(h)
+
+	pm := MakePrometheusExporter()
+	pm.ScrapeRegistry(r, false /* includeChildMetrics */)
+	oe := MakeOTLPExporter(&pm, map[string]string{"node_id": "1", "tenant_id": "2"})
+
+	var resourceAttrs []string
+	for _, kv := range oe.resource.Attributes {
+		resourceAttrs = append(resourceAttrs, kv.Key+"="+kv.Value.GetStringValue())
+	}
+	require.Equal(t, []string{"service.name=cockroach", "node_id=1", "tenant_id=2"}, resourceAttrs)
+
+	families, err := pm.Gather()
+	require.NoError(t, err)
+	metrics := oe.makeMetrics(families, timeutil.Now(), OTLPExplicitBuckets)
+	require.Len(t, metrics, 3)
+
+	require.Equal(t, "some_counter", metrics[0].Name)
+	sum := metrics[0].GetSum()
+	require.NotNil(t, sum)
+	require.True(t, sum.IsMonotonic)
+	require.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, sum.AggregationTemporality)
+	require.Len(t, sum.DataPoints, 1)
+	require.Equal(t, 3.0, sum.DataPoints[0].GetAsDouble())
+	require.Equal(t, "registry", sum.DataPoints[0].Attributes[0].Key)
+	require.Equal(t, "one", sum.DataPoints[0].Attributes[0].Value.GetStringValue())
+
+	require.Equal(t, "some_gauge", metrics[1].Name)
+	require.Equal(t, "a gauge", metrics[1].Description)
+	require.Equal(t, 12.0, metrics[1].GetGauge().DataPoints[0].GetAsDouble())
+
+	require.Equal(t, "some_histogram", metrics[2].Name)
+	hist := metrics[2].GetHistogram()
+	require.NotNil(t, hist)
+	dp := hist.DataPoints[0]
+	require.Equal(t, uint64(3), dp.Count)
+	require.Equal(t, families[2].Metric[0].GetHistogram().GetSampleSum(), dp.Sum)
+	require.Len(t, dp.BucketCounts, len(dp.ExplicitBounds)+1)
+	var total uint64
+	for _, n := range dp.BucketCounts {
+		total += n
+	}
+	require.Equal(t, uint64(3), total)
+}
+
+func TestOTLPHistogramBuckets(t *testing.T) {
+	h := &prometheusgo.Histogram{
+		SampleCount: proto.Uint64(7),
+		Bucket: []*prometheusgo.Bucket{
+			{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(1)},
+			{UpperBound: proto.Float64(3), CumulativeCount: proto.Uint64(3)},
+			{UpperBound: proto.Float64(4), CumulativeCount: proto.Uint64(4)},
+			{UpperBound: proto.Float64(100), CumulativeCount: proto.Uint64(6)},
+		},
+	}
+
+	bounds, counts := otlpHistogramBuckets(h, OTLPExplicitBuckets)
+	require.Equal(t, []float64{1, 3, 4, 100}, bounds)
+	require.Equal(t, []uint64{1, 2, 1, 2, 1}, counts)
+
+	// With power-of-two buckets, the buckets up to 3 and 4 are merged
+	// into the bucket up to 4.
+	bounds, counts = otlpHistogramBuckets(h, OTLPPowerOfTwoBuckets)
+	require.Equal(t, []float64{1, 4, 128}, bounds)
+	require.Equal(t, []uint64{1, 3, 2, 1}, counts)
+}
@@ -183,6 +183,12 @@ func exportedName(name string) string {
 	return prometheusNameReplaceRE.ReplaceAllString(name, "_")
 }
 
+// ExportedName returns the name under which the metric with the given name
+// is exported to Prometheus.
+func ExportedName(name string) string {
+	return exportedName(name)
+}
+
 // exportedLabel takes a metric name and generates a valid prometheus name.
 func exportedLabel(name string) string {
 	return prometheusLabelReplaceRE.ReplaceAllString(name, "_")
@@ -0,0 +1,563 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Sample is the most recent value of a single series. The series is
+// identified by its labels, e.g. {instance="1"} or {store="2"}.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// SeriesSource provides the data against which rules are evaluated.
+type SeriesSource interface {
+	// Series returns the most recent value of every series with the given
+	// (Prometheus-compatible) metric name. An unknown name yields no
+	// samples rather than an error.
+	Series(ctx context.Context, name string) ([]Sample, error)
+}
+
+// AlertState is the state of an alert raised by an AlertingRule.
+type AlertState int
+
+const (
+	// AlertPending means that the alert expression holds but has not held
+	// for the recommended hold duration of the rule yet.
+	AlertPending AlertState = iota
+	// AlertFiring means that the alert expression has held for at least the
+	// recommended hold duration of the rule.
+	AlertFiring
+	// AlertResolved means that a firing alert's expression no longer holds.
+	AlertResolved
+)
+
+// String implements the fmt.Stringer interface.
+func (s AlertState) String() string {
+	switch s {
+	case AlertPending:
+		return "pending"
+	case AlertFiring:
+		return "firing"
+	case AlertResolved:
+		return "resolved"
+	default:
+		return "unknown"
+	}
+}
+
+// Alert is a single instance of an AlertingRule, i.e. one series for which
+// the rule expression holds.
+type Alert struct {
+	// RuleName is the name of the AlertingRule that raised the alert.
+	RuleName string
+	// Labels are the labels of the series for which the expression holds,
+	// merged with the labels of the rule.
+	Labels map[string]string
+	// Annotations are the annotations of the rule, with the templates
+	// {{ $labels.<name> }} and {{ $value }} expanded.
+	Annotations map[string]string
+	// Value is the last value of the rule expression for the series.
+	Value float64
+	// State is the current state of the alert.
+	State AlertState
+	// ActiveSince is the time at which the expression started holding.
+	ActiveSince time.Time
+	// ResolvedAt is the time at which the alert was resolved, if it was.
+	ResolvedAt time.Time
+}
+
+// RuleEvaluator evaluates the rules of a RuleRegistry against a
+// SeriesSource and tracks the state of the resulting alerts across
+// evaluations, in the same way as the Prometheus rule manager does.
+//
+// Only the subset of PromQL used by the rules defined in CockroachDB is
+// supported: instant vector selectors, number literals, arithmetic and
+// comparison operators, and the sum, min, max, avg and count
+// aggregations. Rules using other constructs fail to evaluate.
+type RuleEvaluator struct {
+	registry *RuleRegistry
+
+	mu struct {
+		syncutil.Mutex
+		// active tracks the pending and firing alerts by rule name and
+		// labels.
+		active map[string]*Alert
+	}
+}
+
+// NewRuleEvaluator creates a new RuleEvaluator for the rules in the given
+// registry.
+func NewRuleEvaluator(registry *RuleRegistry) *RuleEvaluator {
+	e := &RuleEvaluator{registry: registry}
+	e.mu.active = make(map[string]*Alert)
+	return e
+}
+
+// Evaluate evaluates all rules at the given time. Aggregation rules are
+// evaluated in registration order and their results can be referenced by
+// name in the rules registered after them. It returns the alerts that
+// started firing or were resolved by this evaluation; the rules that
+// could not be evaluated are reported in the returned error and leave the
+// state of their alerts untouched.
+func (e *RuleEvaluator) Evaluate(
+	ctx context.Context, src SeriesSource, now time.Time,
+) ([]Alert, error) {
+	var rules []Rule
+	e.registry.Each(func(rule Rule) {
+		rules = append(rules, rule)
+	})
+
+	ev := evaluator{ctx: ctx, src: src, recorded: make(map[string][]Sample)}
+	var transitions []Alert
+	var retErr error
+	for _, rule := range rules {
+		expr, err := parser.ParseExpr(rule.Expr())
+		if err == nil {
+			var v value
+			v, err = ev.eval(expr)
+			if err == nil && v.isScalar {
+				v = value{vector: []Sample{{Labels: map[string]string{}, Value: v.scalar}}}
+			}
+			if err == nil {
+				switch r := rule.(type) {
+				case *AggregationRule:
+					ev.recorded[r.name] = withLabels(v.vector, r.labels)
+				case *AlertingRule:
+					transitions = append(transitions, e.updateAlerts(r, v.vector, now)...)
+				}
+			}
+		}
+		if err != nil {
+			retErr = errors.CombineErrors(retErr, errors.Wrapf(err, "evaluating rule %s", rule.Name()))
+		}
+	}
+	return transitions, retErr
+}
+
+// ActiveAlerts returns the alerts that are currently pending or firing,
+// ordered by rule name and labels.
+func (e *RuleEvaluator) ActiveAlerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	keys := make([]string, 0, len(e.mu.active))
+	for key := range e.mu.active {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	alerts := make([]Alert, 0, len(keys))
+	for _, key := range keys {
+		alerts = append(alerts, *e.mu.active[key])
+	}
+	return alerts
+}
+
+// updateAlerts updates the state of the alerts of the given rule with the
+// result of evaluating its expression, and returns the alerts that
+// transitioned to firing or resolved.
+func (e *RuleEvaluator) updateAlerts(r *AlertingRule, result []Sample, now time.Time) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var transitions []Alert
+	seen := make(map[string]struct{}, len(result))
+	for _, s := range withLabels(result, r.labels) {
+		key := alertKey(r.name, s.Labels)
+		seen[key] = struct{}{}
+		a, ok := e.mu.active[key]
+		if !ok {
+			a = &Alert{
+				RuleName:    r.name,
+				Labels:      s.Labels,
+				State:       AlertPending,
+				ActiveSince: now,
+			}
+			e.mu.active[key] = a
+		}
+		a.Value = s.Value
+		a.Annotations = expandAnnotations(r.annotations, s.Labels, s.Value)
+		if a.State == AlertPending && now.Sub(a.ActiveSince) >= r.recommendedHoldDuration {
+			a.State = AlertFiring
+			transitions = append(transitions, *a)
+		}
+	}
+	for key, a := range e.mu.active {
+		if a.RuleName != r.name {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		delete(e.mu.active, key)
+		if a.State == AlertFiring {
+			a.State = AlertResolved
+			a.ResolvedAt = now
+			transitions = append(transitions, *a)
+		}
+	}
+	return transitions
+}
+
+// alertKey identifies an alert by its rule name and labels.
+func alertKey(ruleName string, labels map[string]string) string {
+	var b strings.Builder
+	b.WriteString(ruleName)
+	for _, name := range sortedLabelNames(labels) {
+		b.WriteByte(0)
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(labels[name])
+	}
+	return b.String()
+}
+
+func sortedLabelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withLabels returns a copy of the samples with the given labels added.
+func withLabels(samples []Sample, labels []LabelPair) []Sample {
+	if len(labels) == 0 {
+		return samples
+	}
+	res := make([]Sample, len(samples))
+	for i, s := range samples {
+		res[i] = Sample{Labels: copyLabels(s.Labels), Value: s.Value}
+		for name, val := range getLabelMap(labels) {
+			res[i].Labels[name] = val
+		}
+	}
+	return res
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	res := make(map[string]string, len(labels))
+	for name, val := range labels {
+		res[name] = val
+	}
+	return res
+}
+
+// expandAnnotations expands the Prometheus templates {{ $labels.<name> }}
+// and {{ $value }} in the given annotations. Annotations which fail to
+// expand are returned unexpanded.
+func expandAnnotations(
+	annotations []LabelPair, labels map[string]string, val float64,
+) map[string]string {
+	res := getLabelMap(annotations)
+	data := struct {
+		Labels map[string]string
+		Value  float64
+	}{labels, val}
+	for name, text := range res {
+		tmpl, err := template.New(name).Option("missingkey=zero").Parse(
+			"{{$labels := .Labels}}{{$value := .Value}}" + text)
+		if err != nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			continue
+		}
+		res[name] = buf.String()
+	}
+	return res
+}
+
+// value is the result of evaluating a PromQL expression, which is either a
+// scalar or an instant vector.
+type value struct {
+	isScalar bool
+	scalar   float64
+	vector   []Sample
+}
+
+// evaluator evaluates PromQL expressions for a single evaluation of the
+// rules.
+type evaluator struct {
+	ctx context.Context
+	src SeriesSource
+	// recorded holds the results of the aggregation rules evaluated so far,
+	// by rule name.
+	recorded map[string][]Sample
+}
+
+func (ev *evaluator) eval(expr parser.Expr) (value, error) {
+	switch e := expr.(type) {
+	case *parser.NumberLiteral:
+		return value{isScalar: true, scalar: e.Val}, nil
+	case *parser.ParenExpr:
+		return ev.eval(e.Expr)
+	case *parser.UnaryExpr:
+		v, err := ev.eval(e.Expr)
+		if err != nil || e.Op != parser.SUB {
+			return v, err
+		}
+		return binaryOp(parser.MUL, value{isScalar: true, scalar: -1}, v, nil, false)
+	case *parser.VectorSelector:
+		return ev.selectSeries(e)
+	case *parser.AggregateExpr:
+		v, err := ev.eval(e.Expr)
+		if err != nil {
+			return value{}, err
+		}
+		return aggregate(e, v)
+	case *parser.BinaryExpr:
+		lhs, err := ev.eval(e.LHS)
+		if err != nil {
+			return value{}, err
+		}
+		rhs, err := ev.eval(e.RHS)
+		if err != nil {
+			return value{}, err
+		}
+		return binaryOp(e.Op, lhs, rhs, e.VectorMatching, e.ReturnBool)
+	default:
+		return value{}, errors.Newf("unsupported expression %s", expr)
+	}
+}
+
+func (ev *evaluator) selectSeries(e *parser.VectorSelector) (value, error) {
+	if e.OriginalOffset != 0 || e.Timestamp != nil {
+		return value{}, errors.Newf("unsupported modifier in %s", e)
+	}
+	samples, ok := ev.recorded[e.Name]
+	if !ok {
+		var err error
+		if samples, err = ev.src.Series(ev.ctx, e.Name); err != nil {
+			return value{}, err
+		}
+	}
+	var res []Sample
+	for _, s := range samples {
+		matches := true
+		for _, m := range e.LabelMatchers {
+			if m.Name == "__name__" {
+				continue
+			}
+			if !m.Matches(s.Labels[m.Name]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			res = append(res, s)
+		}
+	}
+	return value{vector: res}, nil
+}
+
+// groupingLabels returns the labels that identify the group of the given
+// sample in an aggregation or a vector match.
+func groupingLabels(labels map[string]string, names []string, include bool) map[string]string {
+	res := make(map[string]string)
+	if include {
+		for _, name := range names {
+			if val, ok := labels[name]; ok {
+				res[name] = val
+			}
+		}
+		return res
+	}
+	for name, val := range labels {
+		res[name] = val
+	}
+	for _, name := range names {
+		delete(res, name)
+	}
+	return res
+}
+
+func aggregate(e *parser.AggregateExpr, v value) (value, error) {
+	if v.isScalar {
+		return value{}, errors.Newf("expected instant vector in aggregation %s", e)
+	}
+	type group struct {
+		labels map[string]string
+		val    float64
+		count  int
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for _, s := range v.vector {
+		labels := groupingLabels(s.Labels, e.Grouping, !e.Without)
+		key := alertKey("", labels)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels, val: s.Value}
+			groups[key] = g
+			order = append(order, key)
+		} else {
+			switch e.Op {
+			case parser.SUM, parser.AVG:
+				g.val += s.Value
+			case parser.MIN:
+				g.val = math.Min(g.val, s.Value)
+			case parser.MAX:
+				g.val = math.Max(g.val, s.Value)
+			}
+		}
+		g.count++
+	}
+	res := make([]Sample, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		switch e.Op {
+		case parser.SUM, parser.MIN, parser.MAX:
+		case parser.AVG:
+			g.val /= float64(g.count)
+		case parser.COUNT:
+			g.val = float64(g.count)
+		default:
+			return value{}, errors.Newf("unsupported aggregation %s", e.Op)
+		}
+		res = append(res, Sample{Labels: g.labels, Value: g.val})
+	}
+	return value{vector: res}, nil
+}
+
+func binaryOp(
+	op parser.ItemType, lhs, rhs value, matching *parser.VectorMatching, returnBool bool,
+) (value, error) {
+	apply := func(l, r float64) (float64, bool, error) {
+		if op.IsComparisonOperator() {
+			holds, err := compare(op, l, r)
+			if returnBool {
+				if holds {
+					return 1, true, err
+				}
+				return 0, true, err
+			}
+			return l, holds, err
+		}
+		res, err := arithmetic(op, l, r)
+		return res, true, err
+	}
+
+	switch {
+	case lhs.isScalar && rhs.isScalar:
+		res, _, err := apply(lhs.scalar, rhs.scalar)
+		return value{isScalar: true, scalar: res}, err
+
+	case lhs.isScalar || rhs.isScalar:
+		vec, scalarOnLeft := rhs.vector, true
+		if rhs.isScalar {
+			vec, scalarOnLeft = lhs.vector, false
+		}
+		var res []Sample
+		for _, s := range vec {
+			l, r := s.Value, rhs.scalar
+			if scalarOnLeft {
+				l, r = lhs.scalar, s.Value
+			}
+			val, keep, err := apply(l, r)
+			if err != nil {
+				return value{}, err
+			}
+			if keep {
+				if scalarOnLeft && op.IsComparisonOperator() && !returnBool {
+					val = s.Value
+				}
+				res = append(res, Sample{Labels: s.Labels, Value: val})
+			}
+		}
+		return value{vector: res}, nil
+
+	default:
+		if matching != nil && matching.Card != parser.CardOneToOne {
+			return value{}, errors.Newf("unsupported vector matching %s", matching.Card)
+		}
+		var names []string
+		on := false
+		if matching != nil {
+			names, on = matching.MatchingLabels, matching.On
+		}
+		rhsByKey := make(map[string]Sample, len(rhs.vector))
+		for _, s := range rhs.vector {
+			rhsByKey[alertKey("", groupingLabels(s.Labels, names, on))] = s
+		}
+		var res []Sample
+		for _, s := range lhs.vector {
+			labels := groupingLabels(s.Labels, names, on)
+			r, ok := rhsByKey[alertKey("", labels)]
+			if !ok {
+				continue
+			}
+			val, keep, err := apply(s.Value, r.Value)
+			if err != nil {
+				return value{}, err
+			}
+			if keep {
+				if op.IsComparisonOperator() && !returnBool {
+					labels = s.Labels
+				}
+				res = append(res, Sample{Labels: labels, Value: val})
+			}
+		}
+		return value{vector: res}, nil
+	}
+}
+
+func compare(op parser.ItemType, l, r float64) (bool, error) {
+	switch op {
+	case parser.EQLC:
+		return l == r, nil
+	case parser.NEQ:
+		return l != r, nil
+	case parser.GTR:
+		return l > r, nil
+	case parser.LSS:
+		return l < r, nil
+	case parser.GTE:
+		return l >= r, nil
+	case parser.LTE:
+		return l <= r, nil
+	default:
+		return false, errors.Newf("unsupported comparison operator %s", op)
+	}
+}
+
+func arithmetic(op parser.ItemType, l, r float64) (float64, error) {
+	switch op {
+	case parser.ADD:
+		return l + r, nil
+	case parser.SUB:
+		return l - r, nil
+	case parser.MUL:
+		return l * r, nil
+	case parser.DIV:
+		return l / r, nil
+	case parser.MOD:
+		return math.Mod(l, r), nil
+	case parser.POW:
+		return math.Pow(l, r), nil
+	default:
+		return 0, errors.Newf("unsupported operator %s", op)
+	}
+}
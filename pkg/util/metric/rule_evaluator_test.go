@@ -0,0 +1,161 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package metric
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+type testSeriesSource map[string][]Sample
+
+func (s testSeriesSource) Series(_ context.Context, name string) ([]Sample, error) {
+	return s[name], nil
+}
+
+func TestRuleEvaluatorExpressions(t *testing.T) {
+	src := testSeriesSource{
+		"capacity": {
+			{Labels: map[string]string{"instance": "1", "store": "1"}, Value: 100},
+			{Labels: map[string]string{"instance": "1", "store": "2"}, Value: 100},
+			{Labels: map[string]string{"instance": "2", "store": "3"}, Value: 50},
+		},
+		"capacity_available": {
+			{Labels: map[string]string{"instance": "1", "store": "1"}, Value: 10},
+			{Labels: map[string]string{"instance": "1", "store": "2"}, Value: 30},
+			{Labels: map[string]string{"instance": "2", "store": "3"}, Value: 40},
+		},
+	}
+	testCases := []struct {
+		expr     string
+		expected []Sample
+	}{
+		{
+			expr: "sum by(instance) (capacity)",
+			expected: []Sample{
+				{Labels: map[string]string{"instance": "1"}, Value: 200},
+				{Labels: map[string]string{"instance": "2"}, Value: 50},
+			},
+		},
+		{
+			expr: "sum without(store) (capacity_available) / sum without(store) (capacity) < 0.5",
+			expected: []Sample{
+				{Labels: map[string]string{"instance": "1"}, Value: 0.2},
+			},
+		},
+		{
+			expr: `max(capacity{instance="1"}) * 2`,
+			expected: []Sample{
+				{Labels: map[string]string{}, Value: 200},
+			},
+		},
+		{
+			expr:     "count(capacity) > 5",
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			rule, err := NewAlertingRule("test", tc.expr, nil, nil, 0, "", false)
+			require.NoError(t, err)
+			registry := NewRuleRegistry()
+			registry.AddRule(rule)
+			e := NewRuleEvaluator(registry)
+			_, err = e.Evaluate(context.Background(), src, time.Unix(0, 0))
+			require.NoError(t, err)
+			var actual []Sample
+			for _, a := range e.ActiveAlerts() {
+				actual = append(actual, Sample{Labels: a.Labels, Value: a.Value})
+			}
+			require.ElementsMatch(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestRuleEvaluatorAlertLifecycle(t *testing.T) {
+	ctx := context.Background()
+	registry := NewRuleRegistry()
+	agg, err := NewAggregationRule("node:open_fds", "sum without(store) (fds)", nil, "", false)
+	require.NoError(t, err)
+	alert, err := NewAlertingRule(
+		"TooManyFDs",
+		"node:open_fds > 10",
+		[]LabelPair{{
+			Name:  proto.String("summary"),
+			Value: proto.String("{{ $value }} open files on {{ $labels.instance }}"),
+		}},
+		[]LabelPair{{Name: proto.String("severity"), Value: proto.String("warning")}},
+		time.Minute,
+		"",
+		false,
+	)
+	require.NoError(t, err)
+	registry.AddRules([]Rule{agg, alert})
+	e := NewRuleEvaluator(registry)
+
+	src := testSeriesSource{
+		"fds": {{Labels: map[string]string{"instance": "1", "store": "1"}, Value: 12}},
+	}
+	start := time.Unix(1000, 0)
+
+	// The alert is pending until the expression held for the hold duration.
+	transitions, err := e.Evaluate(ctx, src, start)
+	require.NoError(t, err)
+	require.Empty(t, transitions)
+	active := e.ActiveAlerts()
+	require.Len(t, active, 1)
+	require.Equal(t, AlertPending, active[0].State)
+
+	transitions, err = e.Evaluate(ctx, src, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	require.Equal(t, AlertFiring, transitions[0].State)
+	require.Equal(t, "TooManyFDs", transitions[0].RuleName)
+	require.Equal(t, start, transitions[0].ActiveSince)
+	require.Equal(t, map[string]string{"instance": "1", "severity": "warning"}, transitions[0].Labels)
+	require.Equal(t, map[string]string{"summary": "12 open files on 1"}, transitions[0].Annotations)
+
+	// Firing alerts are only reported once.
+	transitions, err = e.Evaluate(ctx, src, start.Add(2*time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, transitions)
+
+	src["fds"][0].Value = 5
+	transitions, err = e.Evaluate(ctx, src, start.Add(3*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	require.Equal(t, AlertResolved, transitions[0].State)
+	require.Equal(t, start.Add(3*time.Minute), transitions[0].ResolvedAt)
+	require.Empty(t, e.ActiveAlerts())
+
+	// A pending alert that stops holding is dropped silently.
+	src["fds"][0].Value = 15
+	_, err = e.Evaluate(ctx, src, start.Add(4*time.Minute))
+	require.NoError(t, err)
+	src["fds"][0].Value = 5
+	transitions, err = e.Evaluate(ctx, src, start.Add(5*time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, transitions)
+	require.Empty(t, e.ActiveAlerts())
+}
+
+func TestRuleEvaluatorUnsupportedExpression(t *testing.T) {
+	rule, err := NewAlertingRule("test", "resets(sys_uptime[10m]) > 0", nil, nil, 0, "", false)
+	require.NoError(t, err)
+	registry := NewRuleRegistry()
+	registry.AddRule(rule)
+	_, err = NewRuleEvaluator(registry).Evaluate(context.Background(), testSeriesSource{}, time.Now())
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// config is a text search configuration, which determines how the words of
+// a document are normalized into lexemes.
+type config struct {
+	// stopWords are the words which are dropped from documents and queries.
+	stopWords map[string]struct{}
+	// stem, if set, reduces a lowercased word to its stem.
+	stem func(word string) string
+}
+
+var configs = map[string]*config{
+	"simple": {},
+	"english": {
+		stopWords: englishStopWords,
+		stem:      stemEnglish,
+	},
+}
+
+// DefaultConfig is the name of the text search configuration used when none
+// is specified.
+const DefaultConfig = "english"
+
+// ValidConfig returns an error if the given text search configuration does
+// not exist.
+func ValidConfig(name string) error {
+	_, err := getConfig(name)
+	return err
+}
+
+func getConfig(name string) (*config, error) {
+	c, ok := configs[strings.TrimPrefix(strings.ToLower(name), "pg_catalog.")]
+	if !ok {
+		return nil, pgerror.Newf(pgcode.UndefinedObject,
+			"text search configuration %q does not exist", name)
+	}
+	return c, nil
+}
+
+// lexize returns the lexeme for the given word, or false if the word is a
+// stop word.
+func (c *config) lexize(word string) (string, bool) {
+	word = strings.ToLower(word)
+	if _, ok := c.stopWords[word]; ok {
+		return "", false
+	}
+	if c.stem != nil && isASCIIWord(word) {
+		word = c.stem(word)
+	}
+	return word, true
+}
+
+// isASCIIWord returns whether the word only contains ASCII letters, which is
+// what the stemmers handle.
+func isASCIIWord(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if c := word[i]; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// word is a word of a document, along with its position.
+type word struct {
+	text     string
+	position int
+}
+
+// tokenize splits the given document into words, which are the maximal runs
+// of letters and digits. Words are numbered starting at 1.
+func tokenize(document string) []word {
+	var words []word
+	start := -1
+	for i, r := range document {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, word{text: document[start:i], position: len(words) + 1})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{text: document[start:], position: len(words) + 1})
+	}
+	return words
+}
+
+// lexemes returns the lexemes of the given document, along with their
+// positions. The positions of stop words are skipped.
+func (c *config) lexemes(document string) []word {
+	words := tokenize(document)
+	res := words[:0]
+	for _, w := range words {
+		if lexeme, ok := c.lexize(w.text); ok && len(lexeme) <= maxLexemeLength {
+			res = append(res, word{text: lexeme, position: w.position})
+		}
+	}
+	return res
+}
+
+// ToTSVector normalizes the given document into a tsvector using the given
+// text search configuration.
+func ToTSVector(configName string, document string) (TSVector, error) {
+	c, err := getConfig(configName)
+	if err != nil {
+		return nil, err
+	}
+	words := c.lexemes(document)
+	v := make(TSVector, len(words))
+	for i, w := range words {
+		pos := w.position
+		if pos > maxPosition {
+			pos = maxPosition
+		}
+		v[i] = tsTerm{lexeme: w.text, positions: []tsPosition{{position: uint16(pos)}}}
+	}
+	return v.normalize(), nil
+}
+
+// ToTSQuery parses the given query, in the tsquery syntax, and normalizes its
+// operands using the given text search configuration. Operands which produce
+// several lexemes are replaced by the phrase of those lexemes, and operands
+// which are stop words are dropped.
+func ToTSQuery(configName string, query string) (TSQuery, error) {
+	c, err := getConfig(configName)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	return parseTSQuery(query, func(leaf *tsNode) *tsNode {
+		words := c.lexemes(leaf.lexeme)
+		return makePhrase(words, func(lexeme string) *tsNode {
+			return &tsNode{lexeme: lexeme, prefix: leaf.prefix, weights: leaf.weights}
+		})
+	})
+}
+
+// PlainToTSQuery normalizes the given text into a query which matches the
+// documents containing all its words, combined with &.
+func PlainToTSQuery(configName string, text string) (TSQuery, error) {
+	c, err := getConfig(configName)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	var root *tsNode
+	for _, w := range c.lexemes(text) {
+		leaf := &tsNode{lexeme: w.text}
+		if root == nil {
+			root = leaf
+		} else {
+			root = &tsNode{op: and, l: root, r: leaf}
+		}
+	}
+	return TSQuery{root: root}, nil
+}
+
+// PhraseToTSQuery normalizes the given text into a query which matches the
+// documents containing its words as a phrase, combined with <->. Dropped
+// stop words are accounted for in the distances between the words.
+func PhraseToTSQuery(configName string, text string) (TSQuery, error) {
+	c, err := getConfig(configName)
+	if err != nil {
+		return TSQuery{}, err
+	}
+	return TSQuery{root: makePhrase(c.lexemes(text), func(lexeme string) *tsNode {
+		return &tsNode{lexeme: lexeme}
+	})}, nil
+}
+
+// makePhrase combines the leaves made for the given words with phrase
+// operators whose distances are the ones between the words.
+func makePhrase(words []word, makeLeaf func(lexeme string) *tsNode) *tsNode {
+	var root *tsNode
+	for i, w := range words {
+		leaf := makeLeaf(w.text)
+		if root == nil {
+			root = leaf
+			continue
+		}
+		dist := w.position - words[i-1].position
+		if dist > maxPhraseDistance {
+			dist = maxPhraseDistance
+		}
+		root = &tsNode{op: followedBy, followedN: uint16(dist), l: root, r: leaf}
+	}
+	return root
+}
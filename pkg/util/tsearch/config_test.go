@@ -0,0 +1,128 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToTSVector(t *testing.T) {
+	testCases := []struct {
+		config   string
+		document string
+		expected string
+	}{
+		{"simple", "The Fat Rats", `'fat':2 'rats':3 'the':1`},
+		{"english", "The Fat Rats", `'fat':2 'rat':3`},
+		{"english", "a fat  cat sat on a mat - it ate a fat rats",
+			`'ate':9 'cat':3 'fat':2,11 'mat':7 'rat':12 'sat':4`},
+		{"pg_catalog.english", "Supernovae stars are running!", `'run':4 'star':2 'supernova':1`},
+		{"simple", "", ``},
+		{"simple", "ÉCOLE 42nd", `'42nd':2 'école':1`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.config+"/"+tc.document, func(t *testing.T) {
+			v, err := ToTSVector(tc.config, tc.document)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, v.String())
+		})
+	}
+
+	_, err := ToTSVector("klingon", "a")
+	require.Error(t, err)
+}
+
+func TestToTSQuery(t *testing.T) {
+	testCases := []struct {
+		config   string
+		query    string
+		expected string
+	}{
+		{"english", "The & Fat & Rats", `'fat' & 'rat'`},
+		{"simple", "The & Fat & Rats", `'the' & 'fat' & 'rats'`},
+		{"english", "supernovae:*B & !stars", `'supernova':*B & !'star'`},
+		{"english", "'fat rats' | cats", `'fat' <-> 'rat' | 'cat'`},
+		{"english", "fat <-> the <-> rats", `'fat' <2> 'rat'`},
+		{"english", "the <-> fat <-> rats", `'fat' <-> 'rat'`},
+		{"english", "fat <-> the", `'fat'`},
+		{"english", "the & a", ``},
+		{"english", "!the", ``},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.config+"/"+tc.query, func(t *testing.T) {
+			q, err := ToTSQuery(tc.config, tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q.String())
+		})
+	}
+}
+
+func TestPlainAndPhraseToTSQuery(t *testing.T) {
+	q, err := PlainToTSQuery("english", "The Fat Rats")
+	require.NoError(t, err)
+	require.Equal(t, `'fat' & 'rat'`, q.String())
+
+	q, err = PhraseToTSQuery("english", "The Fat Rats")
+	require.NoError(t, err)
+	require.Equal(t, `'fat' <-> 'rat'`, q.String())
+
+	q, err = PhraseToTSQuery("english", "the cat and the rat")
+	require.NoError(t, err)
+	require.Equal(t, `'cat' <3> 'rat'`, q.String())
+
+	q, err = PlainToTSQuery("simple", "")
+	require.NoError(t, err)
+	require.Equal(t, ``, q.String())
+}
+
+func TestStemEnglish(t *testing.T) {
+	testCases := map[string]string{
+		"a":              "a",
+		"cats":           "cat",
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"ties":           "tie",
+		"gas":            "gas",
+		"gaps":           "gap",
+		"running":        "run",
+		"hopping":        "hop",
+		"hoping":         "hope",
+		"agreed":         "agre",
+		"feed":           "feed",
+		"luxuriating":    "luxuri",
+		"happy":          "happi",
+		"cry":            "cri",
+		"say":            "say",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generously":     "generous",
+		"generalization": "general",
+		"communication":  "communic",
+		"hopefulness":    "hope",
+		"adjustable":     "adjust",
+		"adoption":       "adopt",
+		"controll":       "control",
+		"rate":           "rate",
+		"ate":            "ate",
+		"skies":          "sky",
+		"dying":          "die",
+		"succeed":        "succeed",
+		"knightly":       "knight",
+		"consignment":    "consign",
+		"yelling":        "yell",
+		"playing":        "play",
+	}
+	for word, expected := range testCases {
+		require.Equal(t, expected, stemEnglish(word), word)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"encoding/binary"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+// EncodeTSVector appends the binary encoding of the given vector to
+// appendTo. The encoding is the number of lexemes, followed by each lexeme
+// and its positions, all lengths and positions being uvarints. The weight of
+// a position is stored in the two lowest bits of the position.
+func EncodeTSVector(appendTo []byte, v TSVector) []byte {
+	appendTo = appendUvarint(appendTo, uint64(len(v)))
+	for _, t := range v {
+		appendTo = appendUvarint(appendTo, uint64(len(t.lexeme)))
+		appendTo = append(appendTo, t.lexeme...)
+		appendTo = appendUvarint(appendTo, uint64(len(t.positions)))
+		for _, p := range t.positions {
+			appendTo = appendUvarint(appendTo, uint64(p.position)<<2|uint64(p.weight))
+		}
+	}
+	return appendTo
+}
+
+// DecodeTSVector decodes a vector encoded by EncodeTSVector.
+func DecodeTSVector(b []byte) (TSVector, error) {
+	d := decoder{b: b}
+	n := d.readUvarint()
+	var v TSVector
+	if n > uint64(len(d.b)) {
+		// Every lexeme takes at least one byte.
+		d.err = errTruncated
+	} else if n > 0 {
+		v = make(TSVector, 0, n)
+	}
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		t := tsTerm{lexeme: d.readString()}
+		numPositions := d.readUvarint()
+		for j := uint64(0); j < numPositions && d.err == nil; j++ {
+			p := d.readUvarint()
+			t.positions = append(t.positions, tsPosition{
+				position: uint16(p >> 2),
+				weight:   tsWeight(p & 3),
+			})
+		}
+		v = append(v, t)
+	}
+	if err := d.finish(); err != nil {
+		return nil, errors.Wrap(err, "decoding tsvector")
+	}
+	return v, nil
+}
+
+// EncodeTSQuery appends the binary encoding of the given query to appendTo.
+// The nodes of the query are encoded in prefix order, each node being its
+// operator followed by its lexeme, flags and weights for leaves, or by its
+// distance for phrase operators. An empty query is encoded as no bytes.
+func EncodeTSQuery(appendTo []byte, q TSQuery) []byte {
+	if q.root == nil {
+		return appendTo
+	}
+	return q.root.encode(appendTo)
+}
+
+// encode appends the binary encoding of the subtree rooted at n.
+func (n *tsNode) encode(appendTo []byte) []byte {
+	appendTo = append(appendTo, byte(n.op))
+	switch n.op {
+	case invalidOp:
+		appendTo = appendUvarint(appendTo, uint64(len(n.lexeme)))
+		appendTo = append(appendTo, n.lexeme...)
+		var prefix byte
+		if n.prefix {
+			prefix = 1
+		}
+		return append(appendTo, prefix, byte(n.weights))
+	case not:
+		return n.l.encode(appendTo)
+	case followedBy:
+		appendTo = appendUvarint(appendTo, uint64(n.followedN))
+	}
+	appendTo = n.l.encode(appendTo)
+	return n.r.encode(appendTo)
+}
+
+// DecodeTSQuery decodes a query encoded by EncodeTSQuery.
+func DecodeTSQuery(b []byte) (TSQuery, error) {
+	if len(b) == 0 {
+		return TSQuery{}, nil
+	}
+	d := decoder{b: b}
+	root := d.readNode()
+	if err := d.finish(); err != nil {
+		return TSQuery{}, errors.Wrap(err, "decoding tsquery")
+	}
+	return TSQuery{root: root}, nil
+}
+
+// appendUvarint appends the uvarint encoding of x to appendTo.
+func appendUvarint(appendTo []byte, x uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], x)
+	return append(appendTo, buf[:n]...)
+}
+
+// decoder decodes the binary encodings of vectors and queries. The first
+// error encountered is kept in err, after which the decoded values are
+// meaningless.
+type decoder struct {
+	b   []byte
+	err error
+}
+
+var errTruncated = errors.New("unexpected end of input")
+
+func (d *decoder) readByte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.b) == 0 {
+		d.err = errTruncated
+		return 0
+	}
+	c := d.b[0]
+	d.b = d.b[1:]
+	return c
+}
+
+func (d *decoder) readUvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(d.b)
+	if n <= 0 {
+		d.err = errTruncated
+		return 0
+	}
+	d.b = d.b[n:]
+	return x
+}
+
+func (d *decoder) readString() string {
+	n := d.readUvarint()
+	if d.err != nil {
+		return ""
+	}
+	if uint64(len(d.b)) < n {
+		d.err = errTruncated
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+func (d *decoder) readNode() *tsNode {
+	n := &tsNode{op: tsOperator(d.readByte())}
+	if d.err != nil {
+		return nil
+	}
+	switch n.op {
+	case invalidOp:
+		n.lexeme = d.readString()
+		n.prefix = d.readByte() == 1
+		n.weights = weightMask(d.readByte())
+		return n
+	case not:
+		n.l = d.readNode()
+		return n
+	case followedBy:
+		n.followedN = uint16(d.readUvarint())
+	case and, or:
+	default:
+		d.err = errors.Newf("unknown operator %d", n.op)
+		return nil
+	}
+	n.l = d.readNode()
+	n.r = d.readNode()
+	return n
+}
+
+// finish returns the error encountered while decoding, if any, or an error
+// if not all the input was consumed.
+func (d *decoder) finish() error {
+	if d.err == nil && len(d.b) > 0 {
+		return errors.Newf("%d trailing bytes", len(d.b))
+	}
+	return d.err
+}
+
+// EncodeInvertedIndexKeys returns the inverted index keys of the given
+// vector, one per lexeme, each prefixed by inKey. Positions and weights are
+// not part of the keys.
+func EncodeInvertedIndexKeys(inKey []byte, v TSVector) ([][]byte, error) {
+	keys := make([][]byte, len(v))
+	for i := range v {
+		key := make([]byte, len(inKey), len(inKey)+len(v[i].lexeme)+3)
+		copy(key, inKey)
+		keys[i] = encoding.EncodeStringAscending(key, v[i].lexeme)
+	}
+	return keys, nil
+}
+
+// errNotIndexable is returned by GetInvertedExpr for queries which cannot be
+// evaluated using an inverted index.
+var errNotIndexable = errors.New("tsquery cannot be index-accelerated")
+
+// GetInvertedExpr returns the inverted expression which must be evaluated on
+// an inverted index of tsvectors to find the vectors which may match the
+// query. The expression is tight if the index is enough to evaluate the
+// query, i.e. when the query has no phrase operators nor weight
+// restrictions. Queries whose matches cannot be constrained by the lexemes
+// they contain, e.g. !'fat', cannot be index-accelerated, in which case an
+// error is returned.
+func (q TSQuery) GetInvertedExpr() (inverted.Expression, error) {
+	if q.root == nil {
+		// An empty query does not match anything.
+		return &inverted.SpanExpression{Tight: true, Unique: true}, nil
+	}
+	expr := q.root.invertedExpr()
+	if expr == nil {
+		return nil, errNotIndexable
+	}
+	return expr, nil
+}
+
+// invertedExpr returns the inverted expression of the node, or nil if the
+// node does not constrain the matching vectors.
+func (n *tsNode) invertedExpr() inverted.Expression {
+	switch n.op {
+	case invalidOp:
+		key := encoding.EncodeStringAscending(nil, n.lexeme)
+		tight := n.weights == 0
+		if !n.prefix {
+			expr := inverted.ExprForSpan(inverted.MakeSingleValSpan(key), tight)
+			expr.Unique = true
+			return expr
+		}
+		// Strip the terminator of the encoded string to find all the keys
+		// having the lexeme as a prefix.
+		prefix := key[:len(key)-2]
+		return inverted.ExprForSpan(inverted.Span{
+			Start: inverted.EncVal(prefix),
+			End:   inverted.EncVal(roachpb.Key(prefix).PrefixEnd()),
+		}, tight)
+	case not:
+		return nil
+	case and, followedBy:
+		l, r := n.l.invertedExpr(), n.r.invertedExpr()
+		var expr inverted.Expression
+		switch {
+		case l == nil && r == nil:
+			return nil
+		case l == nil:
+			expr = r
+			expr.SetNotTight()
+		case r == nil:
+			expr = l
+			expr.SetNotTight()
+		default:
+			expr = inverted.And(l, r)
+		}
+		if n.op == followedBy {
+			// The positions of the lexemes must be checked.
+			expr.SetNotTight()
+		}
+		return expr
+	case or:
+		l, r := n.l.invertedExpr(), n.r.invertedExpr()
+		if l == nil || r == nil {
+			return nil
+		}
+		return inverted.Or(l, r)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/inverted"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvertedIndexEncoding(t *testing.T) {
+	vectors := []string{
+		`'fat':1 'cat':2`,
+		`'fat':1 'rat':2`,
+		`'supernova':1 'cat':2`,
+		`'dog'`,
+		``,
+	}
+	testCases := []struct {
+		query string
+		tight bool
+		// indexable is false if the query cannot be index-accelerated.
+		indexable bool
+	}{
+		{`fat`, true, true},
+		{`fat & cat`, true, true},
+		{`fat | dog`, true, true},
+		{`super:*`, true, true},
+		{`fat:A`, false, true},
+		{`fat <-> cat`, false, true},
+		{`fat & !cat`, false, true},
+		{`!cat`, false, false},
+		{`fat | !cat`, false, false},
+		{``, true, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			expr, err := q.GetInvertedExpr()
+			if !tc.indexable {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.tight, expr.IsTight())
+			spanExpr, ok := expr.(*inverted.SpanExpression)
+			require.True(t, ok)
+
+			for _, s := range vectors {
+				v, err := ParseTSVector(s)
+				require.NoError(t, err)
+				keys, err := EncodeInvertedIndexKeys(nil /* inKey */, v)
+				require.NoError(t, err)
+				matches, err := spanExpr.ContainsKeys(keys)
+				require.NoError(t, err)
+				if EvalTSQuery(q, v) {
+					// The index must find all the matching vectors.
+					require.True(t, matches, s)
+				} else if tc.tight {
+					// A tight expression must not find false positives.
+					require.False(t, matches, s)
+				}
+			}
+		})
+	}
+}
+
+func TestTSVectorEncoding(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`'dog'`,
+		`'fat':1 'cat':2,5`,
+		`'a':1A,2B,3C,4 'b':16383A 'c d':7`,
+	} {
+		t.Run(s, func(t *testing.T) {
+			v, err := ParseTSVector(s)
+			require.NoError(t, err)
+			encoded := EncodeTSVector(nil, v)
+			decoded, err := DecodeTSVector(encoded)
+			require.NoError(t, err)
+			require.Equal(t, v, decoded)
+			require.Equal(t, v.String(), decoded.String())
+
+			// Truncated encodings are rejected.
+			for i := 0; i < len(encoded); i++ {
+				_, err := DecodeTSVector(encoded[:i])
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestTSQueryEncoding(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`fat`,
+		`fat:AB & !(rat | cat:*)`,
+		`fat <-> cat <3> rat`,
+		`!!fat | super:*B`,
+	} {
+		t.Run(s, func(t *testing.T) {
+			q, err := ParseTSQuery(s)
+			require.NoError(t, err)
+			encoded := EncodeTSQuery(nil, q)
+			decoded, err := DecodeTSQuery(encoded)
+			require.NoError(t, err)
+			require.Equal(t, q, decoded)
+			require.Equal(t, q.String(), decoded.String())
+
+			// Truncated encodings are rejected, except the empty encoding
+			// which is the empty query.
+			for i := 1; i < len(encoded); i++ {
+				_, err := DecodeTSQuery(encoded[:i])
+				require.Error(t, err)
+			}
+		})
+	}
+
+	_, err := DecodeTSQuery([]byte{42})
+	require.Error(t, err)
+}
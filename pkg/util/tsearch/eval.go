@@ -0,0 +1,184 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"sort"
+	"strings"
+)
+
+// EvalTSQuery returns whether the given vector matches the given query, which
+// is the result of the tsvector @@ tsquery operator.
+//
+// Phrase operators are evaluated using the positions of the lexemes in the
+// vector. When those are not available, e.g. because the vector was stripped,
+// phrase operators degrade to &, like in PostgreSQL.
+func EvalTSQuery(q TSQuery, v TSVector) bool {
+	if q.root == nil {
+		return false
+	}
+	e := evaluator{v: v}
+	return e.eval(q.root)
+}
+
+type evaluator struct {
+	v TSVector
+	// maxPos is the largest position of the vector, computed lazily to
+	// evaluate negations in phrases.
+	maxPos int
+}
+
+// matchingTerms returns the terms of the vector matched by the given leaf.
+func (e *evaluator) matchingTerms(leaf *tsNode) []tsTerm {
+	i := sort.Search(len(e.v), func(i int) bool { return e.v[i].lexeme >= leaf.lexeme })
+	if !leaf.prefix {
+		if i < len(e.v) && e.v[i].lexeme == leaf.lexeme {
+			return e.v[i : i+1]
+		}
+		return nil
+	}
+	j := i
+	for j < len(e.v) && strings.HasPrefix(e.v[j].lexeme, leaf.lexeme) {
+		j++
+	}
+	return e.v[i:j]
+}
+
+func (e *evaluator) eval(n *tsNode) bool {
+	switch n.op {
+	case invalidOp:
+		for _, t := range e.matchingTerms(n) {
+			if len(t.positions) == 0 {
+				// Weight restrictions are ignored when positions are not
+				// available.
+				return true
+			}
+			for _, p := range t.positions {
+				if n.weights.matches(p.weight) {
+					return true
+				}
+			}
+		}
+		return false
+	case not:
+		return !e.eval(n.l)
+	case and:
+		return e.eval(n.l) && e.eval(n.r)
+	case or:
+		return e.eval(n.l) || e.eval(n.r)
+	case followedBy:
+		positions, ok := e.phrasePositions(n)
+		if !ok {
+			return e.eval(n.l) && e.eval(n.r)
+		}
+		return len(positions) > 0
+	}
+	return false
+}
+
+// phrasePositions returns the sorted positions at which the given expression
+// matches, i.e. the positions of its last lexeme. It returns ok=false if the
+// positions are not available for some of the matched lexemes.
+func (e *evaluator) phrasePositions(n *tsNode) (positions []int, ok bool) {
+	switch n.op {
+	case invalidOp:
+		for _, t := range e.matchingTerms(n) {
+			if len(t.positions) == 0 {
+				return nil, false
+			}
+			for _, p := range t.positions {
+				if n.weights.matches(p.weight) {
+					positions = append(positions, int(p.position))
+				}
+			}
+		}
+		sort.Ints(positions)
+		return positions, true
+	case not:
+		excluded, ok := e.phrasePositions(n.l)
+		if !ok {
+			return nil, false
+		}
+		if e.maxPos == 0 {
+			for _, t := range e.v {
+				for _, p := range t.positions {
+					if int(p.position) > e.maxPos {
+						e.maxPos = int(p.position)
+					}
+				}
+			}
+		}
+		for pos, i := 1, 0; pos <= e.maxPos; pos++ {
+			for i < len(excluded) && excluded[i] < pos {
+				i++
+			}
+			if i < len(excluded) && excluded[i] == pos {
+				continue
+			}
+			positions = append(positions, pos)
+		}
+		return positions, true
+	case and, or, followedBy:
+		l, ok := e.phrasePositions(n.l)
+		if !ok {
+			return nil, false
+		}
+		r, ok := e.phrasePositions(n.r)
+		if !ok {
+			return nil, false
+		}
+		switch n.op {
+		case and:
+			return intersectPositions(l, r, 0 /* offset */), true
+		case or:
+			return unionPositions(l, r), true
+		default:
+			return intersectPositions(l, r, int(n.followedN)), true
+		}
+	}
+	return nil, true
+}
+
+// intersectPositions returns the positions p of r such that p-offset is in l.
+func intersectPositions(l, r []int, offset int) []int {
+	var res []int
+	i := 0
+	for _, p := range r {
+		for i < len(l) && l[i] < p-offset {
+			i++
+		}
+		if i < len(l) && l[i] == p-offset {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// unionPositions returns the sorted union of the given positions.
+func unionPositions(l, r []int) []int {
+	res := make([]int, 0, len(l)+len(r))
+	i, j := 0, 0
+	for i < len(l) || j < len(r) {
+		switch {
+		case j == len(r) || (i < len(l) && l[i] < r[j]):
+			res = append(res, l[i])
+			i++
+		case i == len(l) || r[j] < l[i]:
+			res = append(res, r[j])
+			j++
+		default:
+			res = append(res, l[i])
+			i++
+			j++
+		}
+	}
+	return res
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "math/rand"
+
+// randomLexeme returns a short random lexeme of lowercase letters, so that
+// random vectors and queries are likely to share lexemes.
+func randomLexeme(rng *rand.Rand) string {
+	b := make([]byte, 1+rng.Intn(3))
+	for i := range b {
+		b[i] = byte('a' + rng.Intn(5))
+	}
+	return string(b)
+}
+
+// RandomTSVector returns a random TSVector for testing.
+func RandomTSVector(rng *rand.Rand) TSVector {
+	v := make(TSVector, rng.Intn(10))
+	for i := range v {
+		v[i].lexeme = randomLexeme(rng)
+		for j := rng.Intn(3); j > 0; j-- {
+			v[i].positions = append(v[i].positions, tsPosition{
+				position: uint16(1 + rng.Intn(100)),
+				weight:   tsWeight(rng.Intn(4)),
+			})
+		}
+	}
+	return v.normalize()
+}
+
+// RandomTSQuery returns a random non-empty TSQuery for testing.
+func RandomTSQuery(rng *rand.Rand) TSQuery {
+	return TSQuery{root: randomTSNode(rng, 3 /* depth */)}
+}
+
+func randomTSNode(rng *rand.Rand, depth int) *tsNode {
+	if depth == 0 || rng.Intn(3) == 0 {
+		return &tsNode{
+			lexeme:  randomLexeme(rng),
+			prefix:  rng.Intn(5) == 0,
+			weights: weightMask(rng.Intn(16)),
+		}
+	}
+	switch op := tsOperator(1 + rng.Intn(4)); op {
+	case not:
+		return &tsNode{op: not, l: randomTSNode(rng, depth-1)}
+	case followedBy:
+		return &tsNode{
+			op:        followedBy,
+			followedN: uint16(rng.Intn(3)),
+			l:         randomTSNode(rng, depth-1),
+			r:         randomTSNode(rng, depth-1),
+		}
+	default:
+		return &tsNode{op: op, l: randomTSNode(rng, depth-1), r: randomTSNode(rng, depth-1)}
+	}
+}
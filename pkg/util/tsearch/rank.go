@@ -0,0 +1,205 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// DefaultWeights are the weights of the D, C, B and A positions used by Rank
+// when none are specified.
+var DefaultWeights = [4]float32{0.1, 0.2, 0.4, 1.0}
+
+// The normalization options of Rank, which can be combined.
+const (
+	// RankNormLogLength divides the rank by 1 + the logarithm of the length of
+	// the document.
+	RankNormLogLength = 0x01
+	// RankNormLength divides the rank by the length of the document.
+	RankNormLength = 0x02
+	// RankNormExtDist divides the rank by the mean harmonic distance between
+	// extents. It is only used by ts_rank_cd, and is ignored by Rank.
+	RankNormExtDist = 0x04
+	// RankNormUniq divides the rank by the number of unique words of the
+	// document.
+	RankNormUniq = 0x08
+	// RankNormLogUniq divides the rank by 1 + the logarithm of the number of
+	// unique words of the document.
+	RankNormLogUniq = 0x10
+	// RankNormRDivRPlus1 divides the rank by itself + 1.
+	RankNormRDivRPlus1 = 0x20
+)
+
+// Rank ranks how well the given vector matches the given query, based on the
+// frequency of the matching lexemes and, for queries requiring all their
+// lexemes, on how close to each other they appear. The weights of the D, C, B
+// and A positions, in that order, are given, as well as the normalization
+// options. The computation follows the one of the ts_rank function of
+// PostgreSQL.
+func Rank(weights [4]float32, v TSVector, q TSQuery, normalization int) (float32, error) {
+	for _, w := range weights {
+		if w > 1 {
+			return 0, pgerror.New(pgcode.InvalidParameterValue, "weight out of range")
+		}
+	}
+	if normalization < 0 {
+		return 0, pgerror.New(pgcode.InvalidParameterValue, "normalization must be >= 0")
+	}
+	if len(v) == 0 || q.root == nil {
+		return 0, nil
+	}
+	for i := range weights {
+		if weights[i] < 0 {
+			weights[i] = DefaultWeights[i]
+		}
+	}
+	r := ranker{weights: weights, v: v, items: q.leaves()}
+	var res float64
+	if q.root.op == and || q.root.op == followedBy {
+		res = r.rankAnd()
+	} else {
+		res = r.rankOr()
+	}
+	if res < 0 {
+		res = 1e-20
+	}
+	if normalization&RankNormLogLength != 0 {
+		res /= math.Log(float64(v.numPositions()+1)) / math.Log(2.0)
+	}
+	if normalization&RankNormLength != 0 {
+		if l := v.numPositions(); l > 0 {
+			res /= float64(l)
+		}
+	}
+	if normalization&RankNormUniq != 0 {
+		res /= float64(len(v))
+	}
+	if normalization&RankNormLogUniq != 0 {
+		res /= math.Log(float64(len(v)+1)) / math.Log(2.0)
+	}
+	if normalization&RankNormRDivRPlus1 != 0 {
+		res /= res + 1
+	}
+	return float32(res), nil
+}
+
+// numPositions returns the length of the document of the vector, which is
+// the number of positions of its lexemes, counting 1 for the lexemes without
+// positions.
+func (v TSVector) numPositions() int {
+	n := 0
+	for _, t := range v {
+		if len(t.positions) == 0 {
+			n++
+		} else {
+			n += len(t.positions)
+		}
+	}
+	return n
+}
+
+type ranker struct {
+	weights [4]float32
+	v       TSVector
+	// items are the distinct leaves of the query.
+	items []*tsNode
+}
+
+func (r *ranker) weight(p tsPosition) float64 {
+	return float64(r.weights[p.weight])
+}
+
+// termPositions returns the positions of the given term, using the given
+// position for terms without positions.
+func termPositions(t tsTerm, missing tsPosition) []tsPosition {
+	if len(t.positions) == 0 {
+		return []tsPosition{missing}
+	}
+	return t.positions
+}
+
+// wordDistance returns the factor applied to the rank of two lexemes
+// according to the distance between them.
+func wordDistance(dist int) float64 {
+	if dist > 100 {
+		return 1e-30
+	}
+	return 1.0 / (1.005 + 0.05*math.Exp(float64(dist)/1.5-2))
+}
+
+// rankOr ranks the vector according to the frequency and weight of the
+// positions of each of the lexemes of the query.
+func (r *ranker) rankOr() float64 {
+	e := evaluator{v: r.v}
+	var res float64
+	for _, item := range r.items {
+		for _, t := range e.matchingTerms(item) {
+			var resj, wjm float64 = 0, -1
+			jm := 0
+			for j, p := range termPositions(t, tsPosition{}) {
+				w := r.weight(p)
+				resj += w / float64((j+1)*(j+1))
+				if w > wjm {
+					wjm, jm = w, j
+				}
+			}
+			// The sum of 1/i^2 for i >= 1 converges to pi^2/6.
+			res += (wjm + resj - wjm/float64((jm+1)*(jm+1))) / 1.64493406685
+		}
+	}
+	if len(r.items) > 0 {
+		res /= float64(len(r.items))
+	}
+	return res
+}
+
+// rankAnd ranks the vector according to the distances between the positions
+// of the different lexemes of the query.
+func (r *ranker) rankAnd() float64 {
+	if len(r.items) < 2 {
+		return r.rankOr()
+	}
+	e := evaluator{v: r.v}
+	missing := tsPosition{position: maxPosition}
+	positions := make([][]tsPosition, len(r.items))
+	res := -1.0
+	for i, item := range r.items {
+		for _, t := range e.matchingTerms(item) {
+			positions[i] = termPositions(t, missing)
+			for _, p := range positions[i] {
+				for k := 0; k < i; k++ {
+					for _, q := range positions[k] {
+						dist := int(p.position) - int(q.position)
+						if dist < 0 {
+							dist = -dist
+						}
+						if dist == 0 {
+							if len(t.positions) > 0 && q != missing {
+								continue
+							}
+							dist = maxPosition + 1
+						}
+						curw := math.Sqrt(r.weight(p) * r.weight(q) * wordDistance(dist))
+						if res < 0 {
+							res = curw
+						} else {
+							res = 1.0 - (1.0-res)*(1.0-curw)
+						}
+					}
+				}
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRank(t *testing.T) {
+	testCases := []struct {
+		document      string
+		query         string
+		weights       [4]float32
+		normalization int
+		expected      float32
+	}{
+		{"a fat cat sat on a mat", "cat", DefaultWeights, 0, 0.0607927},
+		{"a fat cat sat on a mat", "dog", DefaultWeights, 0, 0},
+		{"a fat cat sat on a mat", "cat | dog", DefaultWeights, 0, 0.0303964},
+		{"a fat cat sat on a mat and a cat ate", "cat", DefaultWeights, 0, 0.0759909},
+		{"a fat cat sat on a mat", "fat & cat", DefaultWeights, 0, 0.0991032},
+		{"a fat cat sat on a mat", "fat <-> cat", DefaultWeights, 0, 0.0991032},
+		{"a fat cat sat on a mat", "fat & mat", DefaultWeights, 0, 0.0914900},
+		{"a fat cat sat on a mat", "cat", [4]float32{1, 1, 1, 1}, 0, 0.607927},
+		{"a fat cat sat on a mat", "cat", DefaultWeights, RankNormLength, 0.0151982},
+		{"a fat cat sat on a mat", "cat", DefaultWeights, RankNormRDivRPlus1, 0.0573088},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.document+"/"+tc.query, func(t *testing.T) {
+			v, err := ToTSVector("english", tc.document)
+			require.NoError(t, err)
+			q, err := ToTSQuery("english", tc.query)
+			require.NoError(t, err)
+			rank, err := Rank(tc.weights, v, q, tc.normalization)
+			require.NoError(t, err)
+			require.InDelta(t, tc.expected, rank, 1e-6)
+		})
+	}
+
+	v, err := ToTSVector("english", "cat")
+	require.NoError(t, err)
+	q, err := ToTSQuery("english", "cat")
+	require.NoError(t, err)
+	_, err = Rank([4]float32{0.1, 0.2, 0.4, 2}, v, q, 0)
+	require.Error(t, err)
+	_, err = Rank(DefaultWeights, v, q, -1)
+	require.Error(t, err)
+}
@@ -0,0 +1,342 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import "bytes"
+
+// stemEnglish returns the stem of the given lowercase ASCII word, according
+// to the Snowball English (Porter2) stemming algorithm, which is the one used
+// by the english configuration of PostgreSQL. See
+// https://snowballstem.org/algorithms/english/stemmer.html.
+func stemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	if stem, ok := englishExceptions[word]; ok {
+		return stem
+	}
+	w := []byte(word)
+	// Mark the y's which are consonants as Y.
+	if w[0] == 'y' {
+		w[0] = 'Y'
+	}
+	for i := 1; i < len(w); i++ {
+		if w[i] == 'y' && isVowel(w[i-1]) {
+			w[i] = 'Y'
+		}
+	}
+	r1, r2 := stemRegions(w)
+
+	w = stemStep1a(w)
+	if _, ok := englishInvariantsAfterStep1a[string(w)]; ok {
+		return string(w)
+	}
+	w = stemStep1b(w, r1)
+	w = stemStep1c(w)
+	w = stemStep2(w, r1)
+	w = stemStep3(w, r1, r2)
+	w = stemStep4(w, r2)
+	w = stemStep5(w, r1, r2)
+	return string(bytes.ReplaceAll(w, []byte("Y"), []byte("y")))
+}
+
+// englishExceptions are the words which are not stemmed by the algorithm.
+var englishExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishInvariantsAfterStep1a are the words which are left as is after step
+// 1a.
+var englishInvariantsAfterStep1a = makeStopWords(
+	"inning", "outing", "canning", "herring", "earring", "proceed", "exceed", "succeed",
+)
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+// stemRegions returns the start of the regions R1 and R2 of the word. R1 is
+// the region after the first non-vowel following a vowel, and R2 is the
+// region after the first non-vowel following a vowel in R1.
+func stemRegions(w []byte) (r1, r2 int) {
+	r1 = len(w)
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if bytes.HasPrefix(w, []byte(prefix)) {
+			r1 = len(prefix)
+			break
+		}
+	}
+	if r1 == len(w) {
+		for i := 1; i < len(w); i++ {
+			if !isVowel(w[i]) && isVowel(w[i-1]) {
+				r1 = i + 1
+				break
+			}
+		}
+	}
+	r2 = len(w)
+	for i := r1 + 1; i < len(w); i++ {
+		if !isVowel(w[i]) && isVowel(w[i-1]) {
+			r2 = i + 1
+			break
+		}
+	}
+	return r1, r2
+}
+
+// endsWithShortSyllable returns whether the word ends with a short syllable,
+// i.e. a vowel followed by a non-vowel other than w, x or Y and preceded by
+// a non-vowel, or a vowel at the beginning of the word followed by a
+// non-vowel.
+func endsWithShortSyllable(w []byte) bool {
+	n := len(w)
+	if n == 2 {
+		return isVowel(w[0]) && !isVowel(w[1])
+	}
+	if n < 3 {
+		return false
+	}
+	last := w[n-1]
+	return !isVowel(w[n-3]) && isVowel(w[n-2]) && !isVowel(last) &&
+		last != 'w' && last != 'x' && last != 'Y'
+}
+
+// isShortWord returns whether the word ends with a short syllable and R1 is
+// empty.
+func isShortWord(w []byte, r1 int) bool {
+	return r1 >= len(w) && endsWithShortSyllable(w)
+}
+
+func containsVowel(w []byte) bool {
+	for _, c := range w {
+		if isVowel(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func endsWithDouble(w []byte) bool {
+	n := len(w)
+	if n < 2 || w[n-1] != w[n-2] {
+		return false
+	}
+	switch w[n-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	}
+	return false
+}
+
+// longestSuffix returns the longest of the given suffixes which ends the word,
+// or the empty string.
+func longestSuffix(w []byte, suffixes ...string) string {
+	var res string
+	for _, s := range suffixes {
+		if len(s) > len(res) && bytes.HasSuffix(w, []byte(s)) {
+			res = s
+		}
+	}
+	return res
+}
+
+// replaceSuffix replaces the given suffix of the word by repl.
+func replaceSuffix(w []byte, suffix, repl string) []byte {
+	return append(w[:len(w)-len(suffix)], repl...)
+}
+
+func stemStep1a(w []byte) []byte {
+	switch s := longestSuffix(w, "sses", "ied", "ies", "us", "ss", "s"); s {
+	case "sses":
+		return replaceSuffix(w, s, "ss")
+	case "ied", "ies":
+		if len(w) > 4 {
+			return replaceSuffix(w, s, "i")
+		}
+		return replaceSuffix(w, s, "ie")
+	case "s":
+		if containsVowel(w[:len(w)-2]) {
+			return w[:len(w)-1]
+		}
+	}
+	return w
+}
+
+func stemStep1b(w []byte, r1 int) []byte {
+	switch s := longestSuffix(w, "eed", "eedly", "ed", "edly", "ing", "ingly"); s {
+	case "eed", "eedly":
+		if len(w)-len(s) >= r1 {
+			return replaceSuffix(w, s, "ee")
+		}
+	case "ed", "edly", "ing", "ingly":
+		stem := w[:len(w)-len(s)]
+		if !containsVowel(stem) {
+			return w
+		}
+		w = stem
+		switch {
+		case longestSuffix(w, "at", "bl", "iz") != "":
+			return append(w, 'e')
+		case endsWithDouble(w):
+			return w[:len(w)-1]
+		case isShortWord(w, r1):
+			return append(w, 'e')
+		}
+	}
+	return w
+}
+
+func stemStep1c(w []byte) []byte {
+	n := len(w)
+	if n > 2 && (w[n-1] == 'y' || w[n-1] == 'Y') && !isVowel(w[n-2]) {
+		w[n-1] = 'i'
+	}
+	return w
+}
+
+var step2Suffixes = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+var step2SuffixList = mapKeys(step2Suffixes)
+
+func stemStep2(w []byte, r1 int) []byte {
+	s := longestSuffix(w, step2SuffixList...)
+	if s == "" || len(w)-len(s) < r1 {
+		return w
+	}
+	switch s {
+	case "ogi":
+		if len(w) < 4 || w[len(w)-4] != 'l' {
+			return w
+		}
+	case "li":
+		if len(w) < 3 {
+			return w
+		}
+		switch w[len(w)-3] {
+		case 'c', 'd', 'e', 'g', 'h', 'k', 'm', 'n', 'r', 't':
+		default:
+			return w
+		}
+	}
+	return replaceSuffix(w, s, step2Suffixes[s])
+}
+
+var step3Suffixes = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+var step3SuffixList = mapKeys(step3Suffixes)
+
+func stemStep3(w []byte, r1, r2 int) []byte {
+	s := longestSuffix(w, step3SuffixList...)
+	if s == "" || len(w)-len(s) < r1 {
+		return w
+	}
+	if s == "ative" && len(w)-len(s) < r2 {
+		return w
+	}
+	return replaceSuffix(w, s, step3Suffixes[s])
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func stemStep4(w []byte, r2 int) []byte {
+	s := longestSuffix(w, step4Suffixes...)
+	if s == "" || len(w)-len(s) < r2 {
+		return w
+	}
+	if s == "ion" {
+		if n := len(w) - len(s); n == 0 || (w[n-1] != 's' && w[n-1] != 't') {
+			return w
+		}
+	}
+	return w[:len(w)-len(s)]
+}
+
+func stemStep5(w []byte, r1, r2 int) []byte {
+	n := len(w)
+	switch w[n-1] {
+	case 'e':
+		if n-1 >= r2 || (n-1 >= r1 && !endsWithShortSyllable(w[:n-1])) {
+			return w[:n-1]
+		}
+	case 'l':
+		if n-1 >= r2 && w[n-2] == 'l' {
+			return w[:n-1]
+		}
+	}
+	return w
+}
+
+func mapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,455 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// maxPhraseDistance is the largest distance allowed in a <N> operator.
+const maxPhraseDistance = 16384
+
+// tsOperator is an operator of a tsquery.
+type tsOperator int
+
+const (
+	invalidOp tsOperator = iota
+	// and is the & operator.
+	and
+	// or is the | operator.
+	or
+	// not is the ! operator.
+	not
+	// followedBy is the <-> or <N> operator.
+	followedBy
+)
+
+// priority returns the precedence of the operator, the higher binding
+// tighter.
+func (o tsOperator) priority() int {
+	switch o {
+	case or:
+		return 1
+	case and:
+		return 2
+	case followedBy:
+		return 3
+	case not:
+		return 4
+	}
+	return 5
+}
+
+// weightMask is a set of weights, as a bitmask indexed by tsWeight. An empty
+// mask matches any weight.
+type weightMask uint8
+
+func (m weightMask) matches(w tsWeight) bool {
+	return m == 0 || m&(1<<w) != 0
+}
+
+// tsNode is a node of a tsquery expression tree. A node is either a leaf,
+// which matches a lexeme, or an operator applied to one or two children.
+type tsNode struct {
+	// The following fields are set for leaves (op == invalidOp).
+	lexeme string
+	// prefix is set if the leaf matches all the lexemes starting with lexeme.
+	prefix bool
+	// weights restricts the positions matched by the leaf to the given
+	// weights.
+	weights weightMask
+
+	op tsOperator
+	// followedN is the distance of a followedBy operator.
+	followedN uint16
+	// l is the operand of the not operator, and the left operand of binary
+	// operators.
+	l *tsNode
+	// r is the right operand of binary operators.
+	r *tsNode
+}
+
+// TSQuery is a full text search query, which is matched against a TSVector.
+// It is a tree of lexemes combined with the boolean operators & (and), |
+// (or), ! (not) and the phrase operator <-> (followed by).
+type TSQuery struct {
+	root *tsNode
+}
+
+// ParseTSQuery parses the text representation of a tsquery, e.g.
+// 'fat' & ('rat' | 'cat':*). Like for tsvectors, no normalization is
+// performed on the lexemes.
+func ParseTSQuery(s string) (TSQuery, error) {
+	return parseTSQuery(s, nil /* normalize */)
+}
+
+// parseTSQuery parses the text representation of a tsquery. If normalize is
+// not nil, it is called on each operand to produce the corresponding
+// expression, which is nil if the operand is dropped, e.g. because it is a
+// stop word.
+func parseTSQuery(s string, normalize func(leaf *tsNode) *tsNode) (TSQuery, error) {
+	p := tsQueryParser{tsParser: tsParser{input: s, typ: "tsquery"}, normalize: normalize}
+	p.skipSpaces()
+	if p.eof() {
+		return TSQuery{}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return TSQuery{}, err
+	}
+	p.skipSpaces()
+	if !p.eof() {
+		return TSQuery{}, p.syntaxError()
+	}
+	if normalize != nil {
+		root, _, _ = cleanup(root)
+	}
+	return TSQuery{root: root}, nil
+}
+
+type tsQueryParser struct {
+	tsParser
+	normalize func(leaf *tsNode) *tsNode
+}
+
+// removedNode is the placeholder for operands dropped during normalization,
+// which preserves the shape of the tree until cleanup.
+var removedNode = &tsNode{}
+
+func (p *tsQueryParser) parseOr() (*tsNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if p.peek() != '|' {
+			return l, nil
+		}
+		p.pos++
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = &tsNode{op: or, l: l, r: r}
+	}
+}
+
+func (p *tsQueryParser) parseAnd() (*tsNode, error) {
+	l, err := p.parsePhrase()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if p.peek() != '&' {
+			return l, nil
+		}
+		p.pos++
+		r, err := p.parsePhrase()
+		if err != nil {
+			return nil, err
+		}
+		l = &tsNode{op: and, l: l, r: r}
+	}
+}
+
+func (p *tsQueryParser) parsePhrase() (*tsNode, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if p.peek() != '<' {
+			return l, nil
+		}
+		n, err := p.parseDistance()
+		if err != nil {
+			return nil, err
+		}
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = &tsNode{op: followedBy, followedN: n, l: l, r: r}
+	}
+}
+
+// parseDistance parses a <-> or <N> operator.
+func (p *tsQueryParser) parseDistance() (uint16, error) {
+	end := strings.IndexByte(p.input[p.pos:], '>')
+	if end < 0 {
+		return 0, p.syntaxError()
+	}
+	op := p.input[p.pos+1 : p.pos+end]
+	p.pos += end + 1
+	if op == "-" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(op)
+	if err != nil {
+		return 0, p.syntaxError()
+	}
+	if n < 0 || n > maxPhraseDistance {
+		return 0, pgerror.Newf(pgcode.InvalidParameterValue,
+			"distance in phrase operator must be an integer value between zero and %d inclusive",
+			maxPhraseDistance)
+	}
+	return uint16(n), nil
+}
+
+func (p *tsQueryParser) parseNot() (*tsNode, error) {
+	p.skipSpaces()
+	if p.peek() == '!' {
+		p.pos++
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &tsNode{op: not, l: n}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *tsQueryParser) parsePrimary() (*tsNode, error) {
+	p.skipSpaces()
+	if p.peek() == '(' {
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.peek() != ')' {
+			return nil, p.syntaxError()
+		}
+		p.pos++
+		return n, nil
+	}
+	lexeme, err := p.lexemeWithSpecials(":&|!()<")
+	if err != nil {
+		return nil, err
+	}
+	leaf := &tsNode{lexeme: lexeme}
+	if p.peek() == ':' {
+		p.pos++
+		for !p.eof() {
+			c := p.input[p.pos]
+			if c == '*' {
+				leaf.prefix = true
+			} else if w, ok := parseWeight(rune(c)); ok {
+				leaf.weights |= 1 << w
+			} else {
+				break
+			}
+			p.pos++
+		}
+	}
+	if p.normalize != nil {
+		if n := p.normalize(leaf); n != nil {
+			return n, nil
+		}
+		return removedNode, nil
+	}
+	return leaf, nil
+}
+
+// cleanup removes the placeholders of the operands dropped during
+// normalization from the given tree. Operators are simplified accordingly,
+// and the distance of phrase operators is extended to account for the
+// dropped operands, so that e.g. 'fat <-> the <-> rat' in the english
+// configuration becomes 'fat' <2> 'rat'. The returned lAdd and rAdd are the
+// distances to add to the phrase operators on the left and right of the
+// returned node, respectively.
+func cleanup(n *tsNode) (res *tsNode, lAdd, rAdd int) {
+	if n == removedNode {
+		return nil, 0, 0
+	}
+	switch n.op {
+	case invalidOp:
+		return n, 0, 0
+	case not:
+		l, _, _ := cleanup(n.l)
+		if l == nil {
+			return nil, 0, 0
+		}
+		return &tsNode{op: not, l: l}, 0, 0
+	case and, or:
+		l, _, _ := cleanup(n.l)
+		r, _, _ := cleanup(n.r)
+		if l == nil {
+			return r, 0, 0
+		}
+		if r == nil {
+			return l, 0, 0
+		}
+		return &tsNode{op: n.op, l: l, r: r}, 0, 0
+	case followedBy:
+		l, llAdd, lrAdd := cleanup(n.l)
+		r, rlAdd, rrAdd := cleanup(n.r)
+		dist := int(n.followedN)
+		switch {
+		case l == nil && r == nil:
+			return nil, llAdd + dist + rlAdd, 0
+		case l == nil:
+			return r, llAdd + dist + rlAdd, rrAdd
+		case r == nil:
+			return l, llAdd, lrAdd + dist + rlAdd
+		}
+		dist += lrAdd + rlAdd
+		if dist > maxPhraseDistance {
+			dist = maxPhraseDistance
+		}
+		return &tsNode{op: followedBy, followedN: uint16(dist), l: l, r: r}, llAdd, rrAdd
+	}
+	return n, 0, 0
+}
+
+// String returns the text representation of the query.
+func (q TSQuery) String() string {
+	if q.root == nil {
+		return ""
+	}
+	var b strings.Builder
+	q.root.format(&b, 0 /* parentPriority */, false /* rightOfPhrase */)
+	return b.String()
+}
+
+func (n *tsNode) format(b *strings.Builder, parentPriority int, rightOfPhrase bool) {
+	if n.op == invalidOp {
+		writeLexeme(b, n.lexeme)
+		if n.prefix || n.weights != 0 {
+			b.WriteByte(':')
+			if n.prefix {
+				b.WriteByte('*')
+			}
+			for w := weightA; ; w-- {
+				if n.weights&(1<<w) != 0 {
+					b.WriteString(w.String())
+				}
+				if w == weightD {
+					break
+				}
+			}
+		}
+		return
+	}
+	priority := n.op.priority()
+	parens := priority < parentPriority || (rightOfPhrase && n.op == followedBy)
+	if parens {
+		b.WriteString("( ")
+	}
+	switch n.op {
+	case not:
+		b.WriteByte('!')
+		n.l.format(b, priority, false /* rightOfPhrase */)
+	case and, or, followedBy:
+		n.l.format(b, priority, false /* rightOfPhrase */)
+		switch n.op {
+		case and:
+			b.WriteString(" & ")
+		case or:
+			b.WriteString(" | ")
+		case followedBy:
+			if n.followedN == 1 {
+				b.WriteString(" <-> ")
+			} else {
+				b.WriteString(" <" + strconv.Itoa(int(n.followedN)) + "> ")
+			}
+		}
+		n.r.format(b, priority, n.op == followedBy)
+	}
+	if parens {
+		b.WriteString(" )")
+	}
+}
+
+// NumNodes returns the number of lexemes and operators in the query.
+func (q TSQuery) NumNodes() int {
+	var count func(n *tsNode) int
+	count = func(n *tsNode) int {
+		if n == nil {
+			return 0
+		}
+		return 1 + count(n.l) + count(n.r)
+	}
+	return count(q.root)
+}
+
+// Compare compares two queries, returning -1, 0 or 1. The order is the one
+// of their text representation, which is only meant to be deterministic.
+func (q TSQuery) Compare(other TSQuery) int {
+	if c := q.NumNodes() - other.NumNodes(); c != 0 {
+		if c < 0 {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(q.String(), other.String())
+}
+
+// Size returns the approximate size of the query, in bytes.
+func (q TSQuery) Size() uintptr {
+	var size func(n *tsNode) uintptr
+	size = func(n *tsNode) uintptr {
+		if n == nil {
+			return 0
+		}
+		return 56 + uintptr(len(n.lexeme)) + size(n.l) + size(n.r)
+	}
+	return size(q.root)
+}
+
+// leaves returns the distinct lexemes of the query, as the leaves matching
+// them.
+func (q TSQuery) leaves() []*tsNode {
+	var res []*tsNode
+	seen := make(map[string]struct{})
+	var walk func(n *tsNode)
+	walk = func(n *tsNode) {
+		if n == nil {
+			return
+		}
+		if n.op == invalidOp {
+			if _, ok := seen[n.lexeme]; !ok {
+				seen[n.lexeme] = struct{}{}
+				res = append(res, n)
+			}
+			return
+		}
+		walk(n.l)
+		walk(n.r)
+	}
+	walk(q.root)
+	return res
+}
+
+// MakePhrase returns a query which matches when the first query matches
+// followed, at the given distance, by a match of the second one.
+func MakePhrase(l, r TSQuery, distance int) (TSQuery, error) {
+	if distance < 0 || distance > maxPhraseDistance {
+		return TSQuery{}, pgerror.Newf(pgcode.InvalidParameterValue,
+			"distance in phrase operator must be an integer value between zero and %d inclusive",
+			maxPhraseDistance)
+	}
+	if l.root == nil {
+		return r, nil
+	}
+	if r.root == nil {
+		return l, nil
+	}
+	return TSQuery{root: &tsNode{op: followedBy, followedN: uint16(distance), l: l.root, r: r.root}}, nil
+}
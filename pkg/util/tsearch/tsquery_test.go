@@ -0,0 +1,111 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTSQuery(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{``, ``},
+		{`fat`, `'fat'`},
+		{`fat & rat`, `'fat' & 'rat'`},
+		{`fat & (rat | cat)`, `'fat' & ( 'rat' | 'cat' )`},
+		{`fat & rat | cat`, `'fat' & 'rat' | 'cat'`},
+		{`fat | rat & cat`, `'fat' | 'rat' & 'cat'`},
+		{`!fat & !(rat | cat)`, `!'fat' & !( 'rat' | 'cat' )`},
+		{`!!fat`, `!!'fat'`},
+		{`fat <-> rat`, `'fat' <-> 'rat'`},
+		{`fat <2> rat <0> cat`, `'fat' <2> 'rat' <0> 'cat'`},
+		{`fat <-> (rat <-> cat)`, `'fat' <-> ( 'rat' <-> 'cat' )`},
+		{`fat <-> rat & cat`, `'fat' <-> 'rat' & 'cat'`},
+		{`(fat | rat) <-> cat`, `( 'fat' | 'rat' ) <-> 'cat'`},
+		{`super:*`, `'super':*`},
+		{`fat:ab & rat:*c`, `'fat':AB & 'rat':*C`},
+		{`'fat cat' & 'o''reilly'`, `'fat cat' & 'o''reilly'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			q, err := ParseTSQuery(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q.String())
+			// The output must round-trip.
+			q2, err := ParseTSQuery(q.String())
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q2.String())
+		})
+	}
+
+	for _, input := range []string{`fat &`, `& fat`, `(fat`, `fat)`, `fat rat`, `fat <x> rat`, `fat <20000> rat`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseTSQuery(input)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNumNodes(t *testing.T) {
+	q, err := ParseTSQuery(`(fat & rat) | !cat`)
+	require.NoError(t, err)
+	require.Equal(t, 6, q.NumNodes())
+}
+
+func TestEvalTSQuery(t *testing.T) {
+	testCases := []struct {
+		vector   string
+		query    string
+		expected bool
+	}{
+		{`fat:1 cat:2`, `fat`, true},
+		{`fat:1 cat:2`, `rat`, false},
+		{`fat:1 cat:2`, `fat & cat`, true},
+		{`fat:1 cat:2`, `fat & rat`, false},
+		{`fat:1 cat:2`, `rat | cat`, true},
+		{`fat:1 cat:2`, `!rat`, true},
+		{`fat:1 cat:2`, `fat & !cat`, false},
+		{`fat:1 cat:2`, ``, false},
+		{`supernova:1`, `super:*`, true},
+		{`supernova:1`, `nova:*`, false},
+		{`fat:1A cat:2`, `fat:A`, true},
+		{`fat:1A cat:2`, `cat:AB`, false},
+		{`fat cat`, `cat:AB`, true},
+		// Phrases.
+		{`fat:1 cat:2`, `fat <-> cat`, true},
+		{`fat:1 cat:2`, `cat <-> fat`, false},
+		{`fat:1 cat:3`, `fat <-> cat`, false},
+		{`fat:1 cat:3`, `fat <2> cat`, true},
+		{`fat:1 cat:2 rat:3`, `fat <-> cat <-> rat`, true},
+		{`fat:1 cat:2 rat:3`, `fat <-> (cat | dog) <-> rat`, true},
+		{`fat:1 cat:2 rat:3`, `fat <-> !dog <-> rat`, true},
+		{`fat:1 cat:2 rat:3`, `fat <-> !cat <-> rat`, false},
+		{`fat:1,3 cat:2`, `fat <-> cat & cat <-> fat`, true},
+		{`fat:1,4 cat:2,5`, `fat <-> cat & cat <-> fat`, false},
+		{`fat:1 cat:1`, `fat <0> cat`, true},
+		// Phrases degrade to & without positions.
+		{`fat cat`, `cat <-> fat`, true},
+		{`fat cat`, `cat <-> rat`, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.vector+" @@ "+tc.query, func(t *testing.T) {
+			v, err := ParseTSVector(tc.vector)
+			require.NoError(t, err)
+			q, err := ParseTSQuery(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, EvalTSQuery(q, v))
+		})
+	}
+}
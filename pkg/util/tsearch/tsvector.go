@@ -0,0 +1,467 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package tsearch implements the full text search data types tsvector and
+// tsquery, the text search configurations used to produce them from text, and
+// the matching and ranking functions which operate on them. The semantics
+// follow the ones of PostgreSQL.
+package tsearch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// maxPosition is the largest position which can be stored in a tsvector.
+// Larger positions are silently clamped to it, like in PostgreSQL.
+const maxPosition = 16383
+
+// maxLexemeLength is the maximum length of a single lexeme, in bytes.
+const maxLexemeLength = 2046
+
+// tsWeight is the weight of a lexeme position. Weights are used to mark
+// positions coming from different parts of a document, e.g. the title or the
+// body, and influence the rank of a document.
+type tsWeight uint8
+
+const (
+	// weightD is the default weight, which is never printed.
+	weightD tsWeight = iota
+	weightC
+	weightB
+	weightA
+)
+
+// String returns the letter representing the weight.
+func (w tsWeight) String() string {
+	return string("DCBA"[w])
+}
+
+// parseWeight returns the weight represented by the given letter.
+func parseWeight(c rune) (tsWeight, bool) {
+	switch c {
+	case 'a', 'A':
+		return weightA, true
+	case 'b', 'B':
+		return weightB, true
+	case 'c', 'C':
+		return weightC, true
+	case 'd', 'D':
+		return weightD, true
+	}
+	return 0, false
+}
+
+// tsPosition is a position of a lexeme in a document, along with its weight.
+type tsPosition struct {
+	position uint16
+	weight   tsWeight
+}
+
+// tsTerm is a lexeme of a tsvector, together with its positions in the
+// document. The positions are sorted and unique.
+type tsTerm struct {
+	lexeme    string
+	positions []tsPosition
+}
+
+// TSVector is a sorted list of distinct lexemes, each optionally annotated
+// with the positions at which it appears in a document. It is the result of
+// the normalization of a document for full text search.
+type TSVector []tsTerm
+
+// ParseTSVector parses the text representation of a tsvector, e.g.
+// 'a':1A 'cat':2,5 fat. No normalization is performed on the lexemes: they
+// are sorted and deduplicated, but taken as is otherwise.
+func ParseTSVector(s string) (TSVector, error) {
+	p := tsParser{input: s, typ: "tsvector"}
+	var v TSVector
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			break
+		}
+		lexeme, err := p.lexeme()
+		if err != nil {
+			return nil, err
+		}
+		term := tsTerm{lexeme: lexeme}
+		if p.peek() == ':' {
+			p.pos++
+			if term.positions, err = p.positions(); err != nil {
+				return nil, err
+			}
+		}
+		v = append(v, term)
+	}
+	return v.normalize(), nil
+}
+
+// normalize sorts the terms of the vector and merges the positions of
+// duplicate lexemes.
+func (v TSVector) normalize() TSVector {
+	if len(v) == 0 {
+		return v
+	}
+	sort.SliceStable(v, func(i, j int) bool { return v[i].lexeme < v[j].lexeme })
+	res := v[:1]
+	res[0].positions = normalizePositions(res[0].positions)
+	for _, t := range v[1:] {
+		last := &res[len(res)-1]
+		if t.lexeme == last.lexeme {
+			last.positions = normalizePositions(append(last.positions, t.positions...))
+			continue
+		}
+		t.positions = normalizePositions(t.positions)
+		res = append(res, t)
+	}
+	return res
+}
+
+// normalizePositions sorts the given positions and removes duplicates. When a
+// position is repeated with different weights, the highest weight is kept.
+func normalizePositions(positions []tsPosition) []tsPosition {
+	if len(positions) == 0 {
+		return nil
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].position != positions[j].position {
+			return positions[i].position < positions[j].position
+		}
+		return positions[i].weight > positions[j].weight
+	})
+	res := positions[:1]
+	for _, p := range positions[1:] {
+		if p.position != res[len(res)-1].position {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// String returns the text representation of the vector.
+func (v TSVector) String() string {
+	var b strings.Builder
+	for i, t := range v {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		writeLexeme(&b, t.lexeme)
+		for j, p := range t.positions {
+			if j == 0 {
+				b.WriteByte(':')
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(int(p.position)))
+			if p.weight != weightD {
+				b.WriteString(p.weight.String())
+			}
+		}
+	}
+	return b.String()
+}
+
+// writeLexeme writes the quoted representation of a lexeme.
+func writeLexeme(b *strings.Builder, lexeme string) {
+	b.WriteByte('\'')
+	for _, c := range lexeme {
+		if c == '\'' || c == '\\' {
+			b.WriteRune(c)
+		}
+		b.WriteRune(c)
+	}
+	b.WriteByte('\'')
+}
+
+// Len returns the number of distinct lexemes in the vector.
+func (v TSVector) Len() int {
+	return len(v)
+}
+
+// Lexemes returns the lexemes of the vector, in order.
+func (v TSVector) Lexemes() []string {
+	res := make([]string, len(v))
+	for i := range v {
+		res[i] = v[i].lexeme
+	}
+	return res
+}
+
+// MakeTSVector returns the vector made of the given lexemes, without
+// positions.
+func MakeTSVector(lexemes []string) (TSVector, error) {
+	v := make(TSVector, len(lexemes))
+	for i, lexeme := range lexemes {
+		if lexeme == "" {
+			return nil, pgerror.New(pgcode.ZeroLengthCharacterString,
+				"lexeme array may not contain empty strings")
+		}
+		if len(lexeme) > maxLexemeLength {
+			return nil, pgerror.Newf(pgcode.ProgramLimitExceeded,
+				"word is too long (%d bytes, max %d bytes)", len(lexeme), maxLexemeLength)
+		}
+		v[i] = tsTerm{lexeme: lexeme}
+	}
+	return v.normalize(), nil
+}
+
+// Strip returns a copy of the vector with all the position and weight
+// information removed.
+func (v TSVector) Strip() TSVector {
+	res := make(TSVector, len(v))
+	for i := range v {
+		res[i] = tsTerm{lexeme: v[i].lexeme}
+	}
+	return res
+}
+
+// SetWeight returns a copy of the vector in which the weight of every
+// position is set to the weight represented by the given letter.
+func (v TSVector) SetWeight(weight string) (TSVector, error) {
+	r := []rune(weight)
+	if len(r) != 1 {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue, "unrecognized weight: %q", weight)
+	}
+	w, ok := parseWeight(r[0])
+	if !ok {
+		return nil, pgerror.Newf(pgcode.InvalidParameterValue, "unrecognized weight: %q", weight)
+	}
+	res := make(TSVector, len(v))
+	for i := range v {
+		res[i] = tsTerm{lexeme: v[i].lexeme}
+		if len(v[i].positions) > 0 {
+			res[i].positions = make([]tsPosition, len(v[i].positions))
+			for j, p := range v[i].positions {
+				res[i].positions[j] = tsPosition{position: p.position, weight: w}
+			}
+		}
+	}
+	return res, nil
+}
+
+// Concat returns the concatenation of the two vectors. The positions of the
+// second vector are shifted by the largest position of the first one, like
+// the PostgreSQL || operator does.
+func (v TSVector) Concat(other TSVector) TSVector {
+	var maxPos uint16
+	for _, t := range v {
+		for _, p := range t.positions {
+			if p.position > maxPos {
+				maxPos = p.position
+			}
+		}
+	}
+	res := make(TSVector, 0, len(v)+len(other))
+	for _, t := range v {
+		res = append(res, tsTerm{lexeme: t.lexeme, positions: append([]tsPosition(nil), t.positions...)})
+	}
+	for _, t := range other {
+		term := tsTerm{lexeme: t.lexeme}
+		for _, p := range t.positions {
+			pos := int(p.position) + int(maxPos)
+			if pos > maxPosition {
+				pos = maxPosition
+			}
+			term.positions = append(term.positions, tsPosition{position: uint16(pos), weight: p.weight})
+		}
+		res = append(res, term)
+	}
+	return res.normalize()
+}
+
+// Compare compares two vectors, returning -1, 0 or 1. Vectors are ordered
+// first by their number of lexemes, and then lexeme by lexeme.
+func (v TSVector) Compare(other TSVector) int {
+	if len(v) != len(other) {
+		if len(v) < len(other) {
+			return -1
+		}
+		return 1
+	}
+	for i := range v {
+		if c := strings.Compare(v[i].lexeme, other[i].lexeme); c != 0 {
+			return c
+		}
+		a, b := v[i].positions, other[i].positions
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+		for j := range a {
+			if a[j] != b[j] {
+				if a[j].position < b[j].position ||
+					(a[j].position == b[j].position && a[j].weight < b[j].weight) {
+					return -1
+				}
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// Size returns the approximate size of the vector, in bytes.
+func (v TSVector) Size() uintptr {
+	var sz uintptr
+	for i := range v {
+		sz += uintptr(len(v[i].lexeme)) + uintptr(len(v[i].positions))*3 + 40
+	}
+	return sz
+}
+
+// tsParser is a helper to parse the text representation of tsvectors and
+// tsqueries.
+type tsParser struct {
+	input string
+	pos   int
+	// typ is the name of the type being parsed, used in error messages.
+	typ string
+}
+
+func (p *tsParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+// peek returns the next byte of the input, or 0 at the end of the input.
+func (p *tsParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *tsParser) skipSpaces() {
+	for !p.eof() && isSpace(p.input[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// lexeme parses a quoted or unquoted lexeme. A quoted lexeme is delimited by
+// single quotes, in which quotes are escaped by doubling them or with a
+// backslash. An unquoted lexeme extends up to the next space or special
+// character, in which case the special characters can be escaped with a
+// backslash.
+func (p *tsParser) lexeme() (string, error) {
+	return p.lexemeWithSpecials(":")
+}
+
+func (p *tsParser) lexemeWithSpecials(specials string) (string, error) {
+	var b strings.Builder
+	if p.peek() == '\'' {
+		p.pos++
+		for {
+			if p.eof() {
+				return "", p.syntaxError()
+			}
+			c := p.input[p.pos]
+			p.pos++
+			if c == '\\' {
+				if p.eof() {
+					return "", p.syntaxError()
+				}
+				c = p.input[p.pos]
+				p.pos++
+			} else if c == '\'' {
+				if p.peek() != '\'' {
+					break
+				}
+				p.pos++
+			}
+			b.WriteByte(c)
+		}
+	} else {
+		for !p.eof() {
+			c := p.input[p.pos]
+			if isSpace(c) || strings.IndexByte(specials, c) >= 0 {
+				break
+			}
+			if c == '\'' {
+				return "", p.syntaxError()
+			}
+			p.pos++
+			if c == '\\' {
+				if p.eof() {
+					return "", p.syntaxError()
+				}
+				c = p.input[p.pos]
+				p.pos++
+			}
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() == 0 {
+		return "", p.syntaxError()
+	}
+	if b.Len() > maxLexemeLength {
+		return "", pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"word is too long (%d bytes, max %d bytes)", b.Len(), maxLexemeLength)
+	}
+	return b.String(), nil
+}
+
+// positions parses a comma-separated list of positions, each optionally
+// followed by a weight.
+func (p *tsParser) positions() ([]tsPosition, error) {
+	var res []tsPosition
+	for {
+		start := p.pos
+		for !p.eof() && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+			p.pos++
+		}
+		if start == p.pos {
+			return nil, p.syntaxError()
+		}
+		n, err := strconv.Atoi(p.input[start:p.pos])
+		if err != nil || n > maxPosition {
+			n = maxPosition
+		}
+		if n == 0 {
+			return nil, pgerror.Newf(pgcode.InvalidParameterValue, "wrong position info in tsvector: %q", p.input)
+		}
+		pos := tsPosition{position: uint16(n)}
+		if !p.eof() {
+			if w, ok := parseWeight(rune(p.input[p.pos])); ok {
+				pos.weight = w
+				p.pos++
+			}
+		}
+		res = append(res, pos)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if !p.eof() && !isSpace(p.input[p.pos]) {
+		return nil, p.syntaxError()
+	}
+	return res, nil
+}
+
+func (p *tsParser) syntaxError() error {
+	return pgerror.Newf(pgcode.Syntax, "syntax error in %s: %q", p.typ, p.input)
+}
+
+// isWordRune returns whether the given rune is part of a word when parsing
+// documents.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
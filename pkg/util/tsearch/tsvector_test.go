@@ -0,0 +1,100 @@
+// Copyright 2022 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package tsearch
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTSVector(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{``, ``},
+		{`a fat cat`, `'a' 'cat' 'fat'`},
+		{`fat cat fat`, `'cat' 'fat'`},
+		{`'fat cat':1 'o''reilly':2B`, `'fat cat':1 'o''reilly':2B`},
+		{`a:3,1A,3C b:2D c:20000`, `'a':1A,3C 'b':2 'c':16383`},
+		{`a:1 a:2B`, `'a':1,2B`},
+		{`a\:b back\\slash`, `'a:b' 'back\\slash'`},
+		{`  leading   and trailing  `, `'and' 'leading' 'trailing'`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			v, err := ParseTSVector(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, v.String())
+			// The output must round-trip.
+			v2, err := ParseTSVector(v.String())
+			require.NoError(t, err)
+			require.Equal(t, 0, v.Compare(v2))
+		})
+	}
+
+	for _, input := range []string{`'unterminated`, `a:`, `a:1,`, `a:0`, `a:1x`, `a'b`} {
+		t.Run(input, func(t *testing.T) {
+			_, err := ParseTSVector(input)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTSVectorOperations(t *testing.T) {
+	v, err := ParseTSVector(`fat:2,4 cat:3 rat:5A`)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, v.Len())
+	require.Equal(t, []string{"cat", "fat", "rat"}, v.Lexemes())
+	require.Equal(t, `'cat' 'fat' 'rat'`, v.Strip().String())
+
+	weighted, err := v.SetWeight("b")
+	require.NoError(t, err)
+	require.Equal(t, `'cat':3B 'fat':2B,4B 'rat':5B`, weighted.String())
+	_, err = v.SetWeight("x")
+	require.Error(t, err)
+	// The original vector is not modified.
+	require.Equal(t, `'cat':3 'fat':2,4 'rat':5A`, v.String())
+
+	other, err := ParseTSVector(`fat:1 dog:2`)
+	require.NoError(t, err)
+	require.Equal(t, `'cat':3 'dog':7 'fat':2,4,6 'rat':5A`, v.Concat(other).String())
+
+	made, err := MakeTSVector([]string{"b", "a", "b"})
+	require.NoError(t, err)
+	require.Equal(t, `'a' 'b'`, made.String())
+	_, err = MakeTSVector([]string{"a", ""})
+	require.Error(t, err)
+
+	require.Equal(t, 1, v.Compare(other))
+	require.Equal(t, -1, other.Compare(v))
+	require.Equal(t, 0, v.Compare(v))
+}
+
+func TestRandomRoundTrip(t *testing.T) {
+	rng, _ := randutil.NewTestRand()
+	for i := 0; i < 100; i++ {
+		v := RandomTSVector(rng)
+		v2, err := ParseTSVector(v.String())
+		require.NoError(t, err)
+		require.Equal(t, 0, v.Compare(v2), v.String())
+
+		q := RandomTSQuery(rng)
+		q2, err := ParseTSQuery(q.String())
+		require.NoError(t, err)
+		require.Equal(t, q.String(), q2.String())
+		// Evaluation must not depend on the representation of the query.
+		require.Equal(t, EvalTSQuery(q, v), EvalTSQuery(q2, v), "%s @@ %s", v, q)
+	}
+}
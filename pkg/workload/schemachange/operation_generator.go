@@ -842,10 +842,10 @@ func (og *operationGenerator) createIndex(ctx context.Context, tx pgx.Tx) (strin
 		}
 		if def.Inverted {
 			// We can have an inverted index on a set of columns if the last column
-			// is an inverted indexable type and the preceding columns are not.
-			invertedIndexableType := colinfo.ColumnTypeIsInvertedIndexable(columnNames[i].typ)
-			if (invertedIndexableType && i < len(def.Columns)-1) ||
-				(!invertedIndexableType && i == len(def.Columns)-1) {
+			// is an inverted indexable type and the preceding columns are not
+			// only inverted indexable.
+			if (colinfo.ColumnTypeIsOnlyInvertedIndexable(columnNames[i].typ) && i < len(def.Columns)-1) ||
+				(!colinfo.ColumnTypeIsInvertedIndexable(columnNames[i].typ) && i == len(def.Columns)-1) {
 				nonIndexableType = true
 			}
 		} else {